@@ -0,0 +1,32 @@
+package dnsdrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// dnsPortAdapter implements model.DNSPort backed by DNS drivers.
+type dnsPortAdapter struct{}
+
+// GetDNSPort returns a model.DNSPort that writes record sets through registered DNS drivers.
+func GetDNSPort() model.DNSPort {
+	return &dnsPortAdapter{}
+}
+
+// DNSApply creates the driver configured by dns and applies the record set in zone.
+func (a *dnsPortAdapter) DNSApply(ctx context.Context, dns *model.DNSProvider, zone string, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	if dns == nil {
+		return fmt.Errorf("dns provider nil")
+	}
+	factory, ok := GetDriverFactory(dns.Driver)
+	if !ok {
+		return fmt.Errorf("unknown dns driver: %s", dns.Driver)
+	}
+	drv, err := factory(dns)
+	if err != nil {
+		return fmt.Errorf("failed to create dns driver %s: %w", dns.Driver, err)
+	}
+	return drv.RecordSetApply(ctx, zone, rset, opts...)
+}
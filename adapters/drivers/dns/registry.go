@@ -0,0 +1,80 @@
+// Package dnsdrv provides standalone DNS provider drivers that write DNS record sets
+// independently of the cluster provider driver (see model.DNSProvider).
+package dnsdrv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// DefaultTTL is the TTL in seconds used when a record set does not specify one.
+const DefaultTTL = 300
+
+// Driver writes DNS record sets to a DNS provider.
+// Implementations live under adapters/drivers/dns/<name>.
+type Driver interface {
+	// ID returns the DNS driver identifier (e.g., "rfc2136").
+	ID() string
+
+	// RecordSetApply applies rset in zone. zone is the configured zone containing the FQDN,
+	// or empty when the provider has no zones configured. Like ClusterDNSApply, the method must
+	// be idempotent and best-effort: write failures are suppressed unless opts request strict
+	// handling. Empty RData deletes the record set.
+	RecordSetApply(ctx context.Context, zone string, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error
+}
+
+// driverFactory is a constructor function for a DNS driver.
+type driverFactory func(dns *model.DNSProvider) (Driver, error)
+
+// registry holds registered drivers by name.
+var registry = map[string]driverFactory{}
+
+// Register makes a driver available by the given name. Drivers should call
+// this from their init() function.
+func Register(name string, factory driverFactory) {
+	registry[name] = factory
+}
+
+// GetDriverFactory returns the driver factory function for the given name.
+func GetDriverFactory(name string) (driverFactory, bool) {
+	factory, exists := registry[name]
+	return factory, exists
+}
+
+// NormalizeRecordSet lowercases the FQDN, removes its trailing dot, validates the record type
+// and applies DefaultTTL. It returns an error when the FQDN is outside zone (if non-empty).
+func NormalizeRecordSet(zone string, rset *model.DNSRecordSet) error {
+	rset.FQDN = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(rset.FQDN), "."))
+	if rset.FQDN == "" {
+		return fmt.Errorf("FQDN is required")
+	}
+	if zone = strings.ToLower(strings.TrimSuffix(zone, ".")); zone != "" && rset.FQDN != zone && !strings.HasSuffix(rset.FQDN, "."+zone) {
+		return fmt.Errorf("FQDN %s is not in zone %s", rset.FQDN, zone)
+	}
+	switch rset.Type {
+	case model.DNSRecordTypeA, model.DNSRecordTypeAAAA, model.DNSRecordTypeCNAME, model.DNSRecordTypeTXT:
+	default:
+		return fmt.Errorf("unsupported DNS record type: %s", rset.Type)
+	}
+	if rset.Type == model.DNSRecordTypeCNAME && len(rset.RData) > 1 {
+		return fmt.Errorf("CNAME record must have exactly one RData entry, got %d", len(rset.RData))
+	}
+	if rset.TTL == 0 {
+		rset.TTL = DefaultTTL
+	}
+	return nil
+}
+
+// ApplyOptions returns the options struct resulting from opts.
+func ApplyOptions(opts []model.ClusterDNSApplyOption) *model.ClusterDNSApplyOptions {
+	o := &model.ClusterDNSApplyOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
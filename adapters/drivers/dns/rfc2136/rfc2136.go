@@ -0,0 +1,192 @@
+// Package rfc2136 implements a DNS driver that writes record sets with RFC 2136 dynamic
+// updates, optionally signed with TSIG (RFC 8945). It works with BIND, Knot, PowerDNS and
+// other authoritative servers that accept dynamic updates.
+package rfc2136
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	dnsdrv "github.com/kompox/kompox/adapters/drivers/dns"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Setting keys of model.DNSProvider.Settings.
+const (
+	keyServer        = "RFC2136_SERVER"         // primary server host[:port] (port defaults to 53)
+	keyNet           = "RFC2136_NET"            // "udp" (default) or "tcp"
+	keyTSIGKeyName   = "RFC2136_TSIG_KEY_NAME"  // TSIG key name; empty sends unsigned updates
+	keyTSIGSecret    = "RFC2136_TSIG_SECRET"    // base64-encoded TSIG secret
+	keyTSIGAlgorithm = "RFC2136_TSIG_ALGORITHM" // hmac-sha256 (default), hmac-sha1, hmac-sha224, hmac-sha384, hmac-sha512
+)
+
+const (
+	defaultPort = "53"
+	tsigFudge   = 300
+	timeout     = 10 * time.Second
+)
+
+// tsigAlgorithms maps accepted algorithm names to their wire names.
+var tsigAlgorithms = map[string]string{
+	"hmac-sha1":   dns.HmacSHA1,
+	"hmac-sha224": dns.HmacSHA224,
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha384": dns.HmacSHA384,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+// driver sends dynamic updates to a primary server.
+type driver struct {
+	server    string
+	net       string
+	keyName   string // canonical FQDN; empty when unsigned
+	secret    string // base64
+	algorithm string
+}
+
+func init() {
+	dnsdrv.Register("rfc2136", func(cfg *model.DNSProvider) (dnsdrv.Driver, error) {
+		if len(cfg.Zones) == 0 {
+			return nil, fmt.Errorf("rfc2136 requires zones")
+		}
+		settings := cfg.Settings
+		server := strings.TrimSpace(settings[keyServer])
+		if server == "" {
+			return nil, fmt.Errorf("missing: %s", keyServer)
+		}
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), defaultPort)
+		}
+		d := &driver{server: server, net: "udp"}
+		switch n := strings.ToLower(settings[keyNet]); n {
+		case "", "udp":
+		case "tcp":
+			d.net = n
+		default:
+			return nil, fmt.Errorf("invalid %s: %q (must be udp or tcp)", keyNet, settings[keyNet])
+		}
+		if name := settings[keyTSIGKeyName]; name != "" {
+			secret := settings[keyTSIGSecret]
+			if secret == "" {
+				return nil, fmt.Errorf("missing: %s", keyTSIGSecret)
+			}
+			if _, err := base64.StdEncoding.DecodeString(secret); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", keyTSIGSecret, err)
+			}
+			alg := strings.ToLower(strings.TrimSuffix(settings[keyTSIGAlgorithm], "."))
+			if alg == "" {
+				alg = "hmac-sha256"
+			}
+			wire, ok := tsigAlgorithms[alg]
+			if !ok {
+				return nil, fmt.Errorf("invalid %s: %q", keyTSIGAlgorithm, settings[keyTSIGAlgorithm])
+			}
+			d.keyName, d.secret, d.algorithm = dns.CanonicalName(name), secret, wire
+		}
+		return d, nil
+	})
+}
+
+// ID returns the DNS driver identifier.
+func (d *driver) ID() string { return "rfc2136" }
+
+// RecordSetApply replaces the record set (delete RRset, then add records) in one update
+// message so that the change is atomic on the server. Empty RData only deletes.
+func (d *driver) RecordSetApply(ctx context.Context, zone string, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "RecordSetApply")
+	defer func() { cleanup(err) }()
+
+	options := dnsdrv.ApplyOptions(opts)
+	log := logging.FromContext(ctx)
+
+	err = dnsdrv.NormalizeRecordSet(zone, &rset)
+	var msg *dns.Msg
+	if err == nil {
+		msg, err = d.updateMessage(zone, rset)
+	}
+	if err != nil {
+		if options.Strict {
+			return fmt.Errorf("validate DNS record set: %w", err)
+		}
+		log.Warn(ctx, "RecordSetApply: invalid input", "error", err.Error())
+		return nil
+	}
+
+	action := "upsert"
+	if len(rset.RData) == 0 {
+		action = "delete"
+	}
+	if options.DryRun {
+		log.Info(ctx, "RecordSetApply: dry-run", "action", action, "zone", zone, "fqdn", rset.FQDN, "type", rset.Type, "rdata", rset.RData)
+		return nil
+	}
+
+	if err := d.send(ctx, msg); err != nil {
+		if options.Strict || ctx.Err() != nil {
+			return fmt.Errorf("%s DNS record %s: %w", action, rset.FQDN, err)
+		}
+		log.Warn(ctx, "RecordSetApply: update failed", "action", action, "fqdn", rset.FQDN, "error", err.Error())
+		return nil
+	}
+	log.Info(ctx, "RecordSetApply: applied", "action", action, "zone", zone, "fqdn", rset.FQDN, "type", rset.Type)
+	return nil
+}
+
+// updateMessage builds the dynamic update for the normalized rset in zone.
+func (d *driver) updateMessage(zone string, rset model.DNSRecordSet) (*dns.Msg, error) {
+	if zone == "" {
+		return nil, fmt.Errorf("zone is required")
+	}
+	rrtype, ok := dns.StringToType[string(rset.Type)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DNS record type: %s", rset.Type)
+	}
+	name := dns.Fqdn(rset.FQDN)
+
+	rrs := make([]dns.RR, 0, len(rset.RData))
+	for _, rdata := range rset.RData {
+		if rset.Type == model.DNSRecordTypeTXT && !strings.HasPrefix(rdata, `"`) {
+			rdata = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(rdata) + `"`
+		}
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, rset.TTL, rset.Type, rdata))
+		if err != nil {
+			return nil, fmt.Errorf("invalid RData %q: %w", rdata, err)
+		}
+		if rr == nil {
+			return nil, fmt.Errorf("invalid RData %q", rdata)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zone))
+	msg.RemoveRRset([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET}}})
+	if len(rrs) > 0 {
+		msg.Insert(rrs)
+	}
+	return msg, nil
+}
+
+// send signs msg (if a TSIG key is configured) and sends it to the server.
+func (d *driver) send(ctx context.Context, msg *dns.Msg) error {
+	client := &dns.Client{Net: d.net, Timeout: timeout}
+	if d.keyName != "" {
+		client.TsigSecret = map[string]string{d.keyName: d.secret}
+		msg.SetTsig(d.keyName, d.algorithm, tsigFudge, time.Now().Unix())
+	}
+	resp, _, err := client.ExchangeContext(ctx, msg, d.server)
+	if err != nil {
+		return err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("server %s rejected update: %s", d.server, dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package rfc2136
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	dnsdrv "github.com/kompox/kompox/adapters/drivers/dns"
+	"github.com/kompox/kompox/domain/model"
+)
+
+const (
+	testKeyName = "kompox-key."
+	testSecret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0IQ=="
+)
+
+// testServer is an in-process authoritative server for example.com that accepts
+// TSIG-signed dynamic updates and keeps records in memory.
+type testServer struct {
+	addr string
+
+	mu      sync.Mutex
+	records map[string][]string // "<fqdn>/<type>" -> rdata
+}
+
+func startTestServer(t *testing.T, network string) *testServer {
+	t.Helper()
+	ts := &testServer{records: map[string][]string{}}
+	srv := &dns.Server{
+		Net:        network,
+		TsigSecret: map[string]string{testKeyName: testSecret},
+		Handler:    dns.HandlerFunc(ts.serveDNS),
+		// The default accept func rejects dynamic updates.
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			if dh.Bits&(1<<15) != 0 {
+				return dns.MsgIgnore
+			}
+			return dns.MsgAccept
+		},
+	}
+	switch network {
+	case "udp":
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.PacketConn, ts.addr = pc, pc.LocalAddr().String()
+	case "tcp":
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Listener, ts.addr = l, l.Addr().String()
+	}
+	started := make(chan struct{})
+	srv.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = srv.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	return ts
+}
+
+func (ts *testServer) serveDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	defer func() {
+		if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+			m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsigFudge, time.Now().Unix())
+		}
+		_ = w.WriteMsg(m)
+	}()
+
+	if r.Opcode != dns.OpcodeUpdate || len(r.Question) != 1 || r.Question[0].Name != "example.com." {
+		m.Rcode = dns.RcodeNotZone
+		return
+	}
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m.Rcode = dns.RcodeNotAuth
+		return
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, rr := range r.Ns {
+		h := rr.Header()
+		key := strings.TrimSuffix(h.Name, ".") + "/" + dns.TypeToString[h.Rrtype]
+		switch h.Class {
+		case dns.ClassANY:
+			delete(ts.records, key)
+		case dns.ClassINET:
+			ts.records[key] = append(ts.records[key], strings.TrimPrefix(rr.String(), h.String()))
+		}
+	}
+}
+
+func (ts *testServer) lookup(fqdn string, rtype model.DNSRecordType) []string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	rdata := slices.Clone(ts.records[fqdn+"/"+string(rtype)])
+	sort.Strings(rdata)
+	return rdata
+}
+
+func newTestDriver(t *testing.T, settings map[string]string) dnsdrv.Driver {
+	t.Helper()
+	factory, ok := dnsdrv.GetDriverFactory("rfc2136")
+	if !ok {
+		t.Fatal("rfc2136 driver not registered")
+	}
+	drv, err := factory(&model.DNSProvider{Driver: "rfc2136", Zones: []string{"example.com"}, Settings: settings})
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	return drv
+}
+
+func TestRecordSetApply(t *testing.T) {
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			ts := startTestServer(t, network)
+			drv := newTestDriver(t, map[string]string{
+				keyServer:      ts.addr,
+				keyNet:         network,
+				keyTSIGKeyName: "Kompox-Key",
+				keyTSIGSecret:  testSecret,
+			})
+			ctx := context.Background()
+			strict := model.WithClusterDNSApplyStrict()
+
+			rset := model.DNSRecordSet{FQDN: "WWW.example.com.", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.2", "192.0.2.1"}}
+			if err := drv.RecordSetApply(ctx, "example.com", rset, strict); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+			if got := ts.lookup("www.example.com", model.DNSRecordTypeA); !slices.Equal(got, []string{"192.0.2.1", "192.0.2.2"}) {
+				t.Errorf("records = %v", got)
+			}
+
+			// Upsert replaces the whole record set
+			rset.RData = []string{"192.0.2.3"}
+			if err := drv.RecordSetApply(ctx, "example.com", rset, strict); err != nil {
+				t.Fatalf("replace: %v", err)
+			}
+			if got := ts.lookup("www.example.com", model.DNSRecordTypeA); !slices.Equal(got, []string{"192.0.2.3"}) {
+				t.Errorf("records after replace = %v", got)
+			}
+
+			txt := model.DNSRecordSet{FQDN: "txt.example.com", Type: model.DNSRecordTypeTXT, RData: []string{`hello "kompox" world`}}
+			if err := drv.RecordSetApply(ctx, "example.com", txt, strict); err != nil {
+				t.Fatalf("TXT: %v", err)
+			}
+			if got := ts.lookup("txt.example.com", model.DNSRecordTypeTXT); len(got) != 1 || !strings.Contains(got[0], `\"kompox\"`) {
+				t.Errorf("TXT records = %v", got)
+			}
+
+			// Dry run leaves the server untouched
+			rset.RData = []string{"192.0.2.9"}
+			if err := drv.RecordSetApply(ctx, "example.com", rset, strict, model.WithClusterDNSApplyDryRun()); err != nil {
+				t.Fatalf("dry-run: %v", err)
+			}
+			if got := ts.lookup("www.example.com", model.DNSRecordTypeA); !slices.Equal(got, []string{"192.0.2.3"}) {
+				t.Errorf("dry-run changed records: %v", got)
+			}
+
+			// Empty RData deletes; deleting again succeeds
+			rset.RData = nil
+			for range 2 {
+				if err := drv.RecordSetApply(ctx, "example.com", rset, strict); err != nil {
+					t.Fatalf("delete: %v", err)
+				}
+			}
+			if got := ts.lookup("www.example.com", model.DNSRecordTypeA); len(got) != 0 {
+				t.Errorf("records after delete = %v", got)
+			}
+		})
+	}
+}
+
+func TestRecordSetApplyErrors(t *testing.T) {
+	ts := startTestServer(t, "udp")
+	ctx := context.Background()
+	strict := model.WithClusterDNSApplyStrict()
+	rset := model.DNSRecordSet{FQDN: "www.example.com", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}}
+
+	unsigned := newTestDriver(t, map[string]string{keyServer: ts.addr})
+	if err := unsigned.RecordSetApply(ctx, "example.com", rset, strict); err == nil || !strings.Contains(err.Error(), "NOTAUTH") {
+		t.Errorf("expected NOTAUTH for unsigned update, got %v", err)
+	}
+	if err := unsigned.RecordSetApply(ctx, "example.com", rset); err != nil {
+		t.Errorf("best-effort mode must suppress write failures, got %v", err)
+	}
+
+	signed := newTestDriver(t, map[string]string{keyServer: ts.addr, keyTSIGKeyName: testKeyName, keyTSIGSecret: testSecret})
+	outside := model.DNSRecordSet{FQDN: "www.example.net", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}}
+	if err := signed.RecordSetApply(ctx, "example.com", outside, strict); err == nil {
+		t.Error("expected error for FQDN outside the zone")
+	}
+	bad := model.DNSRecordSet{FQDN: "www.example.com", Type: model.DNSRecordTypeA, RData: []string{"not-an-ip"}}
+	if err := signed.RecordSetApply(ctx, "example.com", bad, strict); err == nil {
+		t.Error("expected error for invalid RData")
+	}
+	if len(ts.lookup("www.example.com", model.DNSRecordTypeA)) != 0 {
+		t.Error("failed updates must not change records")
+	}
+}
+
+func TestFactoryValidation(t *testing.T) {
+	factory, _ := dnsdrv.GetDriverFactory("rfc2136")
+	tests := []struct {
+		name     string
+		zones    []string
+		settings map[string]string
+	}{
+		{"no zones", nil, map[string]string{keyServer: "192.0.2.53"}},
+		{"no server", []string{"example.com"}, map[string]string{}},
+		{"bad net", []string{"example.com"}, map[string]string{keyServer: "192.0.2.53", keyNet: "quic"}},
+		{"key without secret", []string{"example.com"}, map[string]string{keyServer: "192.0.2.53", keyTSIGKeyName: "k"}},
+		{"bad secret", []string{"example.com"}, map[string]string{keyServer: "192.0.2.53", keyTSIGKeyName: "k", keyTSIGSecret: "!!"}},
+		{"bad algorithm", []string{"example.com"}, map[string]string{keyServer: "192.0.2.53", keyTSIGKeyName: "k", keyTSIGSecret: testSecret, keyTSIGAlgorithm: "hmac-md5"}},
+	}
+	for _, tt := range tests {
+		if _, err := factory(&model.DNSProvider{Driver: "rfc2136", Zones: tt.zones, Settings: tt.settings}); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	drv, err := factory(&model.DNSProvider{Driver: "rfc2136", Zones: []string{"example.com"}, Settings: map[string]string{keyServer: "192.0.2.53"}})
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	if got := drv.(*driver).server; got != "192.0.2.53:53" {
+		t.Errorf("server = %q, want default port", got)
+	}
+}
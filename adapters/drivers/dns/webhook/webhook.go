@@ -0,0 +1,147 @@
+// Package webhook implements a DNS driver that posts record set changes to an HTTP endpoint.
+// It integrates DNS services without a built-in driver (e.g., Cloudflare through a small
+// relay, or an internal IPAM) by delegating the write to user-operated code.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	dnsdrv "github.com/kompox/kompox/adapters/drivers/dns"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Setting keys of model.DNSProvider.Settings.
+const (
+	keyURL   = "WEBHOOK_URL"   // endpoint receiving POST requests (http or https)
+	keyToken = "WEBHOOK_TOKEN" // optional bearer token sent in the Authorization header
+)
+
+const (
+	// payloadVersion is the version of Payload.
+	payloadVersion = 1
+	timeout        = 30 * time.Second
+	// maxErrorBody bounds the response body included in error messages.
+	maxErrorBody = 512
+)
+
+// Payload is the JSON body posted to the webhook. Action is "upsert" or "delete";
+// RData is empty for deletions.
+type Payload struct {
+	Version int                 `json:"version"`
+	Action  string              `json:"action"`
+	Zone    string              `json:"zone,omitempty"`
+	FQDN    string              `json:"fqdn"`
+	Type    model.DNSRecordType `json:"type"`
+	TTL     uint32              `json:"ttl"`
+	RData   []string            `json:"rdata,omitempty"`
+}
+
+// driver posts record set changes to a webhook endpoint.
+type driver struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func init() {
+	dnsdrv.Register("webhook", func(cfg *model.DNSProvider) (dnsdrv.Driver, error) {
+		raw := strings.TrimSpace(cfg.Settings[keyURL])
+		if raw == "" {
+			return nil, fmt.Errorf("missing: %s", keyURL)
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s: %q (must be an http or https URL)", keyURL, raw)
+		}
+		return &driver{
+			url:    raw,
+			token:  cfg.Settings[keyToken],
+			client: &http.Client{Timeout: timeout},
+		}, nil
+	})
+}
+
+// ID returns the DNS driver identifier.
+func (d *driver) ID() string { return "webhook" }
+
+// RecordSetApply posts the change to the webhook. Any 2xx response is success.
+// The endpoint must treat repeated requests idempotently.
+func (d *driver) RecordSetApply(ctx context.Context, zone string, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "RecordSetApply")
+	defer func() { cleanup(err) }()
+
+	options := dnsdrv.ApplyOptions(opts)
+	log := logging.FromContext(ctx)
+
+	if err := dnsdrv.NormalizeRecordSet(zone, &rset); err != nil {
+		if options.Strict {
+			return fmt.Errorf("validate DNS record set: %w", err)
+		}
+		log.Warn(ctx, "RecordSetApply: invalid input", "error", err.Error())
+		return nil
+	}
+
+	payload := Payload{
+		Version: payloadVersion,
+		Action:  "upsert",
+		Zone:    strings.ToLower(strings.TrimSuffix(zone, ".")),
+		FQDN:    rset.FQDN,
+		Type:    rset.Type,
+		TTL:     rset.TTL,
+		RData:   rset.RData,
+	}
+	if len(rset.RData) == 0 {
+		payload.Action = "delete"
+	}
+	if options.DryRun {
+		log.Info(ctx, "RecordSetApply: dry-run", "action", payload.Action, "zone", payload.Zone, "fqdn", rset.FQDN, "type", rset.Type, "rdata", rset.RData)
+		return nil
+	}
+
+	if err := d.post(ctx, &payload); err != nil {
+		if options.Strict || ctx.Err() != nil {
+			return fmt.Errorf("%s DNS record %s: %w", payload.Action, rset.FQDN, err)
+		}
+		log.Warn(ctx, "RecordSetApply: webhook failed", "action", payload.Action, "fqdn", rset.FQDN, "error", err.Error())
+		return nil
+	}
+	log.Info(ctx, "RecordSetApply: applied", "action", payload.Action, "zone", payload.Zone, "fqdn", rset.FQDN, "type", rset.Type)
+	return nil
+}
+
+// post sends the payload and checks the response status.
+func (d *driver) post(ctx context.Context, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kompoxops")
+	if d.token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.token)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dnsdrv "github.com/kompox/kompox/adapters/drivers/dns"
+	"github.com/kompox/kompox/domain/model"
+)
+
+func newTestDriver(t *testing.T, settings map[string]string) dnsdrv.Driver {
+	t.Helper()
+	factory, ok := dnsdrv.GetDriverFactory("webhook")
+	if !ok {
+		t.Fatal("webhook driver not registered")
+	}
+	drv, err := factory(&model.DNSProvider{Driver: "webhook", Settings: settings})
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	return drv
+}
+
+func TestRecordSetApply(t *testing.T) {
+	var got []Payload
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		got = append(got, p)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("zone locked"))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	strict := model.WithClusterDNSApplyStrict()
+	drv := newTestDriver(t, map[string]string{keyURL: srv.URL, keyToken: "s3cret"})
+
+	rset := model.DNSRecordSet{FQDN: "WWW.Example.com.", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}}
+	if err := drv.RecordSetApply(ctx, "example.com.", rset, strict); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if err := drv.RecordSetApply(ctx, "", model.DNSRecordSet{FQDN: "www.example.com", Type: model.DNSRecordTypeA}, strict); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := drv.RecordSetApply(ctx, "", rset, strict, model.WithClusterDNSApplyDryRun()); err != nil {
+		t.Fatalf("dry-run: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 requests (dry-run must not post), got %d", len(got))
+	}
+	want := Payload{Version: 1, Action: "upsert", Zone: "example.com", FQDN: "www.example.com", Type: model.DNSRecordTypeA, TTL: dnsdrv.DefaultTTL, RData: []string{"192.0.2.1"}}
+	if p := got[0]; p.Version != want.Version || p.Action != want.Action || p.Zone != want.Zone || p.FQDN != want.FQDN || p.TTL != want.TTL || len(p.RData) != 1 {
+		t.Errorf("upsert payload = %+v, want %+v", p, want)
+	}
+	if p := got[1]; p.Action != "delete" || len(p.RData) != 0 {
+		t.Errorf("delete payload = %+v", p)
+	}
+
+	status = http.StatusConflict
+	if err := drv.RecordSetApply(ctx, "", rset, strict); err == nil || !strings.Contains(err.Error(), "zone locked") {
+		t.Errorf("expected strict error with response body, got %v", err)
+	}
+	if err := drv.RecordSetApply(ctx, "", rset); err != nil {
+		t.Errorf("best-effort mode must suppress write failures, got %v", err)
+	}
+
+	unauthorized := newTestDriver(t, map[string]string{keyURL: srv.URL})
+	if err := unauthorized.RecordSetApply(ctx, "", rset, strict); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestFactoryValidation(t *testing.T) {
+	factory, _ := dnsdrv.GetDriverFactory("webhook")
+	for _, u := range []string{"", "ftp://dns.example.com", "not a url", "https://"} {
+		if _, err := factory(&model.DNSProvider{Driver: "webhook", Settings: map[string]string{keyURL: u}}); err == nil {
+			t.Errorf("expected error for %s=%q", keyURL, u)
+		}
+	}
+}
@@ -2,132 +2,77 @@ package aks
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
-	"github.com/kompox/kompox/adapters/kube"
 	"github.com/kompox/kompox/domain/model"
 	"github.com/kompox/kompox/internal/logging"
 )
 
-// Constants for template output keys
-const (
-	outputTenantID                         = "AZURE_TENANT_ID"
-	outputResourceGroupName                = "AZURE_RESOURCE_GROUP_NAME"
-	outputAksClusterName                   = "AZURE_AKS_CLUSTER_NAME"
-	outputAksClusterPrincipalID            = "AZURE_AKS_CLUSTER_PRINCIPAL_ID"
-	outputAksKubeletPrincipalID            = "AZURE_AKS_KUBELET_PRINCIPAL_ID"
-	outputIngressServiceAccountNamespace   = "AZURE_INGRESS_SERVICE_ACCOUNT_NAMESPACE"
-	outputIngressServiceAccountName        = "AZURE_INGRESS_SERVICE_ACCOUNT_NAME"
-	outputIngressServiceAccountClientID    = "AZURE_INGRESS_SERVICE_ACCOUNT_CLIENT_ID"
-	outputIngressServiceAccountPrincipalID = "AZURE_INGRESS_SERVICE_ACCOUNT_PRINCIPAL_ID"
-)
-
-// paramSettingMap maps cluster setting keys to ARM template parameter names.
-var paramSettingMap = []struct{ param, setting string }{
-	{"aksSystemVmSize", "AZURE_AKS_SYSTEM_VM_SIZE"},
-	{"aksSystemVmDiskType", "AZURE_AKS_SYSTEM_VM_DISK_TYPE"},
-	{"aksSystemVmDiskSizeGB", "AZURE_AKS_SYSTEM_VM_DISK_SIZE_GB"},
-	{"aksSystemVmPriority", "AZURE_AKS_SYSTEM_VM_PRIORITY"},
-	{"aksSystemVmZones", "AZURE_AKS_SYSTEM_VM_ZONES"},
-	{"aksUserVmSize", "AZURE_AKS_USER_VM_SIZE"},
-	{"aksUserVmDiskType", "AZURE_AKS_USER_VM_DISK_TYPE"},
-	{"aksUserVmDiskSizeGB", "AZURE_AKS_USER_VM_DISK_SIZE_GB"},
-	{"aksUserVmPriority", "AZURE_AKS_USER_VM_PRIORITY"},
-	{"aksUserVmZones", "AZURE_AKS_USER_VM_ZONES"},
+// aksClusterInfo describes the provisioned cluster resources. It replaces the outputs of the
+// former ARM template deployment and is resolved from resource tags (see azureClusterInfo).
+type aksClusterInfo struct {
+	TenantID           string // Entra ID tenant of the ingress identity
+	ResourceGroup      string // cluster resource group
+	ClusterName        string // AKS managed cluster name
+	ClusterID          string // AKS managed cluster resource ID
+	ClusterPrincipalID string // system-assigned identity of the cluster (control plane)
+	KubeletPrincipalID string // kubelet identity (data plane)
+	OIDCIssuerURL      string // OIDC issuer for Workload Identity
+	IngressIdentityID  string // user-assigned identity resource ID used by the ingress controller
+	IngressClientID    string
+	IngressPrincipalID string
 }
 
-// ensureAzureDeploymentCreated creates or updates the subscription-scoped deployment for the cluster.
-// If an existing deployment succeeded and force is false, it returns without changes (idempotent).
-func (d *driver) ensureAzureDeploymentCreated(ctx context.Context, cluster *model.Cluster, resourceGroupName string, tags map[string]*string, force bool) error {
-	log := logging.FromContext(ctx)
-
-	// Unmarshal embedded ARM template (subscription scope)
-	var template map[string]any
-	if err := json.Unmarshal(mainJSON, &template); err != nil {
-		return fmt.Errorf("unmarshal embedded template: %w", err)
-	}
-
-	// Prepare ARM parameters for subscription-scoped deployment
-	parameters := map[string]any{
-		"environmentName":                map[string]any{"value": cluster.Name},
-		"location":                       map[string]any{"value": d.AzureLocation},
-		"resourceGroupName":              map[string]any{"value": resourceGroupName},
-		"ingressServiceAccountName":      map[string]any{"value": kube.IngressServiceAccountName(cluster)},
-		"ingressServiceAccountNamespace": map[string]any{"value": kube.IngressNamespace(cluster)},
-		"tags":                           map[string]any{"value": tags},
-	}
-
-	// Load parameters from cluster settings if present
-	for _, mapping := range paramSettingMap {
-		if val, exists := cluster.Settings[mapping.setting]; exists {
-			parameters[mapping.param] = map[string]any{"value": val}
-		}
-	}
-
-	// Create deployments client for subscription-scoped deployment
-	deploymentsClient, err := armresources.NewDeploymentsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+// azureClusterInfo discovers the cluster resources by the cluster hash tag and reads the
+// values consumed by other driver methods. It fails when the managed cluster does not exist.
+func (d *driver) azureClusterInfo(ctx context.Context, cluster *model.Cluster) (*aksClusterInfo, error) {
+	r, err := d.newEnsureRun(cluster, true, false)
 	if err != nil {
-		return fmt.Errorf("create deployments client: %w", err)
+		return nil, err
 	}
+	info := &aksClusterInfo{ResourceGroup: r.rg}
 
-	depName, err := d.azureDeploymentName(cluster)
+	mcID, err := r.discover(ctx, armTypeManagedCluster, d.clusterAKSName(cluster))
 	if err != nil {
-		return fmt.Errorf("derive deployment name: %w", err)
-	}
-
-	// Check if deployment already exists and is successful (idempotent unless forced)
-	if existing, err := deploymentsClient.GetAtSubscriptionScope(ctx, depName, nil); err == nil {
-		if existing.Properties != nil && existing.Properties.ProvisioningState != nil &&
-			*existing.Properties.ProvisioningState == "Succeeded" {
-			if !force {
-				log.Info(ctx, "aks cluster already provisioned",
-					"resource_group", resourceGroupName,
-					"cluster", cluster.Name,
-					"provider", d.ProviderName(),
-				)
-				return nil
-			}
-			log.Info(ctx, "force-redeploy enabled, proceeding despite existing successful deployment",
-				"deployment", depName,
-				"resource_group", resourceGroupName,
-				"cluster", cluster.Name,
-			)
-		}
-		// fallthrough: re-issue deployment to converge
-	}
-
-	// Create subscription-scoped deployment
-	deployment := armresources.Deployment{
-		Location: to.Ptr(d.AzureLocation),
-		Properties: &armresources.DeploymentProperties{
-			Template:   template,
-			Parameters: parameters,
-			Mode:       to.Ptr(armresources.DeploymentModeIncremental),
-		},
-		Tags: tags,
+		return nil, fmt.Errorf("discover managed cluster: %w", err)
 	}
-
-	poller, err := deploymentsClient.BeginCreateOrUpdateAtSubscriptionScope(ctx, depName, deployment, nil)
+	mc := &armManagedCluster{}
+	found, err := r.arm.get(ctx, mcID, armAPIManagedClusters, mc)
 	if err != nil {
-		return fmt.Errorf("begin subscription deployment creation: %w", err)
+		return nil, err
 	}
-	if _, err = poller.PollUntilDone(ctx, nil); err != nil {
-		return fmt.Errorf("subscription deployment creation failed: %w", err)
+	if !found {
+		return nil, fmt.Errorf("managed cluster not found in resource group %s", r.rg)
 	}
+	info.ClusterID = mcID
+	info.ClusterName = mc.Name
+	info.ClusterPrincipalID = mc.Identity.PrincipalID
+	info.KubeletPrincipalID = mc.kubeletObjectID()
+	info.OIDCIssuerURL = mc.Properties.OIDCIssuerProfile.IssuerURL
 
-	return nil
+	idID, err := r.discover(ctx, armTypeManagedIdentity, d.clusterIdentityName(cluster))
+	if err != nil {
+		return nil, fmt.Errorf("discover ingress identity: %w", err)
+	}
+	id := &armUserAssignedIdentity{}
+	if found, err := r.arm.get(ctx, idID, armAPIManagedIdentities, id); err != nil {
+		return nil, err
+	} else if found {
+		info.IngressIdentityID = idID
+		info.TenantID = id.Properties.TenantID
+		info.IngressClientID = id.Properties.ClientID
+		info.IngressPrincipalID = id.Properties.PrincipalID
+	}
+	return info, nil
 }
 
-// ensureAzureDeploymentDeleted best-effort deletes the subscription-scoped deployment for the cluster.
-// All errors are logged at debug level and ignored for idempotency.
+// ensureAzureDeploymentDeleted best-effort deletes the subscription-scoped deployment created
+// by drivers before the ensure workflow. All errors are logged at debug level and ignored.
 func (d *driver) ensureAzureDeploymentDeleted(ctx context.Context, cluster *model.Cluster) {
 	log := logging.FromContext(ctx)
-	depClient, err := armresources.NewDeploymentsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	depClient, err := armresources.NewDeploymentsClient(d.AzureSubscriptionId, d.TokenCredential, d.armOptions)
 	if err != nil {
 		return
 	}
@@ -135,7 +80,10 @@ func (d *driver) ensureAzureDeploymentDeleted(ctx context.Context, cluster *mode
 	if err != nil {
 		return
 	}
-	log.Info(ctx, "deleting subscription-scoped deployment (best-effort)",
+	if _, err := depClient.GetAtSubscriptionScope(ctx, depName, nil); err != nil {
+		return
+	}
+	log.Info(ctx, "deleting legacy subscription-scoped deployment (best-effort)",
 		"deployment", depName,
 		"cluster", cluster.Name,
 		"provider", d.ProviderName(),
@@ -149,69 +97,20 @@ func (d *driver) ensureAzureDeploymentDeleted(ctx context.Context, cluster *mode
 	}
 }
 
-// azureDeploymentOutputs retrieves the outputs from the subscription-scoped deployment.
-func (d *driver) azureDeploymentOutputs(ctx context.Context, cluster *model.Cluster) (map[string]any, error) {
-	deploymentsClient, err := armresources.NewDeploymentsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create deployments client: %w", err)
-	}
-
-	depName, err := d.azureDeploymentName(cluster)
-	if err != nil {
-		return nil, fmt.Errorf("derive deployment name: %w", err)
-	}
-	deployment, err := deploymentsClient.GetAtSubscriptionScope(ctx, depName, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get subscription deployment: %w", err)
-	}
-
-	if deployment.Properties == nil || deployment.Properties.Outputs == nil {
-		return nil, fmt.Errorf("deployment has no outputs")
-	}
-
-	// Type assert the outputs to the correct map type
-	outputsMap, ok := deployment.Properties.Outputs.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("deployment outputs has unexpected type")
-	}
-
-	outputs := make(map[string]any)
-	for key, value := range outputsMap {
-		if outputValue, ok := value.(map[string]any); ok {
-			if val, exists := outputValue["value"]; exists {
-				// Normalize keys to uppercase to avoid issues from accidental casing changes
-				outputs[strings.ToUpper(key)] = val
-			}
-		}
-	}
-
-	return outputs, nil
-}
-
-// azureKubeconfig retrieves the admin kubeconfig for the AKS cluster resolved from deployment outputs.
+// azureKubeconfig retrieves the admin kubeconfig for the AKS cluster resolved from resource tags.
 func (d *driver) azureKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
-	// Get outputs from deployment to resolve resource group and cluster name
-	outputs, err := d.azureDeploymentOutputs(ctx, cluster)
+	info, err := d.azureClusterInfo(ctx, cluster)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get deployment outputs: %w", err)
-	}
-
-	aksRGName, ok := outputs[outputResourceGroupName].(string)
-	if !ok {
-		return nil, fmt.Errorf("%s not found in deployment outputs", outputResourceGroupName)
-	}
-	aksName, ok := outputs[outputAksClusterName].(string)
-	if !ok {
-		return nil, fmt.Errorf("%s not found in deployment outputs", outputAksClusterName)
+		return nil, fmt.Errorf("resolve cluster resources: %w", err)
 	}
 
 	// Create AKS client and request admin credentials
-	aksClient, err := armcontainerservice.NewManagedClustersClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	aksClient, err := armcontainerservice.NewManagedClustersClient(d.AzureSubscriptionId, d.TokenCredential, d.armOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AKS client: %w", err)
 	}
 
-	credResult, err := aksClient.ListClusterAdminCredentials(ctx, aksRGName, aksName, nil)
+	credResult, err := aksClient.ListClusterAdminCredentials(ctx, info.ResourceGroup, info.ClusterName, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster credentials: %w", err)
 	}
@@ -1,13 +1,11 @@
 package aks
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/kompox/kompox/domain/model"
-	"github.com/kompox/kompox/internal/logging"
 )
 
 const (
@@ -75,24 +73,3 @@ func (d *driver) collectAzureContainerRegistryIDs(cluster *model.Cluster) ([]*az
 
 	return registries, nil
 }
-
-// ensureAzureContainerRegistryRoles assigns AcrPull role to the given principal for all specified ACR resources.
-// This is a best-effort operation: failures are logged as warnings and do not block cluster installation.
-func (d *driver) ensureAzureContainerRegistryRoles(ctx context.Context, principalID string, registries []*azureContainerRegistryInfo) {
-	if len(registries) == 0 {
-		return
-	}
-
-	// Build full role definition ID for AcrPull
-	roleDefID := d.azureRoleDefinitionID(roleDefIDAcrPull)
-
-	for _, reg := range registries {
-		// Assign AcrPull role at registry scope (best-effort)
-		logger := logging.FromContext(ctx).With("principalId", principalID, "scope", reg.ResourceID)
-		if err := d.ensureAzureRole(ctx, reg.ResourceID, principalID, roleDefID); err != nil {
-			logger.Info(ctx, "AKS:RoleCR/efail", "err", err)
-		} else {
-			logger.Info(ctx, "AKS:RoleCR/eok")
-		}
-	}
-}
@@ -273,30 +273,3 @@ func (d *driver) deleteAzureDNSRecord(ctx context.Context, zone *azureDNSZoneInf
 
 	return nil
 }
-
-// ensureAzureDNSZoneRoles assigns DNS Zone Contributor role to the specified principal for all configured DNS zones.
-// This is a best-effort operation: failures are logged as warnings and do not fail the overall operation.
-func (d *driver) ensureAzureDNSZoneRoles(ctx context.Context, principalID string, zones []*azureDNSZoneInfo) {
-	log := logging.FromContext(ctx)
-
-	if principalID == "" {
-		log.Warn(ctx, "ensureAzureDNSZoneRoles: principalID is empty, skipping DNS role assignments")
-		return
-	}
-
-	if len(zones) == 0 {
-		log.Debug(ctx, "ensureAzureDNSZoneRoles: no DNS zones configured, skipping DNS role assignments")
-		return
-	}
-
-	roleDefID := d.azureRoleDefinitionID(roleDefIDDNSZoneContributor)
-
-	for _, zone := range zones {
-		logger := logging.FromContext(ctx).With("principalId", principalID, "scope", zone.ResourceID)
-		if err := d.ensureAzureRole(ctx, zone.ResourceID, principalID, roleDefID); err != nil {
-			logger.Info(ctx, "AKS:RoleDNS/efail", "err", err)
-		} else {
-			logger.Info(ctx, "AKS:RoleDNS/eok")
-		}
-	}
-}
@@ -0,0 +1,871 @@
+package aks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/uuid"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// ensureAction is the outcome of an ensure step.
+type ensureAction string
+
+const (
+	ensurePlanned   ensureAction = "planned"   // change required but not applied (plan mode)
+	ensureCreated   ensureAction = "created"   // resource did not exist and was created
+	ensureUpdated   ensureAction = "updated"   // resource existed and was converged to the desired state
+	ensureUnchanged ensureAction = "unchanged" // resource already matched the desired state
+)
+
+// ensureResult reports the outcome of one ensure step.
+type ensureResult struct {
+	Step     string                     // step name (e.g., "ManagedCluster", "RoleDNS")
+	ID       string                     // ARM resource ID; the scope for role assignments
+	Action   ensureAction               // outcome
+	Existing bool                       // the resource existed before the step
+	Changes  []model.ClusterChangeField // differences from the desired state (for planned/updated)
+}
+
+// Resource types discovered in the cluster resource group.
+const (
+	armTypeLogAnalytics    = "Microsoft.OperationalInsights/workspaces"
+	armTypeStorageAccount  = "Microsoft.Storage/storageAccounts"
+	armTypeManagedIdentity = "Microsoft.ManagedIdentity/userAssignedIdentities"
+	armTypeManagedCluster  = "Microsoft.ContainerService/managedClusters"
+)
+
+// Fixed names of child resources.
+const (
+	ingressFederatedCredentialName = "fic-ingress"
+	diagnosticSettingsName         = "diagnostics"
+	workloadIdentityAudience       = "api://AzureADTokenExchange"
+)
+
+// Managed cluster defaults (cluster settings override the node pool values).
+const (
+	defaultKubernetesVersion = "1.33"
+	defaultNodePoolMaxPods   = 250
+)
+
+// AKS RBAC Cluster Admin - granted to the identity running ClusterProvision.
+const roleDefIDAKSRBACClusterAdmin = "b1ff04bb-8a4e-4dc4-8eb5-8693973ce19b"
+
+// roleAssignmentRetries bounds retries while a new principal propagates in Entra ID.
+const roleAssignmentRetries = 6
+
+// ensureRun carries the state shared by the ensure steps of one run.
+type ensureRun struct {
+	d       *driver
+	arm     *armClient
+	cluster *model.Cluster
+	rg      string
+	tags    map[string]string
+	plan    bool // report planned changes without mutating
+	force   bool // re-apply the desired state of existing resources
+	results []ensureResult
+
+	// owned maps lower-cased resource types to resources tagged with the cluster hash.
+	owned map[string]armResource
+
+	// Values resolved by earlier steps and consumed by later ones.
+	logAnalyticsID   string
+	storageID        string
+	identity         *armUserAssignedIdentity
+	managedCluster   *armManagedCluster
+	managedClusterID string
+}
+
+// newEnsureRun prepares an ensure run for the cluster.
+func (d *driver) newEnsureRun(cluster *model.Cluster, plan, force bool) (*ensureRun, error) {
+	rg, err := d.clusterResourceGroupName(cluster)
+	if err != nil || rg == "" {
+		return nil, fmt.Errorf("derive cluster resource group: %w", err)
+	}
+	c, err := d.newARMClient()
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for k, v := range d.clusterResourceTags(cluster.Name) {
+		if v != nil {
+			tags[k] = *v
+		}
+	}
+	return &ensureRun{d: d, arm: c, cluster: cluster, rg: rg, tags: tags, plan: plan, force: force}, nil
+}
+
+// ensureAKSClusterResources converges all cloud resources of the cluster in dependency order,
+// including the role assignments for Key Vault, DNS zones and ACR.
+func (d *driver) ensureAKSClusterResources(ctx context.Context, cluster *model.Cluster, plan, force bool) ([]ensureResult, error) {
+	r, err := d.newEnsureRun(cluster, plan, force)
+	if err != nil {
+		return nil, err
+	}
+	steps := []func(context.Context) error{
+		r.ensureAKSResourceGroupCreated,
+		r.ensureAKSLogAnalyticsWorkspaceCreated,
+		r.ensureAKSStorageAccountCreated,
+		r.ensureAKSUserAssignedIdentityCreated,
+		r.ensureAKSManagedClusterCreated,
+		r.ensureAKSManagedClusterDiagnosticsConfigured,
+		r.ensureAKSFederatedIdentityCredentialCreated,
+		r.ensureAKSClusterAdminRole,
+		r.ensureAKSAccessRoles,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return r.results, err
+		}
+	}
+	return r.results, nil
+}
+
+// record appends the result and logs it as AKS:Ensure<Step> with the action.
+func (r *ensureRun) record(ctx context.Context, res ensureResult) {
+	r.results = append(r.results, res)
+	paths := make([]string, 0, len(res.Changes))
+	for _, c := range res.Changes {
+		paths = append(paths, c.Path)
+	}
+	logging.FromContext(ctx).Info(ctx, "AKS:Ensure"+res.Step, "action", string(res.Action), "id", res.ID, "changes", paths)
+}
+
+// converge decides the action for a resource and applies it unless in plan mode.
+// create is called for missing resources and update for existing resources with changes
+// (or any existing resource when force is set).
+func (r *ensureRun) converge(ctx context.Context, step, id string, found bool, changes []model.ClusterChangeField, create, update func() error) error {
+	res := ensureResult{Step: step, ID: id, Existing: found, Changes: changes}
+	var apply func() error
+	switch {
+	case !found:
+		res.Action, apply = ensureCreated, create
+	case len(changes) > 0 || r.force:
+		res.Action, apply = ensureUpdated, update
+	default:
+		res.Action = ensureUnchanged
+	}
+	if apply != nil {
+		if r.plan {
+			res.Action = ensurePlanned
+		} else if err := apply(); err != nil {
+			return fmt.Errorf("ensure %s: %w", step, err)
+		}
+	}
+	r.record(ctx, res)
+	return nil
+}
+
+// resourceGroupID returns the ARM ID of the cluster resource group.
+func (r *ensureRun) resourceGroupID() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", r.d.AzureSubscriptionId, r.rg)
+}
+
+// tagChanges returns the tags whose values differ from the desired cluster tags.
+func (r *ensureRun) tagChanges(current map[string]string) []model.ClusterChangeField {
+	var changes []model.ClusterChangeField
+	for k, v := range r.tags {
+		if cur, ok := current[k]; !ok || cur != v {
+			f := model.ClusterChangeField{Path: "tags." + k, After: v}
+			if ok {
+				f.Before = cur
+			}
+			changes = append(changes, f)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// mergedTags returns current tags overlaid with the desired cluster tags.
+func (r *ensureRun) mergedTags(current map[string]string) map[string]string {
+	out := make(map[string]string, len(current)+len(r.tags))
+	for k, v := range current {
+		out[k] = v
+	}
+	for k, v := range r.tags {
+		out[k] = v
+	}
+	return out
+}
+
+// patchTags updates only the tags of an existing resource.
+func (r *ensureRun) patchTags(ctx context.Context, id, apiVersion string, current map[string]string) error {
+	return r.arm.patch(ctx, id, apiVersion, map[string]any{"tags": r.mergedTags(current)}, nil)
+}
+
+// discover returns the ID of the resource of the given type owned by the cluster.
+// Resource tags are the source of truth: a resource carrying the cluster hash tag is
+// adopted regardless of its name (e.g., resources created by the former ARM template).
+// Otherwise the deterministic default name is used.
+func (r *ensureRun) discover(ctx context.Context, resourceType, defaultName string) (string, error) {
+	if r.owned == nil {
+		owned, err := r.d.ownedClusterResources(ctx, r.arm, r.rg, r.cluster)
+		if err != nil {
+			return "", err
+		}
+		r.owned = owned
+	}
+	if res, ok := r.owned[strings.ToLower(resourceType)]; ok {
+		return res.ID, nil
+	}
+	return fmt.Sprintf("%s/providers/%s/%s", r.resourceGroupID(), resourceType, defaultName), nil
+}
+
+// ownedClusterResources lists resources in the cluster resource group tagged with the
+// cluster hash, keyed by lower-cased resource type. When several resources of a type
+// match, the one with the smallest name wins so that the choice is stable.
+// A missing resource group yields an empty map.
+func (d *driver) ownedClusterResources(ctx context.Context, c *armClient, rg string, cluster *model.Cluster) (map[string]armResource, error) {
+	hash := *d.clusterResourceTags(cluster.Name)[tagClusterHash]
+	path := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/resources", d.AzureSubscriptionId, rg)
+	owned := map[string]armResource{}
+	err := c.list(ctx, path, armAPIResources, nil, func(raw json.RawMessage) error {
+		var res armResource
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return err
+		}
+		if res.Tags[tagClusterHash] != hash {
+			return nil
+		}
+		key := strings.ToLower(res.Type)
+		if cur, ok := owned[key]; !ok || res.Name < cur.Name {
+			owned[key] = res
+		}
+		return nil
+	})
+	if isNotFoundError(err) {
+		return owned, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+// ensureAKSResourceGroupCreated ensures the cluster resource group exists with the cluster tags.
+func (r *ensureRun) ensureAKSResourceGroupCreated(ctx context.Context) error {
+	id := r.resourceGroupID()
+	var cur armResource
+	found, err := r.arm.get(ctx, id, armAPIResourceGroups, &cur)
+	if err != nil {
+		return err
+	}
+	create := func() error {
+		return r.arm.put(ctx, id, armAPIResourceGroups, armResource{Location: r.d.AzureLocation, Tags: r.tags}, nil)
+	}
+	update := func() error { return r.patchTags(ctx, id, armAPIResourceGroups, cur.Tags) }
+	return r.converge(ctx, "ResourceGroup", id, found, r.tagChanges(cur.Tags), create, update)
+}
+
+// ensureAKSLogAnalyticsWorkspaceCreated ensures the Log Analytics workspace used by Container Insights.
+func (r *ensureRun) ensureAKSLogAnalyticsWorkspaceCreated(ctx context.Context) error {
+	id, err := r.discover(ctx, armTypeLogAnalytics, r.d.clusterLogAnalyticsName(r.cluster))
+	if err != nil {
+		return err
+	}
+	r.logAnalyticsID = id
+	var cur armResource
+	found, err := r.arm.get(ctx, id, armAPILogAnalytics, &cur)
+	if err != nil {
+		return err
+	}
+	create := func() error {
+		body := map[string]any{
+			"location": r.d.AzureLocation,
+			"tags":     r.tags,
+			"properties": map[string]any{
+				"retentionInDays": 30,
+				"features":        map[string]any{"searchVersion": 1},
+				"sku":             map[string]any{"name": "PerGB2018"},
+			},
+		}
+		return r.arm.put(ctx, id, armAPILogAnalytics, body, nil)
+	}
+	update := func() error { return r.patchTags(ctx, id, armAPILogAnalytics, cur.Tags) }
+	return r.converge(ctx, "LogAnalytics", id, found, r.tagChanges(cur.Tags), create, update)
+}
+
+// ensureAKSStorageAccountCreated ensures the storage account receiving diagnostic logs.
+func (r *ensureRun) ensureAKSStorageAccountCreated(ctx context.Context) error {
+	id, err := r.discover(ctx, armTypeStorageAccount, r.d.clusterStorageAccountName(r.cluster))
+	if err != nil {
+		return err
+	}
+	r.storageID = id
+	var cur armResource
+	found, err := r.arm.get(ctx, id, armAPIStorageAccounts, &cur)
+	if err != nil {
+		return err
+	}
+	create := func() error {
+		body := map[string]any{
+			"location": r.d.AzureLocation,
+			"tags":     r.tags,
+			"kind":     "StorageV2",
+			"sku":      map[string]any{"name": "Standard_LRS"},
+			"properties": map[string]any{
+				"accessTier":               "Hot",
+				"allowBlobPublicAccess":    false,
+				"minimumTlsVersion":        "TLS1_2",
+				"supportsHttpsTrafficOnly": true,
+			},
+		}
+		return r.arm.put(ctx, id, armAPIStorageAccounts, body, nil)
+	}
+	update := func() error { return r.patchTags(ctx, id, armAPIStorageAccounts, cur.Tags) }
+	return r.converge(ctx, "StorageAccount", id, found, r.tagChanges(cur.Tags), create, update)
+}
+
+// armUserAssignedIdentity is the subset of a user-assigned managed identity used by the driver.
+type armUserAssignedIdentity struct {
+	armResource
+	Properties struct {
+		TenantID    string `json:"tenantId,omitempty"`
+		PrincipalID string `json:"principalId,omitempty"`
+		ClientID    string `json:"clientId,omitempty"`
+	} `json:"properties"`
+}
+
+// ensureAKSUserAssignedIdentityCreated ensures the identity used by the ingress controller
+// through Workload Identity.
+func (r *ensureRun) ensureAKSUserAssignedIdentityCreated(ctx context.Context) error {
+	id, err := r.discover(ctx, armTypeManagedIdentity, r.d.clusterIdentityName(r.cluster))
+	if err != nil {
+		return err
+	}
+	cur := &armUserAssignedIdentity{}
+	found, err := r.arm.get(ctx, id, armAPIManagedIdentities, cur)
+	if err != nil {
+		return err
+	}
+	create := func() error {
+		return r.arm.put(ctx, id, armAPIManagedIdentities, armResource{Location: r.d.AzureLocation, Tags: r.tags}, cur)
+	}
+	update := func() error { return r.patchTags(ctx, id, armAPIManagedIdentities, cur.Tags) }
+	if err := r.converge(ctx, "UserAssignedIdentity", id, found, r.tagChanges(cur.Tags), create, update); err != nil {
+		return err
+	}
+	cur.ID = id
+	r.identity = cur
+	return nil
+}
+
+// armManagedCluster is the subset of an AKS managed cluster used by the driver.
+type armManagedCluster struct {
+	armResource
+	Identity struct {
+		PrincipalID string `json:"principalId,omitempty"`
+		TenantID    string `json:"tenantId,omitempty"`
+	} `json:"identity"`
+	Properties struct {
+		KubernetesVersion string                     `json:"kubernetesVersion,omitempty"`
+		IdentityProfile   map[string]armUserIdentity `json:"identityProfile,omitempty"`
+		OIDCIssuerProfile struct {
+			Enabled   bool   `json:"enabled"`
+			IssuerURL string `json:"issuerURL,omitempty"`
+		} `json:"oidcIssuerProfile"`
+	} `json:"properties"`
+}
+
+// armUserIdentity is an identity entry of managedCluster.properties.identityProfile.
+type armUserIdentity struct {
+	ObjectID string `json:"objectId,omitempty"`
+}
+
+// kubeletObjectID returns the object ID of the kubelet identity.
+func (mc *armManagedCluster) kubeletObjectID() string {
+	return mc.Properties.IdentityProfile["kubeletidentity"].ObjectID
+}
+
+// ensureAKSManagedClusterCreated ensures the AKS managed cluster. New clusters get the full
+// desired spec. For existing clusters the driver-managed fields (tags, OIDC issuer, Workload
+// Identity, Key Vault CSI addon) are compared: tag-only drift is repaired with PATCH, other
+// changes with a PUT of the desired spec built by updatedManagedCluster.
+func (r *ensureRun) ensureAKSManagedClusterCreated(ctx context.Context) error {
+	id, err := r.discover(ctx, armTypeManagedCluster, r.d.clusterAKSName(r.cluster))
+	if err != nil {
+		return err
+	}
+	r.managedClusterID = id
+	var raw json.RawMessage
+	found, err := r.arm.get(ctx, id, armAPIManagedClusters, &raw)
+	if err != nil {
+		return err
+	}
+	var current map[string]any
+	var changes []model.ClusterChangeField
+	if found {
+		if err := json.Unmarshal(raw, &current); err != nil {
+			return fmt.Errorf("decode %s: %w", id, err)
+		}
+		changes = r.managedClusterChanges(current)
+	}
+	cur := &armManagedCluster{}
+	create := func() error {
+		body, err := r.desiredManagedCluster()
+		if err != nil {
+			return err
+		}
+		return r.arm.put(ctx, id, armAPIManagedClusters, body, cur)
+	}
+	update := func() error {
+		tagsOnly := !r.force
+		for _, c := range changes {
+			if !strings.HasPrefix(c.Path, "tags.") {
+				tagsOnly = false
+			}
+		}
+		if tagsOnly {
+			return r.patchTags(ctx, id, armAPIManagedClusters, stringMap(current["tags"]))
+		}
+		body, err := r.updatedManagedCluster(current)
+		if err != nil {
+			return err
+		}
+		return r.arm.put(ctx, id, armAPIManagedClusters, body, cur)
+	}
+	if err := r.converge(ctx, "ManagedCluster", id, found, changes, create, update); err != nil {
+		return err
+	}
+	if found && cur.ID == "" {
+		if err := json.Unmarshal(raw, cur); err != nil {
+			return fmt.Errorf("decode %s: %w", id, err)
+		}
+	}
+	if cur.ID != "" {
+		r.managedCluster = cur
+	}
+	return nil
+}
+
+// managedClusterChanges returns the driver-managed fields of the existing cluster document
+// that differ from the desired state.
+func (r *ensureRun) managedClusterChanges(mc map[string]any) []model.ClusterChangeField {
+	changes := r.tagChanges(stringMap(mc["tags"]))
+	check := func(path string, value any) {
+		var cur any = mc
+		for _, k := range strings.Split(path, ".") {
+			m, _ := cur.(map[string]any)
+			cur = m[k]
+		}
+		if cur != value {
+			changes = append(changes, model.ClusterChangeField{Path: path, Before: cur, After: value})
+		}
+	}
+	check("properties.oidcIssuerProfile.enabled", true)
+	check("properties.securityProfile.workloadIdentity.enabled", true)
+	check("properties.addonProfiles.azureKeyvaultSecretsProvider.enabled", true)
+	return changes
+}
+
+// managedClusterKeptProperties are properties of an existing cluster that the update keeps
+// instead of the desired spec: they are fixed at creation, upgraded separately or, for the
+// agent pools, owned by the NodePool operations.
+var managedClusterKeptProperties = []string{"kubernetesVersion", "nodeResourceGroup", "dnsPrefix", "networkProfile", "agentPoolProfiles"}
+
+// agentPoolReadOnlyProperties are read-only fields of agent pool profiles dropped before a PUT.
+var agentPoolReadOnlyProperties = []string{"provisioningState", "powerState", "currentOrchestratorVersion", "nodeImageVersion", "eTag"}
+
+// updatedManagedCluster builds the PUT body for an existing cluster from the desired spec.
+// The SKU, existing tags and managedClusterKeptProperties are taken from the current
+// document; read-only fields of the current document are never sent back.
+func (r *ensureRun) updatedManagedCluster(current map[string]any) (map[string]any, error) {
+	body, err := r.desiredManagedCluster()
+	if err != nil {
+		return nil, err
+	}
+	body["tags"] = r.mergedTags(stringMap(current["tags"]))
+	if sku, ok := current["sku"]; ok {
+		body["sku"] = sku
+	}
+	props := body["properties"].(map[string]any)
+	curProps, _ := current["properties"].(map[string]any)
+	for _, k := range managedClusterKeptProperties {
+		if v, ok := curProps[k]; ok {
+			props[k] = v
+		}
+	}
+	if pools, ok := props["agentPoolProfiles"].([]any); ok {
+		for _, p := range pools {
+			if pool, ok := p.(map[string]any); ok {
+				for _, k := range agentPoolReadOnlyProperties {
+					delete(pool, k)
+				}
+			}
+		}
+	}
+	return body, nil
+}
+
+// stringMap converts a decoded JSON object of strings (e.g., resource tags) to a map.
+func stringMap(v any) map[string]string {
+	out := map[string]string{}
+	if m, ok := v.(map[string]any); ok {
+		for k, v := range m {
+			out[k], _ = v.(string)
+		}
+	}
+	return out
+}
+
+// desiredManagedCluster builds the managed cluster body for creation from cluster settings.
+func (r *ensureRun) desiredManagedCluster() (map[string]any, error) {
+	setting := func(key, def string) string {
+		if v := strings.TrimSpace(r.cluster.Settings[key]); v != "" {
+			return v
+		}
+		return def
+	}
+	// pool builds an agent pool profile from the AZURE_AKS_{SYSTEM,USER}_VM_* settings and
+	// returns it with the zones listed in <prefix>_VM_ZONES (defaultZones when unset).
+	pool := func(prefix, defaultZones string) (map[string]any, []string, error) {
+		size := setting(prefix+"_VM_DISK_SIZE_GB", "110")
+		sizeGB, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s_VM_DISK_SIZE_GB: %q", prefix, size)
+		}
+		var zones []string
+		for z := range strings.SplitSeq(setting(prefix+"_VM_ZONES", defaultZones), ",") {
+			if z = strings.TrimSpace(z); z != "" {
+				zones = append(zones, z)
+			}
+		}
+		p := map[string]any{
+			"vmSize":            setting(prefix+"_VM_SIZE", "Standard_D2lds_v6"),
+			"osType":            "Linux",
+			"osDiskType":        setting(prefix+"_VM_DISK_TYPE", "Ephemeral"),
+			"osDiskSizeGB":      sizeGB,
+			"maxPods":           defaultNodePoolMaxPods,
+			"type":              "VirtualMachineScaleSets",
+			"enableAutoScaling": true,
+			"scaleSetPriority":  setting(prefix+"_VM_PRIORITY", "Regular"),
+			"count":             1,
+		}
+		return p, zones, nil
+	}
+
+	system, systemZones, err := pool("AZURE_AKS_SYSTEM", "")
+	if err != nil {
+		return nil, err
+	}
+	system["name"] = "npsystem"
+	system["mode"] = "System"
+	system["minCount"], system["maxCount"] = 1, 3
+	system["availabilityZones"] = systemZones
+	system["upgradeSettings"] = map[string]any{"maxSurge": "33%"}
+	system["nodeLabels"] = map[string]any{labelNodePool: "system"}
+	profiles := []any{system}
+
+	// One user pool per zone so that the cluster autoscaler can scale zones independently.
+	_, userZones, err := pool("AZURE_AKS_USER", "1,2,3")
+	if err != nil {
+		return nil, err
+	}
+	for _, zone := range userZones {
+		user, _, _ := pool("AZURE_AKS_USER", "")
+		user["name"] = "npuser" + zone
+		user["mode"] = "User"
+		user["minCount"], user["maxCount"] = 0, 10
+		user["availabilityZones"] = []string{zone}
+		user["nodeLabels"] = map[string]any{labelNodePool: "user", labelNodeZone: zone}
+		profiles = append(profiles, user)
+	}
+
+	tenantID := ""
+	if r.identity != nil {
+		tenantID = r.identity.Properties.TenantID
+	}
+	name := r.managedClusterID[strings.LastIndex(r.managedClusterID, "/")+1:]
+	return map[string]any{
+		"location": r.d.AzureLocation,
+		"tags":     r.tags,
+		"identity": map[string]any{"type": "SystemAssigned"},
+		"sku":      map[string]any{"name": "Base", "tier": "Free"},
+		"properties": map[string]any{
+			"nodeResourceGroup": r.rg + "_mc",
+			"kubernetesVersion": setting("AZURE_AKS_KUBERNETES_VERSION", defaultKubernetesVersion),
+			"dnsPrefix":         name + "-dns",
+			"enableRBAC":        true,
+			"aadProfile":        map[string]any{"managed": true, "enableAzureRBAC": true, "tenantID": tenantID},
+			"agentPoolProfiles": profiles,
+			"networkProfile": map[string]any{
+				"loadBalancerSku":   "standard",
+				"networkPlugin":     "azure",
+				"networkPluginMode": "overlay",
+				"networkPolicy":     "azure",
+			},
+			"addonProfiles": map[string]any{
+				"azurepolicy": map[string]any{"enabled": true, "config": map[string]any{"version": "v2"}},
+				"azureKeyvaultSecretsProvider": map[string]any{
+					"enabled": true,
+					"config":  map[string]any{"enableSecretRotation": "true", "rotationPollInterval": "2m"},
+				},
+				"omsagent": map[string]any{
+					"enabled": true,
+					"config":  map[string]any{"logAnalyticsWorkspaceResourceID": r.logAnalyticsID},
+				},
+			},
+			"ingressProfile":    map[string]any{"webAppRouting": map[string]any{"enabled": false}},
+			"oidcIssuerProfile": map[string]any{"enabled": true},
+			"securityProfile":   map[string]any{"workloadIdentity": map[string]any{"enabled": true}},
+		},
+	}, nil
+}
+
+// ensureAKSManagedClusterDiagnosticsConfigured ensures the cluster diagnostic settings export
+// autoscaler/guard logs and metrics to the storage account.
+func (r *ensureRun) ensureAKSManagedClusterDiagnosticsConfigured(ctx context.Context) error {
+	id := r.managedClusterID + "/providers/Microsoft.Insights/diagnosticSettings/" + diagnosticSettingsName
+	var cur struct {
+		Properties struct {
+			StorageAccountID string `json:"storageAccountId"`
+		} `json:"properties"`
+	}
+	found, err := r.arm.get(ctx, id, armAPIDiagnosticSettings, &cur)
+	if err != nil {
+		return err
+	}
+	var changes []model.ClusterChangeField
+	if found && !strings.EqualFold(cur.Properties.StorageAccountID, r.storageID) {
+		changes = append(changes, model.ClusterChangeField{Path: "properties.storageAccountId", Before: cur.Properties.StorageAccountID, After: r.storageID})
+	}
+	apply := func() error {
+		body := map[string]any{
+			"properties": map[string]any{
+				"storageAccountId": r.storageID,
+				"logs": []any{
+					map[string]any{"category": "cluster-autoscaler", "enabled": true},
+					map[string]any{"category": "guard", "enabled": true},
+				},
+				"metrics": []any{map[string]any{"category": "AllMetrics", "enabled": true}},
+			},
+		}
+		return r.arm.put(ctx, id, armAPIDiagnosticSettings, body, nil)
+	}
+	return r.converge(ctx, "Diagnostics", id, found, changes, apply, apply)
+}
+
+// ensureAKSFederatedIdentityCredentialCreated ensures the federated credential binding the
+// ingress ServiceAccount to the user-assigned identity through the cluster OIDC issuer.
+func (r *ensureRun) ensureAKSFederatedIdentityCredentialCreated(ctx context.Context) error {
+	identityID := ""
+	if r.identity != nil {
+		identityID = r.identity.ID
+	}
+	if identityID == "" {
+		identityID, _ = r.discover(ctx, armTypeManagedIdentity, r.d.clusterIdentityName(r.cluster))
+	}
+	issuer := ""
+	if r.managedCluster != nil {
+		issuer = r.managedCluster.Properties.OIDCIssuerProfile.IssuerURL
+	}
+	subject := fmt.Sprintf("system:serviceaccount:%s:%s", kube.IngressNamespace(r.cluster), kube.IngressServiceAccountName(r.cluster))
+	return r.ensureFederatedCredential(ctx, "FederatedIdentityCredential", identityID+"/federatedIdentityCredentials/"+ingressFederatedCredentialName, issuer, subject)
+}
+
+// ensureFederatedCredential ensures the federated identity credential id trusting tokens of
+// subject issued by issuer (the cluster OIDC issuer).
+func (r *ensureRun) ensureFederatedCredential(ctx context.Context, step, id, issuer, subject string) error {
+	var cur struct {
+		Properties struct {
+			Issuer    string   `json:"issuer"`
+			Subject   string   `json:"subject"`
+			Audiences []string `json:"audiences"`
+		} `json:"properties"`
+	}
+	found, err := r.arm.get(ctx, id, armAPIManagedIdentities, &cur)
+	if err != nil {
+		return err
+	}
+	var changes []model.ClusterChangeField
+	if found {
+		if issuer != "" && cur.Properties.Issuer != issuer {
+			changes = append(changes, model.ClusterChangeField{Path: "properties.issuer", Before: cur.Properties.Issuer, After: issuer})
+		}
+		if cur.Properties.Subject != subject {
+			changes = append(changes, model.ClusterChangeField{Path: "properties.subject", Before: cur.Properties.Subject, After: subject})
+		}
+		if audiences := []string{workloadIdentityAudience}; !slices.Equal(cur.Properties.Audiences, audiences) {
+			changes = append(changes, model.ClusterChangeField{Path: "properties.audiences", Before: cur.Properties.Audiences, After: audiences})
+		}
+	}
+	apply := func() error {
+		if issuer == "" {
+			return fmt.Errorf("OIDC issuer URL of the managed cluster is not available")
+		}
+		body := map[string]any{
+			"properties": map[string]any{
+				"issuer":    issuer,
+				"subject":   subject,
+				"audiences": []string{workloadIdentityAudience},
+			},
+		}
+		return r.arm.put(ctx, id, armAPIManagedIdentities, body, nil)
+	}
+	return r.converge(ctx, step, id, found, changes, apply, apply)
+}
+
+// ensureAKSClusterAdminRole grants AKS RBAC Cluster Admin on the managed cluster to the
+// identity running the provisioning, as the former ARM template did for the deployer.
+func (r *ensureRun) ensureAKSClusterAdminRole(ctx context.Context) error {
+	principalID, err := r.d.callerObjectID(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn(ctx, "caller object ID unavailable, skipping cluster admin role assignment", "error", err.Error())
+		return nil
+	}
+	return r.ensureAKSRoleAssignment(ctx, "RoleClusterAdmin", r.managedClusterID, principalID, roleDefIDAKSRBACClusterAdmin, "")
+}
+
+// ensureAKSAccessRoles grants the cluster identities access to external resources configured
+// for the cluster: Key Vault secrets (ingress identity), DNS zones (cluster identity) and
+// container registries (kubelet identity). The first failure stops the run.
+func (r *ensureRun) ensureAKSAccessRoles(ctx context.Context) error {
+	if r.identity != nil && r.identity.Properties.PrincipalID != "" {
+		scopes, err := r.d.keyVaultSecretScopes(ctx, r.cluster)
+		if err != nil {
+			return fmt.Errorf("ensure RoleKV: %w", err)
+		}
+		for _, scope := range scopes {
+			if err := r.ensureAKSRoleAssignment(ctx, "RoleKV", scope, r.identity.Properties.PrincipalID, roleDefIDKeyVaultSecretsUser, principalTypeServicePrincipal); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.managedCluster != nil && r.managedCluster.Identity.PrincipalID != "" {
+		zones, err := r.d.collectDNSZoneIDs(r.cluster)
+		if err != nil {
+			return fmt.Errorf("ensure RoleDNS: %w", err)
+		}
+		for _, zone := range zones {
+			if err := r.ensureAKSRoleAssignment(ctx, "RoleDNS", zone.ResourceID, r.managedCluster.Identity.PrincipalID, roleDefIDDNSZoneContributor, principalTypeServicePrincipal); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.managedCluster != nil && r.managedCluster.kubeletObjectID() != "" {
+		registries, err := r.d.collectAzureContainerRegistryIDs(r.cluster)
+		if err != nil {
+			return fmt.Errorf("ensure RoleCR: %w", err)
+		}
+		for _, reg := range registries {
+			if err := r.ensureAKSRoleAssignment(ctx, "RoleCR", reg.ResourceID, r.managedCluster.kubeletObjectID(), roleDefIDAcrPull, principalTypeServicePrincipal); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// principalTypeServicePrincipal is the principal type of managed identities.
+const principalTypeServicePrincipal = "ServicePrincipal"
+
+// ensureAKSRoleAssignment ensures principalID holds the role at scope (directly or inherited).
+// New assignments get a deterministic name derived from (scope, principal, role).
+// principalType may be empty when unknown (e.g., the caller identity).
+func (r *ensureRun) ensureAKSRoleAssignment(ctx context.Context, step, scope, principalID, roleDefID, principalType string) error {
+	roleDefinitionID := r.d.azureRoleDefinitionID(roleDefID)
+	found, err := r.hasRoleAssignment(ctx, scope, principalID, roleDefinitionID)
+	if err != nil {
+		return fmt.Errorf("ensure %s: %w", step, err)
+	}
+	name := uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.ToLower(scope+"|"+principalID+"|"+roleDefID))).String()
+	id := scope + "/providers/Microsoft.Authorization/roleAssignments/" + name
+	create := func() error {
+		props := map[string]any{"roleDefinitionId": roleDefinitionID, "principalId": principalID}
+		if principalType != "" {
+			props["principalType"] = principalType
+		}
+		for attempt := 1; ; attempt++ {
+			err := r.arm.put(ctx, id, armAPIRoleAssignments, map[string]any{"properties": props}, nil)
+			if err == nil || isConflictError(err) {
+				return nil
+			}
+			if !isPrincipalNotFoundError(err) || attempt == roleAssignmentRetries {
+				return err
+			}
+			// A principal created moments ago may not be replicated in Entra ID yet.
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(armPollFrequency):
+			}
+		}
+	}
+	// Role assignments have no updatable fields; force does not re-create them.
+	if found {
+		r.record(ctx, ensureResult{Step: step, ID: scope, Action: ensureUnchanged, Existing: true})
+		return nil
+	}
+	return r.converge(ctx, step, scope, false, nil, create, nil)
+}
+
+// hasRoleAssignment reports whether principalID is assigned the role at scope or above.
+func (r *ensureRun) hasRoleAssignment(ctx context.Context, scope, principalID, roleDefinitionID string) (bool, error) {
+	query := url.Values{"$filter": {fmt.Sprintf("principalId eq '%s'", principalID)}}
+	found := false
+	err := r.arm.list(ctx, scope+"/providers/Microsoft.Authorization/roleAssignments", armAPIRoleAssignments, query, func(raw json.RawMessage) error {
+		var ra struct {
+			Properties struct {
+				RoleDefinitionID string `json:"roleDefinitionId"`
+				Scope            string `json:"scope"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(raw, &ra); err != nil {
+			return err
+		}
+		s := strings.ToLower(ra.Properties.Scope)
+		if strings.EqualFold(ra.Properties.RoleDefinitionID, roleDefinitionID) &&
+			(s == "/" || strings.HasPrefix(strings.ToLower(scope)+"/", strings.TrimSuffix(s, "/")+"/")) {
+			found = true
+		}
+		return nil
+	})
+	if isNotFoundError(err) {
+		// The scope itself does not exist yet (plan mode before creation).
+		return false, nil
+	}
+	return found, err
+}
+
+// isPrincipalNotFoundError reports whether ARM rejected a role assignment because the
+// principal does not exist (yet) in the directory.
+func isPrincipalNotFoundError(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.ErrorCode == "PrincipalNotFound"
+}
+
+// ensureAKSAccessRolesFromInfo runs the access role steps for an already provisioned cluster
+// (ClusterInstall), so that references added to the cluster spec later are granted as well.
+func (d *driver) ensureAKSAccessRolesFromInfo(ctx context.Context, cluster *model.Cluster, info *aksClusterInfo, plan bool) ([]ensureResult, error) {
+	r, err := d.newEnsureRun(cluster, plan, false)
+	if err != nil {
+		return nil, err
+	}
+	r.identity = &armUserAssignedIdentity{}
+	r.identity.ID = info.IngressIdentityID
+	r.identity.Properties.PrincipalID = info.IngressPrincipalID
+	r.managedCluster = &armManagedCluster{}
+	r.managedCluster.ID = info.ClusterID
+	r.managedCluster.Identity.PrincipalID = info.ClusterPrincipalID
+	if info.KubeletPrincipalID != "" {
+		r.managedCluster.Properties.IdentityProfile = map[string]armUserIdentity{"kubeletidentity": {ObjectID: info.KubeletPrincipalID}}
+	}
+	if err := r.ensureAKSAccessRoles(ctx); err != nil {
+		return r.results, err
+	}
+	return r.results, nil
+}
@@ -0,0 +1,380 @@
+package aks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// fakeARM is a tiny in-memory ARM control plane for ensure workflow tests. Every write
+// completes synchronously (provisioningState Succeeded) and server-generated identity
+// properties are filled in.
+type fakeARM struct {
+	mu        sync.Mutex
+	resources map[string]map[string]any // lower-cased ID -> resource document
+	writes    []string                  // "METHOD id" of mutating requests
+}
+
+func (f *fakeARM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := r.URL.Path
+	key := strings.ToLower(id)
+	reply := func(status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	notFound := func() {
+		reply(http.StatusNotFound, map[string]any{"error": map[string]any{"code": "ResourceNotFound", "message": id}})
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(key, "/resources"):
+		rg := strings.TrimSuffix(key, "/resources")
+		if _, ok := f.resources[rg]; !ok {
+			notFound()
+			return
+		}
+		var items []any
+		for k, res := range f.resources {
+			rest, ok := strings.CutPrefix(k, rg+"/providers/")
+			if ok && strings.Count(rest, "/") == 2 {
+				items = append(items, res)
+			}
+		}
+		reply(http.StatusOK, map[string]any{"value": items})
+	case r.Method == http.MethodGet && strings.HasSuffix(key, "/roleassignments"):
+		principal := strings.TrimSuffix(strings.TrimPrefix(r.URL.Query().Get("$filter"), "principalId eq '"), "'")
+		var items []any
+		for k, res := range f.resources {
+			props, _ := res["properties"].(map[string]any)
+			if strings.Contains(k, "/roleassignments/") && props["principalId"] == principal {
+				items = append(items, res)
+			}
+		}
+		reply(http.StatusOK, map[string]any{"value": items})
+	case r.Method == http.MethodGet && strings.HasSuffix(key, "/roledefinitions"):
+		var items []any
+		if r.URL.Query().Get("$filter") == "roleName eq 'Key Vault Secrets User'" {
+			items = append(items, map[string]any{"name": roleDefIDKeyVaultSecretsUser})
+		}
+		reply(http.StatusOK, map[string]any{"value": items})
+	case r.Method == http.MethodGet:
+		res, ok := f.resources[key]
+		if !ok {
+			notFound()
+			return
+		}
+		reply(http.StatusOK, res)
+	case r.Method == http.MethodPut || r.Method == http.MethodPatch:
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			reply(http.StatusBadRequest, map[string]any{"error": map[string]any{"code": "InvalidRequestContent"}})
+			return
+		}
+		f.writes = append(f.writes, r.Method+" "+id)
+		res := body
+		if r.Method == http.MethodPatch {
+			res = f.resources[key]
+			if res == nil {
+				notFound()
+				return
+			}
+			for k, v := range body {
+				res[k] = v
+			}
+		}
+		f.complete(id, res)
+		f.resources[key] = res
+		reply(http.StatusOK, res)
+	default:
+		reply(http.StatusMethodNotAllowed, map[string]any{"error": map[string]any{"code": "MethodNotAllowed"}})
+	}
+}
+
+// complete sets the read-only fields ARM returns for the resource.
+func (f *fakeARM) complete(id string, res map[string]any) {
+	res["id"], res["name"] = id, path.Base(id)
+	if i := strings.LastIndex(id, "/providers/"); i >= 0 {
+		parts := strings.Split(id[i+len("/providers/"):], "/")
+		res["type"] = parts[0] + "/" + parts[1]
+		if len(parts) > 3 {
+			res["type"] = parts[0] + "/" + parts[1] + "/" + parts[3]
+		}
+	} else {
+		res["type"] = "Microsoft.Resources/resourceGroups"
+	}
+	props, _ := res["properties"].(map[string]any)
+	if props == nil {
+		props = map[string]any{}
+		res["properties"] = props
+	}
+	props["provisioningState"] = "Succeeded"
+	switch res["type"] {
+	case armTypeManagedIdentity:
+		props["tenantId"], props["principalId"], props["clientId"] = "tenant-1", "uai-principal", "uai-client"
+	case armTypeManagedCluster:
+		res["identity"] = map[string]any{"type": "SystemAssigned", "principalId": "cluster-principal"}
+		props["identityProfile"] = map[string]any{"kubeletidentity": map[string]any{"objectId": "kubelet-principal"}}
+		oidc, _ := props["oidcIssuerProfile"].(map[string]any)
+		oidc["issuerURL"] = "https://oidc.example.com/issuer/"
+	case "Microsoft.Authorization/roleAssignments":
+		props["scope"] = id[:strings.Index(id, "/providers/Microsoft.Authorization/")]
+	}
+}
+
+// fakeCredential returns a JWT-shaped token carrying the caller object ID.
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"oid":"caller-oid"}`))
+	return azcore.AccessToken{Token: "e30." + payload + ".sig", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newEnsureTestDriver(t *testing.T) (*driver, *fakeARM) {
+	t.Helper()
+	fake := &fakeARM{resources: map[string]map[string]any{}}
+	srv := httptest.NewTLSServer(fake)
+	t.Cleanup(srv.Close)
+	d := &driver{
+		workspaceName:       "ws1",
+		providerName:        "prv1",
+		resourcePrefix:      "k4x",
+		TokenCredential:     fakeCredential{},
+		AzureSubscriptionId: "sub1",
+		AzureLocation:       "japaneast",
+		armOptions: &arm.ClientOptions{ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {Endpoint: srv.URL, Audience: "https://management.example.com"},
+			}},
+			Transport: srv.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		}},
+	}
+	return d, fake
+}
+
+// actions maps step names to their actions.
+func actions(results []ensureResult) map[string]ensureAction {
+	out := map[string]ensureAction{}
+	for _, res := range results {
+		out[res.Step] = res.Action
+	}
+	return out
+}
+
+func TestEnsureAKSClusterResources(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1", Settings: map[string]string{"AZURE_AKS_USER_VM_ZONES": "1,3"}}
+	steps := []string{"ResourceGroup", "LogAnalytics", "StorageAccount", "UserAssignedIdentity", "ManagedCluster", "Diagnostics", "FederatedIdentityCredential", "RoleClusterAdmin"}
+
+	// Plan on an empty subscription reports every step without writing.
+	results, err := d.ensureAKSClusterResources(ctx, cluster, true, false)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	got := actions(results)
+	for _, step := range steps {
+		if got[step] != ensurePlanned {
+			t.Errorf("plan: %s = %q, want planned", step, got[step])
+		}
+	}
+	if len(fake.writes) != 0 {
+		t.Fatalf("plan must not write, got %v", fake.writes)
+	}
+
+	results, err = d.ensureAKSClusterResources(ctx, cluster, false, false)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got = actions(results)
+	for _, step := range steps {
+		if got[step] != ensureCreated {
+			t.Errorf("create: %s = %q, want created", step, got[step])
+		}
+	}
+
+	rg, _ := d.clusterResourceGroupName(cluster)
+	mcID := strings.ToLower("/subscriptions/sub1/resourceGroups/" + rg + "/providers/" + armTypeManagedCluster + "/" + d.clusterAKSName(cluster))
+	mc := fake.resources[mcID]
+	if mc == nil {
+		t.Fatalf("managed cluster %s not created", mcID)
+	}
+	var pools []string
+	for _, p := range mc["properties"].(map[string]any)["agentPoolProfiles"].([]any) {
+		pools = append(pools, p.(map[string]any)["name"].(string))
+	}
+	if strings.Join(pools, ",") != "npsystem,npuser1,npuser3" {
+		t.Errorf("agent pools = %v", pools)
+	}
+	if tags := mc["tags"].(map[string]any); tags[tagClusterHash] == "" || tags["managed-by"] != "kompox" {
+		t.Errorf("managed cluster tags = %v", tags)
+	}
+
+	// A second run converges to no changes.
+	results, err = d.ensureAKSClusterResources(ctx, cluster, false, false)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	for step, action := range actions(results) {
+		if action != ensureUnchanged {
+			t.Errorf("rerun: %s = %q, want unchanged", step, action)
+		}
+	}
+
+	// Tag drift is repaired with a tags-only update; other steps stay unchanged.
+	laID := strings.ToLower("/subscriptions/sub1/resourceGroups/" + rg + "/providers/" + armTypeLogAnalytics + "/" + d.clusterLogAnalyticsName(cluster))
+	fake.resources[laID]["tags"].(map[string]any)[tagClusterName] = "other"
+	fake.writes = nil
+	results, err = d.ensureAKSClusterResources(ctx, cluster, false, false)
+	if err != nil {
+		t.Fatalf("drift: %v", err)
+	}
+	got = actions(results)
+	if got["LogAnalytics"] != ensureUpdated || got["ManagedCluster"] != ensureUnchanged {
+		t.Errorf("drift: actions = %v", got)
+	}
+	if len(fake.writes) != 1 || !strings.HasPrefix(fake.writes[0], "PATCH ") {
+		t.Errorf("drift: writes = %v, want one PATCH", fake.writes)
+	}
+
+	info, err := d.azureClusterInfo(ctx, cluster)
+	if err != nil {
+		t.Fatalf("cluster info: %v", err)
+	}
+	if info.ClusterName != d.clusterAKSName(cluster) || info.ResourceGroup != rg || info.TenantID != "tenant-1" ||
+		info.IngressClientID != "uai-client" || info.ClusterPrincipalID != "cluster-principal" || info.KubeletPrincipalID != "kubelet-principal" {
+		t.Errorf("cluster info = %+v", info)
+	}
+}
+
+func TestEnsureAKSAdoptsTaggedResources(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1"}
+	rg, _ := d.clusterResourceGroupName(cluster)
+	tags := map[string]any{}
+	for k, v := range d.clusterResourceTags(cluster.Name) {
+		tags[k] = *v
+	}
+
+	// Resources created by the former ARM template have generated names but carry the cluster tags.
+	rgID := "/subscriptions/sub1/resourceGroups/" + rg
+	legacy := rgID + "/providers/" + armTypeManagedCluster + "/aks-legacy123"
+	for id, res := range map[string]map[string]any{
+		rgID:   {"location": "japaneast", "tags": tags},
+		legacy: {"location": "japaneast", "tags": tags, "properties": map[string]any{"oidcIssuerProfile": map[string]any{"enabled": true}}},
+	} {
+		fake.complete(id, res)
+		fake.resources[strings.ToLower(id)] = res
+	}
+
+	info, err := d.azureClusterInfo(ctx, cluster)
+	if err != nil {
+		t.Fatalf("cluster info: %v", err)
+	}
+	if info.ClusterName != "aks-legacy123" {
+		t.Errorf("cluster name = %q, want adopted aks-legacy123", info.ClusterName)
+	}
+
+	// The adopted cluster is converged in place (Workload Identity and Key Vault addon enabled).
+	results, err := d.ensureAKSClusterResources(ctx, cluster, true, false)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	for _, res := range results {
+		if res.Step != "ManagedCluster" {
+			continue
+		}
+		if res.Action != ensurePlanned || !strings.EqualFold(res.ID, legacy) || len(res.Changes) == 0 {
+			t.Errorf("managed cluster result = %+v", res)
+		}
+	}
+}
+
+func TestEnsureAKSManagedClusterUpdate(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1"}
+	if _, err := d.ensureAKSClusterResources(ctx, cluster, false, false); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	rg, _ := d.clusterResourceGroupName(cluster)
+	id := "/subscriptions/sub1/resourceGroups/" + rg + "/providers/" + armTypeManagedCluster + "/" + d.clusterAKSName(cluster)
+	mc := fake.resources[strings.ToLower(id)]
+
+	// Tag-only drift is repaired with PATCH.
+	mc["tags"].(map[string]any)[tagClusterName] = "other"
+	fake.writes = nil
+	if _, err := d.ensureAKSClusterResources(ctx, cluster, false, false); err != nil {
+		t.Fatalf("tag drift: %v", err)
+	}
+	if len(fake.writes) != 1 || fake.writes[0] != "PATCH "+id {
+		t.Errorf("tag drift: writes = %v, want PATCH %s", fake.writes, id)
+	}
+
+	// Other drift is repaired with a PUT of the desired spec that keeps the node pools, the
+	// SKU and the version of the existing cluster but no read-only fields.
+	props := mc["properties"].(map[string]any)
+	props["securityProfile"] = map[string]any{"workloadIdentity": map[string]any{"enabled": false}}
+	props["kubernetesVersion"] = "1.34"
+	props["fqdn"] = "cls1.hcp.example.com"
+	props["agentPoolProfiles"] = []any{map[string]any{"name": "npextra", "mode": "User", "count": 2, "powerState": map[string]any{"code": "Running"}}}
+	mc["sku"] = map[string]any{"name": "Base", "tier": "Standard"}
+	fake.writes = nil
+	results, err := d.ensureAKSClusterResources(ctx, cluster, false, false)
+	if err != nil {
+		t.Fatalf("drift: %v", err)
+	}
+	if got := actions(results)["ManagedCluster"]; got != ensureUpdated {
+		t.Errorf("drift: ManagedCluster = %q, want updated", got)
+	}
+	if len(fake.writes) != 1 || fake.writes[0] != "PUT "+id {
+		t.Fatalf("drift: writes = %v, want PUT %s", fake.writes, id)
+	}
+	mc = fake.resources[strings.ToLower(id)]
+	props = mc["properties"].(map[string]any)
+	if _, ok := props["fqdn"]; ok {
+		t.Errorf("read-only fqdn was sent back")
+	}
+	if props["kubernetesVersion"] != "1.34" || mc["sku"].(map[string]any)["tier"] != "Standard" {
+		t.Errorf("version/sku not kept: %v %v", props["kubernetesVersion"], mc["sku"])
+	}
+	pools := props["agentPoolProfiles"].([]any)
+	pool := pools[0].(map[string]any)
+	if len(pools) != 1 || pool["name"] != "npextra" {
+		t.Errorf("agent pools = %v, want the existing npextra only", pools)
+	}
+	if _, ok := pool["powerState"]; ok {
+		t.Errorf("read-only agent pool powerState was sent back")
+	}
+	if wi := props["securityProfile"].(map[string]any)["workloadIdentity"].(map[string]any); wi["enabled"] != true {
+		t.Errorf("workload identity = %v, want enabled", wi)
+	}
+}
+
+func TestEnsureAKSAccessRolesFatal(t *testing.T) {
+	ctx := context.Background()
+	d, _ := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1", Settings: map[string]string{settingAzureAKSDNSZoneResourceIDs: "not-a-zone-id"}}
+	_, err := d.ensureAKSClusterResources(ctx, cluster, false, false)
+	if err == nil || !strings.Contains(err.Error(), "RoleDNS") {
+		t.Fatalf("err = %v, want RoleDNS failure", err)
+	}
+}
@@ -0,0 +1,179 @@
+package aks
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/directory"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/file"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/fileerror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/service"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/share"
+	"github.com/kompox/kompox/internal/logging"
+	"golang.org/x/sync/errgroup"
+)
+
+// Azure Files data plane constants
+const (
+	filesSnapshotTimeFormat  = "2006-01-02T15:04:05.0000000Z"
+	filesCopyPollInterval    = 2 * time.Second
+	filesCopyConcurrency     = 16
+	filesStorageEndpointBase = "file.core.windows.net"
+)
+
+// azureFilesClient wraps the Azure Files data plane SDK client authenticated with the
+// storage account Shared Key. It covers only what share snapshot restore needs. The ARM
+// API has no operation to materialize a share snapshot into a new share, so the snapshot
+// is walked and its files are copied server-side with bounded parallelism.
+type azureFilesClient struct {
+	service *service.Client
+	// concurrency is the maximum number of file copies in flight.
+	concurrency int
+}
+
+// newAzureFilesClient creates a data plane client for the app storage account using
+// the first account key retrieved through ARM.
+func (d *driver) newAzureFilesClient(ctx context.Context, rg, accountName string) (*azureFilesClient, error) {
+	accountsClient, err := armstorage.NewAccountsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new storage accounts client: %w", err)
+	}
+	keysResp, err := accountsClient.ListKeys(ctx, rg, accountName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list storage account keys: %w", err)
+	}
+	for _, k := range keysResp.Keys {
+		if k == nil || k.Value == nil || *k.Value == "" {
+			continue
+		}
+		endpoint := fmt.Sprintf("https://%s.%s/", accountName, filesStorageEndpointBase)
+		return newAzureFilesClientWithKey(accountName, *k.Value, endpoint, nil)
+	}
+	return nil, fmt.Errorf("no usable key found for storage account %s", accountName)
+}
+
+// newAzureFilesClientWithKey creates a data plane client with an explicit base64 account
+// key and service endpoint. opts may be nil.
+func newAzureFilesClientWithKey(accountName, key, endpoint string, opts *service.ClientOptions) (*azureFilesClient, error) {
+	cred, err := service.NewSharedKeyCredential(accountName, key)
+	if err != nil {
+		return nil, fmt.Errorf("new shared key credential: %w", err)
+	}
+	client, err := service.NewClientWithSharedKeyCredential(endpoint, cred, opts)
+	if err != nil {
+		return nil, fmt.Errorf("new Azure Files client: %w", err)
+	}
+	return &azureFilesClient{service: client, concurrency: filesCopyConcurrency}, nil
+}
+
+// filesSnapshotTime formats a share snapshot time as expected by the Azure Files API.
+func filesSnapshotTime(t time.Time) string {
+	return t.UTC().Format(filesSnapshotTimeFormat)
+}
+
+// directoryClient returns the client of a slash-separated directory path ("" is the root).
+func directoryClient(s *share.Client, dir string) *directory.Client {
+	if dir == "" {
+		return s.NewRootDirectoryClient()
+	}
+	return s.NewDirectoryClient(dir)
+}
+
+// copySnapshot recursively copies the whole content of a share snapshot into dstShare.
+// Directories are created while walking the snapshot and files are copied in parallel.
+// It returns the number of copied files.
+func (c *azureFilesClient) copySnapshot(ctx context.Context, srcShare, snapshot, dstShare string) (int, error) {
+	log := logging.FromContext(ctx)
+	src, err := c.service.NewShareClient(srcShare).WithSnapshot(snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("share snapshot client: %w", err)
+	}
+	dst := c.service.NewShareClient(dstShare)
+
+	var copied atomic.Int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.concurrency)
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		srcDir := directoryClient(src, dir)
+		pager := srcDir.NewListFilesAndDirectoriesPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(gctx)
+			if err != nil {
+				return fmt.Errorf("list directory %q: %w", dir, err)
+			}
+			if page.Segment == nil {
+				continue
+			}
+			for _, f := range page.Segment.Files {
+				if f == nil || f.Name == nil {
+					continue
+				}
+				name := *f.Name
+				p := path.Join(dir, name)
+				srcURL := srcDir.NewFileClient(name).URL()
+				dstFile := directoryClient(dst, dir).NewFileClient(name)
+				g.Go(func() error {
+					if err := copyFile(gctx, dstFile, srcURL); err != nil {
+						return fmt.Errorf("copy file %q: %w", p, err)
+					}
+					copied.Add(1)
+					log.Debug(ctx, "Copied Azure Files file", "path", p)
+					return nil
+				})
+			}
+			for _, d := range page.Segment.Directories {
+				if d == nil || d.Name == nil {
+					continue
+				}
+				p := path.Join(dir, *d.Name)
+				if _, err := dst.NewDirectoryClient(p).Create(gctx, nil); err != nil && !fileerror.HasCode(err, fileerror.ResourceAlreadyExists) {
+					return fmt.Errorf("create directory %q: %w", p, err)
+				}
+				if err := walk(p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	werr := walk("")
+	gerr := g.Wait()
+	if werr == nil {
+		werr = gerr
+	}
+	return int(copied.Load()), werr
+}
+
+// copyFile starts a server-side copy from srcURL (in the same account) into dst and waits
+// for it to complete.
+func copyFile(ctx context.Context, dst *file.Client, srcURL string) error {
+	resp, err := dst.StartCopyFromURL(ctx, srcURL, nil)
+	if err != nil {
+		return err
+	}
+	status, desc := resp.CopyStatus, ""
+	for status != nil && *status == file.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(filesCopyPollInterval):
+		}
+		props, err := dst.GetProperties(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("get copy status: %w", err)
+		}
+		status = props.CopyStatus
+		if props.CopyStatusDescription != nil {
+			desc = *props.CopyStatusDescription
+		}
+	}
+	if status != nil && *status != file.CopyStatusTypeSuccess {
+		return fmt.Errorf("copy status %s: %s", *status, desc)
+	}
+	return nil
+}
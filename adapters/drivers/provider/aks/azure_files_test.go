@@ -0,0 +1,186 @@
+package aks
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/service"
+)
+
+// fakeAzureFiles is a tiny in-memory Azure Files data plane for copySnapshot tests.
+type fakeAzureFiles struct {
+	mu sync.Mutex
+	// files maps "share[@snapshot]/path" to size; directories end with "/".
+	files map[string]int64
+	// inflight and maxInflight track concurrent copy requests.
+	inflight, maxInflight int
+}
+
+func (f *fakeAzureFiles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey acct:") {
+		w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	q := r.URL.Query()
+	if r.Method == http.MethodPut && r.Header.Get("x-ms-copy-source") != "" {
+		f.mu.Lock()
+		f.inflight++
+		f.maxInflight = max(f.maxInflight, f.inflight)
+		f.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		defer func() {
+			f.mu.Lock()
+			f.inflight--
+			f.mu.Unlock()
+		}()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := fakeFilesKey(r.URL)
+	switch {
+	case r.Method == http.MethodGet && q.Get("comp") == "list":
+		prefix := strings.TrimSuffix(key, "/") + "/"
+		var names []string
+		for k := range f.files {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			rest := strings.TrimPrefix(k, prefix)
+			if rest == "" || strings.Contains(strings.TrimSuffix(rest, "/"), "/") {
+				continue
+			}
+			names = append(names, rest)
+		}
+		sort.Strings(names)
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Entries>`)
+		for _, n := range names {
+			if strings.HasSuffix(n, "/") {
+				fmt.Fprintf(&sb, "<Directory><Name>%s</Name><Properties /></Directory>", strings.TrimSuffix(n, "/"))
+			} else {
+				fmt.Fprintf(&sb, "<File><Name>%s</Name><Properties><Content-Length>%d</Content-Length></Properties></File>", n, f.files[prefix+n])
+			}
+		}
+		sb.WriteString("</Entries><NextMarker /></EnumerationResults>")
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(sb.String()))
+	case r.Method == http.MethodPut && q.Get("restype") == "directory":
+		if _, ok := f.files[key+"/"]; ok {
+			w.Header().Set("x-ms-error-code", "ResourceAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.files[key+"/"] = 0
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-copy-source") != "":
+		srcURL, err := url.Parse(r.Header.Get("x-ms-copy-source"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		size, ok := f.files[fakeFilesKey(srcURL)]
+		if !ok {
+			w.Header().Set("x-ms-error-code", "ResourceNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.files[key] = size
+		w.Header().Set("x-ms-copy-status", "success")
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// fakeFilesKey returns the fakeAzureFiles key ("share[@snapshot]/path") of a data plane URL.
+func fakeFilesKey(u *url.URL) string {
+	share, p, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if s := u.Query().Get("sharesnapshot"); s != "" {
+		share += "@" + s
+	}
+	return share + "/" + p
+}
+
+// redirectTransport sends every request to an httptest server keeping the path and query.
+type redirectTransport struct{ srv *httptest.Server }
+
+func (t redirectTransport) Do(req *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(t.srv.URL)
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return t.srv.Client().Do(req)
+}
+
+func TestAzureFilesClientCopySnapshot(t *testing.T) {
+	snapTime := filesSnapshotTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	fake := &fakeAzureFiles{
+		files: map[string]int64{
+			"vol-src@" + snapTime + "/a.txt":         10,
+			"vol-src@" + snapTime + "/a2.txt":        11,
+			"vol-src@" + snapTime + "/a3.txt":        12,
+			"vol-src@" + snapTime + "/dir/":          0,
+			"vol-src@" + snapTime + "/dir/b.txt":     20,
+			"vol-src@" + snapTime + "/dir/sub/":      0,
+			"vol-src@" + snapTime + "/dir/sub/c.txt": 30,
+			"vol-src/live-only.txt":                  40,
+			"vol-dst/":                               0,
+		},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	key := base64.StdEncoding.EncodeToString([]byte("secret-key"))
+	opts := &service.ClientOptions{ClientOptions: azcore.ClientOptions{
+		Transport: redirectTransport{srv},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}}
+	client, err := newAzureFilesClientWithKey("acct", key, "https://acct.file.core.windows.net/", opts)
+	if err != nil {
+		t.Fatalf("newAzureFilesClientWithKey() error = %v", err)
+	}
+
+	copied, err := client.copySnapshot(context.Background(), "vol-src", snapTime, "vol-dst")
+	if err != nil {
+		t.Fatalf("copySnapshot() error = %v", err)
+	}
+	if copied != 5 {
+		t.Errorf("copySnapshot() copied = %d, want 5", copied)
+	}
+	for path, want := range map[string]int64{
+		"vol-dst/a.txt":         10,
+		"vol-dst/a2.txt":        11,
+		"vol-dst/a3.txt":        12,
+		"vol-dst/dir/b.txt":     20,
+		"vol-dst/dir/sub/c.txt": 30,
+	} {
+		if got, ok := fake.files[path]; !ok || got != want {
+			t.Errorf("file %s = (%d, %v), want (%d, true)", path, got, ok, want)
+		}
+	}
+	if _, ok := fake.files["vol-dst/live-only.txt"]; ok {
+		t.Errorf("file from live share must not be copied from snapshot")
+	}
+	if fake.maxInflight < 2 {
+		t.Errorf("copies in flight = %d, want parallel copies", fake.maxInflight)
+	}
+}
+
+func TestFilesSnapshotTime(t *testing.T) {
+	got := filesSnapshotTime(time.Date(2026, 1, 2, 3, 4, 5, 600000000, time.FixedZone("JST", 9*3600)))
+	want := "2026-01-01T18:04:05.6000000Z"
+	if got != want {
+		t.Errorf("filesSnapshotTime() = %q, want %q", got, want)
+	}
+}
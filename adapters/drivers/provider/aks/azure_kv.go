@@ -3,8 +3,11 @@ package aks
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
@@ -13,11 +16,12 @@ import (
 	"github.com/kompox/kompox/internal/logging"
 )
 
-// assignRolesKeyVaultSecrets assigns Key Vault Secrets User role to the User Assigned Managed Identity
-// for all Key Vault secrets referenced in cluster.Ingress.Certificates.
-func (d *driver) ensureAzureRoleKeyVaultSecret(ctx context.Context, cluster *model.Cluster, principalID string) error {
+// keyVaultSecretScopes returns the role assignment scopes (<key_vault_resource_id>/secrets/<name>)
+// of all Key Vault secrets referenced in cluster.Ingress.Certificates.
+// Unparsable URLs and unknown Key Vaults are logged and skipped.
+func (d *driver) keyVaultSecretScopes(ctx context.Context, cluster *model.Cluster) ([]string, error) {
 	if cluster == nil || cluster.Ingress == nil || len(cluster.Ingress.Certificates) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	log := logging.FromContext(ctx)
@@ -27,7 +31,6 @@ func (d *driver) ensureAzureRoleKeyVaultSecret(ctx context.Context, cluster *mod
 		kvName     string
 		objectName string
 		certName   string
-		sourceURL  string
 	}
 	var secrets []secretInfo
 
@@ -43,16 +46,11 @@ func (d *driver) ensureAzureRoleKeyVaultSecret(ctx context.Context, cluster *mod
 				"error", err)
 			continue
 		}
-		secrets = append(secrets, secretInfo{
-			kvName:     kvName,
-			objectName: objectName,
-			certName:   cert.Name,
-			sourceURL:  cert.Source,
-		})
+		secrets = append(secrets, secretInfo{kvName: kvName, objectName: objectName, certName: cert.Name})
 	}
 
 	if len(secrets) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Get unique Key Vault names for resource ID lookup
@@ -64,44 +62,19 @@ func (d *driver) ensureAzureRoleKeyVaultSecret(ctx context.Context, cluster *mod
 	// Get all accessible Key Vault resources to find resource IDs
 	keyVaultResourceIDs, err := d.azureKeyVaultResourceIDs(ctx, keyVaultNames)
 	if err != nil {
-		return fmt.Errorf("failed to get Key Vault resource IDs: %w", err)
+		return nil, fmt.Errorf("failed to get Key Vault resource IDs: %w", err)
 	}
 
-	roleDefinitionID := d.azureRoleDefinitionID(roleDefIDKeyVaultSecretsUser)
-
-	// Assign roles for each secret individually
-	successCount := 0
-	errorCount := 0
-
+	var scopes []string
 	for _, secret := range secrets {
-		logger := logging.FromContext(ctx).With("certName", secret.certName, "kvName", secret.kvName)
 		keyVaultResourceID, exists := keyVaultResourceIDs[secret.kvName]
 		if !exists {
-			errorCount++
-			logger.Info(ctx, "AKS:EnsureKV/efail", "err", "key vault resource not found")
+			log.Info(ctx, "AKS:EnsureKV/efail", "certName", secret.certName, "kvName", secret.kvName, "err", "key vault resource not found")
 			continue
 		}
-		logger.Info(ctx, "AKS:EnsureKV/eok")
-
-		// Create scope for the specific secret: <key_vault_resource_id>/secrets/<secret_name>
-		secretScope := fmt.Sprintf("%s/secrets/%s", keyVaultResourceID, secret.objectName)
-
-		logger = logging.FromContext(ctx).With("principalId", principalID, "scope", secretScope)
-		if err := d.ensureAzureRole(ctx, secretScope, principalID, roleDefinitionID); err != nil {
-			errorCount++
-			logger.Info(ctx, "AKS:RoleKV/efail", "err", err)
-		} else {
-			successCount++
-			logger.Info(ctx, "AKS:RoleKV/eok")
-		}
-	}
-
-	// Return error if any role assignments failed
-	if errorCount > 0 {
-		return fmt.Errorf("some key vault role assignments failed")
+		scopes = append(scopes, fmt.Sprintf("%s/secrets/%s", keyVaultResourceID, secret.objectName))
 	}
-
-	return nil
+	return scopes, nil
 }
 
 // azureKeyVaultResourceIDs retrieves resource IDs for the specified Key Vault names
@@ -232,3 +205,43 @@ func (d *driver) purgeAzureKeyVaults(ctx context.Context, keyVaultNames []string
 
 	return nil
 }
+
+// Key Vault data plane API version and token scope used to read secret values.
+const (
+	keyVaultAPIVersion = "7.4"
+	keyVaultScope      = "https://vault.azure.net/.default"
+)
+
+// azureKeyVaultSecretValue reads the value of the Key Vault secret at secretURL through the
+// data plane REST API with the driver credential.
+func (d *driver) azureKeyVaultSecretValue(ctx context.Context, secretURL string) (string, error) {
+	var opts policy.ClientOptions
+	if d.armOptions != nil {
+		opts = d.armOptions.ClientOptions
+	}
+	pl := runtime.NewPipeline("kompox/aks", "v1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(d.TokenCredential, []string{keyVaultScope}, nil)},
+	}, &opts)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, secretURL)
+	if err != nil {
+		return "", err
+	}
+	query := req.Raw().URL.Query()
+	query.Set("api-version", keyVaultAPIVersion)
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header.Set("Accept", "application/json")
+	resp, err := pl.Do(req)
+	if err == nil && !runtime.HasStatusCode(resp, http.StatusOK) {
+		err = runtime.NewResponseError(resp)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get key vault secret %s: %w", secretURL, err)
+	}
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &secret); err != nil {
+		return "", fmt.Errorf("decode key vault secret %s: %w", secretURL, err)
+	}
+	return secret.Value, nil
+}
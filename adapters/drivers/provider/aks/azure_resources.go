@@ -28,7 +28,7 @@ func (d *driver) ensureAzureResourceGroupCreated(ctx context.Context, rg string,
 	// Ensure AKS principal has Contributor on this RG (idempotent)
 	principalID = strings.TrimSpace(principalID)
 	if principalID == "" {
-		// Unknown principal; skip assignment silently (caller should have provided from deployment outputs).
+		// Unknown principal; skip assignment silently (caller should have provided from cluster info).
 		return nil
 	}
 	// Create assignment with deterministic GUID name derived from (principalID, roleDefinitionID)
@@ -0,0 +1,211 @@
+package aks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// ARM REST API versions used by the ensure workflow. The driver owns API version
+// management (K4x-ADR-020); bump them only together with an E2E run.
+const (
+	armAPIResourceGroups     = "2021-04-01"
+	armAPIResources          = "2021-04-01"
+	armAPILogAnalytics       = "2022-10-01"
+	armAPIStorageAccounts    = "2023-01-01"
+	armAPIManagedIdentities  = "2023-01-31"
+	armAPIManagedClusters    = "2025-05-01"
+	armAPIDiagnosticSettings = "2021-05-01-preview"
+	armAPIRoleAssignments    = "2022-04-01"
+	armAPIRoleDefinitions    = "2022-04-01"
+)
+
+// armPollFrequency is the polling interval for long-running operations.
+const armPollFrequency = 10 * time.Second
+
+// armClient calls the ARM REST API with the driver credential.
+// The azcore ARM pipeline handles authentication, resource provider registration
+// and retries of throttled (429) and transient (5xx) responses.
+type armClient struct {
+	pl       runtime.Pipeline
+	endpoint string
+}
+
+// armResource is the envelope shared by ARM resources. Resource-specific payloads
+// embed it and add their own properties.
+type armResource struct {
+	ID       string            `json:"id,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	Location string            `json:"location,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// newARMClient creates an ARM REST client for the driver subscription.
+func (d *driver) newARMClient() (*armClient, error) {
+	c, err := arm.NewClient("kompox/aks", "v1.0.0", d.TokenCredential, d.armOptions)
+	if err != nil {
+		return nil, fmt.Errorf("create ARM client: %w", err)
+	}
+	return &armClient{pl: c.Pipeline(), endpoint: strings.TrimSuffix(c.Endpoint(), "/")}, nil
+}
+
+// do sends a request for the resource path (starting with "/") and returns the response
+// when its status is one of ok. Other statuses are returned as *azcore.ResponseError.
+func (c *armClient) do(ctx context.Context, method, path, apiVersion string, query url.Values, body any, ok ...int) (*http.Response, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", apiVersion)
+	req, err := runtime.NewRequest(ctx, method, c.endpoint+path)
+	if err != nil {
+		return nil, err
+	}
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header.Set("Accept", "application/json")
+	if body != nil {
+		if err := runtime.MarshalAsJSON(req, body); err != nil {
+			return nil, fmt.Errorf("encode %s body: %w", path, err)
+		}
+	}
+	resp, err := c.pl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, ok...) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	return resp, nil
+}
+
+// get reads the resource into out. It returns false when the resource does not exist.
+func (c *armClient) get(ctx context.Context, id, apiVersion string, out any) (bool, error) {
+	resp, err := c.do(ctx, http.MethodGet, id, apiVersion, nil, nil, http.StatusOK)
+	if isNotFoundError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get %s: %w", id, err)
+	}
+	if err := runtime.UnmarshalAsJSON(resp, out); err != nil {
+		return false, fmt.Errorf("decode %s: %w", id, err)
+	}
+	return true, nil
+}
+
+// put creates or replaces the resource and waits for the long-running operation to finish.
+// The final resource state is decoded into out when non-nil.
+func (c *armClient) put(ctx context.Context, id, apiVersion string, body, out any) error {
+	return c.send(ctx, http.MethodPut, id, apiVersion, body, out)
+}
+
+// patch updates the resource (e.g., tags only) and waits for completion.
+func (c *armClient) patch(ctx context.Context, id, apiVersion string, body, out any) error {
+	return c.send(ctx, http.MethodPatch, id, apiVersion, body, out)
+}
+
+// send issues a mutating request and polls the operation until done.
+func (c *armClient) send(ctx context.Context, method, id, apiVersion string, body, out any) error {
+	resp, err := c.do(ctx, method, id, apiVersion, nil, body, http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", strings.ToLower(method), id, err)
+	}
+	poller, err := runtime.NewPoller[json.RawMessage](resp, c.pl, nil)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", strings.ToLower(method), id, err)
+	}
+	result, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: armPollFrequency})
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", strings.ToLower(method), id, err)
+	}
+	if out != nil && len(result) > 0 {
+		if err := json.Unmarshal(result, out); err != nil {
+			return fmt.Errorf("decode %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// list returns all items of a collection, following nextLink.
+func (c *armClient) list(ctx context.Context, path, apiVersion string, query url.Values, out func(json.RawMessage) error) error {
+	resp, err := c.do(ctx, http.MethodGet, path, apiVersion, query, nil, http.StatusOK)
+	for {
+		if err != nil {
+			return fmt.Errorf("list %s: %w", path, err)
+		}
+		var page struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"nextLink"`
+		}
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		for _, item := range page.Value {
+			if err := out(item); err != nil {
+				return err
+			}
+		}
+		if page.NextLink == "" {
+			return nil
+		}
+		var req *policy.Request
+		req, err = runtime.NewRequest(ctx, http.MethodGet, page.NextLink)
+		if err != nil {
+			return err
+		}
+		resp, err = c.pl.Do(req)
+		if err == nil && !runtime.HasStatusCode(resp, http.StatusOK) {
+			err = runtime.NewResponseError(resp)
+		}
+	}
+}
+
+// isConflictError reports whether err is an ARM 409 Conflict response.
+func isConflictError(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusConflict
+}
+
+// callerObjectID returns the Entra ID object ID of the driver credential, read from the
+// oid claim of an ARM access token.
+func (d *driver) callerObjectID(ctx context.Context) (string, error) {
+	cfg := cloud.AzurePublic
+	if d.armOptions != nil && d.armOptions.Cloud.Services != nil {
+		cfg = d.armOptions.Cloud
+	}
+	audience := cfg.Services[cloud.ResourceManager].Audience
+	tok, err := d.TokenCredential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{strings.TrimSuffix(audience, "/") + "/.default"}})
+	if err != nil {
+		return "", fmt.Errorf("get token: %w", err)
+	}
+	parts := strings.Split(tok.Token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("decode access token: %w", err)
+	}
+	var claims struct {
+		OID string `json:"oid"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("decode access token: %w", err)
+	}
+	if claims.OID == "" {
+		return "", fmt.Errorf("access token has no oid claim")
+	}
+	return claims.OID, nil
+}
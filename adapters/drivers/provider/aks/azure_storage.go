@@ -29,13 +29,10 @@ func (d *driver) ensureStorageAccountCreated(ctx context.Context, cluster *model
 	// Get Kubelet Identity principal ID for role assignment (optional for non-AKS scenarios)
 	// Kubelet Identity is used by Azure Files CSI driver to retrieve storage account keys
 	principalID := ""
-	outputs, err := d.azureDeploymentOutputs(ctx, cluster)
-	if err == nil {
-		if v, ok := outputs[outputAksKubeletPrincipalID].(string); ok {
-			principalID = v
-		}
+	if info, err := d.azureClusterInfo(ctx, cluster); err == nil {
+		principalID = info.KubeletPrincipalID
 	}
-	// Ignore errors: principalID remains empty if outputs unavailable (e.g., aks-e2e-volume tests)
+	// Ignore errors: principalID remains empty if the cluster is unavailable (e.g., aks-e2e-volume tests)
 	// ensureAzureResourceGroupCreated will skip role assignment when principalID is empty
 	err = d.ensureAzureResourceGroupCreated(ctx, rg, d.appResourceTags(app.Name), principalID)
 	if err != nil {
@@ -32,14 +32,6 @@ func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, o
 	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
 	defer func() { cleanup(err) }()
 
-	// Derive resource group name
-	rgName, err := d.clusterResourceGroupName(cluster)
-	if err != nil || rgName == "" {
-		return fmt.Errorf("derive cluster resource group: %w", err)
-	}
-
-	tags := d.clusterResourceTags(cluster.Name)
-
 	// Resolve options
 	var o model.ClusterProvisionOptions
 	for _, fn := range opts {
@@ -47,10 +39,22 @@ func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, o
 			fn(&o)
 		}
 	}
-	// Ensure subscription-scoped deployment (idempotent; respects force)
-	if err := d.ensureAzureDeploymentCreated(ctx, cluster, rgName, tags, o.Force); err != nil {
+
+	// Converge cluster resources step by step (idempotent; force re-applies existing resources)
+	results, err := d.ensureAKSClusterResources(ctx, cluster, false, o.Force)
+	if err != nil {
 		return err
 	}
+	counts := map[ensureAction]int{}
+	for _, res := range results {
+		counts[res.Action]++
+	}
+	logging.FromContext(ctx).Info(ctx, "aks cluster resources ensured",
+		"cluster", cluster.Name,
+		"created", counts[ensureCreated],
+		"updated", counts[ensureUpdated],
+		"unchanged", counts[ensureUnchanged],
+	)
 
 	return nil
 }
@@ -68,7 +72,7 @@ func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster,
 		return fmt.Errorf("derive cluster resource group: %w", err)
 	}
 
-	// First, delete the subscription-scoped deployment if it exists (idempotent, best-effort)
+	// First, delete the legacy subscription-scoped deployment if it exists (idempotent, best-effort)
 	d.ensureAzureDeploymentDeleted(ctx, cluster)
 
 	// Delete the entire resource group via shared helper (handles KV detection & purge)
@@ -115,8 +119,6 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
 	defer func() { cleanup(err) }()
 
-	log := logging.FromContext(ctx)
-
 	// Build kube client from provider-managed kubeconfig
 	kc, err := d.kubeClient(ctx, cluster)
 	if err != nil {
@@ -128,33 +130,17 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 		return err
 	}
 
-	// Step 2: Create ServiceAccount exactly as specified by deployment outputs
-	outputs, err := d.azureDeploymentOutputs(ctx, cluster)
+	// Step 2: Create ServiceAccount bound to the ingress identity through Workload Identity
+	info, err := d.azureClusterInfo(ctx, cluster)
 	if err != nil {
-		return fmt.Errorf("read deployment outputs: %w", err)
-	}
-	saNS, _ := outputs[outputIngressServiceAccountNamespace].(string)
-	saName, _ := outputs[outputIngressServiceAccountName].(string)
-	if saNS == "" || saName == "" {
-		return fmt.Errorf("deployment outputs must include %s and %s", outputIngressServiceAccountNamespace, outputIngressServiceAccountName)
-	}
-
-	// Validate outputs match the parameters we passed in ClusterProvision
-	expectedNS := kube.IngressNamespace(cluster)
-	expectedName := kube.IngressServiceAccountName(cluster)
-	if saNS != expectedNS || saName != expectedName {
-		return fmt.Errorf("deployment outputs mismatch: expected %s/%s, got %s/%s", expectedNS, expectedName, saNS, saName)
-	}
-
-	// Fetch workload identity tenant/client ID from deployment outputs and annotate the ServiceAccount
-	tenantID, _ := outputs[outputTenantID].(string)
-	if tenantID == "" {
-		return fmt.Errorf("deployment outputs must include non-empty %s", outputTenantID)
+		return fmt.Errorf("resolve cluster resources: %w", err)
 	}
-	clientID, _ := outputs[outputIngressServiceAccountClientID].(string)
-	if clientID == "" {
-		return fmt.Errorf("deployment outputs must include non-empty %s", outputIngressServiceAccountClientID)
+	if info.TenantID == "" || info.IngressClientID == "" {
+		return fmt.Errorf("ingress identity not found in resource group %s (run cluster provision)", info.ResourceGroup)
 	}
+	saNS := kube.IngressNamespace(cluster)
+	saName := kube.IngressServiceAccountName(cluster)
+	tenantID, clientID := info.TenantID, info.IngressClientID
 	annotations := map[string]string{
 		"azure.workload.identity/tenant-id": tenantID,
 		"azure.workload.identity/client-id": clientID,
@@ -165,7 +151,14 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 		return fmt.Errorf("create ingress serviceaccount %s/%s: %w", saNS, saName, err)
 	}
 
-	// Step 3: If static certificates are configured, ensure SecretProviderClass and TLS Secrets from Key Vault
+	// Step 3: Grant access to Key Vault secrets (ingress identity), DNS zones (cluster identity)
+	// and ACR (kubelet identity) configured for the cluster.
+	// Runs before the SecretProviderClass resources are created so that they can mount secrets.
+	if _, err := d.ensureAKSAccessRolesFromInfo(ctx, cluster, info, false); err != nil {
+		return fmt.Errorf("ensure access role assignments: %w", err)
+	}
+
+	// Step 4: If static certificates are configured, ensure SecretProviderClass and TLS Secrets from Key Vault
 	var mounts []map[string]string // {name, mountPath}
 	var configs []map[string]any
 	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
@@ -176,35 +169,7 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 		}
 	}
 
-	// Step 4: Assign DNS Zone Contributor role for configured DNS zones (best-effort)
-	// This allows AKS cluster identity (control plane) to update DNS records in Azure DNS zones.
-	aksClusterPrincipalID, _ := outputs[outputAksClusterPrincipalID].(string)
-	if aksClusterPrincipalID != "" {
-		zones, err := d.collectDNSZoneIDs(cluster)
-		if err != nil {
-			log.Warn(ctx, "failed to collect DNS zone IDs for role assignment (best-effort)", "error", err.Error())
-		} else if len(zones) > 0 {
-			d.ensureAzureDNSZoneRoles(ctx, aksClusterPrincipalID, zones)
-		}
-	} else {
-		log.Warn(ctx, "AKS cluster principal ID not available, skipping DNS zone role assignments")
-	}
-
-	// Step 5: Assign AcrPull role for configured ACR resources (best-effort)
-	// This allows AKS kubelet identity (data plane) to pull images from Azure Container Registry.
-	aksKubeletPrincipalID, _ := outputs[outputAksKubeletPrincipalID].(string)
-	if aksKubeletPrincipalID != "" {
-		registries, err := d.collectAzureContainerRegistryIDs(cluster)
-		if err != nil {
-			log.Warn(ctx, "failed to collect ACR resource IDs for role assignment (best-effort)", "error", err.Error())
-		} else if len(registries) > 0 {
-			d.ensureAzureContainerRegistryRoles(ctx, aksKubeletPrincipalID, registries)
-		}
-	} else {
-		log.Warn(ctx, "AKS kubelet principal ID not available, skipping ACR role assignments")
-	}
-
-	// Step 6: Install Traefik via Helm (idempotent)
+	// Step 5: Install Traefik via Helm (idempotent)
 	// Mount SecretProviderClass volumes created in ensureSecretProviderClassFromKeyVault.
 	// For multiple Key Vaults, mount one CSI volume per SPC with distinct mount paths.
 	mutator := func(ctx context.Context, _ *model.Cluster, _ string, values kube.HelmValues) {
@@ -228,12 +193,6 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 			dep = map[string]any{}
 			values["deployment"] = dep
 		}
-		// Ensure pod label for Workload Identity remains.
-		if pl, ok := dep["podLabels"].(map[string]any); ok {
-			pl["azure.workload.identity/use"] = "true"
-		} else {
-			dep["podLabels"] = map[string]any{"azure.workload.identity/use": "true"}
-		}
 		// Add one volume per SPC mount (captured from outer scope)
 		if len(mounts) > 0 {
 			for i, m := range mounts {
@@ -278,17 +237,35 @@ func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ .
 			}
 		}
 	}
+	mutators := []kube.HelmValuesMutator{workloadIdentityMutator}
 	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
-		if err := kc.InstallIngressTraefik(ctx, cluster, mutator); err != nil {
-			return err
-		}
-	} else {
-		if err := kc.InstallIngressTraefik(ctx, cluster); err != nil {
-			return err
-		}
+		mutators = append(mutators, mutator)
+	}
+	if err := kc.InstallIngressTraefik(ctx, cluster, mutators...); err != nil {
+		return err
 	}
 
-	return nil
+	// Step 6: Install the Spot eviction helper when configured, otherwise remove it
+	if cluster.SpotHandler != nil {
+		return kc.InstallSpotHandler(ctx, cluster)
+	}
+	return kc.UninstallSpotHandler(ctx, cluster)
+}
+
+// workloadIdentityMutator enables AKS Workload Identity on the Traefik pods by adding the
+// required pod label. The ingress ServiceAccount carries the identity annotations.
+func workloadIdentityMutator(_ context.Context, _ *model.Cluster, _ string, values kube.HelmValues) {
+	dep, _ := values["deployment"].(map[string]any)
+	if dep == nil {
+		dep = map[string]any{}
+		values["deployment"] = dep
+	}
+	pl, _ := dep["podLabels"].(map[string]any)
+	if pl == nil {
+		pl = map[string]any{}
+		dep["podLabels"] = pl
+	}
+	pl[workloadIdentityUseLabel] = "true"
 }
 
 // ClusterUninstall uninstalls in-cluster resources (Ingress Controller, etc.) from AKS cluster.
@@ -310,7 +287,12 @@ func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _
 		return err
 	}
 
-	// Step 2: Delete ingress namespace (best-effort, idempotent)
+	// Step 2: Delete the Spot eviction helper including its cluster-scoped RBAC
+	if err := kc.UninstallSpotHandler(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 3: Delete ingress namespace (best-effort, idempotent)
 	if err := kc.DeleteNamespace(ctx, kube.IngressNamespace(cluster)); err != nil {
 		return err
 	}
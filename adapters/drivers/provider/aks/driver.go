@@ -2,9 +2,11 @@ package aks
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
 	"github.com/kompox/kompox/domain/model"
@@ -19,7 +21,10 @@ type driver struct {
 	TokenCredential     azcore.TokenCredential
 	AzureSubscriptionId string
 	AzureLocation       string
+	diskEncryptionSetID string                   // default disk encryption set for managed disks (CMK)
+	diskRequireCMK      bool                     // reject managed disks without a disk encryption set
 	volumeBackends      map[string]volumeBackend // volume type -> volumeBackend
+	armOptions          *arm.ClientOptions       // ARM REST client options (nil for defaults)
 }
 
 // ID returns the provider identifier.
@@ -31,6 +36,23 @@ func (d *driver) WorkspaceName() string { return d.workspaceName }
 // ProviderName returns the provider name associated with this driver instance.
 func (d *driver) ProviderName() string { return d.providerName }
 
+// Capabilities returns the operations supported by the aks driver.
+// Cluster.Plan and Cluster.Upgrade are set by the port adapter from the optional interfaces.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Provision: true, Existing: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk:  {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true, Update: true, Copy: true},
+			model.VolumeTypeFiles: {AccessModes: []string{"ReadWriteMany"}, Snapshot: true},
+		},
+		VolumeInventory: true,
+		NodePool:        model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true},
+		DNS:             true,
+		Identity:        true,
+	}
+}
+
 // ResourceGroupBaseName returns provider-level base resource group name.
 func (d *driver) ResourceGroupBaseName() string { return d.resourcePrefix }
 
@@ -122,6 +144,21 @@ func init() {
 			prefix = prefix[:maxResourcePrefix]
 		}
 
+		diskEncryptionSetID := get(keyDiskEncryptionSetID)
+		if diskEncryptionSetID != "" {
+			if err := validateDiskEncryptionSetID(diskEncryptionSetID); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", keyDiskEncryptionSetID, err)
+			}
+		}
+		diskRequireCMK := false
+		if v := get(keyDiskRequireCMK); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", keyDiskRequireCMK, v)
+			}
+			diskRequireCMK = b
+		}
+
 		d := &driver{
 			workspaceName:       workspaceName,
 			providerName:        provider.Name,
@@ -129,6 +166,8 @@ func init() {
 			TokenCredential:     cred,
 			AzureSubscriptionId: subscriptionID,
 			AzureLocation:       location,
+			diskEncryptionSetID: diskEncryptionSetID,
+			diskRequireCMK:      diskRequireCMK,
 		}
 
 		// Initialize volume backends for each type
@@ -1,8 +0,0 @@
-package aks
-
-import (
-	_ "embed"
-)
-
-//go:embed main.json
-var mainJSON []byte
@@ -0,0 +1,143 @@
+package aks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// Workload Identity metadata recognized by the AKS mutating admission webhook.
+const (
+	workloadIdentityClientIDAnnotation = "azure.workload.identity/client-id"
+	workloadIdentityTenantIDAnnotation = "azure.workload.identity/tenant-id"
+	workloadIdentityUseLabel           = "azure.workload.identity/use"
+)
+
+// AppIdentityApply implements providerdrv.AppIdentityManager. It ensures a user-assigned
+// managed identity in the app resource group, a federated credential trusting the app
+// ServiceAccount of the cluster namespace through the cluster OIDC issuer, and the role
+// assignments of app.Identity. The identity is shared by all clusters of the provider; each
+// cluster namespace adds its own federated credential (Azure allows 20 per identity).
+func (d *driver) AppIdentityApply(ctx context.Context, cluster *model.Cluster, app *model.App, subject model.WorkloadIdentitySubject) (wi *model.WorkloadIdentity, err error) {
+	if app == nil || app.Identity == nil {
+		return nil, fmt.Errorf("app has no identity")
+	}
+	if subject.Namespace == "" || subject.ServiceAccount == "" {
+		return nil, fmt.Errorf("identity subject requires namespace and service account")
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "AppIdentityApply")
+	defer func() { cleanup(err) }()
+
+	info, err := d.azureClusterInfo(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get cluster info: %w", err)
+	}
+	if info.OIDCIssuerURL == "" {
+		return nil, fmt.Errorf("OIDC issuer of cluster %s is not enabled", cluster.Name)
+	}
+	rg, err := d.appResourceGroupName(app)
+	if err != nil {
+		return nil, err
+	}
+	name, err := d.appIdentityName(app)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reuse the ensure steps with the app resource group and tags.
+	r, err := d.newEnsureRun(cluster, false, false)
+	if err != nil {
+		return nil, err
+	}
+	r.rg = rg
+	r.tags = map[string]string{}
+	for k, v := range d.appResourceTags(app.Name) {
+		r.tags[k] = *v
+	}
+	if err := r.ensureAKSResourceGroupCreated(ctx); err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("%s/providers/%s/%s", r.resourceGroupID(), armTypeManagedIdentity, name)
+	identity := &armUserAssignedIdentity{}
+	found, err := r.arm.get(ctx, id, armAPIManagedIdentities, identity)
+	if err != nil {
+		return nil, err
+	}
+	create := func() error {
+		return r.arm.put(ctx, id, armAPIManagedIdentities, armResource{Location: d.AzureLocation, Tags: r.tags}, identity)
+	}
+	update := func() error { return r.patchTags(ctx, id, armAPIManagedIdentities, identity.Tags) }
+	if err := r.converge(ctx, "AppIdentity", id, found, r.tagChanges(identity.Tags), create, update); err != nil {
+		return nil, err
+	}
+	if identity.Properties.ClientID == "" || identity.Properties.PrincipalID == "" {
+		return nil, fmt.Errorf("identity %s has no client or principal ID", id)
+	}
+
+	sub := fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.ServiceAccount)
+	ficID := id + "/federatedIdentityCredentials/fic-" + subject.Namespace
+	if err := r.ensureFederatedCredential(ctx, "AppFederatedCredential", ficID, info.OIDCIssuerURL, sub); err != nil {
+		return nil, err
+	}
+
+	for _, role := range app.Identity.Roles {
+		roleDefID, err := r.resolveRoleDefinition(ctx, role.Scope, role.Role)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.ensureAKSRoleAssignment(ctx, "AppIdentityRole", role.Scope, identity.Properties.PrincipalID, roleDefID, principalTypeServicePrincipal); err != nil {
+			return nil, fmt.Errorf("assign role %q at %s: %w", role.Role, role.Scope, err)
+		}
+	}
+
+	annotations := map[string]string{workloadIdentityClientIDAnnotation: identity.Properties.ClientID}
+	if identity.Properties.TenantID != "" {
+		annotations[workloadIdentityTenantIDAnnotation] = identity.Properties.TenantID
+	}
+	return &model.WorkloadIdentity{
+		ClientID:                  identity.Properties.ClientID,
+		TenantID:                  identity.Properties.TenantID,
+		PrincipalID:               identity.Properties.PrincipalID,
+		ResourceID:                id,
+		ServiceAccountAnnotations: annotations,
+		PodLabels:                 map[string]string{workloadIdentityUseLabel: "true"},
+	}, nil
+}
+
+// resolveRoleDefinition returns the role definition GUID of role, given as a GUID, a role
+// definition resource ID or a role name (e.g., "Key Vault Secrets User") looked up at scope.
+func (r *ensureRun) resolveRoleDefinition(ctx context.Context, scope, role string) (string, error) {
+	role = strings.TrimSpace(role)
+	if i := strings.LastIndex(strings.ToLower(role), "/roledefinitions/"); i >= 0 {
+		role = role[i+len("/roleDefinitions/"):]
+	}
+	if _, err := uuid.Parse(role); err == nil {
+		return strings.ToLower(role), nil
+	}
+	query := url.Values{"$filter": {fmt.Sprintf("roleName eq '%s'", strings.ReplaceAll(role, "'", "''"))}}
+	var ids []string
+	err := r.arm.list(ctx, scope+"/providers/Microsoft.Authorization/roleDefinitions", armAPIRoleDefinitions, query, func(raw json.RawMessage) error {
+		var rd struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &rd); err != nil {
+			return err
+		}
+		ids = append(ids, rd.Name)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("resolve role %q at %s: %w", role, scope, err)
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("role %q not found at %s", role, scope)
+	}
+	return ids[0], nil
+}
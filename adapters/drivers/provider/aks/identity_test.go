@@ -0,0 +1,84 @@
+package aks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestAppIdentityApply(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1"}
+	if _, err := d.ensureAKSClusterResources(ctx, cluster, false, false); err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	const kv = "/subscriptions/sub1/resourceGroups/rg-kv/providers/Microsoft.KeyVault/vaults/kv1"
+	app := &model.App{Name: "app1", Identity: &model.AppIdentity{Roles: []model.AppIdentityRole{
+		{Scope: kv, Role: "Key Vault Secrets User"},
+		{Scope: kv, Role: "/subscriptions/sub1/providers/Microsoft.Authorization/roleDefinitions/" + roleDefIDAcrPull},
+	}}}
+	subject := model.WorkloadIdentitySubject{Namespace: "k4x-ns1", ServiceAccount: "app1"}
+
+	wi, err := d.AppIdentityApply(ctx, cluster, app, subject)
+	if err != nil {
+		t.Fatalf("AppIdentityApply: %v", err)
+	}
+	if wi.ClientID != "uai-client" || wi.ServiceAccountAnnotations[workloadIdentityClientIDAnnotation] != "uai-client" ||
+		wi.ServiceAccountAnnotations[workloadIdentityTenantIDAnnotation] != "tenant-1" || wi.PodLabels[workloadIdentityUseLabel] != "true" {
+		t.Errorf("unexpected identity: %+v", wi)
+	}
+	rg, _ := d.appResourceGroupName(app)
+	if !strings.Contains(wi.ResourceID, "/resourceGroups/"+rg+"/") {
+		t.Errorf("identity %s not in app resource group %s", wi.ResourceID, rg)
+	}
+	fic := fake.resources[strings.ToLower(wi.ResourceID+"/federatedIdentityCredentials/fic-k4x-ns1")]
+	if fic == nil {
+		t.Fatalf("federated credential not created")
+	}
+	if props := fic["properties"].(map[string]any); props["subject"] != "system:serviceaccount:k4x-ns1:app1" || props["issuer"] != "https://oidc.example.com/issuer/" {
+		t.Errorf("unexpected federated credential: %v", props)
+	}
+	roles := 0
+	for k := range fake.resources {
+		if strings.HasPrefix(k, strings.ToLower(kv)+"/providers/microsoft.authorization/roleassignments/") {
+			roles++
+		}
+	}
+	if roles != 2 {
+		t.Errorf("expected 2 role assignments at the vault, got %d", roles)
+	}
+
+	// Re-applying writes nothing.
+	writes := len(fake.writes)
+	if _, err := d.AppIdentityApply(ctx, cluster, app, subject); err != nil {
+		t.Fatalf("AppIdentityApply again: %v", err)
+	}
+	if len(fake.writes) != writes {
+		t.Errorf("re-apply must not write, got %v", fake.writes[writes:])
+	}
+
+	app.Identity.Roles = []model.AppIdentityRole{{Scope: kv, Role: "No Such Role"}}
+	if _, err := d.AppIdentityApply(ctx, cluster, app, subject); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected unknown role error, got %v", err)
+	}
+}
+
+func TestWorkloadIdentityMutator(t *testing.T) {
+	values := kube.HelmValues{"deployment": map[string]any{"podLabels": map[string]any{"app": "traefik"}}}
+	workloadIdentityMutator(context.Background(), nil, kube.TraefikReleaseName, values)
+	pl := values["deployment"].(map[string]any)["podLabels"].(map[string]any)
+	if pl[workloadIdentityUseLabel] != "true" || pl["app"] != "traefik" {
+		t.Errorf("podLabels = %v", pl)
+	}
+
+	values = kube.HelmValues{}
+	workloadIdentityMutator(context.Background(), nil, kube.TraefikReleaseName, values)
+	pl = values["deployment"].(map[string]any)["podLabels"].(map[string]any)
+	if pl[workloadIdentityUseLabel] != "true" {
+		t.Errorf("podLabels = %v", pl)
+	}
+}
@@ -20,6 +20,9 @@ const (
 	tagDiskName      = "kompox-disk-name"     // disk name (CompactID)
 	tagDiskAssigned  = "kompox-disk-assigned" // true/false
 	tagSnapshotName  = "kompox-snapshot-name" // snapshot name (CompactID)
+	tagLabelPrefix   = "kompox-label-"        // user label prefix (kompox-label-<key>)
+	tagDescription   = "kompox-description"   // user description
+	tagOrphanedAt    = "kompox-orphaned-at"   // RFC3339 time first found orphaned by gc
 )
 
 // Resource group related limits and setting keys.
@@ -30,6 +33,12 @@ const (
 	keyResourceGroupName = "AZURE_RESOURCE_GROUP_NAME"
 )
 
+// Disk encryption setting keys.
+const (
+	keyDiskEncryptionSetID = "AZURE_DISK_ENCRYPTION_SET_ID"
+	keyDiskRequireCMK      = "AZURE_DISK_REQUIRE_CMK"
+)
+
 // Volume related limits
 const (
 	maxVolumeName   = 16
@@ -62,12 +71,41 @@ func tagsForLog(tags map[string]*string) map[string]string {
 	return out
 }
 
-// azureDeploymentName generates the deployment name for the subscription-scoped deployment.
-// It returns the same name as the resource group name for consistency.
+// azureDeploymentName returns the name of the subscription-scoped deployment created by
+// drivers before the ensure workflow (same as the resource group name). It is only used
+// to clean up such deployments.
 func (d *driver) azureDeploymentName(cluster *model.Cluster) (string, error) {
 	return d.clusterResourceGroupName(cluster)
 }
 
+// Default names of cluster-scoped resources in the cluster resource group. They are used
+// only when no resource of the type carries the cluster hash tag (see ensureRun.discover).
+func (d *driver) clusterLogAnalyticsName(cluster *model.Cluster) string {
+	return "k4x-log-" + naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "").Cluster
+}
+
+func (d *driver) clusterIdentityName(cluster *model.Cluster) string {
+	return "k4x-id-" + naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "").Cluster
+}
+
+func (d *driver) clusterAKSName(cluster *model.Cluster) string {
+	return "k4x-aks-" + naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "").Cluster
+}
+
+// clusterStorageAccountName generates the storage account name for cluster diagnostics.
+// Format: k4xc{prv_hash}{cls_hash} (16 chars total, lowercase alphanumeric only).
+func (d *driver) clusterStorageAccountName(cluster *model.Cluster) string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "")
+	prvHash, clsHash := h.Provider, h.Cluster
+	if len(prvHash) > 6 {
+		prvHash = prvHash[:6]
+	}
+	if len(clsHash) > 6 {
+		clsHash = clsHash[:6]
+	}
+	return fmt.Sprintf("k4xc%s%s", prvHash, clsHash)
+}
+
 // clusterResourceTags generates key-values for tagging cluster-scoped Azure resources.
 func (d *driver) clusterResourceTags(clusterName string) map[string]*string {
 	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), clusterName, "")
@@ -160,6 +198,20 @@ func (d *driver) appSnapshotName(app *model.App, volName string, snapshotName st
 	return result, nil
 }
 
+// appIdentityName returns the name of the user-assigned identity of the app workload.
+func (d *driver) appIdentityName(app *model.App) (string, error) {
+	if app == nil {
+		return "", fmt.Errorf("app nil")
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	base := fmt.Sprintf("%s_id_%s", d.resourcePrefix, app.Name)
+	result, err := safeTruncate(base, h.AppID)
+	if err != nil {
+		return "", fmt.Errorf("identity name: %w", err)
+	}
+	return result, nil
+}
+
 // appStorageAccountName generates the storage account name for an app.
 // Format: k4x{prv_hash}{app_hash} (15 chars total, lowercase alphanumeric only).
 // Storage account names must be 3-24 characters, lowercase letters and numbers only.
@@ -19,19 +19,13 @@ const (
 	labelNodeZone = "kompox.dev/node-zone"
 )
 
-// getAksClusterName retrieves the actual AKS cluster resource name from deployment outputs.
+// getAksClusterName retrieves the actual AKS cluster resource name discovered by resource tags.
 func (d *driver) getAksClusterName(ctx context.Context, cluster *model.Cluster) (string, error) {
-	outputs, err := d.azureDeploymentOutputs(ctx, cluster)
+	info, err := d.azureClusterInfo(ctx, cluster)
 	if err != nil {
-		return "", fmt.Errorf("get deployment outputs: %w", err)
+		return "", fmt.Errorf("resolve cluster resources: %w", err)
 	}
-
-	aksName, ok := outputs[outputAksClusterName].(string)
-	if !ok {
-		return "", fmt.Errorf("%s not found in deployment outputs", outputAksClusterName)
-	}
-
-	return aksName, nil
+	return info.ClusterName, nil
 }
 
 // NodePoolList returns a list of node pools for the specified cluster.
@@ -0,0 +1,198 @@
+package aks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// ClusterPlan reports the changes of a cluster lifecycle operation without mutating anything.
+// Provision runs the ensure workflow in plan mode; install and uninstall inspect the cluster
+// through the admin kubeconfig; deprovision lists the resources deleted with the resource group.
+func (d *driver) ClusterPlan(ctx context.Context, cluster *model.Cluster, op model.ClusterPlanOperation, opts ...model.ClusterPlanOption) (plan *model.ClusterPlan, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterPlan")
+	defer func() { cleanup(err) }()
+
+	var o model.ClusterPlanOptions
+	for _, fn := range opts {
+		if fn != nil {
+			fn(&o)
+		}
+	}
+
+	plan = &model.ClusterPlan{Operation: op, Cluster: cluster.Name, Driver: d.ID()}
+	switch op {
+	case model.ClusterPlanProvision:
+		results, err := d.ensureAKSClusterResources(ctx, cluster, true, o.Force)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = ensureResultChanges(results)
+	case model.ClusterPlanDeprovision:
+		if plan.Changes, err = d.planAKSClusterDeprovision(ctx, cluster); err != nil {
+			return nil, err
+		}
+	case model.ClusterPlanInstall:
+		if plan.Changes, err = d.planAKSClusterInstall(ctx, cluster); err != nil {
+			return nil, err
+		}
+	case model.ClusterPlanUninstall:
+		if plan.Changes, err = d.planAKSClusterUninstall(ctx, cluster); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cluster plan operation %q", op)
+	}
+	return plan, nil
+}
+
+// ensureResultChanges converts ensure results of a plan-mode run to cloud changes.
+func ensureResultChanges(results []ensureResult) []model.ClusterChange {
+	changes := make([]model.ClusterChange, 0, len(results))
+	for _, res := range results {
+		action := model.ClusterChangeNoOp
+		switch {
+		case res.Action == ensureUnchanged:
+		case !res.Existing:
+			action = model.ClusterChangeCreate
+		default:
+			action = model.ClusterChangeUpdate
+		}
+		changes = append(changes, model.ClusterChange{
+			Scope:    model.ClusterChangeScopeCloud,
+			Kind:     res.Step,
+			Resource: res.ID,
+			Action:   action,
+			Fields:   res.Changes,
+		})
+	}
+	return changes
+}
+
+// planAKSClusterDeprovision lists the legacy deployment record and the cluster resource group
+// with the resources it contains, all of which ClusterDeprovision deletes.
+func (d *driver) planAKSClusterDeprovision(ctx context.Context, cluster *model.Cluster) ([]model.ClusterChange, error) {
+	r, err := d.newEnsureRun(cluster, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var changes []model.ClusterChange
+	deleted := func(kind, id string) {
+		changes = append(changes, model.ClusterChange{Scope: model.ClusterChangeScopeCloud, Kind: kind, Resource: id, Action: model.ClusterChangeDelete})
+	}
+
+	if depName, err := d.azureDeploymentName(cluster); err == nil {
+		id := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Resources/deployments/%s", d.AzureSubscriptionId, depName)
+		found, err := r.arm.get(ctx, id, armAPIResources, &armResource{})
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			deleted("Deployment", id)
+		}
+	}
+
+	rgID := r.resourceGroupID()
+	found, err := r.arm.get(ctx, rgID, armAPIResourceGroups, &armResource{})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		changes = append(changes, model.ClusterChange{Scope: model.ClusterChangeScopeCloud, Kind: "ResourceGroup", Resource: rgID, Action: model.ClusterChangeNoOp})
+		return changes, nil
+	}
+	deleted("ResourceGroup", rgID)
+	err = r.arm.list(ctx, rgID+"/resources", armAPIResources, nil, func(raw json.RawMessage) error {
+		var res armResource
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return err
+		}
+		deleted(res.Type, res.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list resources in %s: %w", r.rg, err)
+	}
+	return changes, nil
+}
+
+// planAKSClusterInstall follows the steps of ClusterInstall: ingress namespace, ingress
+// ServiceAccount, access role assignments, the Traefik release and the Spot eviction helper.
+// SecretProviderClass resources for static certificates are applied together with the release
+// and not listed.
+func (d *driver) planAKSClusterInstall(ctx context.Context, cluster *model.Cluster) ([]model.ClusterChange, error) {
+	info, err := d.azureClusterInfo(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("resolve cluster resources: %w", err)
+	}
+	if info.TenantID == "" || info.IngressClientID == "" {
+		return nil, fmt.Errorf("ingress identity not found in resource group %s (run cluster provision)", info.ResourceGroup)
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []model.ClusterChange
+	ns := kube.IngressNamespace(cluster)
+	nsChange, err := kc.PlanNamespace(ctx, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, nsChange)
+
+	annotations := map[string]string{
+		"azure.workload.identity/tenant-id": info.TenantID,
+		"azure.workload.identity/client-id": info.IngressClientID,
+	}
+	saChange, err := kc.PlanServiceAccount(ctx, ns, kube.IngressServiceAccountName(cluster), annotations)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, saChange)
+
+	results, err := d.ensureAKSAccessRolesFromInfo(ctx, cluster, info, true)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, ensureResultChanges(results)...)
+
+	relChange, err := kc.PlanIngressTraefik(ctx, cluster, false)
+	if err != nil {
+		return nil, err
+	}
+	spotChange, err := kc.PlanSpotHandler(ctx, cluster, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, relChange, spotChange), nil
+}
+
+// planAKSClusterUninstall follows the steps of ClusterUninstall: the Traefik release, the Spot
+// eviction helper and the ingress namespace.
+func (d *driver) planAKSClusterUninstall(ctx context.Context, cluster *model.Cluster) ([]model.ClusterChange, error) {
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	relChange, err := kc.PlanIngressTraefik(ctx, cluster, true)
+	if err != nil {
+		return nil, err
+	}
+	spotChange, err := kc.PlanSpotHandler(ctx, cluster, true)
+	if err != nil {
+		return nil, err
+	}
+	nsChange, err := kc.PlanNamespace(ctx, kube.IngressNamespace(cluster), true)
+	if err != nil {
+		return nil, err
+	}
+	return []model.ClusterChange{relChange, spotChange, nsChange}, nil
+}
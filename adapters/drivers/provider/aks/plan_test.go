@@ -0,0 +1,80 @@
+package aks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestClusterPlanProvisionAndDeprovision(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1"}
+
+	plan, err := d.ClusterPlan(ctx, cluster, model.ClusterPlanProvision)
+	if err != nil {
+		t.Fatalf("plan provision: %v", err)
+	}
+	if len(plan.Changes) == 0 || len(fake.writes) != 0 {
+		t.Fatalf("plan provision: changes=%d writes=%v", len(plan.Changes), fake.writes)
+	}
+	for _, c := range plan.Changes {
+		if c.Scope != model.ClusterChangeScopeCloud || c.Action != model.ClusterChangeCreate {
+			t.Errorf("plan provision on empty subscription: %+v", c)
+		}
+	}
+
+	// Deprovision of a cluster that does not exist deletes nothing.
+	plan, err = d.ClusterPlan(ctx, cluster, model.ClusterPlanDeprovision)
+	if err != nil {
+		t.Fatalf("plan deprovision: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("plan deprovision before provision: %+v", plan.Changes)
+	}
+
+	if _, err := d.ensureAKSClusterResources(ctx, cluster, false, false); err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+
+	// Tag drift is planned as an update with before/after values.
+	rg, _ := d.clusterResourceGroupName(cluster)
+	rgKey := "/subscriptions/sub1/resourcegroups/" + rg
+	fake.resources[rgKey]["tags"].(map[string]any)[tagClusterName] = "other"
+	fake.writes = nil
+	plan, err = d.ClusterPlan(ctx, cluster, model.ClusterPlanProvision)
+	if err != nil {
+		t.Fatalf("plan provision: %v", err)
+	}
+	if len(fake.writes) != 0 {
+		t.Fatalf("plan must not write, got %v", fake.writes)
+	}
+	for _, c := range plan.Changes {
+		switch {
+		case c.Kind == "ResourceGroup":
+			if c.Action != model.ClusterChangeUpdate || len(c.Fields) != 1 || c.Fields[0].Before != "other" || c.Fields[0].After != "cls1" {
+				t.Errorf("resource group change = %+v", c)
+			}
+		case c.Action != model.ClusterChangeNoOp:
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+
+	plan, err = d.ClusterPlan(ctx, cluster, model.ClusterPlanDeprovision)
+	if err != nil {
+		t.Fatalf("plan deprovision: %v", err)
+	}
+	kinds := map[string]bool{}
+	for _, c := range plan.Changes {
+		if c.Action != model.ClusterChangeDelete {
+			t.Errorf("deprovision change = %+v", c)
+		}
+		kinds[c.Kind] = true
+	}
+	for _, kind := range []string{"ResourceGroup", armTypeManagedCluster, armTypeManagedIdentity, armTypeStorageAccount, armTypeLogAnalytics} {
+		if !kinds[kind] {
+			t.Errorf("deprovision plan misses %s: %+v", kind, plan.Changes)
+		}
+	}
+}
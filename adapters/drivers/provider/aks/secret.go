@@ -11,7 +11,6 @@ import (
 
 	"github.com/kompox/kompox/adapters/kube"
 	"github.com/kompox/kompox/domain/model"
-	"github.com/kompox/kompox/internal/logging"
 	"sigs.k8s.io/yaml"
 )
 
@@ -40,6 +39,25 @@ func (d *driver) parseKeyVaultSecretURL(raw string) (string, string, error) {
 	return kvName, objectName, nil
 }
 
+// SecretRead implements providerdrv.SecretReader. source must be a Key Vault secret URL
+// (https://<vault>.vault.azure.net/secrets/<name>[/<version>]); the latest version is read
+// when the version is omitted. The driver credential needs the Key Vault Secrets User role
+// on the secret.
+func (d *driver) SecretRead(ctx context.Context, cluster *model.Cluster, source string) (data []byte, err error) {
+	if _, _, perr := d.parseKeyVaultSecretURL(source); perr != nil {
+		return nil, fmt.Errorf("unsupported secret source %q (%v): %w", source, perr, model.ErrNotSupported)
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "SecretRead")
+	defer func() { cleanup(err) }()
+
+	value, err := d.azureKeyVaultSecretValue(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
 // spcNameForVault returns the SecretProviderClass name for a given Key Vault.
 // When multiple vaults are present, the name will be suffixed with the sanitized vault name.
 func (d *driver) spcNameForVault(vault string) string {
@@ -63,17 +81,6 @@ func (d *driver) ensureSecretProviderClassFromKeyVault(ctx context.Context, kc *
 		return nil, nil, nil
 	}
 
-	// Assign Key Vault Secrets User role to the managed identity for all referenced Key Vaults
-	// This needs to be done before creating SecretProviderClass resources
-	if outputs, err := d.azureDeploymentOutputs(ctx, cluster); err == nil {
-		if principalID, ok := outputs[outputIngressServiceAccountPrincipalID].(string); ok && principalID != "" {
-			if err := d.ensureAzureRoleKeyVaultSecret(ctx, cluster, principalID); err != nil {
-				// Log warning but continue with SPC creation
-				logging.FromContext(ctx).Warn(ctx, "failed to assign Key Vault roles", "error", err)
-			}
-		}
-	}
-
 	ns := kube.IngressNamespace(cluster)
 	// Group certificates by Key Vault name
 	type obj struct {
@@ -0,0 +1,67 @@
+package aks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// handlerTransport serves requests in-process so that Key Vault URLs need no DNS.
+type handlerTransport struct{ h http.Handler }
+
+func (t handlerTransport) Do(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func TestSecretRead(t *testing.T) {
+	var requests []string
+	vault := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Host+r.URL.Path+"?"+r.URL.RawQuery)
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/secrets/ghcr-token":
+			_, _ = w.Write([]byte(`{"value":"s3cret","id":"https://kv1.vault.azure.net/secrets/ghcr-token/v2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"SecretNotFound"}}`))
+		}
+	})
+	d := &driver{
+		TokenCredential: fakeCredential{},
+		armOptions: &arm.ClientOptions{ClientOptions: policy.ClientOptions{
+			Transport: handlerTransport{vault},
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		}},
+	}
+	ctx := context.Background()
+
+	data, err := d.SecretRead(ctx, &model.Cluster{Name: "cls1"}, "https://kv1.vault.azure.net/secrets/ghcr-token")
+	if err != nil {
+		t.Fatalf("SecretRead: %v", err)
+	}
+	if string(data) != "s3cret" {
+		t.Errorf("value = %q", data)
+	}
+	if len(requests) != 1 || requests[0] != "kv1.vault.azure.net/secrets/ghcr-token?api-version="+keyVaultAPIVersion {
+		t.Errorf("requests = %v", requests)
+	}
+
+	if _, err := d.SecretRead(ctx, &model.Cluster{Name: "cls1"}, "https://kv1.vault.azure.net/secrets/missing"); err == nil {
+		t.Errorf("expected error for missing secret")
+	}
+	if _, err := d.SecretRead(ctx, &model.Cluster{Name: "cls1"}, "https://example.com/token"); !errors.Is(err, model.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported for non Key Vault source, got %v", err)
+	}
+}
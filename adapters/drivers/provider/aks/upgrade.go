@@ -0,0 +1,214 @@
+package aks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// labelAgentPool is the node label AKS sets to the agent pool name. Several agent pools
+// (e.g., npuser1..npuser3) share one kompox.dev/node-pool value, so it selects a single pool.
+const labelAgentPool = "kubernetes.azure.com/agentpool"
+
+// armAgentPool is the subset of an AKS agent pool read by the upgrade methods.
+type armAgentPool struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Mode                       string `json:"mode,omitempty"`
+		OrchestratorVersion        string `json:"orchestratorVersion,omitempty"`
+		CurrentOrchestratorVersion string `json:"currentOrchestratorVersion,omitempty"`
+		NodeImageVersion           string `json:"nodeImageVersion,omitempty"`
+	} `json:"properties"`
+}
+
+// kubernetesVersion returns the version the pool nodes run.
+func (p *armAgentPool) kubernetesVersion() string {
+	if p.Properties.CurrentOrchestratorVersion != "" {
+		return p.Properties.CurrentOrchestratorVersion
+	}
+	return p.Properties.OrchestratorVersion
+}
+
+// compareKubernetesVersions orders Kubernetes versions ("1.33.2") numerically.
+func compareKubernetesVersions(a, b string) int {
+	va, err := version.ParseGeneric(a)
+	if err != nil {
+		return strings.Compare(a, b)
+	}
+	c, err := va.Compare(b)
+	if err != nil {
+		return strings.Compare(a, b)
+	}
+	return c
+}
+
+// upgradeTarget resolves the managed cluster of the cluster for the upgrade methods.
+func (d *driver) upgradeTarget(ctx context.Context, cluster *model.Cluster) (*armClient, string, error) {
+	r, err := d.newEnsureRun(cluster, false, false)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := r.discover(ctx, armTypeManagedCluster, d.clusterAKSName(cluster))
+	if err != nil {
+		return nil, "", fmt.Errorf("discover managed cluster: %w", err)
+	}
+	return r.arm, id, nil
+}
+
+// ClusterVersions returns the control plane version with its non-preview upgrades and the
+// Kubernetes and node image versions of each agent pool.
+func (d *driver) ClusterVersions(ctx context.Context, cluster *model.Cluster) (versions *model.ClusterVersions, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterVersions")
+	defer func() { cleanup(err) }()
+
+	arm, mcID, err := d.upgradeTarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	var profile struct {
+		Properties struct {
+			ControlPlaneProfile struct {
+				KubernetesVersion string `json:"kubernetesVersion"`
+				Upgrades          []struct {
+					KubernetesVersion string `json:"kubernetesVersion"`
+					IsPreview         bool   `json:"isPreview"`
+				} `json:"upgrades"`
+			} `json:"controlPlaneProfile"`
+		} `json:"properties"`
+	}
+	found, err := arm.get(ctx, mcID+"/upgradeProfiles/default", armAPIManagedClusters, &profile)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("managed cluster %s not found", mcID)
+	}
+	cp := profile.Properties.ControlPlaneProfile
+	versions = &model.ClusterVersions{KubernetesVersion: cp.KubernetesVersion}
+	for _, u := range cp.Upgrades {
+		if !u.IsPreview {
+			versions.Upgrades = append(versions.Upgrades, u.KubernetesVersion)
+		}
+	}
+	slices.SortFunc(versions.Upgrades, compareKubernetesVersions)
+
+	err = arm.list(ctx, mcID+"/agentPools", armAPIManagedClusters, nil, func(raw json.RawMessage) error {
+		var pool armAgentPool
+		if err := json.Unmarshal(raw, &pool); err != nil {
+			return fmt.Errorf("decode agent pool: %w", err)
+		}
+		var poolProfile struct {
+			Properties struct {
+				LatestNodeImageVersion string `json:"latestNodeImageVersion"`
+			} `json:"properties"`
+		}
+		if _, err := arm.get(ctx, mcID+"/agentPools/"+pool.Name+"/upgradeProfiles/default", armAPIManagedClusters, &poolProfile); err != nil {
+			return err
+		}
+		versions.NodePools = append(versions.NodePools, model.NodePoolVersions{
+			Name:                   pool.Name,
+			Mode:                   strings.ToLower(pool.Properties.Mode),
+			KubernetesVersion:      pool.kubernetesVersion(),
+			NodeImageVersion:       pool.Properties.NodeImageVersion,
+			LatestNodeImageVersion: poolProfile.Properties.LatestNodeImageVersion,
+			NodeSelector:           map[string]string{labelAgentPool: pool.Name},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(versions.NodePools, func(a, b model.NodePoolVersions) int { return strings.Compare(a.Name, b.Name) })
+	return versions, nil
+}
+
+// ClusterUpgrade upgrades the control plane only. The managed cluster is read and written back
+// with the new kubernetesVersion and every agent pool pinned to its current orchestratorVersion,
+// which AKS treats as a control-plane-only upgrade.
+func (d *driver) ClusterUpgrade(ctx context.Context, cluster *model.Cluster, kubernetesVersion string) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUpgrade")
+	defer func() { cleanup(err) }()
+
+	if kubernetesVersion == "" {
+		return fmt.Errorf("kubernetes version is required")
+	}
+	arm, mcID, err := d.upgradeTarget(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	var mc map[string]any
+	found, err := arm.get(ctx, mcID, armAPIManagedClusters, &mc)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("managed cluster %s not found", mcID)
+	}
+	props, _ := mc["properties"].(map[string]any)
+	if props == nil {
+		return fmt.Errorf("managed cluster %s has no properties", mcID)
+	}
+	from, _ := props["kubernetesVersion"].(string)
+	props["kubernetesVersion"] = kubernetesVersion
+	pools, _ := props["agentPoolProfiles"].([]any)
+	for _, p := range pools {
+		pool, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if cur, _ := pool["currentOrchestratorVersion"].(string); cur != "" {
+			pool["orchestratorVersion"] = cur
+		}
+	}
+
+	logging.FromContext(ctx).Info(ctx, "upgrading control plane", "managedCluster", mcID, "from", from, "to", kubernetesVersion)
+	return arm.put(ctx, mcID, armAPIManagedClusters, mc, nil)
+}
+
+// NodePoolUpgrade upgrades the agent pool to the Kubernetes version by updating its
+// orchestratorVersion, and to the latest node image with upgradeNodeImageVersion. A Kubernetes
+// version upgrade also installs the latest node image, so the image upgrade is skipped then.
+// AKS surges, cordons and drains the nodes according to the pool upgradeSettings.
+func (d *driver) NodePoolUpgrade(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolUpgradeOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolUpgrade")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolUpgradeOptions(opts...)
+	if o.KubernetesVersion == "" && !o.NodeImage {
+		return fmt.Errorf("nothing to upgrade for node pool %s", poolName)
+	}
+	arm, mcID, err := d.upgradeTarget(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	log := logging.FromContext(ctx)
+	poolID := mcID + "/agentPools/" + poolName
+
+	if o.KubernetesVersion != "" {
+		var pool map[string]any
+		found, err := arm.get(ctx, poolID, armAPIManagedClusters, &pool)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("agent pool %s not found", poolName)
+		}
+		props, _ := pool["properties"].(map[string]any)
+		if props == nil {
+			return fmt.Errorf("agent pool %s has no properties", poolName)
+		}
+		props["orchestratorVersion"] = o.KubernetesVersion
+		log.Info(ctx, "upgrading agent pool", "poolName", poolName, "kubernetesVersion", o.KubernetesVersion)
+		return arm.put(ctx, poolID, armAPIManagedClusters, pool, nil)
+	}
+
+	log.Info(ctx, "upgrading agent pool node image", "poolName", poolName)
+	return arm.send(ctx, http.MethodPost, poolID+"/upgradeNodeImageVersion", armAPIManagedClusters, nil, nil)
+}
@@ -0,0 +1,59 @@
+package aks
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestCompareKubernetesVersions(t *testing.T) {
+	versions := []string{"1.33.10", "1.32.5", "1.33.2"}
+	slices.SortFunc(versions, compareKubernetesVersions)
+	if strings.Join(versions, ",") != "1.32.5,1.33.2,1.33.10" {
+		t.Errorf("sorted = %v", versions)
+	}
+}
+
+func TestClusterAndNodePoolUpgrade(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEnsureTestDriver(t)
+	cluster := &model.Cluster{Name: "cls1"}
+	if _, err := d.ensureAKSClusterResources(ctx, cluster, false, false); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	rg, _ := d.clusterResourceGroupName(cluster)
+	mcID := "/subscriptions/sub1/resourceGroups/" + rg + "/providers/" + armTypeManagedCluster + "/" + d.clusterAKSName(cluster)
+	mc := fake.resources[strings.ToLower(mcID)]
+	props := mc["properties"].(map[string]any)
+	for _, p := range props["agentPoolProfiles"].([]any) {
+		p.(map[string]any)["currentOrchestratorVersion"] = "1.32.5"
+	}
+
+	if err := d.ClusterUpgrade(ctx, cluster, "1.33.2"); err != nil {
+		t.Fatalf("ClusterUpgrade: %v", err)
+	}
+	props = fake.resources[strings.ToLower(mcID)]["properties"].(map[string]any)
+	if props["kubernetesVersion"] != "1.33.2" {
+		t.Errorf("kubernetesVersion = %v", props["kubernetesVersion"])
+	}
+	for _, p := range props["agentPoolProfiles"].([]any) {
+		if v := p.(map[string]any)["orchestratorVersion"]; v != "1.32.5" {
+			t.Errorf("pool %v orchestratorVersion = %v, want pinned 1.32.5", p.(map[string]any)["name"], v)
+		}
+	}
+
+	poolID := mcID + "/agentPools/npuser1"
+	fake.resources[strings.ToLower(poolID)] = map[string]any{"name": "npuser1", "properties": map[string]any{"orchestratorVersion": "1.32.5", "mode": "User"}}
+	if err := d.NodePoolUpgrade(ctx, cluster, "npuser1", model.WithNodePoolUpgradeKubernetesVersion("1.33.2")); err != nil {
+		t.Fatalf("NodePoolUpgrade: %v", err)
+	}
+	if v := fake.resources[strings.ToLower(poolID)]["properties"].(map[string]any)["orchestratorVersion"]; v != "1.33.2" {
+		t.Errorf("pool orchestratorVersion = %v", v)
+	}
+	if err := d.NodePoolUpgrade(ctx, cluster, "npuser1"); err == nil {
+		t.Error("expected error without upgrade options")
+	}
+}
@@ -102,6 +102,25 @@ func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, a
 	return vb.DiskDelete(ctx, cluster, app, volName, diskName, opts...)
 }
 
+// VolumeDiskUpdate implements spec method.
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.DiskUpdate(ctx, cluster, app, volName, diskName, opts...)
+}
+
 // VolumeSnapshotList implements spec method.
 func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
 	if cluster == nil || app == nil {
@@ -159,6 +178,30 @@ func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluste
 	return vb.SnapshotDelete(ctx, cluster, app, volName, snapName, opts...)
 }
 
+// VolumeSnapshotCopy implements providerdrv.VolumeSnapshotCopier. Only Azure Managed Disk
+// snapshots (Type="disk") can be copied.
+func (d *driver) VolumeSnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	if cluster == nil || app == nil || source == nil {
+		return nil, fmt.Errorf("cluster/app/source nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+	disk, ok := vb.(*volumeBackendDisk)
+	if !ok {
+		return nil, fmt.Errorf("copying snapshots of %s volumes is not supported: %w", vol.Type, model.ErrNotSupported)
+	}
+
+	return disk.SnapshotCopy(ctx, cluster, app, volName, snapName, source, opts...)
+}
+
 // VolumeClass implements providerdrv.Driver VolumeClass method for AKS.
 // Returns opinionated defaults suitable for Azure Disk CSI or Azure Files CSI depending on volume type.
 func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
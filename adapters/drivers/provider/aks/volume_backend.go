@@ -22,6 +22,9 @@ type volumeBackend interface {
 	// DiskAssign assigns a disk to the specified logical volume.
 	DiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error
 
+	// DiskUpdate changes options of an existing disk in place.
+	DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error)
+
 	// SnapshotList returns a list of snapshots of the specified volume.
 	SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error)
 
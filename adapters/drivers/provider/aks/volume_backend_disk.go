@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"maps"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v7"
 	"github.com/kompox/kompox/domain/model"
@@ -121,8 +123,11 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 		return nil, fmt.Errorf("find volume %q: %w", volName, err)
 	}
 
-	// Get size from volume configuration
+	// Get size from volume configuration (options may require a larger disk, e.g. for clone sources)
 	size := vol.Size
+	if optionsStruct.Size > size {
+		size = optionsStruct.Size
+	}
 	sizeGB := int32(size >> 30) // Convert bytes to GB
 	if sizeGB < 1 {
 		sizeGB = 1
@@ -140,21 +145,24 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 		maps.Copy(volOptions, optionsStruct.Options)
 	}
 
+	settings, err := vb.driver.resolveDiskSettings(ctx, volOptions, sizeGB)
+	if err != nil {
+		return nil, err
+	}
+
 	tags := vb.driver.appResourceTags(app.Name)
 	tags[tagVolumeName] = to.Ptr(volName)
 	tags[tagDiskName] = to.Ptr(diskName)
 	tags[tagDiskAssigned] = to.Ptr("false")
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
 
 	// Ensure resource group exists before creating disk
 	// Get AKS system-assigned managed identity principal ID for role assignment (optional for non-AKS scenarios)
 	principalID := ""
-	outputs, err := vb.driver.azureDeploymentOutputs(ctx, cluster)
-	if err == nil {
-		if v, ok := outputs[outputAksClusterPrincipalID].(string); ok {
-			principalID = v
-		}
+	if info, err := vb.driver.azureClusterInfo(ctx, cluster); err == nil {
+		principalID = info.ClusterPrincipalID
 	}
-	// Ignore errors: principalID remains empty if outputs unavailable (e.g., aks-e2e-volume tests)
+	// Ignore errors: principalID remains empty if the cluster is unavailable (e.g., aks-e2e-volume tests)
 	// ensureAzureResourceGroupCreated will skip role assignment when principalID is empty
 	err = vb.driver.ensureAzureResourceGroupCreated(ctx, rg, vb.driver.appResourceTags(app.Name), principalID)
 	if err != nil {
@@ -169,7 +177,6 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 			Location: to.Ptr(vb.driver.AzureLocation),
 			Zones:    vb.zones(zone),
 			Tags:     tags,
-			SKU:      &armcompute.DiskSKU{},
 			Properties: &armcompute.DiskProperties{
 				DiskSizeGB: to.Ptr(sizeGB),
 				CreationData: &armcompute.CreationData{
@@ -177,7 +184,7 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 				},
 			},
 		}
-		vb.setDiskOptions(&disk, vol.Options)
+		vb.setDiskOptions(&disk, settings)
 
 		poller, err := disksClient.BeginCreateOrUpdate(ctx, rg, diskResourceName, disk, nil)
 		if err != nil {
@@ -206,7 +213,6 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 		Location: to.Ptr(vb.driver.AzureLocation),
 		Zones:    vb.zones(zone),
 		Tags:     tags,
-		SKU:      &armcompute.DiskSKU{},
 		Properties: &armcompute.DiskProperties{
 			DiskSizeGB: to.Ptr(sizeGB),
 			CreationData: &armcompute.CreationData{
@@ -215,7 +221,7 @@ func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Clus
 			},
 		},
 	}
-	vb.setDiskOptions(&disk, vol.Options)
+	vb.setDiskOptions(&disk, settings)
 
 	poller, err := disksClient.BeginCreateOrUpdate(ctx, rg, diskResourceName, disk, nil)
 	if err != nil {
@@ -336,6 +342,89 @@ func (vb *volumeBackendDisk) DiskAssign(ctx context.Context, cluster *model.Clus
 	return nil
 }
 
+// DiskUpdate changes IOPS, throughput and bursting of an existing Azure Managed Disk (Type="disk")
+// in place. Other options (SKU, sector size, encryption) cannot be changed online and are rejected.
+func (vb *volumeBackendDisk) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	var optionsStruct model.VolumeDiskUpdateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+	if len(optionsStruct.Options) == 0 {
+		return nil, diskOptionsError("no options to update")
+	}
+	for k := range optionsStruct.Options {
+		if !slices.Contains(diskOptionsOnline, k) {
+			return nil, diskOptionsError("option %q cannot be changed online (supported: %s)", k, strings.Join(diskOptionsOnline, ", "))
+		}
+	}
+	update, err := parseDiskOptions(optionsStruct.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	rg, err := vb.driver.appResourceGroupName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app RG: %w", err)
+	}
+	diskResourceName, err := vb.driver.appDiskName(app, volName, diskName)
+	if err != nil {
+		return nil, fmt.Errorf("generate disk resource name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	disksClient, err := armcompute.NewDisksClient(vb.driver.AzureSubscriptionId, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new disks client: %w", err)
+	}
+
+	diskRes, err := disksClient.Get(ctx, rg, diskResourceName, nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, fmt.Errorf("disk not found: %s", diskName)
+		}
+		return nil, fmt.Errorf("get disk %s: %w", diskResourceName, err)
+	}
+	current := &diskRes.Disk
+	if current.Properties == nil {
+		return nil, fmt.Errorf("disk %s missing properties", diskResourceName)
+	}
+
+	// Validate the requested values against the current SKU and size
+	settings := &diskSettings{IOPS: update.IOPS, MBps: update.MBps, BurstingEnabled: update.BurstingEnabled}
+	if current.SKU != nil && current.SKU.Name != nil {
+		settings.SKU = *current.SKU.Name
+	}
+	var sizeGB int32
+	if current.Properties.DiskSizeGB != nil {
+		sizeGB = *current.Properties.DiskSizeGB
+	}
+	if err := settings.validate(sizeGB); err != nil {
+		return nil, err
+	}
+
+	patch := armcompute.DiskUpdate{Properties: &armcompute.DiskUpdateProperties{
+		DiskIOPSReadWrite: update.IOPS,
+		DiskMBpsReadWrite: update.MBps,
+		BurstingEnabled:   update.BurstingEnabled,
+	}}
+	poller, err := disksClient.BeginUpdate(ctx, rg, diskResourceName, patch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("update disk %s: %w", diskResourceName, err)
+	}
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("poll update disk %s: %w", diskResourceName, err)
+	}
+
+	volumeDisk, err := vb.newDisk(&resp.Disk, volName)
+	if err != nil {
+		return nil, fmt.Errorf("create VolumeDisk from disk: %w", err)
+	}
+	return volumeDisk, nil
+}
+
 // SnapshotList lists snapshots for an Azure Managed Disk (Type="disk").
 func (vb *volumeBackendDisk) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
 	rg, err := vb.driver.appResourceGroupName(app)
@@ -418,20 +507,33 @@ func (vb *volumeBackendDisk) SnapshotCreate(ctx context.Context, cluster *model.
 		return nil, fmt.Errorf("generate snapshot resource name: %w", err)
 	}
 
+	// Encrypt the snapshot with the volume disk encryption set
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume %q: %w", volName, err)
+	}
+	encryption, err := vb.driver.resolveSnapshotEncryption(ctx, vol.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var optionsStruct model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
 	tags := vb.driver.appResourceTags(app.Name)
 	tags[tagVolumeName] = to.Ptr(volName)
 	tags[tagSnapshotName] = to.Ptr(snapName)
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
 
 	// Ensure resource group exists before creating snapshot
 	// Get AKS system-assigned managed identity principal ID for role assignment (optional for non-AKS scenarios)
 	principalID := ""
-	outputs, err := vb.driver.azureDeploymentOutputs(ctx, cluster)
-	if err == nil {
-		if v, ok := outputs[outputAksClusterPrincipalID].(string); ok {
-			principalID = v
-		}
+	if info, err := vb.driver.azureClusterInfo(ctx, cluster); err == nil {
+		principalID = info.ClusterPrincipalID
 	}
-	// Ignore errors: principalID remains empty if outputs unavailable (e.g., aks-e2e-volume tests)
+	// Ignore errors: principalID remains empty if the cluster is unavailable (e.g., aks-e2e-volume tests)
 	// ensureAzureResourceGroupCreated will skip role assignment when principalID is empty
 	err = vb.driver.ensureAzureResourceGroupCreated(ctx, rg, vb.driver.appResourceTags(app.Name), principalID)
 	if err != nil {
@@ -473,6 +575,10 @@ func (vb *volumeBackendDisk) SnapshotCreate(ctx context.Context, cluster *model.
 			},
 		},
 	}
+	if optionsStruct.Incremental {
+		snapshot.Properties.Incremental = to.Ptr(true)
+	}
+	snapshot.Properties.Encryption = encryption
 
 	poller, err := snapsClient.BeginCreateOrUpdate(ctx, rg, snapResourceName, snapshot, nil)
 	if err != nil {
@@ -492,6 +598,152 @@ func (vb *volumeBackendDisk) SnapshotCreate(ctx context.Context, cluster *model.
 	return volumeSnapshot, nil
 }
 
+// SnapshotCopy copies a snapshot of another Azure location or subscription into the app
+// resource group (Type="disk"). A snapshot in another location is copied with CopyStart, which
+// requires an incremental source snapshot, and the background copy is polled until complete.
+func (vb *volumeBackendDisk) SnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	rg, err := vb.driver.appResourceGroupName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app RG: %w", err)
+	}
+	srcID, err := arm.ParseResourceID(source.Handle)
+	if err != nil || !strings.EqualFold(srcID.ResourceType.String(), "Microsoft.Compute/snapshots") {
+		return nil, fmt.Errorf("copy source %q is not an Azure snapshot resource ID", source.Handle)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Hour)
+	defer cancel()
+
+	srcClient, err := armcompute.NewSnapshotsClient(srcID.SubscriptionID, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new snapshots client: %w", err)
+	}
+	srcRes, err := srcClient.Get(ctx, srcID.ResourceGroupName, srcID.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get source snapshot: %w", err)
+	}
+	incremental := srcRes.Properties != nil && srcRes.Properties.Incremental != nil && *srcRes.Properties.Incremental
+	createOption := armcompute.DiskCreateOptionCopy
+	if srcRes.Location != nil && !strings.EqualFold(*srcRes.Location, vb.driver.AzureLocation) {
+		if !incremental {
+			return nil, fmt.Errorf("cross-region copy from %s requires an incremental snapshot", *srcRes.Location)
+		}
+		createOption = armcompute.DiskCreateOptionCopyStart
+	}
+
+	items, err := vb.SnapshotList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		snapName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == snapName {
+				return nil, fmt.Errorf("snapshot %q already exists", snapName)
+			}
+		}
+	}
+	snapResourceName, err := vb.driver.appSnapshotName(app, volName, snapName)
+	if err != nil {
+		return nil, fmt.Errorf("generate snapshot resource name: %w", err)
+	}
+
+	// Encrypt the copy with the volume disk encryption set, which must be in the target region
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume %q: %w", volName, err)
+	}
+	encryption, err := vb.driver.resolveSnapshotEncryption(ctx, vol.Options)
+	if err != nil {
+		return nil, err
+	}
+	if encryption != nil {
+		if err := vb.driver.checkDiskEncryptionSetLocation(ctx, *encryption.DiskEncryptionSetID, vb.driver.AzureLocation); err != nil {
+			return nil, err
+		}
+	}
+
+	var optionsStruct model.VolumeSnapshotCopyOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+	tags := vb.driver.appResourceTags(app.Name)
+	tags[tagVolumeName] = to.Ptr(volName)
+	tags[tagSnapshotName] = to.Ptr(snapName)
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
+
+	principalID := ""
+	if info, err := vb.driver.azureClusterInfo(ctx, cluster); err == nil {
+		principalID = info.ClusterPrincipalID
+	}
+	if err := vb.driver.ensureAzureResourceGroupCreated(ctx, rg, vb.driver.appResourceTags(app.Name), principalID); err != nil {
+		return nil, fmt.Errorf("ensure resource group: %w", err)
+	}
+
+	snapshot := armcompute.Snapshot{
+		Location: to.Ptr(vb.driver.AzureLocation),
+		Tags:     tags,
+		Properties: &armcompute.SnapshotProperties{
+			CreationData: &armcompute.CreationData{
+				CreateOption:     to.Ptr(createOption),
+				SourceResourceID: to.Ptr(source.Handle),
+			},
+			Incremental: to.Ptr(incremental),
+			Encryption:  encryption,
+		},
+	}
+
+	snapsClient, err := armcompute.NewSnapshotsClient(vb.driver.AzureSubscriptionId, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new snapshots client: %w", err)
+	}
+	poller, err := snapsClient.BeginCreateOrUpdate(ctx, rg, snapResourceName, snapshot, nil)
+	if err != nil {
+		return nil, fmt.Errorf("copy snapshot: %w", err)
+	}
+	getResp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("poll snapshot: %w", err)
+	}
+
+	// CopyStart completes the resource before the data: wait for the background copy.
+	res := getResp.Snapshot
+	for createOption == armcompute.DiskCreateOptionCopyStart {
+		props := res.Properties
+		if props != nil && props.CopyCompletionError != nil {
+			msg := ""
+			if props.CopyCompletionError.ErrorMessage != nil {
+				msg = *props.CopyCompletionError.ErrorMessage
+			}
+			return nil, fmt.Errorf("snapshot copy failed: %s", msg)
+		}
+		if props == nil || props.CompletionPercent == nil || *props.CompletionPercent >= 100 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for snapshot copy (%.0f%%): %w", *props.CompletionPercent, ctx.Err())
+		case <-time.After(15 * time.Second):
+		}
+		r, err := snapsClient.Get(ctx, rg, snapResourceName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get snapshot: %w", err)
+		}
+		res = r.Snapshot
+	}
+
+	volumeSnapshot, err := vb.newSnapshot(&res, volName)
+	if err != nil {
+		return nil, fmt.Errorf("create VolumeSnapshot from snapshot: %w", err)
+	}
+	return volumeSnapshot, nil
+}
+
 // SnapshotDelete deletes a snapshot of an Azure Managed Disk (Type="disk").
 func (vb *volumeBackendDisk) SnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
 	rg, err := vb.driver.appResourceGroupName(app)
@@ -522,7 +774,11 @@ func (vb *volumeBackendDisk) SnapshotDelete(ctx context.Context, cluster *model.
 }
 
 // Class returns Azure Managed Disk provisioning parameters (Type="disk").
+// Volume options are validated so that invalid settings are reported before any disk is created.
 func (vb *volumeBackendDisk) Class(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	if _, err := vb.driver.resolveDiskSettings(ctx, vol.Options, int32(vol.Size>>30)); err != nil {
+		return model.VolumeClass{}, err
+	}
 	return model.VolumeClass{
 		StorageClassName: "managed-csi",
 		CSIDriver:        "disk.csi.azure.com",
@@ -591,16 +847,26 @@ func (vb *volumeBackendDisk) newDisk(disk *armcompute.Disk, volName string) (*mo
 		zone = *disk.Zones[0]
 	}
 
+	// Extract user metadata and lineage
+	labels, description := userMetadataFromTags(tags)
+	sourceHandle := ""
+	if cd := disk.Properties.CreationData; cd != nil && cd.SourceResourceID != nil {
+		sourceHandle = *cd.SourceResourceID
+	}
+
 	return &model.VolumeDisk{
-		Name:       diskName,
-		VolumeName: volName,
-		Assigned:   assigned,
-		Size:       int64(size) << 30,
-		Zone:       zone,
-		Options:    vb.diskOptions(disk),
-		Handle:     *disk.ID,
-		CreatedAt:  created,
-		UpdatedAt:  created,
+		Name:         diskName,
+		VolumeName:   volName,
+		Assigned:     assigned,
+		Size:         int64(size) << 30,
+		Zone:         zone,
+		Options:      vb.diskOptions(disk),
+		Handle:       *disk.ID,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
 	}, nil
 }
 
@@ -614,84 +880,51 @@ func (vb *volumeBackendDisk) zones(zone string) []*string {
 	return []*string{to.Ptr(zone)}
 }
 
-// setDiskOptions applies Azure Disk SKU and performance options from volume options.
-// Default SKU is Premium_LRS if not specified.
-func (vb *volumeBackendDisk) setDiskOptions(disk *armcompute.Disk, options map[string]any) {
-	// Initialize SKU with default
-	if disk.SKU == nil {
-		disk.SKU = &armcompute.DiskSKU{}
-	}
-	disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesPremiumLRS) // default
-
-	if options == nil {
-		return
-	}
-
-	// Apply SKU if specified
-	if skuValue, ok := options["sku"].(string); ok && skuValue != "" {
-		switch skuValue {
-		case "Standard_LRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesStandardLRS)
-		case "Premium_LRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesPremiumLRS)
-		case "StandardSSD_LRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesStandardSSDLRS)
-		case "UltraSSD_LRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesUltraSSDLRS)
-		case "Premium_ZRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesPremiumZRS)
-		case "StandardSSD_ZRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesStandardSSDZRS)
-		case "PremiumV2_LRS":
-			disk.SKU.Name = to.Ptr(armcompute.DiskStorageAccountTypesPremiumV2LRS)
-		}
-	}
-
-	// Apply performance options if specified
+// setDiskOptions applies validated Azure Disk SKU, performance, sector size and encryption settings.
+// logicalSectorSize only applies to empty disks; copies inherit it from the source.
+func (vb *volumeBackendDisk) setDiskOptions(disk *armcompute.Disk, settings *diskSettings) {
+	disk.SKU = &armcompute.DiskSKU{Name: to.Ptr(settings.SKU)}
 	if disk.Properties == nil {
-		return
+		disk.Properties = &armcompute.DiskProperties{}
 	}
-
-	// Set IOPS if specified
-	if iopsValue, ok := options["iops"]; ok {
-		switch v := iopsValue.(type) {
-		case int:
-			disk.Properties.DiskIOPSReadWrite = to.Ptr(int64(v))
-		case int64:
-			disk.Properties.DiskIOPSReadWrite = to.Ptr(v)
-		case float64:
-			disk.Properties.DiskIOPSReadWrite = to.Ptr(int64(v))
-		}
-	}
-
-	// Set MBps if specified
-	if mbpsValue, ok := options["mbps"]; ok {
-		switch v := mbpsValue.(type) {
-		case int:
-			disk.Properties.DiskMBpsReadWrite = to.Ptr(int64(v))
-		case int64:
-			disk.Properties.DiskMBpsReadWrite = to.Ptr(v)
-		case float64:
-			disk.Properties.DiskMBpsReadWrite = to.Ptr(int64(v))
-		}
+	disk.Properties.DiskIOPSReadWrite = settings.IOPS
+	disk.Properties.DiskMBpsReadWrite = settings.MBps
+	disk.Properties.BurstingEnabled = settings.BurstingEnabled
+	disk.Properties.Encryption = settings.encryption()
+	if cd := disk.Properties.CreationData; cd != nil && cd.CreateOption != nil && *cd.CreateOption == armcompute.DiskCreateOptionEmpty {
+		cd.LogicalSectorSize = settings.LogicalSectorSize
 	}
 }
 
-// diskOptions extracts Azure Disk SKU and performance options into an options map.
+// diskOptions extracts Azure Disk SKU, performance and encryption options into an options map.
 func (vb *volumeBackendDisk) diskOptions(disk *armcompute.Disk) map[string]any {
 	options := make(map[string]any)
 	// Extract SKU
 	if disk.SKU != nil && disk.SKU.Name != nil {
 		skuName := string(*disk.SKU.Name)
-		options["sku"] = skuName
+		options[diskOptionSKU] = skuName
 	}
-	// Extract performance options
-	if disk.Properties != nil {
-		if disk.Properties.DiskIOPSReadWrite != nil {
-			options["iops"] = *disk.Properties.DiskIOPSReadWrite
+	// Extract performance and encryption options
+	if p := disk.Properties; p != nil {
+		if p.DiskIOPSReadWrite != nil {
+			options[diskOptionIOPS] = *p.DiskIOPSReadWrite
 		}
-		if disk.Properties.DiskMBpsReadWrite != nil {
-			options["mbps"] = *disk.Properties.DiskMBpsReadWrite
+		if p.DiskMBpsReadWrite != nil {
+			options[diskOptionMBps] = *p.DiskMBpsReadWrite
+		}
+		if p.BurstingEnabled != nil {
+			options[diskOptionBurstingEnabled] = *p.BurstingEnabled
+		}
+		if p.CreationData != nil && p.CreationData.LogicalSectorSize != nil {
+			options[diskOptionLogicalSectorSize] = *p.CreationData.LogicalSectorSize
+		}
+		if e := p.Encryption; e != nil {
+			if e.DiskEncryptionSetID != nil && *e.DiskEncryptionSetID != "" {
+				options[diskOptionDiskEncryptionSetID] = *e.DiskEncryptionSetID
+			}
+			if e.Type != nil {
+				options[diskOptionEncryptionType] = string(*e.Type)
+			}
 		}
 	}
 	return options
@@ -727,16 +960,84 @@ func (vb *volumeBackendDisk) newSnapshot(snap *armcompute.Snapshot, volName stri
 		created = *snap.Properties.TimeCreated
 	}
 
+	// Extract user metadata and lineage
+	labels, description := userMetadataFromTags(tags)
+	sourceHandle := ""
+	if cd := snap.Properties.CreationData; cd != nil && cd.SourceResourceID != nil {
+		sourceHandle = *cd.SourceResourceID
+	}
+
 	return &model.VolumeSnapshot{
-		Name:       snapName,
-		VolumeName: volName,
-		Size:       int64(size) << 30,
-		Handle:     *snap.ID,
-		CreatedAt:  created,
-		UpdatedAt:  created,
+		Name:         snapName,
+		VolumeName:   volName,
+		Size:         int64(size) << 30,
+		Handle:       *snap.ID,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
 	}, nil
 }
 
+// setUserMetadataTags stores user labels and description as Azure resource tags.
+func setUserMetadataTags(tags map[string]*string, labels map[string]string, description string) {
+	for k, v := range labels {
+		tags[tagLabelPrefix+k] = to.Ptr(v)
+	}
+	if description != "" {
+		tags[tagDescription] = to.Ptr(description)
+	}
+}
+
+// userMetadataFromTags extracts user labels and description from Azure resource tags.
+// Returns nil labels when none are set.
+func userMetadataFromTags(tags map[string]*string) (map[string]string, string) {
+	var labels map[string]string
+	description := ""
+	for k, v := range tags {
+		if v == nil {
+			continue
+		}
+		if k == tagDescription {
+			description = *v
+			continue
+		}
+		if key, ok := strings.CutPrefix(k, tagLabelPrefix); ok && key != "" {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[key] = *v
+		}
+	}
+	return labels, description
+}
+
+// checkDiskEncryptionSetLocation checks that the disk encryption set exists in location.
+// Azure requires the disk encryption set of a disk or snapshot to be in the same region.
+func (d *driver) checkDiskEncryptionSetLocation(ctx context.Context, desID, location string) error {
+	rid, err := arm.ParseResourceID(desID)
+	if err != nil {
+		return fmt.Errorf("parse disk encryption set ID: %w", err)
+	}
+	client, err := armcompute.NewDiskEncryptionSetsClient(rid.SubscriptionID, d.TokenCredential, nil)
+	if err != nil {
+		return fmt.Errorf("new disk encryption sets client: %w", err)
+	}
+	res, err := client.Get(ctx, rid.ResourceGroupName, rid.Name, nil)
+	if err != nil {
+		return fmt.Errorf("get disk encryption set %s: %w", desID, err)
+	}
+	if res.Location == nil || !strings.EqualFold(strings.ReplaceAll(*res.Location, " ", ""), strings.ReplaceAll(location, " ", "")) {
+		got := ""
+		if res.Location != nil {
+			got = *res.Location
+		}
+		return diskOptionsError("disk encryption set %s is in %s, not in the target region %s; set %s of the volume to a disk encryption set in %s", desID, got, location, diskOptionDiskEncryptionSetID, location)
+	}
+	return nil
+}
+
 // resolveSourceResourceID resolves a source string to an Azure resource ID.
 // - "" (empty) -> error
 // - "snapshot:name" -> Kompox managed snapshot
@@ -0,0 +1,312 @@
+package aks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v7"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Azure Managed Disk option keys accepted in app.volumes.options (Type="disk").
+const (
+	diskOptionSKU                 = "sku"
+	diskOptionIOPS                = "iops"
+	diskOptionMBps                = "mbps"
+	diskOptionBurstingEnabled     = "burstingEnabled"
+	diskOptionLogicalSectorSize   = "logicalSectorSize"
+	diskOptionDiskEncryptionSetID = "diskEncryptionSetId"
+	diskOptionEncryptionType      = "encryptionType"
+)
+
+// diskOptionsOnline lists options that can be changed on an existing disk by DiskUpdate.
+var diskOptionsOnline = []string{diskOptionIOPS, diskOptionMBps, diskOptionBurstingEnabled}
+
+// defaultDiskSKU is used when the sku option is not specified.
+const defaultDiskSKU = armcompute.DiskStorageAccountTypesPremiumLRS
+
+// Performance limits of disks with provisioned IOPS/throughput.
+// Size dependent limits are enforced by Azure at provisioning time.
+const (
+	premiumV2MinIOPS = 3000
+	premiumV2MaxIOPS = 80000
+	premiumV2MinMBps = 125
+	premiumV2MaxMBps = 1200
+	ultraMinIOPS     = 100
+	ultraMaxIOPS     = 400000
+	ultraMinMBps     = 1
+	ultraMaxMBps     = 10000
+	burstingMinGB    = 513 // on-demand bursting requires Premium SSD larger than 512 GiB
+)
+
+// diskSettings holds typed and validated Azure Managed Disk options.
+type diskSettings struct {
+	SKU                 armcompute.DiskStorageAccountTypes
+	IOPS                *int64
+	MBps                *int64
+	BurstingEnabled     *bool
+	LogicalSectorSize   *int32
+	DiskEncryptionSetID string
+	EncryptionType      armcompute.EncryptionType
+	// Unknown lists option keys not recognized by this backend (ignored).
+	Unknown []string
+}
+
+// diskOptionsError returns an error wrapping model.ErrVolumeOptionsInvalid.
+func diskOptionsError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", model.ErrVolumeOptionsInvalid, fmt.Sprintf(format, args...))
+}
+
+// parseDiskOptions converts volume options into diskSettings. Values of the wrong type are
+// rejected. Unknown keys, which may be meant for other providers, are collected in Unknown.
+// Cross-option constraints are checked by validate.
+func parseDiskOptions(options map[string]any) (*diskSettings, error) {
+	s := &diskSettings{}
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := options[k]
+		switch k {
+		case diskOptionSKU:
+			str, ok := v.(string)
+			if !ok {
+				return nil, diskOptionsError("%s must be a string", k)
+			}
+			sku := armcompute.DiskStorageAccountTypes(strings.TrimSpace(str))
+			if !slices.Contains(armcompute.PossibleDiskStorageAccountTypesValues(), sku) {
+				return nil, diskOptionsError("unsupported %s %q", k, str)
+			}
+			s.SKU = sku
+		case diskOptionIOPS:
+			n, err := diskOptionInt(k, v)
+			if err != nil {
+				return nil, err
+			}
+			s.IOPS = to.Ptr(n)
+		case diskOptionMBps:
+			n, err := diskOptionInt(k, v)
+			if err != nil {
+				return nil, err
+			}
+			s.MBps = to.Ptr(n)
+		case diskOptionBurstingEnabled:
+			b, err := diskOptionBool(k, v)
+			if err != nil {
+				return nil, err
+			}
+			s.BurstingEnabled = to.Ptr(b)
+		case diskOptionLogicalSectorSize:
+			n, err := diskOptionInt(k, v)
+			if err != nil {
+				return nil, err
+			}
+			if n != 512 && n != 4096 {
+				return nil, diskOptionsError("%s must be 512 or 4096", k)
+			}
+			s.LogicalSectorSize = to.Ptr(int32(n))
+		case diskOptionDiskEncryptionSetID:
+			str, ok := v.(string)
+			if !ok {
+				return nil, diskOptionsError("%s must be a string", k)
+			}
+			if err := validateDiskEncryptionSetID(str); err != nil {
+				return nil, err
+			}
+			s.DiskEncryptionSetID = strings.TrimSpace(str)
+		case diskOptionEncryptionType:
+			str, ok := v.(string)
+			if !ok {
+				return nil, diskOptionsError("%s must be a string", k)
+			}
+			et := armcompute.EncryptionType(strings.TrimSpace(str))
+			if !slices.Contains(armcompute.PossibleEncryptionTypeValues(), et) {
+				return nil, diskOptionsError("unsupported %s %q", k, str)
+			}
+			s.EncryptionType = et
+		default:
+			s.Unknown = append(s.Unknown, k)
+		}
+	}
+	return s, nil
+}
+
+// diskOptionInt accepts YAML/JSON numbers and numeric strings as a positive integer.
+func diskOptionInt(key string, v any) (int64, error) {
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint64:
+		if x > math.MaxInt64 {
+			return 0, diskOptionsError("%s is out of range", key)
+		}
+		n = int64(x)
+	case float64:
+		if x != math.Trunc(x) {
+			return 0, diskOptionsError("%s must be an integer", key)
+		}
+		n = int64(x)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64)
+		if err != nil {
+			return 0, diskOptionsError("%s must be an integer", key)
+		}
+		n = i
+	default:
+		return 0, diskOptionsError("%s must be an integer", key)
+	}
+	if n <= 0 {
+		return 0, diskOptionsError("%s must be positive", key)
+	}
+	return n, nil
+}
+
+// diskOptionBool accepts booleans and boolean strings.
+func diskOptionBool(key string, v any) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(x))
+		if err != nil {
+			return false, diskOptionsError("%s must be a boolean", key)
+		}
+		return b, nil
+	default:
+		return false, diskOptionsError("%s must be a boolean", key)
+	}
+}
+
+// validateDiskEncryptionSetID checks that id is an ARM resource ID of a disk encryption set.
+func validateDiskEncryptionSetID(id string) error {
+	rid, err := arm.ParseResourceID(strings.TrimSpace(id))
+	if err != nil {
+		return diskOptionsError("%s must be a resource ID: %v", diskOptionDiskEncryptionSetID, err)
+	}
+	if !strings.EqualFold(rid.ResourceType.String(), "Microsoft.Compute/diskEncryptionSets") {
+		return diskOptionsError("%s must be a Microsoft.Compute/diskEncryptionSets resource ID, got %s", diskOptionDiskEncryptionSetID, rid.ResourceType.String())
+	}
+	return nil
+}
+
+// provisioned reports whether the SKU has independently provisioned IOPS and throughput.
+func (s *diskSettings) provisioned() bool {
+	return s.SKU == armcompute.DiskStorageAccountTypesUltraSSDLRS || s.SKU == armcompute.DiskStorageAccountTypesPremiumV2LRS
+}
+
+// validate applies the default SKU and checks cross-option constraints for a disk of sizeGB
+// (0 skips size dependent checks).
+func (s *diskSettings) validate(sizeGB int32) error {
+	if s.SKU == "" {
+		s.SKU = defaultDiskSKU
+	}
+	if (s.IOPS != nil || s.MBps != nil) && !s.provisioned() {
+		return diskOptionsError("%s/%s require sku UltraSSD_LRS or PremiumV2_LRS, got %s", diskOptionIOPS, diskOptionMBps, s.SKU)
+	}
+	switch s.SKU {
+	case armcompute.DiskStorageAccountTypesPremiumV2LRS:
+		if err := checkDiskRange(diskOptionIOPS, s.IOPS, premiumV2MinIOPS, premiumV2MaxIOPS, s.SKU); err != nil {
+			return err
+		}
+		if err := checkDiskRange(diskOptionMBps, s.MBps, premiumV2MinMBps, premiumV2MaxMBps, s.SKU); err != nil {
+			return err
+		}
+	case armcompute.DiskStorageAccountTypesUltraSSDLRS:
+		if err := checkDiskRange(diskOptionIOPS, s.IOPS, ultraMinIOPS, ultraMaxIOPS, s.SKU); err != nil {
+			return err
+		}
+		if err := checkDiskRange(diskOptionMBps, s.MBps, ultraMinMBps, ultraMaxMBps, s.SKU); err != nil {
+			return err
+		}
+	}
+	if s.BurstingEnabled != nil && *s.BurstingEnabled {
+		if s.SKU != armcompute.DiskStorageAccountTypesPremiumLRS && s.SKU != armcompute.DiskStorageAccountTypesPremiumZRS {
+			return diskOptionsError("%s requires sku Premium_LRS or Premium_ZRS, got %s", diskOptionBurstingEnabled, s.SKU)
+		}
+		if sizeGB > 0 && sizeGB < burstingMinGB {
+			return diskOptionsError("%s requires a disk larger than 512 GiB, got %d GiB", diskOptionBurstingEnabled, sizeGB)
+		}
+	}
+	if s.LogicalSectorSize != nil && !s.provisioned() {
+		return diskOptionsError("%s requires sku UltraSSD_LRS or PremiumV2_LRS, got %s", diskOptionLogicalSectorSize, s.SKU)
+	}
+	if s.EncryptionType != "" {
+		cmk := s.EncryptionType != armcompute.EncryptionTypeEncryptionAtRestWithPlatformKey
+		if cmk && s.DiskEncryptionSetID == "" {
+			return diskOptionsError("%s %s requires %s", diskOptionEncryptionType, s.EncryptionType, diskOptionDiskEncryptionSetID)
+		}
+		if !cmk && s.DiskEncryptionSetID != "" {
+			return diskOptionsError("%s %s cannot be used with %s", diskOptionEncryptionType, s.EncryptionType, diskOptionDiskEncryptionSetID)
+		}
+	}
+	return nil
+}
+
+// checkDiskRange checks an optional performance value against the SKU limits.
+func checkDiskRange(key string, v *int64, min, max int64, sku armcompute.DiskStorageAccountTypes) error {
+	if v != nil && (*v < min || *v > max) {
+		return diskOptionsError("%s for %s must be between %d and %d, got %d", key, sku, min, max, *v)
+	}
+	return nil
+}
+
+// encryption returns the Azure encryption settings, or nil for platform managed keys.
+func (s *diskSettings) encryption() *armcompute.Encryption {
+	if s.DiskEncryptionSetID == "" {
+		return nil
+	}
+	et := s.EncryptionType
+	if et == "" {
+		et = armcompute.EncryptionTypeEncryptionAtRestWithCustomerKey
+	}
+	return &armcompute.Encryption{DiskEncryptionSetID: to.Ptr(s.DiskEncryptionSetID), Type: to.Ptr(et)}
+}
+
+// resolveDiskSettings parses and validates volume options for a disk of sizeGB, applying the
+// provider level disk encryption set default and enforcing AZURE_DISK_REQUIRE_CMK.
+// Unknown option keys are logged as warnings.
+func (d *driver) resolveDiskSettings(ctx context.Context, options map[string]any, sizeGB int32) (*diskSettings, error) {
+	s, err := parseDiskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Unknown) > 0 {
+		logging.FromContext(ctx).Warn(ctx, "Ignoring unknown Azure Managed Disk volume options", "options", strings.Join(s.Unknown, ","))
+	}
+	if s.DiskEncryptionSetID == "" && s.EncryptionType != armcompute.EncryptionTypeEncryptionAtRestWithPlatformKey {
+		s.DiskEncryptionSetID = d.diskEncryptionSetID
+	}
+	if err := s.validate(sizeGB); err != nil {
+		return nil, err
+	}
+	if d.diskRequireCMK && s.DiskEncryptionSetID == "" {
+		return nil, diskOptionsError("customer-managed key is required by %s; set %s or the %s option", keyDiskRequireCMK, keyDiskEncryptionSetID, diskOptionDiskEncryptionSetID)
+	}
+	return s, nil
+}
+
+// resolveSnapshotEncryption returns the encryption settings of a snapshot of a volume with the
+// given options: the volume disk encryption set (or the provider default) is used so that
+// snapshots are protected like the disks, and AZURE_DISK_REQUIRE_CMK is enforced.
+func (d *driver) resolveSnapshotEncryption(ctx context.Context, options map[string]any) (*armcompute.Encryption, error) {
+	s, err := d.resolveDiskSettings(ctx, options, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.encryption(), nil
+}
@@ -0,0 +1,159 @@
+package aks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v7"
+	"github.com/kompox/kompox/domain/model"
+)
+
+const testDES = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/diskEncryptionSets/des1"
+
+func TestResolveDiskSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		driver     *driver
+		options    map[string]any
+		sizeGB     int32
+		wantErr    string
+		wantSKU    armcompute.DiskStorageAccountTypes
+		wantDES    string
+		wantSector int32
+	}{
+		{name: "default sku", driver: &driver{}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS},
+		{
+			name:       "premium v2 with performance and sector size",
+			driver:     &driver{},
+			options:    map[string]any{"sku": "PremiumV2_LRS", "iops": 6000, "mbps": "250", "logicalSectorSize": float64(4096)},
+			wantSKU:    armcompute.DiskStorageAccountTypesPremiumV2LRS,
+			wantSector: 4096,
+		},
+		{name: "unknown sku", driver: &driver{}, options: map[string]any{"sku": "Fast_LRS"}, wantErr: "unsupported sku"},
+		{name: "unknown option is ignored", driver: &driver{}, options: map[string]any{"fsType": "xfs"}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS},
+		{name: "iops requires provisioned sku", driver: &driver{}, options: map[string]any{"iops": 5000}, wantErr: "require sku UltraSSD_LRS or PremiumV2_LRS"},
+		{name: "iops out of range", driver: &driver{}, options: map[string]any{"sku": "PremiumV2_LRS", "iops": 100}, wantErr: "between 3000 and 80000"},
+		{name: "bursting requires premium", driver: &driver{}, options: map[string]any{"sku": "StandardSSD_LRS", "burstingEnabled": true}, wantErr: "requires sku Premium_LRS"},
+		{name: "bursting requires large disk", driver: &driver{}, options: map[string]any{"burstingEnabled": "true"}, sizeGB: 256, wantErr: "larger than 512 GiB"},
+		{name: "bursting on large premium disk", driver: &driver{}, options: map[string]any{"burstingEnabled": true}, sizeGB: 1024, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS},
+		{name: "sector size requires provisioned sku", driver: &driver{}, options: map[string]any{"logicalSectorSize": 4096}, wantErr: "logicalSectorSize requires"},
+		{name: "invalid sector size", driver: &driver{}, options: map[string]any{"sku": "UltraSSD_LRS", "logicalSectorSize": 1024}, wantErr: "512 or 4096"},
+		{name: "des from option", driver: &driver{}, options: map[string]any{"diskEncryptionSetId": testDES}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS, wantDES: testDES},
+		{name: "des wrong resource type", driver: &driver{}, options: map[string]any{"diskEncryptionSetId": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"}, wantErr: "diskEncryptionSets"},
+		{name: "customer key type requires des", driver: &driver{}, options: map[string]any{"encryptionType": "EncryptionAtRestWithCustomerKey"}, wantErr: "requires diskEncryptionSetId"},
+		{name: "des from provider default", driver: &driver{diskEncryptionSetID: testDES}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS, wantDES: testDES},
+		{name: "platform key opts out of provider default", driver: &driver{diskEncryptionSetID: testDES}, options: map[string]any{"encryptionType": "EncryptionAtRestWithPlatformKey"}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS},
+		{name: "cmk required without des", driver: &driver{diskRequireCMK: true}, wantErr: "customer-managed key is required"},
+		{name: "cmk required rejects platform key", driver: &driver{diskEncryptionSetID: testDES, diskRequireCMK: true}, options: map[string]any{"encryptionType": "EncryptionAtRestWithPlatformKey"}, wantErr: "customer-managed key is required"},
+		{name: "cmk required with des", driver: &driver{diskRequireCMK: true}, options: map[string]any{"diskEncryptionSetId": testDES}, wantSKU: armcompute.DiskStorageAccountTypesPremiumLRS, wantDES: testDES},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.driver.resolveDiskSettings(context.Background(), tt.options, tt.sizeGB)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q", tt.wantErr)
+				}
+				if !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+					t.Errorf("error %v does not wrap ErrVolumeOptionsInvalid", err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.SKU != tt.wantSKU {
+				t.Errorf("SKU = %s, want %s", s.SKU, tt.wantSKU)
+			}
+			if s.DiskEncryptionSetID != tt.wantDES {
+				t.Errorf("DiskEncryptionSetID = %q, want %q", s.DiskEncryptionSetID, tt.wantDES)
+			}
+			if tt.wantSector != 0 && (s.LogicalSectorSize == nil || *s.LogicalSectorSize != tt.wantSector) {
+				t.Errorf("LogicalSectorSize = %v, want %d", s.LogicalSectorSize, tt.wantSector)
+			}
+			if e := s.encryption(); (e != nil) != (tt.wantDES != "") {
+				t.Errorf("encryption() = %v, want DES %q", e, tt.wantDES)
+			}
+		})
+	}
+}
+
+func TestSetDiskOptions(t *testing.T) {
+	vb := &volumeBackendDisk{driver: &driver{}}
+	s, err := vb.driver.resolveDiskSettings(context.Background(), map[string]any{"sku": "UltraSSD_LRS", "iops": 2000, "mbps": 100, "logicalSectorSize": 512, "diskEncryptionSetId": testDES}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk := &armcompute.Disk{Properties: &armcompute.DiskProperties{CreationData: &armcompute.CreationData{CreateOption: new(armcompute.DiskCreateOption)}}}
+	*disk.Properties.CreationData.CreateOption = armcompute.DiskCreateOptionEmpty
+	vb.setDiskOptions(disk, s)
+
+	got := vb.diskOptions(disk)
+	want := map[string]any{
+		"sku":                 "UltraSSD_LRS",
+		"iops":                int64(2000),
+		"mbps":                int64(100),
+		"logicalSectorSize":   int32(512),
+		"diskEncryptionSetId": testDES,
+		"encryptionType":      "EncryptionAtRestWithCustomerKey",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("diskOptions()[%s] = %v (%T), want %v (%T)", k, got[k], got[k], v, v)
+		}
+	}
+}
+
+func TestParseDiskOptionsUnknown(t *testing.T) {
+	s, err := parseDiskOptions(map[string]any{"sku": "Premium_LRS", "uid": 1000, "node": "n1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(s.Unknown, ","); got != "node,uid" {
+		t.Errorf("Unknown = %q, want %q", got, "node,uid")
+	}
+}
+
+func TestResolveSnapshotEncryption(t *testing.T) {
+	const volumeDES = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/diskEncryptionSets/des2"
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		driver  *driver
+		options map[string]any
+		wantDES string
+		wantErr string
+	}{
+		{name: "platform key", driver: &driver{}},
+		{name: "provider default", driver: &driver{diskEncryptionSetID: testDES}, wantDES: testDES},
+		{name: "volume option overrides provider default", driver: &driver{diskEncryptionSetID: testDES}, options: map[string]any{"diskEncryptionSetId": volumeDES}, wantDES: volumeDES},
+		{name: "cmk required", driver: &driver{diskRequireCMK: true}, wantErr: "customer-managed key is required"},
+		{name: "cmk required with volume option", driver: &driver{diskRequireCMK: true}, options: map[string]any{"diskEncryptionSetId": volumeDES}, wantDES: volumeDES},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := tt.driver.resolveSnapshotEncryption(ctx, tt.options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := ""
+			if e != nil {
+				got = *e.DiskEncryptionSetID
+			}
+			if got != tt.wantDES {
+				t.Errorf("DES = %q, want %q", got, tt.wantDES)
+			}
+		})
+	}
+}
@@ -22,6 +22,10 @@ const (
 	tagFilesVolumeName    = "kompox_volume_name"
 	tagFilesShareName     = "kompox_files_share_name"
 	tagFilesShareAssigned = "kompox_files_share_assigned" // true/false
+	tagFilesSnapshotName  = "kompox_files_snapshot_name"  // snapshot name (CompactID)
+	tagFilesLabelPrefix   = "kompox_label_"               // user label prefix (kompox_label_<key>)
+	tagFilesDescription   = "kompox_description"          // user description
+	tagFilesSourceHandle  = "kompox_source_handle"        // lineage: handle of the source share/snapshot
 	maxShareNameLength    = 41                            // {vol.name}-{disk.name} with max 16+1+24=41
 	defaultFilesSKU       = "Standard_LRS"
 	defaultFilesProtocol  = "smb"
@@ -100,10 +104,6 @@ func (vb *volumeBackendFiles) DiskList(ctx context.Context, cluster *model.Clust
 func (vb *volumeBackendFiles) DiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
 	log := logging.FromContext(ctx)
 
-	if source != "" {
-		return nil, fmt.Errorf("source restoration is not supported for Type=files")
-	}
-
 	// Get volume from app configuration
 	vol, err := app.FindVolume(volName)
 	if err != nil {
@@ -158,8 +158,17 @@ func (vb *volumeBackendFiles) DiskCreate(ctx context.Context, cluster *model.Clu
 		return nil, fmt.Errorf("share name %q exceeds max length %d", shareName, maxShareNameLength)
 	}
 
+	var optionsStruct model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
 	// Get quota from volume size (in GiB)
-	quotaGiB := int32((vol.Size + (1 << 30) - 1) >> 30) // Round up to GiB
+	size := vol.Size
+	if optionsStruct.Size > size {
+		size = optionsStruct.Size
+	}
+	quotaGiB := int32((size + (1 << 30) - 1) >> 30) // Round up to GiB
 
 	// Override quota if specified in options
 	if v, ok := options["quotaGiB"]; ok {
@@ -199,12 +208,38 @@ func (vb *volumeBackendFiles) DiskCreate(ctx context.Context, cluster *model.Clu
 		return nil, fmt.Errorf("new file shares client: %w", err)
 	}
 
+	// Resolve source share (and snapshot) before creating the new share
+	var srcShareName, srcSnapshot string
+	source = strings.TrimSpace(source)
+	if source != "" {
+		srcShareName, srcSnapshot, err = vb.resolveSource(ctx, sharesClient, rg, accountName, volName, source)
+		if err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+		// The restored share must be able to hold the source content
+		srcResp, err := sharesClient.Get(ctx, rg, accountName, srcShareName, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get source share %s: %w", srcShareName, err)
+		}
+		if p := srcResp.FileShare.FileShareProperties; p != nil && p.ShareQuota != nil && *p.ShareQuota > quotaGiB {
+			quotaGiB = *p.ShareQuota
+		}
+	}
+
 	// Build metadata
 	metadata := map[string]*string{
 		tagFilesVolumeName:    to.Ptr(volName),
 		tagFilesShareName:     to.Ptr(diskName),
 		tagFilesShareAssigned: to.Ptr(assignedValue),
 	}
+	setFilesUserMetadata(metadata, optionsStruct.Labels, optionsStruct.Description)
+	if srcShareName != "" {
+		if srcSnapshot != "" {
+			metadata[tagFilesSourceHandle] = to.Ptr(filesSnapshotHandle(accountName, srcShareName, srcSnapshot))
+		} else {
+			metadata[tagFilesSourceHandle] = to.Ptr(vb.shareHandle(rg, accountName, srcShareName))
+		}
+	}
 
 	// Create share
 	shareProps := armstorage.FileShare{
@@ -221,6 +256,24 @@ func (vb *volumeBackendFiles) DiskCreate(ctx context.Context, cluster *model.Clu
 		return nil, fmt.Errorf("create share: %w", err)
 	}
 
+	// Restore content from the source share snapshot (or live share)
+	if srcShareName != "" {
+		log.Info(ctx, "Restoring Azure Files share", "share", shareName, "source_share", srcShareName, "source_snapshot", srcSnapshot)
+		filesClient, err := vb.driver.newAzureFilesClient(ctx, rg, accountName)
+		if err == nil {
+			var copied int
+			copied, err = filesClient.copySnapshot(ctx, srcShareName, srcSnapshot, shareName)
+			log.Info(ctx, "Restored Azure Files share", "share", shareName, "files", copied)
+		}
+		if err != nil {
+			// Do not leave a partially restored share behind
+			if _, derr := sharesClient.Delete(ctx, rg, accountName, shareName, nil); derr != nil {
+				log.Warn(ctx, "Failed to delete partially restored share", "share", shareName, "error", derr)
+			}
+			return nil, fmt.Errorf("restore share from %q: %w", source, err)
+		}
+	}
+
 	// Retrieve the created share to get full details
 	getResp, err := sharesClient.Get(ctx, rg, accountName, shareName, &armstorage.FileSharesClientGetOptions{
 		Expand: to.Ptr("metadata"),
@@ -268,19 +321,66 @@ func (vb *volumeBackendFiles) DiskDelete(ctx context.Context, cluster *model.Clu
 		return fmt.Errorf("new file shares client: %w", err)
 	}
 
+	var o model.VolumeDiskDeleteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// A share with snapshots cannot be deleted unless its snapshots are deleted too.
+	// Snapshots are kompox resources of their own, so they are only deleted with Force.
+	include := "none"
+	if o.Force {
+		include = "snapshots"
+	} else {
+		snaps, err := vb.shareSnapshotNames(ctx, sharesClient, rg, accountName, volName, shareName)
+		if err != nil {
+			return err
+		}
+		if len(snaps) > 0 {
+			return fmt.Errorf("share %s has snapshots (%s); delete them first", shareName, strings.Join(snaps, ", "))
+		}
+	}
+
 	// Delete share
-	_, err = sharesClient.Delete(ctx, rg, accountName, shareName, nil)
+	_, err = sharesClient.Delete(ctx, rg, accountName, shareName, &armstorage.FileSharesClientDeleteOptions{
+		Include: to.Ptr(include),
+	})
 	if err != nil {
 		var respErr *azcore.ResponseError
 		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
 			return nil // Already deleted
 		}
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusConflict {
+			// Snapshots created after the check above, or leased snapshots
+			return fmt.Errorf("delete share %s: share has snapshots or is leased (%s); delete the snapshots first", shareName, respErr.ErrorCode)
+		}
 		return fmt.Errorf("delete share: %w", err)
 	}
 
 	return nil
 }
 
+// shareSnapshotNames returns the names of the snapshots of shareName. Snapshots not
+// created by kompox are reported by their snapshot time.
+func (vb *volumeBackendFiles) shareSnapshotNames(ctx context.Context, sharesClient *armstorage.FileSharesClient, rg, accountName, volName, shareName string) ([]string, error) {
+	items, err := vb.listSnapshotItems(ctx, sharesClient, rg, accountName)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range items {
+		if item.Name == nil || *item.Name != shareName {
+			continue
+		}
+		if snap, err := vb.newSnapshot(item, volName, accountName); err == nil && snap != nil {
+			names = append(names, snap.Name)
+		} else {
+			names = append(names, filesSnapshotTime(*item.Properties.SnapshotTime))
+		}
+	}
+	return names, nil
+}
+
 // DiskAssign assigns an Azure Files share (Type="files").
 func (vb *volumeBackendFiles) DiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
 	rg, err := vb.driver.appResourceGroupName(app)
@@ -361,19 +461,203 @@ func (vb *volumeBackendFiles) DiskAssign(ctx context.Context, cluster *model.Clu
 	return nil
 }
 
-// SnapshotList returns ErrNotSupported (snapshots not supported for Azure Files).
+// DiskUpdate is not supported for Azure Files shares (Type="files").
+func (vb *volumeBackendFiles) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, fmt.Errorf("disk update for files volumes: %w", model.ErrNotSupported)
+}
+
+// SnapshotList lists Azure Files share snapshots for a volume (Type="files").
 func (vb *volumeBackendFiles) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
-	return nil, model.ErrNotSupported
+	rg, err := vb.driver.appResourceGroupName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app RG: %w", err)
+	}
+
+	accountName, err := vb.driver.appStorageAccountName(app)
+	if err != nil {
+		return nil, fmt.Errorf("storage account name: %w", err)
+	}
+
+	sharesClient, err := armstorage.NewFileSharesClient(vb.driver.AzureSubscriptionId, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new file shares client: %w", err)
+	}
+
+	items, err := vb.listSnapshotItems(ctx, sharesClient, rg, accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*model.VolumeSnapshot{}
+	for _, item := range items {
+		snap, err := vb.newSnapshot(item, volName, accountName)
+		if err != nil || snap == nil {
+			continue
+		}
+		out = append(out, snap)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
 }
 
-// SnapshotCreate returns ErrNotSupported (snapshots not supported for Azure Files).
+// SnapshotCreate creates an Azure Files share snapshot (Type="files").
+// Source follows the opaque contract: empty uses the assigned share, "disk:<name>" or
+// "<name>" selects a Kompox managed share of the same volume.
 func (vb *volumeBackendFiles) SnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
-	return nil, model.ErrNotSupported
+	log := logging.FromContext(ctx)
+
+	rg, err := vb.driver.appResourceGroupName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app RG: %w", err)
+	}
+
+	accountName, err := vb.driver.appStorageAccountName(app)
+	if err != nil {
+		return nil, fmt.Errorf("storage account name: %w", err)
+	}
+
+	// List existing snapshots to determine name if needed
+	items, err := vb.SnapshotList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		snapName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == snapName {
+				return nil, fmt.Errorf("snapshot %q already exists", snapName)
+			}
+		}
+	}
+
+	// Determine source share
+	shares, err := vb.DiskList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list shares: %w", err)
+	}
+	diskName := ""
+	source = strings.TrimSpace(source)
+	if source == "" {
+		for _, share := range shares {
+			if share.Assigned {
+				diskName = share.Name
+				break
+			}
+		}
+		if diskName == "" {
+			return nil, fmt.Errorf("no assigned share found for volume %q", volName)
+		}
+	} else {
+		diskName = source
+		if strings.HasPrefix(strings.ToLower(source), "disk:") {
+			diskName = source[5:]
+		}
+		found := false
+		for _, share := range shares {
+			if share.Name == diskName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("share not found: %s", diskName)
+		}
+	}
+	shareName := fmt.Sprintf("%s-%s", volName, diskName)
+
+	sharesClient, err := armstorage.NewFileSharesClient(vb.driver.AzureSubscriptionId, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new file shares client: %w", err)
+	}
+
+	var optionsStruct model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
+	// Creating a share with $expand=snapshots takes a snapshot of the existing share.
+	// The metadata in the request body is applied to the snapshot.
+	metadata := map[string]*string{
+		tagFilesVolumeName:   to.Ptr(volName),
+		tagFilesShareName:    to.Ptr(diskName),
+		tagFilesSnapshotName: to.Ptr(snapName),
+		tagFilesSourceHandle: to.Ptr(vb.shareHandle(rg, accountName, shareName)),
+	}
+	setFilesUserMetadata(metadata, optionsStruct.Labels, optionsStruct.Description)
+	snapshotProps := armstorage.FileShare{
+		FileShareProperties: &armstorage.FileShareProperties{
+			Metadata: metadata,
+		},
+	}
+
+	log.Info(ctx, "Creating Azure Files share snapshot", "share", shareName, "snapshot", snapName)
+
+	_, err = sharesClient.Create(ctx, rg, accountName, shareName, snapshotProps, &armstorage.FileSharesClientCreateOptions{
+		Expand: to.Ptr("snapshots"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create share snapshot: %w", err)
+	}
+
+	item, err := vb.findSnapshotItem(ctx, sharesClient, rg, accountName, volName, snapName)
+	if err != nil {
+		return nil, fmt.Errorf("get share snapshot after create: %w", err)
+	}
+	if item == nil {
+		return nil, fmt.Errorf("share snapshot %q not found after create", snapName)
+	}
+
+	volumeSnapshot, err := vb.newSnapshot(item, volName, accountName)
+	if err != nil {
+		return nil, fmt.Errorf("create VolumeSnapshot from share snapshot: %w", err)
+	}
+	return volumeSnapshot, nil
 }
 
-// SnapshotDelete returns ErrNotSupported (snapshots not supported for Azure Files).
+// SnapshotDelete deletes an Azure Files share snapshot (Type="files").
 func (vb *volumeBackendFiles) SnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
-	return model.ErrNotSupported
+	rg, err := vb.driver.appResourceGroupName(app)
+	if err != nil {
+		return fmt.Errorf("app RG: %w", err)
+	}
+
+	accountName, err := vb.driver.appStorageAccountName(app)
+	if err != nil {
+		return fmt.Errorf("storage account name: %w", err)
+	}
+
+	sharesClient, err := armstorage.NewFileSharesClient(vb.driver.AzureSubscriptionId, vb.driver.TokenCredential, nil)
+	if err != nil {
+		return fmt.Errorf("new file shares client: %w", err)
+	}
+
+	item, err := vb.findSnapshotItem(ctx, sharesClient, rg, accountName, volName, snapName)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return nil // Already deleted
+	}
+
+	_, err = sharesClient.Delete(ctx, rg, accountName, *item.Name, &armstorage.FileSharesClientDeleteOptions{
+		XMSSnapshot: to.Ptr(filesSnapshotTime(*item.Properties.SnapshotTime)),
+	})
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil // Already deleted
+		}
+		return fmt.Errorf("delete share snapshot: %w", err)
+	}
+
+	return nil
 }
 
 // Class returns Azure Files provisioning parameters (Type="files").
@@ -402,6 +686,9 @@ func (vb *volumeBackendFiles) newDisk(share *armstorage.FileShareItem, volName,
 	if share == nil || share.Name == nil || share.Properties == nil {
 		return nil, fmt.Errorf("share is nil or missing required fields")
 	}
+	if share.Properties.SnapshotTime != nil {
+		return nil, nil // Share snapshots are listed by SnapshotList
+	}
 
 	metadata := share.Properties.Metadata
 	if metadata == nil {
@@ -447,11 +734,7 @@ func (vb *volumeBackendFiles) newDisk(share *armstorage.FileShareItem, volName,
 		created = *share.Properties.LastModifiedTime
 	}
 
-	// Generate Handle as Azure Resource ID for CSI driver
-	// Format: {resource-group-name}#{account-name}#{file-share-name}#{placeholder}#{uuid}#{secret-namespace}#{subscription-id}
-	// placeholder, uuid, secret-namespace, subscription-id are optional
-	// https://github.com/kubernetes-sigs/azurefile-csi-driver/blob/master/docs/driver-parameters.md
-	handle := fmt.Sprintf("%s#%s#%s####%s", rg, accountName, *share.Name, vb.driver.AzureSubscriptionId)
+	handle := vb.shareHandle(rg, accountName, *share.Name)
 
 	// Build options from share properties
 	options := map[string]any{
@@ -461,15 +744,198 @@ func (vb *volumeBackendFiles) newDisk(share *armstorage.FileShareItem, volName,
 		options["quotaGiB"] = *share.Properties.ShareQuota
 	}
 
+	labels, description, sourceHandle := filesUserMetadata(metadata)
+
 	return &model.VolumeDisk{
-		Name:       diskName,
-		VolumeName: volName,
-		Assigned:   assigned,
-		Size:       size,
-		Zone:       "", // Azure Files is regional
-		Options:    options,
-		Handle:     handle,
-		CreatedAt:  created,
-		UpdatedAt:  created,
+		Name:         diskName,
+		VolumeName:   volName,
+		Assigned:     assigned,
+		Size:         size,
+		Zone:         "", // Azure Files is regional
+		Options:      options,
+		Handle:       handle,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}, nil
+}
+
+// shareHandle returns the Azure Files CSI volume handle of a share.
+// Format: {resource-group-name}#{account-name}#{file-share-name}#{placeholder}#{uuid}#{secret-namespace}#{subscription-id}
+// placeholder, uuid, secret-namespace, subscription-id are optional
+// https://github.com/kubernetes-sigs/azurefile-csi-driver/blob/master/docs/driver-parameters.md
+func (vb *volumeBackendFiles) shareHandle(rg, accountName, shareName string) string {
+	return fmt.Sprintf("%s#%s#%s####%s", rg, accountName, shareName, vb.driver.AzureSubscriptionId)
+}
+
+// filesSnapshotHandle returns the data plane URL of a share snapshot used as its handle.
+func filesSnapshotHandle(accountName, shareName, snapshot string) string {
+	return fmt.Sprintf("https://%s.%s/%s?sharesnapshot=%s", accountName, filesStorageEndpointBase, shareName, snapshot)
+}
+
+// setFilesUserMetadata stores user labels and description as share metadata.
+func setFilesUserMetadata(metadata map[string]*string, labels map[string]string, description string) {
+	for k, v := range labels {
+		metadata[tagFilesLabelPrefix+k] = to.Ptr(v)
+	}
+	if description != "" {
+		metadata[tagFilesDescription] = to.Ptr(description)
+	}
+}
+
+// filesUserMetadata extracts user labels, description and source handle from share metadata.
+// Returns nil labels when none are set.
+func filesUserMetadata(metadata map[string]*string) (map[string]string, string, string) {
+	var labels map[string]string
+	description, sourceHandle := "", ""
+	for k, v := range metadata {
+		if v == nil {
+			continue
+		}
+		switch k {
+		case tagFilesDescription:
+			description = *v
+		case tagFilesSourceHandle:
+			sourceHandle = *v
+		default:
+			if key, ok := strings.CutPrefix(k, tagFilesLabelPrefix); ok && key != "" {
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels[key] = *v
+			}
+		}
+	}
+	return labels, description, sourceHandle
+}
+
+// newSnapshot creates a model.VolumeSnapshot from an Azure Files share snapshot item.
+// Returns nil without error when the snapshot belongs to a different volume.
+func (vb *volumeBackendFiles) newSnapshot(item *armstorage.FileShareItem, volName, accountName string) (*model.VolumeSnapshot, error) {
+	if item == nil || item.Name == nil || item.Properties == nil || item.Properties.SnapshotTime == nil {
+		return nil, fmt.Errorf("share snapshot is nil or missing required fields")
+	}
+
+	metadata := item.Properties.Metadata
+	if metadata == nil {
+		return nil, fmt.Errorf("share snapshot missing metadata")
+	}
+
+	snapName := ""
+	if v, ok := metadata[tagFilesSnapshotName]; !ok || v == nil || *v == "" {
+		return nil, fmt.Errorf("share snapshot missing required metadata: %s", tagFilesSnapshotName)
+	} else {
+		snapName = *v
+	}
+
+	if v, ok := metadata[tagFilesVolumeName]; !ok || v == nil || *v != volName {
+		return nil, nil // Skip this snapshot, it belongs to a different volume
+	}
+
+	var size int64
+	if item.Properties.ShareQuota != nil {
+		size = int64(*item.Properties.ShareQuota) << 30 // GiB to bytes
+	}
+
+	created := *item.Properties.SnapshotTime
+
+	// Handle is the data plane URL of the share snapshot
+	handle := filesSnapshotHandle(accountName, *item.Name, filesSnapshotTime(created))
+
+	labels, description, sourceHandle := filesUserMetadata(metadata)
+
+	return &model.VolumeSnapshot{
+		Name:         snapName,
+		VolumeName:   volName,
+		Size:         size,
+		Handle:       handle,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
 	}, nil
 }
+
+// listSnapshotItems lists all share snapshots in the storage account.
+// A missing storage account yields an empty list.
+func (vb *volumeBackendFiles) listSnapshotItems(ctx context.Context, sharesClient *armstorage.FileSharesClient, rg, accountName string) ([]*armstorage.FileShareItem, error) {
+	pager := sharesClient.NewListPager(rg, accountName, &armstorage.FileSharesClientListOptions{
+		Expand: to.Ptr("snapshots,metadata"),
+	})
+
+	var out []*armstorage.FileShareItem
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+				// Storage account doesn't exist yet
+				return nil, nil
+			}
+			return nil, fmt.Errorf("list share snapshots page: %w", err)
+		}
+		for _, item := range page.Value {
+			if item == nil || item.Properties == nil || item.Properties.SnapshotTime == nil {
+				continue // Not a snapshot
+			}
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// findSnapshotItem returns the share snapshot item named snapName in the volume, or nil if not found.
+func (vb *volumeBackendFiles) findSnapshotItem(ctx context.Context, sharesClient *armstorage.FileSharesClient, rg, accountName, volName, snapName string) (*armstorage.FileShareItem, error) {
+	items, err := vb.listSnapshotItems(ctx, sharesClient, rg, accountName)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		snap, err := vb.newSnapshot(item, volName, accountName)
+		if err != nil || snap == nil {
+			continue
+		}
+		if snap.Name == snapName {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveSource resolves a DiskCreate source to a share name and snapshot time in the same volume.
+// - "snapshot:name" or "name" -> Kompox managed share snapshot
+// - "disk:name" -> Kompox managed share (live content, empty snapshot time)
+func (vb *volumeBackendFiles) resolveSource(ctx context.Context, sharesClient *armstorage.FileSharesClient, rg, accountName, volName, source string) (shareName string, snapshot string, err error) {
+	lowerSource := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lowerSource, "https://"):
+		// Handles of other apps live in different storage accounts
+		return "", "", fmt.Errorf("share snapshots of other storage accounts are not supported for Type=files")
+	case strings.HasPrefix(lowerSource, "disk:"):
+		diskName := source[5:]
+		if diskName == "" {
+			return "", "", fmt.Errorf("disk name cannot be empty")
+		}
+		shareName = fmt.Sprintf("%s-%s", volName, diskName)
+		if _, err := sharesClient.Get(ctx, rg, accountName, shareName, nil); err != nil {
+			return "", "", fmt.Errorf("get share %q: %w", diskName, err)
+		}
+		return shareName, "", nil
+	case strings.HasPrefix(lowerSource, "snapshot:"):
+		source = source[9:]
+	}
+	if source == "" {
+		return "", "", fmt.Errorf("snapshot name cannot be empty")
+	}
+	item, err := vb.findSnapshotItem(ctx, sharesClient, rg, accountName, volName, source)
+	if err != nil {
+		return "", "", err
+	}
+	if item == nil {
+		return "", "", fmt.Errorf("snapshot not found: %s", source)
+	}
+	return *item.Name, filesSnapshotTime(*item.Properties.SnapshotTime), nil
+}
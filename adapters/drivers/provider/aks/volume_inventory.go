@@ -0,0 +1,254 @@
+package aks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v7"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Volume resource kinds reported by VolumeResourceList.
+const (
+	volumeResourceKindDisk           = "disk"
+	volumeResourceKindSnapshot       = "snapshot"
+	volumeResourceKindStorageAccount = "storageAccount"
+	volumeResourceKindResourceGroup  = "resourceGroup"
+)
+
+// volumeResourceKinds maps Azure resource types in app resource groups to volume resource kinds.
+var volumeResourceKinds = map[string]string{
+	"microsoft.compute/disks":           volumeResourceKindDisk,
+	"microsoft.compute/snapshots":       volumeResourceKindSnapshot,
+	"microsoft.storage/storageaccounts": volumeResourceKindStorageAccount,
+}
+
+// ownsAppResource reports whether tags mark an app-scoped resource created by this
+// driver instance (same workspace and provider) via appResourceTags.
+func (d *driver) ownsAppResource(tags map[string]*string) bool {
+	get := func(k string) string {
+		if v := tags[k]; v != nil {
+			return *v
+		}
+		return ""
+	}
+	return get("managed-by") == "kompox" &&
+		get(tagWorkspaceName) == d.WorkspaceName() &&
+		get(tagProviderName) == d.ProviderName() &&
+		get(tagAppIDHash) != ""
+}
+
+// newVolumeResource converts an Azure resource into model.VolumeResource using its tags.
+func newVolumeResource(kind, id, name string, tags map[string]*string, created *time.Time) *model.VolumeResource {
+	get := func(k string) string {
+		if v := tags[k]; v != nil {
+			return *v
+		}
+		return ""
+	}
+	res := &model.VolumeResource{
+		Kind:       kind,
+		Name:       name,
+		Handle:     id,
+		AppName:    get(tagAppName),
+		AppIDHash:  get(tagAppIDHash),
+		VolumeName: get(tagVolumeName),
+		Container:  kind == volumeResourceKindResourceGroup,
+	}
+	if created != nil {
+		res.CreatedAt = *created
+	}
+	if v := get(tagOrphanedAt); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			res.OrphanedAt = &t
+		}
+	}
+	return res
+}
+
+// VolumeResourceList lists app resource groups tagged by this driver instance and the
+// disks, snapshots and storage accounts within them. A resource group that also contains
+// resources not created by Kompox is not reported itself, so that it is never deleted as a whole.
+func (d *driver) VolumeResourceList(ctx context.Context) (out []*model.VolumeResource, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeResourceList")
+	defer func() { cleanup(err) }()
+	log := logging.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	groupsClient, err := armresources.NewResourceGroupsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new resource groups client: %w", err)
+	}
+	resClient, err := armresources.NewClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new resources client: %w", err)
+	}
+
+	groupPager := groupsClient.NewListPager(nil)
+	for groupPager.More() {
+		page, err := groupPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list resource groups: %w", err)
+		}
+		for _, g := range page.Value {
+			if g == nil || g.Name == nil || g.ID == nil || !d.ownsAppResource(g.Tags) {
+				continue
+			}
+			foreign := 0
+			resPager := resClient.NewListByResourceGroupPager(*g.Name, &armresources.ClientListByResourceGroupOptions{Expand: to.Ptr("createdTime")})
+			for resPager.More() {
+				resPage, err := resPager.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("list resources in %s: %w", *g.Name, err)
+				}
+				for _, r := range resPage.Value {
+					if r == nil || r.ID == nil || r.Name == nil || r.Type == nil {
+						continue
+					}
+					kind, ok := volumeResourceKinds[strings.ToLower(*r.Type)]
+					if !ok || !d.ownsAppResource(r.Tags) {
+						foreign++
+						continue
+					}
+					out = append(out, newVolumeResource(kind, *r.ID, *r.Name, r.Tags, r.CreatedTime))
+				}
+			}
+			if foreign > 0 {
+				log.Info(ctx, "resource group contains resources not managed by kompox; not reported", "resource_group", *g.Name, "count", foreign)
+				continue
+			}
+			out = append(out, newVolumeResource(volumeResourceKindResourceGroup, *g.ID, *g.Name, g.Tags, nil))
+		}
+	}
+	return out, nil
+}
+
+// VolumeResourceMarkOrphaned merges the kompox-orphaned-at tag into the resource tags.
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeResourceMarkOrphaned")
+	defer func() { cleanup(err) }()
+
+	if res == nil || res.Handle == "" {
+		return fmt.Errorf("resource handle required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	tagsClient, err := armresources.NewTagsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	if err != nil {
+		return fmt.Errorf("new tags client: %w", err)
+	}
+	patch := armresources.TagsPatchResource{
+		Operation: to.Ptr(armresources.TagsPatchOperationMerge),
+		Properties: &armresources.Tags{Tags: map[string]*string{
+			tagOrphanedAt: to.Ptr(at.UTC().Format(time.RFC3339)),
+		}},
+	}
+	if _, err := tagsClient.UpdateAtScope(ctx, res.Handle, patch, nil); err != nil {
+		return fmt.Errorf("update tags of %s: %w", res.Handle, err)
+	}
+	return nil
+}
+
+// VolumeResourceUnmarkOrphaned removes the kompox-orphaned-at tag from the resource tags.
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeResourceUnmarkOrphaned")
+	defer func() { cleanup(err) }()
+
+	if res == nil || res.Handle == "" {
+		return fmt.Errorf("resource handle required")
+	}
+	if res.OrphanedAt == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	tagsClient, err := armresources.NewTagsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+	if err != nil {
+		return fmt.Errorf("new tags client: %w", err)
+	}
+	// The Delete operation removes tags matching both name and value.
+	patch := armresources.TagsPatchResource{
+		Operation: to.Ptr(armresources.TagsPatchOperationDelete),
+		Properties: &armresources.Tags{Tags: map[string]*string{
+			tagOrphanedAt: to.Ptr(res.OrphanedAt.UTC().Format(time.RFC3339)),
+		}},
+	}
+	if _, err := tagsClient.UpdateAtScope(ctx, res.Handle, patch, nil); err != nil {
+		return fmt.Errorf("update tags of %s: %w", res.Handle, err)
+	}
+	return nil
+}
+
+// VolumeResourceDelete deletes a resource returned by VolumeResourceList. NotFound is treated as success.
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeResourceDelete")
+	defer func() { cleanup(err) }()
+
+	if res == nil || res.Handle == "" {
+		return fmt.Errorf("resource handle required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	if res.Kind == volumeResourceKindResourceGroup {
+		return d.ensureAzureResourceGroupDeleted(ctx, res.Name)
+	}
+
+	rid, err := arm.ParseResourceID(res.Handle)
+	if err != nil {
+		return fmt.Errorf("parse resource ID %s: %w", res.Handle, err)
+	}
+
+	switch res.Kind {
+	case volumeResourceKindDisk:
+		client, err := armcompute.NewDisksClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+		if err != nil {
+			return fmt.Errorf("new disks client: %w", err)
+		}
+		poller, err := client.BeginDelete(ctx, rid.ResourceGroupName, rid.Name, nil)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil
+			}
+			return fmt.Errorf("delete disk: %w", err)
+		}
+		_, err = poller.PollUntilDone(ctx, nil)
+		return err
+	case volumeResourceKindSnapshot:
+		client, err := armcompute.NewSnapshotsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+		if err != nil {
+			return fmt.Errorf("new snapshots client: %w", err)
+		}
+		poller, err := client.BeginDelete(ctx, rid.ResourceGroupName, rid.Name, nil)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil
+			}
+			return fmt.Errorf("delete snapshot: %w", err)
+		}
+		_, err = poller.PollUntilDone(ctx, nil)
+		return err
+	case volumeResourceKindStorageAccount:
+		client, err := armstorage.NewAccountsClient(d.AzureSubscriptionId, d.TokenCredential, nil)
+		if err != nil {
+			return fmt.Errorf("new storage accounts client: %w", err)
+		}
+		if _, err := client.Delete(ctx, rid.ResourceGroupName, rid.Name, nil); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("delete storage account: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported volume resource kind: %s", res.Kind)
+	}
+}
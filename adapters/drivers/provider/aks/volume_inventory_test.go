@@ -0,0 +1,47 @@
+package aks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+func TestOwnsAppResource(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv"}
+	tags := d.appResourceTags("app1")
+	if !d.ownsAppResource(tags) {
+		t.Fatalf("ownsAppResource() = false for appResourceTags")
+	}
+	other := &driver{workspaceName: "ws", providerName: "other"}
+	if other.ownsAppResource(tags) {
+		t.Errorf("ownsAppResource() = true for a different provider")
+	}
+	if d.ownsAppResource(d.clusterResourceTags("cls1")) {
+		t.Errorf("ownsAppResource() = true for cluster-scoped tags")
+	}
+}
+
+func TestNewVolumeResource(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv"}
+	tags := d.appResourceTags("app1")
+	tags[tagVolumeName] = to.Ptr("db")
+	tags[tagOrphanedAt] = to.Ptr("2026-02-03T04:05:06Z")
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	res := newVolumeResource(volumeResourceKindDisk, "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/d1", "d1", tags, &created)
+	if res.AppName != "app1" || res.AppIDHash == "" || res.VolumeName != "db" || res.Container {
+		t.Errorf("unexpected resource: %+v", res)
+	}
+	if !res.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt = %v, want %v", res.CreatedAt, created)
+	}
+	if res.OrphanedAt == nil || !res.OrphanedAt.Equal(time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)) {
+		t.Errorf("OrphanedAt = %v", res.OrphanedAt)
+	}
+
+	rg := newVolumeResource(volumeResourceKindResourceGroup, "/subscriptions/s/resourceGroups/rg", "rg", d.appResourceTags("app1"), nil)
+	if !rg.Container || rg.OrphanedAt != nil || !rg.CreatedAt.IsZero() {
+		t.Errorf("unexpected resource group: %+v", rg)
+	}
+}
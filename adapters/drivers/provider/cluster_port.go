@@ -38,6 +38,74 @@ func (a *clusterPortAdapter) getDriver(ctx context.Context, cluster *model.Clust
 	return drv, nil
 }
 
+// Capabilities returns the capabilities of the driver managing the cluster.
+// Cluster.Plan and Cluster.Upgrade are derived from whether the driver implements
+// ClusterPlanner and ClusterUpgrader.
+func (a *clusterPortAdapter) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	caps := drv.Capabilities()
+	_, caps.Cluster.Plan = drv.(ClusterPlanner)
+	_, caps.Cluster.Upgrade = drv.(ClusterUpgrader)
+	return &caps, nil
+}
+
+// Plan returns the changes the lifecycle operation would make, computed by the driver
+// without mutating anything. Drivers not implementing ClusterPlanner are not supported.
+func (a *clusterPortAdapter) Plan(ctx context.Context, cluster *model.Cluster, op model.ClusterPlanOperation, opts ...model.ClusterPlanOption) (*model.ClusterPlan, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	planner, ok := drv.(ClusterPlanner)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not plan cluster operations: %w", drv.ID(), model.ErrNotSupported)
+	}
+	return planner.ClusterPlan(ctx, cluster, op, opts...)
+}
+
+// upgrader returns the driver of the cluster as a ClusterUpgrader.
+func (a *clusterPortAdapter) upgrader(ctx context.Context, cluster *model.Cluster) (ClusterUpgrader, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	upgrader, ok := drv.(ClusterUpgrader)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not upgrade clusters: %w", drv.ID(), model.ErrNotSupported)
+	}
+	return upgrader, nil
+}
+
+// Versions returns the current Kubernetes and node image versions and the available upgrades.
+func (a *clusterPortAdapter) Versions(ctx context.Context, cluster *model.Cluster) (*model.ClusterVersions, error) {
+	upgrader, err := a.upgrader(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return upgrader.ClusterVersions(ctx, cluster)
+}
+
+// UpgradeControlPlane upgrades the control plane of the cluster to version.
+func (a *clusterPortAdapter) UpgradeControlPlane(ctx context.Context, cluster *model.Cluster, version string) error {
+	upgrader, err := a.upgrader(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return upgrader.ClusterUpgrade(ctx, cluster, version)
+}
+
+// UpgradeNodePool upgrades the Kubernetes version and/or node image of one node pool.
+func (a *clusterPortAdapter) UpgradeNodePool(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolUpgradeOption) error {
+	upgrader, err := a.upgrader(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return upgrader.NodePoolUpgrade(ctx, cluster, poolName, opts...)
+}
+
 // Status returns the current status of the specified cluster by delegating
 // to the underlying provider driver implementation. It returns a *model.ClusterStatus
 // describing existence, provisioning and installation state.
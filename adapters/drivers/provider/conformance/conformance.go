@@ -0,0 +1,485 @@
+// Package conformance provides a reusable test suite that checks provider drivers against
+// the providerdrv.Driver contract: idempotent deletes, opaque disk/snapshot sources,
+// assigned-disk uniqueness, node pool immutability, best-effort DNS and model.ErrNotSupported
+// for unsupported operations.
+//
+// Drivers run the suite from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		report := conformance.Run(t, conformance.Fixture{...})
+//		report.Require(t, conformance.CapVolumeDisk, conformance.CapVolumeSnapshot)
+//	}
+//
+// Operations returning model.ErrNotSupported mark the capability as not supported and skip
+// the dependent checks instead of failing, so the same suite applies to every driver.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// Capability names a group of driver operations checked by the suite.
+type Capability string
+
+const (
+	CapClusterProvision Capability = "cluster.provision" // ClusterProvision / ClusterStatus
+	CapVolumeDisk       Capability = "volume.disk"       // VolumeDiskCreate / List / Assign / Delete
+	CapVolumeSnapshot   Capability = "volume.snapshot"   // VolumeSnapshotCreate / List / Delete and restore
+	CapVolumeInventory  Capability = "volume.inventory"  // VolumeResourceList
+	CapNodePoolList     Capability = "nodepool.list"     // NodePoolList
+	CapNodePoolMutate   Capability = "nodepool.mutate"   // NodePoolCreate / Update / Delete
+	CapDNSApply         Capability = "dns.apply"         // ClusterDNSApply
+)
+
+// Status is the outcome of the checks of a capability.
+type Status string
+
+const (
+	StatusSupported    Status = "supported"     // all checks passed
+	StatusNotSupported Status = "not-supported" // the driver returned model.ErrNotSupported
+	StatusFailed       Status = "failed"        // a contract check failed
+	StatusSkipped      Status = "skipped"       // not checked (missing fixture or prerequisite)
+)
+
+// Fixture describes the driver under test and the resources the checks operate on.
+type Fixture struct {
+	// Factory creates the driver. Defaults to the factory registered for Provider.Driver.
+	Factory func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error)
+	// Workspace and Provider are passed to Factory. Provider is required.
+	Workspace *model.Workspace
+	Provider  *model.Provider
+	// Cluster is the target cluster. Required.
+	Cluster *model.Cluster
+	// App and VolumeName select a Type="disk" volume used by volume checks.
+	// Volume checks are skipped when App is nil.
+	App        *model.App
+	VolumeName string
+	// NodePool is the pool created by node pool checks; its Name is replaced by a unique name.
+	// ImmutableChange holds fields that must be rejected by NodePoolUpdate (e.g., InstanceType).
+	// Node pool mutation checks are skipped when NodePool is nil.
+	NodePool        *model.NodePool
+	ImmutableChange model.NodePool
+	// DNSRecord is applied by DNS checks. DNS checks are skipped when FQDN is empty.
+	DNSRecord model.DNSRecordSet
+	// LookupDNS observes the record set stored by the provider so that dry-run purity and
+	// deletion can be verified. When nil, only error handling is checked.
+	LookupDNS func(ctx context.Context, fqdn string, rtype model.DNSRecordType) (rdata []string, found bool, err error)
+	// Timeout bounds each check (default 5 minutes).
+	Timeout time.Duration
+}
+
+// Report records the status of each capability.
+type Report struct {
+	mu       sync.Mutex
+	statuses map[Capability]Status
+}
+
+// Status returns the recorded status of the capability.
+func (r *Report) Status(c Capability) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.statuses[c]; ok {
+		return s
+	}
+	return StatusSkipped
+}
+
+// Supported reports whether all checks of the capability passed.
+func (r *Report) Supported(c Capability) bool { return r.Status(c) == StatusSupported }
+
+// Require fails the test unless every given capability is supported.
+func (r *Report) Require(t testing.TB, caps ...Capability) {
+	t.Helper()
+	for _, c := range caps {
+		if s := r.Status(c); s != StatusSupported {
+			t.Errorf("capability %s: %s", c, s)
+		}
+	}
+}
+
+// String formats the report as one "capability: status" line per capability.
+func (r *Report) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	caps := make([]string, 0, len(r.statuses))
+	for c := range r.statuses {
+		caps = append(caps, string(c))
+	}
+	sort.Strings(caps)
+	var b strings.Builder
+	for _, c := range caps {
+		fmt.Fprintf(&b, "%s: %s\n", c, r.statuses[Capability(c)])
+	}
+	return b.String()
+}
+
+// set records the status of the capability. Failures are sticky and not-supported
+// takes precedence over supported.
+func (r *Report) set(c Capability, s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch cur := r.statuses[c]; {
+	case cur == StatusFailed:
+	case cur == StatusNotSupported && s == StatusSupported:
+	default:
+		r.statuses[c] = s
+	}
+}
+
+// suite holds the state shared by the checks of a run.
+type suite struct {
+	fx     Fixture
+	drv    providerdrv.Driver
+	report *Report
+}
+
+// Run executes the conformance checks as subtests of t and returns the capability report.
+// The report is also logged.
+func Run(t *testing.T, fx Fixture) *Report {
+	t.Helper()
+	if fx.Provider == nil || fx.Cluster == nil {
+		t.Fatal("conformance: Fixture.Provider and Fixture.Cluster are required")
+	}
+	if fx.Timeout == 0 {
+		fx.Timeout = 5 * time.Minute
+	}
+	factory := fx.Factory
+	if factory == nil {
+		f, ok := providerdrv.GetDriverFactory(fx.Provider.Driver)
+		if !ok {
+			t.Fatalf("conformance: driver %q not registered", fx.Provider.Driver)
+		}
+		factory = f
+	}
+	drv, err := factory(fx.Workspace, fx.Provider)
+	if err != nil {
+		t.Fatalf("conformance: create driver: %v", err)
+	}
+
+	s := &suite{fx: fx, drv: drv, report: &Report{statuses: map[Capability]Status{}}}
+	checks := []struct {
+		name string
+		cap  Capability
+		fn   func(t *testing.T, ctx context.Context)
+	}{
+		{"ClusterProvisionIdempotent", CapClusterProvision, s.checkClusterProvision},
+		{"DiskDeleteNotFound", CapVolumeDisk, s.checkDiskDeleteNotFound},
+		{"DiskAssignUnique", CapVolumeDisk, s.checkDiskAssignUnique},
+		{"SnapshotDeleteNotFound", CapVolumeSnapshot, s.checkSnapshotDeleteNotFound},
+		{"SnapshotRestoreRoundtrip", CapVolumeSnapshot, s.checkSnapshotRestore},
+		{"VolumeResourceList", CapVolumeInventory, s.checkVolumeResourceList},
+		{"NodePoolList", CapNodePoolList, s.checkNodePoolList},
+		{"NodePoolImmutability", CapNodePoolMutate, s.checkNodePoolImmutability},
+		{"NodePoolDeleteNotFound", CapNodePoolMutate, s.checkNodePoolDeleteNotFound},
+		{"DNSBestEffort", CapDNSApply, s.checkDNSBestEffort},
+		{"DNSDryRunPurity", CapDNSApply, s.checkDNSDryRun},
+	}
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), fx.Timeout)
+			defer cancel()
+			// Deferred because t.Fatal and t.Skip exit the goroutine
+			defer func() {
+				switch {
+				case t.Failed():
+					s.report.set(c.cap, StatusFailed)
+				case !t.Skipped():
+					s.report.set(c.cap, StatusSupported)
+				}
+			}()
+			c.fn(t, ctx)
+		})
+	}
+	t.Run("CapabilitiesDeclared", s.checkCapabilitiesDeclared)
+	t.Logf("conformance report for driver %s:\n%s", drv.ID(), s.report)
+	return s.report
+}
+
+// checkCapabilitiesDeclared compares Driver.Capabilities with the observed report: a capability
+// declared but answered with model.ErrNotSupported, or supported but not declared, is a failure.
+func (s *suite) checkCapabilitiesDeclared(t *testing.T) {
+	caps := s.drv.Capabilities()
+	declared := map[Capability]bool{
+		CapVolumeInventory: caps.VolumeInventory,
+		CapNodePoolList:    caps.NodePool.List,
+		CapNodePoolMutate:  caps.NodePool.Create && caps.NodePool.Update && caps.NodePool.Delete,
+	}
+	if s.fx.App != nil {
+		if vol, err := s.fx.App.FindVolume(s.fx.VolumeName); err == nil {
+			declared[CapVolumeDisk] = caps.CheckVolume(*vol) == nil
+			declared[CapVolumeSnapshot] = caps.CheckVolumeSnapshot(*vol) == nil
+		}
+	}
+	for c, want := range declared {
+		switch s.report.Status(c) {
+		case StatusSupported:
+			if !want {
+				t.Errorf("%s: supported but not declared by Capabilities()", c)
+			}
+		case StatusNotSupported:
+			if want {
+				t.Errorf("%s: declared by Capabilities() but the driver returned model.ErrNotSupported", c)
+			}
+		}
+	}
+}
+
+// supported returns when err is nil. It skips the test when err is model.ErrNotSupported
+// and fails it for other errors.
+func (s *suite) supported(t *testing.T, c Capability, op string, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	if errors.Is(err, model.ErrNotSupported) {
+		s.report.set(c, StatusNotSupported)
+		t.Skipf("%s: not supported", op)
+	}
+	t.Fatalf("%s: %v", op, err)
+}
+
+// uniqueName returns a DNS-1123 compliant name unique to this run.
+func uniqueName(t *testing.T, prefix string) string {
+	t.Helper()
+	id, err := naming.NewCompactID()
+	if err != nil {
+		t.Fatalf("compact id: %v", err)
+	}
+	return prefix + "-" + id
+}
+
+// requireVolume skips volume checks when the fixture has no app.
+func (s *suite) requireVolume(t *testing.T) {
+	t.Helper()
+	if s.fx.App == nil || s.fx.VolumeName == "" {
+		t.Skip("no App/VolumeName fixture")
+	}
+}
+
+// createDisk creates a disk and registers its deletion as cleanup.
+func (s *suite) createDisk(t *testing.T, ctx context.Context, source string) *model.VolumeDisk {
+	t.Helper()
+	name := uniqueName(t, "conf")
+	disk, err := s.drv.VolumeDiskCreate(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName, name, source)
+	s.supported(t, CapVolumeDisk, "VolumeDiskCreate", err)
+	if disk == nil || disk.Name != name {
+		t.Fatalf("VolumeDiskCreate returned %+v, want disk named %s", disk, name)
+	}
+	t.Cleanup(func() {
+		if err := s.drv.VolumeDiskDelete(context.Background(), s.fx.Cluster, s.fx.App, s.fx.VolumeName, name); err != nil {
+			t.Errorf("cleanup VolumeDiskDelete %s: %v", name, err)
+		}
+	})
+	return disk
+}
+
+func (s *suite) checkClusterProvision(t *testing.T, ctx context.Context) {
+	if s.fx.Cluster.Existing {
+		t.Skip("existing cluster")
+	}
+	for i := range 2 {
+		err := s.drv.ClusterProvision(ctx, s.fx.Cluster)
+		s.supported(t, CapClusterProvision, fmt.Sprintf("ClusterProvision #%d", i+1), err)
+	}
+	status, err := s.drv.ClusterStatus(ctx, s.fx.Cluster)
+	s.supported(t, CapClusterProvision, "ClusterStatus", err)
+	if !status.Provisioned {
+		t.Errorf("ClusterStatus.Provisioned = false after ClusterProvision")
+	}
+}
+
+func (s *suite) checkDiskDeleteNotFound(t *testing.T, ctx context.Context) {
+	s.requireVolume(t)
+	err := s.drv.VolumeDiskDelete(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName, uniqueName(t, "missing"))
+	s.supported(t, CapVolumeDisk, "VolumeDiskDelete", err)
+}
+
+func (s *suite) checkDiskAssignUnique(t *testing.T, ctx context.Context) {
+	s.requireVolume(t)
+	first := s.createDisk(t, ctx, "")
+	second := s.createDisk(t, ctx, "")
+
+	for _, name := range []string{first.Name, second.Name} {
+		err := s.drv.VolumeDiskAssign(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName, name)
+		s.supported(t, CapVolumeDisk, "VolumeDiskAssign "+name, err)
+
+		disks, err := s.drv.VolumeDiskList(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName)
+		s.supported(t, CapVolumeDisk, "VolumeDiskList", err)
+		var assigned []string
+		for i, d := range disks {
+			if i > 0 && d.CreatedAt.After(disks[i-1].CreatedAt) {
+				t.Errorf("VolumeDiskList not sorted by CreatedAt descending: %s before %s", disks[i-1].Name, d.Name)
+			}
+			if d.Assigned {
+				assigned = append(assigned, d.Name)
+			}
+		}
+		if len(assigned) != 1 || assigned[0] != name {
+			t.Errorf("after assigning %s, assigned disks = %v; want exactly [%s]", name, assigned, name)
+		}
+	}
+}
+
+func (s *suite) checkSnapshotDeleteNotFound(t *testing.T, ctx context.Context) {
+	s.requireVolume(t)
+	err := s.drv.VolumeSnapshotDelete(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName, uniqueName(t, "missing"))
+	s.supported(t, CapVolumeSnapshot, "VolumeSnapshotDelete", err)
+}
+
+func (s *suite) checkSnapshotRestore(t *testing.T, ctx context.Context) {
+	s.requireVolume(t)
+	disk := s.createDisk(t, ctx, "")
+
+	snapName := uniqueName(t, "conf")
+	snap, err := s.drv.VolumeSnapshotCreate(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName, snapName, "disk:"+disk.Name)
+	s.supported(t, CapVolumeSnapshot, "VolumeSnapshotCreate", err)
+	t.Cleanup(func() {
+		if err := s.drv.VolumeSnapshotDelete(context.Background(), s.fx.Cluster, s.fx.App, s.fx.VolumeName, snapName); err != nil {
+			t.Errorf("cleanup VolumeSnapshotDelete %s: %v", snapName, err)
+		}
+	})
+	if snap == nil || snap.Name != snapName || snap.Handle == "" {
+		t.Fatalf("VolumeSnapshotCreate returned %+v, want snapshot named %s with a handle", snap, snapName)
+	}
+	if snap.SourceHandle != "" && snap.SourceHandle != disk.Handle {
+		t.Errorf("snapshot SourceHandle = %q, want %q", snap.SourceHandle, disk.Handle)
+	}
+
+	snaps, err := s.drv.VolumeSnapshotList(ctx, s.fx.Cluster, s.fx.App, s.fx.VolumeName)
+	s.supported(t, CapVolumeSnapshot, "VolumeSnapshotList", err)
+	if !slices.ContainsFunc(snaps, func(v *model.VolumeSnapshot) bool { return v.Name == snapName }) {
+		t.Errorf("VolumeSnapshotList does not contain %s", snapName)
+	}
+
+	restored := s.createDisk(t, ctx, "snapshot:"+snapName)
+	if restored.SourceHandle != "" && restored.SourceHandle != snap.Handle {
+		t.Errorf("restored disk SourceHandle = %q, want %q", restored.SourceHandle, snap.Handle)
+	}
+	if restored.Size != 0 && disk.Size != 0 && restored.Size < disk.Size {
+		t.Errorf("restored disk size %d is smaller than source disk size %d", restored.Size, disk.Size)
+	}
+}
+
+func (s *suite) checkVolumeResourceList(t *testing.T, ctx context.Context) {
+	res, err := s.drv.VolumeResourceList(ctx)
+	s.supported(t, CapVolumeInventory, "VolumeResourceList", err)
+	for _, r := range res {
+		if r == nil || r.Kind == "" || r.Handle == "" {
+			t.Errorf("VolumeResourceList returned incomplete resource %+v", r)
+		}
+	}
+}
+
+func (s *suite) checkNodePoolList(t *testing.T, ctx context.Context) {
+	pools, err := s.drv.NodePoolList(ctx, s.fx.Cluster)
+	s.supported(t, CapNodePoolList, "NodePoolList", err)
+	for _, p := range pools {
+		if p == nil || p.Name == nil || *p.Name == "" {
+			t.Errorf("NodePoolList returned pool without name: %+v", p)
+		}
+	}
+}
+
+func (s *suite) checkNodePoolImmutability(t *testing.T, ctx context.Context) {
+	if s.fx.NodePool == nil {
+		t.Skip("no NodePool fixture")
+	}
+	pool := *s.fx.NodePool
+	name := uniqueName(t, "np")
+	pool.Name = &name
+	created, err := s.drv.NodePoolCreate(ctx, s.fx.Cluster, pool)
+	s.supported(t, CapNodePoolMutate, "NodePoolCreate", err)
+	t.Cleanup(func() {
+		if err := s.drv.NodePoolDelete(context.Background(), s.fx.Cluster, name); err != nil {
+			t.Errorf("cleanup NodePoolDelete %s: %v", name, err)
+		}
+	})
+	if created == nil || created.Name == nil || *created.Name != name {
+		t.Fatalf("NodePoolCreate returned %+v, want pool named %s", created, name)
+	}
+
+	change := s.fx.ImmutableChange
+	change.Name = &name
+	_, err = s.drv.NodePoolUpdate(ctx, s.fx.Cluster, change)
+	switch {
+	case err == nil:
+		t.Errorf("NodePoolUpdate accepted a change of immutable fields")
+	case errors.Is(err, model.ErrNotSupported):
+		t.Errorf("NodePoolUpdate returned ErrNotSupported for an immutable change; want a validation error")
+	}
+}
+
+func (s *suite) checkNodePoolDeleteNotFound(t *testing.T, ctx context.Context) {
+	if s.fx.NodePool == nil {
+		t.Skip("no NodePool fixture")
+	}
+	err := s.drv.NodePoolDelete(ctx, s.fx.Cluster, uniqueName(t, "missing"))
+	s.supported(t, CapNodePoolMutate, "NodePoolDelete", err)
+}
+
+func (s *suite) checkDNSBestEffort(t *testing.T, ctx context.Context) {
+	if s.fx.DNSRecord.FQDN == "" {
+		t.Skip("no DNSRecord fixture")
+	}
+	invalid := model.DNSRecordSet{Type: s.fx.DNSRecord.Type, RData: s.fx.DNSRecord.RData}
+	err := s.drv.ClusterDNSApply(ctx, s.fx.Cluster, invalid)
+	s.supported(t, CapDNSApply, "ClusterDNSApply (invalid, non-strict)", err)
+	if err := s.drv.ClusterDNSApply(ctx, s.fx.Cluster, invalid, model.WithClusterDNSApplyStrict()); err == nil {
+		t.Errorf("ClusterDNSApply accepted an empty FQDN with Strict")
+	}
+}
+
+func (s *suite) checkDNSDryRun(t *testing.T, ctx context.Context) {
+	if s.fx.DNSRecord.FQDN == "" {
+		t.Skip("no DNSRecord fixture")
+	}
+	rset := s.fx.DNSRecord
+	err := s.drv.ClusterDNSApply(ctx, s.fx.Cluster, rset, model.WithClusterDNSApplyDryRun(), model.WithClusterDNSApplyStrict())
+	s.supported(t, CapDNSApply, "ClusterDNSApply (dry-run)", err)
+	if s.fx.LookupDNS == nil {
+		return
+	}
+	lookup := func(when string) bool {
+		t.Helper()
+		_, found, err := s.fx.LookupDNS(ctx, rset.FQDN, rset.Type)
+		if err != nil {
+			t.Fatalf("LookupDNS %s: %v", when, err)
+		}
+		return found
+	}
+	if lookup("after dry-run") {
+		t.Fatalf("record %s %s exists after dry-run apply", rset.FQDN, rset.Type)
+	}
+
+	err = s.drv.ClusterDNSApply(ctx, s.fx.Cluster, rset, model.WithClusterDNSApplyStrict())
+	s.supported(t, CapDNSApply, "ClusterDNSApply", err)
+	if !lookup("after apply") {
+		t.Errorf("record %s %s not found after apply", rset.FQDN, rset.Type)
+	}
+
+	deletion := model.DNSRecordSet{FQDN: rset.FQDN, Type: rset.Type}
+	if err := s.drv.ClusterDNSApply(ctx, s.fx.Cluster, deletion, model.WithClusterDNSApplyDryRun(), model.WithClusterDNSApplyStrict()); err != nil {
+		t.Fatalf("ClusterDNSApply (delete, dry-run): %v", err)
+	}
+	if !lookup("after delete dry-run") {
+		t.Errorf("record %s %s deleted by dry-run", rset.FQDN, rset.Type)
+	}
+	if err := s.drv.ClusterDNSApply(ctx, s.fx.Cluster, deletion, model.WithClusterDNSApplyStrict()); err != nil {
+		t.Fatalf("ClusterDNSApply (delete): %v", err)
+	}
+	if lookup("after delete") {
+		t.Errorf("record %s %s exists after delete", rset.FQDN, rset.Type)
+	}
+}
@@ -0,0 +1,87 @@
+package conformance
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/fake"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// limitedDriver wraps a driver and reports node pool mutation and volume inventory as unsupported.
+type limitedDriver struct {
+	providerdrv.Driver
+}
+
+func (d *limitedDriver) Capabilities() model.DriverCapabilities {
+	caps := d.Driver.Capabilities()
+	caps.VolumeInventory = false
+	caps.NodePool = model.NodePoolCapabilities{List: true, Update: true}
+	return caps
+}
+func (d *limitedDriver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolCreateOption) (*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *limitedDriver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, _ ...model.NodePoolDeleteOption) error {
+	return model.ErrNotSupported
+}
+func (d *limitedDriver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
+	return nil, model.ErrNotSupported
+}
+
+func TestRunReportsCapabilities(t *testing.T) {
+	factory, ok := providerdrv.GetDriverFactory("fake")
+	if !ok {
+		t.Fatal("fake driver not registered")
+	}
+	report := Run(t, Fixture{
+		Factory: func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+			drv, err := factory(workspace, provider)
+			if err != nil {
+				return nil, err
+			}
+			return &limitedDriver{Driver: drv}, nil
+		},
+		Provider:   &model.Provider{Name: "prv", Driver: "fake", Settings: map[string]string{"FAKE_STATE_FILE": filepath.Join(t.TempDir(), "state.json")}},
+		Cluster:    &model.Cluster{Name: "cls1"},
+		App:        &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db"}}},
+		VolumeName: "db",
+		NodePool:   &model.NodePool{},
+		Timeout:    time.Minute,
+	})
+
+	want := map[Capability]Status{
+		CapClusterProvision: StatusSupported,
+		CapVolumeDisk:       StatusSupported,
+		CapVolumeSnapshot:   StatusSupported,
+		CapVolumeInventory:  StatusNotSupported,
+		CapNodePoolList:     StatusSupported,
+		CapNodePoolMutate:   StatusNotSupported,
+		CapDNSApply:         StatusSkipped,
+	}
+	for c, s := range want {
+		if got := report.Status(c); got != s {
+			t.Errorf("Status(%s) = %s, want %s", c, got, s)
+		}
+	}
+}
+
+func TestReportSet(t *testing.T) {
+	r := &Report{statuses: map[Capability]Status{}}
+	r.set(CapVolumeDisk, StatusNotSupported)
+	r.set(CapVolumeDisk, StatusSupported)
+	if got := r.Status(CapVolumeDisk); got != StatusNotSupported {
+		t.Errorf("not-supported must not be overwritten by supported, got %s", got)
+	}
+	r.set(CapVolumeDisk, StatusFailed)
+	r.set(CapVolumeDisk, StatusSupported)
+	if got := r.Status(CapVolumeDisk); got != StatusFailed {
+		t.Errorf("failed must be sticky, got %s", got)
+	}
+	if got := r.String(); got != "volume.disk: failed\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// pollInterval is the interval between polls of resource states.
+var pollInterval = 10 * time.Second
+
+// ec2Client returns an EC2 client (VPC, subnets, EBS volumes and snapshots).
+func (d *driver) ec2Client() *ec2.Client { return ec2.NewFromConfig(d.awsConfig) }
+
+// eksClient returns an EKS client (clusters, node groups, add-ons, pod identity associations).
+func (d *driver) eksClient() *eks.Client { return eks.NewFromConfig(d.awsConfig) }
+
+// iamClient returns an IAM client (roles, OIDC providers).
+func (d *driver) iamClient() *iam.Client { return iam.NewFromConfig(d.awsConfig) }
+
+// route53Client returns a Route53 client (hosted zones, record sets).
+func (d *driver) route53Client() *route53.Client { return route53.NewFromConfig(d.awsConfig) }
+
+// stsClient returns an STS client (caller identity, kube API tokens).
+func (d *driver) stsClient() *sts.Client { return sts.NewFromConfig(d.awsConfig) }
+
+// apiErrorCode returns the error code of an AWS API error or "".
+func apiErrorCode(err error) string {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		return ae.ErrorCode()
+	}
+	return ""
+}
+
+// isNotFoundError checks if an error reports a missing resource.
+// EC2 uses "<Resource>.NotFound" codes while EKS, IAM and Route53 use dedicated codes.
+func isNotFoundError(err error) bool {
+	code := apiErrorCode(err)
+	return strings.HasSuffix(code, "NotFound") || code == "ResourceNotFoundException" || code == "NoSuchEntity"
+}
+
+// isDependencyError checks if an error reports dependent resources that still exist.
+func isDependencyError(err error) bool {
+	code := apiErrorCode(err)
+	return code == "DependencyViolation" || code == "DeleteConflict" || code == "ResourceInUseException"
+}
+
+// waitFor polls check every pollInterval until it reports done, returns an error or ctx expires.
+func waitFor(ctx context.Context, what string, check func(ctx context.Context) (bool, error)) error {
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for %s: %w", what, err)
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for %s: %w", what, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// retryDependency retries a delete call while AWS reports dependent resources
+// (e.g. ENIs of a deleted load balancer that are released asynchronously).
+func retryDependency(ctx context.Context, what string, fn func(ctx context.Context) error) error {
+	return waitFor(ctx, what, func(ctx context.Context) (bool, error) {
+		err := fn(ctx)
+		if err == nil || isNotFoundError(err) {
+			return true, nil
+		}
+		if isDependencyError(err) {
+			return false, nil
+		}
+		return false, err
+	})
+}
+
+// availabilityZones lists the available (non local/wavelength) AZ names of the region in sorted order.
+func (d *driver) availabilityZones(ctx context.Context) ([]string, error) {
+	out, err := d.ec2Client().DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("zone-type"), Values: []string{"availability-zone"}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describe availability zones: %w", err)
+	}
+	var azs []string
+	for _, az := range out.AvailabilityZones {
+		if az.ZoneName != nil && az.State == ec2types.AvailabilityZoneStateAvailable {
+			azs = append(azs, *az.ZoneName)
+		}
+	}
+	if len(azs) == 0 {
+		return nil, fmt.Errorf("no availability zones found in region %s", d.region)
+	}
+	sort.Strings(azs)
+	return azs, nil
+}
+
+// ec2Tags converts a tag map into EC2 tags sorted by key.
+func ec2Tags(m map[string]string) []ec2types.Tag {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]ec2types.Tag, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, ec2types.Tag{Key: aws.String(k), Value: aws.String(m[k])})
+	}
+	return tags
+}
+
+// ec2TagMap converts EC2 tags into a map.
+func ec2TagMap(tags []ec2types.Tag) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		if t.Key != nil && t.Value != nil {
+			m[*t.Key] = *t.Value
+		}
+	}
+	return m
+}
+
+// tagSpec returns the TagSpecifications for creating a resource of the given type.
+func tagSpec(resourceType ec2types.ResourceType, m map[string]string) []ec2types.TagSpecification {
+	return []ec2types.TagSpecification{{ResourceType: resourceType, Tags: ec2Tags(m)}}
+}
+
+// tagFilters returns EC2 filters matching all the given tags.
+func tagFilters(m map[string]string) []ec2types.Filter {
+	filters := make([]ec2types.Filter, 0, len(m))
+	for _, t := range ec2Tags(m) {
+		filters = append(filters, ec2types.Filter{Name: aws.String("tag:" + *t.Key), Values: []string{*t.Value}})
+	}
+	return filters
+}
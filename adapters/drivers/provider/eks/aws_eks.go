@@ -0,0 +1,330 @@
+package eks
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// EKS add-ons installed by ClusterProvision.
+const (
+	addonPodIdentityAgent = "eks-pod-identity-agent"
+	addonEBSCSIDriver     = "aws-ebs-csi-driver"
+	ebsCSIServiceAccount  = "ebs-csi-controller-sa"
+)
+
+// Kubernetes API authentication with IAM (same token format as "aws eks get-token").
+const (
+	tokenPrefix     = "k8s-aws-v1."
+	clusterIDHeader = "x-k8s-aws-id"
+)
+
+// findEKSCluster returns the EKS cluster or nil when not found.
+func (d *driver) findEKSCluster(ctx context.Context, name string) (*ekstypes.Cluster, error) {
+	out, err := d.eksClient().DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String(name)})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("describe EKS cluster %s: %w", name, err)
+	}
+	return out.Cluster, nil
+}
+
+// activeEKSCluster returns the EKS cluster of the Kompox cluster and fails unless it is ACTIVE.
+func (d *driver) activeEKSCluster(ctx context.Context, cluster *model.Cluster) (*ekstypes.Cluster, error) {
+	name, err := d.eksClusterName(cluster)
+	if err != nil {
+		return nil, err
+	}
+	c, err := d.findEKSCluster(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("EKS cluster %s not found", name)
+	}
+	if c.Status != ekstypes.ClusterStatusActive {
+		return nil, fmt.Errorf("EKS cluster %s is %s", name, c.Status)
+	}
+	return c, nil
+}
+
+// ensureEKSClusterCreated creates the EKS cluster when missing and waits until it becomes ACTIVE.
+// The cluster uses access entries (API_AND_CONFIG_MAP) and grants the caller cluster admin.
+func (d *driver) ensureEKSClusterCreated(ctx context.Context, cluster *model.Cluster, name, roleARN string, net *networkState, tags map[string]string) (*ekstypes.Cluster, error) {
+	log := logging.FromContext(ctx)
+	c := d.eksClient()
+
+	existing, err := d.findEKSCluster(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		in := &eks.CreateClusterInput{
+			Name:    aws.String(name),
+			RoleArn: aws.String(roleARN),
+			ResourcesVpcConfig: &ekstypes.VpcConfigRequest{
+				SubnetIds:             net.SubnetIDs(),
+				EndpointPublicAccess:  aws.Bool(true),
+				EndpointPrivateAccess: aws.Bool(true),
+			},
+			AccessConfig: &ekstypes.CreateAccessConfigRequest{
+				AuthenticationMode:                      ekstypes.AuthenticationModeApiAndConfigMap,
+				BootstrapClusterCreatorAdminPermissions: aws.Bool(true),
+			},
+			Tags: tags,
+		}
+		if v := clusterSetting(cluster, keyKubernetesVersion); v != "" {
+			in.Version = aws.String(strings.TrimPrefix(v, "v"))
+		}
+		log.Info(ctx, "creating EKS cluster", "name", name, "version", aws.ToString(in.Version))
+		if _, err := c.CreateCluster(ctx, in); err != nil {
+			return nil, fmt.Errorf("create EKS cluster %s: %w", name, err)
+		}
+	}
+
+	var active *ekstypes.Cluster
+	err = waitFor(ctx, "EKS cluster "+name, func(ctx context.Context) (bool, error) {
+		got, err := d.findEKSCluster(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		if got == nil {
+			return false, fmt.Errorf("EKS cluster %s disappeared", name)
+		}
+		active = got
+		switch got.Status {
+		case ekstypes.ClusterStatusFailed, ekstypes.ClusterStatusDeleting:
+			return false, fmt.Errorf("EKS cluster %s is %s", name, got.Status)
+		}
+		return got.Status == ekstypes.ClusterStatusActive, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return active, nil
+}
+
+// ensureAddons installs the Pod Identity agent and the EBS CSI driver add-ons.
+// The EBS CSI controller gets its AWS permissions through a Pod Identity association.
+func (d *driver) ensureAddons(ctx context.Context, name, ebsRoleARN string, tags map[string]string) error {
+	log := logging.FromContext(ctx)
+	c := d.eksClient()
+
+	addons := []*eks.CreateAddonInput{
+		{ClusterName: aws.String(name), AddonName: aws.String(addonPodIdentityAgent), Tags: tags},
+		{
+			ClusterName: aws.String(name),
+			AddonName:   aws.String(addonEBSCSIDriver),
+			PodIdentityAssociations: []ekstypes.AddonPodIdentityAssociations{
+				{RoleArn: aws.String(ebsRoleARN), ServiceAccount: aws.String(ebsCSIServiceAccount)},
+			},
+			ResolveConflicts: ekstypes.ResolveConflictsOverwrite,
+			Tags:             tags,
+		},
+	}
+	for _, in := range addons {
+		addonName := aws.ToString(in.AddonName)
+		if _, err := c.DescribeAddon(ctx, &eks.DescribeAddonInput{ClusterName: in.ClusterName, AddonName: in.AddonName}); err == nil {
+			continue
+		} else if !isNotFoundError(err) {
+			return fmt.Errorf("describe add-on %s: %w", addonName, err)
+		}
+		log.Info(ctx, "creating EKS add-on", "addon", addonName)
+		if _, err := c.CreateAddon(ctx, in); err != nil {
+			return fmt.Errorf("create add-on %s: %w", addonName, err)
+		}
+		err := waitFor(ctx, "add-on "+addonName, func(ctx context.Context) (bool, error) {
+			out, err := c.DescribeAddon(ctx, &eks.DescribeAddonInput{ClusterName: in.ClusterName, AddonName: in.AddonName})
+			if err != nil {
+				return false, err
+			}
+			switch out.Addon.Status {
+			case ekstypes.AddonStatusCreateFailed:
+				return false, fmt.Errorf("add-on %s is %s", addonName, out.Addon.Status)
+			}
+			return out.Addon.Status == ekstypes.AddonStatusActive, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureEKSClusterDeleted deletes the managed node groups and the EKS cluster and waits for completion.
+func (d *driver) ensureEKSClusterDeleted(ctx context.Context, name string) error {
+	log := logging.FromContext(ctx)
+	c := d.eksClient()
+
+	existing, err := d.findEKSCluster(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		log.Info(ctx, "EKS cluster not found, nothing to delete", "name", name)
+		return nil
+	}
+
+	groups, err := d.listNodeGroups(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, ng := range groups {
+		if err := d.deleteNodeGroup(ctx, name, aws.ToString(ng.NodegroupName)); err != nil {
+			return err
+		}
+	}
+
+	log.Info(ctx, "deleting EKS cluster", "name", name)
+	if existing.Status != ekstypes.ClusterStatusDeleting {
+		if _, err := c.DeleteCluster(ctx, &eks.DeleteClusterInput{Name: aws.String(name)}); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("delete EKS cluster %s: %w", name, err)
+		}
+	}
+	return waitFor(ctx, "EKS cluster "+name+" deletion", func(ctx context.Context) (bool, error) {
+		got, err := d.findEKSCluster(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		return got == nil, nil
+	})
+}
+
+// eksKubeconfig returns a kubeconfig of the EKS cluster using "aws eks get-token" as the exec credential plugin.
+func (d *driver) eksKubeconfig(c *ekstypes.Cluster) ([]byte, error) {
+	ca, err := clusterCAData(c)
+	if err != nil {
+		return nil, err
+	}
+	name := aws.ToString(c.Name)
+	exec := &clientcmdapi.ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1beta1",
+		Command:         "aws",
+		Args:            []string{"eks", "get-token", "--cluster-name", name, "--region", d.region, "--output", "json"},
+		InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+	}
+	if d.profile != "" {
+		exec.Env = []clientcmdapi.ExecEnvVar{{Name: "AWS_PROFILE", Value: d.profile}}
+	}
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters[name] = &clientcmdapi.Cluster{Server: aws.ToString(c.Endpoint), CertificateAuthorityData: ca}
+	cfg.AuthInfos[name] = &clientcmdapi.AuthInfo{Exec: exec}
+	cfg.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	cfg.CurrentContext = name
+	out, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("write kubeconfig: %w", err)
+	}
+	return out, nil
+}
+
+// eksRESTConfig returns a REST config authenticated with a short-lived IAM token of the driver credentials,
+// so that the driver itself does not depend on the aws CLI.
+func (d *driver) eksRESTConfig(ctx context.Context, c *ekstypes.Cluster) (*rest.Config, error) {
+	ca, err := clusterCAData(c)
+	if err != nil {
+		return nil, err
+	}
+	token, err := d.eksToken(ctx, aws.ToString(c.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &rest.Config{
+		Host:            aws.ToString(c.Endpoint),
+		BearerToken:     token,
+		TLSClientConfig: rest.TLSClientConfig{CAData: ca},
+	}, nil
+}
+
+// eksToken generates a Kubernetes API token from a presigned STS GetCallerIdentity request.
+func (d *driver) eksToken(ctx context.Context, name string) (string, error) {
+	ps := sts.NewPresignClient(d.stsClient())
+	req, err := ps.PresignGetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(o *sts.PresignOptions) {
+		o.ClientOptions = append(o.ClientOptions, func(so *sts.Options) {
+			so.APIOptions = append(so.APIOptions,
+				smithyhttp.SetHeaderValue(clusterIDHeader, name),
+				smithyhttp.SetHeaderValue("X-Amz-Expires", "60"),
+			)
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("presign sts GetCallerIdentity: %w", err)
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(req.URL)), nil
+}
+
+// clusterCAData decodes the certificate authority data of the EKS cluster.
+func clusterCAData(c *ekstypes.Cluster) ([]byte, error) {
+	if c.CertificateAuthority == nil || c.CertificateAuthority.Data == nil {
+		return nil, fmt.Errorf("EKS cluster %s has no certificate authority data", aws.ToString(c.Name))
+	}
+	ca, err := base64.StdEncoding.DecodeString(*c.CertificateAuthority.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode certificate authority data: %w", err)
+	}
+	return ca, nil
+}
+
+// clusterSetting returns a trimmed cluster setting value.
+func clusterSetting(cluster *model.Cluster, key string) string {
+	if cluster == nil || cluster.Settings == nil {
+		return ""
+	}
+	return strings.TrimSpace(cluster.Settings[key])
+}
+
+// ensurePodIdentityAssociation associates the IAM role with the service account through EKS Pod Identity.
+// An existing association of the service account is updated to the given role.
+func (d *driver) ensurePodIdentityAssociation(ctx context.Context, clusterName, namespace, serviceAccount, roleARN string, tags map[string]string) error {
+	c := d.eksClient()
+	out, err := c.ListPodIdentityAssociations(ctx, &eks.ListPodIdentityAssociationsInput{
+		ClusterName:    aws.String(clusterName),
+		Namespace:      aws.String(namespace),
+		ServiceAccount: aws.String(serviceAccount),
+	})
+	if err != nil {
+		return fmt.Errorf("list pod identity associations: %w", err)
+	}
+	for _, a := range out.Associations {
+		got, err := c.DescribePodIdentityAssociation(ctx, &eks.DescribePodIdentityAssociationInput{ClusterName: aws.String(clusterName), AssociationId: a.AssociationId})
+		if err != nil {
+			return fmt.Errorf("describe pod identity association: %w", err)
+		}
+		if aws.ToString(got.Association.RoleArn) == roleARN {
+			return nil
+		}
+		if _, err := c.UpdatePodIdentityAssociation(ctx, &eks.UpdatePodIdentityAssociationInput{
+			ClusterName:   aws.String(clusterName),
+			AssociationId: a.AssociationId,
+			RoleArn:       aws.String(roleARN),
+		}); err != nil {
+			return fmt.Errorf("update pod identity association: %w", err)
+		}
+		return nil
+	}
+	logging.FromContext(ctx).Info(ctx, "creating pod identity association", "namespace", namespace, "serviceAccount", serviceAccount)
+	if _, err := c.CreatePodIdentityAssociation(ctx, &eks.CreatePodIdentityAssociationInput{
+		ClusterName:    aws.String(clusterName),
+		Namespace:      aws.String(namespace),
+		ServiceAccount: aws.String(serviceAccount),
+		RoleArn:        aws.String(roleARN),
+		Tags:           tags,
+	}); err != nil {
+		return fmt.Errorf("create pod identity association: %w", err)
+	}
+	return nil
+}
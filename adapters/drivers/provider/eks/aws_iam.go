@@ -0,0 +1,249 @@
+package eks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// AWS managed policies attached to the cluster-scoped roles.
+const (
+	policyEKSCluster       = "AmazonEKSClusterPolicy"
+	policyEKSWorkerNode    = "AmazonEKSWorkerNodePolicy"
+	policyEKSCNI           = "AmazonEKS_CNI_Policy"
+	policyECRReadOnly      = "AmazonEC2ContainerRegistryReadOnly" // ECR image pull for the kubelet
+	policyEBSCSIDriver     = "service-role/AmazonEBSCSIDriverPolicy"
+	inlinePolicyIngressDNS = "kompox-ingress-route53"
+)
+
+// Role name suffixes of the cluster-scoped IAM roles.
+const (
+	roleCluster = "cluster"
+	roleNode    = "node"
+	roleEBSCSI  = "ebs-csi"
+	roleIngress = "ingress"
+)
+
+// partition returns the AWS partition of the driver region.
+func (d *driver) partition() string {
+	switch {
+	case strings.HasPrefix(d.region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(d.region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+// managedPolicyARN returns the ARN of an AWS managed policy in the driver partition.
+func (d *driver) managedPolicyARN(name string) string {
+	return fmt.Sprintf("arn:%s:iam::aws:policy/%s", d.partition(), name)
+}
+
+// policyDocument renders an IAM policy document with the given statements.
+func policyDocument(statements ...map[string]any) string {
+	b, _ := json.Marshal(map[string]any{"Version": "2012-10-17", "Statement": statements})
+	return string(b)
+}
+
+// serviceTrustPolicy allows an AWS service principal (e.g. eks.amazonaws.com) to assume the role.
+func serviceTrustPolicy(service string) string {
+	return policyDocument(map[string]any{
+		"Effect":    "Allow",
+		"Principal": map[string]any{"Service": service},
+		"Action":    "sts:AssumeRole",
+	})
+}
+
+// podIdentityTrustPolicy allows EKS Pod Identity to assume the role on behalf of service accounts.
+func podIdentityTrustPolicy() string {
+	return policyDocument(map[string]any{
+		"Effect":    "Allow",
+		"Principal": map[string]any{"Service": "pods.eks.amazonaws.com"},
+		"Action":    []string{"sts:AssumeRole", "sts:TagSession"},
+	})
+}
+
+// irsaTrustPolicy allows a single service account to assume the role through the cluster OIDC provider (IRSA).
+func irsaTrustPolicy(providerARN, issuer, namespace, serviceAccount string) string {
+	issuer = strings.TrimPrefix(issuer, "https://")
+	return policyDocument(map[string]any{
+		"Effect":    "Allow",
+		"Principal": map[string]any{"Federated": providerARN},
+		"Action":    "sts:AssumeRoleWithWebIdentity",
+		"Condition": map[string]any{
+			"StringEquals": map[string]any{
+				issuer + ":sub": fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount),
+				issuer + ":aud": "sts.amazonaws.com",
+			},
+		},
+	})
+}
+
+// route53Policy grants record management on the given hosted zones (used for ACME DNS-01 by the ingress).
+func (d *driver) route53Policy(zoneIDs []string) string {
+	resources := make([]string, 0, len(zoneIDs))
+	for _, id := range zoneIDs {
+		resources = append(resources, fmt.Sprintf("arn:%s:route53:::hostedzone/%s", d.partition(), id))
+	}
+	return policyDocument(
+		map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"route53:ChangeResourceRecordSets", "route53:ListResourceRecordSets"},
+			"Resource": resources,
+		},
+		map[string]any{
+			"Effect":   "Allow",
+			"Action":   []string{"route53:GetChange", "route53:ListHostedZones", "route53:ListHostedZonesByName"},
+			"Resource": "*",
+		},
+	)
+}
+
+// iamTags converts a tag map into IAM tags sorted by key.
+func iamTags(m map[string]string) []iamtypes.Tag {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]iamtypes.Tag, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, iamtypes.Tag{Key: aws.String(k), Value: aws.String(m[k])})
+	}
+	return tags
+}
+
+// ensureRole converges an IAM role: trust policy, attached managed policies and an optional inline policy.
+// Returns the role ARN.
+func (d *driver) ensureRole(ctx context.Context, name, trustPolicy string, managedPolicies []string, inlinePolicy string, tags map[string]string) (string, error) {
+	log := logging.FromContext(ctx)
+	c := d.iamClient()
+
+	var arn string
+	got, err := c.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+	switch {
+	case err == nil:
+		arn = aws.ToString(got.Role.Arn)
+		if _, err := c.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{RoleName: aws.String(name), PolicyDocument: aws.String(trustPolicy)}); err != nil {
+			return "", fmt.Errorf("update trust policy of role %s: %w", name, err)
+		}
+	case isNotFoundError(err):
+		log.Info(ctx, "creating IAM role", "role", name)
+		out, err := c.CreateRole(ctx, &iam.CreateRoleInput{
+			RoleName:                 aws.String(name),
+			AssumeRolePolicyDocument: aws.String(trustPolicy),
+			Tags:                     iamTags(tags),
+		})
+		if err != nil {
+			return "", fmt.Errorf("create role %s: %w", name, err)
+		}
+		arn = aws.ToString(out.Role.Arn)
+	default:
+		return "", fmt.Errorf("get role %s: %w", name, err)
+	}
+
+	for _, p := range managedPolicies {
+		if _, err := c.AttachRolePolicy(ctx, &iam.AttachRolePolicyInput{RoleName: aws.String(name), PolicyArn: aws.String(d.managedPolicyARN(p))}); err != nil {
+			return "", fmt.Errorf("attach policy %s to role %s: %w", p, name, err)
+		}
+	}
+	if inlinePolicy != "" {
+		if _, err := c.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
+			RoleName:       aws.String(name),
+			PolicyName:     aws.String(inlinePolicyIngressDNS),
+			PolicyDocument: aws.String(inlinePolicy),
+		}); err != nil {
+			return "", fmt.Errorf("put inline policy of role %s: %w", name, err)
+		}
+	} else if _, err := c.DeleteRolePolicy(ctx, &iam.DeleteRolePolicyInput{RoleName: aws.String(name), PolicyName: aws.String(inlinePolicyIngressDNS)}); err != nil && !isNotFoundError(err) {
+		return "", fmt.Errorf("delete inline policy of role %s: %w", name, err)
+	}
+	return arn, nil
+}
+
+// deleteRole detaches the policies of an IAM role and deletes it. A missing role is not an error.
+func (d *driver) deleteRole(ctx context.Context, name string) error {
+	c := d.iamClient()
+
+	attached, err := c.ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: aws.String(name)})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("list attached policies of role %s: %w", name, err)
+	}
+	for _, p := range attached.AttachedPolicies {
+		if _, err := c.DetachRolePolicy(ctx, &iam.DetachRolePolicyInput{RoleName: aws.String(name), PolicyArn: p.PolicyArn}); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("detach policy from role %s: %w", name, err)
+		}
+	}
+	inline, err := c.ListRolePolicies(ctx, &iam.ListRolePoliciesInput{RoleName: aws.String(name)})
+	if err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("list inline policies of role %s: %w", name, err)
+	}
+	if inline != nil {
+		for _, p := range inline.PolicyNames {
+			if _, err := c.DeleteRolePolicy(ctx, &iam.DeleteRolePolicyInput{RoleName: aws.String(name), PolicyName: aws.String(p)}); err != nil && !isNotFoundError(err) {
+				return fmt.Errorf("delete inline policy from role %s: %w", name, err)
+			}
+		}
+	}
+	logging.FromContext(ctx).Info(ctx, "deleting IAM role", "role", name)
+	if _, err := c.DeleteRole(ctx, &iam.DeleteRoleInput{RoleName: aws.String(name)}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete role %s: %w", name, err)
+	}
+	return nil
+}
+
+// findOIDCProvider returns the ARN of the IAM OIDC provider of the issuer or "" when not registered.
+func (d *driver) findOIDCProvider(ctx context.Context, issuer string) (string, error) {
+	out, err := d.iamClient().ListOpenIDConnectProviders(ctx, &iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return "", fmt.Errorf("list OIDC providers: %w", err)
+	}
+	suffix := ":oidc-provider/" + strings.TrimPrefix(issuer, "https://")
+	for _, p := range out.OpenIDConnectProviderList {
+		if strings.HasSuffix(aws.ToString(p.Arn), suffix) {
+			return aws.ToString(p.Arn), nil
+		}
+	}
+	return "", nil
+}
+
+// ensureOIDCProvider registers the cluster OIDC issuer as an IAM OIDC provider for IRSA.
+func (d *driver) ensureOIDCProvider(ctx context.Context, issuer string, tags map[string]string) (string, error) {
+	arn, err := d.findOIDCProvider(ctx, issuer)
+	if err != nil || arn != "" {
+		return arn, err
+	}
+	logging.FromContext(ctx).Info(ctx, "creating IAM OIDC provider", "issuer", issuer)
+	out, err := d.iamClient().CreateOpenIDConnectProvider(ctx, &iam.CreateOpenIDConnectProviderInput{
+		Url:          aws.String(issuer),
+		ClientIDList: []string{"sts.amazonaws.com"},
+		Tags:         iamTags(tags),
+	})
+	if err != nil {
+		return "", fmt.Errorf("create OIDC provider: %w", err)
+	}
+	return aws.ToString(out.OpenIDConnectProviderArn), nil
+}
+
+// deleteOIDCProvider deletes the IAM OIDC provider of the issuer if registered.
+func (d *driver) deleteOIDCProvider(ctx context.Context, issuer string) error {
+	arn, err := d.findOIDCProvider(ctx, issuer)
+	if err != nil || arn == "" {
+		return err
+	}
+	if _, err := d.iamClient().DeleteOpenIDConnectProvider(ctx, &iam.DeleteOpenIDConnectProviderInput{OpenIDConnectProviderArn: aws.String(arn)}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete OIDC provider: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Network layout: one VPC per cluster with a public subnet in up to maxSubnetAZs availability zones.
+// Nodes get public IPs so that no NAT gateway is needed; load balancers use the same subnets.
+const (
+	vpcCIDR      = "10.0.0.0/16"
+	maxSubnetAZs = 3
+)
+
+// networkState holds the network resources of a cluster.
+type networkState struct {
+	VPCID   string
+	Subnets map[string]string // availability zone -> subnet ID
+	AZs     []string          // availability zones of Subnets in sorted order
+}
+
+// SubnetIDs returns the subnet IDs in availability zone order.
+func (n *networkState) SubnetIDs() []string {
+	ids := make([]string, 0, len(n.AZs))
+	for _, az := range n.AZs {
+		ids = append(ids, n.Subnets[az])
+	}
+	return ids
+}
+
+// SubnetAZs returns the availability zone of each subnet keyed by subnet ID.
+func (n *networkState) SubnetAZs() map[string]string {
+	m := make(map[string]string, len(n.Subnets))
+	for az, id := range n.Subnets {
+		m[id] = az
+	}
+	return m
+}
+
+// subnetCIDR returns the /18 block of the i-th subnet in the VPC.
+func subnetCIDR(i int) string {
+	return fmt.Sprintf("10.0.%d.0/18", i*64)
+}
+
+// findVPC returns the VPC tagged with the cluster hash or nil when not found.
+func (d *driver) findVPC(ctx context.Context, ec *ec2.Client, clusterHash string) (*ec2types.Vpc, error) {
+	out, err := ec.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{Filters: tagFilters(map[string]string{tagClusterHash: clusterHash})})
+	if err != nil {
+		return nil, fmt.Errorf("describe vpcs: %w", err)
+	}
+	if len(out.Vpcs) == 0 {
+		return nil, nil
+	}
+	return &out.Vpcs[0], nil
+}
+
+// findNetwork returns the network state of the cluster or nil when the VPC does not exist.
+func (d *driver) findNetwork(ctx context.Context, cluster *model.Cluster) (*networkState, error) {
+	ec := d.ec2Client()
+	vpc, err := d.findVPC(ctx, ec, d.clusterResourceTags(cluster.Name)[tagClusterHash])
+	if err != nil || vpc == nil {
+		return nil, err
+	}
+	out, err := ec.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{Filters: []ec2types.Filter{{Name: aws.String("vpc-id"), Values: []string{*vpc.VpcId}}}})
+	if err != nil {
+		return nil, fmt.Errorf("describe subnets: %w", err)
+	}
+	n := &networkState{VPCID: *vpc.VpcId, Subnets: map[string]string{}}
+	for _, s := range out.Subnets {
+		if s.AvailabilityZone != nil && s.SubnetId != nil {
+			n.Subnets[*s.AvailabilityZone] = *s.SubnetId
+		}
+	}
+	n.AZs = slices.Sorted(maps.Keys(n.Subnets))
+	return n, nil
+}
+
+// ensureNetworkCreated converges the VPC, internet gateway, route table and public subnets of the cluster.
+func (d *driver) ensureNetworkCreated(ctx context.Context, eksName string, tags map[string]string) (*networkState, error) {
+	log := logging.FromContext(ctx)
+	ec := d.ec2Client()
+
+	named := func(suffix string) map[string]string {
+		m := maps.Clone(tags)
+		m[tagName] = eksName + "-" + suffix
+		return m
+	}
+
+	// VPC
+	vpc, err := d.findVPC(ctx, ec, tags[tagClusterHash])
+	if err != nil {
+		return nil, err
+	}
+	if vpc == nil {
+		log.Info(ctx, "creating VPC", "cidr", vpcCIDR)
+		out, err := ec.CreateVpc(ctx, &ec2.CreateVpcInput{
+			CidrBlock:         aws.String(vpcCIDR),
+			TagSpecifications: tagSpec(ec2types.ResourceTypeVpc, named("vpc")),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create vpc: %w", err)
+		}
+		vpc = out.Vpc
+		if _, err := ec.ModifyVpcAttribute(ctx, &ec2.ModifyVpcAttributeInput{
+			VpcId:              vpc.VpcId,
+			EnableDnsHostnames: &ec2types.AttributeBooleanValue{Value: aws.Bool(true)},
+		}); err != nil {
+			return nil, fmt.Errorf("enable vpc dns hostnames: %w", err)
+		}
+	}
+	vpcID := *vpc.VpcId
+	vpcFilter := ec2types.Filter{Name: aws.String("vpc-id"), Values: []string{vpcID}}
+
+	// Internet gateway
+	igws, err := ec.DescribeInternetGateways(ctx, &ec2.DescribeInternetGatewaysInput{
+		Filters: []ec2types.Filter{{Name: aws.String("attachment.vpc-id"), Values: []string{vpcID}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describe internet gateways: %w", err)
+	}
+	var igwID string
+	if len(igws.InternetGateways) > 0 {
+		igwID = *igws.InternetGateways[0].InternetGatewayId
+	} else {
+		out, err := ec.CreateInternetGateway(ctx, &ec2.CreateInternetGatewayInput{
+			TagSpecifications: tagSpec(ec2types.ResourceTypeInternetGateway, named("igw")),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create internet gateway: %w", err)
+		}
+		igwID = *out.InternetGateway.InternetGatewayId
+		if _, err := ec.AttachInternetGateway(ctx, &ec2.AttachInternetGatewayInput{InternetGatewayId: aws.String(igwID), VpcId: aws.String(vpcID)}); err != nil {
+			return nil, fmt.Errorf("attach internet gateway: %w", err)
+		}
+	}
+
+	// Route table with the default route to the internet gateway
+	rts, err := ec.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{
+		Filters: append(tagFilters(map[string]string{tagClusterHash: tags[tagClusterHash]}), vpcFilter),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describe route tables: %w", err)
+	}
+	var rtID string
+	if len(rts.RouteTables) > 0 {
+		rtID = *rts.RouteTables[0].RouteTableId
+	} else {
+		out, err := ec.CreateRouteTable(ctx, &ec2.CreateRouteTableInput{
+			VpcId:             aws.String(vpcID),
+			TagSpecifications: tagSpec(ec2types.ResourceTypeRouteTable, named("public")),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create route table: %w", err)
+		}
+		rtID = *out.RouteTable.RouteTableId
+		if _, err := ec.CreateRoute(ctx, &ec2.CreateRouteInput{
+			RouteTableId:         aws.String(rtID),
+			DestinationCidrBlock: aws.String("0.0.0.0/0"),
+			GatewayId:            aws.String(igwID),
+		}); err != nil {
+			return nil, fmt.Errorf("create default route: %w", err)
+		}
+	}
+
+	// Public subnets, one per availability zone
+	azs, err := d.availabilityZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(azs) > maxSubnetAZs {
+		azs = azs[:maxSubnetAZs]
+	}
+	n := &networkState{VPCID: vpcID, Subnets: map[string]string{}, AZs: azs}
+	for i, az := range azs {
+		subnets, err := ec.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+			Filters: []ec2types.Filter{vpcFilter, {Name: aws.String("availability-zone"), Values: []string{az}}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describe subnets: %w", err)
+		}
+		if len(subnets.Subnets) > 0 {
+			n.Subnets[az] = *subnets.Subnets[0].SubnetId
+			continue
+		}
+		subnetTags := named("public-" + az)
+		// Subnet discovery tags of the Kubernetes AWS cloud provider for internet-facing load balancers
+		subnetTags["kubernetes.io/role/elb"] = "1"
+		subnetTags["kubernetes.io/cluster/"+eksName] = "shared"
+		log.Info(ctx, "creating subnet", "az", az, "cidr", subnetCIDR(i))
+		out, err := ec.CreateSubnet(ctx, &ec2.CreateSubnetInput{
+			VpcId:             aws.String(vpcID),
+			AvailabilityZone:  aws.String(az),
+			CidrBlock:         aws.String(subnetCIDR(i)),
+			TagSpecifications: tagSpec(ec2types.ResourceTypeSubnet, subnetTags),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create subnet in %s: %w", az, err)
+		}
+		subnetID := *out.Subnet.SubnetId
+		if _, err := ec.ModifySubnetAttribute(ctx, &ec2.ModifySubnetAttributeInput{
+			SubnetId:            aws.String(subnetID),
+			MapPublicIpOnLaunch: &ec2types.AttributeBooleanValue{Value: aws.Bool(true)},
+		}); err != nil {
+			return nil, fmt.Errorf("enable public ip on subnet %s: %w", subnetID, err)
+		}
+		if _, err := ec.AssociateRouteTable(ctx, &ec2.AssociateRouteTableInput{RouteTableId: aws.String(rtID), SubnetId: aws.String(subnetID)}); err != nil {
+			return nil, fmt.Errorf("associate route table with subnet %s: %w", subnetID, err)
+		}
+		n.Subnets[az] = subnetID
+	}
+	return n, nil
+}
+
+// ensureNetworkDeleted deletes the subnets, route tables, security groups, internet gateways and
+// finally the VPC of the cluster. Deletions are retried while AWS still reports dependencies
+// such as network interfaces of load balancers being torn down.
+func (d *driver) ensureNetworkDeleted(ctx context.Context, cluster *model.Cluster) error {
+	log := logging.FromContext(ctx)
+	ec := d.ec2Client()
+
+	vpc, err := d.findVPC(ctx, ec, d.clusterResourceTags(cluster.Name)[tagClusterHash])
+	if err != nil {
+		return err
+	}
+	if vpc == nil {
+		log.Info(ctx, "VPC not found, nothing to delete")
+		return nil
+	}
+	vpcID := *vpc.VpcId
+	vpcFilter := []ec2types.Filter{{Name: aws.String("vpc-id"), Values: []string{vpcID}}}
+
+	subnets, err := ec.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{Filters: vpcFilter})
+	if err != nil {
+		return fmt.Errorf("describe subnets: %w", err)
+	}
+	for _, s := range subnets.Subnets {
+		if err := retryDependency(ctx, "subnet "+*s.SubnetId+" deletion", func(ctx context.Context) error {
+			_, err := ec.DeleteSubnet(ctx, &ec2.DeleteSubnetInput{SubnetId: s.SubnetId})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	rts, err := ec.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{Filters: vpcFilter})
+	if err != nil {
+		return fmt.Errorf("describe route tables: %w", err)
+	}
+	for _, rt := range rts.RouteTables {
+		main := false
+		for _, a := range rt.Associations {
+			if a.Main != nil && *a.Main {
+				main = true
+			}
+		}
+		if main {
+			continue
+		}
+		if _, err := ec.DeleteRouteTable(ctx, &ec2.DeleteRouteTableInput{RouteTableId: rt.RouteTableId}); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("delete route table %s: %w", *rt.RouteTableId, err)
+		}
+	}
+
+	sgs, err := ec.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{Filters: vpcFilter})
+	if err != nil {
+		return fmt.Errorf("describe security groups: %w", err)
+	}
+	for _, sg := range sgs.SecurityGroups {
+		if sg.GroupName != nil && *sg.GroupName == "default" {
+			continue
+		}
+		if err := retryDependency(ctx, "security group "+*sg.GroupId+" deletion", func(ctx context.Context) error {
+			_, err := ec.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{GroupId: sg.GroupId})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	igws, err := ec.DescribeInternetGateways(ctx, &ec2.DescribeInternetGatewaysInput{
+		Filters: []ec2types.Filter{{Name: aws.String("attachment.vpc-id"), Values: []string{vpcID}}},
+	})
+	if err != nil {
+		return fmt.Errorf("describe internet gateways: %w", err)
+	}
+	for _, igw := range igws.InternetGateways {
+		if err := retryDependency(ctx, "internet gateway "+*igw.InternetGatewayId+" detachment", func(ctx context.Context) error {
+			_, err := ec.DetachInternetGateway(ctx, &ec2.DetachInternetGatewayInput{InternetGatewayId: igw.InternetGatewayId, VpcId: aws.String(vpcID)})
+			return err
+		}); err != nil {
+			return err
+		}
+		if _, err := ec.DeleteInternetGateway(ctx, &ec2.DeleteInternetGatewayInput{InternetGatewayId: igw.InternetGatewayId}); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("delete internet gateway %s: %w", *igw.InternetGatewayId, err)
+		}
+	}
+
+	log.Info(ctx, "deleting VPC", "vpc", vpcID)
+	return retryDependency(ctx, "vpc "+vpcID+" deletion", func(ctx context.Context) error {
+		_, err := ec.DeleteVpc(ctx, &ec2.DeleteVpcInput{VpcId: aws.String(vpcID)})
+		return err
+	})
+}
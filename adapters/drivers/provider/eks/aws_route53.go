@@ -0,0 +1,228 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	r53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+const (
+	// Default TTL for DNS records when not specified (5 minutes)
+	defaultDNSRecordTTL = 300
+
+	// Cluster settings key for Route53 hosted zone IDs
+	settingAWSEKSRoute53HostedZoneIDs = "AWS_EKS_ROUTE53_HOSTED_ZONE_IDS"
+)
+
+// route53ZoneInfo represents a Route53 hosted zone.
+type route53ZoneInfo struct {
+	ID   string // Hosted zone ID without the "/hostedzone/" prefix (e.g., "Z0123456789ABC")
+	Name string // Zone name without trailing dot (e.g., "example.com")
+}
+
+// collectDNSZoneIDs retrieves hosted zone IDs from cluster settings.
+// Both "Z0123456789ABC" and "/hostedzone/Z0123456789ABC" forms are accepted.
+func (d *driver) collectDNSZoneIDs(cluster *model.Cluster) []string {
+	raw := clusterSetting(cluster, settingAWSEKSRoute53HostedZoneIDs)
+	if raw == "" {
+		return nil
+	}
+
+	// Split by comma or space
+	var ids []string
+	for _, id := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		id = strings.TrimPrefix(strings.TrimSpace(id), "/hostedzone/")
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// resolveDNSZones looks up the names of the configured hosted zones.
+func (d *driver) resolveDNSZones(ctx context.Context, ids []string) ([]*route53ZoneInfo, error) {
+	c := d.route53Client()
+	zones := make([]*route53ZoneInfo, 0, len(ids))
+	for _, id := range ids {
+		out, err := c.GetHostedZone(ctx, &route53.GetHostedZoneInput{Id: aws.String(id)})
+		if err != nil {
+			return nil, fmt.Errorf("get hosted zone %s: %w", id, err)
+		}
+		zones = append(zones, &route53ZoneInfo{ID: id, Name: strings.TrimSuffix(aws.ToString(out.HostedZone.Name), ".")})
+	}
+	return zones, nil
+}
+
+// selectDNSZone selects the best matching hosted zone for the given FQDN.
+// Priority: 1) ZoneHint (match by ID or name), 2) longest-match heuristic.
+func (d *driver) selectDNSZone(ctx context.Context, fqdn string, zones []*route53ZoneInfo, zoneHint string) (*route53ZoneInfo, error) {
+	log := logging.FromContext(ctx)
+
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("no DNS zones configured in cluster.settings.%s", settingAWSEKSRoute53HostedZoneIDs)
+	}
+
+	// Normalize FQDN: remove trailing dot
+	fqdn = strings.TrimSuffix(fqdn, ".")
+
+	// Step 1: Check ZoneHint (match by ID or name)
+	if zoneHint != "" {
+		hint := strings.TrimPrefix(zoneHint, "/hostedzone/")
+		for _, z := range zones {
+			if z.ID == hint || z.Name == strings.TrimSuffix(hint, ".") {
+				log.Debug(ctx, "DNS zone selected via hint", "fqdn", fqdn, "zone", z.Name, "zone_id", z.ID)
+				return z, nil
+			}
+		}
+		log.Warn(ctx, "DNS zone hint did not match any configured zone", "hint", zoneHint)
+	}
+
+	// Step 2: Longest-match heuristic
+	var bestMatch *route53ZoneInfo
+	bestMatchLen := 0
+	for _, z := range zones {
+		if fqdn == z.Name || strings.HasSuffix(fqdn, "."+z.Name) {
+			if len(z.Name) > bestMatchLen {
+				bestMatch = z
+				bestMatchLen = len(z.Name)
+			}
+		}
+	}
+
+	if bestMatch != nil {
+		log.Debug(ctx, "DNS zone selected via longest-match", "fqdn", fqdn, "zone", bestMatch.Name, "zone_id", bestMatch.ID)
+		return bestMatch, nil
+	}
+
+	return nil, fmt.Errorf("no matching DNS zone found for FQDN %s", fqdn)
+}
+
+// normalizeDNSRecordSet validates and normalizes the input record set.
+// Returns error if validation fails.
+func (d *driver) normalizeDNSRecordSet(rset *model.DNSRecordSet) error {
+	// Validate FQDN
+	if rset.FQDN == "" {
+		return fmt.Errorf("FQDN is required")
+	}
+
+	// Normalize FQDN: remove trailing dot
+	rset.FQDN = strings.TrimSuffix(rset.FQDN, ".")
+
+	// Validate Type
+	switch rset.Type {
+	case model.DNSRecordTypeA, model.DNSRecordTypeAAAA, model.DNSRecordTypeCNAME:
+		// Supported types
+	default:
+		return fmt.Errorf("unsupported DNS record type: %s", rset.Type)
+	}
+
+	// Validate CNAME: must have exactly 1 RData entry
+	if rset.Type == model.DNSRecordTypeCNAME && len(rset.RData) > 1 {
+		return fmt.Errorf("CNAME record must have exactly one RData entry, got %d", len(rset.RData))
+	}
+
+	// Normalize TTL: use default if zero
+	if rset.TTL == 0 {
+		rset.TTL = defaultDNSRecordTTL
+	}
+
+	return nil
+}
+
+// route53RecordName converts a Route53 record name to a FQDN without trailing dot.
+// Route53 returns "*" as the octal escape "\052".
+func route53RecordName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.ReplaceAll(name, `\052`, "*"), "."))
+}
+
+// upsertRoute53Record creates or updates a Route53 record set.
+func (d *driver) upsertRoute53Record(ctx context.Context, zone *route53ZoneInfo, rset model.DNSRecordSet) error {
+	log := logging.FromContext(ctx)
+
+	records := make([]r53types.ResourceRecord, 0, len(rset.RData))
+	for _, v := range rset.RData {
+		records = append(records, r53types.ResourceRecord{Value: aws.String(v)})
+	}
+
+	log.Info(ctx, "upserting Route53 record",
+		"zone_id", zone.ID,
+		"record_name", rset.FQDN,
+		"type", rset.Type,
+		"ttl", rset.TTL,
+		"rdata", rset.RData,
+	)
+
+	_, err := d.route53Client().ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zone.ID),
+		ChangeBatch: &r53types.ChangeBatch{
+			Comment: aws.String("kompox ClusterDNSApply"),
+			Changes: []r53types.Change{{
+				Action: r53types.ChangeActionUpsert,
+				ResourceRecordSet: &r53types.ResourceRecordSet{
+					Name:            aws.String(rset.FQDN + "."),
+					Type:            r53types.RRType(rset.Type),
+					TTL:             aws.Int64(int64(rset.TTL)),
+					ResourceRecords: records,
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("change record sets: %w", err)
+	}
+	return nil
+}
+
+// deleteRoute53Record deletes a Route53 record set. Route53 requires the current values for deletion,
+// so the record set is looked up first; a missing record set is not an error.
+func (d *driver) deleteRoute53Record(ctx context.Context, zone *route53ZoneInfo, rset model.DNSRecordSet) error {
+	log := logging.FromContext(ctx)
+	c := d.route53Client()
+
+	out, err := c.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zone.ID),
+		StartRecordName: aws.String(rset.FQDN + "."),
+		StartRecordType: r53types.RRType(rset.Type),
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil {
+		return fmt.Errorf("list record sets: %w", err)
+	}
+	var current *r53types.ResourceRecordSet
+	for i := range out.ResourceRecordSets {
+		rs := &out.ResourceRecordSets[i]
+		if route53RecordName(aws.ToString(rs.Name)) == strings.ToLower(rset.FQDN) && string(rs.Type) == string(rset.Type) {
+			current = rs
+		}
+	}
+	if current == nil {
+		log.Info(ctx, "Route53 record not found, nothing to delete", "zone_id", zone.ID, "record_name", rset.FQDN, "type", rset.Type)
+		return nil
+	}
+
+	log.Info(ctx, "deleting Route53 record",
+		"zone_id", zone.ID,
+		"record_name", rset.FQDN,
+		"type", rset.Type,
+	)
+
+	_, err = c.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zone.ID),
+		ChangeBatch: &r53types.ChangeBatch{
+			Comment: aws.String("kompox ClusterDNSApply"),
+			Changes: []r53types.Change{{Action: r53types.ChangeActionDelete, ResourceRecordSet: current}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("change record sets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,535 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Ingress identity modes (AWS_EKS_INGRESS_IDENTITY).
+const (
+	ingressIdentityPodIdentity = "pod-identity"
+	ingressIdentityIRSA        = "irsa"
+)
+
+// annotationIRSARoleARN is the ServiceAccount annotation read by the EKS pod identity webhook (IRSA).
+const annotationIRSARoleARN = "eks.amazonaws.com/role-arn"
+
+// defaultStorageClassName is the default StorageClass created by ClusterInstall (gp3 on the EBS CSI driver).
+const defaultStorageClassName = "gp3"
+
+// kubeClient returns a Kubernetes client for the target cluster.
+func (d *driver) kubeClient(ctx context.Context, cluster *model.Cluster) (*kube.Client, error) {
+	c, err := d.activeEKSCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := d.eksRESTConfig(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("get kube REST config: %w", err)
+	}
+	kc, err := kube.NewClientFromRESTConfig(cfg, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("new kube client: %w", err)
+	}
+	return kc, nil
+}
+
+// ClusterProvision provisions an EKS cluster according to the cluster specification.
+// The network, IAM roles, EKS cluster, system/user node groups and add-ons are converged in order.
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
+	defer func() { cleanup(err) }()
+
+	if cluster.Existing {
+		return fmt.Errorf("EKS driver does not support existing clusters: %w", model.ErrNotSupported)
+	}
+
+	name, err := d.eksClusterName(cluster)
+	if err != nil {
+		return fmt.Errorf("derive EKS cluster name: %w", err)
+	}
+	tags := d.clusterResourceTags(cluster.Name)
+
+	// Step 1: Network (VPC, internet gateway, public subnets)
+	net, err := d.ensureNetworkCreated(ctx, name, tags)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: IAM roles for the control plane, the nodes (including ECR pull) and the EBS CSI controller
+	clusterRoleARN, err := d.ensureRole(ctx, d.clusterRoleName(cluster, roleCluster), serviceTrustPolicy("eks.amazonaws.com"),
+		[]string{policyEKSCluster}, "", tags)
+	if err != nil {
+		return err
+	}
+	nodeRoleARN, err := d.ensureRole(ctx, d.clusterRoleName(cluster, roleNode), serviceTrustPolicy("ec2.amazonaws.com"),
+		[]string{policyEKSWorkerNode, policyEKSCNI, policyECRReadOnly}, "", tags)
+	if err != nil {
+		return err
+	}
+	ebsRoleARN, err := d.ensureRole(ctx, d.clusterRoleName(cluster, roleEBSCSI), podIdentityTrustPolicy(),
+		[]string{policyEBSCSIDriver}, "", tags)
+	if err != nil {
+		return err
+	}
+
+	// Step 3: EKS cluster
+	if _, err := d.ensureEKSClusterCreated(ctx, cluster, name, clusterRoleARN, net, tags); err != nil {
+		return err
+	}
+
+	// Step 4: Managed node groups
+	t := &eksTarget{clusterName: name, nodeRoleARN: nodeRoleARN, net: net}
+	for _, mode := range []string{"system", "user"} {
+		existing, err := d.findNodeGroup(ctx, name, mode)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		pool, err := nodePoolFromSettings(cluster, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := d.createNodeGroup(ctx, cluster, t, pool); err != nil {
+			return err
+		}
+	}
+
+	// Step 5: Add-ons (they need nodes to become ACTIVE)
+	return d.ensureAddons(ctx, name, ebsRoleARN, tags)
+}
+
+// ClusterDeprovision deletes the node groups, the EKS cluster, the IAM roles and finally the network.
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterDeprovision")
+	defer func() { cleanup(err) }()
+
+	name, err := d.eksClusterName(cluster)
+	if err != nil {
+		return fmt.Errorf("derive EKS cluster name: %w", err)
+	}
+
+	// The OIDC issuer is only known while the cluster exists
+	issuer := ""
+	if c, err := d.findEKSCluster(ctx, name); err != nil {
+		return err
+	} else if c != nil && c.Identity != nil && c.Identity.Oidc != nil {
+		issuer = aws.ToString(c.Identity.Oidc.Issuer)
+	}
+
+	if err := d.ensureEKSClusterDeleted(ctx, name); err != nil {
+		return err
+	}
+	if issuer != "" {
+		if err := d.deleteOIDCProvider(ctx, issuer); err != nil {
+			return err
+		}
+	}
+	for _, role := range []string{roleIngress, roleEBSCSI, roleNode, roleCluster} {
+		if err := d.deleteRole(ctx, d.clusterRoleName(cluster, role)); err != nil {
+			return err
+		}
+	}
+	return d.ensureNetworkDeleted(ctx, cluster)
+}
+
+// ClusterStatus returns the status of an EKS cluster. A missing or non-ACTIVE EKS cluster is
+// reported as not provisioned and a missing ingress Service as not installed; other errors are returned.
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	status := &model.ClusterStatus{
+		Existing:    cluster.Existing,
+		Provisioned: false,
+		Installed:   false,
+	}
+
+	name, err := d.eksClusterName(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("derive EKS cluster name: %w", err)
+	}
+	c, err := d.findEKSCluster(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.Status != ekstypes.ClusterStatusActive {
+		return status, nil
+	}
+	status.Provisioned = true
+
+	// Retrieve ingress endpoint (load balancer IP/hostname) when installed
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ip, host, err := kc.IngressEndpoint(ctx, cluster)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Ingress controller not installed
+	case err != nil:
+		return nil, fmt.Errorf("get ingress endpoint: %w", err)
+	default:
+		status.Installed = true
+		status.IngressGlobalIP = ip
+		status.IngressFQDN = host
+	}
+	return status, nil
+}
+
+// ClusterInstall installs the Kompox Traefik ingress controller. The ingress ServiceAccount gets an
+// IAM role through EKS Pod Identity (default) or IRSA, granting Route53 record management on the
+// configured hosted zones. The Service of type LoadBalancer is realized as an NLB by the AWS cloud provider.
+// The Spot eviction helper is installed when the cluster configures it and removed otherwise.
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+
+	c, err := d.activeEKSCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
+		log.Warn(ctx, "static ingress certificates are not supported by the EKS driver; ignored", "count", len(cluster.Ingress.Certificates))
+	}
+
+	// Step 1: Ensure ingress namespace exists (idempotent)
+	ns := kube.IngressNamespace(cluster)
+	if err := kc.CreateNamespace(ctx, ns); err != nil {
+		return err
+	}
+
+	// Step 2: IAM role of the ingress ServiceAccount (Pod Identity or IRSA)
+	saName := kube.IngressServiceAccountName(cluster)
+	annotations, err := d.ensureIngressIdentity(ctx, cluster, c, ns, saName)
+	if err != nil {
+		return fmt.Errorf("ensure ingress identity: %w", err)
+	}
+
+	// Step 3: Default StorageClass for dynamically provisioned volumes (Traefik ACME storage)
+	if err := ensureDefaultStorageClass(ctx, kc); err != nil {
+		return err
+	}
+
+	// Step 4: Install Traefik via Helm (idempotent) behind an NLB preserving client source IPs
+	mutator := func(_ context.Context, _ *model.Cluster, _ string, values kube.HelmValues) {
+		svc, _ := values["service"].(map[string]any)
+		if svc == nil {
+			svc = map[string]any{}
+			values["service"] = svc
+		}
+		svc["annotations"] = map[string]any{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"}
+		spec, _ := svc["spec"].(map[string]any)
+		if spec == nil {
+			spec = map[string]any{}
+			svc["spec"] = spec
+		}
+		spec["externalTrafficPolicy"] = "Local"
+	}
+	if err := kc.InstallIngressTraefikBasic(ctx, cluster, annotations, mutator); err != nil {
+		return err
+	}
+
+	// Step 5: Install the Spot eviction helper when configured, otherwise remove it
+	if cluster.SpotHandler != nil {
+		return kc.InstallSpotHandler(ctx, cluster)
+	}
+	return kc.UninstallSpotHandler(ctx, cluster)
+}
+
+// ensureIngressIdentity converges the IAM role of the ingress ServiceAccount and binds it with
+// Pod Identity or IRSA. Returns the ServiceAccount annotations required by the selected mode.
+func (d *driver) ensureIngressIdentity(ctx context.Context, cluster *model.Cluster, c *ekstypes.Cluster, ns, saName string) (map[string]string, error) {
+	tags := d.clusterResourceTags(cluster.Name)
+	roleName := d.clusterRoleName(cluster, roleIngress)
+
+	policy := ""
+	if ids := d.collectDNSZoneIDs(cluster); len(ids) > 0 {
+		policy = d.route53Policy(ids)
+	}
+
+	mode := strings.ToLower(clusterSetting(cluster, keyIngressIdentity))
+	switch mode {
+	case "", ingressIdentityPodIdentity:
+		roleARN, err := d.ensureRole(ctx, roleName, podIdentityTrustPolicy(), nil, policy, tags)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.ensurePodIdentityAssociation(ctx, aws.ToString(c.Name), ns, saName, roleARN, tags); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case ingressIdentityIRSA:
+		if c.Identity == nil || c.Identity.Oidc == nil || aws.ToString(c.Identity.Oidc.Issuer) == "" {
+			return nil, fmt.Errorf("EKS cluster %s has no OIDC issuer", aws.ToString(c.Name))
+		}
+		issuer := aws.ToString(c.Identity.Oidc.Issuer)
+		providerARN, err := d.ensureOIDCProvider(ctx, issuer, tags)
+		if err != nil {
+			return nil, err
+		}
+		roleARN, err := d.ensureRole(ctx, roleName, irsaTrustPolicy(providerARN, issuer, ns, saName), nil, policy, tags)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{annotationIRSARoleARN: roleARN}, nil
+	default:
+		return nil, fmt.Errorf("unsupported %s: %s (supported: %s, %s)", keyIngressIdentity, mode, ingressIdentityPodIdentity, ingressIdentityIRSA)
+	}
+}
+
+// ensureDefaultStorageClass creates the gp3 StorageClass as the cluster default unless a default already exists.
+func ensureDefaultStorageClass(ctx context.Context, kc *kube.Client) error {
+	list, err := kc.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list storage classes: %w", err)
+	}
+	for _, sc := range list.Items {
+		if sc.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			return nil
+		}
+	}
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        defaultStorageClassName,
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+		Provisioner:          ebsCSIDriver,
+		Parameters:           map[string]string{"type": "gp3", "csi.storage.k8s.io/fstype": "ext4"},
+		VolumeBindingMode:    &bindingMode,
+		AllowVolumeExpansion: aws.Bool(true),
+	}
+	if _, err := kc.Clientset.StorageV1().StorageClasses().Create(ctx, sc, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create storage class %s: %w", defaultStorageClassName, err)
+	}
+	return nil
+}
+
+// ClusterUninstall uninstalls the Kompox Traefik ingress controller.
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUninstall")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: Uninstall Traefik (idempotent)
+	if err := kc.UninstallIngressTraefik(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 2: Delete the Spot eviction helper including its cluster-scoped RBAC
+	if err := kc.UninstallSpotHandler(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 3: Delete ingress namespace (idempotent)
+	if err := kc.DeleteNamespace(ctx, kube.IngressNamespace(cluster)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClusterKubeconfig returns the kubeconfig of the EKS cluster.
+// The kubeconfig requires the aws CLI as the exec credential plugin.
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	c, err := d.activeEKSCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return d.eksKubeconfig(c)
+}
+
+// ClusterDNSApply applies a DNS record set in Route53 hosted zones.
+// Implements zone resolution via ZoneHint or longest-match heuristics,
+// validates input, and supports DryRun and Strict modes.
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	settings := &model.ClusterDNSApplyOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(settings)
+	}
+
+	log := logging.FromContext(ctx)
+	clusterName := "(nil)"
+	if cluster != nil {
+		clusterName = cluster.Name
+	}
+
+	// Collect hosted zones from cluster settings
+	ids := d.collectDNSZoneIDs(cluster)
+	if len(ids) == 0 {
+		err := fmt.Errorf("no DNS zones configured")
+		if settings.Strict {
+			return err
+		}
+		log.Warn(ctx, "ClusterDNSApply: no DNS zones configured")
+		return nil
+	}
+
+	// Normalize and validate input
+	if err := d.normalizeDNSRecordSet(&rset); err != nil {
+		if settings.Strict {
+			return fmt.Errorf("validate DNS record set: %w", err)
+		}
+		log.Warn(ctx, "ClusterDNSApply: invalid input", "error", err.Error())
+		return nil
+	}
+
+	zones, err := d.resolveDNSZones(ctx, ids)
+	if err != nil {
+		if settings.Strict {
+			return fmt.Errorf("resolve DNS zones: %w", err)
+		}
+		log.Warn(ctx, "ClusterDNSApply: failed to resolve DNS zones", "error", err.Error())
+		return nil
+	}
+
+	// Select hosted zone (ZoneHint or longest-match)
+	zone, err := d.selectDNSZone(ctx, rset.FQDN, zones, settings.ZoneHint)
+	if err != nil {
+		if settings.Strict {
+			return fmt.Errorf("select DNS zone: %w", err)
+		}
+		log.Warn(ctx, "ClusterDNSApply: zone resolution failed", "fqdn", rset.FQDN, "error", err.Error())
+		return nil
+	}
+
+	// DryRun mode: show what would be done
+	if settings.DryRun {
+		action := "create/update"
+		if len(rset.RData) == 0 {
+			action = "delete"
+		}
+		log.Info(ctx, "ClusterDNSApply: dry-run",
+			"action", action,
+			"cluster", clusterName,
+			"zone", zone.Name,
+			"zone_id", zone.ID,
+			"fqdn", rset.FQDN,
+			"type", rset.Type,
+			"ttl", rset.TTL,
+			"rdata", rset.RData,
+			"strict", settings.Strict,
+		)
+		return nil
+	}
+
+	// Apply DNS record (upsert or delete)
+	if len(rset.RData) == 0 {
+		if err := d.deleteRoute53Record(ctx, zone, rset); err != nil {
+			if settings.Strict {
+				return fmt.Errorf("delete DNS record: %w", err)
+			}
+			log.Warn(ctx, "ClusterDNSApply: failed to delete DNS record (best-effort)", "error", err.Error())
+			return nil
+		}
+		log.Info(ctx, "ClusterDNSApply: deleted DNS record", "zone", zone.Name, "fqdn", rset.FQDN, "type", rset.Type)
+	} else {
+		if err := d.upsertRoute53Record(ctx, zone, rset); err != nil {
+			if settings.Strict {
+				return fmt.Errorf("upsert DNS record: %w", err)
+			}
+			log.Warn(ctx, "ClusterDNSApply: failed to upsert DNS record (best-effort)", "error", err.Error())
+			return nil
+		}
+		log.Info(ctx, "ClusterDNSApply: upserted DNS record", "zone", zone.Name, "fqdn", rset.FQDN, "type", rset.Type)
+	}
+
+	return nil
+}
+
+// nodePoolFromSettings builds the spec of the system or user node pool created by ClusterProvision
+// from the AWS_EKS_{SYSTEM,USER}_* cluster settings.
+func nodePoolFromSettings(cluster *model.Cluster, mode string) (model.NodePool, error) {
+	prefix := "AWS_EKS_" + strings.ToUpper(mode) + "_"
+	get := func(k string) string {
+		return clusterSetting(cluster, prefix+k)
+	}
+	getInt := func(k string, def int) (int, error) {
+		v := get(k)
+		if v == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s%s: %q", prefix, k, v)
+		}
+		return n, nil
+	}
+
+	name, m := mode, mode
+	pool := model.NodePool{Name: &name, Mode: &m, Extensions: map[string]any{}}
+
+	instanceType := defaultNodeInstanceType
+	if v := get("INSTANCE_TYPE"); v != "" {
+		instanceType = v
+	}
+	pool.InstanceType = &instanceType
+	disk, err := getInt("DISK_SIZE_GB", defaultNodeDiskSizeGiB)
+	if err != nil {
+		return pool, err
+	}
+	pool.OSDiskSizeGiB = &disk
+	count, err := getInt("COUNT", defaultNodeCount)
+	if err != nil {
+		return pool, err
+	}
+	pool.Autoscaling = &model.NodePoolAutoscaling{Desired: &count}
+	if v := get("ZONES"); v != "" {
+		var zones []string
+		for _, z := range strings.Split(v, ",") {
+			if z = strings.TrimSpace(z); z != "" {
+				zones = append(zones, z)
+			}
+		}
+		pool.Zones = &zones
+	}
+	if mode == "user" {
+		if v, _ := strconv.ParseBool(get("SPOT")); v {
+			spot := "spot"
+			pool.Priority = &spot
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,725 @@
+package eks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeAWS serves all AWS clients of the driver: EKS REST-JSON requests are routed by path,
+// IAM Query requests by API version, EC2 network actions here and other EC2 actions to the
+// EBS fake. EKS clusters report kubeHost as their API server, which is an in-process cluster.
+// Operations listed in fail return the given error code.
+type fakeAWS struct {
+	ebs      *fakeEC2
+	kubeHost string
+
+	mu         sync.Mutex
+	seq        int
+	fail       map[string]string                    // operation -> error code
+	network    map[string]*fakeNetworkResource      // id -> VPC, subnet, route table, gateway, security group
+	roles      map[string]*fakeRole                 // role name -> role
+	oidc       []string                             // OIDC provider ARNs
+	clusters   map[string]map[string]any            // EKS cluster name -> cluster
+	nodegroups map[string]map[string]map[string]any // EKS cluster name -> node group name -> node group
+	addons     map[string]map[string]string         // EKS cluster name -> add-on name -> status
+}
+
+// fakeNetworkResource is an EC2 network resource. attrs holds the filterable attributes.
+type fakeNetworkResource struct {
+	kind  string
+	attrs map[string]string
+	tags  map[string]string
+	main  bool // main route table or default security group created with the VPC
+}
+
+type fakeRole struct {
+	attached []string
+	inline   map[string]string
+}
+
+func newFakeAWS(t *testing.T) *fakeAWS {
+	f := &fakeAWS{
+		ebs:        newFakeEC2(),
+		kubeHost:   "https://" + strings.ToLower(t.Name()) + ".eks.inprocess.test",
+		fail:       map[string]string{},
+		network:    map[string]*fakeNetworkResource{},
+		roles:      map[string]*fakeRole{},
+		clusters:   map[string]map[string]any{},
+		nodegroups: map[string]map[string]map[string]any{},
+		addons:     map[string]map[string]string{},
+	}
+	f.kubeHost = strings.NewReplacer("/", "-", "_", "-").Replace(f.kubeHost)
+	return f
+}
+
+// setFail injects an error code for an operation; an empty code removes the injection.
+func (f *fakeAWS) setFail(op, code string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if code == "" {
+		delete(f.fail, op)
+	} else {
+		f.fail[op] = code
+	}
+}
+
+func (f *fakeAWS) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("%s-%017x", prefix, f.seq)
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/clusters") {
+		f.serveEKS(w, r)
+		return
+	}
+	_ = r.ParseForm()
+	if r.PostForm.Get("Version") == "2010-05-08" {
+		f.serveIAM(w, r.PostForm)
+		return
+	}
+	if !f.serveNetwork(w, r.PostForm) {
+		f.ebs.ServeHTTP(w, r)
+	}
+}
+
+// count returns the number of resources of each kind in the fake.
+func (f *fakeAWS) count(kind string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch kind {
+	case "role":
+		return len(f.roles)
+	case "cluster":
+		return len(f.clusters)
+	}
+	n := 0
+	for _, res := range f.network {
+		if res.kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// --- EC2 network ---
+
+// networkKinds maps Describe actions to the resource kind and the XML element of the result set.
+var networkKinds = map[string][2]string{
+	"DescribeVpcs":             {"vpc", "vpcSet"},
+	"DescribeSubnets":          {"subnet", "subnetSet"},
+	"DescribeRouteTables":      {"routeTable", "routeTableSet"},
+	"DescribeInternetGateways": {"internetGateway", "internetGatewaySet"},
+	"DescribeSecurityGroups":   {"securityGroup", "securityGroupInfo"},
+}
+
+func (res *fakeNetworkResource) xml(id string) string {
+	var b strings.Builder
+	a := res.attrs
+	switch res.kind {
+	case "vpc":
+		fmt.Fprintf(&b, "<vpcId>%s</vpcId><cidrBlock>%s</cidrBlock><state>available</state>", id, a["cidr"])
+	case "subnet":
+		fmt.Fprintf(&b, "<subnetId>%s</subnetId><vpcId>%s</vpcId><availabilityZone>%s</availabilityZone><cidrBlock>%s</cidrBlock>", id, a["vpc-id"], a["availability-zone"], a["cidr"])
+	case "routeTable":
+		fmt.Fprintf(&b, "<routeTableId>%s</routeTableId><vpcId>%s</vpcId>", id, a["vpc-id"])
+		if res.main {
+			b.WriteString("<associationSet><item><main>true</main></item></associationSet>")
+		}
+	case "internetGateway":
+		fmt.Fprintf(&b, "<internetGatewayId>%s</internetGatewayId>", id)
+		if v := a["attachment.vpc-id"]; v != "" {
+			fmt.Fprintf(&b, "<attachmentSet><item><vpcId>%s</vpcId><state>available</state></item></attachmentSet>", v)
+		}
+	case "securityGroup":
+		fmt.Fprintf(&b, "<groupId>%s</groupId><groupName>%s</groupName><vpcId>%s</vpcId>", id, a["group-name"], a["vpc-id"])
+	}
+	b.WriteString("<tagSet>")
+	for k, v := range res.tags {
+		fmt.Fprintf(&b, "<item><key>%s</key><value>%s</value></item>", xmlText(k), xmlText(v))
+	}
+	b.WriteString("</tagSet>")
+	return b.String()
+}
+
+// match reports whether the resource satisfies all filters of the request.
+func (res *fakeNetworkResource) match(form url.Values) bool {
+	for i, name := range indexed(form, "Filter", ".Name") {
+		got := res.attrs[name]
+		if key, ok := strings.CutPrefix(name, "tag:"); ok {
+			got = res.tags[key]
+		}
+		if !slices.Contains(indexed(form, fmt.Sprintf("Filter.%d.Value", i+1), ""), got) {
+			return false
+		}
+	}
+	return true
+}
+
+// serveNetwork handles the EC2 network actions and reports whether the action was one of them.
+func (f *fakeAWS) serveNetwork(w http.ResponseWriter, form url.Values) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	action := form.Get("Action")
+	reply := func(body string) {
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<%sResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>req</requestId>%s</%sResponse>`, action, body, action)
+	}
+	fail := func(code string) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `<Response><Errors><Error><Code>%s</Code><Message>%s</Message></Error></Errors><RequestID>req</RequestID></Response>`, code, code)
+	}
+	create := func(kind, prefix string, attrs map[string]string) (string, *fakeNetworkResource) {
+		id := f.nextID(prefix)
+		res := &fakeNetworkResource{kind: kind, attrs: attrs, tags: formTags(form, "TagSpecification.1.Tag")}
+		f.network[id] = res
+		return id, res
+	}
+	lookup := func(param, kind string) (string, *fakeNetworkResource, bool) {
+		id := form.Get(param)
+		res, ok := f.network[id]
+		if !ok || res.kind != kind {
+			fail("Invalid" + strings.TrimSuffix(param, "Id") + "ID.NotFound")
+			return id, nil, false
+		}
+		return id, res, true
+	}
+
+	if _, ok := networkKinds[action]; !ok && !slices.Contains([]string{
+		"CreateVpc", "ModifyVpcAttribute", "DeleteVpc", "CreateSubnet", "ModifySubnetAttribute", "DeleteSubnet",
+		"CreateRouteTable", "CreateRoute", "AssociateRouteTable", "DeleteRouteTable", "CreateInternetGateway",
+		"AttachInternetGateway", "DetachInternetGateway", "DeleteInternetGateway", "DeleteSecurityGroup",
+	}, action) {
+		return false
+	}
+	if code := f.fail[action]; code != "" {
+		fail(code)
+		return true
+	}
+
+	if kind, ok := networkKinds[action]; ok {
+		var b strings.Builder
+		for _, id := range slices.Sorted(func(yield func(string) bool) {
+			for id := range f.network {
+				if !yield(id) {
+					return
+				}
+			}
+		}) {
+			if res := f.network[id]; res.kind == kind[0] && res.match(form) {
+				b.WriteString("<item>" + res.xml(id) + "</item>")
+			}
+		}
+		reply("<" + kind[1] + ">" + b.String() + "</" + kind[1] + ">")
+		return true
+	}
+
+	switch action {
+	case "CreateVpc":
+		id, res := create("vpc", "vpc", map[string]string{"cidr": form.Get("CidrBlock")})
+		rt := f.nextID("rtb")
+		f.network[rt] = &fakeNetworkResource{kind: "routeTable", attrs: map[string]string{"vpc-id": id}, main: true}
+		sg := f.nextID("sg")
+		f.network[sg] = &fakeNetworkResource{kind: "securityGroup", attrs: map[string]string{"vpc-id": id, "group-name": "default"}, main: true}
+		reply("<vpc>" + res.xml(id) + "</vpc>")
+	case "DeleteVpc":
+		id, _, ok := lookup("VpcId", "vpc")
+		if !ok {
+			return true
+		}
+		for _, res := range f.network {
+			if (res.attrs["vpc-id"] == id && !res.main) || res.attrs["attachment.vpc-id"] == id {
+				fail("DependencyViolation")
+				return true
+			}
+		}
+		for rid, res := range f.network {
+			if res.attrs["vpc-id"] == id {
+				delete(f.network, rid)
+			}
+		}
+		delete(f.network, id)
+		reply("<return>true</return>")
+	case "CreateSubnet":
+		if _, _, ok := lookup("VpcId", "vpc"); !ok {
+			return true
+		}
+		id, res := create("subnet", "subnet", map[string]string{
+			"vpc-id":            form.Get("VpcId"),
+			"availability-zone": form.Get("AvailabilityZone"),
+			"cidr":              form.Get("CidrBlock"),
+		})
+		reply("<subnet>" + res.xml(id) + "</subnet>")
+	case "CreateRouteTable":
+		if _, _, ok := lookup("VpcId", "vpc"); !ok {
+			return true
+		}
+		id, res := create("routeTable", "rtb", map[string]string{"vpc-id": form.Get("VpcId")})
+		reply("<routeTable>" + res.xml(id) + "</routeTable>")
+	case "CreateInternetGateway":
+		id, res := create("internetGateway", "igw", map[string]string{})
+		reply("<internetGateway>" + res.xml(id) + "</internetGateway>")
+	case "AttachInternetGateway":
+		if _, res, ok := lookup("InternetGatewayId", "internetGateway"); ok {
+			res.attrs["attachment.vpc-id"] = form.Get("VpcId")
+			reply("<return>true</return>")
+		}
+	case "DetachInternetGateway":
+		if _, res, ok := lookup("InternetGatewayId", "internetGateway"); ok {
+			delete(res.attrs, "attachment.vpc-id")
+			reply("<return>true</return>")
+		}
+	case "DeleteInternetGateway":
+		if id, res, ok := lookup("InternetGatewayId", "internetGateway"); ok {
+			if res.attrs["attachment.vpc-id"] != "" {
+				fail("DependencyViolation")
+				return true
+			}
+			delete(f.network, id)
+			reply("<return>true</return>")
+		}
+	case "DeleteSubnet":
+		if id, _, ok := lookup("SubnetId", "subnet"); ok {
+			delete(f.network, id)
+			reply("<return>true</return>")
+		}
+	case "DeleteRouteTable":
+		if id, res, ok := lookup("RouteTableId", "routeTable"); ok {
+			if res.main {
+				fail("DependencyViolation")
+				return true
+			}
+			delete(f.network, id)
+			reply("<return>true</return>")
+		}
+	case "DeleteSecurityGroup":
+		if id, _, ok := lookup("GroupId", "securityGroup"); ok {
+			delete(f.network, id)
+			reply("<return>true</return>")
+		}
+	case "AssociateRouteTable":
+		reply("<associationId>" + f.nextID("rtbassoc") + "</associationId>")
+	default: // ModifyVpcAttribute, ModifySubnetAttribute, CreateRoute
+		reply("<return>true</return>")
+	}
+	return true
+}
+
+// --- IAM ---
+
+func roleXML(name string) string {
+	return fmt.Sprintf("<Role><Path>/</Path><RoleName>%s</RoleName><RoleId>AROA%s</RoleId><Arn>arn:aws:iam::123456789012:role/%s</Arn><CreateDate>2026-01-01T00:00:00Z</CreateDate></Role>", name, strings.ToUpper(name), name)
+}
+
+func (f *fakeAWS) serveIAM(w http.ResponseWriter, form url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	action := form.Get("Action")
+	reply := func(result string) {
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<%sResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><%sResult>%s</%sResult><ResponseMetadata><RequestId>req</RequestId></ResponseMetadata></%sResponse>`, action, action, result, action, action)
+	}
+	fail := func(code string) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `<ErrorResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><Error><Type>Sender</Type><Code>%s</Code><Message>%s</Message></Error><RequestId>req</RequestId></ErrorResponse>`, code, code)
+	}
+	if code := f.fail[action]; code != "" {
+		fail(code)
+		return
+	}
+
+	name := form.Get("RoleName")
+	role := f.roles[name]
+	if role == nil && name != "" && action != "CreateRole" {
+		fail("NoSuchEntity")
+		return
+	}
+	switch action {
+	case "GetRole":
+		reply(roleXML(name))
+	case "CreateRole":
+		if role != nil {
+			fail("EntityAlreadyExists")
+			return
+		}
+		f.roles[name] = &fakeRole{inline: map[string]string{}}
+		reply(roleXML(name))
+	case "AttachRolePolicy":
+		if arn := form.Get("PolicyArn"); !slices.Contains(role.attached, arn) {
+			role.attached = append(role.attached, arn)
+		}
+		reply("")
+	case "DetachRolePolicy":
+		role.attached = slices.DeleteFunc(role.attached, func(arn string) bool { return arn == form.Get("PolicyArn") })
+		reply("")
+	case "ListAttachedRolePolicies":
+		var b strings.Builder
+		for _, arn := range role.attached {
+			fmt.Fprintf(&b, "<member><PolicyArn>%s</PolicyArn><PolicyName>%s</PolicyName></member>", arn, arn[strings.LastIndex(arn, "/")+1:])
+		}
+		reply("<AttachedPolicies>" + b.String() + "</AttachedPolicies><IsTruncated>false</IsTruncated>")
+	case "PutRolePolicy":
+		role.inline[form.Get("PolicyName")] = form.Get("PolicyDocument")
+		reply("")
+	case "DeleteRolePolicy":
+		if _, ok := role.inline[form.Get("PolicyName")]; !ok {
+			fail("NoSuchEntity")
+			return
+		}
+		delete(role.inline, form.Get("PolicyName"))
+		reply("")
+	case "ListRolePolicies":
+		var b strings.Builder
+		for p := range role.inline {
+			fmt.Fprintf(&b, "<member>%s</member>", p)
+		}
+		reply("<PolicyNames>" + b.String() + "</PolicyNames><IsTruncated>false</IsTruncated>")
+	case "DeleteRole":
+		if len(role.attached) > 0 || len(role.inline) > 0 {
+			fail("DeleteConflict")
+			return
+		}
+		delete(f.roles, name)
+		reply("")
+	case "UpdateAssumeRolePolicy":
+		reply("")
+	case "ListOpenIDConnectProviders":
+		var b strings.Builder
+		for _, arn := range f.oidc {
+			fmt.Fprintf(&b, "<member><Arn>%s</Arn></member>", arn)
+		}
+		reply("<OpenIDConnectProviderList>" + b.String() + "</OpenIDConnectProviderList>")
+	case "CreateOpenIDConnectProvider":
+		arn := "arn:aws:iam::123456789012:oidc-provider/" + strings.TrimPrefix(form.Get("Url"), "https://")
+		f.oidc = append(f.oidc, arn)
+		reply("<OpenIDConnectProviderArn>" + arn + "</OpenIDConnectProviderArn>")
+	case "DeleteOpenIDConnectProvider":
+		f.oidc = slices.DeleteFunc(f.oidc, func(arn string) bool { return arn == form.Get("OpenIDConnectProviderArn") })
+		reply("")
+	default:
+		fail("InvalidAction")
+	}
+}
+
+// --- EKS ---
+
+// eksOperation returns the EKS operation of a REST request or "".
+func eksOperation(method string, parts []string) string {
+	switch {
+	case len(parts) == 1 && method == http.MethodPost:
+		return "CreateCluster"
+	case len(parts) == 2 && method == http.MethodGet:
+		return "DescribeCluster"
+	case len(parts) == 2 && method == http.MethodDelete:
+		return "DeleteCluster"
+	case len(parts) == 3 && parts[2] == "node-groups" && method == http.MethodGet:
+		return "ListNodegroups"
+	case len(parts) == 3 && parts[2] == "node-groups" && method == http.MethodPost:
+		return "CreateNodegroup"
+	case len(parts) == 4 && parts[2] == "node-groups" && method == http.MethodGet:
+		return "DescribeNodegroup"
+	case len(parts) == 4 && parts[2] == "node-groups" && method == http.MethodDelete:
+		return "DeleteNodegroup"
+	case len(parts) == 5 && parts[2] == "node-groups" && parts[4] == "update-config":
+		return "UpdateNodegroupConfig"
+	case len(parts) == 3 && parts[2] == "addons" && method == http.MethodPost:
+		return "CreateAddon"
+	case len(parts) == 4 && parts[2] == "addons" && method == http.MethodGet:
+		return "DescribeAddon"
+	}
+	return ""
+}
+
+func (f *fakeAWS) serveEKS(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := range parts {
+		parts[i], _ = url.PathUnescape(parts[i])
+	}
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	reply := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	fail := func(status int, code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Amzn-Errortype", code)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": code})
+	}
+
+	op := eksOperation(r.Method, parts)
+	if op == "" {
+		fail(http.StatusBadRequest, "InvalidRequestException")
+		return
+	}
+	if code := f.fail[op]; code != "" {
+		fail(http.StatusBadRequest, code)
+		return
+	}
+	name := ""
+	if len(parts) > 1 {
+		name = parts[1]
+	}
+	if op != "CreateCluster" && f.clusters[name] == nil {
+		fail(http.StatusNotFound, "ResourceNotFoundException")
+		return
+	}
+
+	switch op {
+	case "CreateCluster":
+		name, _ = body["name"].(string)
+		if f.clusters[name] != nil {
+			fail(http.StatusConflict, "ResourceInUseException")
+			return
+		}
+		c := map[string]any{
+			"name":                 name,
+			"arn":                  "arn:aws:eks:us-east-1:123456789012:cluster/" + name,
+			"status":               "CREATING",
+			"endpoint":             f.kubeHost,
+			"roleArn":              body["roleArn"],
+			"resourcesVpcConfig":   body["resourcesVpcConfig"],
+			"certificateAuthority": map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("fake-ca"))},
+			"identity":             map[string]any{"oidc": map[string]any{"issuer": "https://oidc.eks.us-east-1.amazonaws.com/id/" + name}},
+			"tags":                 body["tags"],
+		}
+		f.clusters[name] = c
+		f.nodegroups[name] = map[string]map[string]any{}
+		f.addons[name] = map[string]string{}
+		reply(map[string]any{"cluster": c})
+	case "DescribeCluster":
+		c := f.clusters[name]
+		reply(map[string]any{"cluster": c})
+		switch c["status"] {
+		case "CREATING":
+			c["status"] = "ACTIVE"
+		case "DELETING":
+			delete(f.clusters, name)
+			delete(f.nodegroups, name)
+			delete(f.addons, name)
+		}
+	case "DeleteCluster":
+		if len(f.nodegroups[name]) > 0 {
+			fail(http.StatusConflict, "ResourceInUseException")
+			return
+		}
+		f.clusters[name]["status"] = "DELETING"
+		reply(map[string]any{"cluster": f.clusters[name]})
+	case "ListNodegroups":
+		reply(map[string]any{"nodegroups": slices.Sorted(func(yield func(string) bool) {
+			for ng := range f.nodegroups[name] {
+				if !yield(ng) {
+					return
+				}
+			}
+		})})
+	case "CreateNodegroup":
+		ngName, _ := body["nodegroupName"].(string)
+		if f.nodegroups[name][ngName] != nil {
+			fail(http.StatusConflict, "ResourceInUseException")
+			return
+		}
+		delete(body, "clientRequestToken")
+		body["clusterName"] = name
+		body["nodegroupArn"] = "arn:aws:eks:us-east-1:123456789012:nodegroup/" + name + "/" + ngName
+		body["status"] = "CREATING"
+		f.nodegroups[name][ngName] = body
+		reply(map[string]any{"nodegroup": body})
+	case "DescribeNodegroup":
+		ng := f.nodegroups[name][parts[3]]
+		if ng == nil {
+			fail(http.StatusNotFound, "ResourceNotFoundException")
+			return
+		}
+		reply(map[string]any{"nodegroup": ng})
+		switch ng["status"] {
+		case "CREATING", "UPDATING":
+			ng["status"] = "ACTIVE"
+		case "DELETING":
+			delete(f.nodegroups[name], parts[3])
+		}
+	case "DeleteNodegroup":
+		ng := f.nodegroups[name][parts[3]]
+		if ng == nil {
+			fail(http.StatusNotFound, "ResourceNotFoundException")
+			return
+		}
+		ng["status"] = "DELETING"
+		reply(map[string]any{"nodegroup": ng})
+	case "UpdateNodegroupConfig":
+		ng := f.nodegroups[name][parts[3]]
+		if ng == nil {
+			fail(http.StatusNotFound, "ResourceNotFoundException")
+			return
+		}
+		if sc, ok := body["scalingConfig"]; ok {
+			ng["scalingConfig"] = sc
+		}
+		if l, ok := body["labels"].(map[string]any); ok {
+			labels, _ := ng["labels"].(map[string]any)
+			if labels == nil {
+				labels = map[string]any{}
+			}
+			if add, ok := l["addOrUpdateLabels"].(map[string]any); ok {
+				for k, v := range add {
+					labels[k] = v
+				}
+			}
+			if remove, ok := l["removeLabels"].([]any); ok {
+				for _, k := range remove {
+					delete(labels, k.(string))
+				}
+			}
+			ng["labels"] = labels
+		}
+		ng["status"] = "UPDATING"
+		reply(map[string]any{"update": map[string]any{"id": f.nextID("update"), "status": "InProgress"}})
+	case "CreateAddon":
+		addon, _ := body["addonName"].(string)
+		f.addons[name][addon] = "CREATING"
+		reply(map[string]any{"addon": map[string]any{"addonName": addon, "clusterName": name, "status": "CREATING"}})
+	case "DescribeAddon":
+		status, ok := f.addons[name][parts[3]]
+		if !ok {
+			fail(http.StatusNotFound, "ResourceNotFoundException")
+			return
+		}
+		reply(map[string]any{"addon": map[string]any{"addonName": parts[3], "clusterName": name, "status": status}})
+		f.addons[name][parts[3]] = "ACTIVE"
+	}
+}
+
+// newClusterTestDriver returns a driver backed by a fake AWS and the in-process cluster the
+// fake EKS clusters point at. The in-process cluster serves objects.
+func newClusterTestDriver(t *testing.T, objects *[]runtime.Object) (*driver, *fakeAWS) {
+	t.Helper()
+	f := newFakeAWS(t)
+	kube.RegisterInProcessCluster(f.kubeHost, func() (*kube.InProcessOptions, error) {
+		return &kube.InProcessOptions{Objects: *objects}, nil
+	})
+	t.Cleanup(func() { kube.UnregisterInProcessCluster(f.kubeHost) })
+	return newTestDriver(t, f), f
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	ctx := context.Background()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls", Settings: map[string]string{"AWS_EKS_USER_ZONES": "2"}}
+
+	status, err := d.ClusterStatus(ctx, cluster)
+	if err != nil || status.Provisioned {
+		t.Fatalf("ClusterStatus before provision = %+v, %v; want not provisioned", status, err)
+	}
+
+	for i := range 2 {
+		if err := d.ClusterProvision(ctx, cluster); err != nil {
+			t.Fatalf("ClusterProvision #%d: %v", i+1, err)
+		}
+	}
+	for kind, want := range map[string]int{"vpc": 1, "subnet": 3, "internetGateway": 1, "routeTable": 2, "role": 3, "cluster": 1} {
+		if got := f.count(kind); got != want {
+			t.Errorf("%s count after provision = %d, want %d", kind, got, want)
+		}
+	}
+	pools, err := d.NodePoolList(ctx, cluster)
+	if err != nil {
+		t.Fatalf("NodePoolList: %v", err)
+	}
+	if len(pools) != 2 || *pools[0].Name != "system" || *pools[1].Name != "user" {
+		t.Fatalf("NodePoolList = %d pools, want system and user", len(pools))
+	}
+	if z := pools[1].Zones; z == nil || len(*z) != 1 || (*z)[0] != "us-east-1b" {
+		t.Errorf("user pool zones = %v, want [us-east-1b]", z)
+	}
+
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || !status.Provisioned || status.Installed {
+		t.Fatalf("ClusterStatus after provision = %+v, %v; want provisioned and not installed", status, err)
+	}
+	objects = []runtime.Object{&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: kube.IngressServiceName(cluster), Namespace: kube.IngressNamespace(cluster)},
+		Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{Hostname: "nlb.example.com"}}}},
+	}}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || !status.Installed || status.IngressFQDN != "nlb.example.com" {
+		t.Fatalf("ClusterStatus with ingress = %+v, %v; want installed", status, err)
+	}
+
+	for i := range 2 {
+		if err := d.ClusterDeprovision(ctx, cluster); err != nil {
+			t.Fatalf("ClusterDeprovision #%d: %v", i+1, err)
+		}
+	}
+	for _, kind := range []string{"vpc", "subnet", "internetGateway", "routeTable", "securityGroup", "role", "cluster"} {
+		if got := f.count(kind); got != 0 {
+			t.Errorf("%s count after deprovision = %d, want 0", kind, got)
+		}
+	}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || status.Provisioned {
+		t.Errorf("ClusterStatus after deprovision = %+v, %v; want not provisioned", status, err)
+	}
+}
+
+func TestClusterErrors(t *testing.T) {
+	ctx := context.Background()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls"}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		op   string
+		call func() error
+	}{
+		{"status describe cluster", "DescribeCluster", func() error { _, err := d.ClusterStatus(ctx, cluster); return err }},
+		{"provision create subnet", "DescribeSubnets", func() error { return d.ClusterProvision(ctx, cluster) }},
+		{"provision get role", "GetRole", func() error { return d.ClusterProvision(ctx, cluster) }},
+		{"provision describe add-on", "DescribeAddon", func() error { return d.ClusterProvision(ctx, cluster) }},
+		{"deprovision delete node group", "DeleteNodegroup", func() error { return d.ClusterDeprovision(ctx, cluster) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.setFail(tt.op, "AccessDeniedException")
+			defer f.setFail(tt.op, "")
+			if err := tt.call(); err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+				t.Errorf("got %v, want the %s error", err, tt.op)
+			}
+		})
+	}
+
+	// Kubernetes API errors other than NotFound are returned
+	kube.RegisterInProcessCluster(f.kubeHost, func() (*kube.InProcessOptions, error) {
+		return nil, errors.New("api unavailable")
+	})
+	if _, err := d.ClusterStatus(ctx, cluster); err == nil || !strings.Contains(err.Error(), "api unavailable") {
+		t.Errorf("ClusterStatus with a failing API server = %v, want error", err)
+	}
+}
@@ -0,0 +1,120 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// driver implements the EKS (Amazon Elastic Kubernetes Service) provider driver.
+// Cloud resources are converged by ensure*() functions using the AWS SDK for Go v2 and
+// are discovered again through the Kompox tags instead of a separate state store.
+type driver struct {
+	workspaceName  string
+	providerName   string
+	resourcePrefix string
+	awsConfig      aws.Config               // shared AWS SDK configuration (region, credentials, endpoint override)
+	region         string                   // AWS region (e.g. ap-northeast-1)
+	profile        string                   // shared config profile passed to the kubeconfig exec plugin
+	volumeBackends map[string]volumeBackend // volume type -> volumeBackend
+}
+
+// ID returns the provider identifier.
+func (d *driver) ID() string { return "eks" }
+
+// WorkspaceName returns the workspace name associated with this driver instance.
+func (d *driver) WorkspaceName() string { return d.workspaceName }
+
+// ProviderName returns the provider name associated with this driver instance.
+func (d *driver) ProviderName() string { return d.providerName }
+
+// Capabilities returns the operations supported by the eks driver.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Provision: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk: {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true, Update: true},
+		},
+		NodePool: model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true},
+		DNS:      true,
+	}
+}
+
+// init registers the EKS driver.
+func init() {
+	providerdrv.Register("eks", func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+		// Determine WorkspaceName
+		workspaceName := "(nil)"
+		if workspace != nil {
+			workspaceName = workspace.Name
+		}
+
+		settings := provider.Settings
+		get := func(k string) string {
+			if settings == nil {
+				return ""
+			}
+			return strings.TrimSpace(settings[k])
+		}
+
+		region := strings.ToLower(get(keyRegion))
+		if region == "" {
+			return nil, fmt.Errorf("missing required EKS settings: %s", keyRegion)
+		}
+
+		loadOpts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+		profile := get(keyProfile)
+		if profile != "" {
+			loadOpts = append(loadOpts, config.WithSharedConfigProfile(profile))
+		}
+		accessKey, secretKey := get(keyAccessKeyID), get(keySecretAccessKey)
+		if (accessKey == "") != (secretKey == "") {
+			return nil, fmt.Errorf("%s and %s must be specified together", keyAccessKeyID, keySecretAccessKey)
+		}
+		if accessKey != "" {
+			loadOpts = append(loadOpts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, get(keySessionToken))))
+		}
+		cfg, err := config.LoadDefaultConfig(context.Background(), loadOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("load AWS config: %w", err)
+		}
+		if v := get(keyEndpointURL); v != "" {
+			// Local AWS API stand-ins (e.g. LocalStack) serve every service on a single endpoint
+			cfg.BaseEndpoint = aws.String(v)
+		}
+
+		prefix := get(keyResourcePrefix)
+		if prefix == "" {
+			h := naming.NewHashes(workspaceName, provider.Name, "", "")
+			// Default AWS resource prefix aligns with the AKS driver: k4x-<spHASH>
+			prefix = fmt.Sprintf("k4x-%s", h.Provider)
+		}
+		if len(prefix) > maxResourcePrefix {
+			prefix = prefix[:maxResourcePrefix]
+		}
+
+		d := &driver{
+			workspaceName:  workspaceName,
+			providerName:   provider.Name,
+			resourcePrefix: prefix,
+			awsConfig:      cfg,
+			region:         region,
+			profile:        profile,
+		}
+
+		// Initialize volume backends for each type
+		d.volumeBackends = map[string]volumeBackend{
+			model.VolumeTypeDisk: newVolumeBackendDisk(d),
+		}
+
+		return d, nil
+	})
+}
@@ -0,0 +1,43 @@
+package eks
+
+import (
+	"context"
+	"time"
+
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// withMethodLogger implements the Span pattern for EKS driver logging.
+// It emits a start log line and returns a context with logger attributes attached,
+// plus a cleanup function to emit the success or failure log line.
+//
+// Log message format:
+// - Start:   EKS:<method>/S (with driver in logger attributes)
+// - Success: EKS:<method>/EOK (with err, elapsed in logger attributes)
+// - Failure: EKS:<method>/EFAIL (with err, elapsed in logger attributes)
+//
+// See design/v1/Kompox-Logging.ja.md for the full Span pattern specification.
+func (d *driver) withMethodLogger(ctx context.Context, method string) (context.Context, func(err error)) {
+	startAt := time.Now()
+
+	logger := logging.FromContext(ctx).With("driver", "EKS."+method)
+	ctx = logging.WithLogger(ctx, logger)
+
+	logger.Info(ctx, "EKS:"+method+"/S")
+
+	cleanup := func(err error) {
+		elapsed := time.Since(startAt).Seconds()
+		msg := "EKS:" + method + "/EOK"
+		errStr := ""
+		if err != nil {
+			msg = "EKS:" + method + "/EFAIL"
+			errStr = err.Error()
+			if len(errStr) > 32 {
+				errStr = errStr[:32] + "..."
+			}
+		}
+		logger.Info(ctx, msg, "err", errStr, "elapsed", elapsed)
+	}
+
+	return ctx, cleanup
+}
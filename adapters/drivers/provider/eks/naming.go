@@ -0,0 +1,171 @@
+package eks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// Resource tag names (same keys as the AKS driver)
+const (
+	tagWorkspaceName = "kompox-workspace-name"
+	tagProviderName  = "kompox-provider-name"
+	tagClusterName   = "kompox-cluster-name"
+	tagClusterHash   = "kompox-cluster-hash"
+	tagAppName       = "kompox-app-name"
+	tagAppIDHash     = "kompox-app-id-hash"
+	tagVolumeName    = "kompox-volume"         // volume name
+	tagDiskName      = "kompox-disk-name"      // disk name (CompactID)
+	tagDiskAssigned  = "kompox-disk-assigned"  // true/false
+	tagSnapshotName  = "kompox-snapshot-name"  // snapshot name (CompactID)
+	tagLabelPrefix   = "kompox-label-"         // user label prefix (kompox-label-<key>)
+	tagDescription   = "kompox-description"    // user description
+	tagNodePoolMode  = "kompox-node-pool-mode" // system/user
+	tagName          = "Name"                  // AWS console display name
+)
+
+// Provider setting keys.
+const (
+	keyRegion          = "AWS_REGION"
+	keyProfile         = "AWS_PROFILE"
+	keyAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	keySecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	keySessionToken    = "AWS_SESSION_TOKEN"
+	keyEndpointURL     = "AWS_ENDPOINT_URL"
+	keyResourcePrefix  = "AWS_RESOURCE_PREFIX"
+)
+
+// Cluster setting keys.
+const (
+	keyClusterName       = "AWS_EKS_CLUSTER_NAME"
+	keyKubernetesVersion = "AWS_EKS_KUBERNETES_VERSION"
+	keyIngressIdentity   = "AWS_EKS_INGRESS_IDENTITY" // pod-identity (default) or irsa
+)
+
+// Resource name limits
+const (
+	maxResourcePrefix = 32
+	maxClusterName    = 100
+	maxRoleName       = 64
+	maxVolumeName     = 16
+	maxDiskName       = 24
+	maxSnapshotName   = 24
+)
+
+// safeTruncate ensures resulting name does not exceed max characters, preserving hash suffix.
+// Returns an error if the hash is too long to accommodate any base characters.
+func safeTruncate(base, hash string, max int) (string, error) {
+	maxBaseLen := max - (len(hash) + 1)
+	if maxBaseLen < 1 {
+		return "", fmt.Errorf("hash too long: %d chars exceeds limit", len(hash))
+	}
+	if len(base) > maxBaseLen {
+		base = base[:maxBaseLen]
+	}
+	return fmt.Sprintf("%s-%s", base, hash), nil
+}
+
+// clusterResourceTags generates tags for cluster-scoped AWS resources.
+func (d *driver) clusterResourceTags(clusterName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), clusterName, "")
+	return map[string]string{
+		tagWorkspaceName: d.WorkspaceName(),
+		tagProviderName:  d.ProviderName(),
+		tagClusterName:   clusterName,
+		tagClusterHash:   h.Cluster,
+		"managed-by":     "kompox",
+	}
+}
+
+// appResourceTags generates tags for app-scoped AWS resources.
+func (d *driver) appResourceTags(appName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", appName)
+	return map[string]string{
+		tagWorkspaceName: d.WorkspaceName(),
+		tagProviderName:  d.ProviderName(),
+		tagAppName:       appName,
+		tagAppIDHash:     h.AppID,
+		"managed-by":     "kompox",
+	}
+}
+
+// eksClusterName returns the EKS cluster name. AWS_EKS_CLUSTER_NAME overrides the derived name.
+func (d *driver) eksClusterName(cluster *model.Cluster) (string, error) {
+	if cluster == nil {
+		return "", fmt.Errorf("cluster nil")
+	}
+	if cluster.Settings != nil {
+		if v := strings.TrimSpace(cluster.Settings[keyClusterName]); v != "" {
+			return v, nil
+		}
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "")
+	base := fmt.Sprintf("%s-%s", d.resourcePrefix, cluster.Name)
+	result, err := safeTruncate(base, h.Cluster, maxClusterName)
+	if err != nil {
+		return "", fmt.Errorf("EKS cluster name: %w", err)
+	}
+	return result, nil
+}
+
+// clusterRoleName returns the name of a cluster-scoped IAM role (cluster, node, ebs-csi, ingress).
+// The cluster hash keeps names unique and within the 64 character IAM limit.
+func (d *driver) clusterRoleName(cluster *model.Cluster, role string) string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "")
+	name := fmt.Sprintf("%s-%s-%s", d.resourcePrefix, h.Cluster, role)
+	if len(name) > maxRoleName {
+		name = name[:maxRoleName]
+	}
+	return name
+}
+
+func (d *driver) appDiskName(app *model.App, volName string, diskName string) (string, error) {
+	if len(volName) > maxVolumeName {
+		return "", fmt.Errorf("volume name %q exceeds max length %d", volName, maxVolumeName)
+	}
+	if len(diskName) > maxDiskName {
+		return "", fmt.Errorf("disk name %q exceeds max length %d", diskName, maxDiskName)
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	return fmt.Sprintf("%s-disk-%s-%s-%s", d.resourcePrefix, volName, diskName, h.AppID), nil
+}
+
+func (d *driver) appSnapshotName(app *model.App, volName string, snapshotName string) (string, error) {
+	if len(volName) > maxVolumeName {
+		return "", fmt.Errorf("volume name %q exceeds max length %d", volName, maxVolumeName)
+	}
+	if len(snapshotName) > maxSnapshotName {
+		return "", fmt.Errorf("snapshot name %q exceeds max length %d", snapshotName, maxSnapshotName)
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	return fmt.Sprintf("%s-snap-%s-%s-%s", d.resourcePrefix, volName, snapshotName, h.AppID), nil
+}
+
+// resolveAZ resolves a Kompox zone to one of the availability zone names of the region.
+// Accepted forms: the AZ name "ap-northeast-1a", the AZ letter "a" and the
+// AKS style index "1" (mapped to the letter "a") so that zones of existing app definitions keep working.
+func resolveAZ(zone string, azs []string) (string, error) {
+	zone = strings.ToLower(strings.TrimSpace(zone))
+	if zone == "" {
+		return "", fmt.Errorf("zone is empty")
+	}
+	suffix := ""
+	switch {
+	case len(zone) == 1 && zone[0] >= 'a' && zone[0] <= 'z':
+		suffix = zone
+	case len(zone) == 1 && zone[0] >= '1' && zone[0] <= '9':
+		suffix = string(rune('a' + zone[0] - '1'))
+	}
+	for _, az := range azs {
+		if az == zone {
+			return az, nil
+		}
+		// Regional AZ names are the region name followed by a single letter (ap-northeast-1a)
+		if suffix != "" && len(az) > 2 && az[len(az)-1:] == suffix && az[len(az)-2] >= '0' && az[len(az)-2] <= '9' {
+			return az, nil
+		}
+	}
+	return "", fmt.Errorf("availability zone %q not found (available: %s)", zone, strings.Join(azs, ", "))
+}
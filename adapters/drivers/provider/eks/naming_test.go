@@ -0,0 +1,109 @@
+package eks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestResolveAZ(t *testing.T) {
+	azs := []string{"ap-northeast-1a", "ap-northeast-1c", "ap-northeast-1d"}
+	tests := []struct {
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{zone: "ap-northeast-1c", want: "ap-northeast-1c"},
+		{zone: "AP-NORTHEAST-1D", want: "ap-northeast-1d"},
+		{zone: "a", want: "ap-northeast-1a"},
+		{zone: "d", want: "ap-northeast-1d"},
+		{zone: "1", want: "ap-northeast-1a"},
+		{zone: "3", want: "ap-northeast-1c"},
+		{zone: "b", wantErr: true},
+		{zone: "ap-northeast-1b", wantErr: true},
+		{zone: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveAZ(tt.zone, azs)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveAZ(%q) expected error, got %q", tt.zone, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveAZ(%q) unexpected error: %v", tt.zone, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveAZ(%q) = %q, want %q", tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestEKSClusterName(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", resourcePrefix: "k4x-abc"}
+
+	name, err := d.eksClusterName(&model.Cluster{Name: "cls1"})
+	if err != nil {
+		t.Fatalf("eksClusterName: %v", err)
+	}
+	if !strings.HasPrefix(name, "k4x-abc-cls1-") {
+		t.Errorf("unexpected cluster name %q", name)
+	}
+
+	override, err := d.eksClusterName(&model.Cluster{Name: "cls1", Settings: map[string]string{keyClusterName: " custom "}})
+	if err != nil {
+		t.Fatalf("eksClusterName override: %v", err)
+	}
+	if override != "custom" {
+		t.Errorf("override = %q, want %q", override, "custom")
+	}
+
+	long, err := d.eksClusterName(&model.Cluster{Name: strings.Repeat("x", 200)})
+	if err != nil {
+		t.Fatalf("eksClusterName long: %v", err)
+	}
+	if len(long) > maxClusterName {
+		t.Errorf("cluster name length %d exceeds %d", len(long), maxClusterName)
+	}
+}
+
+func TestClusterRoleName(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", resourcePrefix: strings.Repeat("p", maxResourcePrefix)}
+	cluster := &model.Cluster{Name: "cls1"}
+
+	node := d.clusterRoleName(cluster, roleNode)
+	ingress := d.clusterRoleName(cluster, roleIngress)
+	if node == ingress {
+		t.Errorf("role names must differ: %q", node)
+	}
+	for _, n := range []string{node, ingress} {
+		if len(n) > maxRoleName {
+			t.Errorf("role name %q exceeds %d chars", n, maxRoleName)
+		}
+	}
+	if !strings.HasSuffix(node, "-"+roleNode) {
+		t.Errorf("unexpected node role name %q", node)
+	}
+}
+
+func TestAppDiskNameLimits(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", resourcePrefix: "k4x-abc"}
+	app := &model.App{Name: "app1"}
+
+	if _, err := d.appDiskName(app, strings.Repeat("v", maxVolumeName+1), "d"); err == nil {
+		t.Error("expected error for long volume name")
+	}
+	if _, err := d.appSnapshotName(app, "vol", strings.Repeat("s", maxSnapshotName+1)); err == nil {
+		t.Error("expected error for long snapshot name")
+	}
+	name, err := d.appDiskName(app, "vol", "disk1")
+	if err != nil {
+		t.Fatalf("appDiskName: %v", err)
+	}
+	if !strings.HasPrefix(name, "k4x-abc-disk-vol-disk1-") {
+		t.Errorf("unexpected disk name %q", name)
+	}
+}
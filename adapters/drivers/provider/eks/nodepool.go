@@ -0,0 +1,537 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Kompox label keys for node pools.
+const (
+	labelNodePool = "kompox.dev/node-pool"
+	labelNodeZone = "kompox.dev/node-zone"
+)
+
+// Node pool defaults used when neither the pool spec nor the cluster settings specify a value.
+const (
+	defaultNodeInstanceType = "t3.large"
+	defaultNodeDiskSizeGiB  = 50
+	defaultNodeCount        = 1
+)
+
+// gravitonFamily matches instance families of AWS Graviton (arm64) processors such as m7g, c6gn and t4g.
+var gravitonFamily = regexp.MustCompile(`^[a-z]+[0-9]+g[a-z]*$`)
+
+// eksTarget bundles the AWS resources a node group operation works on.
+type eksTarget struct {
+	clusterName string
+	nodeRoleARN string
+	net         *networkState
+}
+
+// resolveEKSTarget resolves the EKS cluster, the node role and the network of the Kompox cluster.
+func (d *driver) resolveEKSTarget(ctx context.Context, cluster *model.Cluster) (*eksTarget, error) {
+	c, err := d.activeEKSCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	net, err := d.findNetwork(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if net == nil {
+		return nil, fmt.Errorf("VPC of cluster %s not found", cluster.Name)
+	}
+	role, err := d.iamClient().GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(d.clusterRoleName(cluster, roleNode))})
+	if err != nil {
+		return nil, fmt.Errorf("get node role: %w", err)
+	}
+	return &eksTarget{clusterName: aws.ToString(c.Name), nodeRoleARN: aws.ToString(role.Role.Arn), net: net}, nil
+}
+
+// NodePoolList returns a list of node pools for the specified cluster.
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) (pools []*model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolList")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolListOptions(opts...)
+
+	t, err := d.resolveEKSTarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := d.listNodeGroups(ctx, t.clusterName)
+	if err != nil {
+		return nil, err
+	}
+	for _, ng := range groups {
+		if o.Name != "" && aws.ToString(ng.NodegroupName) != o.Name {
+			continue
+		}
+		pools = append(pools, nodeGroupToModel(ng, t.net.SubnetAZs()))
+	}
+	return pools, nil
+}
+
+// NodePoolCreate creates a new managed node group in the cluster.
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolCreateOption) (result *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolCreate")
+	defer func() { cleanup(err) }()
+
+	_ = model.ApplyNodePoolCreateOptions(opts...)
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("validation error: pool.Name is required")
+	}
+	t, err := d.resolveEKSTarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ng, err := d.createNodeGroup(ctx, cluster, t, pool)
+	if err != nil {
+		return nil, err
+	}
+	return nodeGroupToModel(*ng, t.net.SubnetAZs()), nil
+}
+
+// NodePoolUpdate updates mutable fields of an existing managed node group.
+// Labels and the scaling configuration (desired, min and max sizes) are mutable.
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolUpdateOption) (result *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolUpdate")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+	_ = model.ApplyNodePoolUpdateOptions(opts...)
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("validation error: pool.Name is required")
+	}
+	t, err := d.resolveEKSTarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := d.findNodeGroup(ctx, t.clusterName, *pool.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("node pool %s not found", *pool.Name)
+	}
+	cur := nodeGroupToModel(*existing, t.net.SubnetAZs())
+	if err := validateImmutableFields(pool, cur); err != nil {
+		return nil, err
+	}
+
+	in := &eks.UpdateNodegroupConfigInput{ClusterName: aws.String(t.clusterName), NodegroupName: existing.NodegroupName}
+	changed := false
+	if pool.Labels != nil {
+		want := maps.Clone(*pool.Labels)
+		if want == nil {
+			want = map[string]string{}
+		}
+		want[labelNodePool] = *pool.Name
+		if z, ok := existing.Labels[labelNodeZone]; ok {
+			want[labelNodeZone] = z
+		}
+		payload := &ekstypes.UpdateLabelsPayload{AddOrUpdateLabels: map[string]string{}}
+		for k, v := range want {
+			if existing.Labels[k] != v {
+				payload.AddOrUpdateLabels[k] = v
+			}
+		}
+		for k := range existing.Labels {
+			if _, ok := want[k]; !ok {
+				payload.RemoveLabels = append(payload.RemoveLabels, k)
+			}
+		}
+		if len(payload.AddOrUpdateLabels) > 0 || len(payload.RemoveLabels) > 0 {
+			slices.Sort(payload.RemoveLabels)
+			in.Labels = payload
+			changed = true
+		}
+	}
+	if pool.Autoscaling != nil {
+		sc, err := scalingConfig(pool.Autoscaling, existing.ScalingConfig)
+		if err != nil {
+			return nil, err
+		}
+		if !scalingConfigEqual(sc, existing.ScalingConfig) {
+			in.ScalingConfig = sc
+			changed = true
+		}
+	}
+	if !changed {
+		return cur, nil
+	}
+
+	log.Info(ctx, "updating node group", "poolName", *pool.Name)
+	if _, err := d.eksClient().UpdateNodegroupConfig(ctx, in); err != nil {
+		return nil, fmt.Errorf("update node group: %w", err)
+	}
+	ng, err := d.waitNodeGroupActive(ctx, t.clusterName, *pool.Name)
+	if err != nil {
+		return nil, err
+	}
+	return nodeGroupToModel(*ng, t.net.SubnetAZs()), nil
+}
+
+// NodePoolDelete deletes the specified node pool from the cluster.
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolDelete")
+	defer func() { cleanup(err) }()
+
+	_ = model.ApplyNodePoolDeleteOptions(opts...)
+
+	c, err := d.activeEKSCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return d.deleteNodeGroup(ctx, aws.ToString(c.Name), poolName)
+}
+
+// listNodeGroups lists the managed node groups of the EKS cluster.
+func (d *driver) listNodeGroups(ctx context.Context, clusterName string) ([]ekstypes.Nodegroup, error) {
+	c := d.eksClient()
+	var groups []ekstypes.Nodegroup
+	p := eks.NewListNodegroupsPaginator(c, &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list node groups: %w", err)
+		}
+		for _, name := range page.Nodegroups {
+			ng, err := d.findNodeGroup(ctx, clusterName, name)
+			if err != nil {
+				return nil, err
+			}
+			if ng != nil {
+				groups = append(groups, *ng)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// findNodeGroup returns the managed node group with the given name or nil when not found.
+func (d *driver) findNodeGroup(ctx context.Context, clusterName, name string) (*ekstypes.Nodegroup, error) {
+	out, err := d.eksClient().DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("describe node group %s: %w", name, err)
+	}
+	return out.Nodegroup, nil
+}
+
+// waitNodeGroupActive waits until the node group becomes ACTIVE. Failed states are reported with the health issues.
+func (d *driver) waitNodeGroupActive(ctx context.Context, clusterName, name string) (*ekstypes.Nodegroup, error) {
+	var ng *ekstypes.Nodegroup
+	err := waitFor(ctx, "node group "+name, func(ctx context.Context) (bool, error) {
+		got, err := d.findNodeGroup(ctx, clusterName, name)
+		if err != nil {
+			return false, err
+		}
+		if got == nil {
+			return false, fmt.Errorf("node group %s not found", name)
+		}
+		ng = got
+		switch got.Status {
+		case ekstypes.NodegroupStatusCreateFailed, ekstypes.NodegroupStatusDegraded:
+			var msgs []string
+			if got.Health != nil {
+				for _, issue := range got.Health.Issues {
+					msgs = append(msgs, aws.ToString(issue.Message))
+				}
+			}
+			return false, fmt.Errorf("node group %s is %s: %s", name, got.Status, strings.Join(msgs, "; "))
+		}
+		return got.Status == ekstypes.NodegroupStatusActive, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ng, nil
+}
+
+// createNodeGroup creates a managed node group and waits until it becomes ACTIVE.
+func (d *driver) createNodeGroup(ctx context.Context, cluster *model.Cluster, t *eksTarget, pool model.NodePool) (*ekstypes.Nodegroup, error) {
+	log := logging.FromContext(ctx)
+
+	if pool.OSDiskType != nil && *pool.OSDiskType != "" {
+		return nil, fmt.Errorf("validation error: OSDiskType is not supported by EKS managed node groups")
+	}
+	name := *pool.Name
+
+	instanceType := defaultNodeInstanceType
+	if pool.InstanceType != nil && *pool.InstanceType != "" {
+		instanceType = *pool.InstanceType
+	}
+	mode := "user"
+	if pool.Mode != nil && *pool.Mode != "" {
+		mode = strings.ToLower(*pool.Mode)
+	}
+	diskSize := defaultNodeDiskSizeGiB
+	if pool.OSDiskSizeGiB != nil && *pool.OSDiskSizeGiB > 0 {
+		diskSize = *pool.OSDiskSizeGiB
+	}
+	sc, err := scalingConfig(pool.Autoscaling, nil)
+	if err != nil {
+		return nil, err
+	}
+	capacity := ekstypes.CapacityTypesOnDemand
+	if pool.Priority != nil && strings.EqualFold(*pool.Priority, "spot") {
+		capacity = ekstypes.CapacityTypesSpot
+	}
+
+	// Placement: the subnets of the requested availability zones (all cluster subnets by default)
+	azs := t.net.AZs
+	if pool.Zones != nil && len(*pool.Zones) > 0 {
+		var selected []string
+		for _, z := range *pool.Zones {
+			az, err := resolveAZ(z, t.net.AZs)
+			if err != nil {
+				return nil, fmt.Errorf("validation error: %w", err)
+			}
+			selected = append(selected, az)
+		}
+		azs = selected
+	}
+	subnets := make([]string, 0, len(azs))
+	for _, az := range azs {
+		subnets = append(subnets, t.net.Subnets[az])
+	}
+
+	var labels map[string]string
+	if pool.Labels != nil {
+		labels = *pool.Labels
+	}
+	var zones *[]string
+	if pool.Zones != nil && len(*pool.Zones) > 0 {
+		zones = &azs
+	}
+
+	tags := d.clusterResourceTags(cluster.Name)
+	tags[tagNodePoolMode] = mode
+
+	log.Info(ctx, "creating node group", "poolName", name, "instanceType", instanceType, "capacityType", capacity, "size", aws.ToInt32(sc.DesiredSize), "zones", azs)
+	if _, err := d.eksClient().CreateNodegroup(ctx, &eks.CreateNodegroupInput{
+		ClusterName:   aws.String(t.clusterName),
+		NodegroupName: aws.String(name),
+		NodeRole:      aws.String(t.nodeRoleARN),
+		Subnets:       subnets,
+		InstanceTypes: []string{instanceType},
+		AmiType:       amiType(instanceType),
+		CapacityType:  capacity,
+		DiskSize:      aws.Int32(int32(diskSize)),
+		ScalingConfig: sc,
+		Labels:        nodeLabels(name, zones, labels),
+		Tags:          tags,
+	}); err != nil {
+		return nil, fmt.Errorf("create node group %s: %w", name, err)
+	}
+	return d.waitNodeGroupActive(ctx, t.clusterName, name)
+}
+
+// deleteNodeGroup deletes a managed node group and waits for completion. NotFound is not an error.
+func (d *driver) deleteNodeGroup(ctx context.Context, clusterName, name string) error {
+	log := logging.FromContext(ctx)
+	log.Info(ctx, "deleting node group", "poolName", name)
+	if _, err := d.eksClient().DeleteNodegroup(ctx, &eks.DeleteNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)}); err != nil {
+		if isNotFoundError(err) {
+			// NotFound is acceptable for idempotency
+			log.Info(ctx, "node group not found, considering delete successful", "poolName", name)
+			return nil
+		}
+		return fmt.Errorf("delete node group %s: %w", name, err)
+	}
+	return waitFor(ctx, "node group "+name+" deletion", func(ctx context.Context) (bool, error) {
+		ng, err := d.findNodeGroup(ctx, clusterName, name)
+		if err != nil {
+			return false, err
+		}
+		if ng != nil && ng.Status == ekstypes.NodegroupStatusDeleteFailed {
+			return false, fmt.Errorf("node group %s is %s", name, ng.Status)
+		}
+		return ng == nil, nil
+	})
+}
+
+// amiType selects the Amazon Linux 2023 AMI type matching the CPU architecture of the instance type.
+func amiType(instanceType string) ekstypes.AMITypes {
+	family, _, _ := strings.Cut(instanceType, ".")
+	if gravitonFamily.MatchString(family) {
+		return ekstypes.AMITypesAl2023Arm64Standard
+	}
+	return ekstypes.AMITypesAl2023X8664Standard
+}
+
+// scalingConfig converts Kompox autoscaling settings into a node group scaling config.
+// Without autoscaling the group is pinned to the desired size (min = max = desired).
+// Unspecified values are taken from the current config when available.
+func scalingConfig(as *model.NodePoolAutoscaling, cur *ekstypes.NodegroupScalingConfig) (*ekstypes.NodegroupScalingConfig, error) {
+	desired := int32(defaultNodeCount)
+	if cur != nil && cur.DesiredSize != nil {
+		desired = *cur.DesiredSize
+	}
+	if as != nil && as.Desired != nil {
+		desired = int32(*as.Desired)
+	}
+	if as == nil || !as.Enabled {
+		return &ekstypes.NodegroupScalingConfig{MinSize: aws.Int32(desired), MaxSize: aws.Int32(max(desired, 1)), DesiredSize: aws.Int32(desired)}, nil
+	}
+	minSize, maxSize := int32(as.Min), int32(as.Max)
+	if maxSize < 1 || minSize < 0 || minSize > maxSize {
+		return nil, fmt.Errorf("validation error: invalid autoscaling range min=%d max=%d", as.Min, as.Max)
+	}
+	desired = min(max(desired, minSize), maxSize)
+	return &ekstypes.NodegroupScalingConfig{MinSize: aws.Int32(minSize), MaxSize: aws.Int32(maxSize), DesiredSize: aws.Int32(desired)}, nil
+}
+
+func scalingConfigEqual(a, b *ekstypes.NodegroupScalingConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return aws.ToInt32(a.MinSize) == aws.ToInt32(b.MinSize) &&
+		aws.ToInt32(a.MaxSize) == aws.ToInt32(b.MaxSize) &&
+		aws.ToInt32(a.DesiredSize) == aws.ToInt32(b.DesiredSize)
+}
+
+// nodeLabels builds the node labels: user labels plus the Kompox pool and zone labels.
+func nodeLabels(name string, zones *[]string, labels map[string]string) map[string]string {
+	m := maps.Clone(labels)
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[labelNodePool] = name
+	if zones != nil && len(*zones) > 0 {
+		// Set node-zone label to the first zone (primary zone for multi-zone pools)
+		m[labelNodeZone] = (*zones)[0]
+	}
+	return m
+}
+
+// nodeGroupToModel converts an EKS managed node group to Kompox NodePool.
+// subnetAZ maps the subnet IDs of the cluster network to their availability zones.
+func nodeGroupToModel(ng ekstypes.Nodegroup, subnetAZ map[string]string) *model.NodePool {
+	pool := &model.NodePool{
+		Extensions: make(map[string]any),
+	}
+	if ng.NodegroupName != nil {
+		pool.Name = aws.String(*ng.NodegroupName)
+	}
+	if ng.NodegroupArn != nil {
+		pool.ProviderName = aws.String(*ng.NodegroupArn)
+	}
+
+	// Mode: recorded as a tag; node groups created outside Kompox fall back to the name
+	mode := ng.Tags[tagNodePoolMode]
+	if mode == "" {
+		mode = "user"
+		if pool.Name != nil && *pool.Name == "system" {
+			mode = "system"
+		}
+	}
+	pool.Mode = &mode
+
+	if len(ng.InstanceTypes) > 0 {
+		pool.InstanceType = aws.String(ng.InstanceTypes[0])
+	}
+	if ng.DiskSize != nil {
+		size := int(*ng.DiskSize)
+		pool.OSDiskSizeGiB = &size
+	}
+	if ng.AmiType != "" {
+		pool.Extensions["amiType"] = string(ng.AmiType)
+	}
+	priority := "regular"
+	if ng.CapacityType == ekstypes.CapacityTypesSpot {
+		priority = "spot"
+	}
+	pool.Priority = &priority
+
+	azs := map[string]bool{}
+	for _, s := range ng.Subnets {
+		if az, ok := subnetAZ[s]; ok {
+			azs[az] = true
+		}
+	}
+	if len(azs) > 0 {
+		zones := slices.Sorted(maps.Keys(azs))
+		pool.Zones = &zones
+	}
+
+	autoscaling := &model.NodePoolAutoscaling{}
+	if sc := ng.ScalingConfig; sc != nil {
+		autoscaling.Min = int(aws.ToInt32(sc.MinSize))
+		autoscaling.Max = int(aws.ToInt32(sc.MaxSize))
+		autoscaling.Enabled = autoscaling.Min != autoscaling.Max
+		if sc.DesiredSize != nil {
+			desired := int(*sc.DesiredSize)
+			autoscaling.Desired = &desired
+		}
+	}
+	pool.Autoscaling = autoscaling
+
+	if len(ng.Labels) > 0 {
+		labels := maps.Clone(ng.Labels)
+		pool.Labels = &labels
+	}
+
+	state := string(ng.Status)
+	pool.Status = &model.NodePoolStatus{
+		ProvisioningState: &state,
+		CurrentNodeCount:  autoscaling.Desired,
+		Extensions:        make(map[string]any),
+	}
+	return pool
+}
+
+// validateImmutableFields checks if any immutable fields are being changed.
+func validateImmutableFields(update model.NodePool, existing *model.NodePool) error {
+	var errs []string
+	if update.Mode != nil && existing.Mode != nil && !strings.EqualFold(*update.Mode, *existing.Mode) {
+		errs = append(errs, "Mode is immutable")
+	}
+	if update.InstanceType != nil && existing.InstanceType != nil && *update.InstanceType != *existing.InstanceType {
+		errs = append(errs, "InstanceType is immutable")
+	}
+	if update.OSDiskType != nil && *update.OSDiskType != "" {
+		errs = append(errs, "OSDiskType is not supported")
+	}
+	if update.OSDiskSizeGiB != nil && existing.OSDiskSizeGiB != nil && *update.OSDiskSizeGiB != *existing.OSDiskSizeGiB {
+		errs = append(errs, "OSDiskSizeGiB is immutable")
+	}
+	if update.Priority != nil && existing.Priority != nil && !strings.EqualFold(*update.Priority, *existing.Priority) {
+		errs = append(errs, "Priority is immutable")
+	}
+	if update.Zones != nil && existing.Zones != nil {
+		have := slices.Sorted(slices.Values(*existing.Zones))
+		want := make([]string, 0, len(*update.Zones))
+		for _, z := range *update.Zones {
+			az, err := resolveAZ(z, have)
+			if err != nil {
+				az = z
+			}
+			want = append(want, az)
+		}
+		slices.Sort(want)
+		if !slices.Equal(want, have) {
+			errs = append(errs, "Zones are immutable")
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("validation error: cannot modify immutable fields: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package eks
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/kompox/kompox/domain/model"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNodeGroupToModel(t *testing.T) {
+	ng := ekstypes.Nodegroup{
+		NodegroupName: aws.String("user"),
+		NodegroupArn:  aws.String("arn:aws:eks:ap-northeast-1:123456789012:nodegroup/cls/user/abc"),
+		InstanceTypes: []string{"m7g.large"},
+		DiskSize:      aws.Int32(100),
+		AmiType:       ekstypes.AMITypesAl2023Arm64Standard,
+		CapacityType:  ekstypes.CapacityTypesSpot,
+		Subnets:       []string{"subnet-c", "subnet-a"},
+		Labels:        map[string]string{labelNodePool: "user", "team": "a"},
+		ScalingConfig: &ekstypes.NodegroupScalingConfig{MinSize: aws.Int32(1), MaxSize: aws.Int32(5), DesiredSize: aws.Int32(2)},
+		Status:        ekstypes.NodegroupStatusActive,
+	}
+	subnetAZ := map[string]string{"subnet-a": "ap-northeast-1a", "subnet-c": "ap-northeast-1c"}
+
+	got := nodeGroupToModel(ng, subnetAZ)
+	if *got.Name != "user" || !strings.HasPrefix(*got.ProviderName, "arn:aws:eks:") {
+		t.Errorf("unexpected name/providerName: %q/%q", *got.Name, *got.ProviderName)
+	}
+	if *got.Mode != "user" {
+		t.Errorf("Mode = %q, want user", *got.Mode)
+	}
+	if *got.InstanceType != "m7g.large" || *got.OSDiskSizeGiB != 100 {
+		t.Errorf("InstanceType/OSDiskSizeGiB = %q/%d", *got.InstanceType, *got.OSDiskSizeGiB)
+	}
+	if *got.Priority != "spot" {
+		t.Errorf("Priority = %q, want spot", *got.Priority)
+	}
+	if !reflect.DeepEqual(*got.Zones, []string{"ap-northeast-1a", "ap-northeast-1c"}) {
+		t.Errorf("Zones = %v", *got.Zones)
+	}
+	if as := got.Autoscaling; !as.Enabled || as.Min != 1 || as.Max != 5 || *as.Desired != 2 {
+		t.Errorf("unexpected autoscaling: %+v", as)
+	}
+	if (*got.Labels)["team"] != "a" {
+		t.Errorf("Labels = %v", *got.Labels)
+	}
+	if got.Extensions["amiType"] != "AL2023_ARM_64_STANDARD" {
+		t.Errorf("Extensions = %v", got.Extensions)
+	}
+	if *got.Status.ProvisioningState != "ACTIVE" {
+		t.Errorf("ProvisioningState = %q", *got.Status.ProvisioningState)
+	}
+
+	tagged := nodeGroupToModel(ekstypes.Nodegroup{NodegroupName: aws.String("infra"), Tags: map[string]string{tagNodePoolMode: "system"}}, nil)
+	if *tagged.Mode != "system" {
+		t.Errorf("Mode = %q, want system", *tagged.Mode)
+	}
+	system := nodeGroupToModel(ekstypes.Nodegroup{NodegroupName: aws.String("system")}, nil)
+	if *system.Mode != "system" || *system.Priority != "regular" {
+		t.Errorf("Mode/Priority = %q/%q", *system.Mode, *system.Priority)
+	}
+}
+
+func TestValidateImmutableFields(t *testing.T) {
+	existing := &model.NodePool{
+		Mode:         aws.String("user"),
+		InstanceType: aws.String("t3.large"),
+		Priority:     aws.String("regular"),
+		Zones:        &[]string{"ap-northeast-1a", "ap-northeast-1c"},
+	}
+
+	if err := validateImmutableFields(model.NodePool{Zones: &[]string{"c", "1"}, Labels: &map[string]string{"a": "b"}}, existing); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := validateImmutableFields(model.NodePool{
+		InstanceType: aws.String("m7g.large"),
+		OSDiskType:   aws.String("Ephemeral"),
+		Priority:     aws.String("spot"),
+		Zones:        &[]string{"d"},
+	}, existing)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, s := range []string{"InstanceType", "OSDiskType", "Priority", "Zones"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("error %q does not mention %s", err, s)
+		}
+	}
+}
+
+func TestScalingConfig(t *testing.T) {
+	three := 3
+	tests := []struct {
+		name           string
+		as             *model.NodePoolAutoscaling
+		cur            *ekstypes.NodegroupScalingConfig
+		min, max, want int32
+		wantErr        bool
+	}{
+		{name: "default", min: 1, max: 1, want: 1},
+		{name: "fixed", as: &model.NodePoolAutoscaling{Desired: &three}, min: 3, max: 3, want: 3},
+		{name: "keep current", as: &model.NodePoolAutoscaling{}, cur: &ekstypes.NodegroupScalingConfig{DesiredSize: aws.Int32(2)}, min: 2, max: 2, want: 2},
+		{name: "scale to zero", as: &model.NodePoolAutoscaling{Desired: new(int)}, min: 0, max: 1, want: 0},
+		{name: "autoscaling clamps desired", as: &model.NodePoolAutoscaling{Enabled: true, Min: 1, Max: 2, Desired: &three}, min: 1, max: 2, want: 2},
+		{name: "invalid range", as: &model.NodePoolAutoscaling{Enabled: true, Min: 3, Max: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scalingConfig(tt.as, tt.cur)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got.MinSize != tt.min || *got.MaxSize != tt.max || *got.DesiredSize != tt.want {
+				t.Errorf("got min=%d max=%d desired=%d, want %d/%d/%d", *got.MinSize, *got.MaxSize, *got.DesiredSize, tt.min, tt.max, tt.want)
+			}
+		})
+	}
+}
+
+func TestAMIType(t *testing.T) {
+	tests := map[string]ekstypes.AMITypes{
+		"t3.large":    ekstypes.AMITypesAl2023X8664Standard,
+		"m7g.large":   ekstypes.AMITypesAl2023Arm64Standard,
+		"c6gn.xlarge": ekstypes.AMITypesAl2023Arm64Standard,
+		"t4g.medium":  ekstypes.AMITypesAl2023Arm64Standard,
+		"g5.xlarge":   ekstypes.AMITypesAl2023X8664Standard,
+		"m6i.large":   ekstypes.AMITypesAl2023X8664Standard,
+	}
+	for in, want := range tests {
+		if got := amiType(in); got != want {
+			t.Errorf("amiType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	got := nodeLabels("user", &[]string{"1", "2"}, map[string]string{"team": "a"})
+	want := map[string]string{"team": "a", labelNodePool: "user", labelNodeZone: "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeLabels = %v, want %v", got, want)
+	}
+}
+
+func TestNodePoolFromSettings(t *testing.T) {
+	cluster := &model.Cluster{Settings: map[string]string{
+		"AWS_EKS_USER_INSTANCE_TYPE": "m7g.large",
+		"AWS_EKS_USER_DISK_SIZE_GB":  "80",
+		"AWS_EKS_USER_ZONES":         "a, c",
+		"AWS_EKS_USER_SPOT":          "true",
+		"AWS_EKS_USER_COUNT":         "2",
+	}}
+
+	user, err := nodePoolFromSettings(cluster, "user")
+	if err != nil {
+		t.Fatalf("nodePoolFromSettings: %v", err)
+	}
+	if *user.InstanceType != "m7g.large" || *user.OSDiskSizeGiB != 80 {
+		t.Errorf("unexpected instance settings: %s %d", *user.InstanceType, *user.OSDiskSizeGiB)
+	}
+	if !reflect.DeepEqual(*user.Zones, []string{"a", "c"}) {
+		t.Errorf("Zones = %v", *user.Zones)
+	}
+	if user.Priority == nil || *user.Priority != "spot" {
+		t.Errorf("Priority = %v, want spot", user.Priority)
+	}
+	if *user.Autoscaling.Desired != 2 {
+		t.Errorf("Desired = %d, want 2", *user.Autoscaling.Desired)
+	}
+
+	system, err := nodePoolFromSettings(cluster, "system")
+	if err != nil {
+		t.Fatalf("nodePoolFromSettings: %v", err)
+	}
+	if *system.InstanceType != defaultNodeInstanceType || *system.OSDiskSizeGiB != defaultNodeDiskSizeGiB || system.Zones != nil || system.Priority != nil {
+		t.Errorf("unexpected system defaults: %+v", system)
+	}
+
+	cluster.Settings["AWS_EKS_SYSTEM_COUNT"] = "two"
+	if _, err := nodePoolFromSettings(cluster, "system"); err == nil {
+		t.Error("expected error for invalid COUNT")
+	}
+}
+
+func TestNodePoolLifecycle(t *testing.T) {
+	ctx := context.Background()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls"}
+
+	if _, err := d.NodePoolList(ctx, cluster); err == nil {
+		t.Fatal("NodePoolList before provision: want error")
+	}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+
+	pool, err := d.NodePoolCreate(ctx, cluster, model.NodePool{
+		Name:        aws.String("batch"),
+		Zones:       &[]string{"3"},
+		Priority:    aws.String("spot"),
+		Labels:      &map[string]string{"team": "a"},
+		Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 1, Max: 3},
+	})
+	if err != nil {
+		t.Fatalf("NodePoolCreate: %v", err)
+	}
+	if z := *pool.Zones; len(z) != 1 || z[0] != "us-east-1c" || *pool.Priority != "spot" || (*pool.Labels)["team"] != "a" {
+		t.Errorf("NodePoolCreate = zones %v priority %s labels %v", z, *pool.Priority, *pool.Labels)
+	}
+	if _, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: aws.String("batch")}); err == nil {
+		t.Error("NodePoolCreate of an existing pool: want error")
+	}
+
+	pools, err := d.NodePoolList(ctx, cluster, model.WithNodePoolListName("batch"))
+	if err != nil || len(pools) != 1 {
+		t.Fatalf("NodePoolList(batch) = %d pools, %v; want 1", len(pools), err)
+	}
+
+	pool, err = d.NodePoolUpdate(ctx, cluster, model.NodePool{
+		Name:        aws.String("batch"),
+		Labels:      &map[string]string{"team": "b"},
+		Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 2, Max: 5},
+	})
+	if err != nil {
+		t.Fatalf("NodePoolUpdate: %v", err)
+	}
+	if as := pool.Autoscaling; (*pool.Labels)["team"] != "b" || (*pool.Labels)[labelNodePool] != "batch" || as.Min != 2 || as.Max != 5 {
+		t.Errorf("NodePoolUpdate = labels %v autoscaling %+v", *pool.Labels, *as)
+	}
+	if _, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: aws.String("batch"), InstanceType: aws.String("m5.xlarge")}); err == nil || !strings.Contains(err.Error(), "InstanceType is immutable") {
+		t.Errorf("NodePoolUpdate of an immutable field = %v, want validation error", err)
+	}
+	if _, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: aws.String("missing")}); err == nil {
+		t.Error("NodePoolUpdate of a missing pool: want error")
+	}
+
+	for i := range 2 {
+		if err := d.NodePoolDelete(ctx, cluster, "batch"); err != nil {
+			t.Fatalf("NodePoolDelete #%d: %v", i+1, err)
+		}
+	}
+	if pools, err := d.NodePoolList(ctx, cluster, model.WithNodePoolListName("batch")); err != nil || len(pools) != 0 {
+		t.Errorf("NodePoolList(batch) after delete = %d pools, %v; want none", len(pools), err)
+	}
+
+	tests := []struct {
+		name string
+		op   string
+		call func() error
+	}{
+		{"list", "ListNodegroups", func() error { _, err := d.NodePoolList(ctx, cluster); return err }},
+		{"list node role", "GetRole", func() error { _, err := d.NodePoolList(ctx, cluster); return err }},
+		{"create", "CreateNodegroup", func() error {
+			_, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: aws.String("extra")})
+			return err
+		}},
+		{"update", "UpdateNodegroupConfig", func() error {
+			_, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: aws.String("user"), Labels: &map[string]string{"x": "y"}})
+			return err
+		}},
+		{"delete", "DeleteNodegroup", func() error { return d.NodePoolDelete(ctx, cluster, "user") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.setFail(tt.op, "AccessDeniedException")
+			defer f.setFail(tt.op, "")
+			if err := tt.call(); err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+				t.Errorf("got %v, want the %s error", err, tt.op)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// resolveVolumeDriver returns the appropriate volume driver based on volume type.
+// Empty type defaults to disk volume driver.
+func (d *driver) resolveVolumeDriver(vol *model.AppVolume) (volumeBackend, error) {
+	volType := vol.Type
+	if volType == "" {
+		volType = model.VolumeTypeDisk
+	}
+
+	vb, ok := d.volumeBackends[volType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported volume type: %s", volType)
+	}
+
+	return vb, nil
+}
+
+// VolumeDiskList implements spec method.
+func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.DiskList(ctx, cluster, app, volName, opts...)
+}
+
+// VolumeDiskCreate implements spec method.
+func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	diskName = strings.TrimSpace(diskName)
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.DiskCreate(ctx, cluster, app, volName, diskName, source, opts...)
+}
+
+// VolumeDiskAssign implements spec method.
+func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
+	if cluster == nil || app == nil {
+		return fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return err
+	}
+
+	return vb.DiskAssign(ctx, cluster, app, volName, diskName, opts...)
+}
+
+// VolumeDiskDelete implements spec method.
+func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) error {
+	if cluster == nil || app == nil {
+		return fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return err
+	}
+
+	return vb.DiskDelete(ctx, cluster, app, volName, diskName, opts...)
+}
+
+// VolumeDiskUpdate implements spec method.
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.DiskUpdate(ctx, cluster, app, volName, diskName, opts...)
+}
+
+// VolumeSnapshotList implements spec method.
+func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.SnapshotList(ctx, cluster, app, volName, opts...)
+}
+
+// VolumeSnapshotCreate implements spec method.
+func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	if cluster == nil || app == nil {
+		return nil, fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return vb.SnapshotCreate(ctx, cluster, app, volName, snapName, source, opts...)
+}
+
+// VolumeSnapshotDelete implements spec method.
+func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
+	if cluster == nil || app == nil {
+		return fmt.Errorf("cluster/app nil")
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return fmt.Errorf("find volume: %w", err)
+	}
+
+	vb, err := d.resolveVolumeDriver(vol)
+	if err != nil {
+		return err
+	}
+
+	return vb.SnapshotDelete(ctx, cluster, app, volName, snapName, opts...)
+}
+
+// VolumeClass implements providerdrv.Driver VolumeClass method for EKS.
+// Returns opinionated defaults suitable for the EBS CSI driver.
+func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	vb, err := d.resolveVolumeDriver(&vol)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+
+	return vb.Class(ctx, cluster, app, vol)
+}
+
+// VolumeResourceList is not supported: orphaned EBS volumes are not inventoried yet.
+func (d *driver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
+	return nil, model.ErrNotSupported
+}
+
+// VolumeResourceMarkOrphaned is not supported.
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error {
+	return model.ErrNotSupported
+}
+
+// VolumeResourceUnmarkOrphaned is not supported.
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error {
+	return model.ErrNotSupported
+}
+
+// VolumeResourceDelete is not supported.
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error {
+	return model.ErrNotSupported
+}
@@ -0,0 +1,830 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// EBS volume options (app.volumes.options)
+const (
+	diskOptionType       = "type"       // volume type: gp3 (default), gp2, io1, io2, st1, sc1
+	diskOptionIOPS       = "iops"       // provisioned IOPS (gp3, io1, io2)
+	diskOptionThroughput = "throughput" // provisioned throughput in MiB/s (gp3)
+)
+
+// EBS volume defaults
+const (
+	minEBSVolumeSizeGiB  = 1
+	defaultEBSVolumeType = ec2types.VolumeTypeGp3
+	ebsCSIDriver         = "ebs.csi.aws.com"
+)
+
+// ebsVolumeTypes lists the volume types accepted by the type option.
+var ebsVolumeTypes = []ec2types.VolumeType{
+	ec2types.VolumeTypeGp3,
+	ec2types.VolumeTypeGp2,
+	ec2types.VolumeTypeIo1,
+	ec2types.VolumeTypeIo2,
+	ec2types.VolumeTypeSt1,
+	ec2types.VolumeTypeSc1,
+}
+
+// ebsOptions holds the parsed EBS volume options. Unset options are empty/nil.
+type ebsOptions struct {
+	Type       ec2types.VolumeType
+	IOPS       *int32
+	Throughput *int32
+}
+
+// volumeBackendDisk implements volumeBackend interface for Amazon EBS volumes (Type="disk").
+// Disks are EBS volumes and snapshots are EBS snapshots, both discovered through the app tags.
+type volumeBackendDisk struct {
+	driver *driver
+}
+
+func newVolumeBackendDisk(d *driver) volumeBackend {
+	return &volumeBackendDisk{driver: d}
+}
+
+// volumeFilters returns the EC2 filters selecting the resources of the app volume.
+func (vb *volumeBackendDisk) volumeFilters(app *model.App, volName string) []ec2types.Filter {
+	tags := vb.driver.appResourceTags(app.Name)
+	return tagFilters(map[string]string{
+		tagAppIDHash:  tags[tagAppIDHash],
+		tagVolumeName: volName,
+	})
+}
+
+// DiskList lists EBS volumes for a volume (Type="disk").
+func (vb *volumeBackendDisk) DiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	volumes, err := vb.listVolumes(ctx, app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var out []*model.VolumeDisk
+	for i := range volumes {
+		if disk := vb.newDisk(&volumes[i], volName); disk != nil {
+			out = append(out, disk)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// DiskCreate creates a new EBS volume for a volume (Type="disk").
+func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+	var optionsStruct model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	// List existing disks to determine name if needed
+	items, err := vb.DiskList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list disks: %w", err)
+	}
+
+	diskName = strings.TrimSpace(diskName)
+	if diskName == "" {
+		diskName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == diskName {
+				return nil, fmt.Errorf("disk %q already exists", diskName)
+			}
+		}
+	}
+
+	displayName, err := vb.driver.appDiskName(app, volName, diskName)
+	if err != nil {
+		return nil, fmt.Errorf("generate disk resource name: %w", err)
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume %q: %w", volName, err)
+	}
+
+	// Get size from volume configuration (options may require a larger disk, e.g. for snapshot sources)
+	size := vol.Size
+	if optionsStruct.Size > size {
+		size = optionsStruct.Size
+	}
+	sizeGiB := size >> 30
+	if sizeGiB < minEBSVolumeSizeGiB {
+		sizeGiB = minEBSVolumeSizeGiB
+	}
+
+	// Merge volume options with functional options
+	volOptions := maps.Clone(vol.Options)
+	if optionsStruct.Options != nil {
+		if volOptions == nil {
+			volOptions = map[string]any{}
+		}
+		maps.Copy(volOptions, optionsStruct.Options)
+	}
+	ebsOpts, err := parseDiskOptions(volOptions)
+	if err != nil {
+		return nil, err
+	}
+	if ebsOpts.Type == "" {
+		ebsOpts.Type = defaultEBSVolumeType
+	}
+	if err := validateDiskOptions(ebsOpts); err != nil {
+		return nil, err
+	}
+
+	// Determine availability zone (options override app config; default is the first AZ)
+	zone := app.Deployment.Zone
+	if optionsStruct.Zone != "" {
+		zone = optionsStruct.Zone
+	}
+	azs, err := vb.driver.availabilityZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	az := azs[0]
+	if strings.TrimSpace(zone) != "" {
+		if az, err = resolveAZ(zone, azs); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := vb.driver.appResourceTags(app.Name)
+	tags[tagVolumeName] = volName
+	tags[tagDiskName] = diskName
+	tags[tagDiskAssigned] = "false"
+	tags[tagName] = displayName
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
+
+	input := &ec2.CreateVolumeInput{
+		AvailabilityZone:  aws.String(az),
+		Size:              aws.Int32(int32(sizeGiB)),
+		VolumeType:        ebsOpts.Type,
+		Iops:              ebsOpts.IOPS,
+		Throughput:        ebsOpts.Throughput,
+		Encrypted:         aws.Bool(true),
+		TagSpecifications: tagSpec(ec2types.ResourceTypeVolume, tags),
+	}
+	if source = strings.TrimSpace(source); source != "" {
+		// Resolve source using snapshot resolution (defaults to "snapshot:" prefix if unknown)
+		snapshotID, err := vb.resolveSnapshotSource(ctx, app, volName, source)
+		if err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+		input.SnapshotId = aws.String(snapshotID)
+	}
+
+	ec := vb.driver.ec2Client()
+	res, err := ec.CreateVolume(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create volume: %w", err)
+	}
+	volume, err := vb.waitVolumeAvailable(ctx, aws.ToString(res.VolumeId))
+	if err != nil {
+		return nil, err
+	}
+	return vb.newDisk(volume, volName), nil
+}
+
+// DiskDelete deletes an EBS volume (Type="disk"). A missing volume is not an error.
+func (vb *volumeBackendDisk) DiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	volume, err := vb.findVolume(ctx, app, volName, diskName)
+	if err != nil || volume == nil {
+		return err
+	}
+	if _, err := vb.driver.ec2Client().DeleteVolume(ctx, &ec2.DeleteVolumeInput{VolumeId: volume.VolumeId}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete volume: %w", err)
+	}
+	return nil
+}
+
+// DiskAssign assigns or unassigns EBS volumes (Type="disk") by updating tags.
+func (vb *volumeBackendDisk) DiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	volumes, err := vb.listVolumes(ctx, app, volName)
+	if err != nil {
+		return err
+	}
+
+	// Find the target disk
+	var found bool
+	for i := range volumes {
+		if disk := vb.newDisk(&volumes[i], volName); disk != nil && disk.Name == diskName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("disk not found: %s", diskName)
+	}
+
+	ec := vb.driver.ec2Client()
+	for i := range volumes {
+		disk := vb.newDisk(&volumes[i], volName)
+		if disk == nil {
+			continue
+		}
+		assigned := disk.Name == diskName
+		if assigned == disk.Assigned {
+			continue
+		}
+		if _, err := ec.CreateTags(ctx, &ec2.CreateTagsInput{
+			Resources: []string{aws.ToString(volumes[i].VolumeId)},
+			Tags:      ec2Tags(map[string]string{tagDiskAssigned: strconv.FormatBool(assigned)}),
+		}); err != nil {
+			return fmt.Errorf("tag volume %s: %w", aws.ToString(volumes[i].VolumeId), err)
+		}
+	}
+	return nil
+}
+
+// DiskUpdate changes the type and performance (type, iops, throughput) of an existing EBS volume
+// in place with Elastic Volumes. Other options are rejected.
+func (vb *volumeBackendDisk) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	var optionsStruct model.VolumeDiskUpdateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+	if len(optionsStruct.Options) == 0 {
+		return nil, diskOptionsError("no options to update")
+	}
+	for k := range optionsStruct.Options {
+		if k != diskOptionType && k != diskOptionIOPS && k != diskOptionThroughput {
+			return nil, diskOptionsError("option %q cannot be changed online (supported: %s, %s, %s)", k, diskOptionType, diskOptionIOPS, diskOptionThroughput)
+		}
+	}
+	ebsOpts, err := parseDiskOptions(optionsStruct.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	volume, err := vb.findVolume(ctx, app, volName, diskName)
+	if err != nil {
+		return nil, err
+	}
+	if volume == nil {
+		return nil, fmt.Errorf("disk not found: %s", diskName)
+	}
+
+	// Validate against the resulting volume type
+	target := *ebsOpts
+	if target.Type == "" {
+		target.Type = volume.VolumeType
+	}
+	if err := validateDiskOptions(&target); err != nil {
+		return nil, err
+	}
+
+	ec := vb.driver.ec2Client()
+	input := &ec2.ModifyVolumeInput{
+		VolumeId:   volume.VolumeId,
+		Iops:       ebsOpts.IOPS,
+		Throughput: ebsOpts.Throughput,
+	}
+	if ebsOpts.Type != "" {
+		input.VolumeType = ebsOpts.Type
+	}
+	if _, err := ec.ModifyVolume(ctx, input); err != nil {
+		return nil, fmt.Errorf("modify volume %s: %w", aws.ToString(volume.VolumeId), err)
+	}
+
+	// The new settings are effective once the modification reaches the optimizing state
+	id := aws.ToString(volume.VolumeId)
+	err = waitFor(ctx, "volume modification "+id, func(ctx context.Context) (bool, error) {
+		out, err := ec.DescribeVolumesModifications(ctx, &ec2.DescribeVolumesModificationsInput{VolumeIds: []string{id}})
+		if err != nil {
+			return false, err
+		}
+		for _, m := range out.VolumesModifications {
+			switch m.ModificationState {
+			case ec2types.VolumeModificationStateFailed:
+				return false, fmt.Errorf("volume modification %s failed: %s", id, aws.ToString(m.StatusMessage))
+			case ec2types.VolumeModificationStateOptimizing, ec2types.VolumeModificationStateCompleted:
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	volume, err = vb.waitVolumeAvailable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return vb.newDisk(volume, volName), nil
+}
+
+// SnapshotList lists EBS snapshots for a volume (Type="disk").
+func (vb *volumeBackendDisk) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	snapshots, err := vb.listSnapshots(ctx, app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var out []*model.VolumeSnapshot
+	for i := range snapshots {
+		if snap := vb.newSnapshot(&snapshots[i], volName); snap != nil {
+			out = append(out, snap)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// SnapshotCreate creates an EBS snapshot of an EBS volume (Type="disk").
+func (vb *volumeBackendDisk) SnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	var optionsStruct model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	// List existing snapshots to determine name if needed
+	items, err := vb.SnapshotList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		snapName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == snapName {
+				return nil, fmt.Errorf("snapshot %q already exists", snapName)
+			}
+		}
+	}
+
+	displayName, err := vb.driver.appSnapshotName(app, volName, snapName)
+	if err != nil {
+		return nil, fmt.Errorf("generate snapshot resource name: %w", err)
+	}
+
+	// Determine source volume ID
+	var sourceID string
+	source = strings.TrimSpace(source)
+	if source == "" {
+		// Use assigned disk
+		disks, err := vb.DiskList(ctx, cluster, app, volName)
+		if err != nil {
+			return nil, fmt.Errorf("list disks: %w", err)
+		}
+		for _, d := range disks {
+			if d.Assigned && d.VolumeName == volName {
+				sourceID = d.Handle
+				break
+			}
+		}
+		if sourceID == "" {
+			return nil, fmt.Errorf("no assigned disk found for volume %q", volName)
+		}
+	} else {
+		sourceID, err = vb.resolveDiskSource(ctx, app, volName, source)
+		if err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+	}
+
+	tags := vb.driver.appResourceTags(app.Name)
+	tags[tagVolumeName] = volName
+	tags[tagSnapshotName] = snapName
+	tags[tagName] = displayName
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
+
+	ec := vb.driver.ec2Client()
+	res, err := ec.CreateSnapshot(ctx, &ec2.CreateSnapshotInput{
+		VolumeId:          aws.String(sourceID),
+		Description:       aws.String(displayName),
+		TagSpecifications: tagSpec(ec2types.ResourceTypeSnapshot, tags),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot: %w", err)
+	}
+	id := aws.ToString(res.SnapshotId)
+	var snapshot *ec2types.Snapshot
+	err = waitFor(ctx, "snapshot "+displayName, func(ctx context.Context) (bool, error) {
+		out, err := ec.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{SnapshotIds: []string{id}})
+		if err != nil {
+			return false, err
+		}
+		if len(out.Snapshots) == 0 {
+			return false, nil
+		}
+		snapshot = &out.Snapshots[0]
+		if snapshot.State == ec2types.SnapshotStateError {
+			return false, fmt.Errorf("snapshot %s failed: %s", id, aws.ToString(snapshot.StateMessage))
+		}
+		return snapshot.State == ec2types.SnapshotStateCompleted, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vb.newSnapshot(snapshot, volName), nil
+}
+
+// SnapshotDelete deletes an EBS snapshot (Type="disk"). A missing snapshot is not an error.
+func (vb *volumeBackendDisk) SnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	snapshot, err := vb.findSnapshot(ctx, app, volName, snapName)
+	if err != nil || snapshot == nil {
+		return err
+	}
+	if _, err := vb.driver.ec2Client().DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{SnapshotId: snapshot.SnapshotId}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// Class returns EBS CSI driver provisioning parameters (Type="disk").
+// Volume options are validated so that invalid settings are reported before any volume is created.
+func (vb *volumeBackendDisk) Class(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	opts, err := parseDiskOptions(vol.Options)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+	if opts.Type == "" {
+		opts.Type = defaultEBSVolumeType
+	}
+	if err := validateDiskOptions(opts); err != nil {
+		return model.VolumeClass{}, err
+	}
+	return model.VolumeClass{
+		StorageClassName: defaultStorageClassName,
+		CSIDriver:        ebsCSIDriver,
+		FSType:           "ext4",
+		Attributes:       map[string]string{"fsType": "ext4"},
+		AccessModes:      []string{"ReadWriteOnce"},
+		ReclaimPolicy:    "Retain",
+		VolumeMode:       "Filesystem",
+	}, nil
+}
+
+// listVolumes lists the EBS volumes of the app volume, excluding deleted ones.
+func (vb *volumeBackendDisk) listVolumes(ctx context.Context, app *model.App, volName string) ([]ec2types.Volume, error) {
+	p := ec2.NewDescribeVolumesPaginator(vb.driver.ec2Client(), &ec2.DescribeVolumesInput{Filters: vb.volumeFilters(app, volName)})
+	var out []ec2types.Volume
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("describe volumes: %w", err)
+		}
+		for _, v := range page.Volumes {
+			if v.State == ec2types.VolumeStateDeleting || v.State == ec2types.VolumeStateDeleted {
+				continue
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// listSnapshots lists the EBS snapshots of the app volume owned by the account.
+func (vb *volumeBackendDisk) listSnapshots(ctx context.Context, app *model.App, volName string) ([]ec2types.Snapshot, error) {
+	p := ec2.NewDescribeSnapshotsPaginator(vb.driver.ec2Client(), &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters:  vb.volumeFilters(app, volName),
+	})
+	var out []ec2types.Snapshot
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("describe snapshots: %w", err)
+		}
+		out = append(out, page.Snapshots...)
+	}
+	return out, nil
+}
+
+// findVolume returns the EBS volume of the Kompox disk or nil when not found.
+func (vb *volumeBackendDisk) findVolume(ctx context.Context, app *model.App, volName, diskName string) (*ec2types.Volume, error) {
+	volumes, err := vb.listVolumes(ctx, app, volName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range volumes {
+		if ec2TagMap(volumes[i].Tags)[tagDiskName] == diskName {
+			return &volumes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// findSnapshot returns the EBS snapshot of the Kompox snapshot or nil when not found.
+func (vb *volumeBackendDisk) findSnapshot(ctx context.Context, app *model.App, volName, snapName string) (*ec2types.Snapshot, error) {
+	snapshots, err := vb.listSnapshots(ctx, app, volName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if ec2TagMap(snapshots[i].Tags)[tagSnapshotName] == snapName {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// waitVolumeAvailable polls the EBS volume until it becomes available (or in-use when attached).
+func (vb *volumeBackendDisk) waitVolumeAvailable(ctx context.Context, id string) (*ec2types.Volume, error) {
+	ec := vb.driver.ec2Client()
+	var volume *ec2types.Volume
+	err := waitFor(ctx, "volume "+id, func(ctx context.Context) (bool, error) {
+		out, err := ec.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{id}})
+		if err != nil {
+			return false, err
+		}
+		if len(out.Volumes) == 0 {
+			return false, nil
+		}
+		volume = &out.Volumes[0]
+		if volume.State == ec2types.VolumeStateError {
+			return false, fmt.Errorf("volume %s is in error state", id)
+		}
+		return volume.State == ec2types.VolumeStateAvailable || volume.State == ec2types.VolumeStateInUse, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// resolveSnapshotSource resolves a DiskCreate source string to an EBS snapshot ID.
+// - "snapshot:name" -> Kompox managed snapshot
+// - "snap-..." -> EBS snapshot ID
+// - Others -> Kompox managed snapshot name
+// EBS cannot create a volume directly from another volume, so disk sources are rejected.
+func (vb *volumeBackendDisk) resolveSnapshotSource(ctx context.Context, app *model.App, volName, source string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(source), "snap-") {
+		return source, nil
+	}
+	kind, name := "snapshot", source
+	if k, n, ok := strings.Cut(source, ":"); ok {
+		kind, name = strings.ToLower(k), strings.TrimSpace(n)
+	}
+	if kind != "snapshot" {
+		return "", fmt.Errorf("unsupported source kind %q (EBS volumes can only be created from snapshots)", kind)
+	}
+	if name == "" {
+		return "", fmt.Errorf("source name is empty")
+	}
+	snapshot, err := vb.findSnapshot(ctx, app, volName, name)
+	if err != nil {
+		return "", err
+	}
+	if snapshot == nil {
+		return "", fmt.Errorf("snapshot %q not found", name)
+	}
+	return aws.ToString(snapshot.SnapshotId), nil
+}
+
+// resolveDiskSource resolves a SnapshotCreate source string to an EBS volume ID.
+// - "disk:name" -> Kompox managed disk
+// - "vol-..." -> EBS volume ID
+// - Others -> Kompox managed disk name
+func (vb *volumeBackendDisk) resolveDiskSource(ctx context.Context, app *model.App, volName, source string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(source), "vol-") {
+		return source, nil
+	}
+	kind, name := "disk", source
+	if k, n, ok := strings.Cut(source, ":"); ok {
+		kind, name = strings.ToLower(k), strings.TrimSpace(n)
+	}
+	if kind != "disk" {
+		return "", fmt.Errorf("unsupported source kind %q (snapshot source must be a disk)", kind)
+	}
+	if name == "" {
+		return "", fmt.Errorf("source name is empty")
+	}
+	volume, err := vb.findVolume(ctx, app, volName, name)
+	if err != nil {
+		return "", err
+	}
+	if volume == nil {
+		return "", fmt.Errorf("disk %q not found", name)
+	}
+	return aws.ToString(volume.VolumeId), nil
+}
+
+// newDisk creates a model.VolumeDisk from an EBS volume.
+// Returns nil when the volume is not a Kompox disk of the logical volume.
+func (vb *volumeBackendDisk) newDisk(v *ec2types.Volume, volName string) *model.VolumeDisk {
+	if v == nil || v.VolumeId == nil {
+		return nil
+	}
+	tags := ec2TagMap(v.Tags)
+	if tags[tagVolumeName] != volName || tags[tagDiskName] == "" {
+		return nil
+	}
+
+	var size int64
+	if v.Size != nil {
+		size = int64(*v.Size) << 30
+	}
+	var created time.Time
+	if v.CreateTime != nil {
+		created = *v.CreateTime
+	}
+	options := map[string]any{diskOptionType: string(v.VolumeType)}
+	if v.Iops != nil {
+		options[diskOptionIOPS] = int(*v.Iops)
+	}
+	if v.Throughput != nil {
+		options[diskOptionThroughput] = int(*v.Throughput)
+	}
+	labels, description := userMetadataFromTags(tags)
+
+	return &model.VolumeDisk{
+		Name:         tags[tagDiskName],
+		VolumeName:   volName,
+		Assigned:     strings.EqualFold(tags[tagDiskAssigned], "true"),
+		Size:         size,
+		Zone:         aws.ToString(v.AvailabilityZone),
+		Options:      options,
+		Handle:       *v.VolumeId,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: aws.ToString(v.SnapshotId),
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// newSnapshot creates a model.VolumeSnapshot from an EBS snapshot.
+// Returns nil when the snapshot is not a Kompox snapshot of the logical volume.
+func (vb *volumeBackendDisk) newSnapshot(s *ec2types.Snapshot, volName string) *model.VolumeSnapshot {
+	if s == nil || s.SnapshotId == nil {
+		return nil
+	}
+	tags := ec2TagMap(s.Tags)
+	if tags[tagVolumeName] != volName || tags[tagSnapshotName] == "" {
+		return nil
+	}
+
+	var size int64
+	if s.VolumeSize != nil {
+		size = int64(*s.VolumeSize) << 30
+	}
+	var created time.Time
+	if s.StartTime != nil {
+		created = *s.StartTime
+	}
+	labels, description := userMetadataFromTags(tags)
+
+	return &model.VolumeSnapshot{
+		Name:         tags[tagSnapshotName],
+		VolumeName:   volName,
+		Size:         size,
+		Handle:       *s.SnapshotId,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: aws.ToString(s.VolumeId),
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// parseDiskOptions reads the EBS volume options. Other options are ignored.
+func parseDiskOptions(options map[string]any) (*ebsOptions, error) {
+	out := &ebsOptions{}
+	for k, raw := range options {
+		switch k {
+		case diskOptionType:
+			s, ok := raw.(string)
+			if !ok {
+				return nil, diskOptionsError("%s must be a string", diskOptionType)
+			}
+			t := ec2types.VolumeType(strings.ToLower(strings.TrimSpace(s)))
+			if !slices.Contains(ebsVolumeTypes, t) {
+				return nil, diskOptionsError("unsupported %s %q (supported: gp3, gp2, io1, io2, st1, sc1)", diskOptionType, s)
+			}
+			out.Type = t
+		case diskOptionIOPS:
+			n, err := parsePositiveInt32(k, raw)
+			if err != nil {
+				return nil, err
+			}
+			out.IOPS = n
+		case diskOptionThroughput:
+			n, err := parsePositiveInt32(k, raw)
+			if err != nil {
+				return nil, err
+			}
+			out.Throughput = n
+		}
+	}
+	return out, nil
+}
+
+// validateDiskOptions checks that the performance options are applicable to the volume type.
+func validateDiskOptions(o *ebsOptions) error {
+	switch o.Type {
+	case ec2types.VolumeTypeGp3:
+	case ec2types.VolumeTypeIo1, ec2types.VolumeTypeIo2:
+		if o.Throughput != nil {
+			return diskOptionsError("%s is only supported by gp3 volumes", diskOptionThroughput)
+		}
+	default:
+		if o.IOPS != nil || o.Throughput != nil {
+			return diskOptionsError("%s/%s are not supported by %s volumes", diskOptionIOPS, diskOptionThroughput, o.Type)
+		}
+	}
+	return nil
+}
+
+// parsePositiveInt32 reads a positive integer option value.
+func parsePositiveInt32(key string, raw any) (*int32, error) {
+	var n int64
+	switch v := raw.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, diskOptionsError("%s must be an integer", key)
+		}
+		n = int64(v)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, diskOptionsError("%s must be an integer", key)
+		}
+		n = i
+	default:
+		return nil, diskOptionsError("%s must be an integer", key)
+	}
+	if n <= 0 || n > 1<<31-1 {
+		return nil, diskOptionsError("%s must be a positive integer", key)
+	}
+	return aws.Int32(int32(n)), nil
+}
+
+// diskOptionsError wraps model.ErrVolumeOptionsInvalid with a message.
+func diskOptionsError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", model.ErrVolumeOptionsInvalid, fmt.Sprintf(format, args...))
+}
+
+// setUserMetadataTags stores user labels and description as tags.
+func setUserMetadataTags(tags map[string]string, labels map[string]string, description string) {
+	for k, v := range labels {
+		tags[tagLabelPrefix+k] = v
+	}
+	if description != "" {
+		tags[tagDescription] = description
+	}
+}
+
+// userMetadataFromTags extracts user labels and description from tags.
+// Returns nil labels when none are set.
+func userMetadataFromTags(tags map[string]string) (map[string]string, string) {
+	var labels map[string]string
+	for k, v := range tags {
+		if key, ok := strings.CutPrefix(k, tagLabelPrefix); ok && key != "" {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[key] = v
+		}
+	}
+	return labels, tags[tagDescription]
+}
@@ -0,0 +1,413 @@
+package eks
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// fakeEC2 is a minimal in-memory EC2 Query API (availability zones, EBS volumes and snapshots).
+type fakeEC2 struct {
+	mu        sync.Mutex
+	seq       int
+	volumes   map[string]map[string]string // id -> attributes
+	snapshots map[string]map[string]string // id -> attributes
+	tags      map[string]map[string]string // resource id -> tags
+}
+
+func newFakeEC2() *fakeEC2 {
+	return &fakeEC2{
+		volumes:   map[string]map[string]string{},
+		snapshots: map[string]map[string]string{},
+		tags:      map[string]map[string]string{},
+	}
+}
+
+func (f *fakeEC2) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("%s-%017x", prefix, f.seq)
+}
+
+// indexed collects "<prefix>.N.<suffix>" parameters in order (N starting at 1).
+func indexed(form url.Values, prefix, suffix string) []string {
+	var out []string
+	for i := 1; ; i++ {
+		v, ok := form[fmt.Sprintf("%s.%d%s", prefix, i, suffix)]
+		if !ok {
+			return out
+		}
+		out = append(out, v[0])
+	}
+}
+
+// formTags reads "<prefix>.N.Key/Value" parameters.
+func formTags(form url.Values, prefix string) map[string]string {
+	keys := indexed(form, prefix, ".Key")
+	values := indexed(form, prefix, ".Value")
+	m := map[string]string{}
+	for i, k := range keys {
+		m[k] = values[i]
+	}
+	return m
+}
+
+// matchFilters reports whether the resource tags satisfy the tag:<key> filters of the request.
+func matchFilters(form url.Values, tags map[string]string) bool {
+	for i, name := range indexed(form, "Filter", ".Name") {
+		key, ok := strings.CutPrefix(name, "tag:")
+		if !ok {
+			continue
+		}
+		if tags[key] != form.Get(fmt.Sprintf("Filter.%d.Value.1", i+1)) {
+			return false
+		}
+	}
+	return true
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (f *fakeEC2) tagSet(id string) string {
+	var b strings.Builder
+	b.WriteString("<tagSet>")
+	for k, v := range f.tags[id] {
+		fmt.Fprintf(&b, "<item><key>%s</key><value>%s</value></item>", xmlText(k), xmlText(v))
+	}
+	b.WriteString("</tagSet>")
+	return b.String()
+}
+
+func (f *fakeEC2) volumeXML(id string) string {
+	v := f.volumes[id]
+	var b strings.Builder
+	for _, k := range []string{"volumeId", "size", "availabilityZone", "status", "createTime", "volumeType", "iops", "throughput", "snapshotId"} {
+		if v[k] != "" {
+			fmt.Fprintf(&b, "<%s>%s</%s>", k, xmlText(v[k]), k)
+		}
+	}
+	b.WriteString(f.tagSet(id))
+	return b.String()
+}
+
+func (f *fakeEC2) snapshotXML(id string) string {
+	s := f.snapshots[id]
+	var b strings.Builder
+	for _, k := range []string{"snapshotId", "volumeId", "volumeSize", "status", "startTime"} {
+		fmt.Fprintf(&b, "<%s>%s</%s>", k, xmlText(s[k]), k)
+	}
+	b.WriteString(f.tagSet(id))
+	return b.String()
+}
+
+func (f *fakeEC2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_ = r.ParseForm()
+	form := r.PostForm
+	action := form.Get("Action")
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	reply := func(body string) {
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<%sResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>req</requestId>%s</%sResponse>`, action, body, action)
+	}
+	fail := func(code string) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `<Response><Errors><Error><Code>%s</Code><Message>%s</Message></Error></Errors><RequestID>req</RequestID></Response>`, code, code)
+	}
+
+	switch action {
+	case "DescribeAvailabilityZones":
+		reply(`<availabilityZoneInfo>` +
+			`<item><zoneName>us-east-1a</zoneName><zoneState>available</zoneState></item>` +
+			`<item><zoneName>us-east-1b</zoneName><zoneState>available</zoneState></item>` +
+			`<item><zoneName>us-east-1c</zoneName><zoneState>available</zoneState></item>` +
+			`</availabilityZoneInfo>`)
+	case "CreateVolume":
+		id := f.nextID("vol")
+		size := form.Get("Size")
+		if sid := form.Get("SnapshotId"); sid != "" {
+			s, ok := f.snapshots[sid]
+			if !ok {
+				fail("InvalidSnapshot.NotFound")
+				return
+			}
+			if size == "" {
+				size = s["volumeSize"]
+			}
+		}
+		f.volumes[id] = map[string]string{
+			"volumeId":         id,
+			"size":             size,
+			"availabilityZone": form.Get("AvailabilityZone"),
+			"status":           "available",
+			"createTime":       now,
+			"volumeType":       form.Get("VolumeType"),
+			"iops":             form.Get("Iops"),
+			"throughput":       form.Get("Throughput"),
+			"snapshotId":       form.Get("SnapshotId"),
+		}
+		f.tags[id] = formTags(form, "TagSpecification.1.Tag")
+		reply(f.volumeXML(id))
+	case "DescribeVolumes":
+		ids := indexed(form, "VolumeId", "")
+		var b strings.Builder
+		for id := range f.volumes {
+			if len(ids) > 0 && !strings.Contains(strings.Join(ids, ","), id) {
+				continue
+			}
+			if matchFilters(form, f.tags[id]) {
+				b.WriteString("<item>" + f.volumeXML(id) + "</item>")
+			}
+		}
+		reply("<volumeSet>" + b.String() + "</volumeSet>")
+	case "DeleteVolume":
+		id := form.Get("VolumeId")
+		if _, ok := f.volumes[id]; !ok {
+			fail("InvalidVolume.NotFound")
+			return
+		}
+		delete(f.volumes, id)
+		delete(f.tags, id)
+		reply("<return>true</return>")
+	case "CreateTags":
+		for _, id := range indexed(form, "ResourceId", "") {
+			if f.tags[id] == nil {
+				f.tags[id] = map[string]string{}
+			}
+			for k, v := range formTags(form, "Tag") {
+				f.tags[id][k] = v
+			}
+		}
+		reply("<return>true</return>")
+	case "ModifyVolume":
+		id := form.Get("VolumeId")
+		v, ok := f.volumes[id]
+		if !ok {
+			fail("InvalidVolume.NotFound")
+			return
+		}
+		for param, attr := range map[string]string{"VolumeType": "volumeType", "Iops": "iops", "Throughput": "throughput"} {
+			if s := form.Get(param); s != "" {
+				v[attr] = s
+			}
+		}
+		reply("<volumeModification><volumeId>" + id + "</volumeId><modificationState>modifying</modificationState></volumeModification>")
+	case "DescribeVolumesModifications":
+		var b strings.Builder
+		for _, id := range indexed(form, "VolumeId", "") {
+			fmt.Fprintf(&b, "<item><volumeId>%s</volumeId><modificationState>optimizing</modificationState></item>", id)
+		}
+		reply("<volumeModificationSet>" + b.String() + "</volumeModificationSet>")
+	case "CreateSnapshot":
+		vid := form.Get("VolumeId")
+		v, ok := f.volumes[vid]
+		if !ok {
+			fail("InvalidVolume.NotFound")
+			return
+		}
+		id := f.nextID("snap")
+		f.snapshots[id] = map[string]string{
+			"snapshotId": id,
+			"volumeId":   vid,
+			"volumeSize": v["size"],
+			"status":     "completed",
+			"startTime":  now,
+		}
+		f.tags[id] = formTags(form, "TagSpecification.1.Tag")
+		reply(f.snapshotXML(id))
+	case "DescribeSnapshots":
+		ids := indexed(form, "SnapshotId", "")
+		var b strings.Builder
+		for id := range f.snapshots {
+			if len(ids) > 0 && !strings.Contains(strings.Join(ids, ","), id) {
+				continue
+			}
+			if matchFilters(form, f.tags[id]) {
+				b.WriteString("<item>" + f.snapshotXML(id) + "</item>")
+			}
+		}
+		reply("<snapshotSet>" + b.String() + "</snapshotSet>")
+	case "DeleteSnapshot":
+		id := form.Get("SnapshotId")
+		if _, ok := f.snapshots[id]; !ok {
+			fail("InvalidSnapshot.NotFound")
+			return
+		}
+		delete(f.snapshots, id)
+		delete(f.tags, id)
+		reply("<return>true</return>")
+	default:
+		fail("InvalidAction")
+	}
+}
+
+// newTestDriver returns a driver whose AWS clients talk to the fake server.
+func newTestDriver(t *testing.T, f http.Handler) *driver {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	old := pollInterval
+	pollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollInterval = old })
+
+	d := &driver{
+		workspaceName:  "ws",
+		providerName:   "prv",
+		resourcePrefix: "k4x-test",
+		region:         "us-east-1",
+		awsConfig: aws.Config{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			BaseEndpoint: aws.String(srv.URL),
+		},
+	}
+	d.volumeBackends = map[string]volumeBackend{model.VolumeTypeDisk: newVolumeBackendDisk(d)}
+	return d
+}
+
+func TestVolumeDiskFlow(t *testing.T) {
+	f := newFakeEC2()
+	d := newTestDriver(t, f)
+	ctx := context.Background()
+
+	cluster := &model.Cluster{Name: "cls"}
+	app := &model.App{
+		Name:       "app",
+		Volumes:    []model.AppVolume{{Name: "db", Size: 10 << 30, Options: map[string]any{"iops": 4000}}},
+		Deployment: model.AppDeployment{Zone: "2"},
+	}
+
+	disks, err := d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil || len(disks) != 0 {
+		t.Fatalf("initial list: %v %v", disks, err)
+	}
+
+	disk1, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", "", model.WithVolumeDiskCreateLabels(map[string]string{"env": "test"}))
+	if err != nil {
+		t.Fatalf("create disk1: %v", err)
+	}
+	if disk1.Zone != "us-east-1b" || disk1.Size != 10<<30 || disk1.Options[diskOptionType] != "gp3" || disk1.Options[diskOptionIOPS] != 4000 {
+		t.Errorf("unexpected disk1: %+v", disk1)
+	}
+	if disk1.Labels["env"] != "test" {
+		t.Errorf("labels = %v", disk1.Labels)
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", ""); err == nil {
+		t.Error("expected duplicate disk error")
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "clone", "disk:disk1"); err == nil {
+		t.Error("expected unsupported disk source error")
+	}
+
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+
+	snap, err := d.VolumeSnapshotCreate(ctx, cluster, app, "db", "snap1", "")
+	if err != nil {
+		t.Fatalf("snapshot create: %v", err)
+	}
+	if snap.SourceHandle != disk1.Handle {
+		t.Errorf("snapshot source = %q, want %q", snap.SourceHandle, disk1.Handle)
+	}
+	snaps, err := d.VolumeSnapshotList(ctx, cluster, app, "db")
+	if err != nil || len(snaps) != 1 || snaps[0].Name != "snap1" {
+		t.Fatalf("snapshot list: %v %v", snaps, err)
+	}
+
+	disk2, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk2", "snap1")
+	if err != nil {
+		t.Fatalf("create disk2 from snapshot: %v", err)
+	}
+	if disk2.SourceHandle != snap.Handle {
+		t.Errorf("disk2 source = %q, want %q", disk2.SourceHandle, snap.Handle)
+	}
+
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk2"); err != nil {
+		t.Fatalf("assign disk2: %v", err)
+	}
+	disks, err = d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, disk := range disks {
+		if disk.Assigned != (disk.Name == "disk2") {
+			t.Errorf("disk %s assigned = %v", disk.Name, disk.Assigned)
+		}
+	}
+
+	updated, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"throughput": 250}))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Options[diskOptionThroughput] != 250 {
+		t.Errorf("throughput = %v", updated.Options[diskOptionThroughput])
+	}
+	if _, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"size": 100})); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+	if _, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"type": "st1", "iops": 500})); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid for st1 with iops, got %v", err)
+	}
+
+	if err := d.VolumeSnapshotDelete(ctx, cluster, app, "db", "snap1"); err != nil {
+		t.Fatalf("snapshot delete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("delete missing: %v", err)
+	}
+	disks, err = d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil || len(disks) != 1 || disks[0].Name != "disk2" {
+		t.Fatalf("final list: %v %v", disks, err)
+	}
+}
+
+func TestVolumeClass(t *testing.T) {
+	d := newTestDriver(t, newFakeEC2())
+	ctx := context.Background()
+
+	vc, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "db"})
+	if err != nil {
+		t.Fatalf("VolumeClass: %v", err)
+	}
+	if vc.StorageClassName != "gp3" || vc.CSIDriver != "ebs.csi.aws.com" {
+		t.Errorf("unexpected class: %+v", vc)
+	}
+	invalid := []map[string]any{
+		{"type": "standard"},
+		{"type": "gp2", "iops": 3000},
+		{"type": "io2", "throughput": 500},
+		{"iops": -1},
+	}
+	for _, opts := range invalid {
+		if _, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "db", Options: opts}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+			t.Errorf("options %v: expected ErrVolumeOptionsInvalid, got %v", opts, err)
+		}
+	}
+	if _, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "files", Type: model.VolumeTypeFiles}); err == nil {
+		t.Error("expected unsupported volume type error")
+	}
+}
@@ -0,0 +1,317 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// defaultDNSRecordTTL is the TTL applied when the record set does not specify one.
+const defaultDNSRecordTTL = 300
+
+// ClusterProvision records the cluster as provisioned with the default system and user node pools.
+// Provisioning an already provisioned cluster is a no-op.
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		if cs := st.Clusters[cluster.Name]; cs != nil && cs.Provisioned {
+			return nil
+		}
+		st.Clusters[cluster.Name] = newClusterState(d.zones(cluster))
+		return nil
+	})
+}
+
+// ClusterDeprovision removes the cluster with its node pools. Disks, snapshots and DNS records
+// are provider-scoped and survive, as they do with cloud providers.
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterDeprovision")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		delete(st.Clusters, cluster.Name)
+		return nil
+	})
+}
+
+// ClusterStatus returns the recorded status. Existing clusters are always reported as provisioned.
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	status := &model.ClusterStatus{
+		Existing:    cluster.Existing,
+		Provisioned: cluster.Existing,
+		Installed:   false,
+	}
+	err := d.store.view(func(st *providerState) error {
+		cs := st.Clusters[cluster.Name]
+		if cs == nil {
+			return nil
+		}
+		status.Provisioned = status.Provisioned || cs.Provisioned
+		status.Installed = status.Provisioned && cs.Installed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status.Installed {
+		status.IngressGlobalIP = d.setting(cluster, keyIngressIP)
+		if status.IngressGlobalIP == "" {
+			status.IngressGlobalIP = defaultIngressIP
+		}
+		status.IngressFQDN = d.setting(cluster, keyIngressFQDN)
+	}
+	return status, nil
+}
+
+// ClusterInstall records in-cluster resources as installed. Nothing is applied to the API server
+// so that the in-process cluster or envtest, which have no controllers, can be used as the cluster.
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		cs.Installed = true
+		return nil
+	})
+}
+
+// ClusterUninstall records in-cluster resources as uninstalled.
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUninstall")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		if cs := st.Clusters[cluster.Name]; cs != nil {
+			cs.Installed = false
+		}
+		return nil
+	})
+}
+
+// ClusterKubeconfig returns the kubeconfig configured by FAKE_KUBECONFIG or FAKE_KUBECONFIG_PATH.
+// Without either setting the cluster is served in process (see inProcessHost); its objects
+// are kept in the provider state so that they survive across kompoxops invocations.
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	err := d.store.view(func(st *providerState) error {
+		_, err := st.provisionedCluster(cluster)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if v := d.setting(cluster, keyKubeconfig); v != "" {
+		return kube.DecodeKubeconfig(v), nil
+	}
+	path := d.setting(cluster, keyKubeconfigPath)
+	if path == "" {
+		host := d.inProcessHost(cluster)
+		kube.RegisterInProcessCluster(host, func() (*kube.InProcessOptions, error) {
+			return d.inProcessOptions(cluster)
+		})
+		return kube.InProcessKubeconfig(host, cluster.Name)
+	}
+	path, err = kube.ExpandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// inProcessHost returns the API server URL of the in-process cluster. The host is never
+// resolved; kube clients for it are served by client-go fakes.
+func (d *driver) inProcessHost(cluster *model.Cluster) string {
+	return fmt.Sprintf("https://fake.kompox.invalid/%s/%s/%s", url.PathEscape(d.workspaceName), url.PathEscape(d.providerName), url.PathEscape(cluster.Name))
+}
+
+// inProcessOptions loads the objects of the in-process cluster from the provider state.
+// Ingresses get the ingress IP once the cluster is installed, as a real ingress controller would do.
+func (d *driver) inProcessOptions(cluster *model.Cluster) (*kube.InProcessOptions, error) {
+	opts := &kube.InProcessOptions{}
+	err := d.store.view(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		for key, raw := range cs.Objects {
+			obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(raw, nil, nil)
+			if err != nil {
+				return fmt.Errorf("decode object %s: %w", key, err)
+			}
+			opts.Objects = append(opts.Objects, obj)
+		}
+		if cs.Installed {
+			opts.IngressIP = d.setting(cluster, keyIngressIP)
+			if opts.IngressIP == "" {
+				opts.IngressIP = defaultIngressIP
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts.OnChange = func(key string, obj runtime.Object) error {
+		var raw json.RawMessage
+		if obj != nil {
+			var err error
+			if raw, err = json.Marshal(obj); err != nil {
+				return fmt.Errorf("encode object %s: %w", key, err)
+			}
+		}
+		return d.store.update(func(st *providerState) error {
+			cs, err := st.provisionedCluster(cluster)
+			if err != nil {
+				return err
+			}
+			if raw == nil {
+				delete(cs.Objects, key)
+				return nil
+			}
+			if cs.Objects == nil {
+				cs.Objects = map[string]json.RawMessage{}
+			}
+			cs.Objects[key] = raw
+			return nil
+		})
+	}
+	return opts, nil
+}
+
+// ClusterDNSApply stores or deletes a record set in the simulated DNS zones (FAKE_DNS_ZONES).
+// When no zones are configured any FQDN is accepted.
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	settings := &model.ClusterDNSApplyOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(settings)
+	}
+
+	log := logging.FromContext(ctx)
+
+	if err := normalizeDNSRecordSet(&rset); err != nil {
+		if settings.Strict {
+			return fmt.Errorf("validate DNS record set: %w", err)
+		}
+		log.Warn(ctx, "ClusterDNSApply: invalid input", "error", err.Error())
+		return nil
+	}
+
+	zone, err := selectDNSZone(rset.FQDN, splitList(d.setting(cluster, keyDNSZones)), settings.ZoneHint)
+	if err != nil {
+		if settings.Strict {
+			return fmt.Errorf("select DNS zone: %w", err)
+		}
+		log.Warn(ctx, "ClusterDNSApply: zone resolution failed", "fqdn", rset.FQDN, "error", err.Error())
+		return nil
+	}
+
+	action := "upsert"
+	if len(rset.RData) == 0 {
+		action = "delete"
+	}
+	if settings.DryRun {
+		log.Info(ctx, "ClusterDNSApply: dry-run", "action", action, "zone", zone, "fqdn", rset.FQDN, "type", rset.Type, "rdata", rset.RData)
+		return nil
+	}
+
+	err = d.store.update(func(st *providerState) error {
+		key := rset.FQDN + "/" + string(rset.Type)
+		if len(rset.RData) == 0 {
+			delete(st.DNSRecords, key)
+			return nil
+		}
+		st.DNSRecords[key] = &dnsRecord{Zone: zone, FQDN: rset.FQDN, Type: string(rset.Type), TTL: rset.TTL, RData: rset.RData}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s DNS record: %w", action, err)
+	}
+	log.Info(ctx, "ClusterDNSApply: applied", "action", action, "zone", zone, "fqdn", rset.FQDN, "type", rset.Type)
+	return nil
+}
+
+// newClusterState returns a provisioned cluster with the default system and user node pools
+// spanning zones (no zones for existing clusters, whose pools are not known).
+func newClusterState(zones []string) *clusterState {
+	return &clusterState{
+		Provisioned: true,
+		NodePools: map[string]*nodePoolRecord{
+			"system": {Name: "system", Mode: "system", InstanceType: defaultInstanceType, Priority: "regular", Zones: zones, Count: 1},
+			"user":   {Name: "user", Mode: "user", InstanceType: defaultInstanceType, Priority: "regular", Zones: zones, Count: 1},
+		},
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// normalizeDNSRecordSet validates the record set and normalizes FQDN (lowercase, no trailing dot) and TTL.
+func normalizeDNSRecordSet(rset *model.DNSRecordSet) error {
+	rset.FQDN = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(rset.FQDN), "."))
+	if rset.FQDN == "" {
+		return fmt.Errorf("FQDN is required")
+	}
+	switch rset.Type {
+	case model.DNSRecordTypeA, model.DNSRecordTypeAAAA, model.DNSRecordTypeCNAME, model.DNSRecordTypeTXT:
+	default:
+		return fmt.Errorf("unsupported DNS record type: %s", rset.Type)
+	}
+	if rset.Type == model.DNSRecordTypeCNAME && len(rset.RData) > 1 {
+		return fmt.Errorf("CNAME record must have exactly one RData entry, got %d", len(rset.RData))
+	}
+	if rset.TTL == 0 {
+		rset.TTL = defaultDNSRecordTTL
+	}
+	return nil
+}
+
+// selectDNSZone returns the zone for fqdn: the hinted zone if given, otherwise the longest
+// matching zone. It returns an empty zone when no zones are configured.
+func selectDNSZone(fqdn string, zones []string, hint string) (string, error) {
+	if len(zones) == 0 {
+		return "", nil
+	}
+	inZone := func(zone string) bool { return fqdn == zone || strings.HasSuffix(fqdn, "."+zone) }
+	if hint = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(hint), ".")); hint != "" {
+		for _, z := range zones {
+			if strings.EqualFold(strings.TrimSuffix(z, "."), hint) {
+				if !inZone(hint) {
+					return "", fmt.Errorf("FQDN %s is not in zone %s", fqdn, hint)
+				}
+				return hint, nil
+			}
+		}
+		return "", fmt.Errorf("zone hint %s does not match configured zones", hint)
+	}
+	best := ""
+	for _, z := range zones {
+		z = strings.ToLower(strings.TrimSuffix(z, "."))
+		if inZone(z) && len(z) > len(best) {
+			best = z
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no zone found for FQDN %s", fqdn)
+	}
+	return best, nil
+}
@@ -0,0 +1,313 @@
+package fake
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestDriver returns a fake driver whose state is kept in a temporary state file.
+func newTestDriver(t *testing.T, settings map[string]string) *driver {
+	t.Helper()
+	if settings == nil {
+		settings = map[string]string{}
+	}
+	if _, ok := settings[keyStateFile]; !ok {
+		settings[keyStateFile] = filepath.Join(t.TempDir(), "state.json")
+	}
+	factory, ok := providerdrv.GetDriverFactory("fake")
+	if !ok {
+		t.Fatal("fake driver not registered")
+	}
+	drv, err := factory(&model.Workspace{Name: "ws"}, &model.Provider{Name: "prv", Driver: "fake", Settings: settings})
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	return drv.(*driver)
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	ctx := context.Background()
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte("apiVersion: v1\nkind: Config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDriver(t, map[string]string{keyKubeconfigPath: kubeconfig})
+	cluster := &model.Cluster{Name: "cls1", Settings: map[string]string{keyIngressFQDN: "ingress.example.com"}}
+
+	if _, err := d.ClusterKubeconfig(ctx, cluster); err == nil {
+		t.Error("expected kubeconfig error before provisioning")
+	}
+	if err := d.ClusterInstall(ctx, cluster); err == nil {
+		t.Error("expected install error before provisioning")
+	}
+
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	if err := d.ClusterInstall(ctx, cluster); err != nil {
+		t.Fatalf("ClusterInstall: %v", err)
+	}
+
+	// State is shared by driver instances using the same state file
+	other := newTestDriver(t, map[string]string{keyStateFile: d.settings[keyStateFile], keyIngressIP: "198.51.100.7"})
+	status, err := other.ClusterStatus(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterStatus: %v", err)
+	}
+	if !status.Provisioned || !status.Installed || status.IngressGlobalIP != "198.51.100.7" || status.IngressFQDN != "ingress.example.com" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	data, err := d.ClusterKubeconfig(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterKubeconfig: %v", err)
+	}
+	if !strings.Contains(string(data), "kind: Config") {
+		t.Errorf("unexpected kubeconfig: %q", data)
+	}
+
+	if err := d.ClusterDeprovision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterDeprovision: %v", err)
+	}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterStatus: %v", err)
+	}
+	if status.Provisioned || status.Installed || status.IngressGlobalIP != "" {
+		t.Errorf("unexpected status after deprovision: %+v", status)
+	}
+
+	existing := &model.Cluster{Name: "ext", Existing: true}
+	if err := d.ClusterInstall(ctx, existing); err != nil {
+		t.Fatalf("ClusterInstall existing: %v", err)
+	}
+	if status, _ := d.ClusterStatus(ctx, existing); !status.Provisioned || !status.Installed || status.IngressGlobalIP != defaultIngressIP {
+		t.Errorf("unexpected existing cluster status: %+v", status)
+	}
+}
+
+func TestClusterKubeconfigInProcess(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	if err := d.ClusterInstall(ctx, cluster); err != nil {
+		t.Fatalf("ClusterInstall: %v", err)
+	}
+	kubeconfig, err := d.ClusterKubeconfig(ctx, cluster)
+	if err != nil || !strings.Contains(string(kubeconfig), d.inProcessHost(cluster)) {
+		t.Fatalf("ClusterKubeconfig = %s, %v", kubeconfig, err)
+	}
+
+	kc, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromKubeconfig: %v", err)
+	}
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns1", Labels: map[string]string{"app": "web"}},
+		Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "web.example.com"}}},
+	}
+	if _, err := kc.Clientset.NetworkingV1().Ingresses("ns1").Create(ctx, ing, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create ingress: %v", err)
+	}
+
+	// Objects live in the provider state, so a fresh driver and client see them.
+	d2 := newTestDriver(t, map[string]string{keyStateFile: d.settings[keyStateFile]})
+	if kubeconfig, err = d2.ClusterKubeconfig(ctx, cluster); err != nil {
+		t.Fatalf("ClusterKubeconfig: %v", err)
+	}
+	if kc, err = kube.NewClientFromKubeconfig(ctx, kubeconfig, nil); err != nil {
+		t.Fatalf("NewClientFromKubeconfig: %v", err)
+	}
+	hosts, err := kc.IngressHostIPs(ctx, "ns1", "app=web")
+	if err != nil || len(hosts) != 1 || hosts[0].IP != defaultIngressIP {
+		t.Errorf("IngressHostIPs = %+v, %v", hosts, err)
+	}
+
+	if err := d.ClusterDeprovision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterDeprovision: %v", err)
+	}
+	if _, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, nil); err == nil {
+		t.Errorf("expected error for deprovisioned in-process cluster")
+	}
+}
+
+func TestClusterDNSApply(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, map[string]string{keyDNSZones: "example.com, sub.example.com"})
+	cluster := &model.Cluster{Name: "cls1"}
+
+	records := func() map[string]*dnsRecord {
+		var out map[string]*dnsRecord
+		if err := d.store.view(func(st *providerState) error { out = st.DNSRecords; return nil }); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	rset := model.DNSRecordSet{FQDN: "App.Sub.Example.com.", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}}
+	if err := d.ClusterDNSApply(ctx, cluster, rset, model.WithClusterDNSApplyStrict()); err != nil {
+		t.Fatalf("ClusterDNSApply: %v", err)
+	}
+	got := records()["app.sub.example.com/A"]
+	if got == nil || got.Zone != "sub.example.com" || got.TTL != defaultDNSRecordTTL || got.RData[0] != "192.0.2.1" {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+
+	dryRun := model.DNSRecordSet{FQDN: "dry.example.com", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.2"}}
+	if err := d.ClusterDNSApply(ctx, cluster, dryRun, model.WithClusterDNSApplyDryRun()); err != nil {
+		t.Fatalf("ClusterDNSApply dry-run: %v", err)
+	}
+	if _, ok := records()["dry.example.com/A"]; ok {
+		t.Error("dry-run must not store records")
+	}
+
+	outside := model.DNSRecordSet{FQDN: "app.example.net", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}}
+	if err := d.ClusterDNSApply(ctx, cluster, outside); err != nil {
+		t.Errorf("non-strict apply must not fail: %v", err)
+	}
+	if err := d.ClusterDNSApply(ctx, cluster, outside, model.WithClusterDNSApplyStrict()); err == nil {
+		t.Error("expected strict error for FQDN outside zones")
+	}
+
+	rset.RData = nil
+	if err := d.ClusterDNSApply(ctx, cluster, rset, model.WithClusterDNSApplyStrict()); err != nil {
+		t.Fatalf("ClusterDNSApply delete: %v", err)
+	}
+	if len(records()) != 0 {
+		t.Errorf("records not deleted: %v", records())
+	}
+}
+
+func TestSelectDNSZone(t *testing.T) {
+	zones := []string{"example.com", "sub.example.com."}
+	tests := []struct {
+		fqdn, hint, want string
+		wantErr          bool
+	}{
+		{fqdn: "a.sub.example.com", want: "sub.example.com"},
+		{fqdn: "a.example.com", want: "example.com"},
+		{fqdn: "a.sub.example.com", hint: "example.com", want: "example.com"},
+		{fqdn: "a.example.com", hint: "sub.example.com", wantErr: true},
+		{fqdn: "a.example.com", hint: "other.com", wantErr: true},
+		{fqdn: "a.example.net", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := selectDNSZone(tt.fqdn, zones, tt.hint)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("selectDNSZone(%q, %q) = %q, %v; want %q, err=%v", tt.fqdn, tt.hint, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if got, err := selectDNSZone("a.example.net", nil, ""); err != nil || got != "" {
+		t.Errorf("selectDNSZone without zones = %q, %v", got, err)
+	}
+}
+
+func TestClusterPlan(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+	actions := func(op model.ClusterPlanOperation, opts ...model.ClusterPlanOption) map[string]model.ClusterChangeAction {
+		t.Helper()
+		plan, err := d.ClusterPlan(ctx, cluster, op, opts...)
+		if err != nil {
+			t.Fatalf("ClusterPlan(%s): %v", op, err)
+		}
+		out := map[string]model.ClusterChangeAction{}
+		for _, c := range plan.Changes {
+			out[c.Kind+":"+c.Resource] = c.Action
+		}
+		return out
+	}
+
+	if got := actions(model.ClusterPlanProvision); got["Cluster:cls1"] != model.ClusterChangeCreate || got["NodePool:cls1/user"] != model.ClusterChangeCreate {
+		t.Errorf("provision plan = %v", got)
+	}
+	if _, err := d.ClusterPlan(ctx, cluster, model.ClusterPlanInstall); err == nil {
+		t.Error("expected install plan error before provisioning")
+	}
+	status, err := d.ClusterStatus(ctx, cluster)
+	if err != nil || status.Provisioned {
+		t.Fatalf("plan must not provision: %+v, %v", status, err)
+	}
+
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	if got := actions(model.ClusterPlanProvision); got["Cluster:cls1"] != model.ClusterChangeNoOp {
+		t.Errorf("provision plan after provision = %v", got)
+	}
+	if got := actions(model.ClusterPlanInstall); got["IngressController:cls1"] != model.ClusterChangeCreate {
+		t.Errorf("install plan = %v", got)
+	}
+	if err := d.ClusterInstall(ctx, cluster); err != nil {
+		t.Fatalf("ClusterInstall: %v", err)
+	}
+	if got := actions(model.ClusterPlanInstall, model.WithClusterPlanForce()); got["IngressController:cls1"] != model.ClusterChangeUpdate {
+		t.Errorf("forced install plan = %v", got)
+	}
+	if got := actions(model.ClusterPlanUninstall); got["IngressController:cls1"] != model.ClusterChangeDelete {
+		t.Errorf("uninstall plan = %v", got)
+	}
+	if got := actions(model.ClusterPlanDeprovision); got["Cluster:cls1"] != model.ClusterChangeDelete || got["NodePool:cls1/system"] != model.ClusterChangeDelete {
+		t.Errorf("deprovision plan = %v", got)
+	}
+}
+
+func TestClusterUpgrade(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, map[string]string{keyK8sVersions: "1.32.5,1.33.2,1.34.0", keyNodeImage: "img-2"})
+	cluster := &model.Cluster{Name: "cls1"}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	versions, err := d.ClusterVersions(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterVersions: %v", err)
+	}
+	if versions.KubernetesVersion != "1.32.5" || strings.Join(versions.Upgrades, ",") != "1.33.2,1.34.0" || len(versions.NodePools) != 2 {
+		t.Fatalf("versions = %+v", versions)
+	}
+	if p := versions.NodePools[1]; p.Name != "user" || p.NodeImageVersion != defaultNodeImage || !p.NodeImageUpgradable() {
+		t.Errorf("user pool versions = %+v", p)
+	}
+
+	if err := d.NodePoolUpgrade(ctx, cluster, "user", model.WithNodePoolUpgradeKubernetesVersion("1.33.2")); err == nil {
+		t.Error("expected error upgrading pool beyond the control plane")
+	}
+	if err := d.ClusterUpgrade(ctx, cluster, "1.33.2"); err != nil {
+		t.Fatalf("ClusterUpgrade: %v", err)
+	}
+	if err := d.ClusterUpgrade(ctx, cluster, "1.32.5"); err == nil {
+		t.Error("expected error downgrading the control plane")
+	}
+	if err := d.NodePoolUpgrade(ctx, cluster, "user", model.WithNodePoolUpgradeKubernetesVersion("1.33.2")); err != nil {
+		t.Fatalf("NodePoolUpgrade version: %v", err)
+	}
+	if err := d.NodePoolUpgrade(ctx, cluster, "system", model.WithNodePoolUpgradeNodeImage()); err != nil {
+		t.Fatalf("NodePoolUpgrade image: %v", err)
+	}
+
+	versions, err = d.ClusterVersions(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterVersions: %v", err)
+	}
+	got := map[string]string{}
+	for _, p := range versions.NodePools {
+		got[p.Name] = p.KubernetesVersion + "/" + p.NodeImageVersion
+	}
+	if versions.KubernetesVersion != "1.33.2" || got["system"] != "1.32.5/img-2" || got["user"] != "1.33.2/img-2" {
+		t.Errorf("versions after upgrade = %s %v", versions.KubernetesVersion, got)
+	}
+}
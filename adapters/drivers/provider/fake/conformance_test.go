@@ -0,0 +1,51 @@
+package fake
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/drivers/provider/conformance"
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestConformance(t *testing.T) {
+	provider := &model.Provider{Name: "prv", Driver: "fake", Settings: map[string]string{
+		keyStateFile:  filepath.Join(t.TempDir(), "state.json"),
+		keyDNSZones:   "example.com",
+		keyIngressIP:  "192.0.2.10",
+		keyKubeconfig: "apiVersion: v1\nkind: Config\n",
+	}}
+	workspace := &model.Workspace{Name: "ws"}
+	store := newStore(provider.Settings[keyStateFile], workspace.Name+"/"+provider.Name)
+
+	report := conformance.Run(t, conformance.Fixture{
+		Workspace:       workspace,
+		Provider:        provider,
+		Cluster:         &model.Cluster{Name: "cls1"},
+		App:             &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30}}},
+		VolumeName:      "db",
+		NodePool:        &model.NodePool{Zones: &[]string{"1"}},
+		ImmutableChange: model.NodePool{InstanceType: ptr("fake-large"), Zones: &[]string{"2"}},
+		DNSRecord:       model.DNSRecordSet{FQDN: "www.example.com", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.10"}},
+		LookupDNS: func(ctx context.Context, fqdn string, rtype model.DNSRecordType) (rdata []string, found bool, err error) {
+			err = store.view(func(st *providerState) error {
+				if r := st.DNSRecords[fqdn+"/"+string(rtype)]; r != nil {
+					rdata, found = slices.Clone(r.RData), true
+				}
+				return nil
+			})
+			return rdata, found, err
+		},
+	})
+	report.Require(t,
+		conformance.CapClusterProvision,
+		conformance.CapVolumeDisk,
+		conformance.CapVolumeSnapshot,
+		conformance.CapVolumeInventory,
+		conformance.CapNodePoolList,
+		conformance.CapNodePoolMutate,
+		conformance.CapDNSApply,
+	)
+}
@@ -0,0 +1,130 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// Setting keys. Each key may be set in Provider settings and overridden per Cluster,
+// except keyStateFile which is read from Provider settings only.
+const (
+	keyStateFile      = "FAKE_STATE_FILE"          // JSON state file path; empty keeps state in process memory
+	keyKubeconfig     = "FAKE_KUBECONFIG"          // embedded kubeconfig (YAML or base64-encoded YAML)
+	keyKubeconfigPath = "FAKE_KUBECONFIG_PATH"     // kubeconfig file path (e.g., envtest or kind)
+	keyIngressIP      = "FAKE_INGRESS_IP"          // ingress global IP reported by ClusterStatus and in-process Ingresses
+	keyIngressFQDN    = "FAKE_INGRESS_FQDN"        // ingress FQDN reported by ClusterStatus
+	keyZones          = "FAKE_ZONES"               // comma-separated availability zones
+	keyDNSZones       = "FAKE_DNS_ZONES"           // comma-separated DNS zone names; empty accepts any FQDN
+	keyStorageClass   = "FAKE_STORAGE_CLASS"       // storage class name returned by VolumeClass
+	keyK8sVersions    = "FAKE_KUBERNETES_VERSIONS" // comma-separated Kubernetes versions in ascending order
+	keyNodeImage      = "FAKE_NODE_IMAGE_VERSION"  // latest node image version
+)
+
+// Defaults of simulated cloud attributes.
+const (
+	defaultIngressIP = "192.0.2.1" // TEST-NET-1 (RFC 5737)
+	defaultZones     = "1,2,3"
+	defaultVersions  = "1.32.5,1.33.2"
+	defaultNodeImage = "fake-node-image-1"
+)
+
+// driver implements a provider driver that simulates clusters, disks, snapshots, node pools
+// and DNS records without any cloud. State is kept in process memory or in a local JSON file
+// so that kompoxops flows can be exercised at unit-test speed. Cluster access uses the
+// kubeconfig from settings (e.g., envtest or kind) or, by default, an in-process cluster
+// backed by client-go fakes.
+type driver struct {
+	workspaceName string
+	providerName  string
+	settings      map[string]string // provider settings; cluster settings take precedence (see setting)
+	store         *store
+}
+
+// ID returns the provider identifier.
+func (d *driver) ID() string { return "fake" }
+
+// WorkspaceName returns the workspace name associated with this driver instance.
+func (d *driver) WorkspaceName() string { return d.workspaceName }
+
+// ProviderName returns the provider name associated with this driver instance.
+func (d *driver) ProviderName() string { return d.providerName }
+
+// Capabilities returns the operations supported by the fake driver.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Provision: true, Existing: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk:  {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true, Update: true, Copy: true},
+			model.VolumeTypeFiles: {AccessModes: []string{"ReadWriteMany"}, Snapshot: true, Update: true},
+		},
+		VolumeInventory: true,
+		NodePool:        model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true},
+		DNS:             true,
+		Identity:        true,
+	}
+}
+
+// setting returns the cluster setting for key, falling back to the provider setting.
+func (d *driver) setting(cluster *model.Cluster, key string) string {
+	if cluster != nil && cluster.Settings != nil {
+		if v := strings.TrimSpace(cluster.Settings[key]); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(d.settings[key])
+}
+
+// zones returns the availability zones simulated for the cluster.
+func (d *driver) zones(cluster *model.Cluster) []string {
+	v := d.setting(cluster, keyZones)
+	if v == "" {
+		v = defaultZones
+	}
+	return splitList(v)
+}
+
+// validateZone checks that zone is one of the simulated availability zones.
+func (d *driver) validateZone(cluster *model.Cluster, zone string) error {
+	for _, z := range d.zones(cluster) {
+		if z == zone {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown zone %q (available: %s)", zone, strings.Join(d.zones(cluster), ","))
+}
+
+// splitList splits a comma or space separated setting value.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// init registers the fake driver.
+func init() {
+	providerdrv.Register("fake", func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+		// Determine WorkspaceName
+		workspaceName := "(nil)"
+		if workspace != nil {
+			workspaceName = workspace.Name
+		}
+
+		if provider.Settings != nil && provider.Settings["disabled"] == "true" {
+			return nil, fmt.Errorf("fake provider disabled by settings")
+		}
+		return &driver{
+			workspaceName: workspaceName,
+			providerName:  provider.Name,
+			settings:      provider.Settings,
+			store:         newStore(strings.TrimSpace(provider.Settings[keyStateFile]), workspaceName+"/"+provider.Name),
+		}, nil
+	})
+}
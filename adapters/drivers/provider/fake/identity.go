@@ -0,0 +1,75 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// Metadata the fake driver asks the converter to add for workload identities. Nothing in the
+// cluster acts on them; they let tests observe the binding.
+const (
+	identityClientIDAnnotation = "fake.kompox.dev/client-id"
+	identityUseLabel           = "fake.kompox.dev/use-identity"
+)
+
+// AppIdentityApply implements providerdrv.AppIdentityManager. It records one identity per app
+// with the federated subjects and granted roles. Client and principal IDs are derived from the
+// app ID hash so that they are stable across runs.
+func (d *driver) AppIdentityApply(ctx context.Context, cluster *model.Cluster, app *model.App, subject model.WorkloadIdentitySubject) (wi *model.WorkloadIdentity, err error) {
+	if app == nil || app.Identity == nil {
+		return nil, fmt.Errorf("app has no identity")
+	}
+	if subject.Namespace == "" || subject.ServiceAccount == "" {
+		return nil, fmt.Errorf("identity subject requires namespace and service account")
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "AppIdentityApply")
+	defer func() { cleanup(err) }()
+
+	appIDHash := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name).AppID
+	err = d.store.update(func(st *providerState) error {
+		if _, err := st.provisionedCluster(cluster); err != nil {
+			return err
+		}
+		rec := st.Identities[appIDHash]
+		if rec == nil {
+			rec = &identityRecord{
+				AppName:     app.Name,
+				ClientID:    uuid.NewSHA1(uuid.NameSpaceURL, []byte(handlePrefix+"identity/client/"+appIDHash)).String(),
+				PrincipalID: uuid.NewSHA1(uuid.NameSpaceURL, []byte(handlePrefix+"identity/principal/"+appIDHash)).String(),
+			}
+			st.Identities[appIDHash] = rec
+		}
+		sub := fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.ServiceAccount)
+		if !slices.Contains(rec.Subjects, sub) {
+			rec.Subjects = append(rec.Subjects, sub)
+		}
+		for _, r := range app.Identity.Roles {
+			if strings.TrimSpace(r.Scope) == "" || strings.TrimSpace(r.Role) == "" {
+				return fmt.Errorf("identity role requires scope and role")
+			}
+			role := identityRole{Scope: r.Scope, Role: r.Role}
+			if !slices.Contains(rec.Roles, role) {
+				rec.Roles = append(rec.Roles, role)
+			}
+		}
+		wi = &model.WorkloadIdentity{
+			ClientID:                  rec.ClientID,
+			PrincipalID:               rec.PrincipalID,
+			ResourceID:                handlePrefix + "identity/" + appIDHash,
+			ServiceAccountAnnotations: map[string]string{identityClientIDAnnotation: rec.ClientID},
+			PodLabels:                 map[string]string{identityUseLabel: "true"},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wi, nil
+}
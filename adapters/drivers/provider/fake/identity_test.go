@@ -0,0 +1,55 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestAppIdentityApply(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1", Existing: true}
+	app := &model.App{Name: "app1", Identity: &model.AppIdentity{Roles: []model.AppIdentityRole{{Scope: "/kv/1", Role: "Key Vault Secrets User"}}}}
+	subject := model.WorkloadIdentitySubject{Namespace: "k4x-ns1", ServiceAccount: "app1"}
+
+	wi, err := d.AppIdentityApply(ctx, cluster, app, subject)
+	if err != nil {
+		t.Fatalf("AppIdentityApply: %v", err)
+	}
+	if wi.ClientID == "" || wi.ServiceAccountAnnotations[identityClientIDAnnotation] != wi.ClientID || wi.PodLabels[identityUseLabel] != "true" {
+		t.Errorf("unexpected identity: %+v", wi)
+	}
+
+	// A second namespace (another cluster) federates the same identity.
+	wi2, err := d.AppIdentityApply(ctx, cluster, app, model.WorkloadIdentitySubject{Namespace: "k4x-ns2", ServiceAccount: "app1"})
+	if err != nil {
+		t.Fatalf("AppIdentityApply again: %v", err)
+	}
+	if wi2.ClientID != wi.ClientID {
+		t.Errorf("client ID changed: %s -> %s", wi.ClientID, wi2.ClientID)
+	}
+	err = d.store.view(func(st *providerState) error {
+		for _, rec := range st.Identities {
+			if len(rec.Subjects) != 2 || len(rec.Roles) != 1 {
+				t.Errorf("unexpected identity record: %+v", rec)
+			}
+		}
+		if len(st.Identities) != 1 {
+			t.Errorf("expected 1 identity, got %d", len(st.Identities))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Identity.Roles = append(app.Identity.Roles, model.AppIdentityRole{Scope: "/kv/2"})
+	if _, err := d.AppIdentityApply(ctx, cluster, app, subject); err == nil {
+		t.Error("expected error for a role without name")
+	}
+	if _, err := d.AppIdentityApply(ctx, &model.Cluster{Name: "missing"}, app, subject); err == nil {
+		t.Error("expected error for an unprovisioned cluster")
+	}
+}
@@ -0,0 +1,259 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// defaultInstanceType is the instance type of node pools created without one.
+const defaultInstanceType = "fake-standard"
+
+// NodePoolList returns the node pools of the simulated cluster sorted by name.
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) (pools []*model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolList")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolListOptions(opts...)
+
+	err = d.store.view(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		names := slices.Sorted(maps.Keys(cs.NodePools))
+		for _, name := range names {
+			if o.Name != "" && name != o.Name {
+				continue
+			}
+			pools = append(pools, cs.NodePools[name].toModel())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// NodePoolCreate adds a node pool. Zones must be simulated zones (FAKE_ZONES).
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolCreateOption) (created *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolCreate")
+	defer func() { cleanup(err) }()
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("node pool name is required")
+	}
+	rec := &nodePoolRecord{Name: *pool.Name, Mode: "user", InstanceType: defaultInstanceType, Priority: "regular", Count: 1}
+	if pool.Mode != nil && *pool.Mode != "" {
+		rec.Mode = *pool.Mode
+	}
+	if rec.Mode != "system" && rec.Mode != "user" {
+		return nil, fmt.Errorf("invalid node pool mode %q (expected system or user)", rec.Mode)
+	}
+	if pool.InstanceType != nil && *pool.InstanceType != "" {
+		rec.InstanceType = *pool.InstanceType
+	}
+	if pool.OSDiskType != nil {
+		rec.OSDiskType = *pool.OSDiskType
+	}
+	if pool.OSDiskSizeGiB != nil {
+		rec.OSDiskSizeGiB = *pool.OSDiskSizeGiB
+	}
+	if pool.Priority != nil && *pool.Priority != "" {
+		rec.Priority = *pool.Priority
+	}
+	if rec.Priority != "regular" && rec.Priority != "spot" {
+		return nil, fmt.Errorf("invalid node pool priority %q (expected regular or spot)", rec.Priority)
+	}
+	if pool.Zones != nil {
+		for _, z := range *pool.Zones {
+			if err := d.validateZone(cluster, z); err != nil {
+				return nil, err
+			}
+		}
+		rec.Zones = slices.Clone(*pool.Zones)
+	}
+	if pool.Labels != nil {
+		rec.Labels = maps.Clone(*pool.Labels)
+	}
+	if err := rec.applyAutoscaling(pool.Autoscaling); err != nil {
+		return nil, err
+	}
+
+	err = d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		if _, ok := cs.NodePools[rec.Name]; ok {
+			return fmt.Errorf("node pool %q already exists", rec.Name)
+		}
+		rec.KubernetesVersion, rec.NodeImageVersion = d.clusterVersion(cluster, cs), d.nodeImage(cluster)
+		cs.NodePools[rec.Name] = rec
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec.toModel(), nil
+}
+
+// NodePoolUpdate changes labels and scaling of an existing node pool. Other fields are immutable.
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolUpdateOption) (updated *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolUpdate")
+	defer func() { cleanup(err) }()
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("node pool name is required")
+	}
+	err = d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		rec := cs.NodePools[*pool.Name]
+		if rec == nil {
+			return fmt.Errorf("node pool %q not found", *pool.Name)
+		}
+		if err := validateImmutableFields(pool, rec); err != nil {
+			return err
+		}
+		if pool.Labels != nil {
+			rec.Labels = maps.Clone(*pool.Labels)
+		}
+		if pool.Autoscaling != nil {
+			if err := rec.applyAutoscaling(pool.Autoscaling); err != nil {
+				return err
+			}
+		}
+		updated = rec.toModel()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// NodePoolDelete removes a node pool. The last system pool cannot be removed.
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, _ ...model.NodePoolDeleteOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolDelete")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		rec := cs.NodePools[poolName]
+		if rec == nil {
+			return nil
+		}
+		if rec.Mode == "system" {
+			systems := 0
+			for _, p := range cs.NodePools {
+				if p.Mode == "system" {
+					systems++
+				}
+			}
+			if systems == 1 {
+				return fmt.Errorf("cannot delete the last system node pool %q", poolName)
+			}
+		}
+		delete(cs.NodePools, poolName)
+		return nil
+	})
+}
+
+// applyAutoscaling sets scaling parameters. Without autoscaling the pool runs Desired nodes;
+// with autoscaling the node count is the minimum.
+func (rec *nodePoolRecord) applyAutoscaling(as *model.NodePoolAutoscaling) error {
+	if as == nil {
+		return nil
+	}
+	if as.Enabled {
+		if as.Min < 0 || as.Max < as.Min || as.Max == 0 {
+			return fmt.Errorf("invalid autoscaling range min=%d max=%d", as.Min, as.Max)
+		}
+		rec.Autoscaling, rec.Min, rec.Max = true, as.Min, as.Max
+		rec.Count = min(max(rec.Count, as.Min), as.Max)
+		return nil
+	}
+	rec.Autoscaling, rec.Min, rec.Max = false, 0, 0
+	if as.Desired != nil {
+		if *as.Desired < 0 {
+			return fmt.Errorf("invalid desired node count %d", *as.Desired)
+		}
+		rec.Count = *as.Desired
+	}
+	return nil
+}
+
+// validateImmutableFields reports all immutable fields that pool tries to change.
+func validateImmutableFields(pool model.NodePool, rec *nodePoolRecord) error {
+	var errs []error
+	changed := func(field string, requested *string, current string) {
+		if requested != nil && *requested != current {
+			errs = append(errs, fmt.Errorf("%s is immutable (current %q, requested %q)", field, current, *requested))
+		}
+	}
+	changed("Mode", pool.Mode, rec.Mode)
+	changed("InstanceType", pool.InstanceType, rec.InstanceType)
+	changed("OSDiskType", pool.OSDiskType, rec.OSDiskType)
+	changed("Priority", pool.Priority, rec.Priority)
+	if pool.OSDiskSizeGiB != nil && *pool.OSDiskSizeGiB != rec.OSDiskSizeGiB {
+		errs = append(errs, fmt.Errorf("OSDiskSizeGiB is immutable (current %d, requested %d)", rec.OSDiskSizeGiB, *pool.OSDiskSizeGiB))
+	}
+	if pool.Zones != nil {
+		requested, current := slices.Clone(*pool.Zones), slices.Clone(rec.Zones)
+		sort.Strings(requested)
+		sort.Strings(current)
+		if !slices.Equal(requested, current) {
+			errs = append(errs, fmt.Errorf("Zones is immutable (current %v, requested %v)", rec.Zones, *pool.Zones))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// toModel converts the record to the common node pool model.
+func (rec *nodePoolRecord) toModel() *model.NodePool {
+	labels := maps.Clone(rec.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	pool := &model.NodePool{
+		Name:         ptr(rec.Name),
+		ProviderName: ptr(rec.Name),
+		Mode:         ptr(rec.Mode),
+		Labels:       &labels,
+		InstanceType: ptr(rec.InstanceType),
+		Priority:     ptr(rec.Priority),
+		Status: &model.NodePoolStatus{
+			ProvisioningState: ptr("Succeeded"),
+			CurrentNodeCount:  ptr(rec.Count),
+		},
+	}
+	if rec.OSDiskType != "" {
+		pool.OSDiskType = ptr(rec.OSDiskType)
+	}
+	if rec.OSDiskSizeGiB != 0 {
+		pool.OSDiskSizeGiB = ptr(rec.OSDiskSizeGiB)
+	}
+	if len(rec.Zones) > 0 {
+		pool.Zones = ptr(slices.Clone(rec.Zones))
+	}
+	if rec.Autoscaling {
+		pool.Autoscaling = &model.NodePoolAutoscaling{Enabled: true, Min: rec.Min, Max: rec.Max}
+	} else {
+		pool.Autoscaling = &model.NodePoolAutoscaling{Desired: ptr(rec.Count)}
+	}
+	return pool
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,82 @@
+package fake
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestNodePoolCRUD(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+
+	if _, err := d.NodePoolList(ctx, cluster); err == nil {
+		t.Error("expected error before provisioning")
+	}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+
+	pools, err := d.NodePoolList(ctx, cluster)
+	if err != nil {
+		t.Fatalf("NodePoolList: %v", err)
+	}
+	if len(pools) != 2 || *pools[0].Name != "system" || *pools[1].Name != "user" {
+		t.Fatalf("unexpected default pools: %d", len(pools))
+	}
+
+	three := 3
+	created, err := d.NodePoolCreate(ctx, cluster, model.NodePool{
+		Name:        ptr("spot"),
+		Priority:    ptr("spot"),
+		Zones:       &[]string{"1", "3"},
+		Autoscaling: &model.NodePoolAutoscaling{Desired: &three},
+	})
+	if err != nil {
+		t.Fatalf("NodePoolCreate: %v", err)
+	}
+	if *created.Mode != "user" || *created.Status.CurrentNodeCount != 3 || len(*created.Zones) != 2 {
+		t.Errorf("unexpected pool: %+v", created)
+	}
+	if _, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: ptr("spot")}); err == nil {
+		t.Error("expected duplicate pool error")
+	}
+	if _, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: ptr("bad"), Zones: &[]string{"4"}}); err == nil {
+		t.Error("expected unknown zone error")
+	}
+
+	updated, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{
+		Name:        ptr("spot"),
+		Zones:       &[]string{"3", "1"},
+		Labels:      &map[string]string{"team": "a"},
+		Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 1, Max: 2},
+	})
+	if err != nil {
+		t.Fatalf("NodePoolUpdate: %v", err)
+	}
+	if !updated.Autoscaling.Enabled || *updated.Status.CurrentNodeCount != 2 || (*updated.Labels)["team"] != "a" {
+		t.Errorf("unexpected updated pool: %+v", updated)
+	}
+	_, err = d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: ptr("spot"), InstanceType: ptr("big"), Priority: ptr("regular")})
+	if err == nil || !strings.Contains(err.Error(), "InstanceType") || !strings.Contains(err.Error(), "Priority") {
+		t.Errorf("expected immutable field error, got %v", err)
+	}
+
+	filtered, err := d.NodePoolList(ctx, cluster, model.WithNodePoolListName("spot"))
+	if err != nil || len(filtered) != 1 {
+		t.Fatalf("NodePoolList filtered: %v, %d", err, len(filtered))
+	}
+
+	if err := d.NodePoolDelete(ctx, cluster, "system"); err == nil {
+		t.Error("expected error deleting the last system pool")
+	}
+	if err := d.NodePoolDelete(ctx, cluster, "spot"); err != nil {
+		t.Fatalf("NodePoolDelete: %v", err)
+	}
+	if err := d.NodePoolDelete(ctx, cluster, "spot"); err != nil {
+		t.Errorf("NodePoolDelete must be idempotent: %v", err)
+	}
+}
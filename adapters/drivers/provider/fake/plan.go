@@ -0,0 +1,87 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// ClusterPlan reports the simulated changes of a cluster lifecycle operation from the recorded
+// state. Like the operations themselves, re-running a completed operation plans no-op entries;
+// force plans updates of existing resources.
+func (d *driver) ClusterPlan(ctx context.Context, cluster *model.Cluster, op model.ClusterPlanOperation, opts ...model.ClusterPlanOption) (*model.ClusterPlan, error) {
+	var o model.ClusterPlanOptions
+	for _, fn := range opts {
+		if fn != nil {
+			fn(&o)
+		}
+	}
+
+	var cs *clusterState
+	if err := d.store.view(func(st *providerState) error {
+		cs = st.Clusters[cluster.Name]
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	provisioned := cluster.Existing || (cs != nil && cs.Provisioned)
+	installed := provisioned && cs != nil && cs.Installed
+
+	plan := &model.ClusterPlan{Operation: op, Cluster: cluster.Name, Driver: d.ID()}
+	add := func(scope model.ClusterChangeScope, kind, resource string, action model.ClusterChangeAction) {
+		plan.Changes = append(plan.Changes, model.ClusterChange{Scope: scope, Kind: kind, Resource: resource, Action: action})
+	}
+	existing := func(found bool) model.ClusterChangeAction {
+		switch {
+		case !found:
+			return model.ClusterChangeCreate
+		case o.Force:
+			return model.ClusterChangeUpdate
+		default:
+			return model.ClusterChangeNoOp
+		}
+	}
+	removed := func(found bool) model.ClusterChangeAction {
+		if found {
+			return model.ClusterChangeDelete
+		}
+		return model.ClusterChangeNoOp
+	}
+
+	switch op {
+	case model.ClusterPlanProvision:
+		// ClusterProvision leaves provisioned clusters untouched, force included.
+		action := model.ClusterChangeNoOp
+		if !provisioned {
+			action = model.ClusterChangeCreate
+		}
+		add(model.ClusterChangeScopeCloud, "Cluster", cluster.Name, action)
+		pools := newClusterState(nil).NodePools
+		if provisioned && cs != nil {
+			pools = cs.NodePools
+		}
+		for _, name := range slices.Sorted(maps.Keys(pools)) {
+			add(model.ClusterChangeScopeCloud, "NodePool", cluster.Name+"/"+name, action)
+		}
+	case model.ClusterPlanDeprovision:
+		add(model.ClusterChangeScopeCloud, "Cluster", cluster.Name, removed(cs != nil))
+		if cs != nil {
+			for _, name := range slices.Sorted(maps.Keys(cs.NodePools)) {
+				add(model.ClusterChangeScopeCloud, "NodePool", cluster.Name+"/"+name, model.ClusterChangeDelete)
+			}
+		}
+	case model.ClusterPlanInstall:
+		if !provisioned {
+			return nil, fmt.Errorf("cluster %s not provisioned", cluster.Name)
+		}
+		add(model.ClusterChangeScopeCluster, "IngressController", cluster.Name, existing(installed))
+	case model.ClusterPlanUninstall:
+		add(model.ClusterChangeScopeCluster, "IngressController", cluster.Name, removed(installed))
+	default:
+		return nil, fmt.Errorf("unknown cluster plan operation %q", op)
+	}
+	return plan, nil
+}
@@ -0,0 +1,259 @@
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// stateVersion is the schema version of the state document.
+const stateVersion = 1
+
+// document is the persisted state of all fake providers sharing a state file
+// (or the process memory). Providers are keyed by "<workspace>/<provider>".
+type document struct {
+	Version   int                       `json:"version"`
+	Providers map[string]*providerState `json:"providers"`
+}
+
+// providerState is the simulated cloud of a single provider.
+type providerState struct {
+	Clusters   map[string]*clusterState   `json:"clusters"`             // by cluster name
+	Volumes    map[string]*volumeState    `json:"volumes"`              // by "<appIDHash>/<volName>"
+	DNSRecords map[string]*dnsRecord      `json:"dnsRecords"`           // by "<fqdn>/<type>"
+	Identities map[string]*identityRecord `json:"identities,omitempty"` // by "<appIDHash>"
+}
+
+// clusterState is a simulated managed cluster.
+type clusterState struct {
+	Provisioned bool                       `json:"provisioned"`
+	Installed   bool                       `json:"installed"`
+	NodePools   map[string]*nodePoolRecord `json:"nodePools"` // by pool name
+	CreatedAt   time.Time                  `json:"createdAt"`
+	// KubernetesVersion is the control plane version; empty is the first of FAKE_KUBERNETES_VERSIONS.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Objects are the Kubernetes objects of the in-process cluster by "<resource>/<namespace>/<name>".
+	Objects map[string]json.RawMessage `json:"objects,omitempty"`
+}
+
+// nodePoolRecord is a simulated node pool.
+type nodePoolRecord struct {
+	Name          string            `json:"name"`
+	Mode          string            `json:"mode"`
+	InstanceType  string            `json:"instanceType,omitempty"`
+	OSDiskType    string            `json:"osDiskType,omitempty"`
+	OSDiskSizeGiB int               `json:"osDiskSizeGiB,omitempty"`
+	Priority      string            `json:"priority"`
+	Zones         []string          `json:"zones,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Autoscaling   bool              `json:"autoscaling,omitempty"`
+	Min           int               `json:"min,omitempty"`
+	Max           int               `json:"max,omitempty"`
+	Count         int               `json:"count"`
+	// KubernetesVersion and NodeImageVersion default to the cluster version and the first node image.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	NodeImageVersion  string `json:"nodeImageVersion,omitempty"`
+}
+
+// volumeState holds disks and snapshots of a logical volume of an app.
+type volumeState struct {
+	AppName   string            `json:"appName"`
+	AppIDHash string            `json:"appIdHash"`
+	Type      string            `json:"type"`
+	Disks     []*diskRecord     `json:"disks"`
+	Snapshots []*snapshotRecord `json:"snapshots"`
+}
+
+// diskRecord is a simulated disk. OrphanedAt is set by VolumeResourceMarkOrphaned.
+type diskRecord struct {
+	model.VolumeDisk
+	OrphanedAt *time.Time `json:"orphanedAt,omitempty"`
+}
+
+// snapshotRecord is a simulated snapshot. OrphanedAt is set by VolumeResourceMarkOrphaned.
+type snapshotRecord struct {
+	model.VolumeSnapshot
+	OrphanedAt *time.Time `json:"orphanedAt,omitempty"`
+}
+
+// identityRecord is a simulated app workload identity with its federated subjects and roles.
+type identityRecord struct {
+	AppName     string         `json:"appName"`
+	ClientID    string         `json:"clientId"`
+	PrincipalID string         `json:"principalId"`
+	Subjects    []string       `json:"subjects"` // system:serviceaccount:<namespace>:<name>
+	Roles       []identityRole `json:"roles,omitempty"`
+}
+
+// identityRole is a role granted to a simulated identity.
+type identityRole struct {
+	Scope string `json:"scope"`
+	Role  string `json:"role"`
+}
+
+// dnsRecord is a DNS record set stored in a simulated DNS zone.
+type dnsRecord struct {
+	Zone  string   `json:"zone,omitempty"`
+	FQDN  string   `json:"fqdn"`
+	Type  string   `json:"type"`
+	TTL   uint32   `json:"ttl"`
+	RData []string `json:"rdata"`
+}
+
+// storeMu serializes state access of all driver instances in the process.
+// Concurrent kompoxops processes sharing a state file are not supported.
+var storeMu sync.Mutex
+
+// memoryDocument is the encoded state used when no state file is configured.
+var memoryDocument []byte
+
+// store loads and saves the state of a provider. Each transaction decodes a fresh copy of
+// the document so that failed operations leave the stored state untouched.
+type store struct {
+	path string // state file path; empty uses memoryDocument
+	key  string // provider key in the document
+}
+
+// newStore returns a store backed by the state file at path, or by process memory if path is empty.
+func newStore(path, key string) *store {
+	return &store{path: path, key: key}
+}
+
+// view runs fn with a read-only copy of the provider state.
+func (s *store) view(fn func(st *providerState) error) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+	return fn(doc.provider(s.key))
+}
+
+// update runs fn with the provider state and saves it when fn succeeds.
+func (s *store) update(fn func(st *providerState) error) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(doc.provider(s.key)); err != nil {
+		return err
+	}
+	return s.save(doc)
+}
+
+// load decodes the document. A missing state file yields an empty document.
+func (s *store) load() (*document, error) {
+	data := memoryDocument
+	if s.path != "" {
+		path, err := kube.ExpandHome(s.path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("read state file %s: %w", path, err)
+		}
+	}
+	doc := &document{Version: stateVersion}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("decode state: %w", err)
+		}
+		if doc.Version != stateVersion {
+			return nil, fmt.Errorf("unsupported state version %d", doc.Version)
+		}
+	}
+	if doc.Providers == nil {
+		doc.Providers = map[string]*providerState{}
+	}
+	return doc, nil
+}
+
+// save encodes the document and replaces the state file atomically.
+func (s *store) save(doc *document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+	if s.path == "" {
+		memoryDocument = data
+		return nil
+	}
+	path, err := kube.ExpandHome(s.path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// provider returns the state of the provider, creating it if needed.
+func (doc *document) provider(key string) *providerState {
+	st := doc.Providers[key]
+	if st == nil {
+		st = &providerState{}
+		doc.Providers[key] = st
+	}
+	if st.Clusters == nil {
+		st.Clusters = map[string]*clusterState{}
+	}
+	if st.Volumes == nil {
+		st.Volumes = map[string]*volumeState{}
+	}
+	if st.DNSRecords == nil {
+		st.DNSRecords = map[string]*dnsRecord{}
+	}
+	if st.Identities == nil {
+		st.Identities = map[string]*identityRecord{}
+	}
+	return st
+}
+
+// provisionedCluster returns the state of a provisioned cluster. Existing clusters are
+// provisioned outside Kompox, so their state is created on first use.
+func (st *providerState) provisionedCluster(cluster *model.Cluster) (*clusterState, error) {
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster nil")
+	}
+	cs := st.Clusters[cluster.Name]
+	if cs == nil && cluster.Existing {
+		cs = newClusterState(nil)
+		st.Clusters[cluster.Name] = cs
+	}
+	if cs == nil || !cs.Provisioned {
+		return nil, fmt.Errorf("cluster %s not provisioned", cluster.Name)
+	}
+	if cs.NodePools == nil {
+		cs.NodePools = map[string]*nodePoolRecord{}
+	}
+	return cs, nil
+}
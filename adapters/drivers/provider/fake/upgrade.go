@@ -0,0 +1,139 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// versions returns the simulated Kubernetes versions in ascending order (FAKE_KUBERNETES_VERSIONS).
+func (d *driver) versions(cluster *model.Cluster) []string {
+	v := d.setting(cluster, keyK8sVersions)
+	if v == "" {
+		v = defaultVersions
+	}
+	return splitList(v)
+}
+
+// nodeImage returns the latest simulated node image version (FAKE_NODE_IMAGE_VERSION).
+func (d *driver) nodeImage(cluster *model.Cluster) string {
+	if v := d.setting(cluster, keyNodeImage); v != "" {
+		return v
+	}
+	return defaultNodeImage
+}
+
+// clusterVersion returns the control plane version of the simulated cluster.
+func (d *driver) clusterVersion(cluster *model.Cluster, cs *clusterState) string {
+	if cs.KubernetesVersion != "" {
+		return cs.KubernetesVersion
+	}
+	return d.versions(cluster)[0]
+}
+
+// poolVersions returns the Kubernetes and node image versions of a simulated node pool.
+func (d *driver) poolVersions(cluster *model.Cluster, cs *clusterState, rec *nodePoolRecord) (string, string) {
+	k8s, image := rec.KubernetesVersion, rec.NodeImageVersion
+	if k8s == "" {
+		k8s = d.clusterVersion(cluster, cs)
+	}
+	if image == "" {
+		image = defaultNodeImage
+	}
+	return k8s, image
+}
+
+// ClusterVersions returns the recorded versions. Upgrades are the FAKE_KUBERNETES_VERSIONS
+// entries after the control plane version.
+func (d *driver) ClusterVersions(ctx context.Context, cluster *model.Cluster) (versions *model.ClusterVersions, err error) {
+	err = d.store.view(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		all := d.versions(cluster)
+		current := d.clusterVersion(cluster, cs)
+		versions = &model.ClusterVersions{KubernetesVersion: current}
+		if i := slices.Index(all, current); i >= 0 {
+			versions.Upgrades = slices.Clone(all[i+1:])
+		}
+		for _, name := range slices.Sorted(maps.Keys(cs.NodePools)) {
+			rec := cs.NodePools[name]
+			k8s, image := d.poolVersions(cluster, cs, rec)
+			versions.NodePools = append(versions.NodePools, model.NodePoolVersions{
+				Name:                   name,
+				Mode:                   rec.Mode,
+				KubernetesVersion:      k8s,
+				NodeImageVersion:       image,
+				LatestNodeImageVersion: d.nodeImage(cluster),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// ClusterUpgrade records the control plane version. The version must be one of the upgrades
+// reported by ClusterVersions; node pools keep their versions.
+func (d *driver) ClusterUpgrade(ctx context.Context, cluster *model.Cluster, version string) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUpgrade")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		all := d.versions(cluster)
+		current := d.clusterVersion(cluster, cs)
+		if slices.Index(all, version) <= slices.Index(all, current) {
+			return fmt.Errorf("cannot upgrade control plane from %s to %s (available: %s)", current, version, strings.Join(all, ","))
+		}
+		// Pin pools to the version they run before the control plane moves.
+		for _, rec := range cs.NodePools {
+			rec.KubernetesVersion, _ = d.poolVersions(cluster, cs, rec)
+		}
+		cs.KubernetesVersion = version
+		return nil
+	})
+}
+
+// NodePoolUpgrade records the node pool versions. The Kubernetes version must not exceed the
+// control plane version; a version upgrade also installs the latest node image.
+func (d *driver) NodePoolUpgrade(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolUpgradeOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolUpgrade")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolUpgradeOptions(opts...)
+	if o.KubernetesVersion == "" && !o.NodeImage {
+		return fmt.Errorf("nothing to upgrade for node pool %s", poolName)
+	}
+	return d.store.update(func(st *providerState) error {
+		cs, err := st.provisionedCluster(cluster)
+		if err != nil {
+			return err
+		}
+		rec := cs.NodePools[poolName]
+		if rec == nil {
+			return fmt.Errorf("node pool %q not found", poolName)
+		}
+		rec.KubernetesVersion, rec.NodeImageVersion = d.poolVersions(cluster, cs, rec)
+		if o.KubernetesVersion != "" {
+			all := d.versions(cluster)
+			i := slices.Index(all, o.KubernetesVersion)
+			if i < 0 || i > slices.Index(all, d.clusterVersion(cluster, cs)) {
+				return fmt.Errorf("node pool version %s must be a known version not newer than the control plane %s", o.KubernetesVersion, d.clusterVersion(cluster, cs))
+			}
+			rec.KubernetesVersion = o.KubernetesVersion
+		}
+		rec.NodeImageVersion = d.nodeImage(cluster)
+		return nil
+	})
+}
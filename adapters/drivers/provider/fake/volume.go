@@ -0,0 +1,619 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// fakeCSIDriver is the CSI driver name of simulated volumes. No such driver runs in the
+// cluster, so generated PVs bind but pods using them never start outside envtest.
+const fakeCSIDriver = "fake.csi.kompox.dev"
+
+// Resource kinds reported by VolumeResourceList.
+const (
+	resourceKindDisk     = "disk"
+	resourceKindSnapshot = "snapshot"
+)
+
+// handlePrefix is the scheme of disk and snapshot handles:
+// fake://<kind>/<appIDHash>/<volName>/<name>
+const handlePrefix = "fake://"
+
+// appVolume returns the app volume after checking that its type is supported.
+func appVolume(app *model.App, volName string) (*model.AppVolume, error) {
+	if app == nil {
+		return nil, fmt.Errorf("app nil")
+	}
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+	if vol.Type != "" && vol.Type != model.VolumeTypeDisk && vol.Type != model.VolumeTypeFiles {
+		return nil, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+	return vol, nil
+}
+
+// volumeKey returns the state key of the logical volume and the app ID hash.
+// Volumes belong to the app regardless of the cluster, as with cloud resource groups.
+func (d *driver) volumeKey(app *model.App, volName string) (key, appIDHash string) {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	return h.AppID + "/" + volName, h.AppID
+}
+
+// volumeState returns the state of the logical volume, creating it if needed.
+func (d *driver) volumeState(st *providerState, app *model.App, vol *model.AppVolume) *volumeState {
+	key, appIDHash := d.volumeKey(app, vol.Name)
+	vs := st.Volumes[key]
+	if vs == nil {
+		vs = &volumeState{AppName: app.Name, AppIDHash: appIDHash}
+		st.Volumes[key] = vs
+	}
+	vs.Type = vol.Type
+	if vs.Type == "" {
+		vs.Type = model.VolumeTypeDisk
+	}
+	return vs
+}
+
+func handleOf(kind, appIDHash, volName, name string) string {
+	return fmt.Sprintf("%s%s/%s/%s/%s", handlePrefix, kind, appIDHash, volName, name)
+}
+
+// VolumeDiskList returns the disks of the volume.
+func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	out := []*model.VolumeDisk{}
+	err = d.store.view(func(st *providerState) error {
+		for _, r := range d.volumeState(st, app, vol).Disks {
+			disk := r.VolumeDisk
+			out = append(out, &disk)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(out, func(v *model.VolumeDisk) (time.Time, string) { return v.CreatedAt, v.Name })
+	return out, nil
+}
+
+// VolumeDiskCreate creates a disk, either empty or from a disk or snapshot source.
+//   - "disk:<name>" / "snapshot:<name>" -> disk / snapshot of the volume
+//   - "fake://..." -> disk or snapshot handle (e.g., resolved app: sources)
+//   - Others -> snapshot name of the volume
+//
+// Disks of Type="disk" are placed in the requested zone, which must be one of FAKE_ZONES.
+func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (disk *model.VolumeDisk, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskCreate")
+	defer func() { cleanup(err) }()
+
+	zone := ""
+	if vol.Type != model.VolumeTypeFiles {
+		zone = o.Zone
+		if zone == "" {
+			zone = app.Deployment.Zone
+		}
+		if zone != "" {
+			if err := d.validateZone(cluster, zone); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	diskName = strings.TrimSpace(diskName)
+	if diskName == "" {
+		if diskName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	}
+
+	options := maps.Clone(vol.Options)
+	if options == nil {
+		options = map[string]any{}
+	}
+	maps.Copy(options, o.Options)
+
+	err = d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		if vs.findDisk(diskName) != nil {
+			return fmt.Errorf("disk %q already exists", diskName)
+		}
+		size := max(vol.Size, o.Size)
+		sourceHandle := ""
+		if source = strings.TrimSpace(source); source != "" {
+			handle, srcSize, err := st.resolveSource(vs, source, resourceKindSnapshot)
+			if err != nil {
+				return err
+			}
+			sourceHandle, size = handle, max(size, srcSize)
+		}
+		now := time.Now().UTC()
+		rec := &diskRecord{VolumeDisk: model.VolumeDisk{
+			Name:         diskName,
+			VolumeName:   volName,
+			Size:         size,
+			Zone:         zone,
+			Options:      options,
+			Handle:       handleOf(resourceKindDisk, vs.AppIDHash, volName, diskName),
+			Labels:       maps.Clone(o.Labels),
+			Description:  o.Description,
+			SourceHandle: sourceHandle,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}}
+		vs.Disks = append(vs.Disks, rec)
+		v := rec.VolumeDisk
+		disk = &v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return disk, nil
+}
+
+// VolumeDiskDelete deletes a disk. Deleting a missing disk succeeds.
+func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskDeleteOption) (err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return err
+	}
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskDelete")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		vs.Disks = slices.DeleteFunc(vs.Disks, func(r *diskRecord) bool { return r.Name == diskName })
+		return nil
+	})
+}
+
+// VolumeDiskAssign marks the disk as assigned and unassigns all other disks of the volume.
+func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskAssignOption) (err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return err
+	}
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskAssign")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		if vs.findDisk(diskName) == nil {
+			return fmt.Errorf("disk not found: %s", diskName)
+		}
+		now := time.Now().UTC()
+		for _, r := range vs.Disks {
+			if assigned := r.Name == diskName; r.Assigned != assigned {
+				r.Assigned = assigned
+				r.UpdatedAt = now
+			}
+		}
+		return nil
+	})
+}
+
+// VolumeDiskUpdate merges options into an existing disk. Simulated options are opaque and
+// can always be changed online; the size and zone are fixed and cannot be set as options.
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (disk *model.VolumeDisk, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeDiskUpdateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.Options) == 0 {
+		return nil, fmt.Errorf("%w: no options to update", model.ErrVolumeOptionsInvalid)
+	}
+	for _, k := range []string{"size", "zone"} {
+		if _, ok := o.Options[k]; ok {
+			return nil, fmt.Errorf("%w: %s cannot be changed", model.ErrVolumeOptionsInvalid, k)
+		}
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskUpdate")
+	defer func() { cleanup(err) }()
+
+	err = d.store.update(func(st *providerState) error {
+		r := d.volumeState(st, app, vol).findDisk(diskName)
+		if r == nil {
+			return fmt.Errorf("disk not found: %s", diskName)
+		}
+		if r.Options == nil {
+			r.Options = map[string]any{}
+		}
+		maps.Copy(r.Options, o.Options)
+		r.UpdatedAt = time.Now().UTC()
+		v := r.VolumeDisk
+		disk = &v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return disk, nil
+}
+
+// VolumeSnapshotList returns the snapshots of the volume.
+func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	out := []*model.VolumeSnapshot{}
+	err = d.store.view(func(st *providerState) error {
+		for _, r := range d.volumeState(st, app, vol).Snapshots {
+			snap := r.VolumeSnapshot
+			out = append(out, &snap)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(out, func(v *model.VolumeSnapshot) (time.Time, string) { return v.CreatedAt, v.Name })
+	return out, nil
+}
+
+// VolumeSnapshotCreate takes a snapshot of a disk. An empty source selects the assigned disk;
+// other sources default to a disk name of the volume (see VolumeDiskCreate).
+func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (snap *model.VolumeSnapshot, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotCreate")
+	defer func() { cleanup(err) }()
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		if snapName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	}
+
+	err = d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		if vs.findSnapshot(snapName) != nil {
+			return fmt.Errorf("snapshot %q already exists", snapName)
+		}
+		var handle string
+		var size int64
+		if source = strings.TrimSpace(source); source == "" {
+			i := slices.IndexFunc(vs.Disks, func(r *diskRecord) bool { return r.Assigned })
+			if i < 0 {
+				return fmt.Errorf("no assigned disk found for volume %s", volName)
+			}
+			handle, size = vs.Disks[i].Handle, vs.Disks[i].Size
+		} else {
+			var err error
+			if handle, size, err = st.resolveSource(vs, source, resourceKindDisk); err != nil {
+				return err
+			}
+			if !strings.HasPrefix(handle, handlePrefix+resourceKindDisk+"/") {
+				return fmt.Errorf("snapshot source must be a disk: %s", source)
+			}
+		}
+		now := time.Now().UTC()
+		rec := &snapshotRecord{VolumeSnapshot: model.VolumeSnapshot{
+			Name:         snapName,
+			VolumeName:   volName,
+			Size:         size,
+			Handle:       handleOf(resourceKindSnapshot, vs.AppIDHash, volName, snapName),
+			Labels:       maps.Clone(o.Labels),
+			Description:  o.Description,
+			SourceHandle: handle,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}}
+		vs.Snapshots = append(vs.Snapshots, rec)
+		s := rec.VolumeSnapshot
+		snap = &s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// VolumeSnapshotDelete deletes a snapshot. Deleting a missing snapshot succeeds.
+func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, _ ...model.VolumeSnapshotDeleteOption) (err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return err
+	}
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotDelete")
+	defer func() { cleanup(err) }()
+
+	return d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		vs.Snapshots = slices.DeleteFunc(vs.Snapshots, func(r *snapshotRecord) bool { return r.Name == snapName })
+		return nil
+	})
+}
+
+// VolumeSnapshotCopy records a copy of a snapshot of another provider. The source must be a
+// fake:// snapshot handle; it is not looked up since it belongs to another state. The copy
+// keeps the source record (size, labels, description) except the name, handle and timestamps;
+// option labels are merged over the source labels and a non-empty description replaces it.
+func (d *driver) VolumeSnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (snap *model.VolumeSnapshot, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	if vol.Type == model.VolumeTypeFiles {
+		return nil, fmt.Errorf("copying snapshots of %s volumes is not supported: %w", vol.Type, model.ErrNotSupported)
+	}
+	if source == nil || !strings.HasPrefix(source.Handle, handlePrefix+resourceKindSnapshot+"/") {
+		return nil, fmt.Errorf("copy source must be a fake snapshot handle")
+	}
+	var o model.VolumeSnapshotCopyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotCopy")
+	defer func() { cleanup(err) }()
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		if snapName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	}
+	err = d.store.update(func(st *providerState) error {
+		vs := d.volumeState(st, app, vol)
+		if vs.findSnapshot(snapName) != nil {
+			return fmt.Errorf("snapshot %q already exists", snapName)
+		}
+		now := time.Now().UTC()
+		rec := &snapshotRecord{VolumeSnapshot: *source}
+		rec.Name = snapName
+		rec.VolumeName = volName
+		rec.Handle = handleOf(resourceKindSnapshot, vs.AppIDHash, volName, snapName)
+		rec.SourceHandle = source.Handle
+		rec.CreatedAt = now
+		rec.UpdatedAt = now
+		rec.Labels = maps.Clone(source.Labels)
+		if len(o.Labels) > 0 {
+			if rec.Labels == nil {
+				rec.Labels = map[string]string{}
+			}
+			maps.Copy(rec.Labels, o.Labels)
+		}
+		if o.Description != "" {
+			rec.Description = o.Description
+		}
+		vs.Snapshots = append(vs.Snapshots, rec)
+		s := rec.VolumeSnapshot
+		snap = &s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// VolumeClass returns CSI parameters of the simulated driver. The storage class name is
+// taken from FAKE_STORAGE_CLASS and omitted when unset.
+func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	switch vol.Type {
+	case "", model.VolumeTypeDisk:
+		return model.VolumeClass{
+			StorageClassName: d.setting(cluster, keyStorageClass),
+			CSIDriver:        fakeCSIDriver,
+			FSType:           "ext4",
+			AccessModes:      []string{"ReadWriteOnce"},
+			ReclaimPolicy:    "Retain",
+			VolumeMode:       "Filesystem",
+		}, nil
+	case model.VolumeTypeFiles:
+		return model.VolumeClass{
+			StorageClassName: d.setting(cluster, keyStorageClass),
+			CSIDriver:        fakeCSIDriver,
+			AccessModes:      []string{"ReadWriteMany"},
+			ReclaimPolicy:    "Retain",
+			VolumeMode:       "Filesystem",
+		}, nil
+	default:
+		return model.VolumeClass{}, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+}
+
+// VolumeResourceList lists all disks and snapshots of the provider.
+func (d *driver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
+	var out []*model.VolumeResource
+	err := d.store.view(func(st *providerState) error {
+		for _, key := range slices.Sorted(maps.Keys(st.Volumes)) {
+			vs := st.Volumes[key]
+			for _, r := range vs.Disks {
+				out = append(out, vs.resource(resourceKindDisk, r.Name, r.Handle, r.VolumeName, r.Size, r.CreatedAt, r.OrphanedAt))
+			}
+			for _, r := range vs.Snapshots {
+				out = append(out, vs.resource(resourceKindSnapshot, r.Name, r.Handle, r.VolumeName, r.Size, r.CreatedAt, r.OrphanedAt))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VolumeResourceMarkOrphaned records the orphaned time on the disk or snapshot.
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error {
+	return d.store.update(func(st *providerState) error {
+		at := at.UTC()
+		for _, vs := range st.Volumes {
+			for _, r := range vs.Disks {
+				if r.Handle == res.Handle {
+					r.OrphanedAt = &at
+					return nil
+				}
+			}
+			for _, r := range vs.Snapshots {
+				if r.Handle == res.Handle {
+					r.OrphanedAt = &at
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("volume resource not found: %s", res.Handle)
+	})
+}
+
+// VolumeResourceUnmarkOrphaned clears the orphaned time of the disk or snapshot.
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error {
+	return d.store.update(func(st *providerState) error {
+		for _, vs := range st.Volumes {
+			for _, r := range vs.Disks {
+				if r.Handle == res.Handle {
+					r.OrphanedAt = nil
+					return nil
+				}
+			}
+			for _, r := range vs.Snapshots {
+				if r.Handle == res.Handle {
+					r.OrphanedAt = nil
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("volume resource not found: %s", res.Handle)
+	})
+}
+
+// VolumeResourceDelete deletes the disk or snapshot. Deleting a missing resource succeeds.
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error {
+	return d.store.update(func(st *providerState) error {
+		for key, vs := range st.Volumes {
+			vs.Disks = slices.DeleteFunc(vs.Disks, func(r *diskRecord) bool { return r.Handle == res.Handle })
+			vs.Snapshots = slices.DeleteFunc(vs.Snapshots, func(r *snapshotRecord) bool { return r.Handle == res.Handle })
+			if len(vs.Disks) == 0 && len(vs.Snapshots) == 0 {
+				delete(st.Volumes, key)
+			}
+		}
+		return nil
+	})
+}
+
+// resolveSource resolves a disk or snapshot source to its handle and size.
+// Names without a disk:/snapshot: prefix refer to defaultKind; fake:// handles are looked up
+// in all volumes of the provider.
+func (st *providerState) resolveSource(vs *volumeState, source, defaultKind string) (string, int64, error) {
+	if strings.HasPrefix(source, handlePrefix) {
+		for _, v := range st.Volumes {
+			if v.Type != vs.Type {
+				continue
+			}
+			for _, r := range v.Disks {
+				if r.Handle == source {
+					return r.Handle, r.Size, nil
+				}
+			}
+			for _, r := range v.Snapshots {
+				if r.Handle == source {
+					return r.Handle, r.Size, nil
+				}
+			}
+		}
+		return "", 0, fmt.Errorf("no %s volume disk or snapshot with handle %q", vs.Type, source)
+	}
+
+	kind, name := defaultKind, source
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lower, "disk:"):
+		kind, name = resourceKindDisk, source[5:]
+	case strings.HasPrefix(lower, "snapshot:"):
+		kind, name = resourceKindSnapshot, source[9:]
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("source name cannot be empty")
+	}
+	if kind == resourceKindDisk {
+		if r := vs.findDisk(name); r != nil {
+			return r.Handle, r.Size, nil
+		}
+		return "", 0, fmt.Errorf("disk not found: %s", name)
+	}
+	if r := vs.findSnapshot(name); r != nil {
+		return r.Handle, r.Size, nil
+	}
+	return "", 0, fmt.Errorf("snapshot not found: %s", name)
+}
+
+// sortNewestFirst sorts items by creation time descending, then by name ascending.
+func sortNewestFirst[T any](items []T, key func(T) (time.Time, string)) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		ta, na := key(a)
+		tb, nb := key(b)
+		if c := tb.Compare(ta); c != 0 {
+			return c
+		}
+		return strings.Compare(na, nb)
+	})
+}
+
+func (vs *volumeState) findDisk(name string) *diskRecord {
+	for _, r := range vs.Disks {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func (vs *volumeState) findSnapshot(name string) *snapshotRecord {
+	for _, r := range vs.Snapshots {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// resource returns the inventory entry of a disk or snapshot of the volume.
+func (vs *volumeState) resource(kind, name, handle, volName string, size int64, createdAt time.Time, orphanedAt *time.Time) *model.VolumeResource {
+	return &model.VolumeResource{
+		Kind:       kind,
+		Name:       name,
+		Handle:     handle,
+		AppName:    vs.AppName,
+		AppIDHash:  vs.AppIDHash,
+		VolumeName: volName,
+		Size:       size,
+		CreatedAt:  createdAt,
+		OrphanedAt: orphanedAt,
+	}
+}
@@ -0,0 +1,242 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestVolumeDiskSnapshotFlow(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+	app := &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30, Options: map[string]any{"sku": "standard"}}}}
+
+	disk1, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", "",
+		model.WithVolumeDiskCreateZone("2"),
+		model.WithVolumeDiskCreateLabels(map[string]string{"env": "test"}))
+	if err != nil {
+		t.Fatalf("VolumeDiskCreate: %v", err)
+	}
+	if disk1.Assigned || disk1.Zone != "2" || disk1.Size != 1<<30 || disk1.Options["sku"] != "standard" || disk1.Labels["env"] != "test" {
+		t.Errorf("unexpected disk: %+v", disk1)
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", ""); err == nil {
+		t.Error("expected duplicate disk error")
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "bad", "", model.WithVolumeDiskCreateZone("9")); err == nil {
+		t.Error("expected unknown zone error")
+	}
+
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("VolumeDiskAssign: %v", err)
+	}
+
+	// Empty source snapshots the assigned disk
+	snap, err := d.VolumeSnapshotCreate(ctx, cluster, app, "db", "snap1", "")
+	if err != nil {
+		t.Fatalf("VolumeSnapshotCreate: %v", err)
+	}
+	if snap.SourceHandle != disk1.Handle || snap.Size != disk1.Size {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+
+	// Restore from the snapshot by bare name and assign the restored disk
+	disk2, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk2", "snap1")
+	if err != nil {
+		t.Fatalf("VolumeDiskCreate from snapshot: %v", err)
+	}
+	if disk2.SourceHandle != snap.Handle {
+		t.Errorf("SourceHandle = %q, want %q", disk2.SourceHandle, snap.Handle)
+	}
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk2"); err != nil {
+		t.Fatalf("VolumeDiskAssign: %v", err)
+	}
+	disks, err := d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil {
+		t.Fatalf("VolumeDiskList: %v", err)
+	}
+	if len(disks) != 2 || disks[0].Name != "disk2" || !disks[0].Assigned || disks[1].Assigned {
+		t.Errorf("unexpected order or assignment: %+v, %+v", disks[0], disks[1])
+	}
+
+	// Handles resolve across apps of the provider
+	other := &model.App{Name: "app2", Volumes: []model.AppVolume{{Name: "db"}}}
+	clone, err := d.VolumeDiskCreate(ctx, cluster, other, "db", "", disk1.Handle)
+	if err != nil {
+		t.Fatalf("VolumeDiskCreate from handle: %v", err)
+	}
+	if clone.Name == "" || clone.SourceHandle != disk1.Handle || clone.Size != disk1.Size {
+		t.Errorf("unexpected clone: %+v", clone)
+	}
+	if _, err := d.VolumeSnapshotCreate(ctx, cluster, app, "db", "snap2", "snapshot:snap1"); err == nil {
+		t.Error("expected error for snapshot source of a snapshot")
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk3", "disk:missing"); err == nil {
+		t.Error("expected error for missing source disk")
+	}
+
+	updated, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"sku": "premium"}))
+	if err != nil {
+		t.Fatalf("VolumeDiskUpdate: %v", err)
+	}
+	if updated.Options["sku"] != "premium" {
+		t.Errorf("Options = %v", updated.Options)
+	}
+	if _, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"zone": "1"})); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+
+	if err := d.VolumeSnapshotDelete(ctx, cluster, app, "db", "snap1"); err != nil {
+		t.Fatalf("VolumeSnapshotDelete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("VolumeDiskDelete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Errorf("VolumeDiskDelete must be idempotent: %v", err)
+	}
+	snaps, _ := d.VolumeSnapshotList(ctx, cluster, app, "db")
+	disks, _ = d.VolumeDiskList(ctx, cluster, app, "db")
+	if len(snaps) != 0 || len(disks) != 1 {
+		t.Errorf("unexpected volumes after delete: %d snapshots, %d disks", len(snaps), len(disks))
+	}
+}
+
+func TestVolumeSnapshotCopy(t *testing.T) {
+	ctx := context.Background()
+	primary := newTestDriver(t, nil)
+	standby := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+	app := &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30}}}
+
+	if _, err := primary.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", ""); err != nil {
+		t.Fatalf("VolumeDiskCreate: %v", err)
+	}
+	if err := primary.VolumeDiskAssign(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("VolumeDiskAssign: %v", err)
+	}
+	src, err := primary.VolumeSnapshotCreate(ctx, cluster, app, "db", "snap1", "", model.WithVolumeSnapshotCreateIncremental())
+	if err != nil {
+		t.Fatalf("VolumeSnapshotCreate: %v", err)
+	}
+	src.Labels = map[string]string{"team": "db"}
+	src.Description = "nightly"
+
+	replica, err := standby.VolumeSnapshotCopy(ctx, cluster, app, "db", "", src, model.WithVolumeSnapshotCopyLabels(map[string]string{"replica_source": "snap1"}))
+	if err != nil {
+		t.Fatalf("VolumeSnapshotCopy: %v", err)
+	}
+	if replica.Name == "" || replica.Name == src.Name || replica.Handle == src.Handle || replica.SourceHandle != src.Handle || replica.Size != src.Size {
+		t.Errorf("unexpected replica: %+v", replica)
+	}
+	if replica.Labels["team"] != "db" || replica.Labels["replica_source"] != "snap1" || replica.Description != "nightly" {
+		t.Errorf("replica metadata not copied: labels=%v description=%q", replica.Labels, replica.Description)
+	}
+	if replica.CreatedAt.Before(src.CreatedAt) {
+		t.Errorf("replica CreatedAt = %v, want copy time", replica.CreatedAt)
+	}
+	if _, ok := src.Labels["replica_source"]; ok {
+		t.Error("copy labels must not leak into the source record")
+	}
+	disk, err := standby.VolumeDiskCreate(ctx, cluster, app, "db", "", "snapshot:"+replica.Name)
+	if err != nil {
+		t.Fatalf("VolumeDiskCreate from replica: %v", err)
+	}
+	if disk.SourceHandle != replica.Handle {
+		t.Errorf("SourceHandle = %q, want %q", disk.SourceHandle, replica.Handle)
+	}
+	if _, err := standby.VolumeSnapshotCopy(ctx, cluster, app, "db", "", &model.VolumeSnapshot{Handle: disk.Handle}); err == nil {
+		t.Error("expected error for a disk copy source")
+	}
+}
+
+func TestVolumeResourceInventory(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDriver(t, nil)
+	cluster := &model.Cluster{Name: "cls1"}
+	app := &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "files", Type: model.VolumeTypeFiles}}}
+
+	disk, err := d.VolumeDiskCreate(ctx, cluster, app, "files", "share1", "", model.WithVolumeDiskCreateZone("1"))
+	if err != nil {
+		t.Fatalf("VolumeDiskCreate: %v", err)
+	}
+	if disk.Zone != "" {
+		t.Errorf("files disk must be regional, got zone %q", disk.Zone)
+	}
+	if _, err := d.VolumeSnapshotCreate(ctx, cluster, app, "files", "snap1", "share1"); err != nil {
+		t.Fatalf("VolumeSnapshotCreate: %v", err)
+	}
+
+	res, err := d.VolumeResourceList(ctx)
+	if err != nil {
+		t.Fatalf("VolumeResourceList: %v", err)
+	}
+	if len(res) != 2 || res[0].Kind != resourceKindDisk || res[1].Kind != resourceKindSnapshot || res[0].AppName != "app1" || res[0].AppIDHash == "" {
+		t.Fatalf("unexpected resources: %+v", res)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := d.VolumeResourceMarkOrphaned(ctx, res[0], at); err != nil {
+		t.Fatalf("VolumeResourceMarkOrphaned: %v", err)
+	}
+	res, _ = d.VolumeResourceList(ctx)
+	if res[0].OrphanedAt == nil || !res[0].OrphanedAt.Equal(at) {
+		t.Errorf("OrphanedAt = %v, want %v", res[0].OrphanedAt, at)
+	}
+
+	for _, r := range res {
+		if err := d.VolumeResourceDelete(ctx, r); err != nil {
+			t.Fatalf("VolumeResourceDelete: %v", err)
+		}
+	}
+	if res, _ := d.VolumeResourceList(ctx); len(res) != 0 {
+		t.Errorf("resources not deleted: %+v", res)
+	}
+}
+
+func TestVolumeClass(t *testing.T) {
+	d := newTestDriver(t, map[string]string{keyStorageClass: "standard"})
+	ctx := context.Background()
+
+	vc, err := d.VolumeClass(ctx, nil, nil, model.AppVolume{Name: "db"})
+	if err != nil {
+		t.Fatalf("VolumeClass: %v", err)
+	}
+	if vc.CSIDriver != fakeCSIDriver || vc.StorageClassName != "standard" || vc.AccessModes[0] != "ReadWriteOnce" {
+		t.Errorf("unexpected disk class: %+v", vc)
+	}
+	vc, err = d.VolumeClass(ctx, nil, nil, model.AppVolume{Name: "share", Type: model.VolumeTypeFiles})
+	if err != nil {
+		t.Fatalf("VolumeClass: %v", err)
+	}
+	if vc.AccessModes[0] != "ReadWriteMany" {
+		t.Errorf("unexpected files class: %+v", vc)
+	}
+	if _, err := d.VolumeClass(ctx, nil, nil, model.AppVolume{Name: "x", Type: "tape"}); err == nil {
+		t.Error("expected unsupported type error")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	saved := memoryDocument
+	t.Cleanup(func() { memoryDocument = saved })
+	memoryDocument = nil
+
+	ctx := context.Background()
+	d := newTestDriver(t, map[string]string{keyStateFile: ""})
+	if err := d.ClusterProvision(ctx, &model.Cluster{Name: "cls1"}); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	other := newTestDriver(t, map[string]string{keyStateFile: ""})
+	status, err := other.ClusterStatus(ctx, &model.Cluster{Name: "cls1"})
+	if err != nil {
+		t.Fatalf("ClusterStatus: %v", err)
+	}
+	if !status.Provisioned {
+		t.Error("memory state must be shared within the process")
+	}
+}
@@ -0,0 +1,68 @@
+package providerdrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kompox/kompox/domain"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// identityPortAdapter implements model.AppIdentityPort backed by provider drivers.
+type identityPortAdapter struct {
+	workspaces domain.WorkspaceRepository
+	providers  domain.ProviderRepository
+}
+
+// getDriver fetches driver for given cluster.
+func (a *identityPortAdapter) getDriver(ctx context.Context, cluster *model.Cluster) (Driver, error) {
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster is nil")
+	}
+	provider, err := a.providers.Get(ctx, cluster.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider %s: %w", cluster.ProviderID, err)
+	}
+	var workspace *model.Workspace
+	if provider.WorkspaceID != "" {
+		workspace, _ = a.workspaces.Get(ctx, provider.WorkspaceID)
+	}
+	factory, ok := GetDriverFactory(provider.Driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider driver: %s", provider.Driver)
+	}
+	drv, err := factory(workspace, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver %s: %w", provider.Driver, err)
+	}
+	return drv, nil
+}
+
+// Capabilities returns the capabilities of the driver managing the cluster.
+func (a *identityPortAdapter) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	caps := drv.Capabilities()
+	return &caps, nil
+}
+
+// AppIdentityApply creates or updates the workload identity of the app. Drivers not
+// implementing AppIdentityManager are not supported.
+func (a *identityPortAdapter) AppIdentityApply(ctx context.Context, cluster *model.Cluster, app *model.App, subject model.WorkloadIdentitySubject) (*model.WorkloadIdentity, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	manager, ok := drv.(AppIdentityManager)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not support app workload identities: %w", drv.ID(), model.ErrNotSupported)
+	}
+	return manager.AppIdentityApply(ctx, cluster, app, subject)
+}
+
+// GetAppIdentityPort returns a model.AppIdentityPort implemented via provider drivers.
+func GetAppIdentityPort(workspaces domain.WorkspaceRepository, providers domain.ProviderRepository) model.AppIdentityPort {
+	return &identityPortAdapter{workspaces: workspaces, providers: providers}
+}
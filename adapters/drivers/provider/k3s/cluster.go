@@ -0,0 +1,216 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Ingress controller modes selected by K3S_INGRESS.
+const (
+	ingressModeAuto    = "auto"    // use the bundled Traefik when present, otherwise install Kompox Traefik
+	ingressModeKompox  = "kompox"  // always install Kompox Traefik into the ingress namespace
+	ingressModeBundled = "bundled" // use the Traefik bundled with k3s; never install
+)
+
+// Location of the Traefik Service deployed by the k3s bundled HelmChart.
+const (
+	bundledTraefikNamespace = "kube-system"
+	bundledTraefikService   = "traefik"
+)
+
+// ingressMode returns the validated K3S_INGRESS mode for the cluster.
+func (d *driver) ingressMode(cluster *model.Cluster) (string, error) {
+	switch v := d.setting(cluster, keyIngress); v {
+	case "":
+		return ingressModeAuto, nil
+	case ingressModeAuto, ingressModeKompox, ingressModeBundled:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q (expected %s, %s or %s)", keyIngress, v, ingressModeAuto, ingressModeKompox, ingressModeBundled)
+	}
+}
+
+// bundledTraefikEndpoint returns the LoadBalancer IP/hostname of the bundled Traefik Service.
+// found is false when the bundled Traefik is not deployed (disabled with --disable=traefik).
+func bundledTraefikEndpoint(ctx context.Context, kc *kube.Client) (ip, host string, found bool, err error) {
+	svc, err := kc.Clientset.CoreV1().Services(bundledTraefikNamespace).Get(ctx, bundledTraefikService, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("get service %s/%s: %w", bundledTraefikNamespace, bundledTraefikService, err)
+	}
+	if ing := svc.Status.LoadBalancer.Ingress; len(ing) > 0 {
+		return ing[0].IP, ing[0].Hostname, true, nil
+	}
+	return "", "", true, nil
+}
+
+// ClusterProvision verifies that the self-hosted k3s API server is reachable.
+// k3s clusters are installed outside Kompox, so nothing is created.
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if _, err := kc.Clientset.Discovery().ServerVersion(); err != nil {
+		return fmt.Errorf("k3s clusters must be installed outside kompox; API server not reachable: %w", err)
+	}
+	return nil
+}
+
+// ClusterDeprovision is not supported: k3s clusters are managed outside Kompox.
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) error {
+	return fmt.Errorf("k3s cluster deprovision: %w", model.ErrNotSupported)
+}
+
+// ClusterStatus returns the status of a k3s cluster by querying the API server.
+// Unreachable API servers and unexpected errors are returned; missing ingress Services
+// mean the cluster is not installed.
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	status := &model.ClusterStatus{
+		Existing:    cluster.Existing,
+		Provisioned: false,
+		Installed:   false,
+	}
+
+	mode, err := d.ingressMode(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := kc.Clientset.Discovery().ServerVersion(); err != nil {
+		return nil, fmt.Errorf("API server not reachable: %w", err)
+	}
+	status.Provisioned = true
+
+	if mode != ingressModeBundled {
+		ip, host, err := kc.IngressEndpoint(ctx, cluster)
+		switch {
+		case apierrors.IsNotFound(err):
+			// Kompox ingress controller not installed
+		case err != nil:
+			return nil, fmt.Errorf("get ingress endpoint: %w", err)
+		default:
+			status.Installed = true
+			status.IngressGlobalIP = ip
+			status.IngressFQDN = host
+			return status, nil
+		}
+	}
+	if mode != ingressModeKompox {
+		ip, host, found, err := bundledTraefikEndpoint(ctx, kc)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			status.Installed = true
+			status.IngressGlobalIP = ip
+			status.IngressFQDN = host
+		}
+	}
+	return status, nil
+}
+
+// ClusterInstall installs the Kompox Traefik ingress controller, or adopts the Traefik
+// bundled with k3s depending on K3S_INGRESS.
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+
+	mode, err := d.ingressMode(cluster)
+	if err != nil {
+		return err
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	_, _, bundled, err := bundledTraefikEndpoint(ctx, kc)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mode == ingressModeBundled && !bundled:
+		return fmt.Errorf("%s=%s but bundled traefik service %s/%s not found", keyIngress, mode, bundledTraefikNamespace, bundledTraefikService)
+	case mode == ingressModeBundled || (mode == ingressModeAuto && bundled):
+		log.Info(ctx, "using traefik bundled with k3s; skipping ingress install", "namespace", bundledTraefikNamespace, "service", bundledTraefikService)
+		return nil
+	case bundled:
+		log.Warn(ctx, "bundled traefik is running; it may hold ports 80/443 needed by the kompox ingress", "namespace", bundledTraefikNamespace)
+	}
+
+	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
+		log.Warn(ctx, "static ingress certificates are not supported by the k3s driver; ignored", "count", len(cluster.Ingress.Certificates))
+	}
+
+	return kc.InstallIngressTraefikBasic(ctx, cluster, nil)
+}
+
+// ClusterUninstall uninstalls the Kompox Traefik ingress controller. The bundled Traefik is left untouched.
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUninstall")
+	defer func() { cleanup(err) }()
+
+	mode, err := d.ingressMode(cluster)
+	if err != nil {
+		return err
+	}
+	if mode == ingressModeBundled {
+		return nil
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: Uninstall Traefik (idempotent)
+	if err := kc.UninstallIngressTraefik(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 2: Delete ingress namespace (idempotent)
+	if err := kc.DeleteNamespace(ctx, kube.IngressNamespace(cluster)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClusterKubeconfig returns kubeconfig bytes from K3S_KUBECONFIG or K3S_KUBECONFIG_PATH.
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	return d.kubeconfig(cluster)
+}
+
+// ClusterDNSApply is a no-op for the k3s provider driver.
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	return nil
+}
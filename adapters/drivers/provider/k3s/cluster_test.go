@@ -0,0 +1,77 @@
+package k3s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestClusterStatus(t *testing.T) {
+	cluster := &model.Cluster{Name: "c1", Existing: true}
+	lbService := func(ns, name, ip string) runtime.Object {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: ip}}}},
+		}
+	}
+	kompox := lbService(kube.IngressNamespace(cluster), kube.IngressServiceName(cluster), "192.0.2.1")
+	bundled := lbService(bundledTraefikNamespace, bundledTraefikService, "192.0.2.2")
+	errAPI := errors.New("api unavailable")
+
+	tests := []struct {
+		name          string
+		mode          string
+		objects       []runtime.Object
+		clientErr     error
+		serviceErr    error
+		wantErr       error
+		wantInstalled bool
+		wantIP        string
+	}{
+		{name: "kompox ingress", objects: []runtime.Object{kompox, bundled}, wantInstalled: true, wantIP: "192.0.2.1"},
+		{name: "bundled ingress", objects: []runtime.Object{bundled}, wantInstalled: true, wantIP: "192.0.2.2"},
+		{name: "bundled mode ignores kompox", mode: ingressModeBundled, objects: []runtime.Object{kompox}},
+		{name: "kompox mode ignores bundled", mode: ingressModeKompox, objects: []runtime.Object{bundled}},
+		{name: "not installed"},
+		{name: "client error", clientErr: errAPI, wantErr: errAPI},
+		{name: "service error", serviceErr: errAPI, wantErr: errAPI},
+		{name: "bundled service error", mode: ingressModeBundled, serviceErr: errAPI, wantErr: errAPI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(tt.objects...)
+			if tt.serviceErr != nil {
+				cs.PrependReactor("get", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.serviceErr
+				})
+			}
+			d := &driver{
+				settings: map[string]string{keyIngress: tt.mode},
+				newClient: func(context.Context, *model.Cluster) (*kube.Client, error) {
+					return &kube.Client{Clientset: cs}, tt.clientErr
+				},
+			}
+			status, err := d.ClusterStatus(context.Background(), cluster)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %+v, %v; want error %v", status, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !status.Provisioned || status.Installed != tt.wantInstalled || status.IngressGlobalIP != tt.wantIP {
+				t.Errorf("got %+v, want installed=%v ip=%q", status, tt.wantInstalled, tt.wantIP)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package k3s
+
+import (
+	"context"
+	"testing"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/drivers/provider/conformance"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newConformanceClient returns a client of a fake cluster with one Ready node where every
+// helper pod succeeds as soon as it is created.
+func newConformanceClient() *kube.Client {
+	node := testNode("n1", true, map[string]string{kube.LabelK4xNodePool: "system"})
+	cs := fake.NewSimpleClientset(&node)
+	cs.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod).DeepCopy()
+		if pod.Name == "" {
+			pod.Name = pod.GenerateName + "0"
+		}
+		pod.Status.Phase = corev1.PodSucceeded
+		return true, pod, cs.Tracker().Create(action.GetResource(), pod, pod.Namespace)
+	})
+	return &kube.Client{Clientset: cs}
+}
+
+func TestConformance(t *testing.T) {
+	kc := newConformanceClient()
+	report := conformance.Run(t, conformance.Fixture{
+		Factory: func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+			return &driver{
+				workspaceName: workspace.Name,
+				providerName:  provider.Name,
+				settings:      provider.Settings,
+				newClient: func(context.Context, *model.Cluster) (*kube.Client, error) {
+					return kc, nil
+				},
+			}, nil
+		},
+		Workspace:  &model.Workspace{Name: "ws"},
+		Provider:   &model.Provider{Name: "prv", Driver: "k3s", Settings: map[string]string{}},
+		Cluster:    &model.Cluster{Name: "cls1", Existing: true},
+		App:        &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30}}},
+		VolumeName: "db",
+	})
+	report.Require(t, conformance.CapVolumeDisk, conformance.CapVolumeSnapshot, conformance.CapNodePoolList)
+}
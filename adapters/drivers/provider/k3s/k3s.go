@@ -3,17 +3,26 @@ package k3s
 import (
 	"context"
 	"fmt"
+	"time"
 
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
 	"github.com/kompox/kompox/domain/model"
 )
 
-// driver implements the K3s provider driver.
+// driver implements the K3s provider driver for existing self-hosted clusters.
+// Cluster operations use the kubeconfig from settings; volumes are node-local directories
+// managed through short-lived helper pods.
 type driver struct {
 	workspaceName string
 	providerName  string
+	settings      map[string]string // provider settings; cluster settings take precedence (see setting)
+	newClient     clientFunc        // client constructor (nil builds the client from the kubeconfig)
 }
 
+// clientFunc returns the Kubernetes client of a cluster.
+type clientFunc func(ctx context.Context, cluster *model.Cluster) (*kube.Client, error)
+
 // ID returns the provider identifier.
 func (d *driver) ID() string { return "k3s" }
 
@@ -23,82 +32,30 @@ func (d *driver) WorkspaceName() string { return d.workspaceName }
 // ProviderName returns the provider name associated with this driver instance.
 func (d *driver) ProviderName() string { return d.providerName }
 
-// ClusterProvision is not implemented for K3s provider.
-func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) error {
-	return fmt.Errorf("ClusterProvision is not implemented for k3s provider")
-}
-
-// ClusterDeprovision is not implemented for K3s provider.
-func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) error {
-	return fmt.Errorf("ClusterDeprovision is not implemented for k3s provider")
-}
-
-// ClusterStatus is not implemented for K3s provider.
-func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
-	return nil, fmt.Errorf("ClusterStatus is not implemented for k3s provider")
-}
-
-// ClusterInstall is not implemented for K3s provider.
-func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) error {
-	return fmt.Errorf("ClusterInstall is not implemented for k3s provider")
-}
-
-// ClusterUninstall is not implemented for K3s provider.
-func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) error {
-	return fmt.Errorf("ClusterUninstall is not implemented for k3s provider")
-}
-
-// ClusterKubeconfig is not implemented for K3s provider.
-func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
-	return nil, fmt.Errorf("ClusterKubeconfig is not implemented for k3s provider")
-}
-
-// ClusterDNSApply is a no-op for the k3s provider driver.
-func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
-	return nil
-}
-
-// Volume management (not implemented for k3s)
-func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
-	return nil, fmt.Errorf("VolumeDiskList is not implemented for k3s provider")
-}
-func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, _ ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
-	return nil, fmt.Errorf("VolumeDiskCreate is not implemented for k3s provider")
-}
-func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskDeleteOption) error {
-	return fmt.Errorf("VolumeDiskDelete is not implemented for k3s provider")
-}
-func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskAssignOption) error {
-	return fmt.Errorf("VolumeDiskAssign is not implemented for k3s provider")
-}
-
-// Snapshot operations (not implemented for k3s)
-func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
-	return nil, fmt.Errorf("VolumeSnapshotList is not implemented for k3s provider")
-}
-func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, _ ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
-	return nil, fmt.Errorf("VolumeSnapshotCreate is not implemented for k3s provider")
-}
-func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, _ ...model.VolumeSnapshotDeleteOption) error {
-	return fmt.Errorf("VolumeSnapshotDelete is not implemented for k3s provider")
-}
-
-// VolumeClass returns empty spec (no opinion) for k3s provider.
-func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
-	return model.VolumeClass{}, nil
-}
-
-// NodePool operations (not implemented for k3s)
-func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, _ ...model.NodePoolListOption) ([]*model.NodePool, error) {
+// Capabilities returns the operations supported by the k3s driver.
+// k3s clusters and nodes are managed outside Kompox; volumes are node-local directories.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Existing: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk: {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true},
+		},
+		NodePool: model.NodePoolCapabilities{List: true},
+	}
+}
+
+// Volume resource inventory (not implemented for k3s)
+func (d *driver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
 	return nil, model.ErrNotSupported
 }
-func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolCreateOption) (*model.NodePool, error) {
-	return nil, model.ErrNotSupported
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error {
+	return model.ErrNotSupported
 }
-func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolUpdateOption) (*model.NodePool, error) {
-	return nil, model.ErrNotSupported
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error {
+	return model.ErrNotSupported
 }
-func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, _ ...model.NodePoolDeleteOption) error {
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error {
 	return model.ErrNotSupported
 }
 
@@ -117,6 +74,7 @@ func init() {
 		return &driver{
 			workspaceName: workspaceName,
 			providerName:  provider.Name,
+			settings:      provider.Settings,
 		}, nil
 	})
 }
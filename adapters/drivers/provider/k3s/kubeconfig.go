@@ -0,0 +1,78 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// Setting keys. Each key may be set in Provider settings and overridden per Cluster.
+const (
+	keyKubeconfig        = "K3S_KUBECONFIG"         // embedded kubeconfig (YAML or base64-encoded YAML)
+	keyKubeconfigPath    = "K3S_KUBECONFIG_PATH"    // kubeconfig file path
+	keyKubeconfigContext = "K3S_KUBECONFIG_CONTEXT" // context to use (default: current-context)
+	keyServer            = "K3S_SERVER"             // API server URL override (e.g., https://k3s.example.com:6443)
+	keyIngress           = "K3S_INGRESS"            // ingress controller mode: auto, kompox, bundled
+)
+
+// defaultKubeconfigPath is the kubeconfig written by the k3s server.
+const defaultKubeconfigPath = "/etc/rancher/k3s/k3s.yaml"
+
+// setting returns the cluster setting for key, falling back to the provider setting.
+func (d *driver) setting(cluster *model.Cluster, key string) string {
+	if cluster != nil && cluster.Settings != nil {
+		if v := strings.TrimSpace(cluster.Settings[key]); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(d.settings[key])
+}
+
+// kubeconfig resolves the kubeconfig of the cluster from the embedded setting or the
+// kubeconfig file, then applies context selection and the API server override.
+func (d *driver) kubeconfig(cluster *model.Cluster) ([]byte, error) {
+	var data []byte
+	if v := d.setting(cluster, keyKubeconfig); v != "" {
+		data = kube.DecodeKubeconfig(v)
+	} else {
+		path := d.setting(cluster, keyKubeconfigPath)
+		if path == "" {
+			path = defaultKubeconfigPath
+		}
+		path, err := kube.ExpandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read kubeconfig %s: %w", path, err)
+		}
+	}
+
+	contextName := d.setting(cluster, keyKubeconfigContext)
+	server := d.setting(cluster, keyServer)
+	if contextName == "" && server == "" {
+		return data, nil
+	}
+	return kube.RewriteKubeconfig(data, contextName, server)
+}
+
+// kubeClient returns a Kubernetes client for the target cluster.
+func (d *driver) kubeClient(ctx context.Context, cluster *model.Cluster) (*kube.Client, error) {
+	if d.newClient != nil {
+		return d.newClient(ctx, cluster)
+	}
+	kubeconfig, err := d.kubeconfig(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get kubeconfig: %w", err)
+	}
+	kc, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("new kube client: %w", err)
+	}
+	return kc, nil
+}
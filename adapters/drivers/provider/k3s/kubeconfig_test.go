@@ -0,0 +1,107 @@
+package k3s
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: https://127.0.0.1:6443
+- name: other
+  cluster:
+    server: https://10.0.0.2:6443
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+- name: other
+  context:
+    cluster: other
+    user: default
+current-context: default
+users:
+- name: default
+  user:
+    token: secret
+`
+
+func TestKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "k3s.yaml")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("path from provider settings", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfigPath: path}}
+		got, err := d.kubeconfig(&model.Cluster{})
+		if err != nil {
+			t.Fatalf("kubeconfig() error = %v", err)
+		}
+		if string(got) != testKubeconfig {
+			t.Errorf("kubeconfig() returned modified content")
+		}
+	})
+
+	t.Run("embedded base64 overrides path", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfigPath: filepath.Join(dir, "missing")}}
+		cluster := &model.Cluster{Settings: map[string]string{keyKubeconfig: base64.StdEncoding.EncodeToString([]byte(testKubeconfig))}}
+		got, err := d.kubeconfig(cluster)
+		if err != nil {
+			t.Fatalf("kubeconfig() error = %v", err)
+		}
+		if string(got) != testKubeconfig {
+			t.Errorf("kubeconfig() did not decode embedded base64 content")
+		}
+	})
+
+	t.Run("context selection and server override", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfig: testKubeconfig, keyKubeconfigContext: "other"}}
+		cluster := &model.Cluster{Settings: map[string]string{keyServer: "https://k3s.example.com:6443"}}
+		got, err := d.kubeconfig(cluster)
+		if err != nil {
+			t.Fatalf("kubeconfig() error = %v", err)
+		}
+		cfg, err := clientcmd.Load(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.CurrentContext != "other" || len(cfg.Contexts) != 1 || len(cfg.Clusters) != 1 {
+			t.Fatalf("unexpected contexts: current=%s contexts=%d clusters=%d", cfg.CurrentContext, len(cfg.Contexts), len(cfg.Clusters))
+		}
+		if s := cfg.Clusters["other"].Server; s != "https://k3s.example.com:6443" {
+			t.Errorf("server = %s, want override", s)
+		}
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfig: testKubeconfig, keyKubeconfigContext: "nope"}}
+		if _, err := d.kubeconfig(nil); err == nil || !strings.Contains(err.Error(), `context "nope" not found`) {
+			t.Errorf("kubeconfig() error = %v, want context not found", err)
+		}
+	})
+}
+
+func TestIngressMode(t *testing.T) {
+	d := &driver{settings: map[string]string{keyIngress: "bundled"}}
+	if m, err := d.ingressMode(&model.Cluster{}); err != nil || m != ingressModeBundled {
+		t.Errorf("ingressMode() = %q, %v; want bundled", m, err)
+	}
+	if m, err := d.ingressMode(&model.Cluster{Settings: map[string]string{keyIngress: "kompox"}}); err != nil || m != ingressModeKompox {
+		t.Errorf("ingressMode() = %q, %v; want cluster override kompox", m, err)
+	}
+	if _, err := (&driver{settings: map[string]string{keyIngress: "nginx"}}).ingressMode(nil); err == nil {
+		t.Errorf("ingressMode() must reject unknown mode")
+	}
+}
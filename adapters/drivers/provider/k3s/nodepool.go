@@ -0,0 +1,194 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultNodePoolName groups nodes without the kompox.dev/node-pool label.
+const defaultNodePoolName = "default"
+
+// Well-known node labels used to derive pool attributes.
+const (
+	labelZone         = "topology.kubernetes.io/zone"
+	labelInstanceType = "node.kubernetes.io/instance-type"
+)
+
+// NodePoolList derives node pools from the kompox.dev/node-pool label of cluster nodes.
+// k3s has no node pool API, so pools are read-only views over existing nodes.
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) (pools []*model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolList")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolListOptions(opts...)
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := kc.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	pools = nodePoolsFromNodes(nodes.Items)
+	if o.Name != "" {
+		filtered := pools[:0]
+		for _, p := range pools {
+			if *p.Name == o.Name {
+				filtered = append(filtered, p)
+			}
+		}
+		pools = filtered
+	}
+	return pools, nil
+}
+
+// NodePool mutations are not supported: nodes join k3s outside Kompox.
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolCreateOption) (*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolUpdateOption) (*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, _ ...model.NodePoolDeleteOption) error {
+	return model.ErrNotSupported
+}
+
+// nodePoolsFromNodes groups nodes by pool label and returns pools sorted by name.
+// Labels are those shared by all nodes of a pool, excluding Kubernetes/k3s managed ones.
+func nodePoolsFromNodes(nodes []corev1.Node) []*model.NodePool {
+	groups := map[string][]corev1.Node{}
+	for _, n := range nodes {
+		name := n.Labels[kube.LabelK4xNodePool]
+		if name == "" {
+			name = defaultNodePoolName
+		}
+		groups[name] = append(groups[name], n)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pools := make([]*model.NodePool, 0, len(names))
+	for _, name := range names {
+		members := groups[name]
+		pool := &model.NodePool{
+			Name:         ptr(name),
+			ProviderName: ptr(name),
+			Mode:         ptr("user"),
+		}
+		if name == "system" {
+			pool.Mode = ptr("system")
+		}
+
+		labels := commonNodeLabels(members)
+		pool.Labels = &labels
+
+		zoneSet := map[string]bool{}
+		instanceTypes := map[string]bool{}
+		nodeNames := make([]string, 0, len(members))
+		ready := 0
+		for _, n := range members {
+			if z := n.Labels[labelZone]; z != "" {
+				zoneSet[z] = true
+			}
+			instanceTypes[n.Labels[labelInstanceType]] = true
+			nodeNames = append(nodeNames, n.Name)
+			if nodeReady(n) {
+				ready++
+			}
+		}
+		if len(zoneSet) > 0 {
+			zones := make([]string, 0, len(zoneSet))
+			for z := range zoneSet {
+				zones = append(zones, z)
+			}
+			sort.Strings(zones)
+			pool.Zones = &zones
+		}
+		if len(instanceTypes) == 1 {
+			for it := range instanceTypes {
+				if it != "" {
+					pool.InstanceType = ptr(it)
+				}
+			}
+		}
+		sort.Strings(nodeNames)
+
+		count := len(members)
+		state := "Ready"
+		if ready < count {
+			state = "NotReady"
+		}
+		pool.Autoscaling = &model.NodePoolAutoscaling{Enabled: false, Desired: &count}
+		pool.Status = &model.NodePoolStatus{
+			ProvisioningState: ptr(state),
+			CurrentNodeCount:  &count,
+			Extensions:        map[string]any{"readyNodeCount": ready, "nodes": nodeNames},
+		}
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// commonNodeLabels returns labels with identical values on all nodes, skipping labels
+// managed by Kubernetes or k3s (kubernetes.io, k8s.io and k3s.io domains).
+func commonNodeLabels(nodes []corev1.Node) map[string]string {
+	out := map[string]string{}
+	if len(nodes) == 0 {
+		return out
+	}
+	for k, v := range nodes[0].Labels {
+		if managedNodeLabel(k) {
+			continue
+		}
+		shared := true
+		for _, n := range nodes[1:] {
+			if n.Labels[k] != v {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// managedNodeLabel reports whether the label key belongs to a Kubernetes or k3s managed domain.
+func managedNodeLabel(key string) bool {
+	domain, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return false
+	}
+	for _, suffix := range []string{"kubernetes.io", "k8s.io", "k3s.io"} {
+		if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeReady reports whether the node has the Ready condition set to True.
+func nodeReady(n corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,54 @@
+package k3s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name string, ready bool, labels map[string]string) corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+func TestNodePoolsFromNodes(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("n1", true, map[string]string{"kompox.dev/node-pool": "system", "kubernetes.io/hostname": "n1", "node.kubernetes.io/instance-type": "k3s", "topology.kubernetes.io/zone": "rack-1", "team": "infra"}),
+		testNode("n2", false, map[string]string{"kompox.dev/node-pool": "system", "kubernetes.io/hostname": "n2", "node.kubernetes.io/instance-type": "k3s", "topology.kubernetes.io/zone": "rack-2", "team": "infra", "gpu": "true"}),
+		testNode("n3", true, map[string]string{"kubernetes.io/hostname": "n3"}),
+	}
+	pools := nodePoolsFromNodes(nodes)
+	if len(pools) != 2 || *pools[0].Name != "default" || *pools[1].Name != "system" {
+		t.Fatalf("unexpected pools: %d", len(pools))
+	}
+
+	def := pools[0]
+	if *def.Mode != "user" || *def.Status.CurrentNodeCount != 1 || *def.Status.ProvisioningState != "Ready" {
+		t.Errorf("default pool = mode %s count %d state %s", *def.Mode, *def.Status.CurrentNodeCount, *def.Status.ProvisioningState)
+	}
+
+	sys := pools[1]
+	if *sys.Mode != "system" {
+		t.Errorf("system pool mode = %s", *sys.Mode)
+	}
+	if *sys.Status.CurrentNodeCount != 2 || *sys.Status.ProvisioningState != "NotReady" || sys.Status.Extensions["readyNodeCount"] != 1 {
+		t.Errorf("system pool status = %+v", sys.Status)
+	}
+	labels := *sys.Labels
+	if len(labels) != 2 || labels["team"] != "infra" || labels["kompox.dev/node-pool"] != "system" {
+		t.Errorf("system pool labels = %v, want shared non-managed labels", labels)
+	}
+	if sys.Zones == nil || len(*sys.Zones) != 2 || (*sys.Zones)[0] != "rack-1" {
+		t.Errorf("system pool zones = %v", sys.Zones)
+	}
+	if sys.InstanceType == nil || *sys.InstanceType != "k3s" {
+		t.Errorf("system pool instance type = %v", sys.InstanceType)
+	}
+}
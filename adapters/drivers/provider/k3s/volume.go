@@ -0,0 +1,575 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/kompox/kompox/internal/naming"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// volumeStorageClassName is the storage class of node-local PVs. It is only used to bind
+// the generated PV and PVC statically and does not need to exist in the cluster.
+const volumeStorageClassName = "kompox-local"
+
+// Timeouts of volume operations. Data copies run in helper pods and may take long.
+const (
+	volumeListTimeout = 1 * time.Minute
+	volumeCopyTimeout = 60 * time.Minute
+)
+
+// volumeSource is a resolved disk or snapshot source.
+type volumeSource struct {
+	Handle string
+	Node   string // node holding the source; empty for S3 archives
+	Dir    string // node directory of a disk source
+	File   string // node archive of a snapshot source
+	URL    string // s3:// URL of a snapshot source
+}
+
+// diskVolume returns the app volume after checking that its type is supported.
+// Only Type="disk" is supported: node-local directories cannot be shared across nodes.
+func diskVolume(app *model.App, volName string) (*model.AppVolume, error) {
+	if app == nil {
+		return nil, fmt.Errorf("app nil")
+	}
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+	if vol.Type != "" && vol.Type != model.VolumeTypeDisk {
+		return nil, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+	return vol, nil
+}
+
+// VolumeDiskList lists local-path disks of a volume from their records.
+func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	if _, err := diskVolume(app, volName); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindDisk, volName)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.VolumeDisk, 0, len(records))
+	for _, r := range records {
+		out = append(out, r.disk())
+	}
+	return out, nil
+}
+
+// VolumeDiskCreate creates a directory on a node, either empty, copied from a disk on the
+// same node or extracted from a snapshot archive.
+func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (disk *model.VolumeDisk, err error) {
+	vol, err := diskVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCopyTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskCreate")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	env, err := d.helperEnv(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindDisk, volName)
+	if err != nil {
+		return nil, err
+	}
+	diskName = strings.TrimSpace(diskName)
+	if diskName == "" {
+		if diskName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, r := range records {
+			if r.Name == diskName {
+				return nil, fmt.Errorf("disk %q already exists", diskName)
+			}
+		}
+	}
+
+	// Merge volume options with functional options
+	volOptions := maps.Clone(vol.Options)
+	if volOptions == nil {
+		volOptions = map[string]any{}
+	}
+	maps.Copy(volOptions, o.Options)
+	vo, err := parseVolumeOptions(volOptions)
+	if err != nil {
+		return nil, err
+	}
+	node := vo.node
+
+	size := vol.Size
+	if o.Size > size {
+		size = o.Size
+	}
+	zone := app.Deployment.Zone
+	if o.Zone != "" {
+		zone = o.Zone
+	}
+
+	// Resolve the source and the node of the new disk
+	var src *volumeSource
+	if source = strings.TrimSpace(source); source != "" {
+		if src, err = d.resolveSource(ctx, kc, cluster, app, volName, source, recordKindSnapshot); err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+	}
+	switch {
+	case src != nil && src.Node != "":
+		// Node-local sources can only be copied on their own node
+		if node != "" && node != src.Node {
+			return nil, fmt.Errorf("source %q is stored on node %q; cannot create disk on node %q", source, src.Node, node)
+		}
+		node = src.Node
+	default:
+		nodes, err := kc.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list nodes: %w", err)
+		}
+		if node, err = selectVolumeNode(nodes.Items, node, zone, app.Deployment.Pool); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := d.diskDir(env.base, app, volName, diskName)
+	dst, err := env.hostPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	task := helperTask{Node: node, Script: scriptMakeDir(dst)}
+	if src != nil {
+		switch {
+		case src.Dir != "":
+			from, err := env.hostPath(src.Dir)
+			if err != nil {
+				return nil, err
+			}
+			task.Script = scriptCopyDir(from, dst)
+		case src.File != "":
+			from, err := env.hostPath(src.File)
+			if err != nil {
+				return nil, err
+			}
+			task.Script = scriptExtractFromFile(from, dst)
+		default:
+			task.Script = scriptExtractFromS3(src.URL, dst, env.awsArgs())
+			task.S3 = true
+		}
+	}
+	if vo.owner != "" || vo.mode != "" {
+		task.Script += " && " + scriptSetPermissions(dst, vo.owner, vo.mode)
+	}
+	if _, err := env.runHelper(ctx, kc, task); err != nil {
+		return nil, err
+	}
+
+	r := &volumeRecord{
+		Kind:        recordKindDisk,
+		Name:        diskName,
+		VolumeName:  volName,
+		Node:        node,
+		Handle:      kube.LocalVolumeHandle(node, dir),
+		Size:        size,
+		Labels:      o.Labels,
+		Description: o.Description,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	if src != nil {
+		r.SourceHandle = src.Handle
+	}
+	if err := d.saveRecord(ctx, kc, cluster, app, r); err != nil {
+		return nil, err
+	}
+	return r.disk(), nil
+}
+
+// VolumeDiskDelete removes the disk directory from its node and deletes the record.
+// With Force, failures to remove the directory (e.g., the node is gone) are logged and ignored.
+// A missing disk is not an error.
+func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) (err error) {
+	if _, err := diskVolume(app, volName); err != nil {
+		return err
+	}
+	var o model.VolumeDiskDeleteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCopyTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskDelete")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	env, err := d.helperEnv(cluster)
+	if err != nil {
+		return err
+	}
+	r, err := d.findRecord(ctx, kc, cluster, app, recordKindDisk, volName, diskName)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		// Already deleted
+		return nil
+	}
+
+	if err := d.removeLocal(ctx, kc, env, r.Handle); err != nil {
+		if !o.Force {
+			return err
+		}
+		logging.FromContext(ctx).Warn(ctx, "failed to remove disk directory; deleting record only", "disk", diskName, "err", err)
+	}
+	return d.deleteRecord(ctx, kc, cluster, app, r)
+}
+
+// VolumeDiskAssign marks the disk as assigned and unassigns the other disks of the volume.
+func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskAssignOption) error {
+	if _, err := diskVolume(app, volName); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindDisk, volName)
+	if err != nil {
+		return err
+	}
+
+	// Find the target disk
+	var found bool
+	for _, r := range records {
+		if r.Name == diskName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("disk not found: %s", diskName)
+	}
+
+	for _, r := range records {
+		assigned := r.Name == diskName
+		if assigned == r.Assigned {
+			continue
+		}
+		r.Assigned = assigned
+		if err := d.saveRecord(ctx, kc, cluster, app, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VolumeDiskUpdate is not supported: local-path disks have no provider options to change.
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, fmt.Errorf("k3s disk update: %w", model.ErrNotSupported)
+}
+
+// VolumeSnapshotList lists tarball snapshots of a volume from their records.
+func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	if _, err := diskVolume(app, volName); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindSnapshot, volName)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.VolumeSnapshot, 0, len(records))
+	for _, r := range records {
+		out = append(out, r.snapshot())
+	}
+	return out, nil
+}
+
+// VolumeSnapshotCreate archives a disk directory as tar.zst to the node or to S3
+// depending on K3S_SNAPSHOT_TARGET. An empty source selects the assigned disk.
+func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (snap *model.VolumeSnapshot, err error) {
+	if _, err := diskVolume(app, volName); err != nil {
+		return nil, err
+	}
+	var o model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCopyTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotCreate")
+	defer func() { cleanup(err) }()
+
+	target, err := d.snapshotTarget(cluster)
+	if err != nil {
+		return nil, err
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	env, err := d.helperEnv(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindSnapshot, volName)
+	if err != nil {
+		return nil, err
+	}
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		if snapName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, r := range records {
+			if r.Name == snapName {
+				return nil, fmt.Errorf("snapshot %q already exists", snapName)
+			}
+		}
+	}
+
+	// Determine the source disk directory
+	var src *volumeSource
+	if source = strings.TrimSpace(source); source == "" {
+		disks, err := d.listRecords(ctx, kc, cluster, app, recordKindDisk, volName)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range disks {
+			if r.Assigned {
+				src, err = parseSourceHandle(r.Handle)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		if src == nil {
+			return nil, fmt.Errorf("no assigned disk found for volume %q", volName)
+		}
+	} else if src, err = d.resolveSource(ctx, kc, cluster, app, volName, source, recordKindDisk); err != nil {
+		return nil, fmt.Errorf("resolve source %q: %w", source, err)
+	}
+	if src.Dir == "" {
+		return nil, fmt.Errorf("snapshot source %q is not a disk", source)
+	}
+	from, err := env.hostPath(src.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &volumeRecord{
+		Kind:         recordKindSnapshot,
+		Name:         snapName,
+		VolumeName:   volName,
+		Labels:       o.Labels,
+		Description:  o.Description,
+		SourceHandle: src.Handle,
+	}
+	task := helperTask{Node: src.Node}
+	switch target {
+	case snapshotTargetS3:
+		url := env.s3URL(d.snapshotObjectKey(cluster, app, volName, snapName))
+		task.Script = scriptArchiveToS3(from, url, env.awsArgs())
+		task.S3 = true
+		r.Handle = url
+	default:
+		file := d.snapshotFile(env.base, app, volName, snapName)
+		to, err := env.hostPath(file)
+		if err != nil {
+			return nil, err
+		}
+		task.Script = scriptArchiveToFile(from, to)
+		r.Node = src.Node
+		r.Handle = kube.LocalVolumeHandle(src.Node, file)
+	}
+	logs, err := env.runHelper(ctx, kc, task)
+	if err != nil {
+		return nil, err
+	}
+	r.Size = parseHelperSize(logs)
+	r.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	if err := d.saveRecord(ctx, kc, cluster, app, r); err != nil {
+		return nil, err
+	}
+	return r.snapshot(), nil
+}
+
+// VolumeSnapshotDelete removes the snapshot archive and deletes the record.
+// With Force, failures to remove the archive are logged and ignored. A missing snapshot is not an error.
+func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) (err error) {
+	if _, err := diskVolume(app, volName); err != nil {
+		return err
+	}
+	var o model.VolumeSnapshotDeleteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCopyTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotDelete")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	env, err := d.helperEnv(cluster)
+	if err != nil {
+		return err
+	}
+	r, err := d.findRecord(ctx, kc, cluster, app, recordKindSnapshot, volName, snapName)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		// Already deleted
+		return nil
+	}
+
+	if err := d.removeLocal(ctx, kc, env, r.Handle); err != nil {
+		if !o.Force {
+			return err
+		}
+		logging.FromContext(ctx).Warn(ctx, "failed to remove snapshot archive; deleting record only", "snapshot", snapName, "err", err)
+	}
+	return d.deleteRecord(ctx, kc, cluster, app, r)
+}
+
+// VolumeClass returns node-local PV parameters. Volume options are validated so that
+// invalid settings are reported before any disk is created.
+func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	if vol.Type != "" && vol.Type != model.VolumeTypeDisk {
+		return model.VolumeClass{}, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+	if _, err := parseVolumeOptions(vol.Options); err != nil {
+		return model.VolumeClass{}, err
+	}
+	return model.VolumeClass{
+		StorageClassName: volumeStorageClassName,
+		AccessModes:      []string{"ReadWriteOnce"},
+		ReclaimPolicy:    "Retain",
+		VolumeMode:       "Filesystem",
+		NodeLocal:        true,
+	}, nil
+}
+
+// removeLocal removes the directory, node archive or S3 object referenced by handle.
+func (d *driver) removeLocal(ctx context.Context, kc *kube.Client, env *helperEnv, handle string) error {
+	src, err := parseSourceHandle(handle)
+	if err != nil {
+		return err
+	}
+	task := helperTask{Node: src.Node}
+	switch {
+	case src.URL != "":
+		task.Script = scriptRemoveS3(src.URL, env.awsArgs())
+		task.S3 = true
+	default:
+		target := src.Dir
+		if src.File != "" {
+			target = src.File
+		}
+		p, err := env.hostPath(target)
+		if err != nil {
+			return err
+		}
+		task.Script = scriptRemove(p)
+	}
+	_, err = env.runHelper(ctx, kc, task)
+	return err
+}
+
+// resolveSource resolves a source string to a disk directory or snapshot archive.
+//   - "disk:<name>" / "snapshot:<name>" -> Kompox managed disk / snapshot of the volume
+//   - "local://<node>/<path>" / "s3://<bucket>/<key>" -> handle as-is (e.g., resolved app: sources)
+//   - Others -> name of defaultKind
+func (d *driver) resolveSource(ctx context.Context, kc *kube.Client, cluster *model.Cluster, app *model.App, volName, source, defaultKind string) (*volumeSource, error) {
+	kind, name := defaultKind, source
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lower, kube.LocalVolumeScheme+"://"), strings.HasPrefix(lower, "s3://"):
+		return parseSourceHandle(source)
+	case strings.HasPrefix(lower, "disk:"):
+		kind, name = recordKindDisk, source[5:]
+	case strings.HasPrefix(lower, "snapshot:"):
+		kind, name = recordKindSnapshot, source[9:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%s name cannot be empty", kind)
+	}
+	r, err := d.findRecord(ctx, kc, cluster, app, kind, volName, name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%s not found: %s", kind, name)
+	}
+	return parseSourceHandle(r.Handle)
+}
+
+// parseSourceHandle classifies a disk or snapshot handle.
+func parseSourceHandle(handle string) (*volumeSource, error) {
+	if strings.HasPrefix(strings.ToLower(handle), "s3://") {
+		return &volumeSource{Handle: handle, URL: handle}, nil
+	}
+	node, p, err := kube.ParseLocalVolumeHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+	src := &volumeSource{Handle: handle, Node: node}
+	if strings.HasSuffix(p, snapshotArchiveExt) {
+		src.File = p
+	} else {
+		src.Dir = p
+	}
+	return src, nil
+}
@@ -0,0 +1,328 @@
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// helperMountPath is where K3S_VOLUME_PATH is mounted in helper pods.
+const helperMountPath = "/data"
+
+// helperS3SecretName is the Secret holding S3 credentials for helper pods.
+const helperS3SecretName = "kompox-snapshot-s3"
+
+// helperPollInterval is the interval between helper pod status checks.
+const helperPollInterval = 2 * time.Second
+
+// helperSizePrefix prefixes the line reporting an archive size in helper pod logs.
+const helperSizePrefix = "KOMPOX_SIZE="
+
+// helperTask is a shell script run by a short-lived helper pod.
+type helperTask struct {
+	// Node pins the pod and mounts K3S_VOLUME_PATH when non-empty.
+	Node string
+	// Script is the shell script body (run with sh -c after the common preamble).
+	Script string
+	// S3 injects S3 credentials; the helper image must provide aws-cli.
+	S3 bool
+}
+
+// helperEnv holds the resolved settings needed to build and run helper tasks.
+type helperEnv struct {
+	namespace string
+	image     string
+	base      string // K3S_VOLUME_PATH on nodes
+	s3        s3Target
+}
+
+// s3Target holds the S3-compatible snapshot target settings.
+type s3Target struct {
+	endpoint string
+	bucket   string
+	region   string
+	keyID    string
+	secret   string
+}
+
+// helperEnv resolves helper pod settings for the cluster.
+func (d *driver) helperEnv(cluster *model.Cluster) (*helperEnv, error) {
+	base, err := d.volumeBasePath(cluster)
+	if err != nil {
+		return nil, err
+	}
+	image := d.setting(cluster, keyHelperImage)
+	if image == "" {
+		image = defaultHelperImage
+	}
+	return &helperEnv{
+		namespace: d.volumeNamespace(cluster),
+		image:     image,
+		base:      base,
+		s3: s3Target{
+			endpoint: d.setting(cluster, keyS3Endpoint),
+			bucket:   d.setting(cluster, keyS3Bucket),
+			region:   d.setting(cluster, keyS3Region),
+			keyID:    d.setting(cluster, keyS3AccessKeyID),
+			secret:   d.setting(cluster, keyS3SecretKey),
+		},
+	}, nil
+}
+
+// hostPath maps a node path under K3S_VOLUME_PATH to the helper pod mount.
+func (e *helperEnv) hostPath(p string) (string, error) {
+	rel, ok := strings.CutPrefix(path.Clean(p), e.base+"/")
+	if !ok || rel == "" {
+		return "", fmt.Errorf("path %q is outside %s %q", p, keyVolumePath, e.base)
+	}
+	return path.Join(helperMountPath, rel), nil
+}
+
+// s3URL returns the s3:// URL of an object key in the snapshot bucket.
+func (e *helperEnv) s3URL(key string) string {
+	return "s3://" + e.s3.bucket + "/" + key
+}
+
+// awsArgs returns extra aws-cli arguments (custom endpoint for S3-compatible stores).
+func (e *helperEnv) awsArgs() string {
+	if e.s3.endpoint == "" {
+		return ""
+	}
+	return " --endpoint-url " + shellQuote(e.s3.endpoint)
+}
+
+// shellQuote quotes s for POSIX sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Helper scripts. Paths are helper pod paths (see hostPath); s3 arguments are s3:// URLs.
+
+func scriptMakeDir(dir string) string {
+	return fmt.Sprintf("mkdir -p %s", shellQuote(dir))
+}
+
+// scriptSetPermissions applies the owner (chown argument) and mode (octal) requested by
+// volume options to the top directory of a disk. Empty values are left unchanged.
+func scriptSetPermissions(dir, owner, mode string) string {
+	var cmds []string
+	if owner != "" {
+		cmds = append(cmds, fmt.Sprintf("chown %s %s", shellQuote(owner), shellQuote(dir)))
+	}
+	if mode != "" {
+		cmds = append(cmds, fmt.Sprintf("chmod %s %s", shellQuote(mode), shellQuote(dir)))
+	}
+	return strings.Join(cmds, " && ")
+}
+
+func scriptRemove(p string) string {
+	return fmt.Sprintf("rm -rf %s", shellQuote(p))
+}
+
+func scriptCopyDir(src, dst string) string {
+	return fmt.Sprintf("test -d %s && %s && cp -a %s/. %s/", shellQuote(src), scriptMakeDir(dst), shellQuote(src), shellQuote(dst))
+}
+
+func scriptArchiveToFile(src, file string) string {
+	qs, qf, qt := shellQuote(src), shellQuote(file), shellQuote(file+".tmp")
+	return fmt.Sprintf("test -d %s && mkdir -p %s && tar -C %s -cf - . | zstd -q -T0 -o %s && mv %s %s && echo %s$(stat -c %%s %s)",
+		qs, shellQuote(path.Dir(file)), qs, qt, qt, qf, helperSizePrefix, qf)
+}
+
+func scriptArchiveToS3(src, url, awsArgs string) string {
+	qs, qu := shellQuote(src), shellQuote(url)
+	return fmt.Sprintf("test -d %s && tar -C %s -cf - . | zstd -q -T0 -c | aws s3 cp - %s%s && echo %s$(aws s3 ls %s%s | awk '{print $3}')",
+		qs, qs, qu, awsArgs, helperSizePrefix, qu, awsArgs)
+}
+
+func scriptExtractFromFile(file, dst string) string {
+	return fmt.Sprintf("test -f %s && %s && zstd -dc %s | tar -C %s -xf -", shellQuote(file), scriptMakeDir(dst), shellQuote(file), shellQuote(dst))
+}
+
+func scriptExtractFromS3(url, dst, awsArgs string) string {
+	return fmt.Sprintf("%s && aws s3 cp %s -%s | zstd -dc | tar -C %s -xf -", scriptMakeDir(dst), shellQuote(url), awsArgs, shellQuote(dst))
+}
+
+func scriptRemoveS3(url, awsArgs string) string {
+	return fmt.Sprintf("aws s3 rm %s%s", shellQuote(url), awsArgs)
+}
+
+// helperScript returns the full script with the common preamble.
+// Tools are never installed at runtime; the preamble fails fast when the helper image
+// (K3S_HELPER_IMAGE) lacks zstd or aws-cli.
+func helperScript(t helperTask) string {
+	var b strings.Builder
+	b.WriteString("set -eu\nset -o pipefail\n")
+	if strings.Contains(t.Script, "zstd") {
+		b.WriteString(scriptRequireTool("zstd"))
+	}
+	if t.S3 {
+		b.WriteString(scriptRequireTool("aws"))
+	}
+	b.WriteString(t.Script)
+	b.WriteString("\n")
+	return b.String()
+}
+
+// scriptRequireTool returns a preamble line failing when tool is not in the helper image.
+func scriptRequireTool(tool string) string {
+	return fmt.Sprintf("command -v %s >/dev/null 2>&1 || { echo %s >&2; exit 127; }\n", tool, shellQuote(tool+" not found in helper image (set "+keyHelperImage+")"))
+}
+
+// helperPod builds the helper pod for a task.
+func (e *helperEnv) helperPod(t helperTask) *corev1.Pod {
+	container := corev1.Container{
+		Name:    "helper",
+		Image:   e.image,
+		Command: []string{"/bin/sh", "-c", helperScript(t)},
+	}
+	spec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+	}
+	if t.Node != "" {
+		spec.NodeName = t.Node
+		spec.Volumes = []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
+				Path: e.base,
+				Type: ptr(corev1.HostPathDirectoryOrCreate),
+			}},
+		}}
+		container.VolumeMounts = []corev1.VolumeMount{{Name: "data", MountPath: helperMountPath}}
+	}
+	if t.S3 {
+		if e.s3.keyID != "" {
+			container.EnvFrom = []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: helperS3SecretName},
+			}}}
+		}
+		if e.s3.region != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "AWS_DEFAULT_REGION", Value: e.s3.region})
+		}
+	}
+	spec.Containers = []corev1.Container{container}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kompox-volume-helper-",
+			Namespace:    e.namespace,
+			Labels:       map[string]string{kube.LabelAppK8sManagedBy: "kompox", kube.LabelAppK8sComponent: "volume-helper"},
+		},
+		Spec: spec,
+	}
+}
+
+// ensureS3Secret creates or updates the Secret holding S3 credentials for helper pods.
+func (e *helperEnv) ensureS3Secret(ctx context.Context, kc *kube.Client) error {
+	if e.s3.keyID == "" {
+		return nil
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: helperS3SecretName, Namespace: e.namespace},
+		StringData: map[string]string{
+			"AWS_ACCESS_KEY_ID":     e.s3.keyID,
+			"AWS_SECRET_ACCESS_KEY": e.s3.secret,
+		},
+	}
+	client := kc.Clientset.CoreV1().Secrets(e.namespace)
+	if _, err := client.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create secret %s/%s: %w", e.namespace, helperS3SecretName, err)
+		}
+		if _, err := client.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update secret %s/%s: %w", e.namespace, helperS3SecretName, err)
+		}
+	}
+	return nil
+}
+
+// runHelper runs a task in a helper pod, waits for completion and returns the pod logs.
+// The pod is deleted when done regardless of the outcome.
+func (e *helperEnv) runHelper(ctx context.Context, kc *kube.Client, t helperTask) (string, error) {
+	if err := kc.CreateNamespace(ctx, e.namespace); err != nil {
+		return "", err
+	}
+	if t.S3 {
+		if err := e.ensureS3Secret(ctx, kc); err != nil {
+			return "", err
+		}
+	}
+
+	pods := kc.Clientset.CoreV1().Pods(e.namespace)
+	pod, err := pods.Create(ctx, e.helperPod(t), metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("create helper pod: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so that cleanup also runs after timeouts
+		delCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = pods.Delete(delCtx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: ptr(int64(0))})
+	}()
+
+	ticker := time.NewTicker(helperPollInterval)
+	defer ticker.Stop()
+	for {
+		cur, err := pods.Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("get helper pod %s: %w", pod.Name, err)
+		}
+		switch cur.Status.Phase {
+		case corev1.PodSucceeded:
+			return helperLogs(ctx, kc, e.namespace, pod.Name), nil
+		case corev1.PodFailed:
+			logs := helperLogs(ctx, kc, e.namespace, pod.Name)
+			return logs, fmt.Errorf("helper pod %s failed on node %q: %s", pod.Name, t.Node, lastLines(logs, 5))
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("wait helper pod %s: %w", pod.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// helperLogs returns the logs of a helper pod (empty on error).
+func helperLogs(ctx context.Context, kc *kube.Client, ns, name string) string {
+	b, err := kc.Clientset.CoreV1().Pods(ns).GetLogs(name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// lastLines returns the last n non-empty lines of s joined with "; ".
+func lastLines(s string, n int) string {
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "; ")
+}
+
+// parseHelperSize returns the archive size reported by a helper script (0 if missing).
+func parseHelperSize(logs string) int64 {
+	var size int64
+	for _, l := range strings.Split(logs, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(l), helperSizePrefix); ok {
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				size = n
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,397 @@
+package k3s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Volume setting keys. Each key may be set in Provider settings and overridden per Cluster.
+const (
+	keyVolumePath      = "K3S_VOLUME_PATH"      // node directory holding volume disks
+	keyVolumeNamespace = "K3S_VOLUME_NAMESPACE" // namespace of volume records and helper pods
+	keyHelperImage     = "K3S_HELPER_IMAGE"     // helper pod image (sh, tar, zstd and aws-cli)
+	keySnapshotTarget  = "K3S_SNAPSHOT_TARGET"  // snapshot storage: node or s3
+	keyS3Endpoint      = "K3S_SNAPSHOT_S3_ENDPOINT"
+	keyS3Bucket        = "K3S_SNAPSHOT_S3_BUCKET"
+	keyS3Prefix        = "K3S_SNAPSHOT_S3_PREFIX"
+	keyS3Region        = "K3S_SNAPSHOT_S3_REGION"
+	keyS3AccessKeyID   = "K3S_SNAPSHOT_S3_ACCESS_KEY_ID"
+	keyS3SecretKey     = "K3S_SNAPSHOT_S3_SECRET_ACCESS_KEY"
+)
+
+// Volume setting defaults.
+const (
+	defaultVolumePath      = "/var/lib/kompox/volumes"
+	defaultVolumeNamespace = "kompox-volumes"
+	defaultHelperImage     = "ghcr.io/kompox/kompox/volume-helper:latest"
+)
+
+// Snapshot targets selected by K3S_SNAPSHOT_TARGET.
+const (
+	snapshotTargetNode = "node" // tar.zst archive under <K3S_VOLUME_PATH>/.snapshots on the disk node
+	snapshotTargetS3   = "s3"   // tar.zst archive in an S3-compatible bucket
+)
+
+// Volume option keys accepted in app.volumes.options and disk create -O.
+const (
+	volumeOptionNode = "node" // node to place the disk on
+	volumeOptionUID  = "uid"  // owner user ID of the disk directory
+	volumeOptionGID  = "gid"  // owner group ID of the disk directory
+	volumeOptionMode = "mode" // permission bits of the disk directory (octal string, e.g. "0750")
+	volumeOptionPath = "path" // node directory of the disk (read-only, reported in disk options)
+)
+
+// Volume record kinds and labels of the ConfigMaps holding them.
+const (
+	recordKindDisk     = "disk"
+	recordKindSnapshot = "snapshot"
+
+	labelRecordKind   = kube.K4xDomain + "/volume-record"
+	labelRecordVolume = kube.K4xDomain + "/volume"
+	recordDataKey     = "record.json"
+)
+
+// snapshotArchiveExt is the file extension of snapshot archives.
+const snapshotArchiveExt = ".tar.zst"
+
+// volumeRecord is the metadata of a disk or snapshot persisted as a ConfigMap.
+// Local directories and archives carry no metadata of their own, so the record is the
+// source of truth for listing; helper pods only touch node storage.
+type volumeRecord struct {
+	Kind         string            `json:"kind"`
+	Name         string            `json:"name"`
+	VolumeName   string            `json:"volumeName"`
+	Node         string            `json:"node,omitempty"` // empty for snapshots stored in S3
+	Handle       string            `json:"handle"`
+	Size         int64             `json:"size"`
+	Assigned     bool              `json:"assigned,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	SourceHandle string            `json:"sourceHandle,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+func (r *volumeRecord) disk() *model.VolumeDisk {
+	options := map[string]any{volumeOptionNode: r.Node}
+	if _, dir, err := kube.ParseLocalVolumeHandle(r.Handle); err == nil {
+		options[volumeOptionPath] = dir
+	}
+	return &model.VolumeDisk{
+		Name:         r.Name,
+		VolumeName:   r.VolumeName,
+		Assigned:     r.Assigned,
+		Size:         r.Size,
+		Zone:         r.Node,
+		Options:      options,
+		Handle:       r.Handle,
+		Labels:       r.Labels,
+		Description:  r.Description,
+		SourceHandle: r.SourceHandle,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.CreatedAt,
+	}
+}
+
+func (r *volumeRecord) snapshot() *model.VolumeSnapshot {
+	return &model.VolumeSnapshot{
+		Name:         r.Name,
+		VolumeName:   r.VolumeName,
+		Size:         r.Size,
+		Handle:       r.Handle,
+		Labels:       r.Labels,
+		Description:  r.Description,
+		SourceHandle: r.SourceHandle,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.CreatedAt,
+	}
+}
+
+// volumeNamespace returns the namespace of volume records and helper pods.
+func (d *driver) volumeNamespace(cluster *model.Cluster) string {
+	if v := d.setting(cluster, keyVolumeNamespace); v != "" {
+		return v
+	}
+	return defaultVolumeNamespace
+}
+
+// volumeBasePath returns the node directory holding volume disks and node snapshots.
+func (d *driver) volumeBasePath(cluster *model.Cluster) (string, error) {
+	base := d.setting(cluster, keyVolumePath)
+	if base == "" {
+		base = defaultVolumePath
+	}
+	if !path.IsAbs(base) {
+		return "", fmt.Errorf("%s must be an absolute path: %q", keyVolumePath, base)
+	}
+	base = path.Clean(base)
+	if base == "/" {
+		return "", fmt.Errorf("%s must not be the root directory", keyVolumePath)
+	}
+	return base, nil
+}
+
+// snapshotTarget returns the validated K3S_SNAPSHOT_TARGET.
+func (d *driver) snapshotTarget(cluster *model.Cluster) (string, error) {
+	switch v := d.setting(cluster, keySnapshotTarget); v {
+	case "":
+		return snapshotTargetNode, nil
+	case snapshotTargetNode:
+		return v, nil
+	case snapshotTargetS3:
+		if d.setting(cluster, keyS3Bucket) == "" {
+			return "", fmt.Errorf("%s=%s requires %s", keySnapshotTarget, v, keyS3Bucket)
+		}
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q (expected %s or %s)", keySnapshotTarget, v, snapshotTargetNode, snapshotTargetS3)
+	}
+}
+
+// appDirName returns the per-app directory name, unique per workspace/provider/app.
+func (d *driver) appDirName(app *model.App) string {
+	return naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name).Namespace
+}
+
+// diskDir returns the node directory of a disk: <base>/<app>/<vol>/<disk>.
+func (d *driver) diskDir(base string, app *model.App, volName, diskName string) string {
+	return path.Join(base, d.appDirName(app), volName, diskName)
+}
+
+// snapshotFile returns the node archive of a snapshot: <base>/.snapshots/<app>/<vol>/<snap>.tar.zst.
+func (d *driver) snapshotFile(base string, app *model.App, volName, snapName string) string {
+	return path.Join(base, ".snapshots", d.appDirName(app), volName, snapName+snapshotArchiveExt)
+}
+
+// snapshotObjectKey returns the S3 object key of a snapshot: [<prefix>/]<app>/<vol>/<snap>.tar.zst.
+func (d *driver) snapshotObjectKey(cluster *model.Cluster, app *model.App, volName, snapName string) string {
+	return path.Join(strings.Trim(d.setting(cluster, keyS3Prefix), "/"), d.appDirName(app), volName, snapName+snapshotArchiveExt)
+}
+
+// recordName returns the ConfigMap name of a volume record.
+func (d *driver) recordName(app *model.App, kind, volName, name string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", d.appDirName(app), kind, volName, name)
+}
+
+// recordLabels returns the labels identifying volume records of an app volume.
+func (d *driver) recordLabels(app *model.App, kind, volName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	return map[string]string{
+		kube.LabelAppK8sManagedBy: "kompox",
+		kube.LabelK4xAppIDHash:    h.AppID,
+		labelRecordKind:           kind,
+		labelRecordVolume:         volName,
+	}
+}
+
+// listRecords returns the records of kind for an app volume, newest first.
+func (d *driver) listRecords(ctx context.Context, kc *kube.Client, cluster *model.Cluster, app *model.App, kind, volName string) ([]*volumeRecord, error) {
+	ns := d.volumeNamespace(cluster)
+	selector := labels.SelectorFromSet(d.recordLabels(app, kind, volName)).String()
+	cms, err := kc.Clientset.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list %s records: %w", kind, err)
+	}
+	var out []*volumeRecord
+	for i := range cms.Items {
+		var r volumeRecord
+		if err := json.Unmarshal([]byte(cms.Items[i].Data[recordDataKey]), &r); err != nil {
+			continue
+		}
+		out = append(out, &r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// findRecord returns the named record or nil if it does not exist.
+func (d *driver) findRecord(ctx context.Context, kc *kube.Client, cluster *model.Cluster, app *model.App, kind, volName, name string) (*volumeRecord, error) {
+	records, err := d.listRecords(ctx, kc, cluster, app, kind, volName)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// saveRecord creates or replaces the ConfigMap of a record.
+func (d *driver) saveRecord(ctx context.Context, kc *kube.Client, cluster *model.Cluster, app *model.App, r *volumeRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal %s record: %w", r.Kind, err)
+	}
+	ns := d.volumeNamespace(cluster)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        d.recordName(app, r.Kind, r.VolumeName, r.Name),
+			Namespace:   ns,
+			Labels:      d.recordLabels(app, r.Kind, r.VolumeName),
+			Annotations: map[string]string{kube.AnnotationK4xApp: app.Name},
+		},
+		Data: map[string]string{recordDataKey: string(data)},
+	}
+	cmClient := kc.Clientset.CoreV1().ConfigMaps(ns)
+	existing, err := cmClient.Get(ctx, cm.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := cmClient.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create %s record %s: %w", r.Kind, cm.Name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("get %s record %s: %w", r.Kind, cm.Name, err)
+	default:
+		cm.ResourceVersion = existing.ResourceVersion
+		if _, err := cmClient.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update %s record %s: %w", r.Kind, cm.Name, err)
+		}
+	}
+	return nil
+}
+
+// deleteRecord deletes the ConfigMap of a record. NotFound is treated as success.
+func (d *driver) deleteRecord(ctx context.Context, kc *kube.Client, cluster *model.Cluster, app *model.App, r *volumeRecord) error {
+	name := d.recordName(app, r.Kind, r.VolumeName, r.Name)
+	err := kc.Clientset.CoreV1().ConfigMaps(d.volumeNamespace(cluster)).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s record %s: %w", r.Kind, name, err)
+	}
+	return nil
+}
+
+// selectVolumeNode chooses the node of a new disk. An explicit node wins; otherwise zone
+// matches a node name or a zone label, and the app deployment pool narrows the candidates.
+// The first Ready schedulable candidate in name order is returned.
+func selectVolumeNode(nodes []corev1.Node, node, zone, pool string) (string, error) {
+	byName := map[string]corev1.Node{}
+	for _, n := range nodes {
+		byName[n.Name] = n
+	}
+	if node != "" {
+		if _, ok := byName[node]; !ok {
+			return "", fmt.Errorf("node %q not found", node)
+		}
+		return node, nil
+	}
+	if _, ok := byName[zone]; ok && zone != "" {
+		return zone, nil
+	}
+
+	var candidates []string
+	for _, n := range nodes {
+		if n.Spec.Unschedulable || !nodeReady(n) {
+			continue
+		}
+		if zone != "" && n.Labels[labelZone] != zone && n.Labels[kube.LabelK4xNodeZone] != zone {
+			continue
+		}
+		if pool != "" && n.Labels[kube.LabelK4xNodePool] != pool {
+			continue
+		}
+		candidates = append(candidates, n.Name)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no ready node found (zone=%q pool=%q)", zone, pool)
+	}
+	sort.Strings(candidates)
+	return candidates[0], nil
+}
+
+// volumeOptions holds parsed k3s volume options.
+type volumeOptions struct {
+	node  string
+	owner string // chown argument ("uid", "uid:gid" or ":gid"); empty keeps root ownership
+	mode  string // chmod argument (octal); empty keeps the mkdir default
+}
+
+// parseVolumeOptions validates k3s volume options.
+func parseVolumeOptions(options map[string]any) (volumeOptions, error) {
+	var o volumeOptions
+	uid, gid := "", ""
+	for k, v := range options {
+		switch k {
+		case volumeOptionNode:
+			s, ok := v.(string)
+			if !ok {
+				return o, fmt.Errorf("%w: option %q must be a string", model.ErrVolumeOptionsInvalid, k)
+			}
+			o.node = strings.TrimSpace(s)
+		case volumeOptionUID, volumeOptionGID:
+			id, err := parseVolumeID(v)
+			if err != nil {
+				return o, fmt.Errorf("%w: option %q %v", model.ErrVolumeOptionsInvalid, k, err)
+			}
+			if k == volumeOptionUID {
+				uid = id
+			} else {
+				gid = id
+			}
+		case volumeOptionMode:
+			s, ok := v.(string)
+			if !ok {
+				return o, fmt.Errorf("%w: option %q must be an octal string such as \"0750\"", model.ErrVolumeOptionsInvalid, k)
+			}
+			s = strings.TrimSpace(s)
+			if n, err := strconv.ParseUint(s, 8, 32); err != nil || n > 0o7777 {
+				return o, fmt.Errorf("%w: option %q must be an octal string such as \"0750\", got %q", model.ErrVolumeOptionsInvalid, k, s)
+			}
+			o.mode = s
+		default:
+			return o, fmt.Errorf("%w: unknown option %q (supported: %s, %s, %s, %s)", model.ErrVolumeOptionsInvalid, k, volumeOptionNode, volumeOptionUID, volumeOptionGID, volumeOptionMode)
+		}
+	}
+	switch {
+	case uid != "" && gid != "":
+		o.owner = uid + ":" + gid
+	case uid != "":
+		o.owner = uid
+	case gid != "":
+		o.owner = ":" + gid
+	}
+	return o, nil
+}
+
+// parseVolumeID returns a non-negative numeric user or group ID given as a number or string.
+func parseVolumeID(v any) (string, error) {
+	var s string
+	switch t := v.(type) {
+	case int:
+		s = strconv.Itoa(t)
+	case int64:
+		s = strconv.FormatInt(t, 10)
+	case uint64:
+		s = strconv.FormatUint(t, 10)
+	case float64:
+		if t != float64(int64(t)) {
+			return "", fmt.Errorf("must be an integer, got %v", t)
+		}
+		s = strconv.FormatInt(int64(t), 10)
+	case string:
+		s = strings.TrimSpace(t)
+	default:
+		return "", fmt.Errorf("must be an integer, got %T", v)
+	}
+	if _, err := strconv.ParseUint(s, 10, 32); err != nil {
+		return "", fmt.Errorf("must be a non-negative integer, got %q", s)
+	}
+	return s, nil
+}
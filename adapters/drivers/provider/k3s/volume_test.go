@@ -0,0 +1,205 @@
+package k3s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSelectVolumeNode(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("n3", true, map[string]string{labelZone: "z1", kube.LabelK4xNodePool: "user"}),
+		testNode("n2", true, map[string]string{labelZone: "z2", kube.LabelK4xNodePool: "user"}),
+		testNode("n1", false, map[string]string{labelZone: "z1", kube.LabelK4xNodePool: "user"}),
+		testNode("s1", true, map[string]string{kube.LabelK4xNodePool: "system"}),
+	}
+	tests := []struct {
+		name, node, zone, pool string
+		want                   string
+		wantErr                bool
+	}{
+		{name: "explicit node", node: "n1", want: "n1"},
+		{name: "unknown node", node: "nx", wantErr: true},
+		{name: "zone as node name", zone: "n2", want: "n2"},
+		{name: "zone label skips not ready", zone: "z1", want: "n3"},
+		{name: "pool", pool: "system", want: "s1"},
+		{name: "first ready", want: "n2"},
+		{name: "no match", zone: "z9", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectVolumeNode(nodes, tt.node, tt.zone, tt.pool)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVolumeOptions(t *testing.T) {
+	if o, err := parseVolumeOptions(map[string]any{"node": " n1 "}); err != nil || o.node != "n1" || o.owner != "" || o.mode != "" {
+		t.Errorf("got %+v, %v", o, err)
+	}
+	if o, err := parseVolumeOptions(map[string]any{"uid": float64(1000), "gid": "2000", "mode": "0750"}); err != nil || o.owner != "1000:2000" || o.mode != "0750" {
+		t.Errorf("got %+v, %v", o, err)
+	}
+	if o, err := parseVolumeOptions(map[string]any{"gid": 2000}); err != nil || o.owner != ":2000" {
+		t.Errorf("got %+v, %v", o, err)
+	}
+	for _, bad := range []map[string]any{{"uid": -1}, {"uid": 1.5}, {"gid": "root"}, {"mode": 750}, {"mode": "0999"}, {"mode": "17777"}} {
+		if _, err := parseVolumeOptions(bad); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+			t.Errorf("%v: expected ErrVolumeOptionsInvalid, got %v", bad, err)
+		}
+	}
+	if _, err := parseVolumeOptions(map[string]any{"sku": "x"}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+	if _, err := parseVolumeOptions(map[string]any{"node": 1}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+}
+
+func TestParseSourceHandle(t *testing.T) {
+	src, err := parseSourceHandle("local://n1/var/lib/kompox/volumes/a/db/d1")
+	if err != nil || src.Node != "n1" || src.Dir != "/var/lib/kompox/volumes/a/db/d1" || src.File != "" {
+		t.Errorf("disk handle: %+v, %v", src, err)
+	}
+	src, err = parseSourceHandle("local://n1/var/lib/kompox/volumes/.snapshots/a/db/s1.tar.zst")
+	if err != nil || src.File == "" || src.Dir != "" {
+		t.Errorf("snapshot handle: %+v, %v", src, err)
+	}
+	src, err = parseSourceHandle("s3://bucket/a/db/s1.tar.zst")
+	if err != nil || src.URL != "s3://bucket/a/db/s1.tar.zst" || src.Node != "" {
+		t.Errorf("s3 handle: %+v, %v", src, err)
+	}
+	if _, err := parseSourceHandle("/subscriptions/x"); err == nil {
+		t.Errorf("expected error for foreign handle")
+	}
+}
+
+func TestHelperEnv(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", settings: map[string]string{keyVolumePath: "/srv/vol/"}}
+	env, err := d.helperEnv(&model.Cluster{Name: "c"})
+	if err != nil {
+		t.Fatalf("helperEnv: %v", err)
+	}
+	if p, err := env.hostPath("/srv/vol/app/db/d1"); err != nil || p != "/data/app/db/d1" {
+		t.Errorf("hostPath = %q, %v", p, err)
+	}
+	if _, err := env.hostPath("/srv/other/d1"); err == nil {
+		t.Errorf("expected error for path outside base")
+	}
+
+	pod := env.helperPod(helperTask{Node: "n1", Script: scriptMakeDir("/data/app/db/d1")})
+	if pod.Spec.NodeName != "n1" || pod.Spec.Volumes[0].HostPath.Path != "/srv/vol" || pod.Namespace != defaultVolumeNamespace {
+		t.Errorf("unexpected pod: %+v", pod.Spec)
+	}
+	script := pod.Spec.Containers[0].Command[2]
+	if strings.Contains(script, "command -v") || strings.Contains(script, "chmod") {
+		t.Errorf("mkdir script should neither check tools nor change mode: %s", script)
+	}
+	if got := scriptSetPermissions("/data/d1", "1000:2000", "0750"); got != "chown '1000:2000' '/data/d1' && chmod '0750' '/data/d1'" {
+		t.Errorf("scriptSetPermissions = %s", got)
+	}
+
+	script = helperScript(helperTask{Script: scriptArchiveToS3("/data/d1", "s3://b/k", ""), S3: true})
+	if !strings.Contains(script, "command -v zstd") || !strings.Contains(script, "command -v aws") || strings.Contains(script, "apk") {
+		t.Errorf("s3 script should require zstd and aws without installing them: %s", script)
+	}
+
+	if _, err := (&driver{settings: map[string]string{keyVolumePath: "rel"}}).helperEnv(nil); err == nil {
+		t.Errorf("expected error for relative volume path")
+	}
+}
+
+func TestParseHelperSize(t *testing.T) {
+	if got := parseHelperSize("installing\nKOMPOX_SIZE=1234\n"); got != 1234 {
+		t.Errorf("got %d", got)
+	}
+	if got := parseHelperSize("no size"); got != 0 {
+		t.Errorf("got %d", got)
+	}
+}
+
+func TestSnapshotTarget(t *testing.T) {
+	d := &driver{settings: map[string]string{}}
+	if v, err := d.snapshotTarget(nil); err != nil || v != snapshotTargetNode {
+		t.Errorf("default: %q, %v", v, err)
+	}
+	d.settings[keySnapshotTarget] = snapshotTargetS3
+	if _, err := d.snapshotTarget(nil); err == nil {
+		t.Errorf("expected error without bucket")
+	}
+	d.settings[keyS3Bucket] = "b"
+	if v, err := d.snapshotTarget(nil); err != nil || v != snapshotTargetS3 {
+		t.Errorf("s3: %q, %v", v, err)
+	}
+}
+
+func TestVolumeRecords(t *testing.T) {
+	ctx := context.Background()
+	kc := &kube.Client{Clientset: fake.NewSimpleClientset()}
+	d := &driver{workspaceName: "ws", providerName: "prv", settings: map[string]string{}}
+	cluster := &model.Cluster{Name: "c"}
+	app := &model.App{Name: "app"}
+	other := &model.App{Name: "other"}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, name := range []string{"d1", "d2"} {
+		r := &volumeRecord{Kind: recordKindDisk, Name: name, VolumeName: "db", Node: "n1",
+			Handle: kube.LocalVolumeHandle("n1", d.diskDir(defaultVolumePath, app, "db", name)), CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		if err := d.saveRecord(ctx, kc, cluster, app, r); err != nil {
+			t.Fatalf("saveRecord: %v", err)
+		}
+	}
+	if err := d.saveRecord(ctx, kc, cluster, other, &volumeRecord{Kind: recordKindDisk, Name: "d1", VolumeName: "db"}); err != nil {
+		t.Fatalf("saveRecord: %v", err)
+	}
+
+	records, err := d.listRecords(ctx, kc, cluster, app, recordKindDisk, "db")
+	if err != nil {
+		t.Fatalf("listRecords: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "d2" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+	disk := records[1].disk()
+	if disk.Zone != "n1" || disk.Options[volumeOptionPath] != d.diskDir(defaultVolumePath, app, "db", "d1") {
+		t.Errorf("unexpected disk: %+v", disk)
+	}
+
+	records[1].Assigned = true
+	if err := d.saveRecord(ctx, kc, cluster, app, records[1]); err != nil {
+		t.Fatalf("saveRecord update: %v", err)
+	}
+	r, err := d.findRecord(ctx, kc, cluster, app, recordKindDisk, "db", "d1")
+	if err != nil || r == nil || !r.Assigned {
+		t.Fatalf("findRecord: %+v, %v", r, err)
+	}
+
+	if err := d.deleteRecord(ctx, kc, cluster, app, r); err != nil {
+		t.Fatalf("deleteRecord: %v", err)
+	}
+	if r, _ := d.findRecord(ctx, kc, cluster, app, recordKindDisk, "db", "d1"); r != nil {
+		t.Errorf("record not deleted")
+	}
+	if snaps, _ := d.listRecords(ctx, kc, cluster, app, recordKindSnapshot, "db"); len(snaps) != 0 {
+		t.Errorf("unexpected snapshot records: %+v", snaps)
+	}
+}
@@ -0,0 +1,132 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ClusterProvision verifies that the existing cluster API server is reachable.
+// The kubernetes driver never creates clusters, so the cluster must be marked existing.
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
+	defer func() { cleanup(err) }()
+
+	if !cluster.Existing {
+		return fmt.Errorf("kubernetes driver only supports existing clusters (set existing: true): %w", model.ErrNotSupported)
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if _, err := kc.Clientset.Discovery().ServerVersion(); err != nil {
+		return fmt.Errorf("API server not reachable: %w", err)
+	}
+	return nil
+}
+
+// ClusterDeprovision is not supported: existing clusters are managed outside Kompox.
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) error {
+	return fmt.Errorf("kubernetes cluster deprovision: %w", model.ErrNotSupported)
+}
+
+// ClusterStatus returns the status of the cluster by querying the API server.
+// Unreachable API servers and unexpected errors are returned; a missing ingress Service
+// means the cluster is not installed.
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	status := &model.ClusterStatus{
+		Existing:    cluster.Existing,
+		Provisioned: false,
+		Installed:   false,
+	}
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := kc.Clientset.Discovery().ServerVersion(); err != nil {
+		return nil, fmt.Errorf("API server not reachable: %w", err)
+	}
+	status.Provisioned = true
+
+	ip, host, err := kc.IngressEndpoint(ctx, cluster)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Ingress controller not installed
+	case err != nil:
+		return nil, fmt.Errorf("get ingress endpoint: %w", err)
+	default:
+		status.Installed = true
+		status.IngressGlobalIP = ip
+		status.IngressFQDN = host
+	}
+	return status, nil
+}
+
+// ClusterInstall installs the Kompox Traefik ingress controller without cloud specific values.
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
+		log.Warn(ctx, "static ingress certificates are not supported by the kubernetes driver; ignored", "count", len(cluster.Ingress.Certificates))
+	}
+
+	return kc.InstallIngressTraefikBasic(ctx, cluster, nil)
+}
+
+// ClusterUninstall uninstalls the Kompox Traefik ingress controller.
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUninstall")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: Uninstall Traefik (idempotent)
+	if err := kc.UninstallIngressTraefik(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 2: Delete ingress namespace (idempotent)
+	if err := kc.DeleteNamespace(ctx, kube.IngressNamespace(cluster)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClusterKubeconfig returns the resolved kubeconfig bytes of the cluster.
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	return d.kubeconfig(cluster)
+}
+
+// ClusterDNSApply is a no-op for the kubernetes provider driver.
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestClusterStatus(t *testing.T) {
+	ctx := context.Background()
+	const host = "https://status.inprocess.test"
+	var objs []runtime.Object
+	kube.RegisterInProcessCluster(host, func() (*kube.InProcessOptions, error) {
+		return &kube.InProcessOptions{Objects: objs}, nil
+	})
+	defer kube.UnregisterInProcessCluster(host)
+	kubeconfig, err := kube.InProcessKubeconfig(host, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &driver{}
+	cluster := &model.Cluster{Name: "c1", Existing: true, Settings: map[string]string{keyKubeconfig: string(kubeconfig)}}
+
+	// Reachable cluster without the ingress Service is provisioned but not installed.
+	status, err := d.ClusterStatus(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterStatus() error = %v", err)
+	}
+	if !status.Provisioned || status.Installed {
+		t.Errorf("ClusterStatus() = %+v, want provisioned and not installed", status)
+	}
+
+	// The ingress Service with a load balancer address means installed.
+	objs = []runtime.Object{&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: kube.IngressServiceName(cluster), Namespace: kube.IngressNamespace(cluster)},
+		Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}}},
+	}}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil {
+		t.Fatalf("ClusterStatus() error = %v", err)
+	}
+	if !status.Installed || status.IngressGlobalIP != "192.0.2.1" {
+		t.Errorf("ClusterStatus() = %+v, want installed with ingress IP", status)
+	}
+
+	// An unreachable API server is reported as an error.
+	kube.UnregisterInProcessCluster(host)
+	if _, err := d.ClusterStatus(ctx, cluster); err == nil {
+		t.Errorf("ClusterStatus() expected error for unreachable API server")
+	}
+}
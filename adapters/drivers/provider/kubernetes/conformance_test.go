@@ -0,0 +1,93 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/drivers/provider/conformance"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newConformanceClients returns clients of a fake cluster with a default storage class, a
+// provisioner binding every PVC to a new CSI PV and a snapshot controller making every
+// VolumeSnapshot ready.
+func newConformanceClients() (*kube.Client, dynamic.Interface) {
+	cs := fake.NewSimpleClientset(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{annotationDefaultStorageClass: "true"}},
+		Provisioner: "csi.example.com",
+	})
+	cs.PrependReactor("create", "persistentvolumeclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pvc := action.(k8stesting.CreateAction).GetObject().(*corev1.PersistentVolumeClaim).DeepCopy()
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-" + pvc.Name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:                      pvc.Spec.Resources.Requests,
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       "csi.example.com",
+					VolumeHandle: "vol-" + pvc.Name,
+				}},
+			},
+		}
+		if err := cs.Tracker().Add(pv); err != nil {
+			return true, nil, err
+		}
+		pvc.Spec.VolumeName = pv.Name
+		pvc.Status.Phase = corev1.ClaimBound
+		pvc.Status.Capacity = pvc.Spec.Resources.Requests
+		return true, pvc, cs.Tracker().Create(action.GetResource(), pvc, pvc.Namespace)
+	})
+
+	dc := newDynamicClient()
+	dc.PrependReactor("create", "volumesnapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		vs := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured).DeepCopy()
+		content := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": gvrContent.GroupVersion().String(),
+			"kind":       "VolumeSnapshotContent",
+			"status":     map[string]any{"snapshotHandle": "snap-" + vs.GetName()},
+		}}
+		content.SetName("content-" + vs.GetName())
+		if err := dc.Tracker().Create(gvrContent, content, ""); err != nil {
+			return true, nil, err
+		}
+		vs.Object["status"] = map[string]any{
+			"readyToUse":                     true,
+			"restoreSize":                    "1Gi",
+			"boundVolumeSnapshotContentName": content.GetName(),
+		}
+		return true, vs, dc.Tracker().Create(gvrSnapshot, vs, vs.GetNamespace())
+	})
+	return &kube.Client{Clientset: cs}, dc
+}
+
+func TestConformance(t *testing.T) {
+	kc, dc := newConformanceClients()
+	report := conformance.Run(t, conformance.Fixture{
+		Factory: func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+			return &driver{
+				workspaceName: workspace.Name,
+				providerName:  provider.Name,
+				settings:      provider.Settings,
+				newClients: func(context.Context, *model.Cluster) (*kube.Client, dynamic.Interface, error) {
+					return kc, dc, nil
+				},
+			}, nil
+		},
+		Workspace:  &model.Workspace{Name: "ws"},
+		Provider:   &model.Provider{Name: "prv", Driver: "kubernetes", Settings: map[string]string{}},
+		Cluster:    &model.Cluster{Name: "cls1", Existing: true},
+		App:        &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30}}},
+		VolumeName: "db",
+	})
+	report.Require(t, conformance.CapVolumeDisk, conformance.CapVolumeSnapshot)
+}
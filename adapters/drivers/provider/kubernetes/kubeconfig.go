@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Setting keys. Each key may be set in Provider settings and overridden per Cluster.
+const (
+	keyKubeconfig        = "KUBE_KUBECONFIG"         // embedded kubeconfig (YAML or base64-encoded YAML)
+	keyKubeconfigPath    = "KUBE_KUBECONFIG_PATH"    // kubeconfig file path (default: $KUBECONFIG or ~/.kube/config)
+	keyKubeconfigContext = "KUBE_KUBECONFIG_CONTEXT" // context to use (default: current-context)
+	keyServer            = "KUBE_SERVER"             // API server URL override
+)
+
+// setting returns the cluster setting for key, falling back to the provider setting.
+func (d *driver) setting(cluster *model.Cluster, key string) string {
+	if cluster != nil && cluster.Settings != nil {
+		if v := strings.TrimSpace(cluster.Settings[key]); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(d.settings[key])
+}
+
+// kubeconfig resolves the kubeconfig of the cluster from the embedded setting, the kubeconfig
+// file or the default loading rules, then applies context selection and the API server override.
+func (d *driver) kubeconfig(cluster *model.Cluster) ([]byte, error) {
+	var data []byte
+	if v := d.setting(cluster, keyKubeconfig); v != "" {
+		data = kube.DecodeKubeconfig(v)
+	} else if path := d.setting(cluster, keyKubeconfigPath); path != "" {
+		path, err := kube.ExpandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read kubeconfig %s: %w", path, err)
+		}
+	} else {
+		// Merge $KUBECONFIG or ~/.kube/config like kubectl does
+		cfg, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+		if err != nil {
+			return nil, fmt.Errorf("load default kubeconfig: %w", err)
+		}
+		if len(cfg.Contexts) == 0 {
+			return nil, fmt.Errorf("no kubeconfig found; set %s or %s", keyKubeconfig, keyKubeconfigPath)
+		}
+		data, err = clientcmd.Write(*cfg)
+		if err != nil {
+			return nil, fmt.Errorf("write kubeconfig: %w", err)
+		}
+	}
+	return kube.RewriteKubeconfig(data, d.setting(cluster, keyKubeconfigContext), d.setting(cluster, keyServer))
+}
+
+// kubeClient returns a Kubernetes client for the target cluster.
+func (d *driver) kubeClient(ctx context.Context, cluster *model.Cluster) (*kube.Client, error) {
+	if d.newClients != nil {
+		kc, _, err := d.newClients(ctx, cluster)
+		return kc, err
+	}
+	kubeconfig, err := d.kubeconfig(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get kubeconfig: %w", err)
+	}
+	kc, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("new kube client: %w", err)
+	}
+	return kc, nil
+}
+
+// volumeClients returns the typed client and the dynamic client used for VolumeSnapshot resources.
+func (d *driver) volumeClients(ctx context.Context, cluster *model.Cluster) (*kube.Client, dynamic.Interface, error) {
+	if d.newClients != nil {
+		return d.newClients(ctx, cluster)
+	}
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	dc, err := dynamic.NewForConfig(kc.RESTConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new dynamic client: %w", err)
+	}
+	return kc, dc, nil
+}
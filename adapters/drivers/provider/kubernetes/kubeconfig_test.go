@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+current-context: prod
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(t *testing.T, d *driver, cluster *model.Cluster) (string, string) {
+		t.Helper()
+		got, err := d.kubeconfig(cluster)
+		if err != nil {
+			t.Fatalf("kubeconfig() error = %v", err)
+		}
+		cfg, err := clientcmd.Load(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cfg.Contexts) != 1 || len(cfg.Clusters) != 1 {
+			t.Fatalf("kubeconfig() not minified: contexts=%d clusters=%d", len(cfg.Contexts), len(cfg.Clusters))
+		}
+		return cfg.CurrentContext, cfg.Clusters[cfg.Contexts[cfg.CurrentContext].Cluster].Server
+	}
+
+	t.Run("default loading rules", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", path)
+		if ctx, server := load(t, &driver{settings: map[string]string{}}, nil); ctx != "prod" || server != "https://prod.example.com:6443" {
+			t.Errorf("got %s %s", ctx, server)
+		}
+	})
+
+	t.Run("path with context from cluster settings", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfigPath: path}}
+		cluster := &model.Cluster{Settings: map[string]string{keyKubeconfigContext: "dev"}}
+		if ctx, server := load(t, d, cluster); ctx != "dev" || server != "https://dev.example.com:6443" {
+			t.Errorf("got %s %s", ctx, server)
+		}
+	})
+
+	t.Run("embedded with server override", func(t *testing.T) {
+		d := &driver{settings: map[string]string{keyKubeconfig: testKubeconfig, keyServer: "https://lb.example.com:6443"}}
+		if ctx, server := load(t, d, nil); ctx != "prod" || server != "https://lb.example.com:6443" {
+			t.Errorf("got %s %s", ctx, server)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"k8s.io/client-go/dynamic"
+)
+
+// driver implements the generic Kubernetes provider driver for existing clusters.
+// Cluster operations use the kubeconfig from settings; volumes are dynamically provisioned
+// PVCs and CSI VolumeSnapshots, so any cluster with a CSI driver supporting snapshots works.
+type driver struct {
+	workspaceName string
+	providerName  string
+	settings      map[string]string // provider settings; cluster settings take precedence (see setting)
+	newClients    clientsFunc       // client constructor (nil builds clients from the kubeconfig)
+}
+
+// clientsFunc returns the typed and dynamic clients of a cluster.
+type clientsFunc func(ctx context.Context, cluster *model.Cluster) (*kube.Client, dynamic.Interface, error)
+
+// ID returns the provider identifier.
+func (d *driver) ID() string { return "kubernetes" }
+
+// WorkspaceName returns the workspace name associated with this driver instance.
+func (d *driver) WorkspaceName() string { return d.workspaceName }
+
+// ProviderName returns the provider name associated with this driver instance.
+func (d *driver) ProviderName() string { return d.providerName }
+
+// Capabilities returns the operations supported by the kubernetes driver.
+// Snapshots require a CSI driver with VolumeSnapshot support in the target cluster.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Existing: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk:  {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true},
+			model.VolumeTypeFiles: {AccessModes: []string{"ReadWriteMany"}, Snapshot: true},
+		},
+	}
+}
+
+// Volume resource inventory (not implemented for kubernetes)
+func (d *driver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error {
+	return model.ErrNotSupported
+}
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error {
+	return model.ErrNotSupported
+}
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error {
+	return model.ErrNotSupported
+}
+
+// NodePool operations are not supported: nodes are managed outside Kompox.
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, _ ...model.NodePoolListOption) ([]*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolCreateOption) (*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, _ ...model.NodePoolUpdateOption) (*model.NodePool, error) {
+	return nil, model.ErrNotSupported
+}
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, _ ...model.NodePoolDeleteOption) error {
+	return model.ErrNotSupported
+}
+
+// init registers the generic Kubernetes driver.
+func init() {
+	providerdrv.Register("kubernetes", func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+		// Determine WorkspaceName
+		workspaceName := "(nil)"
+		if workspace != nil {
+			workspaceName = workspace.Name
+		}
+
+		if provider.Settings != nil && provider.Settings["disabled"] == "true" {
+			return nil, fmt.Errorf("kubernetes provider disabled by settings")
+		}
+		return &driver{
+			workspaceName: workspaceName,
+			providerName:  provider.Name,
+			settings:      provider.Settings,
+		}, nil
+	})
+}
@@ -0,0 +1,744 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/kompox/kompox/internal/naming"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Timeouts of volume operations. Restores and snapshots are copied by the storage backend
+// and may take long.
+const (
+	volumeListTimeout   = 1 * time.Minute
+	volumeCreateTimeout = 60 * time.Minute
+	volumePollInterval  = 2 * time.Second
+)
+
+// Kinds of PVC data sources.
+const (
+	sourceKindClaim    = "PersistentVolumeClaim"
+	sourceKindSnapshot = "VolumeSnapshot"
+)
+
+// volumeSource is a resolved disk or snapshot source in the volume namespace.
+type volumeSource struct {
+	Kind   string // sourceKindClaim or sourceKindSnapshot
+	Name   string // PVC or VolumeSnapshot name
+	Handle string
+	Size   int64
+}
+
+// dataSource returns the PVC dataSource referencing the source.
+func (s *volumeSource) dataSource() *corev1.TypedLocalObjectReference {
+	ref := &corev1.TypedLocalObjectReference{Kind: s.Kind, Name: s.Name}
+	if s.Kind == sourceKindSnapshot {
+		ref.APIGroup = &snapshotAPIGroup
+	}
+	return ref
+}
+
+// listClaims returns the disk holder PVCs of an app volume.
+func (d *driver) listClaims(ctx context.Context, kc *kube.Client, ns string, app *model.App, volName string) ([]corev1.PersistentVolumeClaim, error) {
+	list, err := kc.Clientset.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{LabelSelector: d.selector(app, volName, componentDisk)})
+	if err != nil {
+		return nil, fmt.Errorf("list pvcs in %s: %w", ns, err)
+	}
+	return list.Items, nil
+}
+
+// VolumeDiskList lists the disk holder PVCs of a volume.
+func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	if _, err := appVolume(app, volName); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := d.listClaims(ctx, kc, d.volumeNamespace(cluster), app, volName)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.VolumeDisk, 0, len(claims))
+	for i := range claims {
+		out = append(out, diskFromClaim(&claims[i]))
+	}
+	sortDisks(out)
+	return out, nil
+}
+
+// VolumeDiskCreate creates a dynamically provisioned holder PVC, empty or restored from a
+// VolumeSnapshot or cloned from another disk via the PVC dataSource. The bound PV is switched
+// to the Retain reclaim policy so that the disk survives the app PV/PVC lifecycle.
+func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (disk *model.VolumeDisk, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	volOpts, err := parseVolumeOptions(mergeOptions(vol.Options, o.Options))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCreateTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskCreate")
+	defer func() { cleanup(err) }()
+
+	kc, dc, err := d.volumeClients(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ns := d.volumeNamespace(cluster)
+	if err := kc.CreateNamespace(ctx, ns); err != nil {
+		return nil, err
+	}
+
+	diskName = strings.TrimSpace(diskName)
+	if diskName == "" {
+		if diskName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	}
+	claimName := d.diskClaimName(app, volName, diskName)
+	claims := kc.Clientset.CoreV1().PersistentVolumeClaims(ns)
+	if _, err := claims.Get(ctx, claimName, metav1.GetOptions{}); err == nil {
+		return nil, fmt.Errorf("disk %q already exists", diskName)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get pvc %s/%s: %w", ns, claimName, err)
+	}
+
+	sc, err := d.storageClass(ctx, kc, cluster, vol.Type, volOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	size := vol.Size
+	if o.Size > size {
+		size = o.Size
+	}
+	zone := app.Deployment.Zone
+	if o.Zone != "" {
+		zone = o.Zone
+	}
+
+	var src *volumeSource
+	if source = strings.TrimSpace(source); source != "" {
+		if src, err = d.resolveSource(ctx, kc, dc, ns, app, volName, source, sourceKindSnapshot); err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+		if src.Size > size {
+			size = src.Size
+		}
+	}
+
+	annotations := metadataAnnotations(o.Labels, o.Description)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        claimName,
+			Namespace:   ns,
+			Labels:      d.diskLabels(app, volName, diskName, false),
+			Annotations: annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes(vol.Type),
+			StorageClassName: &sc.Name,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: *resource.NewQuantity(size, resource.BinarySI)},
+			},
+		},
+	}
+	if src != nil {
+		pvc.Annotations[annotationSourceHandle] = src.Handle
+		pvc.Spec.DataSource = src.dataSource()
+	}
+	if _, err := claims.Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("create pvc %s/%s: %w", ns, claimName, err)
+	}
+	defer func() {
+		if err != nil {
+			// Use a fresh context so that cleanup also runs after timeouts
+			delCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if derr := deleteClaim(delCtx, kc, ns, claimName); derr != nil {
+				logging.FromContext(ctx).Warn(ctx, "failed to clean up pvc", "pvc", claimName, "err", derr)
+			}
+		}
+	}()
+
+	bound, err := d.waitBound(ctx, kc, cluster, ns, claimName, sc, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the provisioned volume when the holder PVC goes away
+	pvs := kc.Clientset.CoreV1().PersistentVolumes()
+	pv, err := pvs.Get(ctx, bound.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get pv %s: %w", bound.Spec.VolumeName, err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeHandle == "" {
+		return nil, fmt.Errorf("pv %s provisioned by storageclass %s is not a CSI volume", pv.Name, sc.Name)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		if pv, err = pvs.Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+			return nil, fmt.Errorf("set reclaim policy of pv %s: %w", bound.Spec.VolumeName, err)
+		}
+	}
+
+	// Record the handle and zone so that listing needs no PV lookups
+	if bound.Annotations == nil {
+		bound.Annotations = map[string]string{}
+	}
+	bound.Annotations[annotationHandle] = pv.Spec.CSI.VolumeHandle
+	if z := pvZone(pv); z != "" {
+		bound.Annotations[annotationZone] = z
+	}
+	updated, err := claims.Update(ctx, bound, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("update pvc %s/%s: %w", ns, claimName, err)
+	}
+	return diskFromClaim(updated), nil
+}
+
+// waitBound waits until the PVC is bound. For WaitForFirstConsumer storage classes a binder
+// pod (restricted to the zone when given) consumes the PVC so that provisioning starts.
+func (d *driver) waitBound(ctx context.Context, kc *kube.Client, cluster *model.Cluster, ns, claimName string, sc *storagev1.StorageClass, zone string) (*corev1.PersistentVolumeClaim, error) {
+	if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+		image := d.setting(cluster, keyBinderImage)
+		if image == "" {
+			image = defaultBinderImage
+		}
+		pods := kc.Clientset.CoreV1().Pods(ns)
+		pod, err := pods.Create(ctx, binderPod(ns, claimName, image, zone), metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("create binder pod: %w", err)
+		}
+		defer func() {
+			// Use a fresh context so that cleanup also runs after timeouts
+			delCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			_ = pods.Delete(delCtx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: ptr(int64(0))})
+		}()
+	}
+
+	claims := kc.Clientset.CoreV1().PersistentVolumeClaims(ns)
+	ticker := time.NewTicker(volumePollInterval)
+	defer ticker.Stop()
+	for {
+		pvc, err := claims.Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get pvc %s/%s: %w", ns, claimName, err)
+		}
+		if pvc.Status.Phase == corev1.ClaimBound && pvc.Spec.VolumeName != "" {
+			return pvc, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for pvc %s/%s to be bound: %w", ns, claimName, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// binderPod builds a pod that only mounts the PVC to trigger WaitForFirstConsumer provisioning.
+func binderPod(ns, claimName, image, zone string) *corev1.Pod {
+	spec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		Containers:    []corev1.Container{{Name: "binder", Image: image}},
+		Volumes: []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			}},
+		}},
+	}
+	if zone != "" {
+		spec.NodeSelector = map[string]string{labelZone: zone}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kompox-volume-binder-",
+			Namespace:    ns,
+			Labels:       map[string]string{kube.LabelAppK8sManagedBy: "kompox", kube.LabelAppK8sComponent: componentBinder},
+		},
+		Spec: spec,
+	}
+}
+
+// deleteClaim deletes a holder PVC after switching its PV back to the Delete reclaim policy,
+// so that the provisioner deletes the backing volume.
+func deleteClaim(ctx context.Context, kc *kube.Client, ns, claimName string) error {
+	claims := kc.Clientset.CoreV1().PersistentVolumeClaims(ns)
+	pvc, err := claims.Get(ctx, claimName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get pvc %s/%s: %w", ns, claimName, err)
+	}
+	if name := pvc.Spec.VolumeName; name != "" {
+		pvs := kc.Clientset.CoreV1().PersistentVolumes()
+		pv, err := pvs.Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return fmt.Errorf("get pv %s: %w", name, err)
+		case pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete:
+			pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimDelete
+			if _, err := pvs.Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("set reclaim policy of pv %s: %w", name, err)
+			}
+		}
+	}
+	if err := claims.Delete(ctx, claimName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete pvc %s/%s: %w", ns, claimName, err)
+	}
+	return nil
+}
+
+// VolumeDiskDelete deletes the holder PVC; the provisioner then deletes the backing volume.
+// A missing disk is not an error.
+func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskDeleteOption) (err error) {
+	if _, err := appVolume(app, volName); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeDiskDelete")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	ns := d.volumeNamespace(cluster)
+	return deleteClaim(ctx, kc, ns, d.diskClaimName(app, volName, diskName))
+}
+
+// VolumeDiskAssign sets the assignment label of the disk and clears it on the other disks.
+// Only PVCs whose label changes are updated, so repeated calls are no-ops.
+func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskAssignOption) error {
+	if _, err := appVolume(app, volName); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return d.assignDisk(ctx, kc, d.volumeNamespace(cluster), app, volName, diskName)
+}
+
+// assignDisk updates the assignment labels of the disk holder PVCs of an app volume.
+func (d *driver) assignDisk(ctx context.Context, kc *kube.Client, ns string, app *model.App, volName, diskName string) error {
+	claims, err := d.listClaims(ctx, kc, ns, app, volName)
+	if err != nil {
+		return err
+	}
+
+	// Find the target disk
+	var found bool
+	for _, c := range claims {
+		if c.Labels[labelDisk] == diskName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("disk not found: %s", diskName)
+	}
+
+	for i := range claims {
+		c := &claims[i]
+		assigned := strconv.FormatBool(c.Labels[labelDisk] == diskName)
+		if c.Labels[labelDiskAssigned] == assigned {
+			continue
+		}
+		c.Labels[labelDiskAssigned] = assigned
+		if _, err := kc.Clientset.CoreV1().PersistentVolumeClaims(ns).Update(ctx, c, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update pvc %s/%s: %w", ns, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// VolumeDiskUpdate is not supported: PVC parameters other than size are immutable.
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, _ ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, fmt.Errorf("kubernetes disk update: %w", model.ErrNotSupported)
+}
+
+// VolumeSnapshotList lists the VolumeSnapshots of a volume.
+func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, _ ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	if _, err := appVolume(app, volName); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	_, dc, err := d.volumeClients(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ns := d.volumeNamespace(cluster)
+	list, err := dc.Resource(gvrSnapshot).Namespace(ns).List(ctx, metav1.ListOptions{LabelSelector: d.selector(app, volName, componentSnapshot)})
+	if err != nil {
+		return nil, fmt.Errorf("list volumesnapshots in %s: %w", ns, err)
+	}
+	out := make([]*model.VolumeSnapshot, 0, len(list.Items))
+	for i := range list.Items {
+		out = append(out, snapshotFromObject(&list.Items[i]))
+	}
+	sortSnapshots(out)
+	return out, nil
+}
+
+// VolumeSnapshotCreate creates a VolumeSnapshot of a disk holder PVC and waits until it is
+// ready to use. An empty source selects the assigned disk.
+func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (snap *model.VolumeSnapshot, err error) {
+	vol, err := appVolume(app, volName)
+	if err != nil {
+		return nil, err
+	}
+	var o model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	volOpts, err := parseVolumeOptions(vol.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeCreateTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotCreate")
+	defer func() { cleanup(err) }()
+
+	kc, dc, err := d.volumeClients(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ns := d.volumeNamespace(cluster)
+	snapshots := dc.Resource(gvrSnapshot).Namespace(ns)
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		if snapName, err = naming.NewCompactID(); err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	}
+	objName := d.snapshotName(app, volName, snapName)
+	if _, err := snapshots.Get(ctx, objName, metav1.GetOptions{}); err == nil {
+		return nil, fmt.Errorf("snapshot %q already exists", snapName)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get volumesnapshot %s/%s: %w", ns, objName, err)
+	}
+
+	// Determine the source disk
+	var src *volumeSource
+	if source = strings.TrimSpace(source); source == "" {
+		claims, err := d.listClaims(ctx, kc, ns, app, volName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range claims {
+			if claims[i].Labels[labelDiskAssigned] == "true" {
+				src = sourceFromClaim(&claims[i])
+				break
+			}
+		}
+		if src == nil {
+			return nil, fmt.Errorf("no assigned disk found for volume %q", volName)
+		}
+	} else if src, err = d.resolveSource(ctx, kc, dc, ns, app, volName, source, sourceKindClaim); err != nil {
+		return nil, fmt.Errorf("resolve source %q: %w", source, err)
+	}
+	if src.Kind != sourceKindClaim {
+		return nil, fmt.Errorf("snapshot source %q is not a disk", source)
+	}
+
+	snapshotClass := volOpts.SnapshotClass
+	if snapshotClass == "" {
+		snapshotClass = d.setting(cluster, keySnapshotClass)
+	}
+	annotations := metadataAnnotations(o.Labels, o.Description)
+	annotations[annotationSourceHandle] = src.Handle
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": gvrSnapshot.GroupVersion().String(),
+		"kind":       sourceKindSnapshot,
+		"spec": map[string]any{
+			"source": map[string]any{"persistentVolumeClaimName": src.Name},
+		},
+	}}
+	obj.SetName(objName)
+	obj.SetNamespace(ns)
+	obj.SetLabels(d.snapshotLabels(app, volName, snapName))
+	obj.SetAnnotations(annotations)
+	if snapshotClass != "" {
+		if err := unstructured.SetNestedField(obj.Object, snapshotClass, "spec", "volumeSnapshotClassName"); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := snapshots.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("create volumesnapshot %s/%s: %w", ns, objName, err)
+	}
+	defer func() {
+		if err != nil {
+			// Use a fresh context so that cleanup also runs after timeouts
+			delCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			_ = snapshots.Delete(delCtx, objName, metav1.DeleteOptions{})
+		}
+	}()
+
+	ready, err := waitSnapshotReady(ctx, snapshots, objName)
+	if err != nil {
+		return nil, err
+	}
+	contentName, _, _ := unstructured.NestedString(ready.Object, "status", "boundVolumeSnapshotContentName")
+	content, err := dc.Resource(gvrContent).Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get volumesnapshotcontent %s: %w", contentName, err)
+	}
+	handle, _, _ := unstructured.NestedString(content.Object, "status", "snapshotHandle")
+	if handle == "" {
+		return nil, fmt.Errorf("volumesnapshotcontent %s has no snapshot handle", contentName)
+	}
+
+	// Record the handle so that listing needs no VolumeSnapshotContent lookups
+	ann := ready.GetAnnotations()
+	if ann == nil {
+		ann = map[string]string{}
+	}
+	ann[annotationHandle] = handle
+	ready.SetAnnotations(ann)
+	updated, err := snapshots.Update(ctx, ready, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("update volumesnapshot %s/%s: %w", ns, objName, err)
+	}
+	return snapshotFromObject(updated), nil
+}
+
+// waitSnapshotReady waits until status.readyToUse of the VolumeSnapshot is true.
+// Errors reported in status.error are retried by the snapshot controller, so they are only
+// included in the timeout error.
+func waitSnapshotReady(ctx context.Context, snapshots dynamic.ResourceInterface, name string) (*unstructured.Unstructured, error) {
+	ticker := time.NewTicker(volumePollInterval)
+	defer ticker.Stop()
+	var lastErr string
+	for {
+		vs, err := snapshots.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get volumesnapshot %s: %w", name, err)
+		}
+		if ok, _, _ := unstructured.NestedBool(vs.Object, "status", "readyToUse"); ok {
+			return vs, nil
+		}
+		if msg, _, _ := unstructured.NestedString(vs.Object, "status", "error", "message"); msg != "" {
+			lastErr = msg
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != "" {
+				return nil, fmt.Errorf("wait for volumesnapshot %s to be ready: %w (last error: %s)", name, ctx.Err(), lastErr)
+			}
+			return nil, fmt.Errorf("wait for volumesnapshot %s to be ready: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// VolumeSnapshotDelete deletes the VolumeSnapshot. Whether the backing snapshot is deleted
+// follows the deletionPolicy of its VolumeSnapshotClass. A missing snapshot is not an error.
+func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, _ ...model.VolumeSnapshotDeleteOption) (err error) {
+	if _, err := appVolume(app, volName); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "VolumeSnapshotDelete")
+	defer func() { cleanup(err) }()
+
+	_, dc, err := d.volumeClients(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	ns := d.volumeNamespace(cluster)
+	objName := d.snapshotName(app, volName, snapName)
+	if err := dc.Resource(gvrSnapshot).Namespace(ns).Delete(ctx, objName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete volumesnapshot %s/%s: %w", ns, objName, err)
+	}
+	return nil
+}
+
+// VolumeClass returns CSI parameters matching the disks of the volume. When a disk is assigned
+// the parameters are copied from its PV so that the app PV reproduces the provisioned volume;
+// otherwise they are derived from the StorageClass.
+func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	if vol.Type != "" && vol.Type != model.VolumeTypeDisk && vol.Type != model.VolumeTypeFiles {
+		return model.VolumeClass{}, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+	volOpts, err := parseVolumeOptions(vol.Options)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, volumeListTimeout)
+	defer cancel()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+	sc, err := d.storageClass(ctx, kc, cluster, vol.Type, volOpts)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+	modes := accessModes(vol.Type)
+	class := model.VolumeClass{
+		StorageClassName: sc.Name,
+		CSIDriver:        sc.Provisioner,
+		FSType:           sc.Parameters["csi.storage.k8s.io/fstype"],
+		AccessModes:      []string{string(modes[0])},
+		ReclaimPolicy:    "Retain",
+		VolumeMode:       "Filesystem",
+	}
+
+	claims, err := d.listClaims(ctx, kc, d.volumeNamespace(cluster), app, vol.Name)
+	if err != nil {
+		return model.VolumeClass{}, err
+	}
+	for _, c := range claims {
+		if c.Labels[labelDiskAssigned] != "true" || c.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := kc.Clientset.CoreV1().PersistentVolumes().Get(ctx, c.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return model.VolumeClass{}, fmt.Errorf("get pv %s: %w", c.Spec.VolumeName, err)
+		}
+		if c.Spec.StorageClassName != nil && *c.Spec.StorageClassName != "" {
+			class.StorageClassName = *c.Spec.StorageClassName
+		}
+		if csi := pv.Spec.CSI; csi != nil {
+			class.CSIDriver = csi.Driver
+			class.FSType = csi.FSType
+			class.Attributes = maps.Clone(csi.VolumeAttributes)
+		}
+		break
+	}
+	return class, nil
+}
+
+// sourceFromClaim returns the source referencing a disk holder PVC.
+func sourceFromClaim(pvc *corev1.PersistentVolumeClaim) *volumeSource {
+	disk := diskFromClaim(pvc)
+	return &volumeSource{Kind: sourceKindClaim, Name: pvc.Name, Handle: disk.Handle, Size: disk.Size}
+}
+
+// sourceFromSnapshot returns the source referencing a VolumeSnapshot.
+func sourceFromSnapshot(vs *unstructured.Unstructured) *volumeSource {
+	return &volumeSource{Kind: sourceKindSnapshot, Name: vs.GetName(), Handle: vs.GetAnnotations()[annotationHandle], Size: snapshotRestoreSize(vs)}
+}
+
+// resolveSource resolves a source string to a disk PVC or VolumeSnapshot in the volume namespace.
+//   - "disk:<name>" / "snapshot:<name>" -> Kompox managed disk / snapshot of the volume
+//   - Others -> name of defaultKind, then a disk or snapshot handle (e.g., resolved app: sources)
+//
+// PVC dataSources must be in the same namespace, so handles of objects outside the volume
+// namespace cannot be used.
+func (d *driver) resolveSource(ctx context.Context, kc *kube.Client, dc dynamic.Interface, ns string, app *model.App, volName, source, defaultKind string) (*volumeSource, error) {
+	kind, name := defaultKind, source
+	explicit := true
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lower, "disk:"):
+		kind, name = sourceKindClaim, source[5:]
+	case strings.HasPrefix(lower, "snapshot:"):
+		kind, name = sourceKindSnapshot, source[9:]
+	default:
+		explicit = false
+	}
+	if name == "" {
+		return nil, fmt.Errorf("source name cannot be empty")
+	}
+
+	switch kind {
+	case sourceKindClaim:
+		pvc, err := kc.Clientset.CoreV1().PersistentVolumeClaims(ns).Get(ctx, d.diskClaimName(app, volName, name), metav1.GetOptions{})
+		if err == nil {
+			return sourceFromClaim(pvc), nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("get pvc: %w", err)
+		}
+		if explicit {
+			return nil, fmt.Errorf("disk not found: %s", name)
+		}
+	default:
+		vs, err := dc.Resource(gvrSnapshot).Namespace(ns).Get(ctx, d.snapshotName(app, volName, name), metav1.GetOptions{})
+		if err == nil {
+			return sourceFromSnapshot(vs), nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("get volumesnapshot: %w", err)
+		}
+		if explicit {
+			return nil, fmt.Errorf("snapshot not found: %s", name)
+		}
+	}
+
+	// Fall back to handles of any Kompox managed disk or snapshot in the volume namespace
+	managed := metav1.ListOptions{LabelSelector: kube.LabelAppK8sManagedBy + "=kompox"}
+	snaps, err := dc.Resource(gvrSnapshot).Namespace(ns).List(ctx, managed)
+	if err != nil {
+		return nil, fmt.Errorf("list volumesnapshots: %w", err)
+	}
+	for i := range snaps.Items {
+		if snaps.Items[i].GetAnnotations()[annotationHandle] == source {
+			return sourceFromSnapshot(&snaps.Items[i]), nil
+		}
+	}
+	claims, err := kc.Clientset.CoreV1().PersistentVolumeClaims(ns).List(ctx, managed)
+	if err != nil {
+		return nil, fmt.Errorf("list pvcs: %w", err)
+	}
+	for i := range claims.Items {
+		if claims.Items[i].Annotations[annotationHandle] == source {
+			return sourceFromClaim(&claims.Items[i]), nil
+		}
+	}
+	return nil, fmt.Errorf("no disk or snapshot named or with handle %q in namespace %s", source, ns)
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,375 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Volume setting keys. Each key may be set in Provider settings and overridden per Cluster.
+const (
+	keyVolumeNamespace   = "KUBE_VOLUME_NAMESPACE"    // namespace of disk PVCs and VolumeSnapshots
+	keyStorageClass      = "KUBE_STORAGE_CLASS"       // StorageClass of Type=disk volumes (default: cluster default)
+	keyFilesStorageClass = "KUBE_FILES_STORAGE_CLASS" // StorageClass of Type=files volumes (must support RWX)
+	keySnapshotClass     = "KUBE_SNAPSHOT_CLASS"      // VolumeSnapshotClass (default: chosen by the snapshot controller)
+	keyBinderImage       = "KUBE_BINDER_IMAGE"        // image of pods binding WaitForFirstConsumer PVCs
+)
+
+// Volume setting defaults.
+const (
+	defaultVolumeNamespace = "kompox-volumes"
+	defaultBinderImage     = "registry.k8s.io/pause:3.10"
+)
+
+// Volume option keys accepted in app.volumes.options and disk create -O.
+const (
+	volumeOptionStorageClass  = "storageClassName"        // overrides KUBE_STORAGE_CLASS / KUBE_FILES_STORAGE_CLASS
+	volumeOptionSnapshotClass = "volumeSnapshotClassName" // overrides KUBE_SNAPSHOT_CLASS
+	volumeOptionClaim         = "claimName"               // holder PVC of the disk (read-only, reported in disk options)
+	volumeOptionVolume        = "volumeName"              // bound PV of the disk (read-only, reported in disk options)
+)
+
+// Labels of disk PVCs and VolumeSnapshots. Names and assignment state live in labels so that
+// listing is a label selector query and assignment is an idempotent label update.
+const (
+	labelVolume       = kube.K4xDomain + "/volume"
+	labelDisk         = kube.K4xDomain + "/disk"
+	labelDiskAssigned = kube.K4xDomain + "/disk-assigned"
+	labelSnapshot     = kube.K4xDomain + "/snapshot"
+)
+
+// Annotations of disk PVCs and VolumeSnapshots.
+const (
+	annotationLabelPrefix  = "label." + kube.K4xDomain + "/" // user labels
+	annotationDescription  = kube.K4xDomain + "/description"
+	annotationHandle       = kube.K4xDomain + "/handle"
+	annotationZone         = kube.K4xDomain + "/zone"
+	annotationSourceHandle = kube.K4xDomain + "/source-handle"
+)
+
+// Well-known annotations marking the default StorageClass.
+const (
+	annotationDefaultStorageClass     = "storageclass.kubernetes.io/is-default-class"
+	annotationDefaultStorageClassBeta = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// labelZone is the well-known topology label of nodes and CSI volumes.
+const labelZone = "topology.kubernetes.io/zone"
+
+// Resources of the snapshot.storage.k8s.io API served by the external snapshotter.
+var (
+	snapshotAPIGroup = "snapshot.storage.k8s.io"
+	gvrSnapshot      = schema.GroupVersionResource{Group: snapshotAPIGroup, Version: "v1", Resource: "volumesnapshots"}
+	gvrContent       = schema.GroupVersionResource{Group: snapshotAPIGroup, Version: "v1", Resource: "volumesnapshotcontents"}
+)
+
+// volumeOptions holds the parsed volume options.
+type volumeOptions struct {
+	StorageClass  string
+	SnapshotClass string
+}
+
+// parseVolumeOptions validates volume options. Unknown keys are rejected so that typos
+// (and options of other drivers) are reported instead of silently ignored.
+func parseVolumeOptions(options map[string]any) (volumeOptions, error) {
+	var o volumeOptions
+	for k, v := range options {
+		switch k {
+		case volumeOptionStorageClass, volumeOptionSnapshotClass:
+			s, ok := v.(string)
+			if !ok {
+				return o, fmt.Errorf("%w: option %q must be a string", model.ErrVolumeOptionsInvalid, k)
+			}
+			if k == volumeOptionStorageClass {
+				o.StorageClass = strings.TrimSpace(s)
+			} else {
+				o.SnapshotClass = strings.TrimSpace(s)
+			}
+		default:
+			return o, fmt.Errorf("%w: unknown option %q", model.ErrVolumeOptionsInvalid, k)
+		}
+	}
+	return o, nil
+}
+
+// appVolume returns the app volume of volName.
+func appVolume(app *model.App, volName string) (*model.AppVolume, error) {
+	if app == nil {
+		return nil, fmt.Errorf("app nil")
+	}
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume: %w", err)
+	}
+	if vol.Type != "" && vol.Type != model.VolumeTypeDisk && vol.Type != model.VolumeTypeFiles {
+		return nil, fmt.Errorf("unsupported volume type: %s", vol.Type)
+	}
+	return vol, nil
+}
+
+// accessModes returns the PVC access modes of a volume type.
+func accessModes(volType string) []corev1.PersistentVolumeAccessMode {
+	if volType == model.VolumeTypeFiles {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	}
+	return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+}
+
+// volumeNamespace returns the namespace of disk PVCs and VolumeSnapshots.
+func (d *driver) volumeNamespace(cluster *model.Cluster) string {
+	if v := d.setting(cluster, keyVolumeNamespace); v != "" {
+		return v
+	}
+	return defaultVolumeNamespace
+}
+
+// appDirName returns the per-app name prefix, unique per workspace/provider/app.
+func (d *driver) appDirName(app *model.App) string {
+	return naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name).Namespace
+}
+
+// diskClaimName returns the holder PVC name of a disk: <app>-disk-<vol>-<disk>.
+func (d *driver) diskClaimName(app *model.App, volName, diskName string) string {
+	return fmt.Sprintf("%s-disk-%s-%s", d.appDirName(app), volName, diskName)
+}
+
+// snapshotName returns the VolumeSnapshot name of a snapshot: <app>-snap-<vol>-<snap>.
+func (d *driver) snapshotName(app *model.App, volName, snapName string) string {
+	return fmt.Sprintf("%s-snap-%s-%s", d.appDirName(app), volName, snapName)
+}
+
+// volumeLabels returns the labels identifying disks or snapshots of an app volume.
+func (d *driver) volumeLabels(app *model.App, volName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	return map[string]string{
+		kube.LabelAppK8sManagedBy: "kompox",
+		kube.LabelK4xAppIDHash:    h.AppID,
+		labelVolume:               volName,
+	}
+}
+
+// Component labels of disk PVCs and VolumeSnapshots.
+const (
+	componentDisk     = "volume-disk"
+	componentSnapshot = "volume-snapshot"
+	componentBinder   = "volume-binder"
+)
+
+// diskLabels returns the labels of a disk holder PVC.
+func (d *driver) diskLabels(app *model.App, volName, diskName string, assigned bool) map[string]string {
+	set := d.volumeLabels(app, volName)
+	set[kube.LabelAppK8sComponent] = componentDisk
+	set[labelDisk] = diskName
+	set[labelDiskAssigned] = strconv.FormatBool(assigned)
+	return set
+}
+
+// snapshotLabels returns the labels of a VolumeSnapshot.
+func (d *driver) snapshotLabels(app *model.App, volName, snapName string) map[string]string {
+	set := d.volumeLabels(app, volName)
+	set[kube.LabelAppK8sComponent] = componentSnapshot
+	set[labelSnapshot] = snapName
+	return set
+}
+
+// selector returns the label selector of the objects of a component of an app volume.
+func (d *driver) selector(app *model.App, volName, component string) string {
+	set := d.volumeLabels(app, volName)
+	set[kube.LabelAppK8sComponent] = component
+	return labels.SelectorFromSet(set).String()
+}
+
+// metadataAnnotations stores user labels and description as annotations.
+// Annotations are used because user label values need not be valid Kubernetes label values.
+func metadataAnnotations(userLabels map[string]string, description string) map[string]string {
+	ann := map[string]string{}
+	for k, v := range userLabels {
+		ann[annotationLabelPrefix+k] = v
+	}
+	if description != "" {
+		ann[annotationDescription] = description
+	}
+	return ann
+}
+
+// metadataFromAnnotations is the inverse of metadataAnnotations.
+func metadataFromAnnotations(ann map[string]string) (map[string]string, string) {
+	var userLabels map[string]string
+	for k, v := range ann {
+		if key, ok := strings.CutPrefix(k, annotationLabelPrefix); ok {
+			if userLabels == nil {
+				userLabels = map[string]string{}
+			}
+			userLabels[key] = v
+		}
+	}
+	return userLabels, ann[annotationDescription]
+}
+
+// diskFromClaim converts a disk holder PVC to a VolumeDisk.
+func diskFromClaim(pvc *corev1.PersistentVolumeClaim) *model.VolumeDisk {
+	var size int64
+	if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		size = q.Value()
+	} else if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		size = q.Value()
+	}
+	options := map[string]any{volumeOptionClaim: pvc.Name}
+	if sc := pvc.Spec.StorageClassName; sc != nil && *sc != "" {
+		options[volumeOptionStorageClass] = *sc
+	}
+	if pvc.Spec.VolumeName != "" {
+		options[volumeOptionVolume] = pvc.Spec.VolumeName
+	}
+	userLabels, description := metadataFromAnnotations(pvc.Annotations)
+	created := pvc.CreationTimestamp.UTC()
+	return &model.VolumeDisk{
+		Name:         pvc.Labels[labelDisk],
+		VolumeName:   pvc.Labels[labelVolume],
+		Assigned:     pvc.Labels[labelDiskAssigned] == "true",
+		Size:         size,
+		Zone:         pvc.Annotations[annotationZone],
+		Options:      options,
+		Handle:       pvc.Annotations[annotationHandle],
+		Labels:       userLabels,
+		Description:  description,
+		SourceHandle: pvc.Annotations[annotationSourceHandle],
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// snapshotFromObject converts a VolumeSnapshot to a VolumeSnapshot model.
+// Size and creation time are taken from the status once the snapshot is ready.
+func snapshotFromObject(vs *unstructured.Unstructured) *model.VolumeSnapshot {
+	ann := vs.GetAnnotations()
+	userLabels, description := metadataFromAnnotations(ann)
+	created := vs.GetCreationTimestamp().UTC()
+	if s, ok, _ := unstructured.NestedString(vs.Object, "status", "creationTime"); ok {
+		var t metav1.Time
+		if err := t.UnmarshalQueryParameter(s); err == nil {
+			created = t.UTC()
+		}
+	}
+	return &model.VolumeSnapshot{
+		Name:         vs.GetLabels()[labelSnapshot],
+		VolumeName:   vs.GetLabels()[labelVolume],
+		Size:         snapshotRestoreSize(vs),
+		Handle:       ann[annotationHandle],
+		Labels:       userLabels,
+		Description:  description,
+		SourceHandle: ann[annotationSourceHandle],
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// snapshotRestoreSize returns status.restoreSize of a VolumeSnapshot in bytes (0 if unknown).
+func snapshotRestoreSize(vs *unstructured.Unstructured) int64 {
+	s, ok, _ := unstructured.NestedString(vs.Object, "status", "restoreSize")
+	if !ok || s == "" {
+		return 0
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0
+	}
+	return q.Value()
+}
+
+// pvZone returns the zone a PV is pinned to by its node affinity, if any.
+// Besides topology.kubernetes.io/zone, CSI drivers use keys like topology.ebs.csi.aws.com/zone.
+func pvZone(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Operator != corev1.NodeSelectorOpIn || len(expr.Values) != 1 {
+				continue
+			}
+			if expr.Key == labelZone || strings.HasSuffix(expr.Key, "/zone") {
+				return expr.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+// sortDisks sorts disks newest first, then by name.
+func sortDisks(disks []*model.VolumeDisk) {
+	sort.SliceStable(disks, func(i, j int) bool {
+		if !disks[i].CreatedAt.Equal(disks[j].CreatedAt) {
+			return disks[i].CreatedAt.After(disks[j].CreatedAt)
+		}
+		return disks[i].Name < disks[j].Name
+	})
+}
+
+// sortSnapshots sorts snapshots newest first, then by name.
+func sortSnapshots(snaps []*model.VolumeSnapshot) {
+	sort.SliceStable(snaps, func(i, j int) bool {
+		if !snaps[i].CreatedAt.Equal(snaps[j].CreatedAt) {
+			return snaps[i].CreatedAt.After(snaps[j].CreatedAt)
+		}
+		return snaps[i].Name < snaps[j].Name
+	})
+}
+
+// storageClass resolves the StorageClass of a volume: the volume option, then the setting
+// of the volume type, then the cluster default StorageClass.
+func (d *driver) storageClass(ctx context.Context, kc *kube.Client, cluster *model.Cluster, volType string, o volumeOptions) (*storagev1.StorageClass, error) {
+	name := o.StorageClass
+	if name == "" {
+		key := keyStorageClass
+		if volType == model.VolumeTypeFiles {
+			key = keyFilesStorageClass
+		}
+		name = d.setting(cluster, key)
+	}
+	if name != "" {
+		sc, err := kc.Clientset.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get storageclass %s: %w", name, err)
+		}
+		return sc, nil
+	}
+	if volType == model.VolumeTypeFiles {
+		return nil, fmt.Errorf("%s must be set for volumes of type %s (the default StorageClass is rarely RWX)", keyFilesStorageClass, model.VolumeTypeFiles)
+	}
+	list, err := kc.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list storageclasses: %w", err)
+	}
+	for i := range list.Items {
+		sc := &list.Items[i]
+		if sc.Annotations[annotationDefaultStorageClass] == "true" || sc.Annotations[annotationDefaultStorageClassBeta] == "true" {
+			return sc, nil
+		}
+	}
+	return nil, fmt.Errorf("no default storageclass found; set %s or the %s option", keyStorageClass, volumeOptionStorageClass)
+}
+
+// mergeOptions merges volume options with functional options (the latter take precedence).
+func mergeOptions(base, override map[string]any) map[string]any {
+	out := maps.Clone(base)
+	if out == nil {
+		out = map[string]any{}
+	}
+	maps.Copy(out, override)
+	return out
+}
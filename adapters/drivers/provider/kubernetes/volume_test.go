@@ -0,0 +1,229 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testClaim(d *driver, app *model.App, volName, diskName string, assigned bool, handle string) *corev1.PersistentVolumeClaim {
+	ann := metadataAnnotations(map[string]string{"env": "prod"}, "desc")
+	ann[annotationHandle] = handle
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        d.diskClaimName(app, volName, diskName),
+			Namespace:   defaultVolumeNamespace,
+			Labels:      d.diskLabels(app, volName, diskName, assigned),
+			Annotations: ann,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pv-" + diskName},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")},
+		},
+	}
+}
+
+func testSnapshot(d *driver, app *model.App, volName, snapName, handle string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": gvrSnapshot.GroupVersion().String(),
+		"kind":       sourceKindSnapshot,
+		"status":     map[string]any{"readyToUse": true, "restoreSize": "3Gi"},
+	}}
+	obj.SetName(d.snapshotName(app, volName, snapName))
+	obj.SetNamespace(defaultVolumeNamespace)
+	obj.SetLabels(d.snapshotLabels(app, volName, snapName))
+	obj.SetAnnotations(map[string]string{annotationHandle: handle})
+	return obj
+}
+
+func newDynamicClient(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvrSnapshot: "VolumeSnapshotList",
+		gvrContent:  "VolumeSnapshotContentList",
+	}, objs...)
+}
+
+func TestParseVolumeOptions(t *testing.T) {
+	o, err := parseVolumeOptions(map[string]any{volumeOptionStorageClass: " fast ", volumeOptionSnapshotClass: "csi-snap"})
+	if err != nil || o.StorageClass != "fast" || o.SnapshotClass != "csi-snap" {
+		t.Errorf("got %+v, %v", o, err)
+	}
+	if _, err := parseVolumeOptions(map[string]any{"sku": "x"}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+	if _, err := parseVolumeOptions(map[string]any{volumeOptionStorageClass: 1}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+}
+
+func TestStorageClass(t *testing.T) {
+	ctx := context.Background()
+	kc := &kube.Client{Clientset: fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{annotationDefaultStorageClass: "true"}}, Provisioner: "ebs.csi.aws.com"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}, Provisioner: "ebs.csi.aws.com"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "nfs"}, Provisioner: "nfs.csi.k8s.io"},
+	)}
+	d := &driver{settings: map[string]string{}}
+
+	tests := []struct {
+		name     string
+		volType  string
+		settings map[string]string
+		opts     volumeOptions
+		want     string
+		wantErr  bool
+	}{
+		{name: "default", want: "standard"},
+		{name: "setting", settings: map[string]string{keyStorageClass: "fast"}, want: "fast"},
+		{name: "option over setting", settings: map[string]string{keyStorageClass: "fast"}, opts: volumeOptions{StorageClass: "standard"}, want: "standard"},
+		{name: "files requires setting", volType: model.VolumeTypeFiles, wantErr: true},
+		{name: "files", volType: model.VolumeTypeFiles, settings: map[string]string{keyFilesStorageClass: "nfs"}, want: "nfs"},
+		{name: "missing", opts: volumeOptions{StorageClass: "none"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d.settings = tt.settings
+			sc, err := d.storageClass(ctx, kc, nil, tt.volType, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", sc.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sc.Name != tt.want {
+				t.Errorf("got %q, want %q", sc.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignDisk(t *testing.T) {
+	ctx := context.Background()
+	d := &driver{workspaceName: "ws", providerName: "prv", settings: map[string]string{}}
+	app := &model.App{Name: "app"}
+	other := &model.App{Name: "other"}
+	kc := &kube.Client{Clientset: fake.NewSimpleClientset(
+		testClaim(d, app, "db", "d1", true, "h1"),
+		testClaim(d, app, "db", "d2", false, "h2"),
+		testClaim(d, other, "db", "d1", true, "h3"),
+	)}
+
+	if err := d.assignDisk(ctx, kc, defaultVolumeNamespace, app, "db", "d2"); err != nil {
+		t.Fatalf("assignDisk: %v", err)
+	}
+	// Repeating the assignment is a no-op
+	if err := d.assignDisk(ctx, kc, defaultVolumeNamespace, app, "db", "d2"); err != nil {
+		t.Fatalf("assignDisk again: %v", err)
+	}
+	if err := d.assignDisk(ctx, kc, defaultVolumeNamespace, app, "db", "dx"); err == nil {
+		t.Errorf("expected error for unknown disk")
+	}
+
+	claims, err := d.listClaims(ctx, kc, defaultVolumeNamespace, app, "db")
+	if err != nil || len(claims) != 2 {
+		t.Fatalf("listClaims: %d, %v", len(claims), err)
+	}
+	for i := range claims {
+		disk := diskFromClaim(&claims[i])
+		if disk.Assigned != (disk.Name == "d2") {
+			t.Errorf("disk %s assigned=%v", disk.Name, disk.Assigned)
+		}
+		if disk.Size != 2<<30 || disk.Labels["env"] != "prod" || disk.Description != "desc" || disk.Options[volumeOptionVolume] != "pv-"+disk.Name {
+			t.Errorf("unexpected disk: %+v", disk)
+		}
+	}
+
+	// Other apps are untouched
+	claims, _ = d.listClaims(ctx, kc, defaultVolumeNamespace, other, "db")
+	if len(claims) != 1 || claims[0].Labels[labelDiskAssigned] != "true" {
+		t.Errorf("other app disk changed: %+v", claims)
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	ctx := context.Background()
+	d := &driver{workspaceName: "ws", providerName: "prv", settings: map[string]string{}}
+	app := &model.App{Name: "app"}
+	other := &model.App{Name: "other"}
+	kc := &kube.Client{Clientset: fake.NewSimpleClientset(testClaim(d, app, "db", "d1", true, "vol-1"))}
+	dc := newDynamicClient(testSnapshot(d, app, "db", "s1", "snap-1"), testSnapshot(d, other, "db", "s9", "snap-9"))
+
+	tests := []struct {
+		source, defaultKind string
+		wantKind, wantName  string
+		wantErr             bool
+	}{
+		{source: "disk:d1", defaultKind: sourceKindSnapshot, wantKind: sourceKindClaim, wantName: d.diskClaimName(app, "db", "d1")},
+		{source: "s1", defaultKind: sourceKindSnapshot, wantKind: sourceKindSnapshot, wantName: d.snapshotName(app, "db", "s1")},
+		{source: "d1", defaultKind: sourceKindClaim, wantKind: sourceKindClaim, wantName: d.diskClaimName(app, "db", "d1")},
+		{source: "snap-9", defaultKind: sourceKindSnapshot, wantKind: sourceKindSnapshot, wantName: d.snapshotName(other, "db", "s9")},
+		{source: "vol-1", defaultKind: sourceKindSnapshot, wantKind: sourceKindClaim, wantName: d.diskClaimName(app, "db", "d1")},
+		{source: "snapshot:d1", defaultKind: sourceKindSnapshot, wantErr: true},
+		{source: "unknown", defaultKind: sourceKindSnapshot, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			src, err := d.resolveSource(ctx, kc, dc, defaultVolumeNamespace, app, "db", tt.source, tt.defaultKind)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", src)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if src.Kind != tt.wantKind || src.Name != tt.wantName {
+				t.Errorf("got %+v", src)
+			}
+		})
+	}
+
+	src, _ := d.resolveSource(ctx, kc, dc, defaultVolumeNamespace, app, "db", "s1", sourceKindSnapshot)
+	ref := src.dataSource()
+	if ref.APIGroup == nil || *ref.APIGroup != snapshotAPIGroup || src.Size != 3<<30 || src.Handle != "snap-1" {
+		t.Errorf("unexpected snapshot source: %+v %+v", src, ref)
+	}
+}
+
+func TestPVZone(t *testing.T) {
+	pv := &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{
+		Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"n1"}},
+				{Key: "topology.ebs.csi.aws.com/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+			},
+		}}},
+	}}}
+	if got := pvZone(pv); got != "us-east-1a" {
+		t.Errorf("got %q", got)
+	}
+	if got := pvZone(&corev1.PersistentVolume{}); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestBinderPod(t *testing.T) {
+	pod := binderPod("ns", "claim", defaultBinderImage, "z1")
+	if pod.Spec.NodeSelector[labelZone] != "z1" || pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != "claim" || pod.Namespace != "ns" {
+		t.Errorf("unexpected pod: %+v", pod)
+	}
+	if pod := binderPod("ns", "claim", defaultBinderImage, ""); pod.Spec.NodeSelector != nil {
+		t.Errorf("unexpected node selector: %v", pod.Spec.NodeSelector)
+	}
+}
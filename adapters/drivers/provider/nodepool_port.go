@@ -38,6 +38,16 @@ func (a *nodePoolPortAdapter) getDriver(ctx context.Context, cluster *model.Clus
 	return drv, nil
 }
 
+// Capabilities returns the capabilities of the driver managing the cluster.
+func (a *nodePoolPortAdapter) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	caps := drv.Capabilities()
+	return &caps, nil
+}
+
 // NodePoolList returns a list of node pools for the specified cluster.
 func (a *nodePoolPortAdapter) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) ([]*model.NodePool, error) {
 	drv, err := a.getDriver(ctx, cluster)
@@ -0,0 +1,319 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// kubeClient returns a Kubernetes client for the target cluster.
+func (d *driver) kubeClient(ctx context.Context, cluster *model.Cluster) (*kube.Client, error) {
+	kubeconfig, err := d.okeKubeconfig(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get kubeconfig: %w", err)
+	}
+	kc, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("new kube client: %w", err)
+	}
+	return kc, nil
+}
+
+// ClusterProvision provisions an OKE cluster according to the cluster specification.
+// The cluster compartment, network, OKE cluster and the system/user node pools are converged in order.
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterProvisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterProvision")
+	defer func() { cleanup(err) }()
+
+	if cluster.Existing {
+		return fmt.Errorf("OKE driver does not support existing clusters: %w", model.ErrNotSupported)
+	}
+
+	compName, err := d.clusterCompartmentName(cluster)
+	if err != nil {
+		return fmt.Errorf("derive cluster compartment: %w", err)
+	}
+	tags := d.clusterResourceTags(cluster.Name)
+
+	// Step 1: Compartment
+	comp, err := d.ensureCompartmentCreated(ctx, compName, tags)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: Network (VCN, gateway, subnets)
+	net, err := d.ensureNetworkCreated(ctx, comp, tags)
+	if err != nil {
+		return err
+	}
+
+	// Step 3: OKE cluster
+	oke, err := d.ensureOKEClusterCreated(ctx, cluster, comp, net, tags)
+	if err != nil {
+		return err
+	}
+
+	// Step 4: Node pools
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return err
+	}
+	t := &okeTarget{ce: ce, comp: comp, clusterID: *oke.Id, version: *oke.KubernetesVersion, net: net}
+	for _, mode := range []string{"system", "user"} {
+		existing, err := d.findNodePool(ctx, t, mode)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		pool, err := nodePoolFromSettings(cluster, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := d.createNodePool(ctx, cluster, t, pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClusterDeprovision deletes the node pools, the OKE cluster, the network and finally the cluster compartment.
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterDeprovisionOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterDeprovision")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+
+	compName, err := d.clusterCompartmentName(cluster)
+	if err != nil {
+		return fmt.Errorf("derive cluster compartment: %w", err)
+	}
+	comp, err := d.findCompartment(ctx, compName)
+	if err != nil {
+		return err
+	}
+	if comp == nil {
+		log.Info(ctx, "cluster compartment not found, nothing to deprovision", "name", compName)
+		return nil
+	}
+
+	if err := d.ensureOKEClusterDeleted(ctx, cluster, comp); err != nil {
+		return err
+	}
+	if err := d.ensureNetworkDeleted(ctx, comp); err != nil {
+		return err
+	}
+	return d.ensureCompartmentDeleted(ctx, compName)
+}
+
+// ClusterStatus returns the status of an OKE cluster.
+// A missing compartment or OKE cluster means not provisioned; other OCI and Kubernetes errors are returned.
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	status := &model.ClusterStatus{
+		Existing:    cluster.Existing,
+		Provisioned: false,
+		Installed:   false,
+	}
+
+	compName, err := d.clusterCompartmentName(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("derive cluster compartment: %w", err)
+	}
+	comp, err := d.findCompartment(ctx, compName)
+	if err != nil {
+		return nil, err
+	}
+	if comp == nil {
+		return status, nil
+	}
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return nil, err
+	}
+	oke, err := d.findOKECluster(ctx, ce, comp, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if oke == nil || oke.LifecycleState != containerengine.ClusterLifecycleStateActive {
+		return status, nil
+	}
+	status.Provisioned = true
+
+	// Retrieve ingress endpoint (global IP/FQDN) when installed
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ip, host, err := kc.IngressEndpoint(ctx, cluster)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Ingress controller not installed
+	case err != nil:
+		return nil, fmt.Errorf("get ingress endpoint: %w", err)
+	default:
+		status.Installed = true
+		status.IngressGlobalIP = ip
+		status.IngressFQDN = host
+	}
+	return status, nil
+}
+
+// ClusterInstall installs the Kompox Traefik ingress controller. The Service of type LoadBalancer
+// is realized by the OCI cloud controller in the LB subnet configured at cluster creation.
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterInstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterInstall")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	if cluster.Ingress != nil && len(cluster.Ingress.Certificates) > 0 {
+		log.Warn(ctx, "static ingress certificates are not supported by the OKE driver; ignored", "count", len(cluster.Ingress.Certificates))
+	}
+
+	// Step 1: Install Traefik via Helm (idempotent) with the shared basic settings
+	if err := kc.InstallIngressTraefikBasic(ctx, cluster, nil); err != nil {
+		return err
+	}
+
+	// Step 2: Install the Spot eviction helper when configured, otherwise remove it
+	if cluster.SpotHandler != nil {
+		return kc.InstallSpotHandler(ctx, cluster)
+	}
+	return kc.UninstallSpotHandler(ctx, cluster)
+}
+
+// ClusterUninstall uninstalls the Kompox Traefik ingress controller.
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, _ ...model.ClusterUninstallOption) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	ctx, cleanup := d.withMethodLogger(ctx, "ClusterUninstall")
+	defer func() { cleanup(err) }()
+
+	kc, err := d.kubeClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: Uninstall Traefik (best-effort)
+	if err := kc.UninstallIngressTraefik(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 2: Delete the Spot eviction helper including its cluster-scoped RBAC
+	if err := kc.UninstallSpotHandler(ctx, cluster); err != nil {
+		return err
+	}
+
+	// Step 3: Delete ingress namespace (best-effort, idempotent)
+	if err := kc.DeleteNamespace(ctx, kube.IngressNamespace(cluster)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClusterKubeconfig returns the kubeconfig of the OKE cluster.
+// The kubeconfig requires the oci CLI as the exec credential plugin.
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	return d.okeKubeconfig(ctx, cluster)
+}
+
+// ClusterDNSApply is not implemented for OCI DNS yet and always returns an error wrapping
+// model.ErrNotSupported, so callers never mistake a skipped record for an applied one.
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	return fmt.Errorf("OKE DNS apply for %s: OCI DNS is not implemented: %w", rset.FQDN, model.ErrNotSupported)
+}
+
+// nodePoolFromSettings builds the spec of the system or user node pool created by ClusterProvision
+// from the OCI_OKE_{SYSTEM,USER}_* cluster settings.
+func nodePoolFromSettings(cluster *model.Cluster, mode string) (model.NodePool, error) {
+	prefix := "OCI_OKE_" + strings.ToUpper(mode) + "_"
+	get := func(k string) string {
+		if cluster.Settings == nil {
+			return ""
+		}
+		return strings.TrimSpace(cluster.Settings[prefix+k])
+	}
+	getInt := func(k string, def int) (int, error) {
+		v := get(k)
+		if v == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s%s: %q", prefix, k, v)
+		}
+		return n, nil
+	}
+
+	name, m := mode, mode
+	pool := model.NodePool{Name: &name, Mode: &m, Extensions: map[string]any{}}
+
+	shape := defaultNodeShape
+	if v := get("SHAPE"); v != "" {
+		shape = v
+	}
+	pool.InstanceType = &shape
+	ocpus, err := getInt("OCPUS", defaultNodeOcpus)
+	if err != nil {
+		return pool, err
+	}
+	mem, err := getInt("MEMORY_GB", defaultNodeMemoryGB)
+	if err != nil {
+		return pool, err
+	}
+	pool.Extensions[extOcpus] = float64(ocpus)
+	pool.Extensions[extMemoryInGBs] = float64(mem)
+	boot, err := getInt("BOOT_VOLUME_GB", defaultNodeBootVolumeGB)
+	if err != nil {
+		return pool, err
+	}
+	pool.OSDiskSizeGiB = &boot
+	count, err := getInt("COUNT", defaultNodeCount)
+	if err != nil {
+		return pool, err
+	}
+	pool.Autoscaling = &model.NodePoolAutoscaling{Desired: &count}
+	if v := get("ZONES"); v != "" {
+		var zones []string
+		for _, z := range strings.Split(v, ",") {
+			if z = strings.TrimSpace(z); z != "" {
+				zones = append(zones, z)
+			}
+		}
+		pool.Zones = &zones
+	}
+	if mode == "user" {
+		if v, _ := strconv.ParseBool(get("PREEMPTIBLE")); v {
+			spot := "spot"
+			pool.Priority = &spot
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,457 @@
+package oke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeOKE extends fakeOCI with the VCN network and the OKE control plane (clusters, node pools,
+// work requests). The kubeconfig of every OKE cluster points at kubeHost, an in-process cluster.
+// Requests matching a key of fail ("METHOD /resource/{id}/...") return 400 with the given code
+// and work requests of the operation types in failWork end FAILED with the given message.
+type fakeOKE struct {
+	oci      *fakeOCI
+	kubeHost string
+
+	mu           sync.Mutex
+	fail         map[string]string
+	failWork     map[string]string
+	vcns         map[string]map[string]any
+	gateways     map[string]map[string]any
+	subnets      map[string]map[string]any
+	clusters     map[string]map[string]any
+	nodePools    map[string]map[string]any
+	workRequests map[string]map[string]any
+}
+
+func newFakeOKE(t *testing.T) *fakeOKE {
+	return &fakeOKE{
+		oci:          newFakeOCI(),
+		kubeHost:     "https://" + strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-")) + ".oke.inprocess.test",
+		fail:         map[string]string{},
+		failWork:     map[string]string{},
+		vcns:         map[string]map[string]any{},
+		gateways:     map[string]map[string]any{},
+		subnets:      map[string]map[string]any{},
+		clusters:     map[string]map[string]any{},
+		nodePools:    map[string]map[string]any{},
+		workRequests: map[string]map[string]any{},
+	}
+}
+
+// setFail injects an error code for a request key; an empty code removes the injection.
+func (f *fakeOKE) setFail(key, code string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if code == "" {
+		delete(f.fail, key)
+	} else {
+		f.fail[key] = code
+	}
+}
+
+// count returns the number of live resources of a collection.
+func (f *fakeOKE) count(res string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := map[string]map[string]map[string]any{
+		"compartments": f.oci.compartments,
+		"vcns":         f.vcns,
+		"gateways":     f.gateways,
+		"subnets":      f.subnets,
+		"clusters":     f.clusters,
+		"nodePools":    f.nodePools,
+	}[res]
+	n := 0
+	for _, it := range items {
+		if it["lifecycleState"] != "DELETED" {
+			n++
+		}
+	}
+	return n
+}
+
+// fakeOKEResources are the resources served by fakeOKE; the others are served by fakeOCI.
+var fakeOKEResources = map[string]bool{
+	"vcns": true, "internetGateways": true, "subnets": true, "routeTables": true, "securityLists": true,
+	"clusters": true, "clusterOptions": true, "nodePoolOptions": true, "nodePools": true, "workRequests": true,
+}
+
+// requestKey returns the failure injection key of a request path ("/<version>/<resource>/<id>/...").
+func requestKey(method string, parts []string) string {
+	segs := slices.Clone(parts[1:])
+	if len(segs) > 1 {
+		segs[1] = "{id}"
+	}
+	return method + " /" + strings.Join(segs, "/")
+}
+
+func (f *fakeOKE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 {
+		f.oci.ServeHTTP(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	code := f.fail[requestKey(r.Method, parts)]
+	if code == "" && !fakeOKEResources[parts[1]] {
+		f.mu.Unlock()
+		f.oci.ServeHTTP(w, r)
+		return
+	}
+	defer f.mu.Unlock()
+
+	reply := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	replyError := func(status int, code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "message": code})
+	}
+	if code != "" {
+		replyError(http.StatusBadRequest, code)
+		return
+	}
+
+	var body map[string]any
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	q := r.URL.Query()
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, id := parts[1], ""
+	if len(parts) > 2 {
+		id = parts[2]
+	}
+
+	// list replies the items of the compartment matching the given query parameters.
+	list := func(items map[string]map[string]any, params ...string) {
+		out := []map[string]any{}
+		for _, key := range slices.Sorted(func(yield func(string) bool) {
+			for k := range items {
+				if !yield(k) {
+					return
+				}
+			}
+		}) {
+			it := items[key]
+			match := it["compartmentId"] == q.Get("compartmentId")
+			for _, p := range params {
+				if v := q.Get(p); v != "" && it[p] != v {
+					match = false
+				}
+			}
+			if states := q["lifecycleState"]; len(states) > 0 && !slices.Contains(states, it["lifecycleState"].(string)) {
+				match = false
+			}
+			if match {
+				out = append(out, it)
+			}
+		}
+		reply(out)
+	}
+	// create stores body under a new OCID with the given lifecycle state.
+	create := func(items map[string]map[string]any, kind, state string) map[string]any {
+		body["id"] = f.oci.nextID(kind)
+		body["lifecycleState"] = state
+		body["timeCreated"] = now
+		items[body["id"].(string)] = body
+		return body
+	}
+	// workRequest records a work request affecting the resource and sets its id header.
+	workRequest := func(op, entityType string, it map[string]any) {
+		wr := map[string]any{
+			"id":            f.oci.nextID("workrequest"),
+			"operationType": op,
+			"status":        "SUCCEEDED",
+			"compartmentId": it["compartmentId"],
+			"resources":     []map[string]any{{"entityType": entityType, "identifier": it["id"], "actionType": "CREATED"}},
+			"timeAccepted":  now,
+		}
+		if msg := f.failWork[op]; msg != "" {
+			wr["status"] = "FAILED"
+			wr["errors"] = []map[string]any{{"code": "InternalError", "message": msg, "timestamp": now}}
+		}
+		f.workRequests[wr["id"].(string)] = wr
+		w.Header().Set("opc-work-request-id", wr["id"].(string))
+	}
+	// get replies the item and advances its lifecycle state.
+	get := func(items map[string]map[string]any, next map[string]string) {
+		it, ok := items[id]
+		if !ok {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		reply(it)
+		state, _ := it["lifecycleState"].(string)
+		if s, ok := next[state]; ok {
+			it["lifecycleState"] = s
+		}
+	}
+	// remove deletes the item.
+	remove := func(items map[string]map[string]any) {
+		if _, ok := items[id]; !ok {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		delete(items, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	provisioned := map[string]string{"PROVISIONING": "AVAILABLE"}
+
+	switch {
+	// Virtual network
+	case res == "vcns" && r.Method == http.MethodGet && id == "":
+		list(f.vcns, "displayName")
+	case res == "vcns" && r.Method == http.MethodPost:
+		vcn := create(f.vcns, "vcn", "PROVISIONING")
+		vcn["defaultRouteTableId"] = f.oci.nextID("routetable")
+		vcn["defaultSecurityListId"] = f.oci.nextID("securitylist")
+		reply(vcn)
+	case res == "vcns" && r.Method == http.MethodGet:
+		get(f.vcns, provisioned)
+	case res == "vcns" && r.Method == http.MethodDelete:
+		for _, items := range []map[string]map[string]any{f.subnets, f.gateways} {
+			for _, it := range items {
+				if it["vcnId"] == id {
+					replyError(http.StatusConflict, "Conflict")
+					return
+				}
+			}
+		}
+		remove(f.vcns)
+	case res == "internetGateways" && r.Method == http.MethodGet:
+		list(f.gateways, "vcnId", "displayName")
+	case res == "internetGateways" && r.Method == http.MethodPost:
+		reply(create(f.gateways, "internetgateway", "AVAILABLE"))
+	case res == "internetGateways" && r.Method == http.MethodDelete:
+		remove(f.gateways)
+	case res == "subnets" && r.Method == http.MethodGet && id == "":
+		list(f.subnets, "vcnId", "displayName")
+	case res == "subnets" && r.Method == http.MethodPost:
+		reply(create(f.subnets, "subnet", "PROVISIONING"))
+	case res == "subnets" && r.Method == http.MethodGet:
+		get(f.subnets, provisioned)
+	case res == "subnets" && r.Method == http.MethodDelete:
+		remove(f.subnets)
+	case res == "routeTables" || res == "securityLists":
+		reply(map[string]any{"id": id, "lifecycleState": "AVAILABLE"})
+
+	// Container Engine
+	case res == "clusterOptions":
+		reply(map[string]any{"kubernetesVersions": []string{"v1.30.1", "v1.31.1"}})
+	case res == "nodePoolOptions":
+		reply(map[string]any{"sources": []map[string]any{
+			{"sourceType": "IMAGE", "sourceName": "Oracle-Linux-8.10-2025.01.01-0-OKE-1.31.1-100", "imageId": "ocid1.image.oc1..x86"},
+			{"sourceType": "IMAGE", "sourceName": "Oracle-Linux-8.10-aarch64-2025.01.01-0-OKE-1.31.1-100", "imageId": "ocid1.image.oc1..arm"},
+		}})
+	case res == "clusters" && r.Method == http.MethodGet && id == "":
+		list(f.clusters, "name")
+	case res == "clusters" && r.Method == http.MethodPost && id == "":
+		c := create(f.clusters, "cluster", "CREATING")
+		workRequest("CLUSTER_CREATE", "cluster", c)
+		w.WriteHeader(http.StatusAccepted)
+	case res == "clusters" && r.Method == http.MethodPost: // kubeconfig/content
+		c, ok := f.clusters[id]
+		if !ok {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		kubeconfig, err := kube.InProcessKubeconfig(f.kubeHost, c["name"].(string))
+		if err != nil {
+			replyError(http.StatusInternalServerError, "InternalError")
+			return
+		}
+		_, _ = w.Write(kubeconfig)
+	case res == "clusters" && r.Method == http.MethodGet:
+		get(f.clusters, map[string]string{"CREATING": "ACTIVE"})
+	case res == "clusters" && r.Method == http.MethodDelete:
+		c, ok := f.clusters[id]
+		if !ok || c["lifecycleState"] == "DELETED" {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		for _, np := range f.nodePools {
+			if np["clusterId"] == id && np["lifecycleState"] != "DELETED" {
+				replyError(http.StatusBadRequest, "InvalidParameter")
+				return
+			}
+		}
+		c["lifecycleState"] = "DELETED"
+		workRequest("CLUSTER_DELETE", "cluster", c)
+		w.WriteHeader(http.StatusAccepted)
+	case res == "nodePools" && r.Method == http.MethodGet && id == "":
+		list(f.nodePools, "clusterId")
+	case res == "nodePools" && r.Method == http.MethodPost:
+		np := create(f.nodePools, "nodepool", "ACTIVE")
+		workRequest("NODEPOOL_CREATE", "nodepool", np)
+		if f.failWork["NODEPOOL_CREATE"] != "" {
+			np["lifecycleState"] = "FAILED"
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case res == "nodePools" && r.Method == http.MethodGet:
+		get(f.nodePools, nil)
+	case res == "nodePools" && r.Method == http.MethodPut:
+		np, ok := f.nodePools[id]
+		if !ok {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		for k, v := range body {
+			if k == "nodeConfigDetails" {
+				np[k].(map[string]any)["size"] = v.(map[string]any)["size"]
+				continue
+			}
+			np[k] = v
+		}
+		workRequest("NODEPOOL_UPDATE", "nodepool", np)
+		w.WriteHeader(http.StatusAccepted)
+	case res == "nodePools" && r.Method == http.MethodDelete:
+		np, ok := f.nodePools[id]
+		if !ok || np["lifecycleState"] == "DELETED" {
+			replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+			return
+		}
+		np["lifecycleState"] = "DELETED"
+		workRequest("NODEPOOL_DELETE", "nodepool", np)
+		w.WriteHeader(http.StatusAccepted)
+	case res == "workRequests" && len(parts) > 3: // errors
+		reply(f.workRequests[id]["errors"])
+	case res == "workRequests":
+		get(f.workRequests, nil)
+	default:
+		replyError(http.StatusNotFound, "NotAuthorizedOrNotFound")
+	}
+}
+
+// newClusterTestDriver returns a driver backed by a fake OKE whose clusters are the in-process
+// cluster serving objects.
+func newClusterTestDriver(t *testing.T, objects *[]runtime.Object) (*driver, *fakeOKE) {
+	t.Helper()
+	f := newFakeOKE(t)
+	kube.RegisterInProcessCluster(f.kubeHost, func() (*kube.InProcessOptions, error) {
+		return &kube.InProcessOptions{Objects: *objects}, nil
+	})
+	t.Cleanup(func() { kube.UnregisterInProcessCluster(f.kubeHost) })
+	return newTestDriver(t, f), f
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls", Settings: map[string]string{"OCI_OKE_USER_ZONES": "AD-2"}}
+
+	status, err := d.ClusterStatus(ctx, cluster)
+	if err != nil || status.Provisioned {
+		t.Fatalf("ClusterStatus before provision = %+v, %v; want not provisioned", status, err)
+	}
+
+	for i := range 2 {
+		if err := d.ClusterProvision(ctx, cluster); err != nil {
+			t.Fatalf("ClusterProvision #%d: %v", i+1, err)
+		}
+	}
+	for res, want := range map[string]int{"compartments": 1, "vcns": 1, "gateways": 1, "subnets": 3, "clusters": 1, "nodePools": 2} {
+		if got := f.count(res); got != want {
+			t.Errorf("%s after provision = %d, want %d", res, got, want)
+		}
+	}
+	pools, err := d.NodePoolList(ctx, cluster)
+	if err != nil {
+		t.Fatalf("NodePoolList: %v", err)
+	}
+	if len(pools) != 2 || *pools[0].Name != "system" || *pools[1].Name != "user" {
+		t.Fatalf("NodePoolList = %d pools, want system and user", len(pools))
+	}
+	if z := pools[1].Zones; z == nil || len(*z) != 1 || (*z)[0] != "AD-2" {
+		t.Errorf("user pool zones = %v, want [AD-2]", z)
+	}
+
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || !status.Provisioned || status.Installed {
+		t.Fatalf("ClusterStatus after provision = %+v, %v; want provisioned and not installed", status, err)
+	}
+	objects = []runtime.Object{&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: kube.IngressServiceName(cluster), Namespace: kube.IngressNamespace(cluster)},
+		Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.10"}}}},
+	}}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || !status.Installed || status.IngressGlobalIP != "192.0.2.10" {
+		t.Fatalf("ClusterStatus with ingress = %+v, %v; want installed", status, err)
+	}
+
+	for i := range 2 {
+		if err := d.ClusterDeprovision(ctx, cluster); err != nil {
+			t.Fatalf("ClusterDeprovision #%d: %v", i+1, err)
+		}
+	}
+	for _, res := range []string{"compartments", "vcns", "gateways", "subnets", "clusters", "nodePools"} {
+		if got := f.count(res); got != 0 {
+			t.Errorf("%s after deprovision = %d, want 0", res, got)
+		}
+	}
+	status, err = d.ClusterStatus(ctx, cluster)
+	if err != nil || status.Provisioned {
+		t.Errorf("ClusterStatus after deprovision = %+v, %v; want not provisioned", status, err)
+	}
+}
+
+func TestClusterErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls"}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+
+	status := func() error { _, err := d.ClusterStatus(ctx, cluster); return err }
+	tests := []struct {
+		name string
+		key  string
+		call func() error
+	}{
+		{"status list compartments", "GET /compartments", status},
+		{"status get cluster", "GET /clusters/{id}", status},
+		{"status kubeconfig", "POST /clusters/{id}/kubeconfig/content", status},
+		{"provision get VCN", "GET /vcns/{id}", func() error { return d.ClusterProvision(ctx, cluster) }},
+		{"provision list node pools", "GET /nodePools", func() error { return d.ClusterProvision(ctx, cluster) }},
+		{"deprovision delete node pool", "DELETE /nodePools/{id}", func() error { return d.ClusterDeprovision(ctx, cluster) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.setFail(tt.key, "InvalidParameter")
+			defer f.setFail(tt.key, "")
+			if err := tt.call(); err == nil || !strings.Contains(err.Error(), "InvalidParameter") {
+				t.Errorf("got %v, want the %s error", err, tt.key)
+			}
+		})
+	}
+
+	// Kubernetes API errors other than NotFound are returned
+	kube.RegisterInProcessCluster(f.kubeHost, func() (*kube.InProcessOptions, error) {
+		return nil, errors.New("api unavailable")
+	})
+	if err := status(); err == nil || !strings.Contains(err.Error(), "api unavailable") {
+		t.Errorf("ClusterStatus with a failing API server = %v, want error", err)
+	}
+}
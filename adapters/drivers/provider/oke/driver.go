@@ -0,0 +1,153 @@
+package oke
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/common/auth"
+)
+
+// driver implements the OKE (Oracle Container Engine for Kubernetes) provider driver.
+// Cloud resources are converged by ensure*() functions using the OCI Go SDK and
+// non-deterministic values are recorded as freeform tags of the cluster compartment.
+type driver struct {
+	workspaceName   string
+	providerName    string
+	resourcePrefix  string
+	configProvider  common.ConfigurationProvider
+	tenancyOCID     string
+	compartmentOCID string                   // parent compartment of cluster/app compartments
+	region          string                   // OCI region identifier (e.g. ap-osaka-1)
+	endpoint        string                   // service endpoint override (tests); empty uses regional endpoints
+	volumeBackends  map[string]volumeBackend // volume type -> volumeBackend
+}
+
+// ID returns the provider identifier.
+func (d *driver) ID() string { return "oke" }
+
+// WorkspaceName returns the workspace name associated with this driver instance.
+func (d *driver) WorkspaceName() string { return d.workspaceName }
+
+// ProviderName returns the provider name associated with this driver instance.
+func (d *driver) ProviderName() string { return d.providerName }
+
+// Capabilities returns the operations supported by the oke driver.
+// DNS records are not written yet (see ClusterDNSApply).
+func (d *driver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  d.ID(),
+		Cluster: model.ClusterCapabilities{Provision: true, Install: true},
+		Volumes: map[string]model.VolumeTypeCapabilities{
+			model.VolumeTypeDisk: {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true, Update: true},
+		},
+		NodePool: model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true},
+	}
+}
+
+// init registers the OKE driver.
+func init() {
+	providerdrv.Register("oke", func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+		// Determine WorkspaceName
+		workspaceName := "(nil)"
+		if workspace != nil {
+			workspaceName = workspace.Name
+		}
+
+		settings := provider.Settings
+		get := func(k string) string {
+			if settings == nil {
+				return ""
+			}
+			return strings.TrimSpace(settings[k])
+		}
+
+		tenancyOCID := get(keyTenancyOCID)
+		compartmentOCID := get(keyCompartmentOCID)
+		region := strings.ToLower(get(keyRegion))
+		missing := make([]string, 0, 3)
+		if tenancyOCID == "" {
+			missing = append(missing, keyTenancyOCID)
+		}
+		if compartmentOCID == "" {
+			missing = append(missing, keyCompartmentOCID)
+		}
+		if region == "" {
+			missing = append(missing, keyRegion)
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("missing required OKE settings: %s", strings.Join(missing, ", "))
+		}
+
+		authMethod := get("OCI_AUTH_METHOD")
+		if authMethod == "" {
+			return nil, fmt.Errorf("OCI_AUTH_METHOD must be specified")
+		}
+
+		var cp common.ConfigurationProvider
+		var err error
+		switch authMethod {
+		case "instance_principal":
+			cp, err = auth.InstancePrincipalConfigurationProviderForRegion(common.StringToRegion(region))
+		case "user_principal":
+			userOCID := get("OCI_USER_OCID")
+			fingerprint := get("OCI_FINGERPRINT")
+			keyFile := get("OCI_PRIVATE_KEY_FILE")
+			if userOCID == "" || fingerprint == "" || keyFile == "" {
+				return nil, fmt.Errorf("user_principal auth requires OCI_USER_OCID, OCI_FINGERPRINT, OCI_PRIVATE_KEY_FILE")
+			}
+			keyPath, rerr := kube.ExpandHome(keyFile)
+			if rerr != nil {
+				return nil, fmt.Errorf("expand OCI_PRIVATE_KEY_FILE: %w", rerr)
+			}
+			key, rerr := os.ReadFile(keyPath)
+			if rerr != nil {
+				return nil, fmt.Errorf("read OCI_PRIVATE_KEY_FILE: %w", rerr)
+			}
+			var passphrase *string
+			if v := get("OCI_PRIVATE_KEY_PASSPHRASE"); v != "" {
+				passphrase = &v
+			}
+			cp = common.NewRawConfigurationProvider(tenancyOCID, userOCID, region, fingerprint, string(key), passphrase)
+		case "workload_identity":
+			cp, err = auth.OkeWorkloadIdentityConfigurationProvider()
+		default:
+			return nil, fmt.Errorf("unsupported OCI_AUTH_METHOD: %s", authMethod)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create OCI configuration provider: %w", err)
+		}
+
+		prefix := get(keyResourcePrefix)
+		if prefix == "" {
+			h := naming.NewHashes(workspaceName, provider.Name, "", "")
+			// Default OCI resource prefix aligns with the AKS driver: k4x-<spHASH>
+			prefix = fmt.Sprintf("k4x-%s", h.Provider)
+		}
+		if len(prefix) > maxResourcePrefix {
+			prefix = prefix[:maxResourcePrefix]
+		}
+
+		d := &driver{
+			workspaceName:   workspaceName,
+			providerName:    provider.Name,
+			resourcePrefix:  prefix,
+			configProvider:  cp,
+			tenancyOCID:     tenancyOCID,
+			compartmentOCID: compartmentOCID,
+			region:          region,
+		}
+
+		// Initialize volume backends for each type
+		d.volumeBackends = map[string]volumeBackend{
+			model.VolumeTypeDisk: newVolumeBackendDisk(d),
+		}
+
+		return d, nil
+	})
+}
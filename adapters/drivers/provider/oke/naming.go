@@ -0,0 +1,193 @@
+package oke
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// Resource tag names (same keys as the AKS driver)
+const (
+	tagWorkspaceName = "kompox-workspace-name"
+	tagProviderName  = "kompox-provider-name"
+	tagClusterName   = "kompox-cluster-name"
+	tagClusterHash   = "kompox-cluster-hash"
+	tagAppName       = "kompox-app-name"
+	tagAppIDHash     = "kompox-app-id-hash"
+	tagVolumeName    = "kompox-volume"         // volume name
+	tagDiskName      = "kompox-disk-name"      // disk name (CompactID)
+	tagDiskAssigned  = "kompox-disk-assigned"  // true/false
+	tagSnapshotName  = "kompox-snapshot-name"  // snapshot name (CompactID)
+	tagLabelPrefix   = "kompox-label-"         // user label prefix (kompox-label-<key>)
+	tagDescription   = "kompox-description"    // user description
+	tagNodePoolMode  = "kompox-node-pool-mode" // system/user
+)
+
+// State tag names recorded on the cluster compartment (single source of truth).
+const (
+	tagStateClusterOCID    = "kompox/oke-cluster-ocid"
+	tagStateVCNOCID        = "kompox/oke-vcn-ocid"
+	tagStateEndpointSubnet = "kompox/oke-endpoint-subnet-ocid"
+	tagStateNodeSubnet     = "kompox/oke-node-subnet-ocid"
+	tagStateLBSubnet       = "kompox/oke-lb-subnet-ocid"
+)
+
+// Provider setting keys.
+const (
+	keyTenancyOCID     = "OCI_TENANCY_OCID"
+	keyCompartmentOCID = "OCI_COMPARTMENT_OCID"
+	keyRegion          = "OCI_REGION"
+	keyResourcePrefix  = "OCI_RESOURCE_PREFIX"
+)
+
+// Compartment related limits and setting keys.
+const (
+	maxResourcePrefix  = 32
+	maxResourceName    = 100
+	keyCompartmentName = "OCI_COMPARTMENT_NAME"
+)
+
+// Volume related limits
+const (
+	maxVolumeName   = 16
+	maxDiskName     = 24
+	maxSnapshotName = 24
+)
+
+// safeTruncate ensures resulting name does not exceed the compartment name length, preserving hash suffix.
+// Returns an error if the hash is too long to accommodate any base characters.
+func safeTruncate(base, hash string) (string, error) {
+	maxBaseLen := maxResourceName - (len(hash) + 1)
+	if maxBaseLen < 1 {
+		return "", fmt.Errorf("hash too long: %d chars exceeds limit", len(hash))
+	}
+	if len(base) > maxBaseLen {
+		base = base[:maxBaseLen]
+	}
+	return fmt.Sprintf("%s_%s", base, hash), nil
+}
+
+// clusterResourceTags generates freeform tags for cluster-scoped OCI resources.
+func (d *driver) clusterResourceTags(clusterName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), clusterName, "")
+	return map[string]string{
+		tagWorkspaceName: d.WorkspaceName(),
+		tagProviderName:  d.ProviderName(),
+		tagClusterName:   clusterName,
+		tagClusterHash:   h.Cluster,
+		"managed-by":     "kompox",
+	}
+}
+
+func (d *driver) clusterCompartmentName(cluster *model.Cluster) (string, error) {
+	if cluster == nil {
+		return "", fmt.Errorf("cluster nil")
+	}
+	if cluster.Settings != nil {
+		if v := strings.TrimSpace(cluster.Settings[keyCompartmentName]); v != "" {
+			return v, nil
+		}
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), cluster.Name, "")
+	base := fmt.Sprintf("%s_cls_%s", d.resourcePrefix, cluster.Name)
+	result, err := safeTruncate(base, h.Cluster)
+	if err != nil {
+		return "", fmt.Errorf("cluster compartment name: %w", err)
+	}
+	return result, nil
+}
+
+// appResourceTags generates freeform tags for app-scoped OCI resources.
+func (d *driver) appResourceTags(appName string) map[string]string {
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", appName)
+	return map[string]string{
+		tagWorkspaceName: d.WorkspaceName(),
+		tagProviderName:  d.ProviderName(),
+		tagAppName:       appName,
+		tagAppIDHash:     h.AppID,
+		"managed-by":     "kompox",
+	}
+}
+
+func (d *driver) appCompartmentName(app *model.App) (string, error) {
+	if app == nil {
+		return "", fmt.Errorf("app nil")
+	}
+	if app.Settings != nil {
+		if v := strings.TrimSpace(app.Settings[keyCompartmentName]); v != "" {
+			return v, nil
+		}
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	base := fmt.Sprintf("%s_app_%s", d.resourcePrefix, app.Name)
+	result, err := safeTruncate(base, h.AppID)
+	if err != nil {
+		return "", fmt.Errorf("app compartment name: %w", err)
+	}
+	return result, nil
+}
+
+func (d *driver) appDiskName(app *model.App, volName string, diskName string) (string, error) {
+	if len(volName) > maxVolumeName {
+		return "", fmt.Errorf("volume name %q exceeds max length %d", volName, maxVolumeName)
+	}
+	if len(diskName) > maxDiskName {
+		return "", fmt.Errorf("disk name %q exceeds max length %d", diskName, maxDiskName)
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	base := fmt.Sprintf("%s_disk_%s_%s", d.resourcePrefix, volName, diskName)
+	result, err := safeTruncate(base, h.AppID)
+	if err != nil {
+		return "", fmt.Errorf("disk name: %w", err)
+	}
+	return result, nil
+}
+
+func (d *driver) appSnapshotName(app *model.App, volName string, snapshotName string) (string, error) {
+	if len(volName) > maxVolumeName {
+		return "", fmt.Errorf("volume name %q exceeds max length %d", volName, maxVolumeName)
+	}
+	if len(snapshotName) > maxSnapshotName {
+		return "", fmt.Errorf("snapshot name %q exceeds max length %d", snapshotName, maxSnapshotName)
+	}
+	h := naming.NewHashes(d.WorkspaceName(), d.ProviderName(), "", app.Name)
+	base := fmt.Sprintf("%s_snap_%s_%s", d.resourcePrefix, volName, snapshotName)
+	result, err := safeTruncate(base, h.AppID)
+	if err != nil {
+		return "", fmt.Errorf("snapshot name: %w", err)
+	}
+	return result, nil
+}
+
+// normalizeADToKompox converts an OCI availability domain name to the Kompox zone format.
+// OCI uses tenancy specific names such as "Uocm:AP-OSAKA-1-AD-1" while Kompox uses "AD-1".
+// Names without an AD index are returned as-is.
+func normalizeADToKompox(ad string) string {
+	upper := strings.ToUpper(ad)
+	i := strings.LastIndex(upper, "AD-")
+	if i < 0 {
+		return ad
+	}
+	return upper[i:]
+}
+
+// resolveAD resolves a Kompox zone to one of the OCI availability domain names.
+// Accepted forms: "AD-1", "1", "AP-OSAKA-1-AD-1" and the full name "Uocm:AP-OSAKA-1-AD-1".
+func resolveAD(zone string, ads []string) (string, error) {
+	zone = strings.TrimSpace(zone)
+	if zone == "" {
+		return "", fmt.Errorf("zone is empty")
+	}
+	want := normalizeADToKompox(zone)
+	if !strings.HasPrefix(want, "AD-") {
+		want = "AD-" + want
+	}
+	for _, ad := range ads {
+		if strings.EqualFold(ad, zone) || normalizeADToKompox(ad) == want {
+			return ad, nil
+		}
+	}
+	return "", fmt.Errorf("availability domain %q not found (available: %s)", zone, strings.Join(ads, ", "))
+}
@@ -0,0 +1,113 @@
+package oke
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestNormalizeADToKompox(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Uocm:AP-OSAKA-1-AD-1", "AD-1"},
+		{"Uocm:PHX-AD-3", "AD-3"},
+		{"ad-2", "AD-2"},
+		{"AD-1", "AD-1"},
+		{"zone-a", "zone-a"},
+	}
+	for _, tt := range tests {
+		if got := normalizeADToKompox(tt.in); got != tt.want {
+			t.Errorf("normalizeADToKompox(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAD(t *testing.T) {
+	ads := []string{"Uocm:PHX-AD-1", "Uocm:PHX-AD-2", "Uocm:PHX-AD-3"}
+	tests := []struct {
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{zone: "AD-2", want: "Uocm:PHX-AD-2"},
+		{zone: "3", want: "Uocm:PHX-AD-3"},
+		{zone: "phx-ad-1", want: "Uocm:PHX-AD-1"},
+		{zone: "Uocm:PHX-AD-2", want: "Uocm:PHX-AD-2"},
+		{zone: "AD-4", wantErr: true},
+		{zone: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveAD(tt.zone, ads)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveAD(%q) expected error, got %q", tt.zone, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveAD(%q) unexpected error: %v", tt.zone, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveAD(%q) = %q, want %q", tt.zone, got, tt.want)
+		}
+	}
+}
+
+func TestCompartmentNames(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", resourcePrefix: "k4x-abc"}
+
+	cls, err := d.clusterCompartmentName(&model.Cluster{Name: "cls1"})
+	if err != nil {
+		t.Fatalf("clusterCompartmentName: %v", err)
+	}
+	if !strings.HasPrefix(cls, "k4x-abc_cls_cls1_") {
+		t.Errorf("unexpected cluster compartment name %q", cls)
+	}
+
+	app, err := d.appCompartmentName(&model.App{Name: "app1"})
+	if err != nil {
+		t.Fatalf("appCompartmentName: %v", err)
+	}
+	if !strings.HasPrefix(app, "k4x-abc_app_app1_") {
+		t.Errorf("unexpected app compartment name %q", app)
+	}
+
+	override, err := d.clusterCompartmentName(&model.Cluster{Name: "cls1", Settings: map[string]string{keyCompartmentName: " custom "}})
+	if err != nil {
+		t.Fatalf("clusterCompartmentName override: %v", err)
+	}
+	if override != "custom" {
+		t.Errorf("override = %q, want %q", override, "custom")
+	}
+
+	long, err := d.clusterCompartmentName(&model.Cluster{Name: strings.Repeat("x", 200)})
+	if err != nil {
+		t.Fatalf("clusterCompartmentName long: %v", err)
+	}
+	if len(long) > maxResourceName {
+		t.Errorf("compartment name length %d exceeds %d", len(long), maxResourceName)
+	}
+}
+
+func TestAppDiskNameLimits(t *testing.T) {
+	d := &driver{workspaceName: "ws", providerName: "prv", resourcePrefix: "k4x-abc"}
+	app := &model.App{Name: "app1"}
+
+	if _, err := d.appDiskName(app, strings.Repeat("v", maxVolumeName+1), "d"); err == nil {
+		t.Error("expected error for long volume name")
+	}
+	if _, err := d.appSnapshotName(app, "vol", strings.Repeat("s", maxSnapshotName+1)); err == nil {
+		t.Error("expected error for long snapshot name")
+	}
+	name, err := d.appDiskName(app, "vol", "disk1")
+	if err != nil {
+		t.Fatalf("appDiskName: %v", err)
+	}
+	if !strings.HasPrefix(name, "k4x-abc_disk_vol_disk1_") {
+		t.Errorf("unexpected disk name %q", name)
+	}
+}
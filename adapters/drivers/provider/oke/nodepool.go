@@ -0,0 +1,570 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Kompox label keys for node pools.
+const (
+	labelNodePool = "kompox.dev/node-pool"
+	labelNodeZone = "kompox.dev/node-zone"
+)
+
+// Node pool defaults used when neither the pool spec nor the cluster settings specify a value.
+const (
+	defaultNodeShape        = "VM.Standard.E4.Flex"
+	defaultNodeOcpus        = 2
+	defaultNodeMemoryGB     = 16
+	defaultNodeBootVolumeGB = 50
+	defaultNodeCount        = 1
+)
+
+// Extension keys of model.NodePool for flexible shapes.
+const (
+	extOcpus       = "ocpus"
+	extMemoryInGBs = "memoryInGBs"
+)
+
+// okeTarget bundles the OCI resources a node pool operation works on.
+type okeTarget struct {
+	ce        containerengine.ContainerEngineClient
+	comp      *identity.Compartment
+	clusterID string
+	version   string
+	net       networkState
+}
+
+// resolveOKETarget resolves the compartment and the OKE cluster of the Kompox cluster.
+func (d *driver) resolveOKETarget(ctx context.Context, cluster *model.Cluster) (*okeTarget, error) {
+	comp, err := d.clusterCompartment(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return nil, err
+	}
+	oke, err := d.findOKECluster(ctx, ce, comp, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if oke == nil || oke.Id == nil {
+		return nil, fmt.Errorf("OKE cluster not found in compartment %s", *comp.Name)
+	}
+	t := &okeTarget{ce: ce, comp: comp, clusterID: *oke.Id, net: networkStateFromTags(comp.FreeformTags)}
+	if oke.KubernetesVersion != nil {
+		t.version = *oke.KubernetesVersion
+	}
+	return t, nil
+}
+
+// NodePoolList returns a list of node pools for the specified cluster.
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) (pools []*model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolList")
+	defer func() { cleanup(err) }()
+
+	o := model.ApplyNodePoolListOptions(opts...)
+
+	t, err := d.resolveOKETarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	items, err := d.listNodePools(ctx, t.ce, t.comp, t.clusterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, np := range items {
+		if o.Name != "" && (np.Name == nil || *np.Name != o.Name) {
+			continue
+		}
+		pools = append(pools, nodePoolToModel(np))
+	}
+	return pools, nil
+}
+
+// NodePoolCreate creates a new node pool in the cluster.
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolCreateOption) (result *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolCreate")
+	defer func() { cleanup(err) }()
+
+	_ = model.ApplyNodePoolCreateOptions(opts...)
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("validation error: pool.Name is required")
+	}
+	t, err := d.resolveOKETarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	np, err := d.createNodePool(ctx, cluster, t, pool)
+	if err != nil {
+		return nil, err
+	}
+	return nodePoolToModel(*np), nil
+}
+
+// NodePoolUpdate updates mutable fields of an existing node pool.
+// Labels, the desired node count and the boot volume size (expand only) are mutable.
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolUpdateOption) (result *model.NodePool, err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolUpdate")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+	_ = model.ApplyNodePoolUpdateOptions(opts...)
+
+	if pool.Name == nil || *pool.Name == "" {
+		return nil, fmt.Errorf("validation error: pool.Name is required")
+	}
+	t, err := d.resolveOKETarget(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := d.findNodePool(ctx, t, *pool.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("node pool %s not found", *pool.Name)
+	}
+	cur := nodePoolToModel(*existing)
+	if err := validateImmutableFields(pool, cur); err != nil {
+		return nil, err
+	}
+	if pool.Autoscaling != nil && pool.Autoscaling.Enabled {
+		return nil, fmt.Errorf("node pool autoscaling: %w", model.ErrNotSupported)
+	}
+
+	details := containerengine.UpdateNodePoolDetails{}
+	changed := false
+	if pool.Labels != nil {
+		details.InitialNodeLabels = nodeLabels(*cur.Name, cur.Zones, *pool.Labels)
+		changed = true
+	}
+	if pool.Autoscaling != nil && pool.Autoscaling.Desired != nil {
+		details.NodeConfigDetails = &containerengine.UpdateNodePoolNodeConfigDetails{Size: common.Int(*pool.Autoscaling.Desired)}
+		changed = true
+	}
+	if pool.OSDiskSizeGiB != nil && (cur.OSDiskSizeGiB == nil || *pool.OSDiskSizeGiB != *cur.OSDiskSizeGiB) {
+		if cur.OSDiskSizeGiB != nil && *pool.OSDiskSizeGiB < *cur.OSDiskSizeGiB {
+			return nil, fmt.Errorf("validation error: OSDiskSizeGiB cannot be shrunk (%d -> %d)", *cur.OSDiskSizeGiB, *pool.OSDiskSizeGiB)
+		}
+		src, ok := existing.NodeSourceDetails.(containerengine.NodeSourceViaImageDetails)
+		if !ok {
+			return nil, fmt.Errorf("node pool %s does not use an image source", *pool.Name)
+		}
+		src.BootVolumeSizeInGBs = common.Int64(int64(*pool.OSDiskSizeGiB))
+		details.NodeSourceDetails = src
+		changed = true
+	}
+	if !changed {
+		return cur, nil
+	}
+
+	log.Info(ctx, "updating node pool", "poolName", *pool.Name, "nodePool", *existing.Id)
+	res, err := t.ce.UpdateNodePool(ctx, containerengine.UpdateNodePoolRequest{NodePoolId: existing.Id, UpdateNodePoolDetails: details})
+	if err != nil {
+		return nil, fmt.Errorf("update node pool: %w", err)
+	}
+	if _, err := waitWorkRequest(ctx, t.ce, res.OpcWorkRequestId); err != nil {
+		return nil, fmt.Errorf("wait for node pool update: %w", err)
+	}
+	got, err := t.ce.GetNodePool(ctx, containerengine.GetNodePoolRequest{NodePoolId: existing.Id})
+	if err != nil {
+		return nil, fmt.Errorf("get node pool: %w", err)
+	}
+	return nodePoolToModel(nodePoolSummary(got.NodePool)), nil
+}
+
+// NodePoolDelete deletes the specified node pool from the cluster.
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, "NodePoolDelete")
+	defer func() { cleanup(err) }()
+
+	log := logging.FromContext(ctx)
+	_ = model.ApplyNodePoolDeleteOptions(opts...)
+
+	t, err := d.resolveOKETarget(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	existing, err := d.findNodePool(ctx, t, poolName)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// NotFound is acceptable for idempotency
+		log.Info(ctx, "node pool not found, considering delete successful", "poolName", poolName)
+		return nil
+	}
+	return d.deleteNodePool(ctx, t.ce, existing.Id)
+}
+
+// listNodePools lists the non-deleted node pools of the OKE cluster.
+func (d *driver) listNodePools(ctx context.Context, ce containerengine.ContainerEngineClient, comp *identity.Compartment, clusterID string) ([]containerengine.NodePoolSummary, error) {
+	req := containerengine.ListNodePoolsRequest{CompartmentId: comp.Id, ClusterId: common.String(clusterID)}
+	var pools []containerengine.NodePoolSummary
+	for {
+		res, err := ce.ListNodePools(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("list node pools: %w", err)
+		}
+		for _, np := range res.Items {
+			if np.LifecycleState == containerengine.NodePoolLifecycleStateDeleted || np.LifecycleState == containerengine.NodePoolLifecycleStateDeleting {
+				continue
+			}
+			pools = append(pools, np)
+		}
+		if res.OpcNextPage == nil {
+			return pools, nil
+		}
+		req.Page = res.OpcNextPage
+	}
+}
+
+// findNodePool returns the node pool with the given name or nil when not found.
+func (d *driver) findNodePool(ctx context.Context, t *okeTarget, name string) (*containerengine.NodePoolSummary, error) {
+	pools, err := d.listNodePools(ctx, t.ce, t.comp, t.clusterID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if pools[i].Name != nil && *pools[i].Name == name {
+			return &pools[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// createNodePool creates a node pool and waits for the work request to complete.
+func (d *driver) createNodePool(ctx context.Context, cluster *model.Cluster, t *okeTarget, pool model.NodePool) (*containerengine.NodePoolSummary, error) {
+	log := logging.FromContext(ctx)
+
+	if pool.OSDiskType != nil && *pool.OSDiskType != "" {
+		return nil, fmt.Errorf("validation error: OSDiskType is not supported by OKE")
+	}
+	if pool.Autoscaling != nil && pool.Autoscaling.Enabled {
+		return nil, fmt.Errorf("node pool autoscaling: %w", model.ErrNotSupported)
+	}
+	if t.net.NodeSubnetID == "" {
+		return nil, fmt.Errorf("node subnet not recorded on compartment %s", *t.comp.Name)
+	}
+	name := *pool.Name
+
+	shape := defaultNodeShape
+	if pool.InstanceType != nil && *pool.InstanceType != "" {
+		shape = *pool.InstanceType
+	}
+	mode := "user"
+	if pool.Mode != nil && *pool.Mode != "" {
+		mode = strings.ToLower(*pool.Mode)
+	}
+	bootGB := defaultNodeBootVolumeGB
+	if pool.OSDiskSizeGiB != nil && *pool.OSDiskSizeGiB > 0 {
+		bootGB = *pool.OSDiskSizeGiB
+	}
+	size := defaultNodeCount
+	if pool.Autoscaling != nil && pool.Autoscaling.Desired != nil {
+		size = *pool.Autoscaling.Desired
+	}
+	spot := pool.Priority != nil && strings.EqualFold(*pool.Priority, "spot")
+
+	// Placement: one placement config per availability domain
+	ads, err := d.availabilityDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Zones != nil && len(*pool.Zones) > 0 {
+		var selected []string
+		for _, z := range *pool.Zones {
+			ad, err := resolveAD(z, ads)
+			if err != nil {
+				return nil, fmt.Errorf("validation error: %w", err)
+			}
+			selected = append(selected, ad)
+		}
+		ads = selected
+	}
+	placements := make([]containerengine.NodePoolPlacementConfigDetails, 0, len(ads))
+	zones := make([]string, 0, len(ads))
+	for _, ad := range ads {
+		pc := containerengine.NodePoolPlacementConfigDetails{
+			AvailabilityDomain: common.String(ad),
+			SubnetId:           common.String(t.net.NodeSubnetID),
+		}
+		if spot {
+			pc.PreemptibleNodeConfig = &containerengine.PreemptibleNodeConfigDetails{
+				PreemptionAction: containerengine.TerminatePreemptionAction{IsPreserveBootVolume: common.Bool(false)},
+			}
+		}
+		placements = append(placements, pc)
+		zones = append(zones, normalizeADToKompox(ad))
+	}
+
+	var labels map[string]string
+	if pool.Labels != nil {
+		labels = *pool.Labels
+	}
+	var zonesPtr *[]string
+	if pool.Zones != nil && len(*pool.Zones) > 0 {
+		zonesPtr = &zones
+	}
+
+	imageID, err := d.nodeImageID(ctx, t.ce, cluster, t.clusterID, t.version, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	details := containerengine.CreateNodePoolDetails{
+		CompartmentId:     t.comp.Id,
+		ClusterId:         common.String(t.clusterID),
+		Name:              common.String(name),
+		NodeShape:         common.String(shape),
+		KubernetesVersion: common.String(t.version),
+		NodeSourceDetails: containerengine.NodeSourceViaImageDetails{
+			ImageId:             common.String(imageID),
+			BootVolumeSizeInGBs: common.Int64(int64(bootGB)),
+		},
+		InitialNodeLabels: nodeLabels(name, zonesPtr, labels),
+		NodeConfigDetails: &containerengine.CreateNodePoolNodeConfigDetails{
+			Size:             common.Int(size),
+			PlacementConfigs: placements,
+		},
+		FreeformTags: d.nodePoolTags(cluster.Name, mode),
+	}
+	if strings.HasSuffix(shape, ".Flex") {
+		ocpus := float32(extFloat(pool.Extensions, extOcpus, defaultNodeOcpus))
+		mem := float32(extFloat(pool.Extensions, extMemoryInGBs, defaultNodeMemoryGB))
+		details.NodeShapeConfig = &containerengine.CreateNodeShapeConfigDetails{Ocpus: &ocpus, MemoryInGBs: &mem}
+	}
+
+	log.Info(ctx, "creating node pool", "poolName", name, "shape", shape, "size", size, "zones", zones)
+	res, err := t.ce.CreateNodePool(ctx, containerengine.CreateNodePoolRequest{CreateNodePoolDetails: details})
+	if err != nil {
+		return nil, fmt.Errorf("create node pool %s: %w", name, err)
+	}
+	wr, err := waitWorkRequest(ctx, t.ce, res.OpcWorkRequestId)
+	if err != nil {
+		return nil, fmt.Errorf("wait for node pool creation: %w", err)
+	}
+	id := workRequestResourceID(wr, "nodepool")
+	if id == "" {
+		np, err := d.findNodePool(ctx, t, name)
+		if err != nil {
+			return nil, err
+		}
+		if np == nil {
+			return nil, fmt.Errorf("node pool %s not found after creation", name)
+		}
+		return np, nil
+	}
+	got, err := t.ce.GetNodePool(ctx, containerengine.GetNodePoolRequest{NodePoolId: common.String(id)})
+	if err != nil {
+		return nil, fmt.Errorf("get node pool: %w", err)
+	}
+	np := nodePoolSummary(got.NodePool)
+	return &np, nil
+}
+
+// deleteNodePool deletes a node pool and waits for the work request. NotFound is not an error.
+func (d *driver) deleteNodePool(ctx context.Context, ce containerengine.ContainerEngineClient, id *string) error {
+	log := logging.FromContext(ctx)
+	log.Info(ctx, "deleting node pool", "nodePool", *id)
+	res, err := ce.DeleteNodePool(ctx, containerengine.DeleteNodePoolRequest{NodePoolId: id})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("delete node pool: %w", err)
+	}
+	if _, err := waitWorkRequest(ctx, ce, res.OpcWorkRequestId); err != nil {
+		return fmt.Errorf("wait for node pool deletion: %w", err)
+	}
+	return nil
+}
+
+// nodePoolTags returns the freeform tags of a node pool.
+func (d *driver) nodePoolTags(clusterName, mode string) map[string]string {
+	tags := d.clusterResourceTags(clusterName)
+	tags[tagNodePoolMode] = mode
+	return tags
+}
+
+// nodeLabels builds the initial node labels: user labels plus the Kompox pool and zone labels.
+func nodeLabels(name string, zones *[]string, labels map[string]string) []containerengine.KeyValue {
+	m := maps.Clone(labels)
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[labelNodePool] = name
+	if zones != nil && len(*zones) > 0 {
+		// Set node-zone label to the first zone (primary zone for multi-zone pools)
+		m[labelNodeZone] = (*zones)[0]
+	}
+	keys := slices.Sorted(maps.Keys(m))
+	kvs := make([]containerengine.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, containerengine.KeyValue{Key: common.String(k), Value: common.String(m[k])})
+	}
+	return kvs
+}
+
+// extFloat reads a numeric extension value, accepting numbers and numeric strings.
+func extFloat(ext map[string]any, key string, def float64) float64 {
+	switch v := ext[key].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+// nodePoolSummary converts a NodePool to the summary form returned by ListNodePools.
+func nodePoolSummary(np containerengine.NodePool) containerengine.NodePoolSummary {
+	return containerengine.NodePoolSummary{
+		Id:                np.Id,
+		CompartmentId:     np.CompartmentId,
+		ClusterId:         np.ClusterId,
+		Name:              np.Name,
+		KubernetesVersion: np.KubernetesVersion,
+		NodeShape:         np.NodeShape,
+		NodeShapeConfig:   np.NodeShapeConfig,
+		NodeSourceDetails: np.NodeSourceDetails,
+		InitialNodeLabels: np.InitialNodeLabels,
+		NodeConfigDetails: np.NodeConfigDetails,
+		FreeformTags:      np.FreeformTags,
+		LifecycleState:    np.LifecycleState,
+	}
+}
+
+// nodePoolToModel converts an OKE node pool to Kompox NodePool.
+func nodePoolToModel(np containerengine.NodePoolSummary) *model.NodePool {
+	pool := &model.NodePool{
+		Extensions: make(map[string]any),
+	}
+	if np.Name != nil {
+		pool.Name = common.String(*np.Name)
+	}
+	if np.Id != nil {
+		pool.ProviderName = common.String(*np.Id)
+	}
+
+	// Mode: recorded as a freeform tag; pools created outside Kompox fall back to the name
+	mode := np.FreeformTags[tagNodePoolMode]
+	if mode == "" {
+		mode = "user"
+		if pool.Name != nil && *pool.Name == "system" {
+			mode = "system"
+		}
+	}
+	pool.Mode = &mode
+
+	if np.NodeShape != nil {
+		pool.InstanceType = common.String(*np.NodeShape)
+	}
+	if sc := np.NodeShapeConfig; sc != nil {
+		if sc.Ocpus != nil {
+			pool.Extensions[extOcpus] = float64(*sc.Ocpus)
+		}
+		if sc.MemoryInGBs != nil {
+			pool.Extensions[extMemoryInGBs] = float64(*sc.MemoryInGBs)
+		}
+	}
+	if src, ok := np.NodeSourceDetails.(containerengine.NodeSourceViaImageDetails); ok && src.BootVolumeSizeInGBs != nil {
+		size := int(*src.BootVolumeSizeInGBs)
+		pool.OSDiskSizeGiB = &size
+	}
+
+	priority := "regular"
+	var zones []string
+	autoscaling := &model.NodePoolAutoscaling{}
+	if nc := np.NodeConfigDetails; nc != nil {
+		for _, pc := range nc.PlacementConfigs {
+			if pc.PreemptibleNodeConfig != nil {
+				priority = "spot"
+			}
+			if pc.AvailabilityDomain != nil {
+				zones = append(zones, normalizeADToKompox(*pc.AvailabilityDomain))
+			}
+		}
+		if nc.Size != nil {
+			size := *nc.Size
+			autoscaling.Desired = &size
+		}
+	}
+	pool.Priority = &priority
+	if len(zones) > 0 {
+		pool.Zones = &zones
+	}
+	pool.Autoscaling = autoscaling
+
+	if len(np.InitialNodeLabels) > 0 {
+		labels := make(map[string]string, len(np.InitialNodeLabels))
+		for _, kv := range np.InitialNodeLabels {
+			if kv.Key != nil && kv.Value != nil {
+				labels[*kv.Key] = *kv.Value
+			}
+		}
+		pool.Labels = &labels
+	}
+
+	state := string(np.LifecycleState)
+	pool.Status = &model.NodePoolStatus{
+		ProvisioningState: &state,
+		CurrentNodeCount:  autoscaling.Desired,
+		Extensions:        make(map[string]any),
+	}
+	return pool
+}
+
+// validateImmutableFields checks if any immutable fields are being changed.
+func validateImmutableFields(update model.NodePool, existing *model.NodePool) error {
+	var errs []string
+	if update.Mode != nil && existing.Mode != nil && !strings.EqualFold(*update.Mode, *existing.Mode) {
+		errs = append(errs, "Mode is immutable")
+	}
+	if update.InstanceType != nil && existing.InstanceType != nil && *update.InstanceType != *existing.InstanceType {
+		errs = append(errs, "InstanceType is immutable")
+	}
+	if update.OSDiskType != nil && *update.OSDiskType != "" {
+		errs = append(errs, "OSDiskType is not supported")
+	}
+	if update.Priority != nil && existing.Priority != nil && !strings.EqualFold(*update.Priority, *existing.Priority) {
+		errs = append(errs, "Priority is immutable")
+	}
+	if update.Zones != nil && existing.Zones != nil {
+		want := make([]string, len(*update.Zones))
+		for i, z := range *update.Zones {
+			want[i] = normalizeADToKompox(z)
+			if !strings.HasPrefix(want[i], "AD-") {
+				want[i] = "AD-" + want[i]
+			}
+		}
+		slices.Sort(want)
+		have := slices.Sorted(slices.Values(*existing.Zones))
+		if !slices.Equal(want, have) {
+			errs = append(errs, "Zones are immutable")
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("validation error: cannot modify immutable fields: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package oke
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNodePoolToModel(t *testing.T) {
+	np := containerengine.NodePoolSummary{
+		Id:        common.String("ocid1.nodepool.oc1..aaa"),
+		Name:      common.String("user"),
+		NodeShape: common.String("VM.Standard.E4.Flex"),
+		NodeShapeConfig: &containerengine.NodeShapeConfig{
+			Ocpus:       common.Float32(2),
+			MemoryInGBs: common.Float32(16),
+		},
+		NodeSourceDetails: containerengine.NodeSourceViaImageDetails{
+			ImageId:             common.String("ocid1.image.oc1..img"),
+			BootVolumeSizeInGBs: common.Int64(100),
+		},
+		InitialNodeLabels: []containerengine.KeyValue{
+			{Key: common.String(labelNodePool), Value: common.String("user")},
+			{Key: common.String("team"), Value: common.String("a")},
+		},
+		NodeConfigDetails: &containerengine.NodePoolNodeConfigDetails{
+			Size: common.Int(3),
+			PlacementConfigs: []containerengine.NodePoolPlacementConfigDetails{
+				{
+					AvailabilityDomain:    common.String("Uocm:PHX-AD-1"),
+					PreemptibleNodeConfig: &containerengine.PreemptibleNodeConfigDetails{PreemptionAction: containerengine.TerminatePreemptionAction{}},
+				},
+				{AvailabilityDomain: common.String("Uocm:PHX-AD-2")},
+			},
+		},
+		LifecycleState: containerengine.NodePoolLifecycleStateActive,
+	}
+
+	got := nodePoolToModel(np)
+	if *got.Name != "user" || *got.ProviderName != "ocid1.nodepool.oc1..aaa" {
+		t.Errorf("unexpected name/providerName: %q/%q", *got.Name, *got.ProviderName)
+	}
+	if *got.Mode != "user" {
+		t.Errorf("Mode = %q, want user", *got.Mode)
+	}
+	if *got.InstanceType != "VM.Standard.E4.Flex" {
+		t.Errorf("InstanceType = %q", *got.InstanceType)
+	}
+	if *got.OSDiskSizeGiB != 100 {
+		t.Errorf("OSDiskSizeGiB = %d, want 100", *got.OSDiskSizeGiB)
+	}
+	if *got.Priority != "spot" {
+		t.Errorf("Priority = %q, want spot", *got.Priority)
+	}
+	if !reflect.DeepEqual(*got.Zones, []string{"AD-1", "AD-2"}) {
+		t.Errorf("Zones = %v", *got.Zones)
+	}
+	if got.Autoscaling == nil || got.Autoscaling.Enabled || *got.Autoscaling.Desired != 3 {
+		t.Errorf("unexpected autoscaling: %+v", got.Autoscaling)
+	}
+	if (*got.Labels)["team"] != "a" {
+		t.Errorf("Labels = %v", *got.Labels)
+	}
+	if got.Extensions[extOcpus] != float64(2) || got.Extensions[extMemoryInGBs] != float64(16) {
+		t.Errorf("Extensions = %v", got.Extensions)
+	}
+	if *got.Status.ProvisioningState != "ACTIVE" {
+		t.Errorf("ProvisioningState = %q", *got.Status.ProvisioningState)
+	}
+}
+
+func TestNodePoolToModelMode(t *testing.T) {
+	system := nodePoolToModel(containerengine.NodePoolSummary{Name: common.String("system")})
+	if *system.Mode != "system" {
+		t.Errorf("Mode = %q, want system", *system.Mode)
+	}
+	tagged := nodePoolToModel(containerengine.NodePoolSummary{
+		Name:         common.String("infra"),
+		FreeformTags: map[string]string{tagNodePoolMode: "system"},
+	})
+	if *tagged.Mode != "system" {
+		t.Errorf("Mode = %q, want system", *tagged.Mode)
+	}
+}
+
+func TestValidateImmutableFields(t *testing.T) {
+	existing := &model.NodePool{
+		Mode:         common.String("user"),
+		InstanceType: common.String("VM.Standard.E4.Flex"),
+		Priority:     common.String("regular"),
+		Zones:        &[]string{"AD-1", "AD-2"},
+	}
+
+	if err := validateImmutableFields(model.NodePool{Zones: &[]string{"2", "ad-1"}, Labels: &map[string]string{"a": "b"}}, existing); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := validateImmutableFields(model.NodePool{
+		InstanceType: common.String("VM.Standard.A1.Flex"),
+		Priority:     common.String("spot"),
+		Zones:        &[]string{"AD-3"},
+	}, existing)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, s := range []string{"InstanceType", "Priority", "Zones"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("error %q does not mention %s", err, s)
+		}
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	kvs := nodeLabels("user", &[]string{"AD-2", "AD-3"}, map[string]string{"team": "a"})
+	got := map[string]string{}
+	for _, kv := range kvs {
+		got[*kv.Key] = *kv.Value
+	}
+	want := map[string]string{"team": "a", labelNodePool: "user", labelNodeZone: "AD-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeLabels = %v, want %v", got, want)
+	}
+}
+
+func TestSelectNodeImage(t *testing.T) {
+	src := func(name, id string) containerengine.NodeSourceOption {
+		return containerengine.NodeSourceViaImageOption{SourceName: common.String(name), ImageId: common.String(id)}
+	}
+	sources := []containerengine.NodeSourceOption{
+		src("Oracle-Linux-8.10-2025.01.31-0-OKE-1.31.1-760", "old"),
+		src("Oracle-Linux-8.10-2025.03.19-0-OKE-1.31.1-790", "x86"),
+		src("Oracle-Linux-8.10-Gen2-GPU-2025.03.19-0-OKE-1.31.1-790", "gpu"),
+		src("Oracle-Linux-8.10-aarch64-2025.03.19-0-OKE-1.31.1-790", "arm"),
+		src("Oracle-Linux-8.10-2025.03.19-0-OKE-1.30.1-790", "other-version"),
+	}
+
+	tests := []struct {
+		shape, want string
+	}{
+		{"VM.Standard.E4.Flex", "x86"},
+		{"VM.Standard.A1.Flex", "arm"},
+	}
+	for _, tt := range tests {
+		got, err := selectNodeImage(sources, "v1.31.1", tt.shape)
+		if err != nil {
+			t.Errorf("selectNodeImage(%s): %v", tt.shape, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("selectNodeImage(%s) = %q, want %q", tt.shape, got, tt.want)
+		}
+	}
+	if _, err := selectNodeImage(sources, "v1.29.1", "VM.Standard.E4.Flex"); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestNodePoolFromSettings(t *testing.T) {
+	cluster := &model.Cluster{Settings: map[string]string{
+		"OCI_OKE_USER_SHAPE":       "VM.Standard.E5.Flex",
+		"OCI_OKE_USER_OCPUS":       "4",
+		"OCI_OKE_USER_ZONES":       "AD-1, AD-2",
+		"OCI_OKE_USER_PREEMPTIBLE": "true",
+		"OCI_OKE_USER_COUNT":       "2",
+	}}
+
+	user, err := nodePoolFromSettings(cluster, "user")
+	if err != nil {
+		t.Fatalf("nodePoolFromSettings: %v", err)
+	}
+	if *user.InstanceType != "VM.Standard.E5.Flex" || user.Extensions[extOcpus] != float64(4) || user.Extensions[extMemoryInGBs] != float64(defaultNodeMemoryGB) {
+		t.Errorf("unexpected shape settings: %s %v", *user.InstanceType, user.Extensions)
+	}
+	if !reflect.DeepEqual(*user.Zones, []string{"AD-1", "AD-2"}) {
+		t.Errorf("Zones = %v", *user.Zones)
+	}
+	if user.Priority == nil || *user.Priority != "spot" {
+		t.Errorf("Priority = %v, want spot", user.Priority)
+	}
+	if *user.Autoscaling.Desired != 2 {
+		t.Errorf("Desired = %d, want 2", *user.Autoscaling.Desired)
+	}
+
+	system, err := nodePoolFromSettings(cluster, "system")
+	if err != nil {
+		t.Fatalf("nodePoolFromSettings: %v", err)
+	}
+	if *system.InstanceType != defaultNodeShape || *system.OSDiskSizeGiB != defaultNodeBootVolumeGB || system.Zones != nil || system.Priority != nil {
+		t.Errorf("unexpected system defaults: %+v", system)
+	}
+
+	cluster.Settings["OCI_OKE_SYSTEM_OCPUS"] = "two"
+	if _, err := nodePoolFromSettings(cluster, "system"); err == nil {
+		t.Error("expected error for invalid OCPUS")
+	}
+}
+
+func TestNodePoolLifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var objects []runtime.Object
+	d, f := newClusterTestDriver(t, &objects)
+	cluster := &model.Cluster{Name: "cls"}
+
+	if _, err := d.NodePoolList(ctx, cluster); err == nil {
+		t.Fatal("NodePoolList before provision: want error")
+	}
+	if err := d.ClusterProvision(ctx, cluster); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+
+	desired := 2
+	pool, err := d.NodePoolCreate(ctx, cluster, model.NodePool{
+		Name:        common.String("batch"),
+		Zones:       &[]string{"2"},
+		Priority:    common.String("spot"),
+		Labels:      &map[string]string{"team": "a"},
+		Autoscaling: &model.NodePoolAutoscaling{Desired: &desired},
+	})
+	if err != nil {
+		t.Fatalf("NodePoolCreate: %v", err)
+	}
+	if z := *pool.Zones; len(z) != 1 || z[0] != "AD-2" || *pool.Priority != "spot" || (*pool.Labels)["team"] != "a" || *pool.Autoscaling.Desired != 2 {
+		t.Errorf("NodePoolCreate = zones %v priority %s labels %v", z, *pool.Priority, *pool.Labels)
+	}
+	if _, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: common.String("auto"), Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 1, Max: 3}}); !errors.Is(err, model.ErrNotSupported) {
+		t.Errorf("NodePoolCreate with autoscaling = %v, want ErrNotSupported", err)
+	}
+
+	pools, err := d.NodePoolList(ctx, cluster, model.WithNodePoolListName("batch"))
+	if err != nil || len(pools) != 1 {
+		t.Fatalf("NodePoolList(batch) = %d pools, %v; want 1", len(pools), err)
+	}
+
+	desired, disk := 3, 100
+	pool, err = d.NodePoolUpdate(ctx, cluster, model.NodePool{
+		Name:          common.String("batch"),
+		Labels:        &map[string]string{"team": "b"},
+		Autoscaling:   &model.NodePoolAutoscaling{Desired: &desired},
+		OSDiskSizeGiB: &disk,
+	})
+	if err != nil {
+		t.Fatalf("NodePoolUpdate: %v", err)
+	}
+	if l := *pool.Labels; l["team"] != "b" || l[labelNodePool] != "batch" || l[labelNodeZone] != "AD-2" || *pool.Autoscaling.Desired != 3 || *pool.OSDiskSizeGiB != 100 {
+		t.Errorf("NodePoolUpdate = labels %v desired %d disk %d", l, *pool.Autoscaling.Desired, *pool.OSDiskSizeGiB)
+	}
+	if z := *pool.Zones; len(z) != 1 || z[0] != "AD-2" {
+		t.Errorf("NodePoolUpdate zones = %v, want [AD-2]", z)
+	}
+	disk = 50
+	if _, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: common.String("batch"), OSDiskSizeGiB: &disk}); err == nil || !strings.Contains(err.Error(), "cannot be shrunk") {
+		t.Errorf("NodePoolUpdate shrinking the boot volume = %v, want validation error", err)
+	}
+	if _, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: common.String("batch"), InstanceType: common.String("VM.Standard.A1.Flex")}); err == nil || !strings.Contains(err.Error(), "InstanceType is immutable") {
+		t.Errorf("NodePoolUpdate of an immutable field = %v, want validation error", err)
+	}
+	if _, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: common.String("missing")}); err == nil {
+		t.Error("NodePoolUpdate of a missing pool: want error")
+	}
+
+	for i := range 2 {
+		if err := d.NodePoolDelete(ctx, cluster, "batch"); err != nil {
+			t.Fatalf("NodePoolDelete #%d: %v", i+1, err)
+		}
+	}
+	if pools, err := d.NodePoolList(ctx, cluster, model.WithNodePoolListName("batch")); err != nil || len(pools) != 0 {
+		t.Errorf("NodePoolList(batch) after delete = %d pools, %v; want none", len(pools), err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		call func() error
+	}{
+		{"list", "GET /nodePools", func() error { _, err := d.NodePoolList(ctx, cluster); return err }},
+		{"list compartment", "GET /compartments", func() error { _, err := d.NodePoolList(ctx, cluster); return err }},
+		{"create", "POST /nodePools", func() error {
+			_, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: common.String("extra")})
+			return err
+		}},
+		{"create image", "GET /nodePoolOptions/{id}", func() error {
+			_, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: common.String("extra")})
+			return err
+		}},
+		{"update", "PUT /nodePools/{id}", func() error {
+			_, err := d.NodePoolUpdate(ctx, cluster, model.NodePool{Name: common.String("user"), Labels: &map[string]string{"x": "y"}})
+			return err
+		}},
+		{"delete", "DELETE /nodePools/{id}", func() error { return d.NodePoolDelete(ctx, cluster, "user") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.setFail(tt.key, "InvalidParameter")
+			defer f.setFail(tt.key, "")
+			if err := tt.call(); err == nil || !strings.Contains(err.Error(), "InvalidParameter") {
+				t.Errorf("got %v, want the %s error", err, tt.key)
+			}
+		})
+	}
+
+	// Failed work requests are reported with their error messages
+	f.mu.Lock()
+	f.failWork["NODEPOOL_CREATE"] = "out of host capacity"
+	f.mu.Unlock()
+	if _, err := d.NodePoolCreate(ctx, cluster, model.NodePool{Name: common.String("extra")}); err == nil || !strings.Contains(err.Error(), "out of host capacity") {
+		t.Errorf("NodePoolCreate with a failed work request = %v, want the work request error", err)
+	}
+}
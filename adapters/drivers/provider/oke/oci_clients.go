@@ -0,0 +1,153 @@
+package oke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// pollInterval is the interval between polls of work requests and resource lifecycle states.
+var pollInterval = 10 * time.Second
+
+// setEndpoint points the client at the configured region or the endpoint override.
+func (d *driver) setEndpoint(c *common.BaseClient) {
+	if d.endpoint != "" {
+		c.Host = d.endpoint
+	}
+}
+
+// identityClient returns an OCI Identity client (compartments, availability domains).
+func (d *driver) identityClient() (identity.IdentityClient, error) {
+	c, err := identity.NewIdentityClientWithConfigurationProvider(d.configProvider)
+	if err != nil {
+		return c, fmt.Errorf("new identity client: %w", err)
+	}
+	c.SetRegion(d.region)
+	d.setEndpoint(&c.BaseClient)
+	return c, nil
+}
+
+// networkClient returns an OCI Virtual Network client (VCN, subnets, gateways).
+func (d *driver) networkClient() (core.VirtualNetworkClient, error) {
+	c, err := core.NewVirtualNetworkClientWithConfigurationProvider(d.configProvider)
+	if err != nil {
+		return c, fmt.Errorf("new virtual network client: %w", err)
+	}
+	c.SetRegion(d.region)
+	d.setEndpoint(&c.BaseClient)
+	return c, nil
+}
+
+// blockstorageClient returns an OCI Block Storage client (block volumes, volume backups).
+func (d *driver) blockstorageClient() (core.BlockstorageClient, error) {
+	c, err := core.NewBlockstorageClientWithConfigurationProvider(d.configProvider)
+	if err != nil {
+		return c, fmt.Errorf("new blockstorage client: %w", err)
+	}
+	c.SetRegion(d.region)
+	d.setEndpoint(&c.BaseClient)
+	return c, nil
+}
+
+// containerEngineClient returns an OCI Container Engine client (OKE clusters, node pools, work requests).
+func (d *driver) containerEngineClient() (containerengine.ContainerEngineClient, error) {
+	c, err := containerengine.NewContainerEngineClientWithConfigurationProvider(d.configProvider)
+	if err != nil {
+		return c, fmt.Errorf("new container engine client: %w", err)
+	}
+	c.SetRegion(d.region)
+	d.setEndpoint(&c.BaseClient)
+	return c, nil
+}
+
+// isNotFoundError checks if an error is an OCI 404 service error.
+func isNotFoundError(err error) bool {
+	return serviceErrorStatus(err) == http.StatusNotFound
+}
+
+// isConflictError checks if an error is an OCI 409 service error (e.g. dependent resources still exist).
+func isConflictError(err error) bool {
+	return serviceErrorStatus(err) == http.StatusConflict
+}
+
+func serviceErrorStatus(err error) int {
+	var se common.ServiceError
+	if errors.As(err, &se) {
+		return se.GetHTTPStatusCode()
+	}
+	return 0
+}
+
+// waitFor polls check every pollInterval until it reports done, returns an error or ctx expires.
+func waitFor(ctx context.Context, what string, check func(ctx context.Context) (bool, error)) error {
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for %s: %w", what, err)
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for %s: %w", what, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// waitWorkRequest polls an OKE work request until it reaches a terminal state.
+// Failed or canceled work requests are reported with the messages of their errors.
+func waitWorkRequest(ctx context.Context, ce containerengine.ContainerEngineClient, id *string) (*containerengine.WorkRequest, error) {
+	if id == nil || *id == "" {
+		return nil, fmt.Errorf("work request id is empty")
+	}
+	var wr containerengine.WorkRequest
+	err := waitFor(ctx, "work request "+*id, func(ctx context.Context) (bool, error) {
+		res, err := ce.GetWorkRequest(ctx, containerengine.GetWorkRequestRequest{WorkRequestId: id})
+		if err != nil {
+			return false, err
+		}
+		wr = res.WorkRequest
+		switch wr.Status {
+		case containerengine.WorkRequestStatusSucceeded:
+			return true, nil
+		case containerengine.WorkRequestStatusFailed, containerengine.WorkRequestStatusCanceled:
+			msgs := []string{}
+			if errs, err := ce.ListWorkRequestErrors(ctx, containerengine.ListWorkRequestErrorsRequest{WorkRequestId: id, CompartmentId: wr.CompartmentId}); err == nil {
+				for _, e := range errs.Items {
+					if e.Message != nil {
+						msgs = append(msgs, *e.Message)
+					}
+				}
+			}
+			return false, fmt.Errorf("work request %s %s: %s", wr.OperationType, wr.Status, strings.Join(msgs, "; "))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wr, nil
+}
+
+// workRequestResourceID returns the identifier of the first resource of entityType affected by the work request.
+func workRequestResourceID(wr *containerengine.WorkRequest, entityType string) string {
+	if wr == nil {
+		return ""
+	}
+	for _, r := range wr.Resources {
+		if r.EntityType != nil && r.Identifier != nil && strings.EqualFold(*r.EntityType, entityType) {
+			return *r.Identifier
+		}
+	}
+	return ""
+}
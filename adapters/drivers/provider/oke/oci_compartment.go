@@ -0,0 +1,178 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// findCompartment returns the ACTIVE child compartment of the parent compartment with the given name.
+// Returns nil without error when no such compartment exists.
+func (d *driver) findCompartment(ctx context.Context, name string) (*identity.Compartment, error) {
+	ic, err := d.identityClient()
+	if err != nil {
+		return nil, err
+	}
+	req := identity.ListCompartmentsRequest{
+		CompartmentId:  common.String(d.compartmentOCID),
+		Name:           common.String(name),
+		LifecycleState: identity.CompartmentLifecycleStateActive,
+	}
+	for {
+		res, err := ic.ListCompartments(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("list compartments: %w", err)
+		}
+		for i := range res.Items {
+			if c := res.Items[i]; c.Name != nil && *c.Name == name {
+				return &c, nil
+			}
+		}
+		if res.OpcNextPage == nil {
+			return nil, nil
+		}
+		req.Page = res.OpcNextPage
+	}
+}
+
+// ensureCompartmentCreated creates the named child compartment of the parent compartment if missing
+// and waits until it becomes ACTIVE. Existing compartments are returned unchanged.
+func (d *driver) ensureCompartmentCreated(ctx context.Context, name string, tags map[string]string) (*identity.Compartment, error) {
+	log := logging.FromContext(ctx)
+
+	comp, err := d.findCompartment(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if comp != nil {
+		return comp, nil
+	}
+
+	ic, err := d.identityClient()
+	if err != nil {
+		return nil, err
+	}
+	log.Info(ctx, "creating compartment", "name", name)
+	res, err := ic.CreateCompartment(ctx, identity.CreateCompartmentRequest{
+		CreateCompartmentDetails: identity.CreateCompartmentDetails{
+			CompartmentId: common.String(d.compartmentOCID),
+			Name:          common.String(name),
+			Description:   common.String("Managed by Kompox"),
+			FreeformTags:  tags,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create compartment %s: %w", name, err)
+	}
+	comp = &res.Compartment
+
+	// Compartment creation is eventually consistent; wait until it is usable
+	err = waitFor(ctx, "compartment "+name, func(ctx context.Context) (bool, error) {
+		got, err := ic.GetCompartment(ctx, identity.GetCompartmentRequest{CompartmentId: comp.Id})
+		if err != nil {
+			if isNotFoundError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		comp = &got.Compartment
+		return comp.LifecycleState == identity.CompartmentLifecycleStateActive, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comp, nil
+}
+
+// ensureCompartmentTags merges kv into the freeform tags of the compartment.
+// The update is skipped when all values are already recorded.
+func (d *driver) ensureCompartmentTags(ctx context.Context, comp *identity.Compartment, kv map[string]string) error {
+	tags := maps.Clone(comp.FreeformTags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	changed := false
+	for k, v := range kv {
+		if tags[k] != v {
+			tags[k] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	ic, err := d.identityClient()
+	if err != nil {
+		return err
+	}
+	res, err := ic.UpdateCompartment(ctx, identity.UpdateCompartmentRequest{
+		CompartmentId:            comp.Id,
+		UpdateCompartmentDetails: identity.UpdateCompartmentDetails{FreeformTags: tags},
+	})
+	if err != nil {
+		return fmt.Errorf("update compartment tags: %w", err)
+	}
+	*comp = res.Compartment
+	return nil
+}
+
+// ensureCompartmentDeleted requests deletion of the named compartment. OCI deletes compartments
+// asynchronously and only when they are empty, so callers must delete child resources first.
+// A missing compartment is not an error.
+func (d *driver) ensureCompartmentDeleted(ctx context.Context, name string) error {
+	log := logging.FromContext(ctx)
+
+	comp, err := d.findCompartment(ctx, name)
+	if err != nil {
+		return err
+	}
+	if comp == nil {
+		log.Info(ctx, "compartment not found, skipping delete", "name", name)
+		return nil
+	}
+
+	ic, err := d.identityClient()
+	if err != nil {
+		return err
+	}
+	res, err := ic.DeleteCompartment(ctx, identity.DeleteCompartmentRequest{CompartmentId: comp.Id})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("delete compartment %s: %w", name, err)
+	}
+	workRequestID := ""
+	if res.OpcWorkRequestId != nil {
+		workRequestID = *res.OpcWorkRequestId
+	}
+	log.Info(ctx, "compartment deletion requested", "name", name, "workRequest", workRequestID)
+	return nil
+}
+
+// availabilityDomains returns the availability domain names of the region.
+func (d *driver) availabilityDomains(ctx context.Context) ([]string, error) {
+	ic, err := d.identityClient()
+	if err != nil {
+		return nil, err
+	}
+	res, err := ic.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{CompartmentId: common.String(d.tenancyOCID)})
+	if err != nil {
+		return nil, fmt.Errorf("list availability domains: %w", err)
+	}
+	var ads []string
+	for _, ad := range res.Items {
+		if ad.Name != nil {
+			ads = append(ads, *ad.Name)
+		}
+	}
+	if len(ads) == 0 {
+		return nil, fmt.Errorf("no availability domains found in region %s", d.region)
+	}
+	return ads, nil
+}
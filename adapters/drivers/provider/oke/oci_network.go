@@ -0,0 +1,351 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Cluster network layout. All subnets are regional and public: the API endpoint and
+// load balancers are reachable from the internet and nodes reach it through the internet gateway.
+const (
+	vcnCIDR            = "10.0.0.0/16"
+	endpointSubnetCIDR = "10.0.0.0/28"
+	nodeSubnetCIDR     = "10.0.16.0/20"
+	lbSubnetCIDR       = "10.0.32.0/24"
+	anywhereCIDR       = "0.0.0.0/0"
+)
+
+// Display names of the network resources inside the cluster compartment.
+const (
+	vcnDisplayName             = "kompox-vcn"
+	internetGatewayDisplayName = "kompox-igw"
+	endpointSubnetDisplayName  = "kompox-endpoint"
+	nodeSubnetDisplayName      = "kompox-nodes"
+	lbSubnetDisplayName        = "kompox-lb"
+)
+
+// networkState holds the OCIDs of the cluster network resources.
+type networkState struct {
+	VCNID            string
+	EndpointSubnetID string
+	NodeSubnetID     string
+	LBSubnetID       string
+}
+
+// networkStateFromTags reads the network state recorded on the cluster compartment.
+func networkStateFromTags(tags map[string]string) networkState {
+	return networkState{
+		VCNID:            tags[tagStateVCNOCID],
+		EndpointSubnetID: tags[tagStateEndpointSubnet],
+		NodeSubnetID:     tags[tagStateNodeSubnet],
+		LBSubnetID:       tags[tagStateLBSubnet],
+	}
+}
+
+// tags returns the compartment state tags of the network.
+func (n networkState) tags() map[string]string {
+	return map[string]string{
+		tagStateVCNOCID:        n.VCNID,
+		tagStateEndpointSubnet: n.EndpointSubnetID,
+		tagStateNodeSubnet:     n.NodeSubnetID,
+		tagStateLBSubnet:       n.LBSubnetID,
+	}
+}
+
+// ensureNetworkCreated converges the VCN, internet gateway, default route table, default security list
+// and subnets of the cluster compartment, and records their OCIDs as compartment tags.
+func (d *driver) ensureNetworkCreated(ctx context.Context, comp *identity.Compartment, tags map[string]string) (networkState, error) {
+	log := logging.FromContext(ctx)
+
+	nc, err := d.networkClient()
+	if err != nil {
+		return networkState{}, err
+	}
+
+	// VCN
+	vcn, err := d.findVcn(ctx, nc, comp)
+	if err != nil {
+		return networkState{}, err
+	}
+	if vcn == nil {
+		log.Info(ctx, "creating VCN", "compartment", *comp.Name)
+		res, err := nc.CreateVcn(ctx, core.CreateVcnRequest{CreateVcnDetails: core.CreateVcnDetails{
+			CompartmentId: comp.Id,
+			CidrBlocks:    []string{vcnCIDR},
+			DisplayName:   common.String(vcnDisplayName),
+			FreeformTags:  tags,
+		}})
+		if err != nil {
+			return networkState{}, fmt.Errorf("create VCN: %w", err)
+		}
+		vcn = &res.Vcn
+	}
+	if err := waitFor(ctx, "VCN", func(ctx context.Context) (bool, error) {
+		res, err := nc.GetVcn(ctx, core.GetVcnRequest{VcnId: vcn.Id})
+		if err != nil {
+			return false, err
+		}
+		vcn = &res.Vcn
+		return vcn.LifecycleState == core.VcnLifecycleStateAvailable, nil
+	}); err != nil {
+		return networkState{}, err
+	}
+
+	// Internet gateway and default route
+	igw, err := d.ensureInternetGatewayCreated(ctx, nc, comp, vcn, tags)
+	if err != nil {
+		return networkState{}, err
+	}
+	if _, err := nc.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+		RtId: vcn.DefaultRouteTableId,
+		UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: []core.RouteRule{{
+			NetworkEntityId: igw.Id,
+			Destination:     common.String(anywhereCIDR),
+			DestinationType: core.RouteRuleDestinationTypeCidrBlock,
+		}}},
+	}); err != nil {
+		return networkState{}, fmt.Errorf("update default route table: %w", err)
+	}
+
+	// Default security list shared by all subnets
+	if _, err := nc.UpdateSecurityList(ctx, core.UpdateSecurityListRequest{
+		SecurityListId:            vcn.DefaultSecurityListId,
+		UpdateSecurityListDetails: defaultSecurityRules(),
+	}); err != nil {
+		return networkState{}, fmt.Errorf("update default security list: %w", err)
+	}
+
+	// Subnets
+	state := networkState{VCNID: *vcn.Id}
+	for _, s := range []struct {
+		name, cidr string
+		id         *string
+	}{
+		{endpointSubnetDisplayName, endpointSubnetCIDR, &state.EndpointSubnetID},
+		{nodeSubnetDisplayName, nodeSubnetCIDR, &state.NodeSubnetID},
+		{lbSubnetDisplayName, lbSubnetCIDR, &state.LBSubnetID},
+	} {
+		id, err := d.ensureSubnetCreated(ctx, nc, comp, vcn, s.name, s.cidr, tags)
+		if err != nil {
+			return networkState{}, err
+		}
+		*s.id = id
+	}
+
+	if err := d.ensureCompartmentTags(ctx, comp, state.tags()); err != nil {
+		return networkState{}, err
+	}
+	return state, nil
+}
+
+// findVcn returns the cluster VCN recorded in the compartment tags or found by display name.
+func (d *driver) findVcn(ctx context.Context, nc core.VirtualNetworkClient, comp *identity.Compartment) (*core.Vcn, error) {
+	if id := comp.FreeformTags[tagStateVCNOCID]; id != "" {
+		res, err := nc.GetVcn(ctx, core.GetVcnRequest{VcnId: common.String(id)})
+		if err == nil && res.LifecycleState != core.VcnLifecycleStateTerminated && res.LifecycleState != core.VcnLifecycleStateTerminating {
+			return &res.Vcn, nil
+		}
+		if err != nil && !isNotFoundError(err) {
+			return nil, fmt.Errorf("get VCN: %w", err)
+		}
+	}
+	res, err := nc.ListVcns(ctx, core.ListVcnsRequest{
+		CompartmentId: comp.Id,
+		DisplayName:   common.String(vcnDisplayName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list VCNs: %w", err)
+	}
+	for i := range res.Items {
+		if s := res.Items[i].LifecycleState; s == core.VcnLifecycleStateAvailable || s == core.VcnLifecycleStateProvisioning || s == core.VcnLifecycleStateUpdating {
+			return &res.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ensureInternetGatewayCreated creates the internet gateway of the VCN if missing.
+func (d *driver) ensureInternetGatewayCreated(ctx context.Context, nc core.VirtualNetworkClient, comp *identity.Compartment, vcn *core.Vcn, tags map[string]string) (*core.InternetGateway, error) {
+	res, err := nc.ListInternetGateways(ctx, core.ListInternetGatewaysRequest{
+		CompartmentId: comp.Id,
+		VcnId:         vcn.Id,
+		DisplayName:   common.String(internetGatewayDisplayName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list internet gateways: %w", err)
+	}
+	for i := range res.Items {
+		if s := res.Items[i].LifecycleState; s == core.InternetGatewayLifecycleStateAvailable || s == core.InternetGatewayLifecycleStateProvisioning {
+			return &res.Items[i], nil
+		}
+	}
+	created, err := nc.CreateInternetGateway(ctx, core.CreateInternetGatewayRequest{CreateInternetGatewayDetails: core.CreateInternetGatewayDetails{
+		CompartmentId: comp.Id,
+		VcnId:         vcn.Id,
+		IsEnabled:     common.Bool(true),
+		DisplayName:   common.String(internetGatewayDisplayName),
+		FreeformTags:  tags,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("create internet gateway: %w", err)
+	}
+	return &created.InternetGateway, nil
+}
+
+// ensureSubnetCreated creates the named regional subnet if missing and waits until it is available.
+func (d *driver) ensureSubnetCreated(ctx context.Context, nc core.VirtualNetworkClient, comp *identity.Compartment, vcn *core.Vcn, name, cidr string, tags map[string]string) (string, error) {
+	res, err := nc.ListSubnets(ctx, core.ListSubnetsRequest{
+		CompartmentId: comp.Id,
+		VcnId:         vcn.Id,
+		DisplayName:   common.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("list subnets: %w", err)
+	}
+	var subnet *core.Subnet
+	for i := range res.Items {
+		if s := res.Items[i].LifecycleState; s == core.SubnetLifecycleStateAvailable || s == core.SubnetLifecycleStateProvisioning || s == core.SubnetLifecycleStateUpdating {
+			subnet = &res.Items[i]
+			break
+		}
+	}
+	if subnet == nil {
+		created, err := nc.CreateSubnet(ctx, core.CreateSubnetRequest{CreateSubnetDetails: core.CreateSubnetDetails{
+			CompartmentId:          comp.Id,
+			VcnId:                  vcn.Id,
+			CidrBlock:              common.String(cidr),
+			DisplayName:            common.String(name),
+			ProhibitPublicIpOnVnic: common.Bool(false),
+			RouteTableId:           vcn.DefaultRouteTableId,
+			SecurityListIds:        []string{*vcn.DefaultSecurityListId},
+			FreeformTags:           tags,
+		}})
+		if err != nil {
+			return "", fmt.Errorf("create subnet %s: %w", name, err)
+		}
+		subnet = &created.Subnet
+	}
+	if err := waitFor(ctx, "subnet "+name, func(ctx context.Context) (bool, error) {
+		res, err := nc.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: subnet.Id})
+		if err != nil {
+			return false, err
+		}
+		return res.LifecycleState == core.SubnetLifecycleStateAvailable, nil
+	}); err != nil {
+		return "", err
+	}
+	return *subnet.Id, nil
+}
+
+// defaultSecurityRules returns the rules of the default security list: all traffic inside the VCN,
+// the Kubernetes API and HTTP(S) from anywhere, path MTU discovery, and unrestricted egress.
+func defaultSecurityRules() core.UpdateSecurityListDetails {
+	tcp := func(port int) *core.TcpOptions {
+		return &core.TcpOptions{DestinationPortRange: &core.PortRange{Min: common.Int(port), Max: common.Int(port)}}
+	}
+	return core.UpdateSecurityListDetails{
+		IngressSecurityRules: []core.IngressSecurityRule{
+			{Protocol: common.String("all"), Source: common.String(vcnCIDR), Description: common.String("VCN internal")},
+			{Protocol: common.String("6"), Source: common.String(anywhereCIDR), TcpOptions: tcp(6443), Description: common.String("Kubernetes API")},
+			{Protocol: common.String("6"), Source: common.String(anywhereCIDR), TcpOptions: tcp(80), Description: common.String("HTTP")},
+			{Protocol: common.String("6"), Source: common.String(anywhereCIDR), TcpOptions: tcp(443), Description: common.String("HTTPS")},
+			{Protocol: common.String("1"), Source: common.String(anywhereCIDR), IcmpOptions: &core.IcmpOptions{Type: common.Int(3), Code: common.Int(4)}, Description: common.String("Path MTU discovery")},
+		},
+		EgressSecurityRules: []core.EgressSecurityRule{
+			{Protocol: common.String("all"), Destination: common.String(anywhereCIDR)},
+		},
+	}
+}
+
+// ensureNetworkDeleted deletes all subnets, internet gateways and VCNs of the cluster compartment.
+// Deletions are retried while dependent resources (e.g. terminating subnets or service load balancers)
+// still exist. Missing resources are not errors.
+func (d *driver) ensureNetworkDeleted(ctx context.Context, comp *identity.Compartment) error {
+	log := logging.FromContext(ctx)
+
+	nc, err := d.networkClient()
+	if err != nil {
+		return err
+	}
+	vcns, err := nc.ListVcns(ctx, core.ListVcnsRequest{CompartmentId: comp.Id})
+	if err != nil {
+		return fmt.Errorf("list VCNs: %w", err)
+	}
+	for _, vcn := range vcns.Items {
+		if vcn.LifecycleState == core.VcnLifecycleStateTerminated {
+			continue
+		}
+
+		subnets, err := nc.ListSubnets(ctx, core.ListSubnetsRequest{CompartmentId: comp.Id, VcnId: vcn.Id})
+		if err != nil {
+			return fmt.Errorf("list subnets: %w", err)
+		}
+		for _, s := range subnets.Items {
+			if s.LifecycleState == core.SubnetLifecycleStateTerminated {
+				continue
+			}
+			log.Info(ctx, "deleting subnet", "subnet", *s.Id)
+			if err := retryWhileConflict(ctx, "delete subnet "+*s.Id, func(ctx context.Context) error {
+				_, err := nc.DeleteSubnet(ctx, core.DeleteSubnetRequest{SubnetId: s.Id})
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Route rules reference the internet gateway; clear them before deleting it
+		if vcn.DefaultRouteTableId != nil {
+			if _, err := nc.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+				RtId:                    vcn.DefaultRouteTableId,
+				UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: []core.RouteRule{}},
+			}); err != nil && !isNotFoundError(err) {
+				return fmt.Errorf("clear default route table: %w", err)
+			}
+		}
+		igws, err := nc.ListInternetGateways(ctx, core.ListInternetGatewaysRequest{CompartmentId: comp.Id, VcnId: vcn.Id})
+		if err != nil {
+			return fmt.Errorf("list internet gateways: %w", err)
+		}
+		for _, igw := range igws.Items {
+			if igw.LifecycleState == core.InternetGatewayLifecycleStateTerminated {
+				continue
+			}
+			if err := retryWhileConflict(ctx, "delete internet gateway "+*igw.Id, func(ctx context.Context) error {
+				_, err := nc.DeleteInternetGateway(ctx, core.DeleteInternetGatewayRequest{IgId: igw.Id})
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		log.Info(ctx, "deleting VCN", "vcn", *vcn.Id)
+		if err := retryWhileConflict(ctx, "delete VCN "+*vcn.Id, func(ctx context.Context) error {
+			_, err := nc.DeleteVcn(ctx, core.DeleteVcnRequest{VcnId: vcn.Id})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryWhileConflict calls fn until it succeeds, returns NotFound (treated as success)
+// or returns an error other than Conflict.
+func retryWhileConflict(ctx context.Context, what string, fn func(ctx context.Context) error) error {
+	return waitFor(ctx, what, func(ctx context.Context) (bool, error) {
+		err := fn(ctx)
+		switch {
+		case err == nil, isNotFoundError(err):
+			return true, nil
+		case isConflictError(err):
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/containerengine"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Cluster setting keys for the OKE cluster itself.
+const (
+	keyKubernetesVersion = "OCI_OKE_KUBERNETES_VERSION"
+	keyNodeImageID       = "OCI_OKE_NODE_IMAGE_ID"
+)
+
+// clusterCompartment returns the cluster compartment or an error when the cluster is not provisioned.
+func (d *driver) clusterCompartment(ctx context.Context, cluster *model.Cluster) (*identity.Compartment, error) {
+	name, err := d.clusterCompartmentName(cluster)
+	if err != nil {
+		return nil, err
+	}
+	comp, err := d.findCompartment(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if comp == nil {
+		return nil, fmt.Errorf("cluster compartment %s not found", name)
+	}
+	return comp, nil
+}
+
+// findOKECluster returns the OKE cluster of the compartment. The OCID recorded in the compartment
+// tags is preferred; otherwise the cluster is looked up by name. Returns nil when not found.
+func (d *driver) findOKECluster(ctx context.Context, ce containerengine.ContainerEngineClient, comp *identity.Compartment, name string) (*containerengine.Cluster, error) {
+	if id := comp.FreeformTags[tagStateClusterOCID]; id != "" {
+		res, err := ce.GetCluster(ctx, containerengine.GetClusterRequest{ClusterId: common.String(id)})
+		if err == nil && res.LifecycleState != containerengine.ClusterLifecycleStateDeleted {
+			return &res.Cluster, nil
+		}
+		if err != nil && !isNotFoundError(err) {
+			return nil, fmt.Errorf("get OKE cluster: %w", err)
+		}
+	}
+	res, err := ce.ListClusters(ctx, containerengine.ListClustersRequest{
+		CompartmentId: comp.Id,
+		Name:          common.String(name),
+		LifecycleState: []containerengine.ClusterLifecycleStateEnum{
+			containerengine.ClusterLifecycleStateCreating,
+			containerengine.ClusterLifecycleStateActive,
+			containerengine.ClusterLifecycleStateUpdating,
+			containerengine.ClusterLifecycleStateFailed,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list OKE clusters: %w", err)
+	}
+	if len(res.Items) == 0 || res.Items[0].Id == nil {
+		return nil, nil
+	}
+	got, err := ce.GetCluster(ctx, containerengine.GetClusterRequest{ClusterId: res.Items[0].Id})
+	if err != nil {
+		return nil, fmt.Errorf("get OKE cluster: %w", err)
+	}
+	return &got.Cluster, nil
+}
+
+// ensureOKEClusterCreated creates the OKE cluster in the cluster compartment if missing, waits until it is
+// ACTIVE and records its OCID as a compartment tag.
+func (d *driver) ensureOKEClusterCreated(ctx context.Context, cluster *model.Cluster, comp *identity.Compartment, net networkState, tags map[string]string) (*containerengine.Cluster, error) {
+	log := logging.FromContext(ctx)
+
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return nil, err
+	}
+	oke, err := d.findOKECluster(ctx, ce, comp, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if oke != nil && oke.LifecycleState == containerengine.ClusterLifecycleStateFailed {
+		return nil, fmt.Errorf("OKE cluster %s is in FAILED state; deprovision and retry", *oke.Id)
+	}
+
+	if oke == nil {
+		version, err := d.kubernetesVersion(ctx, ce, cluster)
+		if err != nil {
+			return nil, err
+		}
+		log.Info(ctx, "creating OKE cluster", "name", cluster.Name, "kubernetesVersion", version)
+		res, err := ce.CreateCluster(ctx, containerengine.CreateClusterRequest{CreateClusterDetails: containerengine.CreateClusterDetails{
+			Name:              common.String(cluster.Name),
+			CompartmentId:     comp.Id,
+			VcnId:             common.String(net.VCNID),
+			KubernetesVersion: common.String(version),
+			EndpointConfig: &containerengine.CreateClusterEndpointConfigDetails{
+				SubnetId:          common.String(net.EndpointSubnetID),
+				IsPublicIpEnabled: common.Bool(true),
+			},
+			Options: &containerengine.ClusterCreateOptions{
+				ServiceLbSubnetIds: []string{net.LBSubnetID},
+			},
+			FreeformTags: tags,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("create OKE cluster: %w", err)
+		}
+		wr, err := waitWorkRequest(ctx, ce, res.OpcWorkRequestId)
+		if err != nil {
+			return nil, fmt.Errorf("wait for OKE cluster creation: %w", err)
+		}
+		id := workRequestResourceID(wr, "cluster")
+		if id == "" {
+			return nil, fmt.Errorf("OKE cluster OCID not found in work request %s", *res.OpcWorkRequestId)
+		}
+		got, err := ce.GetCluster(ctx, containerengine.GetClusterRequest{ClusterId: common.String(id)})
+		if err != nil {
+			return nil, fmt.Errorf("get OKE cluster: %w", err)
+		}
+		oke = &got.Cluster
+	}
+
+	if err := waitFor(ctx, "OKE cluster", func(ctx context.Context) (bool, error) {
+		res, err := ce.GetCluster(ctx, containerengine.GetClusterRequest{ClusterId: oke.Id})
+		if err != nil {
+			return false, err
+		}
+		oke = &res.Cluster
+		if oke.LifecycleState == containerengine.ClusterLifecycleStateFailed {
+			return false, fmt.Errorf("OKE cluster %s failed", *oke.Id)
+		}
+		return oke.LifecycleState == containerengine.ClusterLifecycleStateActive, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := d.ensureCompartmentTags(ctx, comp, map[string]string{tagStateClusterOCID: *oke.Id}); err != nil {
+		return nil, err
+	}
+	return oke, nil
+}
+
+// ensureOKEClusterDeleted deletes all node pools and the OKE cluster of the compartment.
+// Missing resources are not errors.
+func (d *driver) ensureOKEClusterDeleted(ctx context.Context, cluster *model.Cluster, comp *identity.Compartment) error {
+	log := logging.FromContext(ctx)
+
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return err
+	}
+	oke, err := d.findOKECluster(ctx, ce, comp, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if oke == nil {
+		log.Info(ctx, "OKE cluster not found, skipping delete")
+		return nil
+	}
+
+	// Step 1: Node pools
+	pools, err := d.listNodePools(ctx, ce, comp, *oke.Id)
+	if err != nil {
+		return err
+	}
+	for _, np := range pools {
+		if err := d.deleteNodePool(ctx, ce, np.Id); err != nil {
+			return err
+		}
+	}
+
+	// Step 2: Cluster
+	if oke.LifecycleState == containerengine.ClusterLifecycleStateDeleting {
+		return d.waitOKEClusterDeleted(ctx, ce, oke.Id)
+	}
+	log.Info(ctx, "deleting OKE cluster", "cluster", *oke.Id)
+	res, err := ce.DeleteCluster(ctx, containerengine.DeleteClusterRequest{ClusterId: oke.Id})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("delete OKE cluster: %w", err)
+	}
+	if _, err := waitWorkRequest(ctx, ce, res.OpcWorkRequestId); err != nil {
+		return fmt.Errorf("wait for OKE cluster deletion: %w", err)
+	}
+	return nil
+}
+
+func (d *driver) waitOKEClusterDeleted(ctx context.Context, ce containerengine.ContainerEngineClient, id *string) error {
+	return waitFor(ctx, "OKE cluster deletion", func(ctx context.Context) (bool, error) {
+		res, err := ce.GetCluster(ctx, containerengine.GetClusterRequest{ClusterId: id})
+		if err != nil {
+			if isNotFoundError(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return res.LifecycleState == containerengine.ClusterLifecycleStateDeleted, nil
+	})
+}
+
+// kubernetesVersion returns the Kubernetes version of new clusters: the cluster setting when given,
+// otherwise the latest version offered by OKE.
+func (d *driver) kubernetesVersion(ctx context.Context, ce containerengine.ContainerEngineClient, cluster *model.Cluster) (string, error) {
+	if v := strings.TrimSpace(cluster.Settings[keyKubernetesVersion]); v != "" {
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		return v, nil
+	}
+	res, err := ce.GetClusterOptions(ctx, containerengine.GetClusterOptionsRequest{ClusterOptionId: common.String("all")})
+	if err != nil {
+		return "", fmt.Errorf("get cluster options: %w", err)
+	}
+	if len(res.KubernetesVersions) == 0 {
+		return "", fmt.Errorf("no Kubernetes versions offered by OKE")
+	}
+	versions := append([]string(nil), res.KubernetesVersions...)
+	sort.Slice(versions, func(i, j int) bool { return versionLess(versions[i], versions[j]) })
+	return versions[len(versions)-1], nil
+}
+
+// versionLess compares "v1.31.1" style versions numerically.
+func versionLess(a, b string) bool {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		var na, nb int
+		fmt.Sscanf(pa[i], "%d", &na)
+		fmt.Sscanf(pb[i], "%d", &nb)
+		if na != nb {
+			return na < nb
+		}
+	}
+	return len(pa) < len(pb)
+}
+
+// nodeImageID returns the node image of a node pool: the cluster setting when given, otherwise the
+// newest Oracle Linux OKE image matching the Kubernetes version and the shape architecture.
+func (d *driver) nodeImageID(ctx context.Context, ce containerengine.ContainerEngineClient, cluster *model.Cluster, clusterID, version, shape string) (string, error) {
+	if v := strings.TrimSpace(cluster.Settings[keyNodeImageID]); v != "" {
+		return v, nil
+	}
+	res, err := ce.GetNodePoolOptions(ctx, containerengine.GetNodePoolOptionsRequest{NodePoolOptionId: common.String(clusterID)})
+	if err != nil {
+		return "", fmt.Errorf("get node pool options: %w", err)
+	}
+	return selectNodeImage(res.Sources, version, shape)
+}
+
+// selectNodeImage picks the newest Oracle Linux OKE image for the Kubernetes version. Arm shapes
+// (A1/A2) select aarch64 images; GPU images are never selected automatically.
+func selectNodeImage(sources []containerengine.NodeSourceOption, version, shape string) (string, error) {
+	arm := strings.Contains(shape, ".A1.") || strings.Contains(shape, ".A2.")
+	want := "-OKE-" + strings.TrimPrefix(version, "v") + "-"
+	var best, bestName string
+	for _, s := range sources {
+		img, ok := s.(containerengine.NodeSourceViaImageOption)
+		if !ok || img.SourceName == nil || img.ImageId == nil {
+			continue
+		}
+		name := *img.SourceName
+		if !strings.HasPrefix(name, "Oracle-Linux-") || !strings.Contains(name, want) || strings.Contains(name, "GPU") {
+			continue
+		}
+		if strings.Contains(name, "aarch64") != arm {
+			continue
+		}
+		if name > bestName {
+			best, bestName = *img.ImageId, name
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no Oracle Linux node image found for Kubernetes %s and shape %s (set %s)", version, shape, keyNodeImageID)
+	}
+	return best, nil
+}
+
+// okeKubeconfig returns the kubeconfig of the OKE cluster for its public endpoint.
+// The kubeconfig authenticates with the "oci ce cluster generate-token" exec plugin.
+func (d *driver) okeKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	comp, err := d.clusterCompartment(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ce, err := d.containerEngineClient()
+	if err != nil {
+		return nil, err
+	}
+	oke, err := d.findOKECluster(ctx, ce, comp, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if oke == nil {
+		return nil, fmt.Errorf("OKE cluster not found in compartment %s", *comp.Name)
+	}
+	res, err := ce.CreateKubeconfig(ctx, containerengine.CreateKubeconfigRequest{
+		ClusterId: oke.Id,
+		CreateClusterKubeconfigContentDetails: containerengine.CreateClusterKubeconfigContentDetails{
+			TokenVersion: common.String("2.0.0"),
+			Endpoint:     containerengine.CreateClusterKubeconfigContentDetailsEndpointPublicEndpoint,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create kubeconfig: %w", err)
+	}
+	defer res.Content.Close()
+	data, err := io.ReadAll(res.Content)
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig: %w", err)
+	}
+	return data, nil
+}
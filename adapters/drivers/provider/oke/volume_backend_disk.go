@@ -0,0 +1,801 @@
+package oke
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Block volume options (app.volumes.options)
+const (
+	diskOptionVpusPerGB = "vpusPerGB" // performance units per GB: 0 (lower cost), 10 (balanced), 20 (higher), 30-120 (ultra high)
+)
+
+// Block volume limits
+const (
+	minBlockVolumeSizeGB = 50
+	defaultVpusPerGB     = 10
+)
+
+// volumeBackendDisk implements volumeBackend interface for OCI Block Volumes (Type="disk").
+// Disks are block volumes and snapshots are volume backups in the app compartment.
+type volumeBackendDisk struct {
+	driver *driver
+}
+
+func newVolumeBackendDisk(d *driver) volumeBackend {
+	return &volumeBackendDisk{driver: d}
+}
+
+// appCompartment returns the app compartment or nil when it does not exist yet.
+func (vb *volumeBackendDisk) appCompartment(ctx context.Context, app *model.App) (*identity.Compartment, error) {
+	name, err := vb.driver.appCompartmentName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app compartment: %w", err)
+	}
+	return vb.driver.findCompartment(ctx, name)
+}
+
+// DiskList lists OCI Block Volumes for a volume (Type="disk").
+func (vb *volumeBackendDisk) DiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	comp, err := vb.appCompartment(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if comp == nil {
+		// App compartment doesn't exist yet
+		return []*model.VolumeDisk{}, nil
+	}
+	volumes, err := vb.listVolumes(ctx, comp)
+	if err != nil {
+		return nil, err
+	}
+	var out []*model.VolumeDisk
+	for i := range volumes {
+		if disk := vb.newDisk(&volumes[i], volName); disk != nil {
+			out = append(out, disk)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// DiskCreate creates a new OCI Block Volume for a volume (Type="disk").
+func (vb *volumeBackendDisk) DiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+	var optionsStruct model.VolumeDiskCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	// List existing disks to determine name if needed
+	items, err := vb.DiskList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list disks: %w", err)
+	}
+
+	diskName = strings.TrimSpace(diskName)
+	if diskName == "" {
+		diskName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == diskName {
+				return nil, fmt.Errorf("disk %q already exists", diskName)
+			}
+		}
+	}
+
+	displayName, err := vb.driver.appDiskName(app, volName, diskName)
+	if err != nil {
+		return nil, fmt.Errorf("generate disk resource name: %w", err)
+	}
+
+	vol, err := app.FindVolume(volName)
+	if err != nil {
+		return nil, fmt.Errorf("find volume %q: %w", volName, err)
+	}
+
+	// Get size from volume configuration (options may require a larger disk, e.g. for clone sources)
+	size := vol.Size
+	if optionsStruct.Size > size {
+		size = optionsStruct.Size
+	}
+	sizeGB := size >> 30
+	if sizeGB < minBlockVolumeSizeGB {
+		sizeGB = minBlockVolumeSizeGB
+	}
+
+	// Merge volume options with functional options
+	volOptions := maps.Clone(vol.Options)
+	if optionsStruct.Options != nil {
+		if volOptions == nil {
+			volOptions = map[string]any{}
+		}
+		maps.Copy(volOptions, optionsStruct.Options)
+	}
+	vpus, err := parseVpusPerGB(volOptions)
+	if err != nil {
+		return nil, err
+	}
+	if vpus == nil {
+		vpus = common.Int64(defaultVpusPerGB)
+	}
+
+	// Determine availability domain (options override app config; default is the first AD)
+	zone := app.Deployment.Zone
+	if optionsStruct.Zone != "" {
+		zone = optionsStruct.Zone
+	}
+	ads, err := vb.driver.availabilityDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ad := ads[0]
+	if strings.TrimSpace(zone) != "" {
+		if ad, err = resolveAD(zone, ads); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := vb.driver.appResourceTags(app.Name)
+	tags[tagVolumeName] = volName
+	tags[tagDiskName] = diskName
+	tags[tagDiskAssigned] = "false"
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
+
+	// Ensure app compartment exists before creating the volume
+	compName, err := vb.driver.appCompartmentName(app)
+	if err != nil {
+		return nil, fmt.Errorf("app compartment: %w", err)
+	}
+	comp, err := vb.driver.ensureCompartmentCreated(ctx, compName, vb.driver.appResourceTags(app.Name))
+	if err != nil {
+		return nil, fmt.Errorf("ensure app compartment: %w", err)
+	}
+
+	details := core.CreateVolumeDetails{
+		CompartmentId:      comp.Id,
+		AvailabilityDomain: common.String(ad),
+		DisplayName:        common.String(displayName),
+		SizeInGBs:          common.Int64(sizeGB),
+		VpusPerGB:          vpus,
+		FreeformTags:       tags,
+	}
+	if source = strings.TrimSpace(source); source != "" {
+		// Resolve source using snapshot resolution (defaults to "snapshot:" prefix if unknown)
+		src, err := vb.resolveSource(ctx, comp, app, volName, source, "snapshot")
+		if err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+		details.SourceDetails = src
+	}
+
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return nil, err
+	}
+	res, err := bs.CreateVolume(ctx, core.CreateVolumeRequest{CreateVolumeDetails: details})
+	if err != nil {
+		return nil, fmt.Errorf("create volume: %w", err)
+	}
+	volume, err := vb.waitVolumeAvailable(ctx, bs, res.Id)
+	if err != nil {
+		return nil, err
+	}
+	return vb.newDisk(volume, volName), nil
+}
+
+// DiskDelete deletes an OCI Block Volume (Type="disk"). A missing volume is not an error.
+func (vb *volumeBackendDisk) DiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	volume, err := vb.findVolume(ctx, app, volName, diskName)
+	if err != nil || volume == nil {
+		return err
+	}
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return err
+	}
+	if _, err := bs.DeleteVolume(ctx, core.DeleteVolumeRequest{VolumeId: volume.Id}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete volume: %w", err)
+	}
+	return nil
+}
+
+// DiskAssign assigns or unassigns OCI Block Volumes (Type="disk") by updating freeform tags.
+func (vb *volumeBackendDisk) DiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	comp, err := vb.appCompartment(ctx, app)
+	if err != nil {
+		return err
+	}
+	if comp == nil {
+		return fmt.Errorf("disk not found: %s", diskName)
+	}
+	volumes, err := vb.listVolumes(ctx, comp)
+	if err != nil {
+		return err
+	}
+
+	// Find the target disk
+	var found bool
+	for i := range volumes {
+		if disk := vb.newDisk(&volumes[i], volName); disk != nil && disk.Name == diskName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("disk not found: %s", diskName)
+	}
+
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return err
+	}
+	for i := range volumes {
+		disk := vb.newDisk(&volumes[i], volName)
+		if disk == nil {
+			continue
+		}
+		assigned := disk.Name == diskName
+		if assigned == disk.Assigned {
+			continue
+		}
+		tags := maps.Clone(volumes[i].FreeformTags)
+		tags[tagDiskAssigned] = strconv.FormatBool(assigned)
+		if _, err := bs.UpdateVolume(ctx, core.UpdateVolumeRequest{
+			VolumeId:            volumes[i].Id,
+			UpdateVolumeDetails: core.UpdateVolumeDetails{FreeformTags: tags},
+		}); err != nil {
+			return fmt.Errorf("update volume %s: %w", *volumes[i].DisplayName, err)
+		}
+	}
+	return nil
+}
+
+// DiskUpdate changes the performance (vpusPerGB) of an existing OCI Block Volume in place.
+// Other options cannot be changed online and are rejected.
+func (vb *volumeBackendDisk) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	var optionsStruct model.VolumeDiskUpdateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+	if len(optionsStruct.Options) == 0 {
+		return nil, diskOptionsError("no options to update")
+	}
+	for k := range optionsStruct.Options {
+		if k != diskOptionVpusPerGB {
+			return nil, diskOptionsError("option %q cannot be changed online (supported: %s)", k, diskOptionVpusPerGB)
+		}
+	}
+	vpus, err := parseVpusPerGB(optionsStruct.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	volume, err := vb.findVolume(ctx, app, volName, diskName)
+	if err != nil {
+		return nil, err
+	}
+	if volume == nil {
+		return nil, fmt.Errorf("disk not found: %s", diskName)
+	}
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bs.UpdateVolume(ctx, core.UpdateVolumeRequest{
+		VolumeId:            volume.Id,
+		UpdateVolumeDetails: core.UpdateVolumeDetails{VpusPerGB: vpus},
+	}); err != nil {
+		return nil, fmt.Errorf("update volume %s: %w", *volume.DisplayName, err)
+	}
+	volume, err = vb.waitVolumeAvailable(ctx, bs, volume.Id)
+	if err != nil {
+		return nil, err
+	}
+	return vb.newDisk(volume, volName), nil
+}
+
+// SnapshotList lists volume backups for a volume (Type="disk").
+func (vb *volumeBackendDisk) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	comp, err := vb.appCompartment(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if comp == nil {
+		// App compartment doesn't exist yet
+		return []*model.VolumeSnapshot{}, nil
+	}
+	backups, err := vb.listBackups(ctx, comp)
+	if err != nil {
+		return nil, err
+	}
+	var out []*model.VolumeSnapshot
+	for i := range backups {
+		if snap := vb.newSnapshot(&backups[i], volName); snap != nil {
+			out = append(out, snap)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// SnapshotCreate creates an incremental volume backup of an OCI Block Volume (Type="disk").
+func (vb *volumeBackendDisk) SnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	var optionsStruct model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&optionsStruct)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	// List existing snapshots to determine name if needed
+	items, err := vb.SnapshotList(ctx, cluster, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	snapName = strings.TrimSpace(snapName)
+	if snapName == "" {
+		snapName, err = naming.NewCompactID()
+		if err != nil {
+			return nil, fmt.Errorf("compact id: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if item.Name == snapName {
+				return nil, fmt.Errorf("snapshot %q already exists", snapName)
+			}
+		}
+	}
+
+	displayName, err := vb.driver.appSnapshotName(app, volName, snapName)
+	if err != nil {
+		return nil, fmt.Errorf("generate snapshot resource name: %w", err)
+	}
+
+	// Determine source volume OCID
+	var sourceID string
+	source = strings.TrimSpace(source)
+	if source == "" {
+		// Use assigned disk
+		disks, err := vb.DiskList(ctx, cluster, app, volName)
+		if err != nil {
+			return nil, fmt.Errorf("list disks: %w", err)
+		}
+		for _, d := range disks {
+			if d.Assigned && d.VolumeName == volName {
+				sourceID = d.Handle
+				break
+			}
+		}
+		if sourceID == "" {
+			return nil, fmt.Errorf("no assigned disk found for volume %q", volName)
+		}
+	} else {
+		comp, err := vb.appCompartment(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		src, err := vb.resolveSource(ctx, comp, app, volName, source, "disk")
+		if err != nil {
+			return nil, fmt.Errorf("resolve source %q: %w", source, err)
+		}
+		v, ok := src.(core.VolumeSourceFromVolumeDetails)
+		if !ok {
+			return nil, fmt.Errorf("resolve source %q: snapshot source must be a disk", source)
+		}
+		sourceID = *v.Id
+	}
+
+	tags := vb.driver.appResourceTags(app.Name)
+	tags[tagVolumeName] = volName
+	tags[tagSnapshotName] = snapName
+	setUserMetadataTags(tags, optionsStruct.Labels, optionsStruct.Description)
+
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return nil, err
+	}
+	res, err := bs.CreateVolumeBackup(ctx, core.CreateVolumeBackupRequest{CreateVolumeBackupDetails: core.CreateVolumeBackupDetails{
+		VolumeId:     common.String(sourceID),
+		DisplayName:  common.String(displayName),
+		Type:         core.CreateVolumeBackupDetailsTypeIncremental,
+		FreeformTags: tags,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("create volume backup: %w", err)
+	}
+	backup := &res.VolumeBackup
+	err = waitFor(ctx, "volume backup "+displayName, func(ctx context.Context) (bool, error) {
+		got, err := bs.GetVolumeBackup(ctx, core.GetVolumeBackupRequest{VolumeBackupId: res.Id})
+		if err != nil {
+			return false, err
+		}
+		backup = &got.VolumeBackup
+		if backup.LifecycleState == core.VolumeBackupLifecycleStateFaulty {
+			return false, fmt.Errorf("volume backup %s is FAULTY", *backup.Id)
+		}
+		return backup.LifecycleState == core.VolumeBackupLifecycleStateAvailable, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vb.newSnapshot(backup, volName), nil
+}
+
+// SnapshotDelete deletes a volume backup (Type="disk"). A missing backup is not an error.
+func (vb *volumeBackendDisk) SnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	comp, err := vb.appCompartment(ctx, app)
+	if err != nil || comp == nil {
+		return err
+	}
+	backup, err := vb.findBackup(ctx, comp, volName, snapName)
+	if err != nil || backup == nil {
+		return err
+	}
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return err
+	}
+	if _, err := bs.DeleteVolumeBackup(ctx, core.DeleteVolumeBackupRequest{VolumeBackupId: backup.Id}); err != nil && !isNotFoundError(err) {
+		return fmt.Errorf("delete volume backup: %w", err)
+	}
+	return nil
+}
+
+// Class returns OCI Block Volume provisioning parameters (Type="disk").
+// Volume options are validated so that invalid settings are reported before any volume is created.
+func (vb *volumeBackendDisk) Class(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	if _, err := parseVpusPerGB(vol.Options); err != nil {
+		return model.VolumeClass{}, err
+	}
+	return model.VolumeClass{
+		StorageClassName: "oci-bv",
+		CSIDriver:        "blockvolume.csi.oraclecloud.com",
+		FSType:           "ext4",
+		Attributes:       map[string]string{"fsType": "ext4"},
+		AccessModes:      []string{"ReadWriteOnce"},
+		ReclaimPolicy:    "Retain",
+		VolumeMode:       "Filesystem",
+	}, nil
+}
+
+// listVolumes lists the non-terminated block volumes of the compartment.
+func (vb *volumeBackendDisk) listVolumes(ctx context.Context, comp *identity.Compartment) ([]core.Volume, error) {
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return nil, err
+	}
+	req := core.ListVolumesRequest{CompartmentId: comp.Id}
+	var out []core.Volume
+	for {
+		res, err := bs.ListVolumes(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("list volumes: %w", err)
+		}
+		for _, v := range res.Items {
+			if v.LifecycleState == core.VolumeLifecycleStateTerminating || v.LifecycleState == core.VolumeLifecycleStateTerminated {
+				continue
+			}
+			out = append(out, v)
+		}
+		if res.OpcNextPage == nil {
+			return out, nil
+		}
+		req.Page = res.OpcNextPage
+	}
+}
+
+// listBackups lists the non-terminated volume backups of the compartment.
+func (vb *volumeBackendDisk) listBackups(ctx context.Context, comp *identity.Compartment) ([]core.VolumeBackup, error) {
+	bs, err := vb.driver.blockstorageClient()
+	if err != nil {
+		return nil, err
+	}
+	req := core.ListVolumeBackupsRequest{CompartmentId: comp.Id}
+	var out []core.VolumeBackup
+	for {
+		res, err := bs.ListVolumeBackups(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("list volume backups: %w", err)
+		}
+		for _, b := range res.Items {
+			if b.LifecycleState == core.VolumeBackupLifecycleStateTerminating || b.LifecycleState == core.VolumeBackupLifecycleStateTerminated {
+				continue
+			}
+			out = append(out, b)
+		}
+		if res.OpcNextPage == nil {
+			return out, nil
+		}
+		req.Page = res.OpcNextPage
+	}
+}
+
+// findVolume returns the block volume of the Kompox disk or nil when not found.
+func (vb *volumeBackendDisk) findVolume(ctx context.Context, app *model.App, volName, diskName string) (*core.Volume, error) {
+	comp, err := vb.appCompartment(ctx, app)
+	if err != nil || comp == nil {
+		return nil, err
+	}
+	volumes, err := vb.listVolumes(ctx, comp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range volumes {
+		if volumes[i].FreeformTags[tagVolumeName] == volName && volumes[i].FreeformTags[tagDiskName] == diskName {
+			return &volumes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// findBackup returns the volume backup of the Kompox snapshot or nil when not found.
+func (vb *volumeBackendDisk) findBackup(ctx context.Context, comp *identity.Compartment, volName, snapName string) (*core.VolumeBackup, error) {
+	backups, err := vb.listBackups(ctx, comp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range backups {
+		if backups[i].FreeformTags[tagVolumeName] == volName && backups[i].FreeformTags[tagSnapshotName] == snapName {
+			return &backups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// waitVolumeAvailable polls the block volume until it becomes AVAILABLE.
+func (vb *volumeBackendDisk) waitVolumeAvailable(ctx context.Context, bs core.BlockstorageClient, id *string) (*core.Volume, error) {
+	var volume *core.Volume
+	err := waitFor(ctx, "volume "+*id, func(ctx context.Context) (bool, error) {
+		got, err := bs.GetVolume(ctx, core.GetVolumeRequest{VolumeId: id})
+		if err != nil {
+			return false, err
+		}
+		volume = &got.Volume
+		if volume.LifecycleState == core.VolumeLifecycleStateFaulty {
+			return false, fmt.Errorf("volume %s is FAULTY", *id)
+		}
+		return volume.LifecycleState == core.VolumeLifecycleStateAvailable, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// resolveSource resolves a source string to block volume source details.
+// - "snapshot:name" -> Kompox managed snapshot (volume backup)
+// - "disk:name" -> Kompox managed disk (volume clone)
+// - "ocid1.volumebackup..." -> volume backup OCID
+// - "ocid1.volume..." -> block volume OCID
+// - Others -> name with the default kind ("snapshot" or "disk")
+func (vb *volumeBackendDisk) resolveSource(ctx context.Context, comp *identity.Compartment, app *model.App, volName, source, defaultKind string) (core.VolumeSourceDetails, error) {
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lower, "ocid1.volumebackup."):
+		return core.VolumeSourceFromVolumeBackupDetails{Id: common.String(source)}, nil
+	case strings.HasPrefix(lower, "ocid1.volume."):
+		return core.VolumeSourceFromVolumeDetails{Id: common.String(source)}, nil
+	}
+
+	kind, name := defaultKind, source
+	if k, n, ok := strings.Cut(source, ":"); ok {
+		kind, name = strings.ToLower(k), strings.TrimSpace(n)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("source name is empty")
+	}
+	if comp == nil {
+		return nil, fmt.Errorf("%s %q not found", kind, name)
+	}
+	switch kind {
+	case "snapshot":
+		backup, err := vb.findBackup(ctx, comp, volName, name)
+		if err != nil {
+			return nil, err
+		}
+		if backup == nil {
+			return nil, fmt.Errorf("snapshot %q not found", name)
+		}
+		return core.VolumeSourceFromVolumeBackupDetails{Id: backup.Id}, nil
+	case "disk":
+		volume, err := vb.findVolume(ctx, app, volName, name)
+		if err != nil {
+			return nil, err
+		}
+		if volume == nil {
+			return nil, fmt.Errorf("disk %q not found", name)
+		}
+		return core.VolumeSourceFromVolumeDetails{Id: volume.Id}, nil
+	default:
+		return nil, fmt.Errorf("unsupported source kind %q", kind)
+	}
+}
+
+// newDisk creates a model.VolumeDisk from an OCI block volume.
+// Returns nil when the volume is not a Kompox disk of the logical volume.
+func (vb *volumeBackendDisk) newDisk(v *core.Volume, volName string) *model.VolumeDisk {
+	if v == nil || v.Id == nil {
+		return nil
+	}
+	tags := v.FreeformTags
+	if tags[tagVolumeName] != volName || tags[tagDiskName] == "" {
+		return nil
+	}
+
+	var size int64
+	if v.SizeInGBs != nil {
+		size = *v.SizeInGBs << 30
+	}
+	var created time.Time
+	if v.TimeCreated != nil {
+		created = v.TimeCreated.Time
+	}
+	zone := ""
+	if v.AvailabilityDomain != nil {
+		zone = normalizeADToKompox(*v.AvailabilityDomain)
+	}
+	options := map[string]any{}
+	if v.VpusPerGB != nil {
+		options[diskOptionVpusPerGB] = *v.VpusPerGB
+	}
+	sourceHandle := ""
+	switch src := v.SourceDetails.(type) {
+	case core.VolumeSourceFromVolumeBackupDetails:
+		sourceHandle = *src.Id
+	case core.VolumeSourceFromVolumeDetails:
+		sourceHandle = *src.Id
+	}
+	labels, description := userMetadataFromTags(tags)
+
+	return &model.VolumeDisk{
+		Name:         tags[tagDiskName],
+		VolumeName:   volName,
+		Assigned:     strings.EqualFold(tags[tagDiskAssigned], "true"),
+		Size:         size,
+		Zone:         zone,
+		Options:      options,
+		Handle:       *v.Id,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// newSnapshot creates a model.VolumeSnapshot from an OCI volume backup.
+// Returns nil when the backup is not a Kompox snapshot of the logical volume.
+func (vb *volumeBackendDisk) newSnapshot(b *core.VolumeBackup, volName string) *model.VolumeSnapshot {
+	if b == nil || b.Id == nil {
+		return nil
+	}
+	tags := b.FreeformTags
+	if tags[tagVolumeName] != volName || tags[tagSnapshotName] == "" {
+		return nil
+	}
+
+	var size int64
+	if b.SizeInGBs != nil {
+		size = *b.SizeInGBs << 30
+	}
+	var created time.Time
+	if b.TimeCreated != nil {
+		created = b.TimeCreated.Time
+	}
+	sourceHandle := ""
+	if b.VolumeId != nil {
+		sourceHandle = *b.VolumeId
+	}
+	labels, description := userMetadataFromTags(tags)
+
+	return &model.VolumeSnapshot{
+		Name:         tags[tagSnapshotName],
+		VolumeName:   volName,
+		Size:         size,
+		Handle:       *b.Id,
+		Labels:       labels,
+		Description:  description,
+		SourceHandle: sourceHandle,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+// parseVpusPerGB reads the vpusPerGB option. Returns nil when the option is not set.
+func parseVpusPerGB(options map[string]any) (*int64, error) {
+	raw, ok := options[diskOptionVpusPerGB]
+	if !ok {
+		return nil, nil
+	}
+	var n int64
+	switch v := raw.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, diskOptionsError("%s must be an integer", diskOptionVpusPerGB)
+		}
+		n = int64(v)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, diskOptionsError("%s must be an integer", diskOptionVpusPerGB)
+		}
+		n = i
+	default:
+		return nil, diskOptionsError("%s must be an integer", diskOptionVpusPerGB)
+	}
+	if n < 0 || n > 120 || n%10 != 0 {
+		return nil, diskOptionsError("%s must be a multiple of 10 between 0 and 120", diskOptionVpusPerGB)
+	}
+	return &n, nil
+}
+
+// diskOptionsError wraps model.ErrVolumeOptionsInvalid with a message.
+func diskOptionsError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", model.ErrVolumeOptionsInvalid, fmt.Sprintf(format, args...))
+}
+
+// setUserMetadataTags stores user labels and description as freeform tags.
+func setUserMetadataTags(tags map[string]string, labels map[string]string, description string) {
+	for k, v := range labels {
+		tags[tagLabelPrefix+k] = v
+	}
+	if description != "" {
+		tags[tagDescription] = description
+	}
+}
+
+// userMetadataFromTags extracts user labels and description from freeform tags.
+// Returns nil labels when none are set.
+func userMetadataFromTags(tags map[string]string) (map[string]string, string) {
+	var labels map[string]string
+	for k, v := range tags {
+		if key, ok := strings.CutPrefix(k, tagLabelPrefix); ok && key != "" {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[key] = v
+		}
+	}
+	return labels, tags[tagDescription]
+}
@@ -0,0 +1,326 @@
+package oke
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// fakeOCI is a minimal in-memory OCI API (identity compartments/ADs and block volumes/backups).
+type fakeOCI struct {
+	mu           sync.Mutex
+	seq          int
+	compartments map[string]map[string]any
+	volumes      map[string]map[string]any
+	backups      map[string]map[string]any
+}
+
+func newFakeOCI() *fakeOCI {
+	return &fakeOCI{
+		compartments: map[string]map[string]any{},
+		volumes:      map[string]map[string]any{},
+		backups:      map[string]map[string]any{},
+	}
+}
+
+func (f *fakeOCI) nextID(kind string) string {
+	f.seq++
+	return fmt.Sprintf("ocid1.%s.oc1..%04d", kind, f.seq)
+}
+
+func (f *fakeOCI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]any
+	if r.Body != nil && (r.Method == http.MethodPost || r.Method == http.MethodPut) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	reply := func(v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		reply(map[string]any{"code": "NotAuthorizedOrNotFound", "message": "not found"})
+	}
+	list := func(items map[string]map[string]any, match func(map[string]any) bool) {
+		out := []map[string]any{}
+		for _, it := range items {
+			if match(it) {
+				out = append(out, it)
+			}
+		}
+		reply(out)
+	}
+	q := r.URL.Query()
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 {
+		notFound()
+		return
+	}
+	res, id := parts[1], ""
+	if len(parts) > 2 {
+		id = parts[2]
+	}
+
+	switch res {
+	case "availabilityDomains":
+		reply([]map[string]any{{"name": "Uocm:PHX-AD-1"}, {"name": "Uocm:PHX-AD-2"}})
+	case "compartments":
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			list(f.compartments, func(c map[string]any) bool {
+				return c["compartmentId"] == q.Get("compartmentId") && (q.Get("name") == "" || c["name"] == q.Get("name"))
+			})
+		case r.Method == http.MethodPost:
+			body["id"] = f.nextID("compartment")
+			body["lifecycleState"] = "ACTIVE"
+			body["timeCreated"] = now
+			f.compartments[body["id"].(string)] = body
+			reply(body)
+		case r.Method == http.MethodGet:
+			if c, ok := f.compartments[id]; ok {
+				reply(c)
+				return
+			}
+			notFound()
+		case r.Method == http.MethodPut:
+			c, ok := f.compartments[id]
+			if !ok {
+				notFound()
+				return
+			}
+			for k, val := range body {
+				c[k] = val
+			}
+			reply(c)
+		case r.Method == http.MethodDelete:
+			if _, ok := f.compartments[id]; !ok {
+				notFound()
+				return
+			}
+			delete(f.compartments, id)
+			w.Header().Set("opc-work-request-id", f.nextID("workrequest"))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	case "volumes":
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			list(f.volumes, func(v map[string]any) bool { return v["compartmentId"] == q.Get("compartmentId") })
+		case r.Method == http.MethodPost:
+			body["id"] = f.nextID("volume")
+			body["lifecycleState"] = "AVAILABLE"
+			body["timeCreated"] = now
+			f.volumes[body["id"].(string)] = body
+			reply(body)
+		case r.Method == http.MethodGet:
+			if v, ok := f.volumes[id]; ok {
+				reply(v)
+				return
+			}
+			notFound()
+		case r.Method == http.MethodPut:
+			v, ok := f.volumes[id]
+			if !ok {
+				notFound()
+				return
+			}
+			for k, val := range body {
+				v[k] = val
+			}
+			reply(v)
+		case r.Method == http.MethodDelete:
+			if _, ok := f.volumes[id]; !ok {
+				notFound()
+				return
+			}
+			delete(f.volumes, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case "volumeBackups":
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			list(f.backups, func(b map[string]any) bool { return b["compartmentId"] == q.Get("compartmentId") })
+		case r.Method == http.MethodPost:
+			vol, ok := f.volumes[fmt.Sprint(body["volumeId"])]
+			if !ok {
+				notFound()
+				return
+			}
+			body["id"] = f.nextID("volumebackup")
+			body["compartmentId"] = vol["compartmentId"]
+			body["sizeInGBs"] = vol["sizeInGBs"]
+			body["lifecycleState"] = "AVAILABLE"
+			body["timeCreated"] = now
+			f.backups[body["id"].(string)] = body
+			reply(body)
+		case r.Method == http.MethodGet:
+			if b, ok := f.backups[id]; ok {
+				reply(b)
+				return
+			}
+			notFound()
+		case r.Method == http.MethodDelete:
+			delete(f.backups, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		notFound()
+	}
+}
+
+// newTestDriver returns a driver whose OCI clients talk to the fake server.
+func newTestDriver(t *testing.T, f http.Handler) *driver {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	old := pollInterval
+	pollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollInterval = old })
+
+	d := &driver{
+		workspaceName:   "ws",
+		providerName:    "prv",
+		resourcePrefix:  "k4x-test",
+		configProvider:  common.NewRawConfigurationProvider("ocid1.tenancy.oc1..t", "ocid1.user.oc1..u", "us-phoenix-1", "aa:bb", string(pemKey), nil),
+		tenancyOCID:     "ocid1.tenancy.oc1..t",
+		compartmentOCID: "ocid1.compartment.oc1..parent",
+		region:          "us-phoenix-1",
+		endpoint:        srv.URL,
+	}
+	d.volumeBackends = map[string]volumeBackend{model.VolumeTypeDisk: newVolumeBackendDisk(d)}
+	return d
+}
+
+func TestVolumeDiskFlow(t *testing.T) {
+	f := newFakeOCI()
+	d := newTestDriver(t, f)
+	ctx := context.Background()
+
+	cluster := &model.Cluster{Name: "cls"}
+	app := &model.App{
+		Name:       "app",
+		Volumes:    []model.AppVolume{{Name: "db", Size: 10 << 30, Options: map[string]any{"vpusPerGB": 20}}},
+		Deployment: model.AppDeployment{Zone: "AD-2"},
+	}
+
+	disks, err := d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil || len(disks) != 0 {
+		t.Fatalf("initial list: %v %v", disks, err)
+	}
+
+	disk1, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", "", model.WithVolumeDiskCreateLabels(map[string]string{"env": "test"}))
+	if err != nil {
+		t.Fatalf("create disk1: %v", err)
+	}
+	if disk1.Zone != "AD-2" || disk1.Size != minBlockVolumeSizeGB<<30 || disk1.Options[diskOptionVpusPerGB] != int64(20) {
+		t.Errorf("unexpected disk1: %+v", disk1)
+	}
+	if disk1.Labels["env"] != "test" {
+		t.Errorf("labels = %v", disk1.Labels)
+	}
+	if _, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk1", ""); err == nil {
+		t.Error("expected duplicate disk error")
+	}
+
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+
+	snap, err := d.VolumeSnapshotCreate(ctx, cluster, app, "db", "snap1", "")
+	if err != nil {
+		t.Fatalf("snapshot create: %v", err)
+	}
+	if snap.SourceHandle != disk1.Handle {
+		t.Errorf("snapshot source = %q, want %q", snap.SourceHandle, disk1.Handle)
+	}
+
+	disk2, err := d.VolumeDiskCreate(ctx, cluster, app, "db", "disk2", "snap1")
+	if err != nil {
+		t.Fatalf("create disk2 from snapshot: %v", err)
+	}
+	if disk2.SourceHandle != snap.Handle {
+		t.Errorf("disk2 source = %q, want %q", disk2.SourceHandle, snap.Handle)
+	}
+
+	if err := d.VolumeDiskAssign(ctx, cluster, app, "db", "disk2"); err != nil {
+		t.Fatalf("assign disk2: %v", err)
+	}
+	disks, err = d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, disk := range disks {
+		if disk.Assigned != (disk.Name == "disk2") {
+			t.Errorf("disk %s assigned = %v", disk.Name, disk.Assigned)
+		}
+	}
+
+	updated, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"vpusPerGB": 30}))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Options[diskOptionVpusPerGB] != int64(30) {
+		t.Errorf("vpusPerGB = %v", updated.Options[diskOptionVpusPerGB])
+	}
+	if _, err := d.VolumeDiskUpdate(ctx, cluster, app, "db", "disk2", model.WithVolumeDiskUpdateOptions(map[string]any{"size": 100})); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+
+	if err := d.VolumeSnapshotDelete(ctx, cluster, app, "db", "snap1"); err != nil {
+		t.Fatalf("snapshot delete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := d.VolumeDiskDelete(ctx, cluster, app, "db", "disk1"); err != nil {
+		t.Fatalf("delete missing: %v", err)
+	}
+	disks, err = d.VolumeDiskList(ctx, cluster, app, "db")
+	if err != nil || len(disks) != 1 || disks[0].Name != "disk2" {
+		t.Fatalf("final list: %v %v", disks, err)
+	}
+}
+
+func TestVolumeClass(t *testing.T) {
+	d := newTestDriver(t, newFakeOCI())
+	ctx := context.Background()
+
+	vc, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "db"})
+	if err != nil {
+		t.Fatalf("VolumeClass: %v", err)
+	}
+	if vc.StorageClassName != "oci-bv" || vc.CSIDriver != "blockvolume.csi.oraclecloud.com" {
+		t.Errorf("unexpected class: %+v", vc)
+	}
+	if _, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "db", Options: map[string]any{"vpusPerGB": 15}}); !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid, got %v", err)
+	}
+	if _, err := d.VolumeClass(ctx, &model.Cluster{}, &model.App{}, model.AppVolume{Name: "files", Type: model.VolumeTypeFiles}); err == nil {
+		t.Error("expected unsupported volume type error")
+	}
+}
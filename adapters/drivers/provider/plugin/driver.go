@@ -0,0 +1,286 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// capabilitiesTimeout bounds the Capabilities call, which has no caller context.
+const capabilitiesTimeout = 30 * time.Second
+
+// driver implements providerdrv.Driver by running a plugin executable per method call.
+type driver struct {
+	path      string
+	workspace *model.Workspace
+	provider  *model.Provider
+
+	capsOnce sync.Once
+	caps     model.DriverCapabilities
+}
+
+// ID returns the provider driver identifier, i.e. Provider.Driver.
+func (d *driver) ID() string { return d.provider.Driver }
+
+// WorkspaceName returns the workspace name associated with this driver instance.
+func (d *driver) WorkspaceName() string {
+	if d.workspace == nil {
+		return "(nil)"
+	}
+	return d.workspace.Name
+}
+
+// ProviderName returns the provider name associated with this driver instance.
+func (d *driver) ProviderName() string { return d.provider.Name }
+
+// Capabilities returns the capabilities reported by the plugin. The result is cached per
+// driver instance. If the plugin cannot be queried, nothing is reported as supported.
+func (d *driver) Capabilities() model.DriverCapabilities {
+	d.capsOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), capabilitiesTimeout)
+		defer cancel()
+		if err := d.call(ctx, MethodCapabilities, Params{}, &d.caps); err != nil {
+			logging.FromContext(ctx).Warn(ctx, "plugin capabilities unavailable", "plugin", d.path, "err", err)
+			d.caps = model.DriverCapabilities{}
+		}
+		d.caps.Driver = d.ID()
+	})
+	return d.caps
+}
+
+// The methods below forward each call to the plugin. See providerdrv.Driver for their contracts.
+
+func (d *driver) ClusterProvision(ctx context.Context, cluster *model.Cluster, opts ...model.ClusterProvisionOption) error {
+	return d.callWithOptions(ctx, MethodClusterProvision, Params{Cluster: cluster}, applyOptions(opts), nil)
+}
+
+func (d *driver) ClusterDeprovision(ctx context.Context, cluster *model.Cluster, opts ...model.ClusterDeprovisionOption) error {
+	return d.callWithOptions(ctx, MethodClusterDeprovision, Params{Cluster: cluster}, applyOptions(opts), nil)
+}
+
+func (d *driver) ClusterStatus(ctx context.Context, cluster *model.Cluster) (*model.ClusterStatus, error) {
+	var status model.ClusterStatus
+	if err := d.call(ctx, MethodClusterStatus, Params{Cluster: cluster}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (d *driver) ClusterInstall(ctx context.Context, cluster *model.Cluster, opts ...model.ClusterInstallOption) error {
+	return d.callWithOptions(ctx, MethodClusterInstall, Params{Cluster: cluster}, applyOptions(opts), nil)
+}
+
+func (d *driver) ClusterUninstall(ctx context.Context, cluster *model.Cluster, opts ...model.ClusterUninstallOption) error {
+	return d.callWithOptions(ctx, MethodClusterUninstall, Params{Cluster: cluster}, applyOptions(opts), nil)
+}
+
+func (d *driver) ClusterKubeconfig(ctx context.Context, cluster *model.Cluster) ([]byte, error) {
+	var kubeconfig []byte
+	if err := d.call(ctx, MethodClusterKubeconfig, Params{Cluster: cluster}, &kubeconfig); err != nil {
+		return nil, err
+	}
+	return kubeconfig, nil
+}
+
+func (d *driver) ClusterDNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	return d.callWithOptions(ctx, MethodClusterDNSApply, Params{Cluster: cluster, RecordSet: &rset}, applyOptions(opts), nil)
+}
+
+func (d *driver) VolumeDiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	var disks []*model.VolumeDisk
+	p := Params{Cluster: cluster, App: app, VolumeName: volName}
+	if err := d.callWithOptions(ctx, MethodVolumeDiskList, p, applyOptions(opts), &disks); err != nil {
+		return nil, err
+	}
+	return disks, nil
+}
+
+func (d *driver) VolumeDiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+	var disk model.VolumeDisk
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, DiskName: diskName, Source: source}
+	if err := d.callWithOptions(ctx, MethodVolumeDiskCreate, p, applyOptions(opts), &disk); err != nil {
+		return nil, err
+	}
+	return &disk, nil
+}
+
+func (d *driver) VolumeDiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) error {
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, DiskName: diskName}
+	return d.callWithOptions(ctx, MethodVolumeDiskDelete, p, applyOptions(opts), nil)
+}
+
+func (d *driver) VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, DiskName: diskName}
+	return d.callWithOptions(ctx, MethodVolumeDiskAssign, p, applyOptions(opts), nil)
+}
+
+func (d *driver) VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	var disk model.VolumeDisk
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, DiskName: diskName}
+	if err := d.callWithOptions(ctx, MethodVolumeDiskUpdate, p, applyOptions(opts), &disk); err != nil {
+		return nil, err
+	}
+	return &disk, nil
+}
+
+func (d *driver) VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	var snapshots []*model.VolumeSnapshot
+	p := Params{Cluster: cluster, App: app, VolumeName: volName}
+	if err := d.callWithOptions(ctx, MethodVolumeSnapshotList, p, applyOptions(opts), &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (d *driver) VolumeSnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	var snapshot model.VolumeSnapshot
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, SnapshotName: snapName, Source: source}
+	if err := d.callWithOptions(ctx, MethodVolumeSnapshotCreate, p, applyOptions(opts), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (d *driver) VolumeSnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
+	p := Params{Cluster: cluster, App: app, VolumeName: volName, SnapshotName: snapName}
+	return d.callWithOptions(ctx, MethodVolumeSnapshotDelete, p, applyOptions(opts), nil)
+}
+
+func (d *driver) VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	var vc model.VolumeClass
+	if err := d.call(ctx, MethodVolumeClass, Params{Cluster: cluster, App: app, Volume: &vol}, &vc); err != nil {
+		return model.VolumeClass{}, err
+	}
+	return vc, nil
+}
+
+func (d *driver) VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error) {
+	var resources []*model.VolumeResource
+	if err := d.call(ctx, MethodVolumeResourceList, Params{}, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (d *driver) VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error {
+	return d.call(ctx, MethodVolumeResourceMarkOrphaned, Params{Resource: res, At: &at}, nil)
+}
+
+func (d *driver) VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error {
+	return d.call(ctx, MethodVolumeResourceUnmarkOrphaned, Params{Resource: res}, nil)
+}
+
+func (d *driver) VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error {
+	return d.call(ctx, MethodVolumeResourceDelete, Params{Resource: res}, nil)
+}
+
+func (d *driver) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) ([]*model.NodePool, error) {
+	var pools []*model.NodePool
+	if err := d.callWithOptions(ctx, MethodNodePoolList, Params{Cluster: cluster}, applyOptions(opts), &pools); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+func (d *driver) NodePoolCreate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolCreateOption) (*model.NodePool, error) {
+	var created model.NodePool
+	if err := d.callWithOptions(ctx, MethodNodePoolCreate, Params{Cluster: cluster, NodePool: &pool}, applyOptions(opts), &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (d *driver) NodePoolUpdate(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolUpdateOption) (*model.NodePool, error) {
+	var updated model.NodePool
+	if err := d.callWithOptions(ctx, MethodNodePoolUpdate, Params{Cluster: cluster, NodePool: &pool}, applyOptions(opts), &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (d *driver) NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) error {
+	return d.callWithOptions(ctx, MethodNodePoolDelete, Params{Cluster: cluster, NodePoolName: poolName}, applyOptions(opts), nil)
+}
+
+// applyOptions applies functional options to a zero options struct so that it can be sent
+// to the plugin.
+func applyOptions[F ~func(*O), O any](opts []F) O {
+	var o O
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// callWithOptions encodes options into p.Options and calls the method.
+func (d *driver) callWithOptions(ctx context.Context, method string, p Params, options any, result any) error {
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("plugin %s: encode %s options: %w", d.ID(), method, err)
+	}
+	p.Options = raw
+	return d.call(ctx, method, p, result)
+}
+
+// call runs the plugin for one method and decodes the result into result (if non-nil).
+func (d *driver) call(ctx context.Context, method string, p Params, result any) (err error) {
+	ctx, cleanup := d.withMethodLogger(ctx, method)
+	defer func() { cleanup(err) }()
+
+	req, err := json.Marshal(Request{
+		Version:   ProtocolVersion,
+		Method:    method,
+		Workspace: d.workspace,
+		Provider:  d.provider,
+		Params:    p,
+	})
+	if err != nil {
+		return fmt.Errorf("plugin %s: encode %s request: %w", d.ID(), method, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.path)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	logger := logging.FromContext(ctx)
+	sc := bufio.NewScanner(&stderr)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			logger.Debug(ctx, "plugin stderr", "line", line)
+		}
+	}
+
+	var resp Response
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &resp); decodeErr != nil {
+		if runErr != nil {
+			return fmt.Errorf("plugin %s: %s: %w", d.ID(), method, runErr)
+		}
+		return fmt.Errorf("plugin %s: decode %s response: %w", d.ID(), method, decodeErr)
+	}
+	if resp.Error != nil {
+		return resp.Error.err()
+	}
+	if runErr != nil {
+		return fmt.Errorf("plugin %s: %s: %w", d.ID(), method, runErr)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("plugin %s: decode %s result: %w", d.ID(), method, err)
+		}
+	}
+	return nil
+}
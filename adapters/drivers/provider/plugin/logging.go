@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// withMethodLogger implements the Span pattern for plugin driver logging.
+// It emits a start log line and returns a context with logger attributes attached,
+// plus a cleanup function to emit the success or failure log line.
+//
+// Log message format:
+// - Start:   PLUGIN:<method>/S (with driver and plugin in logger attributes)
+// - Success: PLUGIN:<method>/EOK (with err, elapsed in logger attributes)
+// - Failure: PLUGIN:<method>/EFAIL (with err, elapsed in logger attributes)
+//
+// See design/v1/Kompox-Logging.ja.md for the full Span pattern specification.
+func (d *driver) withMethodLogger(ctx context.Context, method string) (context.Context, func(err error)) {
+	startAt := time.Now()
+
+	logger := logging.FromContext(ctx).With("driver", "PLUGIN."+method, "plugin", d.ID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	logger.Info(ctx, "PLUGIN:"+method+"/S")
+
+	cleanup := func(err error) {
+		elapsed := time.Since(startAt).Seconds()
+		msg := "PLUGIN:" + method + "/EOK"
+		errStr := ""
+		if err != nil {
+			msg = "PLUGIN:" + method + "/EFAIL"
+			errStr = err.Error()
+			if len(errStr) > 32 {
+				errStr = errStr[:32] + "..."
+			}
+		}
+		logger.Info(ctx, msg, "err", errStr, "elapsed", elapsed)
+	}
+
+	return ctx, cleanup
+}
@@ -0,0 +1,77 @@
+// Package plugin lets provider drivers live outside the kompoxops binary.
+//
+// A plugin is an executable named kompox-driver-<name>. For every driver method the host
+// starts the plugin, writes one JSON Request to its stdin and reads one JSON Response from
+// its stdout; stderr is forwarded to the host log. Plugins are looked up in
+// $KOMPOX_DIR/plugins first and then in PATH, and are used only when no built-in driver
+// is registered under Provider.Driver.
+//
+// Plugins written in Go implement providerdrv.Driver and call Serve from main:
+//
+//	func main() { plugin.Serve(newDriver) }
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/config/kompoxenv"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// BinaryPrefix is the executable name prefix of driver plugins.
+const BinaryPrefix = "kompox-driver-"
+
+// PluginsDir is the directory under KOMPOX_DIR searched before PATH.
+const PluginsDir = "plugins"
+
+// nameRe restricts driver names so that they cannot escape the plugin directory.
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+func init() {
+	providerdrv.RegisterFallback(func(name string) (func(*model.Workspace, *model.Provider) (providerdrv.Driver, error), bool) {
+		path, err := Lookup(name)
+		if err != nil {
+			return nil, false
+		}
+		return Factory(path), true
+	})
+}
+
+// Lookup returns the path of the plugin executable for the driver name.
+// $KOMPOX_DIR/plugins/kompox-driver-<name> takes precedence over PATH.
+func Lookup(name string) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid plugin driver name %q", name)
+	}
+	binary := BinaryPrefix + name
+	if dir := os.Getenv(kompoxenv.KompoxDirEnvKey); dir != "" {
+		path := filepath.Join(dir, PluginsDir, binary)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() && fi.Mode()&0o111 != 0 {
+			return path, nil
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("stat plugin %s: %w", path, err)
+		}
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s not found in %s/%s or PATH: %w", binary, "$"+kompoxenv.KompoxDirEnvKey, PluginsDir, err)
+	}
+	return path, nil
+}
+
+// Factory returns a driver factory that runs the plugin executable at path.
+// It can be passed to conformance.Fixture.Factory to test a plugin.
+func Factory(path string) func(*model.Workspace, *model.Provider) (providerdrv.Driver, error) {
+	return func(workspace *model.Workspace, provider *model.Provider) (providerdrv.Driver, error) {
+		if provider == nil {
+			return nil, fmt.Errorf("plugin %s: provider is required", path)
+		}
+		return &driver{path: path, workspace: workspace, provider: provider}, nil
+	}
+}
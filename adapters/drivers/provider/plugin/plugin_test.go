@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/drivers/provider/conformance"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/fake"
+	"github.com/kompox/kompox/config/kompoxenv"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// serveEnv makes the test binary act as a plugin serving the fake driver.
+const serveEnv = "KOMPOX_PLUGIN_TEST_SERVE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(serveEnv) == "1" {
+		factory, ok := providerdrv.GetDriverFactory("fake")
+		if !ok {
+			os.Exit(2)
+		}
+		Serve(factory)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeProvider returns a provider for the fake driver served by the test binary.
+// The state file is required because each call runs in a new process.
+func fakeProvider(t *testing.T, driver string) *model.Provider {
+	t.Helper()
+	return &model.Provider{Name: "prv", Driver: driver, Settings: map[string]string{
+		"FAKE_STATE_FILE": filepath.Join(t.TempDir(), "state.json"),
+		"FAKE_DNS_ZONES":  "example.com",
+		"FAKE_KUBECONFIG": "apiVersion: v1\nkind: Config\n",
+	}}
+}
+
+func TestConformance(t *testing.T) {
+	t.Setenv(serveEnv, "1")
+	workspace := &model.Workspace{Name: "ws"}
+	provider := fakeProvider(t, "fake-plugin")
+
+	report := conformance.Run(t, conformance.Fixture{
+		Factory:         Factory(os.Args[0]),
+		Workspace:       workspace,
+		Provider:        provider,
+		Cluster:         &model.Cluster{Name: "cls1"},
+		App:             &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db", Size: 1 << 30}}},
+		VolumeName:      "db",
+		NodePool:        &model.NodePool{Zones: &[]string{"1"}},
+		ImmutableChange: model.NodePool{InstanceType: ptr("fake-large")},
+		DNSRecord:       model.DNSRecordSet{FQDN: "www.example.com", Type: model.DNSRecordTypeA, RData: []string{"192.0.2.10"}},
+		LookupDNS: func(ctx context.Context, fqdn string, rtype model.DNSRecordType) ([]string, bool, error) {
+			// Read the fake state file directly (see Kompox-ProviderDriver-Fake §2).
+			var doc struct {
+				Providers map[string]struct {
+					DNSRecords map[string]struct {
+						RData []string `json:"rdata"`
+					} `json:"dnsRecords"`
+				} `json:"providers"`
+			}
+			b, err := os.ReadFile(provider.Settings["FAKE_STATE_FILE"])
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, false, nil
+			} else if err != nil {
+				return nil, false, err
+			}
+			if err := json.Unmarshal(b, &doc); err != nil {
+				return nil, false, err
+			}
+			r, ok := doc.Providers[workspace.Name+"/"+provider.Name].DNSRecords[fqdn+"/"+string(rtype)]
+			return slices.Clone(r.RData), ok, nil
+		},
+	})
+	report.Require(t,
+		conformance.CapClusterProvision,
+		conformance.CapVolumeDisk,
+		conformance.CapVolumeSnapshot,
+		conformance.CapVolumeInventory,
+		conformance.CapNodePoolList,
+		conformance.CapNodePoolMutate,
+		conformance.CapDNSApply,
+	)
+}
+
+func TestGetDriverFactoryResolvesPlugin(t *testing.T) {
+	t.Setenv(serveEnv, "1")
+	dir := t.TempDir()
+	t.Setenv(kompoxenv.KompoxDirEnvKey, dir)
+	t.Setenv("PATH", "")
+	if err := os.Mkdir(filepath.Join(dir, PluginsDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(os.Args[0], filepath.Join(dir, PluginsDir, BinaryPrefix+"onprem")); err != nil {
+		t.Fatal(err)
+	}
+
+	factory, ok := providerdrv.GetDriverFactory("onprem")
+	if !ok {
+		t.Fatal("plugin driver not resolved")
+	}
+	drv, err := factory(&model.Workspace{Name: "ws"}, fakeProvider(t, "onprem"))
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	if drv.ID() != "onprem" || drv.WorkspaceName() != "ws" || drv.ProviderName() != "prv" {
+		t.Errorf("unexpected identity: %s %s %s", drv.ID(), drv.WorkspaceName(), drv.ProviderName())
+	}
+	if caps := drv.Capabilities(); caps.Driver != "onprem" || !caps.Cluster.Provision || !caps.VolumeInventory {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+
+	ctx := context.Background()
+	cluster := &model.Cluster{Name: "cls1"}
+	if err := drv.ClusterProvision(ctx, cluster, func(o *model.ClusterProvisionOptions) { o.Force = true }); err != nil {
+		t.Fatalf("ClusterProvision: %v", err)
+	}
+	pools, err := drv.NodePoolList(ctx, cluster, func(o *model.NodePoolListOptions) { o.Name = "system" })
+	if err != nil {
+		t.Fatalf("NodePoolList: %v", err)
+	}
+	if len(pools) != 1 || pools[0].Name == nil || *pools[0].Name != "system" {
+		t.Errorf("options not forwarded: %+v", pools)
+	}
+	app := &model.App{Name: "app1", Volumes: []model.AppVolume{{Name: "db"}}}
+	_, err = drv.VolumeDiskUpdate(ctx, cluster, app, "db", "missing", model.WithVolumeDiskUpdateOptions(map[string]any{"size": 1}))
+	if !errors.Is(err, model.ErrVolumeOptionsInvalid) {
+		t.Errorf("expected ErrVolumeOptionsInvalid across the process boundary, got %v", err)
+	}
+
+	if _, ok := providerdrv.GetDriverFactory("missing"); ok {
+		t.Error("unknown driver must not resolve")
+	}
+	if factory, ok := providerdrv.GetDriverFactory("fake"); !ok || factory == nil {
+		t.Error("built-in driver must take precedence")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	pathDir := t.TempDir()
+	t.Setenv(kompoxenv.KompoxDirEnvKey, dir)
+	t.Setenv("PATH", pathDir)
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(pathDir, BinaryPrefix+"x"))
+	if got, err := Lookup("x"); err != nil || got != filepath.Join(pathDir, BinaryPrefix+"x") {
+		t.Errorf("Lookup from PATH = %q, %v", got, err)
+	}
+	write(filepath.Join(dir, PluginsDir, BinaryPrefix+"x"))
+	if got, err := Lookup("x"); err != nil || got != filepath.Join(dir, PluginsDir, BinaryPrefix+"x") {
+		t.Errorf("KOMPOX_DIR/plugins must take precedence, got %q, %v", got, err)
+	}
+	if _, err := Lookup("y"); err == nil {
+		t.Error("expected not found error")
+	}
+	for _, name := range []string{"", "../x", "a/b", "X"} {
+		if _, err := Lookup(name); err == nil {
+			t.Errorf("Lookup(%q) must reject the name", name)
+		}
+	}
+}
+
+func TestServeIOErrors(t *testing.T) {
+	factory, _ := providerdrv.GetDriverFactory("fake")
+	serve := func(req string) Response {
+		t.Helper()
+		var out bytes.Buffer
+		if err := ServeIO(context.Background(), factory, strings.NewReader(req), &out); err != nil {
+			t.Fatalf("ServeIO: %v", err)
+		}
+		var resp Response
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response %q: %v", out.String(), err)
+		}
+		return resp
+	}
+
+	resp := serve(`{"version":1,"method":"Teleport","provider":{"Name":"prv","Driver":"fake"}}`)
+	if resp.Error == nil || resp.Error.Code != CodeNotSupported || !errors.Is(resp.Error.err(), model.ErrNotSupported) {
+		t.Errorf("unknown method must be not_supported: %+v", resp.Error)
+	}
+	resp = serve(`{"version":2,"method":"Capabilities","provider":{"Name":"prv","Driver":"fake"}}`)
+	if resp.Error == nil || !strings.Contains(resp.Error.Message, "protocol version") {
+		t.Errorf("expected version error: %+v", resp.Error)
+	}
+	if err := ServeIO(context.Background(), factory, strings.NewReader("not json"), &bytes.Buffer{}); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
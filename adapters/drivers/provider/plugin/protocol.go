@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// ProtocolVersion is the version of the request/response envelope. Plugins must reject
+// requests with a version they do not understand.
+const ProtocolVersion = 1
+
+// Method names. Each mirrors the providerdrv.Driver method of the same name.
+// ID, WorkspaceName and ProviderName are answered by the host and never sent.
+const (
+	MethodCapabilities                 = "Capabilities"
+	MethodClusterProvision             = "ClusterProvision"
+	MethodClusterDeprovision           = "ClusterDeprovision"
+	MethodClusterStatus                = "ClusterStatus"
+	MethodClusterInstall               = "ClusterInstall"
+	MethodClusterUninstall             = "ClusterUninstall"
+	MethodClusterKubeconfig            = "ClusterKubeconfig"
+	MethodClusterDNSApply              = "ClusterDNSApply"
+	MethodVolumeDiskList               = "VolumeDiskList"
+	MethodVolumeDiskCreate             = "VolumeDiskCreate"
+	MethodVolumeDiskDelete             = "VolumeDiskDelete"
+	MethodVolumeDiskAssign             = "VolumeDiskAssign"
+	MethodVolumeDiskUpdate             = "VolumeDiskUpdate"
+	MethodVolumeSnapshotList           = "VolumeSnapshotList"
+	MethodVolumeSnapshotCreate         = "VolumeSnapshotCreate"
+	MethodVolumeSnapshotDelete         = "VolumeSnapshotDelete"
+	MethodVolumeClass                  = "VolumeClass"
+	MethodVolumeResourceList           = "VolumeResourceList"
+	MethodVolumeResourceMarkOrphaned   = "VolumeResourceMarkOrphaned"
+	MethodVolumeResourceUnmarkOrphaned = "VolumeResourceUnmarkOrphaned"
+	MethodVolumeResourceDelete         = "VolumeResourceDelete"
+	MethodNodePoolList                 = "NodePoolList"
+	MethodNodePoolCreate               = "NodePoolCreate"
+	MethodNodePoolUpdate               = "NodePoolUpdate"
+	MethodNodePoolDelete               = "NodePoolDelete"
+)
+
+// Error codes carried by Error.Code. They map to the sentinel errors of the domain model
+// so that errors.Is keeps working across the process boundary.
+const (
+	CodeNotSupported         = "not_supported"          // model.ErrNotSupported
+	CodeVolumeOptionsInvalid = "volume_options_invalid" // model.ErrVolumeOptionsInvalid
+)
+
+// Request is written by the host to the plugin's stdin. One process serves one request.
+type Request struct {
+	Version   int              `json:"version"`
+	Method    string           `json:"method"`
+	Workspace *model.Workspace `json:"workspace,omitempty"`
+	Provider  *model.Provider  `json:"provider"`
+	Params    Params           `json:"params"`
+}
+
+// Params holds the arguments of a method. Only the fields used by the method are set.
+// Options is the JSON encoding of the method's options struct (e.g., model.VolumeDiskCreateOptions)
+// after applying the functional options on the host.
+type Params struct {
+	Cluster      *model.Cluster        `json:"cluster,omitempty"`
+	App          *model.App            `json:"app,omitempty"`
+	VolumeName   string                `json:"volumeName,omitempty"`
+	DiskName     string                `json:"diskName,omitempty"`
+	SnapshotName string                `json:"snapshotName,omitempty"`
+	Source       string                `json:"source,omitempty"`
+	Volume       *model.AppVolume      `json:"volume,omitempty"`
+	RecordSet    *model.DNSRecordSet   `json:"recordSet,omitempty"`
+	NodePool     *model.NodePool       `json:"nodePool,omitempty"`
+	NodePoolName string                `json:"nodePoolName,omitempty"`
+	Resource     *model.VolumeResource `json:"resource,omitempty"`
+	At           *time.Time            `json:"at,omitempty"`
+	Options      json.RawMessage       `json:"options,omitempty"`
+}
+
+// Response is written by the plugin to stdout. Exactly one of Result and Error is set;
+// Result is omitted for methods without a return value.
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error describes a failed method call.
+type Error struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// newError converts a driver error to its wire form.
+func newError(err error) *Error {
+	e := &Error{Message: err.Error()}
+	switch {
+	case errors.Is(err, model.ErrNotSupported):
+		e.Code = CodeNotSupported
+	case errors.Is(err, model.ErrVolumeOptionsInvalid):
+		e.Code = CodeVolumeOptionsInvalid
+	}
+	return e
+}
+
+// err converts the wire form back to an error wrapping the matching sentinel.
+func (e *Error) err() error {
+	var sentinel error
+	switch e.Code {
+	case CodeNotSupported:
+		sentinel = model.ErrNotSupported
+	case CodeVolumeOptionsInvalid:
+		sentinel = model.ErrVolumeOptionsInvalid
+	}
+	return &remoteError{message: e.Message, sentinel: sentinel}
+}
+
+// remoteError is an error returned by a plugin. The message is kept verbatim; the sentinel,
+// if any, is exposed through Unwrap.
+type remoteError struct {
+	message  string
+	sentinel error
+}
+
+func (e *remoteError) Error() string { return e.message }
+func (e *remoteError) Unwrap() error { return e.sentinel }
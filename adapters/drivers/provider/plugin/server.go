@@ -0,0 +1,223 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// Serve implements the plugin side of the protocol. It reads one request from stdin,
+// creates a driver with factory, dispatches the method and writes the response to stdout.
+// Driver errors are reported in the response; Serve exits with status 1 only when the
+// request cannot be read or the response cannot be written.
+func Serve(factory func(*model.Workspace, *model.Provider) (providerdrv.Driver, error)) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := ServeIO(ctx, factory, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// ServeIO is like Serve but reads the request from r and writes the response to w.
+func ServeIO(ctx context.Context, factory func(*model.Workspace, *model.Provider) (providerdrv.Driver, error), r io.Reader, w io.Writer) error {
+	var req Request
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("decode request: %w", err)
+	}
+
+	var resp Response
+	result, err := handle(ctx, factory, &req)
+	if err != nil {
+		resp.Error = newError(err)
+	} else if result != nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = newError(fmt.Errorf("encode %s result: %w", req.Method, err))
+		} else {
+			resp.Result = raw
+		}
+	}
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	return nil
+}
+
+// handle validates the request and dispatches it to a new driver instance.
+func handle(ctx context.Context, factory func(*model.Workspace, *model.Provider) (providerdrv.Driver, error), req *Request) (any, error) {
+	if req.Version != ProtocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d (want %d)", req.Version, ProtocolVersion)
+	}
+	if req.Provider == nil {
+		return nil, fmt.Errorf("provider is required")
+	}
+	drv, err := factory(req.Workspace, req.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver: %w", err)
+	}
+	return dispatch(ctx, drv, req.Method, &req.Params)
+}
+
+// dispatch calls the driver method named by method. Methods without a return value
+// return a nil result.
+func dispatch(ctx context.Context, drv providerdrv.Driver, method string, p *Params) (any, error) {
+	switch method {
+	case MethodCapabilities:
+		return drv.Capabilities(), nil
+	case MethodClusterProvision:
+		opts, err := decodeOptions[model.ClusterProvisionOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.ClusterProvision(ctx, p.Cluster, opts...)
+	case MethodClusterDeprovision:
+		opts, err := decodeOptions[model.ClusterDeprovisionOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.ClusterDeprovision(ctx, p.Cluster, opts...)
+	case MethodClusterStatus:
+		return drv.ClusterStatus(ctx, p.Cluster)
+	case MethodClusterInstall:
+		opts, err := decodeOptions[model.ClusterInstallOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.ClusterInstall(ctx, p.Cluster, opts...)
+	case MethodClusterUninstall:
+		opts, err := decodeOptions[model.ClusterUninstallOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.ClusterUninstall(ctx, p.Cluster, opts...)
+	case MethodClusterKubeconfig:
+		return drv.ClusterKubeconfig(ctx, p.Cluster)
+	case MethodClusterDNSApply:
+		if p.RecordSet == nil {
+			return nil, fmt.Errorf("%s: recordSet is required", method)
+		}
+		opts, err := decodeOptions[model.ClusterDNSApplyOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.ClusterDNSApply(ctx, p.Cluster, *p.RecordSet, opts...)
+	case MethodVolumeDiskList:
+		opts, err := decodeOptions[model.VolumeDiskListOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.VolumeDiskList(ctx, p.Cluster, p.App, p.VolumeName, opts...)
+	case MethodVolumeDiskCreate:
+		opts, err := decodeOptions[model.VolumeDiskCreateOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.VolumeDiskCreate(ctx, p.Cluster, p.App, p.VolumeName, p.DiskName, p.Source, opts...)
+	case MethodVolumeDiskDelete:
+		opts, err := decodeOptions[model.VolumeDiskDeleteOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.VolumeDiskDelete(ctx, p.Cluster, p.App, p.VolumeName, p.DiskName, opts...)
+	case MethodVolumeDiskAssign:
+		opts, err := decodeOptions[model.VolumeDiskAssignOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.VolumeDiskAssign(ctx, p.Cluster, p.App, p.VolumeName, p.DiskName, opts...)
+	case MethodVolumeDiskUpdate:
+		opts, err := decodeOptions[model.VolumeDiskUpdateOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.VolumeDiskUpdate(ctx, p.Cluster, p.App, p.VolumeName, p.DiskName, opts...)
+	case MethodVolumeSnapshotList:
+		opts, err := decodeOptions[model.VolumeSnapshotListOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.VolumeSnapshotList(ctx, p.Cluster, p.App, p.VolumeName, opts...)
+	case MethodVolumeSnapshotCreate:
+		opts, err := decodeOptions[model.VolumeSnapshotCreateOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.VolumeSnapshotCreate(ctx, p.Cluster, p.App, p.VolumeName, p.SnapshotName, p.Source, opts...)
+	case MethodVolumeSnapshotDelete:
+		opts, err := decodeOptions[model.VolumeSnapshotDeleteOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.VolumeSnapshotDelete(ctx, p.Cluster, p.App, p.VolumeName, p.SnapshotName, opts...)
+	case MethodVolumeClass:
+		if p.Volume == nil {
+			return nil, fmt.Errorf("%s: volume is required", method)
+		}
+		return drv.VolumeClass(ctx, p.Cluster, p.App, *p.Volume)
+	case MethodVolumeResourceList:
+		return drv.VolumeResourceList(ctx)
+	case MethodVolumeResourceMarkOrphaned:
+		if p.At == nil {
+			return nil, fmt.Errorf("%s: at is required", method)
+		}
+		return nil, drv.VolumeResourceMarkOrphaned(ctx, p.Resource, *p.At)
+	case MethodVolumeResourceUnmarkOrphaned:
+		return nil, drv.VolumeResourceUnmarkOrphaned(ctx, p.Resource)
+	case MethodVolumeResourceDelete:
+		return nil, drv.VolumeResourceDelete(ctx, p.Resource)
+	case MethodNodePoolList:
+		opts, err := decodeOptions[model.NodePoolListOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.NodePoolList(ctx, p.Cluster, opts...)
+	case MethodNodePoolCreate:
+		if p.NodePool == nil {
+			return nil, fmt.Errorf("%s: nodePool is required", method)
+		}
+		opts, err := decodeOptions[model.NodePoolCreateOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.NodePoolCreate(ctx, p.Cluster, *p.NodePool, opts...)
+	case MethodNodePoolUpdate:
+		if p.NodePool == nil {
+			return nil, fmt.Errorf("%s: nodePool is required", method)
+		}
+		opts, err := decodeOptions[model.NodePoolUpdateOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return drv.NodePoolUpdate(ctx, p.Cluster, *p.NodePool, opts...)
+	case MethodNodePoolDelete:
+		opts, err := decodeOptions[model.NodePoolDeleteOption](p)
+		if err != nil {
+			return nil, err
+		}
+		return nil, drv.NodePoolDelete(ctx, p.Cluster, p.NodePoolName, opts...)
+	default:
+		return nil, fmt.Errorf("unknown method %q: %w", method, model.ErrNotSupported)
+	}
+}
+
+// decodeOptions decodes p.Options into the options struct and returns a functional option
+// that sets it, so that the driver sees the same options as on the host.
+func decodeOptions[F ~func(*O), O any](p *Params) ([]F, error) {
+	if len(p.Options) == 0 {
+		return nil, nil
+	}
+	var o O
+	if err := json.Unmarshal(p.Options, &o); err != nil {
+		return nil, fmt.Errorf("decode options: %w", err)
+	}
+	return []F{func(dst *O) { *dst = o }}, nil
+}
@@ -2,6 +2,7 @@ package providerdrv
 
 import (
 	"context"
+	"time"
 
 	"github.com/kompox/kompox/domain/model"
 )
@@ -20,6 +21,10 @@ type Driver interface {
 	// ProviderName returns the provider name associated with this driver instance.
 	ProviderName() string
 
+	// Capabilities returns the operations supported by this driver instance.
+	// Usecases check it before acting so that unsupported operations fail early.
+	Capabilities() model.DriverCapabilities
+
 	// ClusterProvision provisions a Kubernetes cluster according to the cluster specification.
 	ClusterProvision(ctx context.Context, cluster *model.Cluster, opts ...model.ClusterProvisionOption) error
 
@@ -58,6 +63,10 @@ type Driver interface {
 	// VolumeDiskAssign assigns a disk to the specified logical volume.
 	VolumeDiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error
 
+	// VolumeDiskUpdate changes provider-specific options of an existing disk in place.
+	// Options that cannot be changed online must be rejected with model.ErrVolumeOptionsInvalid.
+	VolumeDiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error)
+
 	// VolumeSnapshotList returns a list of snapshots of the specified volume.
 	VolumeSnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error)
 
@@ -73,6 +82,21 @@ type Driver interface {
 	// substituting provider-specific defaults. This keeps kube layer free from provider assumptions.
 	VolumeClass(ctx context.Context, cluster *model.Cluster, app *model.App, vol model.AppVolume) (model.VolumeClass, error)
 
+	// VolumeResourceList lists all Kompox-managed app volume resources of this provider regardless
+	// of whether the owning app still exists. Drivers that cannot enumerate return model.ErrNotSupported.
+	VolumeResourceList(ctx context.Context) ([]*model.VolumeResource, error)
+
+	// VolumeResourceMarkOrphaned records on the resource the time it was first found orphaned
+	// so that the grace period survives across runs.
+	VolumeResourceMarkOrphaned(ctx context.Context, res *model.VolumeResource, at time.Time) error
+
+	// VolumeResourceUnmarkOrphaned removes the orphaned mark from a resource that is owned by
+	// an app again, so that a later orphaning starts a new grace period.
+	VolumeResourceUnmarkOrphaned(ctx context.Context, res *model.VolumeResource) error
+
+	// VolumeResourceDelete deletes a resource returned by VolumeResourceList. NotFound is acceptable for idempotency.
+	VolumeResourceDelete(ctx context.Context, res *model.VolumeResource) error
+
 	// NodePoolList returns a list of node pools for the specified cluster.
 	// Implementations should return pools with associated metadata (name, zones, instance type, autoscaling, etc.).
 	// Supports filtering via opts (e.g., by pool name).
@@ -93,6 +117,47 @@ type Driver interface {
 	NodePoolDelete(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) error
 }
 
+// ClusterPlanner is an optional interface of drivers that can plan cluster lifecycle operations.
+// ClusterPlan reports the cloud and in-cluster changes the operation would make and must not
+// mutate anything. Drivers not implementing it report Cluster.Plan=false in their capabilities.
+type ClusterPlanner interface {
+	ClusterPlan(ctx context.Context, cluster *model.Cluster, op model.ClusterPlanOperation, opts ...model.ClusterPlanOption) (*model.ClusterPlan, error)
+}
+
+// ClusterUpgrader is an optional interface of drivers that can upgrade Kubernetes versions and
+// node images. ClusterUpgrade upgrades the control plane only; node pools are upgraded one at a
+// time by NodePoolUpgrade so that callers can move workloads in between. Drivers not
+// implementing it report Cluster.Upgrade=false in their capabilities.
+type ClusterUpgrader interface {
+	ClusterVersions(ctx context.Context, cluster *model.Cluster) (*model.ClusterVersions, error)
+	ClusterUpgrade(ctx context.Context, cluster *model.Cluster, version string) error
+	NodePoolUpgrade(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolUpgradeOption) error
+}
+
+// VolumeSnapshotCopier is an optional interface of drivers that can copy a snapshot taken by
+// another provider instance (typically in another region) into an app volume. source is the
+// snapshot as reported by the other driver; its Handle identifies the cloud resource. Drivers
+// implementing it report Copy=true for the supported volume types in their capabilities.
+type VolumeSnapshotCopier interface {
+	VolumeSnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error)
+}
+
+// AppIdentityManager is an optional interface of drivers that manage app workload identities.
+// AppIdentityApply creates or updates the cloud identity of the app, federates it with the
+// ServiceAccount given by subject and grants the roles of app.Identity. The returned identity
+// carries the ServiceAccount annotations and pod labels the workload needs. Drivers
+// implementing it report Identity=true in their capabilities.
+type AppIdentityManager interface {
+	AppIdentityApply(ctx context.Context, cluster *model.Cluster, app *model.App, subject model.WorkloadIdentitySubject) (*model.WorkloadIdentity, error)
+}
+
+// SecretReader is an optional interface of drivers that resolve provider-specific secret
+// locators (e.g., Key Vault secret URLs on AKS) with the driver credential. Local files
+// ("file:<path>") are read by the SecretPort adapter and never reach the driver.
+type SecretReader interface {
+	SecretRead(ctx context.Context, cluster *model.Cluster, source string) ([]byte, error)
+}
+
 // driverFactory is a constructor function for a provider driver.
 type driverFactory func(workspace *model.Workspace, provider *model.Provider) (Driver, error)
 
@@ -105,8 +170,25 @@ func Register(name string, factory driverFactory) {
 	registry[name] = factory
 }
 
+// fallback resolves names without a registered driver (see RegisterFallback).
+var fallback func(name string) (func(*model.Workspace, *model.Provider) (Driver, error), bool)
+
+// RegisterFallback sets the resolver consulted by GetDriverFactory for names that are not
+// registered, e.g. out-of-tree plugin drivers. Registered drivers always take precedence.
+func RegisterFallback(resolve func(name string) (func(*model.Workspace, *model.Provider) (Driver, error), bool)) {
+	fallback = resolve
+}
+
 // GetDriverFactory returns the driver factory function for the given name.
+// Names not registered are passed to the fallback resolver, if any.
 func GetDriverFactory(name string) (driverFactory, bool) {
-	factory, exists := registry[name]
-	return factory, exists
+	if factory, exists := registry[name]; exists {
+		return factory, true
+	}
+	if fallback != nil {
+		if factory, ok := fallback(name); ok {
+			return factory, true
+		}
+	}
+	return nil, false
 }
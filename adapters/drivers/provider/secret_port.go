@@ -0,0 +1,70 @@
+package providerdrv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kompox/kompox/domain"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// secretPortAdapter implements model.SecretPort backed by provider drivers.
+type secretPortAdapter struct {
+	workspaces domain.WorkspaceRepository
+	providers  domain.ProviderRepository
+}
+
+// getDriver fetches driver for given cluster.
+func (a *secretPortAdapter) getDriver(ctx context.Context, cluster *model.Cluster) (Driver, error) {
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster is nil")
+	}
+	provider, err := a.providers.Get(ctx, cluster.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider %s: %w", cluster.ProviderID, err)
+	}
+	var workspace *model.Workspace
+	if provider.WorkspaceID != "" {
+		workspace, _ = a.workspaces.Get(ctx, provider.WorkspaceID)
+	}
+	factory, ok := GetDriverFactory(provider.Driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider driver: %s", provider.Driver)
+	}
+	drv, err := factory(workspace, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver %s: %w", provider.Driver, err)
+	}
+	return drv, nil
+}
+
+// SecretRead reads "file:<path>" sources locally and delegates other sources to drivers
+// implementing SecretReader.
+func (a *secretPortAdapter) SecretRead(ctx context.Context, cluster *model.Cluster, source string) ([]byte, error) {
+	if path, ok := strings.CutPrefix(source, "file:"); ok {
+		if path == "" {
+			return nil, fmt.Errorf("empty file path in secret source %q", source)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read secret file: %w", err)
+		}
+		return data, nil
+	}
+	drv, err := a.getDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := drv.(SecretReader)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not support secret source %q: %w", drv.ID(), source, model.ErrNotSupported)
+	}
+	return reader.SecretRead(ctx, cluster, source)
+}
+
+// GetSecretPort returns a model.SecretPort implemented via provider drivers.
+func GetSecretPort(workspaces domain.WorkspaceRepository, providers domain.ProviderRepository) model.SecretPort {
+	return &secretPortAdapter{workspaces: workspaces, providers: providers}
+}
@@ -0,0 +1,76 @@
+package providerdrv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/domain"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// volumeInventoryPortAdapter implements model.VolumeInventoryPort backed by provider drivers.
+type volumeInventoryPortAdapter struct {
+	workspaces domain.WorkspaceRepository
+}
+
+// getDriver creates the driver for the given provider.
+func (a *volumeInventoryPortAdapter) getDriver(ctx context.Context, provider *model.Provider) (Driver, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("provider nil")
+	}
+	var workspace *model.Workspace
+	if provider.WorkspaceID != "" {
+		workspace, _ = a.workspaces.Get(ctx, provider.WorkspaceID)
+	}
+	factory, ok := GetDriverFactory(provider.Driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider driver: %s", provider.Driver)
+	}
+	drv, err := factory(workspace, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver %s: %w", provider.Driver, err)
+	}
+	return drv, nil
+}
+
+// ResourceList lists Kompox-managed app volume resources of the provider.
+func (a *volumeInventoryPortAdapter) ResourceList(ctx context.Context, provider *model.Provider) ([]*model.VolumeResource, error) {
+	drv, err := a.getDriver(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	return drv.VolumeResourceList(ctx)
+}
+
+// ResourceMarkOrphaned records the time the resource was first found orphaned.
+func (a *volumeInventoryPortAdapter) ResourceMarkOrphaned(ctx context.Context, provider *model.Provider, res *model.VolumeResource, at time.Time) error {
+	drv, err := a.getDriver(ctx, provider)
+	if err != nil {
+		return err
+	}
+	return drv.VolumeResourceMarkOrphaned(ctx, res, at)
+}
+
+// ResourceUnmarkOrphaned removes the orphaned mark from a resource owned by an app again.
+func (a *volumeInventoryPortAdapter) ResourceUnmarkOrphaned(ctx context.Context, provider *model.Provider, res *model.VolumeResource) error {
+	drv, err := a.getDriver(ctx, provider)
+	if err != nil {
+		return err
+	}
+	return drv.VolumeResourceUnmarkOrphaned(ctx, res)
+}
+
+// ResourceDelete deletes the resource.
+func (a *volumeInventoryPortAdapter) ResourceDelete(ctx context.Context, provider *model.Provider, res *model.VolumeResource) error {
+	drv, err := a.getDriver(ctx, provider)
+	if err != nil {
+		return err
+	}
+	return drv.VolumeResourceDelete(ctx, res)
+}
+
+// GetVolumeInventoryPort returns a model.VolumeInventoryPort implemented via provider drivers.
+func GetVolumeInventoryPort(workspaces domain.WorkspaceRepository) model.VolumeInventoryPort {
+	return &volumeInventoryPortAdapter{workspaces: workspaces}
+}
@@ -21,6 +21,14 @@ func (a *volumePortAdapter) getDriver(ctx context.Context, cluster *model.Cluste
 	if cluster == nil || app == nil {
 		return nil, fmt.Errorf("cluster/app nil")
 	}
+	return a.getClusterDriver(ctx, cluster)
+}
+
+// getClusterDriver fetches driver for given cluster.
+func (a *volumePortAdapter) getClusterDriver(ctx context.Context, cluster *model.Cluster) (Driver, error) {
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster nil")
+	}
 	provider, err := a.providers.Get(ctx, cluster.ProviderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider %s: %w", cluster.ProviderID, err)
@@ -40,6 +48,16 @@ func (a *volumePortAdapter) getDriver(ctx context.Context, cluster *model.Cluste
 	return drv, nil
 }
 
+// Capabilities returns the capabilities of the driver managing the cluster.
+func (a *volumePortAdapter) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	drv, err := a.getClusterDriver(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	caps := drv.Capabilities()
+	return &caps, nil
+}
+
 // DiskList returns the list of disks associated with the
 // logical volume identified by volName for the specified cluster/app.
 func (a *volumePortAdapter) DiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
@@ -81,6 +99,16 @@ func (a *volumePortAdapter) DiskAssign(ctx context.Context, cluster *model.Clust
 	return drv.VolumeDiskAssign(ctx, cluster, app, volName, diskName, opts...)
 }
 
+// DiskUpdate changes provider-specific options of an existing disk (diskName)
+// belonging to the logical volume volName for the specified cluster/app.
+func (a *volumePortAdapter) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	drv, err := a.getDriver(ctx, cluster, app)
+	if err != nil {
+		return nil, err
+	}
+	return drv.VolumeDiskUpdate(ctx, cluster, app, volName, diskName, opts...)
+}
+
 // SnapshotList lists snapshots for the given logical volume.
 func (a *volumePortAdapter) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
 	drv, err := a.getDriver(ctx, cluster, app)
@@ -108,6 +136,22 @@ func (a *volumePortAdapter) SnapshotDelete(ctx context.Context, cluster *model.C
 	return drv.VolumeSnapshotDelete(ctx, cluster, app, volName, snapName, opts...)
 }
 
+// SnapshotCopy copies a snapshot of another provider into the logical volume.
+func (a *volumePortAdapter) SnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	drv, err := a.getDriver(ctx, cluster, app)
+	if err != nil {
+		return nil, err
+	}
+	copier, ok := drv.(VolumeSnapshotCopier)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not copy snapshots: %w", drv.ID(), model.ErrNotSupported)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("source snapshot nil")
+	}
+	return copier.VolumeSnapshotCopy(ctx, cluster, app, volName, snapName, source, opts...)
+}
+
 // GetVolumePort returns a model.VolumePort implemented via provider drivers.
 func GetVolumePort(workspaces domain.WorkspaceRepository, providers domain.ProviderRepository, clusters domain.ClusterRepository, apps domain.AppRepository) model.VolumePort {
 	return &volumePortAdapter{workspaces: workspaces, providers: providers, clusters: clusters, apps: apps}
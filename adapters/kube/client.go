@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"os"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -18,6 +23,10 @@ type Client struct {
 	RESTConfig *rest.Config
 	// Clientset provides typed clients for core/built-in resources.
 	Clientset kubernetes.Interface
+	// dynamic and mapper replace clients built from RESTConfig for in-process clusters
+	// (see RegisterInProcessCluster).
+	dynamic dynamic.Interface
+	mapper  meta.RESTMapper
 	// kubeconfig holds the original kubeconfig bytes when available.
 	// It is set when the client is constructed from kubeconfig bytes or path,
 	// and left empty when constructed from a REST config directly.
@@ -81,6 +90,10 @@ func NewClientFromRESTConfig(cfg *rest.Config, opts *Options) (*Client, error) {
 	}
 	opts.applyDefaults()
 
+	if c, ok, err := newInProcessClient(cfg); ok {
+		return c, err
+	}
+
 	cfg.QPS = opts.QPS
 	cfg.Burst = opts.Burst
 	if opts.UserAgent != "" {
@@ -107,3 +120,19 @@ func (c *Client) Kubeconfig() []byte {
 	copy(out, c.kubeconfig)
 	return out
 }
+
+// dynamicClients returns the dynamic client and REST mapper for generic resource access.
+func (c *Client) dynamicClients() (dynamic.Interface, meta.RESTMapper, error) {
+	if c.dynamic != nil {
+		return c.dynamic, c.mapper, nil
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(c.RESTConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create discovery client: %w", err)
+	}
+	dy, err := dynamic.NewForConfig(c.RESTConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create dynamic client: %w", err)
+	}
+	return dy, restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)), nil
+}
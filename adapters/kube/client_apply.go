@@ -15,10 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/discovery"
-	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/restmapper"
 )
 
 // ApplyOptions configures server-side apply operations.
@@ -60,14 +57,9 @@ func (c *Client) ApplyObjects(ctx context.Context, objs []runtime.Object, opts *
 	}
 	opts.defaults()
 
-	dc, err := discovery.NewDiscoveryClientForConfig(c.RESTConfig)
+	dy, mapper, err := c.dynamicClients()
 	if err != nil {
-		return fmt.Errorf("create discovery client: %w", err)
-	}
-	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
-	dy, err := dynamic.NewForConfig(c.RESTConfig)
-	if err != nil {
-		return fmt.Errorf("create dynamic client: %w", err)
+		return err
 	}
 
 	for _, obj := range objs {
@@ -110,14 +102,9 @@ func (c *Client) ApplyYAML(ctx context.Context, data []byte, opts *ApplyOptions)
 	}
 	opts.defaults()
 
-	dc, err := discovery.NewDiscoveryClientForConfig(c.RESTConfig)
-	if err != nil {
-		return fmt.Errorf("create discovery client: %w", err)
-	}
-	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
-	dy, err := dynamic.NewForConfig(c.RESTConfig)
+	dy, mapper, err := c.dynamicClients()
 	if err != nil {
-		return fmt.Errorf("create dynamic client: %w", err)
+		return err
 	}
 
 	dec := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
@@ -9,7 +9,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 )
 
 // DeleteResourceTarget describes a collection of resources to delete.
@@ -49,9 +48,9 @@ func (c *Client) DeleteByLabelSelector(ctx context.Context, namespace string, ta
 	}
 	opts.defaults()
 
-	dy, err := dynamic.NewForConfig(c.RESTConfig)
+	dy, _, err := c.dynamicClients()
 	if err != nil {
-		return 0, fmt.Errorf("create dynamic client: %w", err)
+		return 0, err
 	}
 
 	var deleted int
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -14,7 +16,8 @@ import (
 )
 
 // PatchDeploymentPodContentHash updates the deployment's content hash annotation and imagePullSecrets list
-// based on presence of pullSecretName. Only patches when something changes.
+// based on presence of pullSecretName. Other imagePullSecrets entries (e.g., the registry Secret generated
+// by the converter) are kept. Only patches when something changes.
 func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, deploymentName string) error {
 	if c == nil || c.Clientset == nil || namespace == "" || deploymentName == "" {
 		return nil
@@ -55,10 +58,14 @@ func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, d
 	}
 
 	desiredSpec := dep.Spec.Template.Spec.DeepCopy()
+	desiredSpec.ImagePullSecrets = nil
+	for _, ref := range dep.Spec.Template.Spec.ImagePullSecrets {
+		if ref.Name != pullSecretName {
+			desiredSpec.ImagePullSecrets = append(desiredSpec.ImagePullSecrets, ref)
+		}
+	}
 	if pullExists && pullSecretName != "" {
-		desiredSpec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: pullSecretName}}
-	} else {
-		desiredSpec.ImagePullSecrets = nil
+		desiredSpec.ImagePullSecrets = append(desiredSpec.ImagePullSecrets, corev1.LocalObjectReference{Name: pullSecretName})
 	}
 	newHash := ComputePodContentHash(desiredSpec, secrets, configMaps)
 	prev := ""
@@ -66,14 +73,7 @@ func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, d
 		prev = dep.Spec.Template.Annotations[AnnotationK4xComposeContentHash]
 	}
 	// Detect imagePullSecrets change.
-	imagePullSecretsChanged := false
-	if pullExists {
-		if len(dep.Spec.Template.Spec.ImagePullSecrets) != 1 || dep.Spec.Template.Spec.ImagePullSecrets[0].Name != pullSecretName {
-			imagePullSecretsChanged = true
-		}
-	} else if len(dep.Spec.Template.Spec.ImagePullSecrets) != 0 {
-		imagePullSecretsChanged = true
-	}
+	imagePullSecretsChanged := !slices.Equal(dep.Spec.Template.Spec.ImagePullSecrets, desiredSpec.ImagePullSecrets)
 
 	hashChanged := newHash != "" && newHash != prev
 	if !hashChanged && !imagePullSecretsChanged {
@@ -108,16 +108,10 @@ func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, d
 
 	if imagePullSecretsChanged {
 		pullPath := "/spec/template/spec/imagePullSecrets"
-		if pullExists {
-			if len(dep.Spec.Template.Spec.ImagePullSecrets) == 0 {
-				patch = append(patch, op{Op: "add", Path: pullPath, Value: []corev1.LocalObjectReference{{Name: pullSecretName}}})
-			} else {
-				patch = append(patch, op{Op: "replace", Path: pullPath, Value: []corev1.LocalObjectReference{{Name: pullSecretName}}})
-			}
+		if len(dep.Spec.Template.Spec.ImagePullSecrets) == 0 {
+			patch = append(patch, op{Op: "add", Path: pullPath, Value: desiredSpec.ImagePullSecrets})
 		} else {
-			if len(dep.Spec.Template.Spec.ImagePullSecrets) > 0 {
-				patch = append(patch, op{Op: "replace", Path: pullPath, Value: []corev1.LocalObjectReference{}})
-			}
+			patch = append(patch, op{Op: "replace", Path: pullPath, Value: desiredImagePullSecrets(desiredSpec)})
 		}
 	}
 
@@ -136,13 +130,7 @@ func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, d
 			tpl["metadata"] = map[string]any{"annotations": map[string]string{AnnotationK4xComposeContentHash: newHash}}
 		}
 		if imagePullSecretsChanged {
-			specMap := map[string]any{}
-			if pullExists {
-				specMap["imagePullSecrets"] = []map[string]string{{"name": pullSecretName}}
-			} else {
-				specMap["imagePullSecrets"] = []any{}
-			}
-			tpl["spec"] = specMap
+			tpl["spec"] = map[string]any{"imagePullSecrets": desiredImagePullSecrets(desiredSpec)}
 		}
 		mpBytes, _ := json.Marshal(mp)
 		if _, err2 := c.Clientset.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.MergePatchType, mpBytes, metav1.PatchOptions{}); err2 != nil {
@@ -152,3 +140,32 @@ func (c *Client) PatchDeploymentPodContentHash(ctx context.Context, namespace, d
 	}
 	return nil
 }
+
+// desiredImagePullSecrets returns the imagePullSecrets of spec, never nil so that an empty
+// list clears the field in patches.
+func desiredImagePullSecrets(spec *corev1.PodSpec) []corev1.LocalObjectReference {
+	if spec.ImagePullSecrets == nil {
+		return []corev1.LocalObjectReference{}
+	}
+	return spec.ImagePullSecrets
+}
+
+// podDeployment returns the name of the Deployment owning a pod through its ReplicaSet,
+// or an empty string when the pod is not owned by a Deployment.
+func (c *Client) podDeployment(ctx context.Context, pod *corev1.Pod) (string, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || ref.Kind != "ReplicaSet" {
+		return "", nil
+	}
+	rs, err := c.Clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get replicaset %s/%s: %w", pod.Namespace, ref.Name, err)
+	}
+	if ref := metav1.GetControllerOf(rs); ref != nil && ref.Kind == "Deployment" {
+		return ref.Name, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,80 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPatchDeploymentPodContentHashKeepsRegistrySecret(t *testing.T) {
+	ctx := context.Background()
+	secret := func(name, hash string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Annotations: map[string]string{kube.AnnotationK4xComposeContentHash: hash}}}
+	}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1-app", Namespace: "ns"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "app1-app--registry"}},
+			}}},
+		},
+		secret("app1-app--registry", "aaaaaa"),
+		secret("app1-app--pull", "bbbbbb"),
+	)
+	client := &kube.Client{Clientset: clientset}
+
+	get := func() *appsv1.Deployment {
+		t.Helper()
+		dep, err := clientset.AppsV1().Deployments("ns").Get(ctx, "app1-app", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get deployment: %v", err)
+		}
+		return dep
+	}
+
+	if err := client.PatchDeploymentPodContentHash(ctx, "ns", "app1-app"); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	dep := get()
+	pull := dep.Spec.Template.Spec.ImagePullSecrets
+	if len(pull) != 2 || pull[0].Name != "app1-app--registry" || pull[1].Name != "app1-app--pull" {
+		t.Errorf("imagePullSecrets = %v", pull)
+	}
+	hash := dep.Spec.Template.Annotations[kube.AnnotationK4xComposeContentHash]
+	if hash == "" {
+		t.Fatalf("content hash not set")
+	}
+
+	// Rotating the registry credentials changes the pod content hash.
+	rotated := secret("app1-app--registry", "cccccc")
+	if _, err := clientset.CoreV1().Secrets("ns").Update(ctx, rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	if err := client.PatchDeploymentPodContentHash(ctx, "ns", "app1-app"); err != nil {
+		t.Fatalf("patch after rotation: %v", err)
+	}
+	dep = get()
+	if dep.Spec.Template.Annotations[kube.AnnotationK4xComposeContentHash] == hash {
+		t.Errorf("content hash unchanged after rotation")
+	}
+	if len(dep.Spec.Template.Spec.ImagePullSecrets) != 2 {
+		t.Errorf("imagePullSecrets = %v", dep.Spec.Template.Spec.ImagePullSecrets)
+	}
+
+	// Deleting the pull secret keeps the registry secret.
+	if err := clientset.CoreV1().Secrets("ns").Delete(ctx, "app1-app--pull", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete secret: %v", err)
+	}
+	if err := client.PatchDeploymentPodContentHash(ctx, "ns", "app1-app"); err != nil {
+		t.Fatalf("patch after delete: %v", err)
+	}
+	pull = get().Spec.Template.Spec.ImagePullSecrets
+	if len(pull) != 1 || pull[0].Name != "app1-app--registry" {
+		t.Errorf("imagePullSecrets after delete = %v", pull)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -96,13 +97,6 @@ func (c *Client) InstallIngressTraefik(ctx context.Context, cluster *model.Clust
 			"fsGroup":             65532,
 			"fsGroupChangePolicy": "OnRootMismatch",
 		},
-		// Ensure AKS Workload Identity is enabled on Traefik Pods by adding the required label.
-		// This relies on a pre-created ServiceAccount annotated for Azure Workload Identity.
-		"deployment": map[string]any{
-			"podLabels": map[string]any{
-				"azure.workload.identity/use": "true",
-			},
-		},
 		// Ensure the Traefik pods are only on nodes labeled with kompox.dev/node-pool=system.
 		"nodeSelector": map[string]any{
 			"kompox.dev/node-pool": "system",
@@ -246,6 +240,44 @@ func (c *Client) InstallIngressTraefik(ctx context.Context, cluster *model.Clust
 	return fmt.Errorf("helm install traefik: %w", err)
 }
 
+// InstallIngressTraefikBasic prepares the ingress namespace and ServiceAccount and installs
+// Traefik for drivers without cloud specific ingress integration. annotations are set on
+// the ServiceAccount (e.g., for workload identity). The nodeSelector pinning Traefik to the
+// system node pool is kept only when some node carries the system pool label. Driver
+// mutators are applied after that.
+func (c *Client) InstallIngressTraefikBasic(ctx context.Context, cluster *model.Cluster, annotations map[string]string, mutators ...HelmValuesMutator) error {
+	if c == nil || c.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	log := logging.FromContext(ctx)
+
+	// Step 1: Ensure ingress namespace and ServiceAccount exist (idempotent)
+	ns := IngressNamespace(cluster)
+	if err := c.CreateNamespace(ctx, ns); err != nil {
+		return err
+	}
+	saName := IngressServiceAccountName(cluster)
+	if err := c.CreateServiceAccount(ctx, ns, saName, annotations); err != nil {
+		return fmt.Errorf("create ingress serviceaccount %s/%s: %w", ns, saName, err)
+	}
+
+	// Step 2: Keep the system node pool selector only when nodes carry the label
+	systemNodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: LabelK4xNodePool + "=system"})
+	if err != nil {
+		return fmt.Errorf("list system nodes: %w", err)
+	}
+	var all []HelmValuesMutator
+	if len(systemNodes.Items) == 0 {
+		log.Info(ctx, "no nodes labeled as system pool; traefik may run on any node", "label", LabelK4xNodePool+"=system")
+		all = append(all, func(_ context.Context, _ *model.Cluster, _ string, values HelmValues) {
+			delete(values, "nodeSelector")
+		})
+	}
+
+	// Step 3: Install Traefik via Helm (idempotent)
+	return c.InstallIngressTraefik(ctx, cluster, append(all, mutators...)...)
+}
+
 // UninstallIngressTraefik removes the Traefik release. Best-effort and idempotent.
 func (c *Client) UninstallIngressTraefik(ctx context.Context, cluster *model.Cluster) error {
 	if c == nil || c.RESTConfig == nil {
@@ -0,0 +1,230 @@
+package kube
+
+import (
+	"fmt"
+	"sync"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// inProcessFieldManager is the field manager of changes made by the in-process cluster itself.
+const inProcessFieldManager = "kompox-inprocess"
+
+// InProcessOptions describes the state of an in-process cluster.
+type InProcessOptions struct {
+	// Objects are the objects stored in the cluster when a client is constructed.
+	Objects []runtime.Object
+	// IngressIP is published in status.loadBalancer of every Ingress (empty publishes nothing).
+	IngressIP string
+	// OnChange is called after every successful mutation with the object key and the stored
+	// object (nil when deleted), so that the owner can persist the cluster between processes.
+	OnChange func(key string, obj runtime.Object) error
+}
+
+// inProcessClusters maps REST config hosts to functions returning the current cluster state.
+var inProcessClusters sync.Map
+
+// RegisterInProcessCluster serves the API server at host in process. Clients constructed for
+// host use client-go fakes seeded by newOptions instead of connecting to the network.
+// Only built-in kinds are served and there are no controllers except the Ingress status
+// publisher (see InProcessOptions.IngressIP). A later registration replaces an earlier one.
+func RegisterInProcessCluster(host string, newOptions func() (*InProcessOptions, error)) {
+	inProcessClusters.Store(host, newOptions)
+}
+
+// UnregisterInProcessCluster stops serving the in-process cluster at host.
+func UnregisterInProcessCluster(host string) {
+	inProcessClusters.Delete(host)
+}
+
+// InProcessKubeconfig returns a kubeconfig whose only context points at host.
+func InProcessKubeconfig(host, name string) ([]byte, error) {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters[name] = &clientcmdapi.Cluster{Server: host}
+	cfg.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: "in-process"}
+	cfg.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	cfg.CurrentContext = name
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("write kubeconfig: %w", err)
+	}
+	return data, nil
+}
+
+// newInProcessClient returns a Client for cfg when cfg.Host is an in-process cluster.
+func newInProcessClient(cfg *rest.Config) (*Client, bool, error) {
+	v, ok := inProcessClusters.Load(cfg.Host)
+	if !ok {
+		return nil, false, nil
+	}
+	opts, err := v.(func() (*InProcessOptions, error))()
+	if err != nil {
+		return nil, true, fmt.Errorf("load in-process cluster %s: %w", cfg.Host, err)
+	}
+	cs := fake.NewClientset()
+	for _, obj := range opts.Objects {
+		if err := cs.Tracker().Add(obj); err != nil {
+			return nil, true, fmt.Errorf("load in-process cluster %s: %w", cfg.Host, err)
+		}
+	}
+	c := &inProcessCluster{tracker: cs.Tracker(), opts: opts}
+	cs.PrependReactor("*", "*", c.react)
+	dy := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	dy.PrependReactor("*", "*", c.reactDynamic)
+	return &Client{
+		RESTConfig: cfg,
+		Clientset:  cs,
+		dynamic:    dy,
+		mapper:     testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme),
+	}, true, nil
+}
+
+// inProcessCluster routes typed and dynamic fake clients to one object tracker.
+type inProcessCluster struct {
+	tracker k8stesting.ObjectTracker
+	opts    *InProcessOptions
+}
+
+// react applies a typed action to the tracker, then runs the Ingress status publisher and
+// reports the change.
+func (c *inProcessCluster) react(action k8stesting.Action) (bool, runtime.Object, error) {
+	handled, ret, err := k8stesting.ObjectReaction(c.tracker)(action)
+	if err != nil {
+		return handled, ret, err
+	}
+	var name string
+	switch a := action.(type) {
+	case k8stesting.CreateActionImpl:
+		name = objectName(a.GetObject())
+	case k8stesting.UpdateActionImpl:
+		name = objectName(a.GetObject())
+	case k8stesting.PatchActionImpl:
+		name = a.GetName()
+	case k8stesting.DeleteActionImpl:
+		name = a.GetName()
+	default:
+		return handled, ret, nil
+	}
+	gvr, ns := action.GetResource(), action.GetNamespace()
+	key := gvr.GroupResource().String() + "/" + ns + "/" + name
+	if action.GetVerb() == "delete" {
+		if c.opts.OnChange != nil {
+			err = c.opts.OnChange(key, nil)
+		}
+		return handled, ret, err
+	}
+	if err := c.publishIngress(gvr, ns, name); err != nil {
+		return true, nil, err
+	}
+	obj, err := c.tracker.Get(gvr, ns, name)
+	if err != nil {
+		return true, nil, err
+	}
+	if err := setKind(obj); err != nil {
+		return true, nil, err
+	}
+	if c.opts.OnChange != nil {
+		if err := c.opts.OnChange(key, obj); err != nil {
+			return true, nil, err
+		}
+	}
+	return handled, obj, nil
+}
+
+// reactDynamic converts unstructured objects of a dynamic action to typed objects, reacts to
+// it like a typed action and returns the result as unstructured.
+func (c *inProcessCluster) reactDynamic(action k8stesting.Action) (bool, runtime.Object, error) {
+	var err error
+	switch a := action.(type) {
+	case k8stesting.CreateActionImpl:
+		if a.Object, err = toTyped(a.Object); err != nil {
+			return true, nil, err
+		}
+		action = a
+	case k8stesting.UpdateActionImpl:
+		if a.Object, err = toTyped(a.Object); err != nil {
+			return true, nil, err
+		}
+		action = a
+	}
+	handled, ret, err := c.react(action)
+	if err != nil || ret == nil {
+		return handled, ret, err
+	}
+	if err := setKind(ret); err != nil {
+		return true, nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ret)
+	if err != nil {
+		return true, nil, err
+	}
+	return handled, &unstructured.Unstructured{Object: content}, nil
+}
+
+// publishIngress sets status.loadBalancer of an Ingress to the cluster ingress IP.
+func (c *inProcessCluster) publishIngress(gvr schema.GroupVersionResource, ns, name string) error {
+	if c.opts.IngressIP == "" || gvr.GroupResource() != networkingv1.Resource("ingresses") {
+		return nil
+	}
+	obj, err := c.tracker.Get(gvr, ns, name)
+	if err != nil {
+		return err
+	}
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok || len(ing.Status.LoadBalancer.Ingress) > 0 {
+		return nil
+	}
+	ing.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: c.opts.IngressIP}}
+	return c.tracker.Update(gvr, ing, ns, metav1.UpdateOptions{FieldManager: inProcessFieldManager})
+}
+
+// toTyped converts an unstructured object of a built-in kind to its typed form.
+func toTyped(obj runtime.Object) (runtime.Object, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+	typed, err := scheme.Scheme.New(u.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, err
+	}
+	return typed, nil
+}
+
+// setKind sets apiVersion and kind of a typed object, which the tracker leaves empty.
+func setKind(obj runtime.Object) error {
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return nil
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	return nil
+}
+
+// objectName returns metadata.name of obj (empty if obj has no metadata).
+func objectName(obj runtime.Object) string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return m.GetName()
+}
@@ -0,0 +1,105 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestInProcessCluster(t *testing.T) {
+	ctx := context.Background()
+	const host = "https://cls1.inprocess.test"
+	stored := map[string]runtime.Object{}
+	newOptions := func() (*kube.InProcessOptions, error) {
+		var objs []runtime.Object
+		for _, obj := range stored {
+			objs = append(objs, obj)
+		}
+		return &kube.InProcessOptions{
+			Objects:   objs,
+			IngressIP: "192.0.2.10",
+			OnChange: func(key string, obj runtime.Object) error {
+				if obj == nil {
+					delete(stored, key)
+				} else {
+					stored[key] = obj
+				}
+				return nil
+			},
+		}, nil
+	}
+	kube.RegisterInProcessCluster(host, newOptions)
+	defer kube.UnregisterInProcessCluster(host)
+
+	kubeconfig, err := kube.InProcessKubeconfig(host, "cls1")
+	if err != nil {
+		t.Fatalf("InProcessKubeconfig: %v", err)
+	}
+	client, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromKubeconfig: %v", err)
+	}
+
+	labels := map[string]string{"app": "web"}
+	pathType := networkingv1.PathTypePrefix
+	objs := []runtime.Object{
+		&corev1.Namespace{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}, ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+		&corev1.Service{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}, ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns1", Labels: labels}},
+		&networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns1", Labels: labels},
+			Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+				Host: "web.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+					Path:     "/",
+					PathType: &pathType,
+					Backend:  networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+				}}}},
+			}}},
+		},
+	}
+	if err := client.ApplyObjects(ctx, objs, nil); err != nil {
+		t.Fatalf("ApplyObjects: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Errorf("stored = %v", stored)
+	}
+
+	// A new client sees the stored objects with the published Ingress IP.
+	client, err = kube.NewClientFromKubeconfig(ctx, kubeconfig, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromKubeconfig: %v", err)
+	}
+	hosts, err := client.IngressHostIPs(ctx, "ns1", "app=web")
+	if err != nil || len(hosts) != 1 || hosts[0].Host != "web.example.com" || hosts[0].IP != "192.0.2.10" {
+		t.Errorf("IngressHostIPs = %+v, %v", hosts, err)
+	}
+
+	// Re-apply is idempotent and deletion is reported.
+	if err := client.ApplyObjects(ctx, objs, nil); err != nil {
+		t.Fatalf("ApplyObjects again: %v", err)
+	}
+	targets := []kube.DeleteResourceTarget{{GVR: schema.GroupVersionResource{Version: "v1", Resource: "services"}, Namespaced: true}}
+	if n, err := client.DeleteByLabelSelector(ctx, "ns1", targets, "app=web", nil); err != nil || n != 1 {
+		t.Errorf("DeleteByLabelSelector = %d, %v", n, err)
+	}
+	if _, ok := stored["services/ns1/web"]; ok || len(stored) != 2 {
+		t.Errorf("stored after delete = %v", stored)
+	}
+
+	// Unregistered hosts are not served in process.
+	kube.UnregisterInProcessCluster(host)
+	client, err = kube.NewClientFromKubeconfig(ctx, kubeconfig, nil)
+	if err != nil {
+		t.Fatalf("NewClientFromKubeconfig: %v", err)
+	}
+	if _, err := client.IngressHostIPs(ctx, "ns1", "app=web"); err == nil {
+		t.Errorf("expected network error after unregister")
+	}
+}
@@ -0,0 +1,160 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kompox/kompox/domain/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Resource kinds reported by the plan helpers.
+const (
+	PlanKindNamespace      = "Namespace"
+	PlanKindServiceAccount = "ServiceAccount"
+	PlanKindHelmRelease    = "HelmRelease"
+	PlanKindDeployment     = "Deployment"
+)
+
+// PlanNamespace returns the change CreateNamespace (create) or DeleteNamespace (remove=true) would make.
+func (c *Client) PlanNamespace(ctx context.Context, name string, remove bool) (model.ClusterChange, error) {
+	change := model.ClusterChange{Scope: model.ClusterChangeScopeCluster, Kind: PlanKindNamespace, Resource: name, Action: model.ClusterChangeNoOp}
+	if c == nil || c.Clientset == nil {
+		return change, fmt.Errorf("kube client is not initialized")
+	}
+	_, err := c.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	found := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return change, fmt.Errorf("get namespace %s: %w", name, err)
+	}
+	switch {
+	case remove && found:
+		change.Action = model.ClusterChangeDelete
+	case !remove && !found:
+		change.Action = model.ClusterChangeCreate
+	}
+	return change, nil
+}
+
+// PlanServiceAccount returns the change CreateServiceAccount would make: merged annotations and
+// automountServiceAccountToken=false.
+func (c *Client) PlanServiceAccount(ctx context.Context, namespace, name string, annotations map[string]string) (model.ClusterChange, error) {
+	change := model.ClusterChange{Scope: model.ClusterChangeScopeCluster, Kind: PlanKindServiceAccount, Resource: namespace + "/" + name, Action: model.ClusterChangeNoOp}
+	if c == nil || c.Clientset == nil {
+		return change, fmt.Errorf("kube client is not initialized")
+	}
+	sa, err := c.Clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		change.Action = model.ClusterChangeCreate
+		return change, nil
+	}
+	if err != nil {
+		return change, fmt.Errorf("get serviceaccount %s/%s: %w", namespace, name, err)
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if cur, ok := sa.Annotations[k]; !ok || cur != annotations[k] {
+			f := model.ClusterChangeField{Path: "metadata.annotations." + k, After: annotations[k]}
+			if ok {
+				f.Before = cur
+			}
+			change.Fields = append(change.Fields, f)
+		}
+	}
+	if sa.AutomountServiceAccountToken == nil || *sa.AutomountServiceAccountToken {
+		f := model.ClusterChangeField{Path: "automountServiceAccountToken", After: false}
+		if sa.AutomountServiceAccountToken != nil {
+			f.Before = true
+		}
+		change.Fields = append(change.Fields, f)
+	}
+	if len(change.Fields) > 0 {
+		change.Action = model.ClusterChangeUpdate
+	}
+	return change, nil
+}
+
+// PlanIngressTraefik returns the change InstallIngressTraefik (install) or UninstallIngressTraefik
+// (remove=true) would make to the Traefik Helm release. Install always upgrades an existing
+// release to a new revision, so an installed release is planned as an update.
+func (c *Client) PlanIngressTraefik(ctx context.Context, cluster *model.Cluster, remove bool) (model.ClusterChange, error) {
+	ns := IngressNamespace(cluster)
+	change := model.ClusterChange{Scope: model.ClusterChangeScopeCluster, Kind: PlanKindHelmRelease, Resource: ns + "/" + TraefikReleaseName, Action: model.ClusterChangeNoOp}
+	revision, found, err := c.helmReleaseRevision(ctx, ns, TraefikReleaseName)
+	if err != nil {
+		return change, err
+	}
+	switch {
+	case remove && found:
+		change.Action = model.ClusterChangeDelete
+	case !remove && !found:
+		change.Action = model.ClusterChangeCreate
+	case !remove && found:
+		change.Action = model.ClusterChangeUpdate
+		change.Fields = []model.ClusterChangeField{{Path: "revision", Before: revision, After: revision + 1}}
+	}
+	return change, nil
+}
+
+// PlanSpotHandler returns the change InstallSpotHandler (install) or UninstallSpotHandler would
+// make to the Spot eviction helper Deployment. Install removes the helper when the cluster does
+// not configure it; remove=true always removes it. An installed helper is re-applied on install.
+func (c *Client) PlanSpotHandler(ctx context.Context, cluster *model.Cluster, remove bool) (model.ClusterChange, error) {
+	ns := IngressNamespace(cluster)
+	change := model.ClusterChange{Scope: model.ClusterChangeScopeCluster, Kind: PlanKindDeployment, Resource: ns + "/" + SpotHandlerName, Action: model.ClusterChangeNoOp}
+	if c == nil || c.Clientset == nil {
+		return change, fmt.Errorf("kube client is not initialized")
+	}
+	_, err := c.Clientset.AppsV1().Deployments(ns).Get(ctx, SpotHandlerName, metav1.GetOptions{})
+	found := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return change, fmt.Errorf("get deployment %s/%s: %w", ns, SpotHandlerName, err)
+	}
+	install := !remove && cluster != nil && cluster.SpotHandler != nil
+	switch {
+	case install && !found:
+		change.Action = model.ClusterChangeCreate
+	case install && found:
+		change.Action = model.ClusterChangeUpdate
+	case !install && found:
+		change.Action = model.ClusterChangeDelete
+	}
+	return change, nil
+}
+
+// helmReleaseRevision returns the latest revision of a Helm release from the release Secrets
+// (Helm "secret" storage driver, labels owner=helm,name=<release>).
+func (c *Client) helmReleaseRevision(ctx context.Context, namespace, release string) (int, bool, error) {
+	if c == nil || c.Clientset == nil {
+		return 0, false, fmt.Errorf("kube client is not initialized")
+	}
+	list, err := c.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: "owner=helm,name=" + release})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("list helm release %s/%s: %w", namespace, release, err)
+	}
+	revision, found := 0, false
+	for _, s := range list.Items {
+		if s.Labels["status"] == "uninstalled" {
+			continue
+		}
+		v, err := strconv.Atoi(s.Labels["version"])
+		if err != nil {
+			continue
+		}
+		found = true
+		if v > revision {
+			revision = v
+		}
+	}
+	return revision, found, nil
+}
@@ -0,0 +1,63 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPlanIngressResources(t *testing.T) {
+	ctx := context.Background()
+	cluster := &model.Cluster{Name: "cls1"}
+	ns := kube.IngressNamespace(cluster)
+	client := &kube.Client{Clientset: fake.NewSimpleClientset()}
+	annotations := map[string]string{"azure.workload.identity/client-id": "new"}
+
+	// Nothing installed yet: install creates everything, uninstall has nothing to delete.
+	if c, err := client.PlanNamespace(ctx, ns, false); err != nil || c.Action != model.ClusterChangeCreate {
+		t.Errorf("namespace install = %+v, %v", c, err)
+	}
+	if c, err := client.PlanNamespace(ctx, ns, true); err != nil || c.Action != model.ClusterChangeNoOp {
+		t.Errorf("namespace uninstall = %+v, %v", c, err)
+	}
+	if c, err := client.PlanServiceAccount(ctx, ns, "sa", annotations); err != nil || c.Action != model.ClusterChangeCreate {
+		t.Errorf("serviceaccount = %+v, %v", c, err)
+	}
+	if c, err := client.PlanIngressTraefik(ctx, cluster, false); err != nil || c.Action != model.ClusterChangeCreate {
+		t.Errorf("release install = %+v, %v", c, err)
+	}
+
+	// Installed with a stale annotation and Helm release revision 2.
+	automount := true
+	client = &kube.Client{Clientset: fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}},
+		&corev1.ServiceAccount{
+			ObjectMeta:                   metav1.ObjectMeta{Name: "sa", Namespace: ns, Annotations: map[string]string{"azure.workload.identity/client-id": "old"}},
+			AutomountServiceAccountToken: &automount,
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.traefik.v1", Namespace: ns, Labels: map[string]string{"owner": "helm", "name": kube.TraefikReleaseName, "version": "1", "status": "superseded"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.traefik.v2", Namespace: ns, Labels: map[string]string{"owner": "helm", "name": kube.TraefikReleaseName, "version": "2", "status": "deployed"}}},
+	)}
+	if c, err := client.PlanNamespace(ctx, ns, false); err != nil || c.Action != model.ClusterChangeNoOp {
+		t.Errorf("namespace install = %+v, %v", c, err)
+	}
+	c, err := client.PlanServiceAccount(ctx, ns, "sa", annotations)
+	if err != nil || c.Action != model.ClusterChangeUpdate || len(c.Fields) != 2 {
+		t.Fatalf("serviceaccount = %+v, %v", c, err)
+	}
+	if f := c.Fields[0]; f.Path != "metadata.annotations.azure.workload.identity/client-id" || f.Before != "old" || f.After != "new" {
+		t.Errorf("annotation field = %+v", f)
+	}
+	c, err = client.PlanIngressTraefik(ctx, cluster, false)
+	if err != nil || c.Action != model.ClusterChangeUpdate || len(c.Fields) != 1 || c.Fields[0].Before != 2 || c.Fields[0].After != 3 {
+		t.Errorf("release install = %+v, %v", c, err)
+	}
+	if c, err := client.PlanIngressTraefik(ctx, cluster, true); err != nil || c.Action != model.ClusterChangeDelete {
+		t.Errorf("release uninstall = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,116 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RescheduleFieldManager is the field manager used when the node pool selector is patched.
+const RescheduleFieldManager = "kompoxops-reschedule"
+
+// SchedulingFailure describes a Pending pod that the scheduler could not place.
+type SchedulingFailure struct {
+	Pod     string `json:"pod"`
+	Message string `json:"message"`
+}
+
+// PodSchedulingFailures returns the Pending pods matching labelSelector that failed scheduling.
+// The message is taken from the latest FailedScheduling event of the pod, or from the
+// PodScheduled=False condition when no event is available.
+func (c *Client) PodSchedulingFailures(ctx context.Context, namespace, labelSelector string) ([]SchedulingFailure, error) {
+	if c == nil || c.Clientset == nil {
+		return nil, fmt.Errorf("kube client is not initialized")
+	}
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+		FieldSelector: fields.OneTermEqualSelector("status.phase", string(corev1.PodPending)).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	events, err := c.Clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	latest := map[string]corev1.Event{}
+	for _, ev := range events.Items {
+		if ev.Reason != "FailedScheduling" || ev.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		if cur, ok := latest[ev.InvolvedObject.Name]; !ok || eventTime(ev).After(eventTime(cur).Time) {
+			latest[ev.InvolvedObject.Name] = ev
+		}
+	}
+
+	var failures []SchedulingFailure
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		if ev, ok := latest[pod.Name]; ok {
+			failures = append(failures, SchedulingFailure{Pod: pod.Name, Message: ev.Message})
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+				failures = append(failures, SchedulingFailure{Pod: pod.Name, Message: cond.Message})
+				break
+			}
+		}
+	}
+	return failures, nil
+}
+
+// eventTime returns the most recent timestamp recorded on an event.
+func eventTime(ev corev1.Event) metav1.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp
+	case !ev.EventTime.IsZero():
+		return metav1.Time{Time: ev.EventTime.Time}
+	default:
+		return ev.FirstTimestamp
+	}
+}
+
+// DeploymentNodePool returns the node pool pinned by the pod template nodeSelector of a deployment.
+// It returns an empty string when no pool is pinned.
+func (c *Client) DeploymentNodePool(ctx context.Context, namespace, name string) (string, error) {
+	if c == nil || c.Clientset == nil {
+		return "", fmt.Errorf("kube client is not initialized")
+	}
+	dep, err := c.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get deployment %s/%s: %w", namespace, name, err)
+	}
+	return dep.Spec.Template.Spec.NodeSelector[LabelK4xNodePool], nil
+}
+
+// PatchDeploymentNodePool pins the pod template of a deployment to a node pool with the
+// nodeSelector label kompox.dev/node-pool. An empty pool removes the selector.
+func (c *Client) PatchDeploymentNodePool(ctx context.Context, namespace, name, pool string) error {
+	if c == nil || c.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	var value any
+	if pool != "" {
+		value = pool
+	}
+	patch := map[string]any{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+		"nodeSelector": map[string]any{LabelK4xNodePool: value},
+	}}}}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("marshal patch: %w", err)
+	}
+	if _, err := c.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, body, metav1.PatchOptions{FieldManager: RescheduleFieldManager}); err != nil {
+		return fmt.Errorf("patch deployment %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
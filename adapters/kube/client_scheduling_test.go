@@ -0,0 +1,84 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodSchedulingFailures(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"app": "app1"}
+	now := time.Now()
+	pending := func(name string, conds ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending, Conditions: conds},
+		}
+	}
+	event := func(name, pod, reason, msg string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "ns"},
+			Reason:         reason,
+			Message:        msg,
+			LastTimestamp:  metav1.Time{Time: at},
+		}
+	}
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(
+		pending("p1"),
+		pending("p2", corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable, Message: "0/3 nodes are available"}),
+		pending("p3"),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p4", Namespace: "ns", Labels: labels}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		event("e1", "p1", "FailedScheduling", "old", now.Add(-time.Minute)),
+		event("e2", "p1", "FailedScheduling", "insufficient cpu", now),
+		event("e3", "p3", "Scheduled", "assigned", now),
+	)}
+
+	failures, err := client.PodSchedulingFailures(ctx, "ns", "app=app1")
+	if err != nil {
+		t.Fatalf("PodSchedulingFailures: %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range failures {
+		got[f.Pod] = f.Message
+	}
+	if len(got) != 2 || got["p1"] != "insufficient cpu" || got["p2"] != "0/3 nodes are available" {
+		t.Errorf("failures = %+v", failures)
+	}
+}
+
+func TestPatchDeploymentNodePool(t *testing.T) {
+	ctx := context.Background()
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "ns"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{kube.LabelK4xNodeZone: "1"},
+		}}},
+	})}
+
+	if pool, err := client.DeploymentNodePool(ctx, "ns", "app1"); err != nil || pool != "" {
+		t.Fatalf("initial pool = %q, %v", pool, err)
+	}
+	if err := client.PatchDeploymentNodePool(ctx, "ns", "app1", "user"); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	dep, _ := client.Clientset.AppsV1().Deployments("ns").Get(ctx, "app1", metav1.GetOptions{})
+	sel := dep.Spec.Template.Spec.NodeSelector
+	if sel[kube.LabelK4xNodePool] != "user" || sel[kube.LabelK4xNodeZone] != "1" {
+		t.Errorf("nodeSelector after patch = %v", sel)
+	}
+	if err := client.PatchDeploymentNodePool(ctx, "ns", "app1", ""); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	dep, _ = client.Clientset.AppsV1().Deployments("ns").Get(ctx, "app1", metav1.GetOptions{})
+	if _, ok := dep.Spec.Template.Spec.NodeSelector[kube.LabelK4xNodePool]; ok {
+		t.Errorf("nodeSelector after reset = %v", dep.Spec.Template.Spec.NodeSelector)
+	}
+}
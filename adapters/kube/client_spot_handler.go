@@ -0,0 +1,190 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// SpotHandlerName names the Deployment, ServiceAccount and RBAC objects of the Spot eviction helper.
+	SpotHandlerName = "kompox-spot-handler"
+	// DefaultSpotHandlerImage is the image providing kompoxops used when the cluster does not set one.
+	DefaultSpotHandlerImage = "ghcr.io/kompox/kompox/box:latest"
+)
+
+// SpotHandlerPools returns the node pools watched by the Spot eviction helper: the configured
+// spot pools, or the declared node pools with spot priority.
+func SpotHandlerPools(cluster *model.Cluster) []string {
+	if cluster == nil || cluster.SpotHandler == nil {
+		return nil
+	}
+	if len(cluster.SpotHandler.SpotPools) > 0 {
+		return slices.Clone(cluster.SpotHandler.SpotPools)
+	}
+	var pools []string
+	for _, p := range cluster.NodePools {
+		if p.Name != nil && p.Priority != nil && strings.EqualFold(*p.Priority, "spot") {
+			pools = append(pools, *p.Name)
+		}
+	}
+	return pools
+}
+
+// ValidateSpotFallback rejects app deployments that may run on a Spot pool watched by the
+// cluster Spot eviction helper but restrict their pools with a node affinity (pools or pool
+// preferences) that excludes the fallback pool: the helper pins evicted Deployments to the
+// fallback pool with a nodeSelector, which the affinity would make unschedulable.
+func ValidateSpotFallback(cluster *model.Cluster, deployment model.AppDeployment) error {
+	if cluster == nil || cluster.SpotHandler == nil || cluster.SpotHandler.FallbackPool == "" {
+		return nil
+	}
+	if len(deployment.Pools) == 0 && len(deployment.PoolPreferences) == 0 {
+		return nil
+	}
+	targets := deployment.TargetPools()
+	fallback := cluster.SpotHandler.FallbackPool
+	if slices.Contains(targets, fallback) {
+		return nil
+	}
+	for _, pool := range SpotHandlerPools(cluster) {
+		if slices.Contains(targets, pool) {
+			return fmt.Errorf("deployment pools %v include spot pool %q but not the spot handler fallback pool %q", targets, pool, fallback)
+		}
+	}
+	return nil
+}
+
+// SpotHandlerObjects returns the resources of the Spot eviction helper in the ingress namespace:
+// ServiceAccount, ClusterRole, ClusterRoleBinding and a Deployment running
+// "kompoxops admin spot-handler" on the fallback pool.
+func SpotHandlerObjects(cluster *model.Cluster) ([]runtime.Object, error) {
+	if cluster == nil || cluster.SpotHandler == nil {
+		return nil, fmt.Errorf("spot handler is not configured")
+	}
+	sh := cluster.SpotHandler
+	pools := SpotHandlerPools(cluster)
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("spot handler has no spot pools (set spotHandler.spotPools or declare nodePools with priority spot)")
+	}
+	if sh.FallbackPool == "" {
+		return nil, fmt.Errorf("spot handler fallback pool is required")
+	}
+	image := sh.Image
+	if image == "" {
+		image = DefaultSpotHandlerImage
+	}
+	ns := IngressNamespace(cluster)
+	labels := map[string]string{
+		LabelAppK8sName:      SpotHandlerName,
+		LabelAppK8sManagedBy: "kompox",
+	}
+	meta := metav1.ObjectMeta{Name: SpotHandlerName, Labels: labels}
+	nsMeta := *meta.DeepCopy()
+	nsMeta.Namespace = ns
+
+	automount := true
+	replicas := int32(1)
+	role := &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+		ObjectMeta: meta,
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "delete"}},
+			{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims"}, Verbs: []string{"get"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"get"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "patch"}},
+			{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"volumeattachments"}, Verbs: []string{"list", "delete"}},
+		},
+	}
+	binding := &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+		ObjectMeta: meta,
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: SpotHandlerName},
+		Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: SpotHandlerName, Namespace: ns}},
+	}
+	sa := &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: nsMeta,
+	}
+	dep := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: nsMeta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName:           SpotHandlerName,
+					AutomountServiceAccountToken: &automount,
+					// Run on the fallback pool so that the helper is not evicted with the Spot nodes.
+					NodeSelector: map[string]string{LabelK4xNodePool: sh.FallbackPool},
+					Containers: []corev1.Container{{
+						Name:    "spot-handler",
+						Image:   image,
+						Command: []string{"kompoxops"},
+						Args: []string{
+							"--log-output", "-",
+							"admin", "spot-handler",
+							"--spot-pools", strings.Join(pools, ","),
+							"--fallback-pool", sh.FallbackPool,
+						},
+						Env:        []corev1.EnvVar{{Name: "KOMPOX_ROOT", Value: "/tmp"}},
+						WorkingDir: "/tmp",
+					}},
+				},
+			},
+		},
+	}
+	return []runtime.Object{sa, role, binding, dep}, nil
+}
+
+// InstallSpotHandler applies the Spot eviction helper resources (idempotent).
+func (c *Client) InstallSpotHandler(ctx context.Context, cluster *model.Cluster) error {
+	objs, err := SpotHandlerObjects(cluster)
+	if err != nil {
+		return err
+	}
+	if err := c.ApplyObjects(ctx, objs, &ApplyOptions{DefaultNamespace: IngressNamespace(cluster), ForceConflicts: true}); err != nil {
+		return fmt.Errorf("apply spot handler: %w", err)
+	}
+	return nil
+}
+
+// UninstallSpotHandler deletes the Spot eviction helper resources. Missing resources are ignored.
+func (c *Client) UninstallSpotHandler(ctx context.Context, cluster *model.Cluster) error {
+	if c == nil || c.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	ns := IngressNamespace(cluster)
+	ignore := func(err error) error {
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := ignore(c.Clientset.AppsV1().Deployments(ns).Delete(ctx, SpotHandlerName, metav1.DeleteOptions{})); err != nil {
+		return fmt.Errorf("delete spot handler deployment: %w", err)
+	}
+	if err := ignore(c.Clientset.RbacV1().ClusterRoleBindings().Delete(ctx, SpotHandlerName, metav1.DeleteOptions{})); err != nil {
+		return fmt.Errorf("delete spot handler clusterrolebinding: %w", err)
+	}
+	if err := ignore(c.Clientset.RbacV1().ClusterRoles().Delete(ctx, SpotHandlerName, metav1.DeleteOptions{})); err != nil {
+		return fmt.Errorf("delete spot handler clusterrole: %w", err)
+	}
+	if err := ignore(c.Clientset.CoreV1().ServiceAccounts(ns).Delete(ctx, SpotHandlerName, metav1.DeleteOptions{})); err != nil {
+		return fmt.Errorf("delete spot handler serviceaccount: %w", err)
+	}
+	return nil
+}
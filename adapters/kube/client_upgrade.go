@@ -0,0 +1,263 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// UpgradeFieldManager is the field manager used when cluster upgrade cordons nodes and restarts deployments.
+const UpgradeFieldManager = "kompoxops-upgrade"
+
+// AnnotationRestartedAt is the pod template annotation set to restart a deployment (as kubectl rollout restart does).
+const AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
+// Estimates used by EstimateDowntime. They are rough figures meant for planning maintenance
+// windows: image pull and readiness of a typical app, and the detach/attach of a managed disk.
+const (
+	downtimePodStart       = 30 * time.Second
+	downtimeDiskReattach   = 90 * time.Second
+	defaultTerminationWait = 30 * time.Second
+)
+
+// PoolWorkload is a Kompox Deployment with pods on the nodes of a node pool, with the downtime
+// expected when the pods move to other nodes.
+type PoolWorkload struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+	Replicas   int32  `json:"replicas"`
+	Strategy   string `json:"strategy"`
+	// RWOClaims are the ReadWriteOnce PVCs of the pods. Their disks must detach before a pod on another node can start.
+	RWOClaims []string `json:"rwo_claims,omitempty"`
+	// ExpectedDowntime is the estimated unavailability while the pods move ("0s" when replicas stay available).
+	ExpectedDowntime string `json:"expected_downtime"`
+	// Reason explains the estimate.
+	Reason string `json:"reason"`
+
+	downtime time.Duration
+}
+
+// Disruptive reports whether moving the workload makes the app unavailable.
+func (w PoolWorkload) Disruptive() bool { return w.downtime > 0 }
+
+// Downtime returns the estimated unavailability while the pods move.
+func (w PoolWorkload) Downtime() time.Duration { return w.downtime }
+
+// EstimateDowntime returns the expected unavailability of a deployment whose pods are moved to
+// another node. Deployments keeping other replicas available through a rolling update have none.
+// Single replicas and Recreate deployments are down for the termination grace period and the pod
+// start, plus the disk reattach when they mount ReadWriteOnce volumes.
+func EstimateDowntime(dep *appsv1.Deployment, rwo bool) (time.Duration, string) {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	recreate := dep.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType
+	if replicas > 1 && !recreate && !rwo {
+		return 0, "rolling update keeps other replicas available"
+	}
+
+	var reasons []string
+	if replicas <= 1 {
+		reasons = append(reasons, "single replica")
+	}
+	if recreate {
+		reasons = append(reasons, "Recreate strategy")
+	}
+	d := defaultTerminationWait
+	if s := dep.Spec.Template.Spec.TerminationGracePeriodSeconds; s != nil {
+		d = time.Duration(*s) * time.Second
+	}
+	d += downtimePodStart
+	if rwo {
+		reasons = append(reasons, "RWO disk reattach")
+		d += downtimeDiskReattach
+	}
+	return d, strings.Join(reasons, ", ")
+}
+
+// NodePoolWorkloads returns the Kompox Deployments with running pods on the nodes of a node
+// pool selected by the node labels in selector, sorted by namespace and name.
+func (c *Client) NodePoolWorkloads(ctx context.Context, selector map[string]string) ([]PoolWorkload, error) {
+	if c == nil || c.Clientset == nil {
+		return nil, fmt.Errorf("kube client is not initialized")
+	}
+	cs := c.Clientset
+	nodes, err := c.poolNodes(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	onPool := map[string]bool{}
+	for _, n := range nodes {
+		onPool[n.Name] = true
+	}
+	if len(onPool) == 0 {
+		return nil, nil
+	}
+	pods, err := cs.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: LabelAppK8sManagedBy + "=kompox"})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	claims := map[types.NamespacedName][]string{}
+	var keys []types.NamespacedName
+	for _, pod := range pods.Items {
+		if !onPool[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		dep, err := c.podDeployment(ctx, &pod)
+		if err != nil {
+			return nil, err
+		}
+		if dep == "" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: dep}
+		if _, ok := claims[key]; !ok {
+			keys = append(keys, key)
+			claims[key] = nil
+		}
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil && !slices.Contains(claims[key], v.PersistentVolumeClaim.ClaimName) {
+				claims[key] = append(claims[key], v.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	slices.SortFunc(keys, func(a, b types.NamespacedName) int { return strings.Compare(a.String(), b.String()) })
+
+	var out []PoolWorkload
+	for _, key := range keys {
+		dep, err := cs.AppsV1().Deployments(key.Namespace).Get(ctx, key.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("get deployment %s: %w", key, err)
+		}
+		w := PoolWorkload{Namespace: key.Namespace, Deployment: key.Name, Replicas: 1, Strategy: string(dep.Spec.Strategy.Type)}
+		if dep.Spec.Replicas != nil {
+			w.Replicas = *dep.Spec.Replicas
+		}
+		for _, name := range claims[key] {
+			pvc, err := cs.CoreV1().PersistentVolumeClaims(key.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("get pvc %s/%s: %w", key.Namespace, name, err)
+			}
+			if slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteOnce) || slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteOncePod) {
+				w.RWOClaims = append(w.RWOClaims, name)
+			}
+		}
+		w.downtime, w.Reason = EstimateDowntime(dep, len(w.RWOClaims) > 0)
+		w.ExpectedDowntime = w.downtime.String()
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// CordonNodePool marks the nodes of the node pool selected by the node labels in selector
+// unschedulable (or schedulable again when cordon is false) and returns their names.
+func (c *Client) CordonNodePool(ctx context.Context, selector map[string]string, cordon bool) ([]string, error) {
+	if c == nil || c.Clientset == nil {
+		return nil, fmt.Errorf("kube client is not initialized")
+	}
+	nodes, err := c.poolNodes(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if cordon {
+		value = true
+	}
+	body, err := json.Marshal(map[string]any{"spec": map[string]any{"unschedulable": value}})
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch: %w", err)
+	}
+	var names []string
+	for _, n := range nodes {
+		names = append(names, n.Name)
+		if n.Spec.Unschedulable == cordon {
+			continue
+		}
+		if _, err := c.Clientset.CoreV1().Nodes().Patch(ctx, n.Name, types.MergePatchType, body, metav1.PatchOptions{FieldManager: UpgradeFieldManager}); err != nil {
+			return nil, fmt.Errorf("patch node %s: %w", n.Name, err)
+		}
+	}
+	return names, nil
+}
+
+// poolNodes lists the nodes matching all labels in selector. An empty selector is rejected
+// so that a missing pool selector never matches every node of the cluster.
+func (c *Client) poolNodes(ctx context.Context, selector map[string]string) ([]corev1.Node, error) {
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("node pool selector is empty")
+	}
+	sel := labels.SelectorFromSet(selector).String()
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: sel})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes %s: %w", sel, err)
+	}
+	return nodes.Items, nil
+}
+
+// RestartDeployment triggers a rollout of the deployment by stamping the pod template with
+// kubectl.kubernetes.io/restartedAt, the same way as kubectl rollout restart.
+func (c *Client) RestartDeployment(ctx context.Context, namespace, name string, at time.Time) error {
+	if c == nil || c.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	patch := map[string]any{"spec": map[string]any{"template": map[string]any{"metadata": map[string]any{
+		"annotations": map[string]any{AnnotationRestartedAt: at.UTC().Format(time.RFC3339)},
+	}}}}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("marshal patch: %w", err)
+	}
+	if _, err := c.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, body, metav1.PatchOptions{FieldManager: UpgradeFieldManager}); err != nil {
+		return fmt.Errorf("patch deployment %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// WaitDeploymentAvailable waits up to timeout until the latest rollout of the deployment has
+// all replicas updated and available.
+func (c *Client) WaitDeploymentAvailable(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	if c == nil || c.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		dep, err := c.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("get deployment %s/%s: %w", namespace, name, err)
+		}
+		want := int32(1)
+		if dep.Spec.Replicas != nil {
+			want = *dep.Spec.Replicas
+		}
+		st := dep.Status
+		if st.ObservedGeneration >= dep.Generation && st.UpdatedReplicas == want && st.AvailableReplicas == want && st.Replicas == want {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("deployment %s/%s not available after %s (%d/%d updated, %d available)", namespace, name, timeout, st.UpdatedReplicas, want, st.AvailableReplicas)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEstimateDowntime(t *testing.T) {
+	replicas := func(n int32) *int32 { return &n }
+	grace := int64(10)
+	tests := []struct {
+		name string
+		dep  appsv1.Deployment
+		rwo  bool
+		want time.Duration
+	}{
+		{name: "rolling multi replica", dep: appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(2)}}},
+		{name: "single replica", dep: appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(1)}}, want: time.Minute},
+		{name: "recreate with rwo", rwo: true, dep: appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{TerminationGracePeriodSeconds: &grace}},
+		}}, want: 130 * time.Second},
+		{name: "multi replica with rwo", rwo: true, dep: appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(3)}}, want: 150 * time.Second},
+	}
+	for _, tt := range tests {
+		got, reason := kube.EstimateDowntime(&tt.dep, tt.rwo)
+		if got != tt.want || reason == "" {
+			t.Errorf("%s: downtime = %s (%q), want %s", tt.name, got, reason, tt.want)
+		}
+	}
+}
+
+func TestNodePoolWorkloadsAndCordon(t *testing.T) {
+	ctx := context.Background()
+	ctrl := true
+	one := int32(1)
+	// AKS-shaped nodes: several agent pools share one kompox.dev/node-pool value.
+	node := func(name, agentPool, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			"kubernetes.azure.com/agentpool": agentPool, kube.LabelK4xNodePool: pool,
+		}}}
+	}
+	npuser1 := map[string]string{"kubernetes.azure.com/agentpool": "npuser1"}
+	pod := func(name, nodeName, rs string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1", Labels: map[string]string{kube.LabelAppK8sManagedBy: "kompox"},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs, Controller: &ctrl}}},
+			Spec: corev1.PodSpec{NodeName: nodeName, Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-" + rs},
+			}}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	rs := func(name, dep string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: dep, Controller: &ctrl}}}}
+	}
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(
+		node("user-0", "npuser1", "user"), node("user-1", "npuser1", "user"), node("user-2", "npuser2", "user"),
+		node("system-0", "npsystem", "system"),
+		pod("app1-0", "user-0", "app1-rs"), pod("app2-0", "system-0", "app2-rs"), pod("app3-0", "user-2", "app3-rs"),
+		rs("app1-rs", "app1-app"), rs("app2-rs", "app2-app"), rs("app3-rs", "app3-app"),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app1-app", Namespace: "ns1"}, Spec: appsv1.DeploymentSpec{
+			Replicas: &one, Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app2-app", Namespace: "ns1"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-app1-rs", Namespace: "ns1"},
+			Spec: corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}}},
+	)}
+
+	ws, err := client.NodePoolWorkloads(ctx, npuser1)
+	if err != nil {
+		t.Fatalf("NodePoolWorkloads: %v", err)
+	}
+	if len(ws) != 1 || ws[0].Deployment != "app1-app" || len(ws[0].RWOClaims) != 1 || !ws[0].Disruptive() {
+		t.Fatalf("workloads = %+v", ws)
+	}
+
+	if _, err := client.NodePoolWorkloads(ctx, nil); err == nil {
+		t.Error("expected error for empty node pool selector")
+	}
+
+	names, err := client.CordonNodePool(ctx, npuser1, true)
+	if err != nil || len(names) != 2 {
+		t.Fatalf("CordonNodePool = %v, %v", names, err)
+	}
+	n, _ := client.Clientset.CoreV1().Nodes().Get(ctx, "user-0", metav1.GetOptions{})
+	if !n.Spec.Unschedulable {
+		t.Error("user-0 not cordoned")
+	}
+	for _, name := range []string{"system-0", "user-2"} {
+		if n, _ := client.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); n.Spec.Unschedulable {
+			t.Errorf("%s must not be cordoned", name)
+		}
+	}
+	if _, err := client.CordonNodePool(ctx, npuser1, false); err != nil {
+		t.Fatalf("uncordon: %v", err)
+	}
+	if n, _ := client.Clientset.CoreV1().Nodes().Get(ctx, "user-1", metav1.GetOptions{}); n.Spec.Unschedulable {
+		t.Error("user-1 still cordoned")
+	}
+
+	if err := client.RestartDeployment(ctx, "ns1", "app1-app", time.Unix(0, 0)); err != nil {
+		t.Fatalf("RestartDeployment: %v", err)
+	}
+	dep, _ := client.Clientset.AppsV1().Deployments("ns1").Get(ctx, "app1-app", metav1.GetOptions{})
+	if dep.Spec.Template.Annotations[kube.AnnotationRestartedAt] != "1970-01-01T00:00:00Z" {
+		t.Errorf("restartedAt = %v", dep.Spec.Template.Annotations)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -81,6 +82,7 @@ type Converter struct {
 	K8sSecrets          []*corev1.Secret    // generated from compose env_file (service order)
 	K8sConfigMaps       []*corev1.ConfigMap // generated from compose configs
 	K8sConfigSecrets    []*corev1.Secret    // generated from compose secrets
+	K8sRegistrySecret   *corev1.Secret      // image pull secret from cluster registries (see BindRegistryCredentials)
 
 	// Config/Secret mount metadata (collected during Convert, consumed by Build for volume definitions)
 	configMapMounts    map[string]*configMapMount    // keyed by configName
@@ -92,11 +94,17 @@ type Converter struct {
 	K8sRole           *rbacv1.Role
 	K8sRoleBinding    *rbacv1.RoleBinding
 
+	// K8sWorkloadServiceAccount is the ServiceAccount the pods run as (see BindWorkloadIdentity).
+	K8sWorkloadServiceAccount *corev1.ServiceAccount
+
 	// Bound storage state
 	VolumeBindings []*ConverterVolumeBinding // input bindings (app.Volumes order), updated in-place with chosen resource names
 	K8sPVs         []runtime.Object          // generated PVs
 	K8sPVCs        []runtime.Object          // generated PVCs
 
+	// WorkloadIdentity is the cloud identity bound to the workload ServiceAccount (see BindWorkloadIdentity).
+	WorkloadIdentity *model.WorkloadIdentity
+
 	// Non-fatal notes during planning
 	warnings []string
 }
@@ -192,11 +200,63 @@ func NewConverter(svc *model.Workspace, prv *model.Provider, cls *model.Cluster,
 	return c
 }
 
+// PinZone restricts pod scheduling to nodes of zone, replacing the zone(s) of the app
+// deployment. Pools are kept. It must be called before Build.
+func (c *Converter) PinZone(zone string) {
+	if c.App == nil {
+		return
+	}
+	deployment := c.App.Deployment
+	deployment.Zone, deployment.Zones = zone, nil
+	c.NodeSelector, c.NodeAffinity = buildNodeScheduling(deployment)
+}
+
+// ServiceAccountName returns the name of the diagnostics ServiceAccount in Namespace, which
+// is bound to the app access Role (exec, attach, port-forward, debug containers).
+func (c *Converter) ServiceAccountName() string {
+	return c.App.Name
+}
+
+// WorkloadServiceAccountName returns the name of the ServiceAccount the pods run as when a
+// workload identity is bound. Providers federate the identity with this ServiceAccount.
+func (c *Converter) WorkloadServiceAccountName() string {
+	return ServiceAccountWorkloadName(c.App.Name)
+}
+
+// BindWorkloadIdentity binds the cloud identity to a dedicated workload ServiceAccount that
+// carries the provider annotations and has no RoleBinding, so the pods never hold the
+// diagnostics permissions. The pods run as that ServiceAccount with the provider labels and
+// without an auto-mounted API token. It must be called after Convert and before Build.
+func (c *Converter) BindWorkloadIdentity(wi *model.WorkloadIdentity) error {
+	if c.K8sServiceAccount == nil {
+		return fmt.Errorf("convert must be called before binding workload identity")
+	}
+	if wi == nil {
+		c.K8sWorkloadServiceAccount, c.WorkloadIdentity = nil, nil
+		return nil
+	}
+	var annotations map[string]string
+	if len(wi.ServiceAccountAnnotations) > 0 {
+		annotations = maps.Clone(wi.ServiceAccountAnnotations)
+	}
+	c.K8sWorkloadServiceAccount = &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.WorkloadServiceAccountName(),
+			Namespace:   c.K8sServiceAccount.Namespace,
+			Labels:      c.K8sServiceAccount.Labels,
+			Annotations: annotations,
+		},
+		AutomountServiceAccountToken: ptr.To(false),
+	}
+	c.WorkloadIdentity = wi
+	return nil
+}
+
 func buildNodeScheduling(deployment model.AppDeployment) (map[string]string, *corev1.NodeAffinity) {
 	nodeSelector := map[string]string{}
 
-	// Keep compatibility default: pool=user unless pools is explicitly used.
-	if len(deployment.Pools) == 0 {
+	// Keep compatibility default: pool=user unless pools or pool preferences are used.
+	if len(deployment.Pools) == 0 && len(deployment.PoolPreferences) == 0 {
 		pool := "user"
 		if deployment.Pool != "" {
 			pool = deployment.Pool
@@ -208,6 +268,7 @@ func buildNodeScheduling(deployment model.AppDeployment) (map[string]string, *co
 	}
 
 	var exprs []corev1.NodeSelectorRequirement
+	var preferred []corev1.PreferredSchedulingTerm
 	if len(deployment.Pools) > 0 {
 		exprs = append(exprs, corev1.NodeSelectorRequirement{
 			Key:      LabelK4xNodePool,
@@ -215,6 +276,23 @@ func buildNodeScheduling(deployment model.AppDeployment) (map[string]string, *co
 			Values:   deployment.Pools,
 		})
 	}
+	if len(deployment.PoolPreferences) > 0 {
+		exprs = append(exprs, corev1.NodeSelectorRequirement{
+			Key:      LabelK4xNodePool,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   deployment.TargetPools(),
+		})
+		for i, p := range deployment.PoolPreferences {
+			preferred = append(preferred, corev1.PreferredSchedulingTerm{
+				Weight: int32(deployment.PoolPreferenceWeight(i)),
+				Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      LabelK4xNodePool,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{p.Pool},
+				}}},
+			})
+		}
+	}
 	if len(deployment.Zones) > 0 {
 		exprs = append(exprs, corev1.NodeSelectorRequirement{
 			Key:      LabelK4xNodeZone,
@@ -232,6 +310,7 @@ func buildNodeScheduling(deployment model.AppDeployment) (map[string]string, *co
 				MatchExpressions: exprs,
 			}},
 		},
+		PreferredDuringSchedulingIgnoredDuringExecution: preferred,
 	}
 }
 
@@ -300,6 +379,9 @@ func (c *Converter) Convert(ctx context.Context) ([]string, error) {
 	if c == nil || c.Svc == nil || c.Prv == nil || c.Cls == nil || c.App == nil {
 		return nil, fmt.Errorf("converter requires svc/prv/cls/app")
 	}
+	if err := ValidateSpotFallback(c.Cls, c.App.Deployment); err != nil {
+		return nil, err
+	}
 
 	// Use RefBase-aware compose loading
 	proj, workingDir, err := NewComposeProject(ctx, c.App.Compose, c.App.RefBase)
@@ -857,7 +939,7 @@ func (c *Converter) Convert(ctx context.Context) ([]string, error) {
 	}
 
 	// Build ServiceAccount/Role/RoleBinding for human users (diagnostics)
-	saName := c.App.Name
+	saName := c.ServiceAccountName()
 	roleName := fmt.Sprintf("%s-access", c.App.Name)
 	var saObj *corev1.ServiceAccount
 	var roleObj *rbacv1.Role
@@ -1170,34 +1252,53 @@ func (c *Converter) BindVolumes(ctx context.Context, vols []*ConverterVolumeBind
 		if vc.VolumeMode == "Block" {
 			volMode = corev1.PersistentVolumeBlock
 		}
-		csiDriver := strings.TrimSpace(vc.CSIDriver)
-		if csiDriver == "" {
-			return fmt.Errorf("no CSIDriver for volume %s", av.Name)
-		}
-
-		// CSI attributes from VolumeClass
-		attrs := map[string]string{}
-		for k, v := range vc.Attributes {
-			if v != "" {
-				attrs[k] = v
-			}
-		}
-		if vc.FSType != "" {
-			attrs["fsType"] = vc.FSType
-		}
-
 		pvSpec := corev1.PersistentVolumeSpec{
 			AccessModes:                   accessModes,
 			PersistentVolumeReclaimPolicy: reclaim,
 			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: sizeQty},
 			VolumeMode:                    ptr.To(volMode),
-			PersistentVolumeSource: corev1.PersistentVolumeSource{
+		}
+		if vc.NodeLocal {
+			// Node-local directory pinned to its node
+			node, dir, err := ParseLocalVolumeHandle(handle)
+			if err != nil {
+				return fmt.Errorf("volume %s: %w", av.Name, err)
+			}
+			pvSpec.PersistentVolumeSource = corev1.PersistentVolumeSource{
+				Local: &corev1.LocalVolumeSource{Path: dir},
+			}
+			pvSpec.NodeAffinity = &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      labelNodeHostname,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{node},
+					}},
+				}}},
+			}
+		} else {
+			csiDriver := strings.TrimSpace(vc.CSIDriver)
+			if csiDriver == "" {
+				return fmt.Errorf("no CSIDriver for volume %s", av.Name)
+			}
+
+			// CSI attributes from VolumeClass
+			attrs := map[string]string{}
+			for k, v := range vc.Attributes {
+				if v != "" {
+					attrs[k] = v
+				}
+			}
+			if vc.FSType != "" {
+				attrs["fsType"] = vc.FSType
+			}
+			pvSpec.PersistentVolumeSource = corev1.PersistentVolumeSource{
 				CSI: &corev1.CSIPersistentVolumeSource{
 					Driver:           csiDriver,
 					VolumeHandle:     handle,
 					VolumeAttributes: attrs,
 				},
-			},
+			}
 		}
 		if vc.StorageClassName != "" {
 			pvSpec.StorageClassName = vc.StorageClassName
@@ -1241,6 +1342,51 @@ func (c *Converter) BindVolumes(ctx context.Context, vols []*ConverterVolumeBind
 	return nil
 }
 
+// ImageRegistries returns the images of the app containers grouped by registry host
+// (see ImageRegistry). It must be called after Convert.
+func (c *Converter) ImageRegistries() (map[string][]string, error) {
+	registries := map[string][]string{}
+	for _, ctn := range slices.Concat(c.K8sInitContainers, c.K8sContainers) {
+		registry, err := ImageRegistry(ctn.Image)
+		if err != nil {
+			return nil, fmt.Errorf("container %s: %w", ctn.Name, err)
+		}
+		if !slices.Contains(registries[registry], ctn.Image) {
+			registries[registry] = append(registries[registry], ctn.Image)
+		}
+	}
+	return registries, nil
+}
+
+// BindRegistryCredentials stores auths in the registry image pull secret of the app, which
+// the pods reference through imagePullSecrets. The Secret carries a content hash so that
+// rotated credentials restart the pods. It must be called after Convert and before Build.
+func (c *Converter) BindRegistryCredentials(auths []RegistryAuth) error {
+	if c.K8sNamespace == nil {
+		return fmt.Errorf("convert must be called before binding registry credentials")
+	}
+	if len(auths) == 0 {
+		c.K8sRegistrySecret = nil
+		return nil
+	}
+	doc, err := DockerConfigJSON(auths)
+	if err != nil {
+		return err
+	}
+	kv := map[string]string{corev1.DockerConfigJsonKey: string(doc)}
+	c.K8sRegistrySecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        SecretRegistryName(c.App.Name, c.ComponentName),
+			Namespace:   c.Namespace,
+			Labels:      c.ComponentLabels,
+			Annotations: map[string]string{AnnotationK4xComposeContentHash: ComputeContentHash(kv)},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: doc},
+	}
+	return nil
+}
+
 // Build composes the final Deployment using this plan and current bindings, stores it, and returns warnings.
 // To retrieve full object lists, use NamespaceObjects/VolumeObjects/DeploymentObjects/AllObjects.
 func (c *Converter) Build() ([]string, error) {
@@ -1328,6 +1474,16 @@ func (c *Converter) Build() ([]string, error) {
 	if affinity != nil {
 		podSpec.Affinity = &corev1.Affinity{NodeAffinity: affinity}
 	}
+	if c.K8sRegistrySecret != nil {
+		podSpec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: c.K8sRegistrySecret.Name}}
+	}
+	podLabels := c.ComponentLabels
+	if wi := c.WorkloadIdentity; wi != nil {
+		podSpec.ServiceAccountName = c.WorkloadServiceAccountName()
+		podSpec.AutomountServiceAccountToken = ptr.To(false)
+		podLabels = maps.Clone(c.ComponentLabels)
+		maps.Copy(podLabels, wi.PodLabels)
+	}
 
 	// Deployment (single replica, Recreate)
 	dep := &appsv1.Deployment{
@@ -1337,7 +1493,7 @@ func (c *Converter) Build() ([]string, error) {
 			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
 			Selector: &metav1.LabelSelector{MatchLabels: c.Selector},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: c.ComponentLabels},
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
 				Spec:       podSpec,
 			},
 		},
@@ -1358,6 +1514,9 @@ func (c *Converter) NamespaceObjects() []runtime.Object {
 	if c.K8sServiceAccount != nil {
 		objs = append(objs, c.K8sServiceAccount)
 	}
+	if c.K8sWorkloadServiceAccount != nil {
+		objs = append(objs, c.K8sWorkloadServiceAccount)
+	}
 	if c.K8sRole != nil {
 		objs = append(objs, c.K8sRole)
 	}
@@ -1390,6 +1549,9 @@ func (c *Converter) DeploymentObjects() []runtime.Object {
 	for _, sec := range c.K8sSecrets { // secrets first so Deployment can refer via envFrom in future
 		objs = append(objs, sec)
 	}
+	if c.K8sRegistrySecret != nil {
+		objs = append(objs, c.K8sRegistrySecret)
+	}
 	if c.K8sDeployment != nil {
 		objs = append(objs, c.K8sDeployment)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -1285,9 +1286,187 @@ func TestDeploymentNodeSelector(t *testing.T) {
 		t.Errorf("unexpected zone expression values: %v", zoneExpr.Values)
 	}
 
+	// Test case 5: PinZone replaces deployment zones with a single zone selector and keeps pools
+	c5 := NewConverter(svc, prv, cls, app4, "app")
+	if _, err := c5.Convert(context.Background()); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	c5.PinZone("japaneast-3")
+	if _, err := c5.Build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	podSpec := c5.K8sDeployment.Spec.Template.Spec
+	if podSpec.NodeSelector[LabelK4xNodeZone] != "japaneast-3" {
+		t.Errorf("expected pinned zone selector, got %v", podSpec.NodeSelector)
+	}
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil {
+		t.Fatal("expected pool nodeAffinity to be kept")
+	}
+	exprs := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
+	if len(exprs) != 1 || exprs[0].Key != LabelK4xNodePool {
+		t.Errorf("expected only pool expression, got %+v", exprs)
+	}
+	if len(app4.Deployment.Zones) != 2 {
+		t.Errorf("PinZone must not modify the app: %v", app4.Deployment.Zones)
+	}
+
+	// Test case 6: poolPreferences require one of the pools and prefer them by weight
+	app6 := &model.App{
+		Name:    "app6",
+		Compose: `services: {app: {image: "test"}}`,
+		RefBase: "file://" + cwd + "/",
+		Deployment: model.AppDeployment{
+			PoolPreferences: []model.AppPoolPreference{{Pool: "spot"}, {Pool: "user"}},
+		},
+	}
+	c6 := NewConverter(svc, prv, cls, app6, "app")
+	if _, err := c6.Convert(context.Background()); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if _, err := c6.Build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	podSpec = c6.K8sDeployment.Spec.Template.Spec
+	if _, ok := podSpec.NodeSelector[LabelK4xNodePool]; ok {
+		t.Errorf("expected no direct pool selector with poolPreferences, got %v", podSpec.NodeSelector)
+	}
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil {
+		t.Fatal("expected nodeAffinity with poolPreferences")
+	}
+	exprs = podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
+	if len(exprs) != 1 || exprs[0].Key != LabelK4xNodePool || len(exprs[0].Values) != 2 || exprs[0].Values[0] != "spot" || exprs[0].Values[1] != "user" {
+		t.Errorf("unexpected required pool expression: %+v", exprs)
+	}
+	preferred := podSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(preferred) != 2 || preferred[0].Weight != 100 || preferred[1].Weight != 90 {
+		t.Fatalf("unexpected preferred terms: %+v", preferred)
+	}
+	if v := preferred[1].Preference.MatchExpressions[0].Values; len(v) != 1 || v[0] != "user" {
+		t.Errorf("unexpected preferred pool: %v", v)
+	}
+
 	t.Logf("All deployment nodeSelector tests completed successfully")
 }
 
+// TestConverterBindWorkloadIdentity checks that the identity metadata reaches the ServiceAccount and pods.
+func TestConverterBindWorkloadIdentity(t *testing.T) {
+	cwd, _ := os.Getwd()
+	svc := &model.Workspace{Name: "ops"}
+	prv := &model.Provider{Name: "aks1", Driver: "aks"}
+	cls := &model.Cluster{Name: "cluster1"}
+	app := &model.App{
+		Name:    "app1",
+		Compose: `services: {app: {image: "test"}}`,
+		RefBase: "file://" + cwd + "/",
+	}
+
+	c := NewConverter(svc, prv, cls, app, "app")
+	if err := c.BindWorkloadIdentity(&model.WorkloadIdentity{ClientID: "x"}); err == nil {
+		t.Errorf("expected error binding before convert")
+	}
+	if _, err := c.Convert(context.Background()); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	wi := &model.WorkloadIdentity{
+		ClientID:                  "00000000-0000-0000-0000-000000000001",
+		ServiceAccountAnnotations: map[string]string{"example.com/client-id": "00000000-0000-0000-0000-000000000001"},
+		PodLabels:                 map[string]string{"example.com/use": "true"},
+	}
+	if err := c.BindWorkloadIdentity(wi); err != nil {
+		t.Fatalf("bind failed: %v", err)
+	}
+	if _, err := c.Build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	wsa := c.K8sWorkloadServiceAccount
+	if wsa == nil || wsa.Name != "app1--workload" || wsa.Annotations["example.com/client-id"] != wi.ClientID {
+		t.Fatalf("workload ServiceAccount = %+v", wsa)
+	}
+	if wsa.AutomountServiceAccountToken == nil || *wsa.AutomountServiceAccountToken {
+		t.Errorf("workload ServiceAccount must not auto-mount the API token")
+	}
+	if _, ok := c.K8sServiceAccount.Annotations["example.com/client-id"]; ok {
+		t.Errorf("diagnostics ServiceAccount must not carry the identity annotation")
+	}
+	for _, s := range c.K8sRoleBinding.Subjects {
+		if s.Name == wsa.Name {
+			t.Errorf("workload ServiceAccount must not be bound to the access role")
+		}
+	}
+	if !slices.Contains(c.NamespaceObjects(), runtime.Object(wsa)) {
+		t.Errorf("workload ServiceAccount missing from namespace objects")
+	}
+	tmpl := c.K8sDeployment.Spec.Template
+	if tmpl.Spec.ServiceAccountName != "app1--workload" {
+		t.Errorf("pod serviceAccountName = %q", tmpl.Spec.ServiceAccountName)
+	}
+	if tmpl.Spec.AutomountServiceAccountToken == nil || *tmpl.Spec.AutomountServiceAccountToken {
+		t.Errorf("pod must not auto-mount the API token")
+	}
+	if tmpl.Labels["example.com/use"] != "true" || tmpl.Labels[LabelAppSelector] == "" {
+		t.Errorf("unexpected pod labels: %v", tmpl.Labels)
+	}
+	if _, ok := c.ComponentLabels["example.com/use"]; ok {
+		t.Errorf("pod labels must not leak into component labels")
+	}
+	if _, ok := c.K8sDeployment.Spec.Selector.MatchLabels["example.com/use"]; ok {
+		t.Errorf("pod labels must not change the selector")
+	}
+}
+
+func TestConverterBindRegistryCredentials(t *testing.T) {
+	cwd, _ := os.Getwd()
+	svc := &model.Workspace{Name: "ops"}
+	prv := &model.Provider{Name: "aks1", Driver: "aks"}
+	cls := &model.Cluster{Name: "cluster1"}
+	app := &model.App{
+		Name:    "app1",
+		Compose: `services: {app: {image: "ghcr.io/org/app:1.0"}, cache: {image: "redis:7"}}`,
+		RefBase: "file://" + cwd + "/",
+	}
+
+	c := NewConverter(svc, prv, cls, app, "app")
+	if err := c.BindRegistryCredentials([]RegistryAuth{{Server: "ghcr.io"}}); err == nil {
+		t.Errorf("expected error binding before convert")
+	}
+	if _, err := c.Convert(context.Background()); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	registries, err := c.ImageRegistries()
+	if err != nil {
+		t.Fatalf("image registries: %v", err)
+	}
+	if len(registries) != 2 || len(registries["ghcr.io"]) != 1 || registries["docker.io"][0] != "redis:7" {
+		t.Errorf("unexpected image registries: %v", registries)
+	}
+
+	if err := c.BindRegistryCredentials([]RegistryAuth{{Server: "ghcr.io", Username: "bot", Password: "s3cret"}}); err != nil {
+		t.Fatalf("bind failed: %v", err)
+	}
+	if _, err := c.Build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	sec := c.K8sRegistrySecret
+	if sec == nil || sec.Name != "app1-app--registry" || sec.Type != corev1.SecretTypeDockerConfigJson {
+		t.Fatalf("unexpected registry secret: %+v", sec)
+	}
+	if !strings.Contains(string(sec.Data[corev1.DockerConfigJsonKey]), `"ghcr.io":{"username":"bot"`) || sec.Annotations[AnnotationK4xComposeContentHash] == "" {
+		t.Errorf("unexpected registry secret content: %s %v", sec.Data[corev1.DockerConfigJsonKey], sec.Annotations)
+	}
+	pull := c.K8sDeployment.Spec.Template.Spec.ImagePullSecrets
+	if len(pull) != 1 || pull[0].Name != sec.Name {
+		t.Errorf("imagePullSecrets = %v", pull)
+	}
+	found := false
+	for _, obj := range c.DeploymentObjects() {
+		found = found || obj == sec
+	}
+	if !found {
+		t.Errorf("registry secret missing from deployment objects")
+	}
+}
+
 // TestHeadlessServicesGeneration validates generation details and pruning metadata.
 func TestHeadlessServicesGeneration(t *testing.T) {
 	ctx := context.Background()
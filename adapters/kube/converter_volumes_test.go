@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // TestConverterVolumesSingleFileBind tests that single-file bind volumes are rejected.
@@ -122,3 +123,62 @@ services:
 		})
 	}
 }
+
+// TestConverterBindVolumesNodeLocal tests that node-local volume classes produce local PVs pinned to the node.
+func TestConverterBindVolumesNodeLocal(t *testing.T) {
+	ctx := context.Background()
+
+	cwd, _ := os.Getwd()
+	svc := &model.Workspace{Name: "testsvc"}
+	prv := &model.Provider{Name: "testprv", Driver: "k3s"}
+	cls := &model.Cluster{Name: "testcls"}
+	app := &model.App{
+		Name: "testapp",
+		Compose: `
+services:
+  web:
+    image: nginx:1.20
+    volumes:
+      - data:/var/lib/data
+`,
+		RefBase: "file://" + cwd + "/",
+		Volumes: []model.AppVolume{{Name: "data", Size: 1 << 30}},
+	}
+	vc := &model.VolumeClass{StorageClassName: "kompox-local", AccessModes: []string{"ReadWriteOnce"}, NodeLocal: true}
+
+	c := NewConverter(svc, prv, cls, app, "app")
+	if _, err := c.Convert(ctx); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	handle := LocalVolumeHandle("node-1", "/var/lib/kompox/volumes/testapp/data/d1")
+	if err := c.BindVolumes(ctx, []*ConverterVolumeBinding{
+		{Name: "data", VolumeDisk: &model.VolumeDisk{Handle: handle, Zone: "node-1"}, VolumeClass: vc},
+	}); err != nil {
+		t.Fatalf("BindVolumes failed: %v", err)
+	}
+	if len(c.K8sPVs) != 1 {
+		t.Fatalf("expected 1 PV, got %d", len(c.K8sPVs))
+	}
+	pv := c.K8sPVs[0].(*corev1.PersistentVolume)
+	if pv.Spec.CSI != nil || pv.Spec.Local == nil {
+		t.Fatalf("expected local PV source, got %+v", pv.Spec.PersistentVolumeSource)
+	}
+	if pv.Spec.Local.Path != "/var/lib/kompox/volumes/testapp/data/d1" {
+		t.Errorf("local path = %q", pv.Spec.Local.Path)
+	}
+	terms := pv.Spec.NodeAffinity.Required.NodeSelectorTerms
+	if len(terms) != 1 || terms[0].MatchExpressions[0].Key != "kubernetes.io/hostname" || terms[0].MatchExpressions[0].Values[0] != "node-1" {
+		t.Errorf("unexpected node affinity: %+v", terms)
+	}
+
+	bad := NewConverter(svc, prv, cls, app, "app")
+	if _, err := bad.Convert(ctx); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	err := bad.BindVolumes(ctx, []*ConverterVolumeBinding{
+		{Name: "data", VolumeDisk: &model.VolumeDisk{Handle: "/subscriptions/x/disks/d1"}, VolumeClass: vc},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid local volume handle") {
+		t.Fatalf("expected invalid handle error, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package kube
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DecodeKubeconfig accepts a kubeconfig embedded in settings as raw YAML or base64-encoded YAML.
+func DecodeKubeconfig(v string) []byte {
+	if !strings.Contains(v, "\n") && !strings.Contains(v, ":") {
+		if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+			return b
+		}
+	}
+	return []byte(v)
+}
+
+// RewriteKubeconfig selects contextName (when non-empty), drops other contexts and
+// replaces the server URL of the selected cluster (when server is non-empty).
+func RewriteKubeconfig(data []byte, contextName, server string) ([]byte, error) {
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse kubeconfig: %w", err)
+	}
+	if contextName != "" {
+		if _, ok := cfg.Contexts[contextName]; !ok {
+			return nil, fmt.Errorf("kubeconfig context %q not found", contextName)
+		}
+		cfg.CurrentContext = contextName
+	}
+	if err := clientcmdapi.MinifyConfig(cfg); err != nil {
+		return nil, fmt.Errorf("select kubeconfig context: %w", err)
+	}
+	if server != "" {
+		for _, c := range cfg.Clusters {
+			c.Server = server
+		}
+	}
+	out, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("write kubeconfig: %w", err)
+	}
+	return out, nil
+}
+
+// ExpandHome expands a leading "~/" in path to the user home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,32 @@
+package kube
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// LocalVolumeScheme is the handle scheme of node-local volume disks (model.VolumeClass.NodeLocal).
+const LocalVolumeScheme = "local"
+
+// labelNodeHostname is the well-known node label used to pin node-local PVs.
+const labelNodeHostname = "kubernetes.io/hostname"
+
+// LocalVolumeHandle returns the handle of a node-local directory: local://<node>/<path>.
+func LocalVolumeHandle(node, dir string) string {
+	u := url.URL{Scheme: LocalVolumeScheme, Host: node, Path: path.Clean("/" + dir)}
+	return u.String()
+}
+
+// ParseLocalVolumeHandle parses a local://<node>/<path> handle into node name and absolute path.
+func ParseLocalVolumeHandle(handle string) (node, dir string, err error) {
+	u, err := url.Parse(strings.TrimSpace(handle))
+	if err != nil {
+		return "", "", fmt.Errorf("parse local volume handle %q: %w", handle, err)
+	}
+	if u.Scheme != LocalVolumeScheme || u.Host == "" || u.Path == "" || u.Path == "/" {
+		return "", "", fmt.Errorf("invalid local volume handle %q: expected %s://<node>/<path>", handle, LocalVolumeScheme)
+	}
+	return u.Host, path.Clean(u.Path), nil
+}
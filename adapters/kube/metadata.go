@@ -21,4 +21,9 @@ const (
 	AnnotationK4xApp                = K4xDomain + "/app"
 	AnnotationK4xProviderDriver     = K4xDomain + "/provider-driver"
 	AnnotationK4xComposeContentHash = K4xDomain + "/compose-content-hash"
+	// AnnotationK4xSpotFallbackFrom and AnnotationK4xSpotOriginalPool are set on Deployments the
+	// Spot handler moved to the fallback pool: the evicted Spot pool and the node pool selector
+	// to restore when that pool recovers ("" when no pool was pinned).
+	AnnotationK4xSpotFallbackFrom = K4xDomain + "/spot-fallback-from"
+	AnnotationK4xSpotOriginalPool = K4xDomain + "/spot-original-pool"
 )
@@ -18,6 +18,19 @@ func SecretPullName(appName, componentName string) string {
 	return appName + "-" + componentName + "--pull"
 }
 
+// SecretRegistryName returns `<appName>-<componentName>--registry`.
+// Used for registry auth Secret (kubernetes.io/dockerconfigjson) generated from cluster registries at deploy.
+func SecretRegistryName(appName, componentName string) string {
+	return appName + "-" + componentName + "--registry"
+}
+
+// ServiceAccountWorkloadName returns `<appName>--workload`.
+// Used for the ServiceAccount the app pods run as when a workload identity is bound. It has
+// no RoleBinding, unlike the diagnostics ServiceAccount `<appName>`.
+func ServiceAccountWorkloadName(appName string) string {
+	return appName + "--workload"
+}
+
 // ConfigMapName returns `<appName>-<componentName>--cfg-<configName>`.
 // Used for ConfigMap resource generated from Compose top-level configs.
 func ConfigMapName(appName, componentName, configName string) string {
@@ -0,0 +1,77 @@
+package kube
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// dockerHubServer is the registry host of Docker Hub images ("nginx", "library/nginx", ...).
+const dockerHubServer = "docker.io"
+
+// dockerHubAuthKey is the auths key kubelet matches for Docker Hub images.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// RegistryAuth is a username/password credential for one container registry.
+type RegistryAuth struct {
+	Server   string
+	Username string
+	Password string
+}
+
+// ImageRegistry returns the registry host of a container image reference, normalized by
+// NormalizeRegistryServer. Images without a registry host are Docker Hub images ("docker.io").
+func ImageRegistry(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+	return NormalizeRegistryServer(reference.Domain(named)), nil
+}
+
+// NormalizeRegistryServer returns the lower-cased registry host of server, which may be given
+// as a URL (e.g., "https://index.docker.io/v1/"). Docker Hub aliases map to "docker.io".
+func NormalizeRegistryServer(server string) string {
+	s := strings.ToLower(strings.TrimSpace(server))
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	switch s {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubServer
+	}
+	return s
+}
+
+// DockerConfigJSON returns the kubernetes.io/dockerconfigjson document holding auths.
+func DockerConfigJSON(auths []RegistryAuth) ([]byte, error) {
+	type entry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	doc := struct {
+		Auths map[string]entry `json:"auths"`
+	}{Auths: map[string]entry{}}
+	for _, a := range auths {
+		key := NormalizeRegistryServer(a.Server)
+		if key == "" {
+			return nil, fmt.Errorf("registry server is empty")
+		}
+		if key == dockerHubServer {
+			key = dockerHubAuthKey
+		}
+		doc.Auths[key] = entry{
+			Username: a.Username,
+			Password: a.Password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password)),
+		}
+	}
+	return json.Marshal(doc)
+}
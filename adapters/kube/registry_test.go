@@ -0,0 +1,56 @@
+package kube
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestImageRegistry(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx", "docker.io"},
+		{"library/nginx:1.25", "docker.io"},
+		{"docker.io/org/app", "docker.io"},
+		{"index.docker.io/org/app", "docker.io"},
+		{"ghcr.io/org/app:1.0", "ghcr.io"},
+		{"Registry.example.com:5000/team/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "registry.example.com:5000"},
+		{"localhost/app", "localhost"},
+	}
+	for _, tt := range tests {
+		got, err := ImageRegistry(tt.image)
+		if err != nil {
+			t.Errorf("ImageRegistry(%q): %v", tt.image, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ImageRegistry(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+	if _, err := ImageRegistry("Invalid/Image"); err == nil {
+		t.Errorf("expected error for invalid image reference")
+	}
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	doc, err := DockerConfigJSON([]RegistryAuth{
+		{Server: "ghcr.io", Username: "bot", Password: "token"},
+		{Server: "docker.io", Username: "user", Password: "pass"},
+	})
+	if err != nil {
+		t.Fatalf("DockerConfigJSON: %v", err)
+	}
+	var got struct {
+		Auths map[string]struct{ Username, Password, Auth string }
+	}
+	if err := json.Unmarshal(doc, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if a := got.Auths["ghcr.io"]; a.Username != "bot" || a.Password != "token" || a.Auth != "Ym90OnRva2Vu" {
+		t.Errorf("unexpected ghcr.io auth: %+v", a)
+	}
+	if _, ok := got.Auths["https://index.docker.io/v1/"]; !ok || len(got.Auths) != 2 {
+		t.Errorf("unexpected auths keys: %v", got.Auths)
+	}
+}
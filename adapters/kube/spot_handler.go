@@ -0,0 +1,513 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/kompox/kompox/internal/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// taintOutOfService is the taint an operator or cloud controller puts on a node that is shut
+// down for good (non-graceful node shutdown).
+const taintOutOfService = "node.kubernetes.io/out-of-service"
+
+// spotEvictionConditions are node conditions reported by node problem detectors when the
+// cloud schedules a preemption (e.g. AKS VMEventScheduled for Spot eviction).
+var spotEvictionConditions = []corev1.NodeConditionType{"VMEventScheduled", "PreemptScheduled"}
+
+// SpotEvictionSignal returns a description of the termination signal on a node, or an
+// empty string when the node is not being terminated. Only definite signals count: a
+// scheduled preemption, the out-of-service taint and node deletion. Scale-down taints and
+// transient not-ready/unreachable taints are not signals, since the node may come back
+// with the volumes still attached.
+func SpotEvictionSignal(node *corev1.Node) string {
+	if node == nil {
+		return ""
+	}
+	if node.DeletionTimestamp != nil {
+		return "node deleted"
+	}
+	if slices.ContainsFunc(node.Spec.Taints, func(t corev1.Taint) bool { return t.Key == taintOutOfService }) {
+		return "taint " + taintOutOfService
+	}
+	for _, c := range node.Status.Conditions {
+		if slices.Contains(spotEvictionConditions, c.Type) && c.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("condition %s: %s", c.Type, c.Message)
+		}
+	}
+	return ""
+}
+
+// SpotEviction reports what the handler did for an evicted node.
+type SpotEviction struct {
+	Node   string `json:"node"`
+	Signal string `json:"signal"`
+	// Deployments are the Kompox Deployments (namespace/name) moved to the fallback pool.
+	Deployments []string `json:"deployments,omitempty"`
+	// Pods are the pods stopped on the node (namespace/name).
+	Pods []string `json:"pods,omitempty"`
+	// PersistentVolumes are the volumes of the pods to detach from the node once it is gone.
+	PersistentVolumes []string `json:"persistent_volumes,omitempty"`
+	// VolumeAttachments are the attachments deleted to detach the pod volumes from the node.
+	VolumeAttachments []string `json:"volume_attachments,omitempty"`
+}
+
+// SpotHandler moves Kompox Deployments off Spot nodes that are being evicted. For each
+// evicted node in SpotPools it pins the Deployments of the Kompox pods on the node to
+// FallbackPool and deletes the pods with their termination grace period so that preStop
+// hooks run. Pods still present after StopTimeout are force deleted only when the node is
+// gone or out of service. Once the Node object is deleted, the VolumeAttachments of the pod
+// volumes on the node are deleted so that RWO disks can attach to the new node without
+// waiting for the attach/detach controller timeout. When a node of the Spot pool has been
+// ready for RestoreDelay, the Deployments get back their original node pool selector.
+type SpotHandler struct {
+	Client       *Client
+	SpotPools    []string
+	FallbackPool string
+	// Interval is the node polling interval (default 5s).
+	Interval time.Duration
+	// StopTimeout bounds the wait for pods to terminate gracefully (default 2m).
+	StopTimeout time.Duration
+	// RestoreDelay is how long a Spot node must be ready before Deployments move back (default 5m).
+	RestoreDelay time.Duration
+
+	handled map[types.UID]bool
+	// nodes are the Spot nodes seen by the last poll, to detect deleted nodes.
+	nodes map[string]*corev1.Node
+	// pending are the evictions whose volumes are detached once the node is gone.
+	pending map[string]*SpotEviction
+}
+
+// Run polls the nodes until ctx is done and handles each evicted Spot node once.
+func (h *SpotHandler) Run(ctx context.Context) error {
+	if h.Client == nil || h.Client.Clientset == nil {
+		return fmt.Errorf("kube client is not initialized")
+	}
+	if h.FallbackPool == "" || len(h.SpotPools) == 0 {
+		return fmt.Errorf("spot pools and fallback pool are required")
+	}
+	interval := h.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	logger := logging.FromContext(ctx)
+	logger.Info(ctx, "SpotHandler:Run/s", "spotPools", h.SpotPools, "fallbackPool", h.FallbackPool)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := h.poll(ctx); err != nil {
+			logger.Warn(ctx, "SpotHandler:poll/efail", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll lists the Spot nodes, handles newly evicted or deleted ones, detaches the volumes of
+// deleted nodes and restores Deployments whose Spot pool has recovered.
+func (h *SpotHandler) poll(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	nodes, err := h.Client.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	if h.handled == nil {
+		h.handled = map[types.UID]bool{}
+	}
+	if h.pending == nil {
+		h.pending = map[string]*SpotEviction{}
+	}
+	current := map[string]*corev1.Node{}
+	evicted := map[string]*corev1.Node{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !slices.Contains(h.SpotPools, node.Labels[LabelK4xNodePool]) {
+			continue
+		}
+		current[node.Name] = node
+		if !h.handled[node.UID] && SpotEvictionSignal(node) != "" {
+			evicted[node.Name] = node
+		}
+	}
+	// Nodes deleted between two polls have no signal left on the object.
+	for name, node := range h.nodes {
+		if _, ok := current[name]; !ok && !h.handled[node.UID] {
+			gone := node.DeepCopy()
+			gone.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			evicted[name] = gone
+		}
+	}
+	retry := map[string]*corev1.Node{}
+	for _, node := range evicted {
+		ev, err := h.HandleNode(ctx, node)
+		if err != nil {
+			logger.Warn(ctx, "SpotHandler:HandleNode/efail", "node", node.Name, "err", err)
+			if _, present := current[node.Name]; !present {
+				retry[node.Name] = h.nodes[node.Name]
+			}
+			continue
+		}
+		h.handled[node.UID] = true
+		if _, present := current[node.Name]; present && len(ev.PersistentVolumes) > 0 {
+			h.pending[node.Name] = ev
+		}
+		logger.Info(ctx, "SpotHandler:HandleNode/eok", "node", ev.Node, "signal", ev.Signal, "deployments", ev.Deployments, "pods", ev.Pods, "volumeAttachments", ev.VolumeAttachments)
+	}
+	for name, ev := range h.pending {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		vas, err := h.DetachVolumes(ctx, name, ev.PersistentVolumes)
+		if err != nil {
+			logger.Warn(ctx, "SpotHandler:DetachVolumes/efail", "node", name, "err", err)
+			continue
+		}
+		delete(h.pending, name)
+		logger.Info(ctx, "SpotHandler:DetachVolumes/eok", "node", name, "volumeAttachments", vas)
+	}
+	seen := map[types.UID]bool{}
+	for _, node := range current {
+		seen[node.UID] = true
+	}
+	for uid := range h.handled {
+		if !seen[uid] {
+			delete(h.handled, uid)
+		}
+	}
+	// Deleted nodes that failed to be handled are retried by the next poll.
+	h.nodes = maps.Clone(current)
+	maps.Copy(h.nodes, retry)
+
+	restored, err := h.RestorePools(ctx, slices.Collect(maps.Values(current)))
+	if err != nil {
+		return err
+	}
+	if len(restored) > 0 {
+		logger.Info(ctx, "SpotHandler:RestorePools/eok", "deployments", restored)
+	}
+	return nil
+}
+
+// HandleNode moves the Kompox workloads off an evicted node. The VolumeAttachments of the
+// pod volumes are deleted right away only when the Node object is already gone; otherwise
+// the volumes are returned in PersistentVolumes for DetachVolumes.
+func (h *SpotHandler) HandleNode(ctx context.Context, node *corev1.Node) (*SpotEviction, error) {
+	cs := h.Client.Clientset
+	ev := &SpotEviction{Node: node.Name, Signal: SpotEvictionSignal(node)}
+
+	list, err := cs.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelAppK8sManagedBy + "=kompox",
+		FieldSelector: "spec.nodeName=" + node.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pods on node %s: %w", node.Name, err)
+	}
+	var pods []corev1.Pod
+	var skipped []string
+	claims := map[types.NamespacedName]bool{}
+	for _, pod := range list.Items {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+		dep, err := h.Client.podDeployment(ctx, &pod)
+		if err != nil {
+			return nil, err
+		}
+		if dep == "" {
+			continue
+		}
+		pods = append(pods, pod)
+		if key := pod.Namespace + "/" + dep; !slices.Contains(ev.Deployments, key) && !slices.Contains(skipped, key) {
+			pinned, err := h.pinFallback(ctx, pod.Namespace, dep, node.Labels[LabelK4xNodePool])
+			if err != nil {
+				return nil, err
+			}
+			if pinned {
+				ev.Deployments = append(ev.Deployments, key)
+			} else {
+				skipped = append(skipped, key)
+				logging.FromContext(ctx).Warn(ctx, "SpotHandler:pinFallback/skip", "deployment", key, "fallbackPool", h.FallbackPool, "reason", "node affinity excludes the fallback pool")
+			}
+		}
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				claims[types.NamespacedName{Namespace: pod.Namespace, Name: v.PersistentVolumeClaim.ClaimName}] = true
+			}
+		}
+	}
+
+	// Stop the pods gracefully so that preStop hooks can quiesce the app.
+	for _, pod := range pods {
+		ev.Pods = append(ev.Pods, pod.Namespace+"/"+pod.Name)
+		if err := cs.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	if err := h.waitPodsGone(ctx, node.Name, pods); err != nil {
+		return nil, err
+	}
+
+	for key := range claims {
+		pvc, err := cs.CoreV1().PersistentVolumeClaims(key.Namespace).Get(ctx, key.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("get pvc %s: %w", key, err)
+		}
+		if pvc.Spec.VolumeName != "" && !slices.Contains(ev.PersistentVolumes, pvc.Spec.VolumeName) {
+			ev.PersistentVolumes = append(ev.PersistentVolumes, pvc.Spec.VolumeName)
+		}
+	}
+	slices.Sort(ev.PersistentVolumes)
+	if len(ev.PersistentVolumes) > 0 {
+		gone, err := h.nodeGone(ctx, node.Name)
+		if err != nil {
+			return nil, err
+		}
+		if gone {
+			if ev.VolumeAttachments, err = h.DetachVolumes(ctx, node.Name, ev.PersistentVolumes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ev, nil
+}
+
+// DetachVolumes deletes the VolumeAttachments of the persistent volumes on a node and returns
+// their names. It refuses while the Node object exists, since the node may still have the
+// volumes mounted and a second attachment could corrupt RWO disks.
+func (h *SpotHandler) DetachVolumes(ctx context.Context, nodeName string, pvs []string) ([]string, error) {
+	cs := h.Client.Clientset
+	gone, err := h.nodeGone(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if !gone {
+		return nil, fmt.Errorf("node %s still exists", nodeName)
+	}
+	vas, err := cs.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list volume attachments: %w", err)
+	}
+	var deleted []string
+	for _, va := range vas.Items {
+		pv := va.Spec.Source.PersistentVolumeName
+		if va.Spec.NodeName != nodeName || pv == nil || !slices.Contains(pvs, *pv) {
+			continue
+		}
+		if err := cs.StorageV1().VolumeAttachments().Delete(ctx, va.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("delete volume attachment %s: %w", va.Name, err)
+		}
+		deleted = append(deleted, va.Name)
+	}
+	return deleted, nil
+}
+
+// RestorePools moves Deployments pinned by the handler back to their original node pool
+// selector when their Spot pool has a node that has been ready for RestoreDelay without an
+// eviction signal. nodes are the current Spot nodes. Deployments whose selector was changed
+// after the move keep it and only lose the handler annotations. It returns the restored
+// Deployments (namespace/name).
+func (h *SpotHandler) RestorePools(ctx context.Context, nodes []*corev1.Node) ([]string, error) {
+	delay := h.RestoreDelay
+	if delay <= 0 {
+		delay = 5 * time.Minute
+	}
+	recovered := map[string]bool{}
+	for _, n := range nodes {
+		if spotNodeReadySince(n, time.Now().Add(-delay)) {
+			recovered[n.Labels[LabelK4xNodePool]] = true
+		}
+	}
+	if len(recovered) == 0 {
+		return nil, nil
+	}
+	cs := h.Client.Clientset
+	deps, err := cs.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: LabelAppK8sManagedBy + "=kompox"})
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+	var restored []string
+	for _, dep := range deps.Items {
+		from, ok := dep.Annotations[AnnotationK4xSpotFallbackFrom]
+		if !ok || !recovered[from] {
+			continue
+		}
+		original := dep.Annotations[AnnotationK4xSpotOriginalPool]
+		pinned := dep.Spec.Template.Spec.NodeSelector[LabelK4xNodePool] == h.FallbackPool
+		if pinned {
+			if err := h.Client.PatchDeploymentNodePool(ctx, dep.Namespace, dep.Name, original); err != nil {
+				return restored, err
+			}
+		}
+		if err := h.patchSpotAnnotations(ctx, dep.Namespace, dep.Name, nil); err != nil {
+			return restored, err
+		}
+		if pinned {
+			restored = append(restored, dep.Namespace+"/"+dep.Name)
+		}
+	}
+	return restored, nil
+}
+
+// pinFallback pins a Deployment to FallbackPool and records the Spot pool and the original
+// selector for RestorePools. A Deployment already moved keeps its first record. It returns
+// false without changes when the required node affinity of the Deployment excludes
+// FallbackPool, since pinning it there would leave the pods unschedulable.
+func (h *SpotHandler) pinFallback(ctx context.Context, namespace, name, spotPool string) (bool, error) {
+	dep, err := h.Client.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("get deployment %s/%s: %w", namespace, name, err)
+	}
+	if !nodeAffinityAllowsPool(dep.Spec.Template.Spec.Affinity, h.FallbackPool) {
+		return false, nil
+	}
+	if _, ok := dep.Annotations[AnnotationK4xSpotFallbackFrom]; !ok {
+		annotations := map[string]any{
+			AnnotationK4xSpotFallbackFrom: spotPool,
+			AnnotationK4xSpotOriginalPool: dep.Spec.Template.Spec.NodeSelector[LabelK4xNodePool],
+		}
+		if err := h.patchSpotAnnotations(ctx, namespace, name, annotations); err != nil {
+			return false, err
+		}
+	}
+	return true, h.Client.PatchDeploymentNodePool(ctx, namespace, name, h.FallbackPool)
+}
+
+// patchSpotAnnotations replaces the handler annotations of a Deployment with annotations
+// (nil removes them).
+func (h *SpotHandler) patchSpotAnnotations(ctx context.Context, namespace, name string, annotations map[string]any) error {
+	if annotations == nil {
+		annotations = map[string]any{AnnotationK4xSpotFallbackFrom: nil, AnnotationK4xSpotOriginalPool: nil}
+	}
+	body, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": annotations}})
+	if err != nil {
+		return fmt.Errorf("marshal patch: %w", err)
+	}
+	if _, err := h.Client.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, body, metav1.PatchOptions{FieldManager: RescheduleFieldManager}); err != nil {
+		return fmt.Errorf("patch deployment %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// nodeAffinityAllowsPool reports whether the required node affinity permits nodes of pool.
+// Only kompox.dev/node-pool requirements are considered; a term without one allows any pool.
+func nodeAffinityAllowsPool(affinity *corev1.Affinity, pool string) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		allowed := true
+		for _, req := range term.MatchExpressions {
+			if req.Key != LabelK4xNodePool {
+				continue
+			}
+			switch req.Operator {
+			case corev1.NodeSelectorOpIn:
+				allowed = allowed && slices.Contains(req.Values, pool)
+			case corev1.NodeSelectorOpNotIn:
+				allowed = allowed && !slices.Contains(req.Values, pool)
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// spotNodeReadySince reports whether a node has been Ready and schedulable since the given
+// time without an eviction signal.
+func spotNodeReadySince(node *corev1.Node, since time.Time) bool {
+	if node.Spec.Unschedulable || SpotEvictionSignal(node) != "" {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue && !c.LastTransitionTime.After(since)
+		}
+	}
+	return false
+}
+
+// nodeGone reports whether the Node object no longer exists.
+func (h *SpotHandler) nodeGone(ctx context.Context, name string) (bool, error) {
+	_, err := h.Client.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get node %s: %w", name, err)
+	}
+	return false, nil
+}
+
+// nodeOutOfService reports whether the node is gone or tainted out-of-service, in which case
+// its pods can be force deleted without the risk of two copies running.
+func (h *SpotHandler) nodeOutOfService(ctx context.Context, name string) (bool, error) {
+	node, err := h.Client.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get node %s: %w", name, err)
+	}
+	return slices.ContainsFunc(node.Spec.Taints, func(t corev1.Taint) bool { return t.Key == taintOutOfService }), nil
+}
+
+// waitPodsGone waits up to StopTimeout for the pods to terminate. Remaining pods are force
+// deleted when the node is gone or out of service, since a kubelet that is gone never
+// confirms their termination; otherwise they are left to the kubelet.
+func (h *SpotHandler) waitPodsGone(ctx context.Context, nodeName string, pods []corev1.Pod) error {
+	timeout := h.StopTimeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	cs := h.Client.Clientset
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := pods[:0:0]
+		for _, pod := range pods {
+			cur, err := cs.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && cur.UID != pod.UID) {
+				continue
+			}
+			remaining = append(remaining, pod)
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			force, err := h.nodeOutOfService(ctx, nodeName)
+			if err != nil || !force {
+				return err
+			}
+			zero := int64(0)
+			for _, pod := range remaining {
+				if err := cs.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &zero}); err != nil && !apierrors.IsNotFound(err) {
+					return fmt.Errorf("force delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+				}
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		pods = remaining
+	}
+}
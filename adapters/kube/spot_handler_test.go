@@ -0,0 +1,242 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSpotEvictionSignal(t *testing.T) {
+	tests := []struct {
+		name string
+		node corev1.Node
+		want bool
+	}{
+		{name: "healthy", node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "kubernetes.azure.com/scalesetpriority", Value: "spot", Effect: corev1.TaintEffectNoSchedule}}}}},
+		{name: "cluster autoscaler", node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "ToBeDeletedByClusterAutoscaler", Effect: corev1.TaintEffectNoSchedule}}}}},
+		{name: "unreachable no-execute", node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeUnreachable, Effect: corev1.TaintEffectNoExecute}}}}},
+		{name: "out of service", node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "node.kubernetes.io/out-of-service", Effect: corev1.TaintEffectNoExecute}}}}, want: true},
+		{name: "deleted", node: corev1.Node{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{}}}, want: true},
+		{name: "unreachable no-schedule", node: corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: corev1.TaintNodeUnreachable, Effect: corev1.TaintEffectNoSchedule}}}}},
+		{name: "preempt scheduled", node: corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "VMEventScheduled", Status: corev1.ConditionTrue, Message: "Preempt"}}}}, want: true},
+		{name: "no event scheduled", node: corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "VMEventScheduled", Status: corev1.ConditionFalse}}}}},
+	}
+	for _, tt := range tests {
+		if got := kube.SpotEvictionSignal(&tt.node); (got != "") != tt.want {
+			t.Errorf("%s: signal = %q, want signal %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpotHandlerHandleNode(t *testing.T) {
+	ctx := context.Background()
+	ctrl := true
+	pv := "pv-disk1"
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "spot-0", Labels: map[string]string{kube.LabelK4xNodePool: "spot"}},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "VMEventScheduled", Status: corev1.ConditionTrue, Message: "Preempt"}}},
+	}
+	pod := func(name, nodeName string, owner *metav1.OwnerReference) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1", UID: types.UID("uid-" + name), Labels: map[string]string{kube.LabelAppK8sManagedBy: "kompox"}},
+			Spec: corev1.PodSpec{NodeName: nodeName, Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-disk1"},
+			}}}},
+		}
+		if owner != nil {
+			p.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		return p
+	}
+	rsRef := &metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app1-app-abc", Controller: &ctrl}
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(
+		node,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app1-app", Namespace: "ns1", Labels: map[string]string{kube.LabelAppK8sManagedBy: "kompox"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{NodeSelector: map[string]string{kube.LabelK4xNodePool: "spot"}}}}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app1-app-abc", Namespace: "ns1", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "app1-app", Controller: &ctrl},
+		}}},
+		pod("app1-app-abc-1", "spot-0", rsRef),
+		pod("app1-app-abc-2", "other", rsRef),
+		pod("standalone", "spot-0", nil),
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-disk1", Namespace: "ns1"}, Spec: corev1.PersistentVolumeClaimSpec{VolumeName: pv}},
+		&storagev1.VolumeAttachment{ObjectMeta: metav1.ObjectMeta{Name: "csi-va1"}, Spec: storagev1.VolumeAttachmentSpec{NodeName: "spot-0", Source: storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv}}},
+		&storagev1.VolumeAttachment{ObjectMeta: metav1.ObjectMeta{Name: "csi-va2"}, Spec: storagev1.VolumeAttachmentSpec{NodeName: "other", Source: storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv}}},
+	)}
+
+	h := &kube.SpotHandler{Client: client, SpotPools: []string{"spot"}, FallbackPool: "user", StopTimeout: time.Second}
+	ev, err := h.HandleNode(ctx, node)
+	if err != nil {
+		t.Fatalf("HandleNode: %v", err)
+	}
+	if len(ev.Deployments) != 1 || ev.Deployments[0] != "ns1/app1-app" {
+		t.Errorf("deployments = %v", ev.Deployments)
+	}
+	if len(ev.Pods) != 1 || ev.Pods[0] != "ns1/app1-app-abc-1" {
+		t.Errorf("pods = %v", ev.Pods)
+	}
+	if len(ev.VolumeAttachments) != 0 || len(ev.PersistentVolumes) != 1 || ev.PersistentVolumes[0] != pv {
+		t.Errorf("volumes must wait for the node to be gone: attachments %v, volumes %v", ev.VolumeAttachments, ev.PersistentVolumes)
+	}
+	if _, err := h.DetachVolumes(ctx, "spot-0", ev.PersistentVolumes); err == nil {
+		t.Error("expected error detaching volumes from an existing node")
+	}
+
+	dep, _ := client.Clientset.AppsV1().Deployments("ns1").Get(ctx, "app1-app", metav1.GetOptions{})
+	if dep.Spec.Template.Spec.NodeSelector[kube.LabelK4xNodePool] != "user" {
+		t.Errorf("deployment node selector = %v, want user", dep.Spec.Template.Spec.NodeSelector)
+	}
+	if dep.Annotations[kube.AnnotationK4xSpotFallbackFrom] != "spot" || dep.Annotations[kube.AnnotationK4xSpotOriginalPool] != "spot" {
+		t.Errorf("deployment annotations = %v", dep.Annotations)
+	}
+	if _, err := client.Clientset.CoreV1().Pods("ns1").Get(ctx, "app1-app-abc-1", metav1.GetOptions{}); err == nil {
+		t.Error("evicted pod still exists")
+	}
+	if _, err := client.Clientset.CoreV1().Pods("ns1").Get(ctx, "standalone", metav1.GetOptions{}); err != nil {
+		t.Errorf("pod without deployment must be kept: %v", err)
+	}
+
+	if err := client.Clientset.CoreV1().Nodes().Delete(ctx, "spot-0", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete node: %v", err)
+	}
+	vas, err := h.DetachVolumes(ctx, "spot-0", ev.PersistentVolumes)
+	if err != nil || len(vas) != 1 || vas[0] != "csi-va1" {
+		t.Errorf("DetachVolumes = %v, %v", vas, err)
+	}
+	if _, err := client.Clientset.StorageV1().VolumeAttachments().Get(ctx, "csi-va2", metav1.GetOptions{}); err != nil {
+		t.Errorf("attachment on another node must be kept: %v", err)
+	}
+
+	// A new Spot node that is not ready long enough keeps the deployment on the fallback pool.
+	recovered := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "spot-1", Labels: map[string]string{kube.LabelK4xNodePool: "spot"}},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute))}}},
+	}
+	h.RestoreDelay = time.Hour
+	if restored, err := h.RestorePools(ctx, []*corev1.Node{recovered}); err != nil || len(restored) != 0 {
+		t.Errorf("RestorePools before delay = %v, %v", restored, err)
+	}
+	h.RestoreDelay = time.Second
+	restored, err := h.RestorePools(ctx, []*corev1.Node{recovered})
+	if err != nil || len(restored) != 1 || restored[0] != "ns1/app1-app" {
+		t.Fatalf("RestorePools = %v, %v", restored, err)
+	}
+	dep, _ = client.Clientset.AppsV1().Deployments("ns1").Get(ctx, "app1-app", metav1.GetOptions{})
+	if dep.Spec.Template.Spec.NodeSelector[kube.LabelK4xNodePool] != "spot" {
+		t.Errorf("deployment node selector = %v, want spot restored", dep.Spec.Template.Spec.NodeSelector)
+	}
+	if _, ok := dep.Annotations[kube.AnnotationK4xSpotFallbackFrom]; ok {
+		t.Errorf("handler annotations must be removed: %v", dep.Annotations)
+	}
+}
+
+func TestSpotHandlerObjects(t *testing.T) {
+	spot, regular := "Spot", "Regular"
+	spotName, userName := "spot", "user"
+	cluster := &model.Cluster{
+		Name:        "cls1",
+		NodePools:   []model.NodePool{{Name: &spotName, Priority: &spot}, {Name: &userName, Priority: &regular}},
+		SpotHandler: &model.ClusterSpotHandler{FallbackPool: "user"},
+	}
+	if pools := kube.SpotHandlerPools(cluster); len(pools) != 1 || pools[0] != "spot" {
+		t.Fatalf("pools = %v", pools)
+	}
+	objs, err := kube.SpotHandlerObjects(cluster)
+	if err != nil {
+		t.Fatalf("SpotHandlerObjects: %v", err)
+	}
+	var dep *appsv1.Deployment
+	for _, o := range objs {
+		if d, ok := o.(*appsv1.Deployment); ok {
+			dep = d
+		}
+	}
+	if dep == nil || dep.Namespace != kube.IngressNamespace(cluster) {
+		t.Fatalf("deployment = %+v", dep)
+	}
+	spec := dep.Spec.Template.Spec
+	if spec.NodeSelector[kube.LabelK4xNodePool] != "user" || spec.Containers[0].Image != kube.DefaultSpotHandlerImage {
+		t.Errorf("pod spec = %+v", spec)
+	}
+
+	cluster.NodePools = nil
+	if _, err := kube.SpotHandlerObjects(cluster); err == nil {
+		t.Error("expected error without spot pools")
+	}
+}
+
+func TestSpotHandlerSkipsAffinityExcludingFallback(t *testing.T) {
+	ctx := context.Background()
+	ctrl := true
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "spot-0", Labels: map[string]string{kube.LabelK4xNodePool: "spot"}},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "node.kubernetes.io/out-of-service", Effect: corev1.TaintEffectNoExecute}}},
+	}
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: kube.LabelK4xNodePool, Operator: corev1.NodeSelectorOpIn, Values: []string{"spot", "spot2"}},
+		}}},
+	}}}
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(
+		node,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app1-app", Namespace: "ns1", Labels: map[string]string{kube.LabelAppK8sManagedBy: "kompox"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Affinity: affinity}}}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app1-app-abc", Namespace: "ns1", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "app1-app", Controller: &ctrl},
+		}}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1-app-abc-1", Namespace: "ns1", UID: "uid-1", Labels: map[string]string{kube.LabelAppK8sManagedBy: "kompox"},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app1-app-abc", Controller: &ctrl}}},
+			Spec: corev1.PodSpec{NodeName: "spot-0"},
+		},
+	)}
+
+	h := &kube.SpotHandler{Client: client, SpotPools: []string{"spot"}, FallbackPool: "user", StopTimeout: time.Second}
+	ev, err := h.HandleNode(ctx, node)
+	if err != nil {
+		t.Fatalf("HandleNode: %v", err)
+	}
+	if len(ev.Deployments) != 0 {
+		t.Errorf("deployments = %v, want none pinned", ev.Deployments)
+	}
+	dep, _ := client.Clientset.AppsV1().Deployments("ns1").Get(ctx, "app1-app", metav1.GetOptions{})
+	if len(dep.Spec.Template.Spec.NodeSelector) != 0 || len(dep.Annotations) != 0 {
+		t.Errorf("deployment must be left unchanged: selector %v, annotations %v", dep.Spec.Template.Spec.NodeSelector, dep.Annotations)
+	}
+}
+
+func TestValidateSpotFallback(t *testing.T) {
+	cluster := &model.Cluster{SpotHandler: &model.ClusterSpotHandler{FallbackPool: "user", SpotPools: []string{"spot"}}}
+	tests := []struct {
+		name       string
+		deployment model.AppDeployment
+		wantErr    bool
+	}{
+		{name: "single pool", deployment: model.AppDeployment{Pool: "spot"}},
+		{name: "pools with fallback", deployment: model.AppDeployment{Pools: []string{"spot", "user"}}},
+		{name: "pools without spot", deployment: model.AppDeployment{Pools: []string{"gpu"}}},
+		{name: "pools without fallback", deployment: model.AppDeployment{Pools: []string{"spot", "gpu"}}, wantErr: true},
+		{name: "preferences without fallback", deployment: model.AppDeployment{PoolPreferences: []model.AppPoolPreference{{Pool: "spot"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := kube.ValidateSpotFallback(cluster, tt.deployment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSpotFallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := kube.ValidateSpotFallback(&model.Cluster{}, model.AppDeployment{Pools: []string{"spot"}}); err != nil {
+		t.Errorf("no spot handler: %v", err)
+	}
+}
@@ -22,5 +22,7 @@ func newCmdAdmin() *cobra.Command {
 	c.AddCommand(newCmdAdminProvider())
 	c.AddCommand(newCmdAdminCluster())
 	c.AddCommand(newCmdAdminApp())
+	c.AddCommand(newCmdAdminGC())
+	c.AddCommand(newCmdAdminSpotHandler())
 	return c
 }
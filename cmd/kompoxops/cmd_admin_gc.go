@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	vuc "github.com/kompox/kompox/usecase/volume"
+	"github.com/spf13/cobra"
+)
+
+// newCmdAdminGC returns the command that reports or deletes orphaned app volume resources.
+func newCmdAdminGC() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "gc",
+		Short:              "Report or delete orphaned disks and snapshots of apps no longer defined",
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			u, err := buildVolumeUseCase(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Minute)
+			defer cancel()
+
+			providerID, _ := cmd.Flags().GetString("provider-id")
+			grace, _ := cmd.Flags().GetDuration("grace-period")
+			del, _ := cmd.Flags().GetBool("delete")
+			force, _ := cmd.Flags().GetBool("force")
+
+			resourceID := providerID
+			if resourceID == "" {
+				resourceID = "*"
+			}
+			ctx, cleanup := withCmdRunLogger(ctx, "admin.gc", resourceID)
+			defer func() { cleanup(err) }()
+
+			out, err := u.GC(ctx, &vuc.GCInput{ProviderID: providerID, DryRun: !del, GracePeriod: grace, Force: force})
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		},
+	}
+	cmd.Flags().String("provider-id", "", "Limit to a provider ID (FQN: /ws/<ws>/prv/<prv>)")
+	cmd.Flags().Duration("grace-period", vuc.DefaultGCGracePeriod, "Time an orphan must stay marked before deletion (0 deletes immediately and requires --force)")
+	cmd.Flags().Bool("delete", false, "Mark orphans and delete those past the grace period (default is a dry-run report)")
+	cmd.Flags().Bool("force", false, "Allow --grace-period 0 with --delete")
+	return cmd
+}
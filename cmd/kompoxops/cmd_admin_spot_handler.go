@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+)
+
+// newCmdAdminSpotHandler runs the Spot eviction helper. It is the entrypoint of the
+// in-cluster Deployment installed by "cluster install" when spotHandler is configured.
+func newCmdAdminSpotHandler() *cobra.Command {
+	var spotPools []string
+	var fallbackPool string
+	var kubeconfig string
+	cmd := &cobra.Command{
+		Use:                "spot-handler",
+		Short:              "Move Kompox apps off evicted Spot nodes (runs in the cluster)",
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			ctx, cleanup := withCmdRunLogger(ctx, "admin.spot-handler", "*")
+			defer func() { cleanup(err) }()
+
+			var kc *kube.Client
+			opts := &kube.Options{UserAgent: "kompoxops-spot-handler"}
+			if kubeconfig != "" {
+				kc, err = kube.NewClientFromKubeconfigPath(ctx, kubeconfig, opts)
+			} else {
+				cfg, cfgErr := rest.InClusterConfig()
+				if cfgErr != nil {
+					return fmt.Errorf("in-cluster config (use --kubeconfig outside the cluster): %w", cfgErr)
+				}
+				kc, err = kube.NewClientFromRESTConfig(cfg, opts)
+			}
+			if err != nil {
+				return err
+			}
+			h := &kube.SpotHandler{Client: kc, SpotPools: spotPools, FallbackPool: fallbackPool}
+			return h.Run(ctx)
+		},
+	}
+	cmd.Flags().StringSliceVar(&spotPools, "spot-pools", nil, "Node pools (kompox.dev/node-pool) to watch for eviction")
+	cmd.Flags().StringVar(&fallbackPool, "fallback-pool", "", "Regular node pool to move apps to")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Kubeconfig path (default: in-cluster config)")
+	_ = cmd.MarkFlagRequired("spot-pools")
+	_ = cmd.MarkFlagRequired("fallback-pool")
+	return cmd
+}
@@ -45,7 +45,7 @@ func newCmdApp() *cobra.Command {
 	// Persistent flag shared across subcommands
 	cmd.PersistentFlags().StringVarP(&flagAppID, "app-id", "A", "", "App ID (FQN: ws/prv/cls/app)")
 	cmd.PersistentFlags().StringVar(&flagAppName, "app-name", "", "App name (backward compatibility, use --app-id)")
-	cmd.AddCommand(newCmdAppValidate(), newCmdAppDeploy(), newCmdAppDestroy(), newCmdAppStatus(), newCmdAppExec(), newCmdAppLogs(), newCmdAppTunnel(), newCmdAppKubectl())
+	cmd.AddCommand(newCmdAppValidate(), newCmdAppDeploy(), newCmdAppDestroy(), newCmdAppStatus(), newCmdAppReschedule(), newCmdAppReplicate(), newCmdAppFailover(), newCmdAppExec(), newCmdAppLogs(), newCmdAppTunnel(), newCmdAppKubectl())
 	return cmd
 }
 
@@ -188,6 +188,7 @@ func normalizeYAMLDocs(s string) string {
 func newCmdAppValidate() *cobra.Command {
 	var outComposePath string
 	var outManifestPath string
+	var autoZone bool
 	cmd := &cobra.Command{
 		Use:                "validate",
 		Short:              "Validate app compose definition",
@@ -209,7 +210,7 @@ func newCmdAppValidate() *cobra.Command {
 				return err
 			}
 
-			out, err := appUC.Validate(ctx, &app.ValidateInput{AppID: appID})
+			out, err := appUC.Validate(ctx, &app.ValidateInput{AppID: appID, AutoZone: autoZone})
 			if err != nil {
 				return fmt.Errorf("validation failed: %w", err)
 			}
@@ -256,6 +257,7 @@ func newCmdAppValidate() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&outComposePath, "out-compose", "", "Write normalized compose YAML to file (omit compose YAML stdout)")
 	cmd.Flags().StringVar(&outManifestPath, "out-manifest", "", "Write generated Kubernetes manifest to file (omit manifest stdout)")
+	cmd.Flags().BoolVar(&autoZone, "auto-zone", false, "Pin node affinity to the zone of the assigned disks")
 	return cmd
 }
 
@@ -273,6 +275,7 @@ func newCmdAppValidate() *cobra.Command {
 func newCmdAppDeploy() *cobra.Command {
 	var bootstrapDisks bool
 	var updateDNS bool
+	var autoZone bool
 	cmd := &cobra.Command{
 		Use:                "deploy",
 		Short:              "Deploy app to cluster (apply generated Kubernetes objects)",
@@ -317,7 +320,7 @@ func newCmdAppDeploy() *cobra.Command {
 				}
 			}
 
-			if _, err := appUC.Deploy(ctx, &app.DeployInput{AppID: target.ID}); err != nil {
+			if _, err := appUC.Deploy(ctx, &app.DeployInput{AppID: target.ID, AutoZone: autoZone}); err != nil {
 				return err
 			}
 
@@ -342,6 +345,7 @@ func newCmdAppDeploy() *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&bootstrapDisks, "bootstrap-disks", false, "Create one assigned disk per volume if none exist (fails on partial state)")
 	cmd.Flags().BoolVar(&updateDNS, "update-dns", false, "Update DNS records after deployment")
+	cmd.Flags().BoolVar(&autoZone, "auto-zone", false, "Pin node affinity to the zone of the assigned disks")
 	return cmd
 }
 
@@ -449,6 +453,49 @@ func newCmdAppStatus() *cobra.Command {
 	}
 }
 
+// newCmdAppReschedule moves an unschedulable app to the next node pool of its pool order.
+func newCmdAppReschedule() *cobra.Command {
+	var dryRun bool
+	var reset bool
+	cmd := &cobra.Command{
+		Use:                "reschedule",
+		Short:              "Move pending app pods to the next node pool of deployment.poolPreferences/pools",
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			appUC, err := buildAppUseCase(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+			defer cancel()
+
+			appID, err := resolveAppID(ctx, appUC.Repos.App, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cleanup := withCmdRunLogger(ctx, "app.reschedule", appID)
+			defer func() { cleanup(err) }()
+
+			out, err := appUC.Reschedule(ctx, &app.RescheduleInput{AppID: appID, DryRun: dryRun, Reset: reset})
+			if out != nil {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(out); encErr != nil && err == nil {
+					err = encErr
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report the selected node pool without patching the deployment")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Remove the node pool pinned by a previous reschedule")
+	return cmd
+}
+
 // newCmdAppExec executes a command in a selected pod of the app namespace.
 func newCmdAppExec() *cobra.Command {
 	var stdin bool
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/internal/logging"
+	"github.com/kompox/kompox/usecase/app"
+	"github.com/kompox/kompox/usecase/dns"
+	"github.com/spf13/cobra"
+)
+
+// newCmdAppReplicate replicates the app volume snapshots to the standby cluster.
+func newCmdAppReplicate() *cobra.Command {
+	var volumes []string
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "replicate",
+		Short: "Replicate volume snapshots to the standby cluster",
+		Long: `Take an incremental snapshot of each disk volume and copy it to the provider of the
+standby cluster (spec.standby.clusterId). Volumes replicated within spec.standby.interval are
+skipped unless --force is given, so the command can run periodically from cron. Replicas beyond
+spec.standby.retain are deleted on both sides.`,
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			appUC, err := buildAppUseCase(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 150*time.Minute)
+			defer cancel()
+
+			appID, err := resolveAppID(ctx, appUC.Repos.App, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cleanup := withCmdRunLogger(ctx, "app.replicate", appID)
+			defer func() { cleanup(err) }()
+
+			out, err := appUC.Replicate(ctx, &app.ReplicateInput{AppID: appID, Volumes: volumes, Force: force})
+			if out != nil {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(out); encErr != nil && err == nil {
+					err = encErr
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringSliceVarP(&volumes, "volume", "V", nil, "Volumes to replicate (repeatable, default all disk volumes)")
+	cmd.Flags().BoolVar(&force, "force", false, "Replicate even when the latest replica is within the standby interval")
+	return cmd
+}
+
+// newCmdAppFailover restores the app on the standby cluster from the latest replicas.
+func newCmdAppFailover() *cobra.Command {
+	var autoZone bool
+	var updateDNS bool
+	cmd := &cobra.Command{
+		Use:   "failover",
+		Short: "Restore the app on the standby cluster from the latest replicas",
+		Long: `Create and assign a disk from the latest replica of each disk volume in the standby
+cluster, deploy the app there and point the DNS records to the standby cluster. The primary
+cluster is not contacted. Afterwards swap the app cluster and spec.standby.clusterId in the KOM
+definition so that later commands target the new primary.`,
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			appUC, err := buildAppUseCase(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Minute)
+			defer cancel()
+
+			appID, err := resolveAppID(ctx, appUC.Repos.App, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cleanup := withCmdRunLogger(ctx, "app.failover", appID)
+			defer func() { cleanup(err) }()
+
+			logger := logging.FromContext(ctx)
+
+			out, err := appUC.Failover(ctx, &app.FailoverInput{AppID: appID, AutoZone: autoZone})
+			if out != nil {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(out); encErr != nil && err == nil {
+					err = encErr
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			if updateDNS {
+				dnsUC, derr := buildDNSUseCase(cmd)
+				if derr != nil {
+					return fmt.Errorf("failed to build DNS use case: %w", derr)
+				}
+				logger.Info(ctx, "updating DNS records", "cluster", out.ToCluster)
+				if _, derr = dnsUC.Deploy(ctx, &dns.DeployInput{
+					AppID:         appID,
+					ComponentName: "app",
+					ClusterID:     out.ToClusterID,
+				}); derr != nil {
+					return fmt.Errorf("failed to update DNS: %w", derr)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&autoZone, "auto-zone", false, "Pin node affinity to the zone of the restored disks")
+	cmd.Flags().BoolVar(&updateDNS, "update-dns", true, "Point DNS records to the standby cluster")
+	return cmd
+}
@@ -45,6 +45,7 @@ func newCmdCluster() *cobra.Command {
 		newCmdClusterKubeconfig(),
 		newCmdClusterLogs(),
 		newCmdClusterNodePool(),
+		newCmdClusterUpgrade(),
 	)
 	return cmd
 }
@@ -151,7 +152,8 @@ func newCmdClusterLogs() *cobra.Command {
 }
 
 func newCmdClusterProvision() *cobra.Command {
-	var force bool
+	var force, plan bool
+	var format string
 	cmd := &cobra.Command{
 		Use:                "provision",
 		Short:              "Provision a Kubernetes cluster",
@@ -160,6 +162,9 @@ func newCmdClusterProvision() *cobra.Command {
 		SilenceErrors:      true,
 		DisableSuggestions: true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := validateClusterPlanFormat(format); err != nil {
+				return err
+			}
 			clusterUC, err := buildClusterUseCase(cmd)
 			if err != nil {
 				return err
@@ -190,19 +195,26 @@ func newCmdClusterProvision() *cobra.Command {
 			}
 
 			// Provision the cluster via usecase
-			if _, err := clusterUC.Provision(ctx, &uc.ProvisionInput{ClusterID: cluster.ID, Force: force}); err != nil {
+			out, err := clusterUC.Provision(ctx, &uc.ProvisionInput{ClusterID: cluster.ID, Force: force, Plan: plan})
+			if err != nil {
 				return fmt.Errorf("failed to provision cluster %s: %w", cluster.Name, err)
 			}
+			if plan {
+				return printClusterPlan(cmd, out.Plan, format)
+			}
 
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&force, "force", false, "Force cluster provisioning even if a successful deployment exists")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show the changes without applying them")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format of --plan (text|json)")
 	return cmd
 }
 
 func newCmdClusterDeprovision() *cobra.Command {
-	var force bool
+	var force, plan bool
+	var format string
 	cmd := &cobra.Command{
 		Use:                "deprovision",
 		Short:              "Deprovision a Kubernetes cluster",
@@ -211,6 +223,9 @@ func newCmdClusterDeprovision() *cobra.Command {
 		SilenceErrors:      true,
 		DisableSuggestions: true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := validateClusterPlanFormat(format); err != nil {
+				return err
+			}
 			clusterUC, err := buildClusterUseCase(cmd)
 			if err != nil {
 				return err
@@ -246,19 +261,26 @@ func newCmdClusterDeprovision() *cobra.Command {
 			}
 
 			// Deprovision the cluster via usecase
-			if _, err := clusterUC.Deprovision(ctx, &uc.DeprovisionInput{ClusterID: cluster.ID, Force: force}); err != nil {
+			out, err := clusterUC.Deprovision(ctx, &uc.DeprovisionInput{ClusterID: cluster.ID, Force: force, Plan: plan})
+			if err != nil {
 				return fmt.Errorf("failed to deprovision cluster %s: %w", cluster.Name, err)
 			}
+			if plan {
+				return printClusterPlan(cmd, out.Plan, format)
+			}
 
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&force, "force", false, "Force deprovision behavior if driver supports it")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show the changes without applying them")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format of --plan (text|json)")
 	return cmd
 }
 
 func newCmdClusterInstall() *cobra.Command {
-	var force bool
+	var force, plan bool
+	var format string
 	cmd := &cobra.Command{
 		Use:                "install",
 		Short:              "Install cluster resources (Ingress Controller, etc.)",
@@ -267,6 +289,9 @@ func newCmdClusterInstall() *cobra.Command {
 		SilenceErrors:      true,
 		DisableSuggestions: true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := validateClusterPlanFormat(format); err != nil {
+				return err
+			}
 			clusterUC, err := buildClusterUseCase(cmd)
 			if err != nil {
 				return err
@@ -291,19 +316,26 @@ func newCmdClusterInstall() *cobra.Command {
 			cluster := getOut.Cluster
 
 			// Install cluster resources via usecase
-			if _, err := clusterUC.Install(ctx, &uc.InstallInput{ClusterID: cluster.ID, Force: force}); err != nil {
+			out, err := clusterUC.Install(ctx, &uc.InstallInput{ClusterID: cluster.ID, Force: force, Plan: plan})
+			if err != nil {
 				return fmt.Errorf("failed to install cluster resources for %s: %w", cluster.Name, err)
 			}
+			if plan {
+				return printClusterPlan(cmd, out.Plan, format)
+			}
 
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&force, "force", false, "Force install behavior if driver supports it")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show the changes without applying them")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format of --plan (text|json)")
 	return cmd
 }
 
 func newCmdClusterUninstall() *cobra.Command {
-	var force bool
+	var force, plan bool
+	var format string
 	cmd := &cobra.Command{
 		Use:                "uninstall",
 		Short:              "Uninstall cluster resources (Ingress Controller, etc.)",
@@ -312,6 +344,9 @@ func newCmdClusterUninstall() *cobra.Command {
 		SilenceErrors:      true,
 		DisableSuggestions: true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := validateClusterPlanFormat(format); err != nil {
+				return err
+			}
 			clusterUC, err := buildClusterUseCase(cmd)
 			if err != nil {
 				return err
@@ -341,14 +376,20 @@ func newCmdClusterUninstall() *cobra.Command {
 			}
 
 			// Uninstall cluster resources via usecase
-			if _, err := clusterUC.Uninstall(ctx, &uc.UninstallInput{ClusterID: cluster.ID, Force: force}); err != nil {
+			out, err := clusterUC.Uninstall(ctx, &uc.UninstallInput{ClusterID: cluster.ID, Force: force, Plan: plan})
+			if err != nil {
 				return fmt.Errorf("failed to uninstall cluster resources for %s: %w", cluster.Name, err)
 			}
+			if plan {
+				return printClusterPlan(cmd, out.Plan, format)
+			}
 
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&force, "force", false, "Force uninstall behavior if driver supports it")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show the changes without applying them")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format of --plan (text|json)")
 	return cmd
 }
 
@@ -35,6 +35,7 @@ func newCmdClusterNodePool() *cobra.Command {
 		newCmdClusterNodePoolCreate(),
 		newCmdClusterNodePoolUpdate(),
 		newCmdClusterNodePoolDelete(),
+		newCmdClusterNodePoolSync(),
 	)
 	return cmd
 }
@@ -331,3 +332,62 @@ func newCmdClusterNodePoolDelete() *cobra.Command {
 	cmd.Flags().BoolVar(&force, "force", false, "Force deletion if driver supports it")
 	return cmd
 }
+
+// newCmdClusterNodePoolSync reconciles node pools with the nodePools declared in the cluster spec.
+func newCmdClusterNodePoolSync() *cobra.Command {
+	var prune, dryRun, force bool
+	cmd := &cobra.Command{
+		Use:                "sync",
+		Short:              "Reconcile node pools with the cluster spec",
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			u, err := buildNodePoolUseCaseFunc(cmd)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Minute)
+			defer cancel()
+
+			clusterID, err := resolveClusterIDFunc(ctx, u.Repos.Cluster, nil)
+			if err != nil {
+				return err
+			}
+
+			ctx, cleanup := withCmdRunLogger(ctx, "nodepool.sync", clusterID)
+			defer func() { cleanup(err) }()
+
+			out, err := u.Sync(ctx, &nuc.SyncInput{
+				ClusterID: clusterID,
+				Prune:     prune,
+				DryRun:    dryRun,
+				Force:     force,
+			})
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+			failed := 0
+			for _, it := range out.Items {
+				if it.Action == nuc.SyncActionFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d node pool(s) failed to sync", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete node pools not declared in the cluster spec")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without applying")
+	cmd.Flags().BoolVar(&force, "force", false, "Force operations if driver supports it")
+	return cmd
+}
@@ -22,6 +22,10 @@ type nodePoolPortMock struct {
 	deleteFn func(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) error
 }
 
+func (m *nodePoolPortMock) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{Driver: "mock", NodePool: model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true}}, nil
+}
+
 type clusterRepoMock struct {
 	getFn func(ctx context.Context, id string) (*model.Cluster, error)
 }
@@ -259,3 +263,61 @@ func TestNodePoolListCommandMapsNameFilter(t *testing.T) {
 		t.Fatalf("name filter not mapped: %q", gotNameFilter)
 	}
 }
+
+func TestNodePoolSyncCommand(t *testing.T) {
+	origBuild := buildNodePoolUseCaseFunc
+	origResolve := resolveClusterIDFunc
+	defer func() {
+		buildNodePoolUseCaseFunc = origBuild
+		resolveClusterIDFunc = origResolve
+	}()
+
+	resolveClusterIDFunc = func(ctx context.Context, _ domain.ClusterRepository, _ []string) (string, error) {
+		return "ws/prv/cls", nil
+	}
+
+	system, user := "system", "user"
+	port := &nodePoolPortMock{
+		listFn: func(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) ([]*model.NodePool, error) {
+			return []*model.NodePool{{Name: &system}}, nil
+		},
+		createFn: func(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolCreateOption) (*model.NodePool, error) {
+			return nil, errors.New("quota exceeded")
+		},
+	}
+	buildNodePoolUseCaseFunc = func(cmd *cobra.Command) (*nuc.UseCase, error) {
+		return &nuc.UseCase{Repos: &nuc.Repos{Cluster: &clusterRepoMock{getFn: func(ctx context.Context, id string) (*model.Cluster, error) {
+			return &model.Cluster{ID: id, Name: "cls", NodePools: []model.NodePool{{Name: &system}, {Name: &user}}}, nil
+		}}}, NodePoolPort: port}, nil
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		cmd := newCmdClusterNodePoolSync()
+		cmd.SetContext(context.Background())
+		buf := &bytes.Buffer{}
+		cmd.SetOut(buf)
+		if err := cmd.Flags().Set("dry-run", "true"); err != nil {
+			t.Fatalf("set dry-run flag: %v", err)
+		}
+		if err := cmd.RunE(cmd, nil); err != nil {
+			t.Fatalf("run sync: %v", err)
+		}
+		if !strings.Contains(buf.String(), `"action": "create"`) || !strings.Contains(buf.String(), `"dry_run": true`) {
+			t.Fatalf("unexpected output: %s", buf.String())
+		}
+	})
+
+	t.Run("failed_pool", func(t *testing.T) {
+		cmd := newCmdClusterNodePoolSync()
+		cmd.SetContext(context.Background())
+		buf := &bytes.Buffer{}
+		cmd.SetOut(buf)
+		err := cmd.RunE(cmd, nil)
+		if err == nil || !strings.Contains(err.Error(), "1 node pool(s) failed") {
+			t.Fatalf("expected failure, got: %v", err)
+		}
+		if !strings.Contains(buf.String(), "quota exceeded") {
+			t.Fatalf("report misses error: %s", buf.String())
+		}
+	})
+}
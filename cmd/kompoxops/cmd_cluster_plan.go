@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/spf13/cobra"
+)
+
+// clusterPlanSymbols maps change actions to the markers of the text output.
+var clusterPlanSymbols = map[model.ClusterChangeAction]string{
+	model.ClusterChangeCreate: "+",
+	model.ClusterChangeUpdate: "~",
+	model.ClusterChangeDelete: "-",
+	model.ClusterChangeNoOp:   "=",
+}
+
+// validateClusterPlanFormat checks the --format value of cluster lifecycle commands.
+func validateClusterPlanFormat(format string) error {
+	switch format {
+	case "text", "json":
+		return nil
+	}
+	return fmt.Errorf("invalid --format %q (text|json)", format)
+}
+
+// printClusterPlan writes the plan to stdout as indented JSON or as text.
+func printClusterPlan(cmd *cobra.Command, plan *model.ClusterPlan, format string) error {
+	if plan == nil {
+		return fmt.Errorf("driver returned no plan")
+	}
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	return writeClusterPlanText(cmd.OutOrStdout(), plan)
+}
+
+// writeClusterPlanText renders the plan as one line per resource followed by its changed fields:
+//
+//	Plan: install cluster cls1 (driver aks): 1 to create, 1 to update, 0 to delete, 1 unchanged
+//	  + cluster Namespace traefik
+//	  ~ cluster ServiceAccount traefik/traefik
+//	      annotations.azure.workload.identity/client-id: "old" -> "new"
+func writeClusterPlanText(w io.Writer, plan *model.ClusterPlan) error {
+	counts := map[model.ClusterChangeAction]int{}
+	for _, c := range plan.Changes {
+		counts[c.Action]++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan: %s cluster %s (driver %s): %d to create, %d to update, %d to delete, %d unchanged\n",
+		plan.Operation, plan.Cluster, plan.Driver,
+		counts[model.ClusterChangeCreate], counts[model.ClusterChangeUpdate], counts[model.ClusterChangeDelete], counts[model.ClusterChangeNoOp])
+	for _, c := range plan.Changes {
+		sym, ok := clusterPlanSymbols[c.Action]
+		if !ok {
+			sym = "?"
+		}
+		fmt.Fprintf(&b, "  %s %s %s %s\n", sym, c.Scope, c.Kind, c.Resource)
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "      %s: %s -> %s\n", f.Path, formatClusterPlanValue(f.Before), formatClusterPlanValue(f.After))
+		}
+	}
+	if !plan.HasChanges() {
+		b.WriteString("No changes.\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatClusterPlanValue renders a field value as compact JSON; nil is shown as (none).
+func formatClusterPlanValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	uc "github.com/kompox/kompox/usecase/cluster"
+	"github.com/spf13/cobra"
+)
+
+func newCmdClusterUpgrade() *cobra.Command {
+	var kubernetesVersion, format string
+	var nodeImage, plan bool
+	var pools []string
+	var moveTimeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the Kubernetes version and node images of a cluster",
+		Long: `Upgrade the control plane to --kubernetes-version and then each node pool one at a time,
+system pools first. Kompox apps on a pool that would be unavailable while their pods move
+(single replica, Recreate strategy or ReadWriteOnce disks) are listed with their expected
+downtime and restarted onto other nodes before the pool is drained. Use --plan to review
+the steps without upgrading.`,
+		Args:               cobra.NoArgs,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		DisableSuggestions: true,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err := validateClusterPlanFormat(format); err != nil {
+				return err
+			}
+			if kubernetesVersion == "" && !nodeImage {
+				return fmt.Errorf("specify --kubernetes-version and/or --node-image")
+			}
+			clusterUC, err := buildClusterUseCase(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Minute)
+			defer cancel()
+
+			clusterID, err := resolveClusterID(ctx, clusterUC.Repos.Cluster, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, cleanup := withCmdRunLogger(ctx, "cluster.upgrade", clusterID)
+			defer func() { cleanup(err) }()
+
+			out, err := clusterUC.Upgrade(ctx, &uc.UpgradeInput{
+				ClusterID:         clusterID,
+				KubernetesVersion: kubernetesVersion,
+				NodeImage:         nodeImage,
+				Pools:             pools,
+				Plan:              plan,
+				MoveTimeout:       moveTimeout,
+			})
+			if out != nil {
+				if perr := printClusterUpgrade(cmd, out, format); perr != nil && err == nil {
+					err = perr
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to upgrade cluster: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kubernetesVersion, "kubernetes-version", "", "Target Kubernetes version of the control plane and node pools")
+	cmd.Flags().BoolVar(&nodeImage, "node-image", false, "Upgrade node pools to the latest node image")
+	cmd.Flags().StringSliceVar(&pools, "pool", nil, "Node pools to upgrade (repeatable, default all)")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show the upgrade steps and expected app downtime without upgrading")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text|json)")
+	cmd.Flags().DurationVar(&moveTimeout, "move-timeout", 10*time.Minute, "Maximum wait for each moved app to become available")
+	return cmd
+}
+
+// printClusterUpgrade writes the upgrade steps to stdout as indented JSON or as text.
+func printClusterUpgrade(cmd *cobra.Command, out *uc.UpgradeOutput, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	return writeClusterUpgradeText(cmd.OutOrStdout(), out)
+}
+
+// writeClusterUpgradeText renders the upgrade as one line per step followed by the apps of each pool:
+//
+//	Plan: upgrade cluster cls1 1.32.5 -> 1.33.2
+//	  control plane 1.32.5 -> 1.33.2
+//	  node pool system 1.32.5 -> 1.33.2
+//	  node pool user 1.32.5 -> 1.33.2
+//	      ns1/app1-app expected downtime 2m30s (single replica, Recreate strategy, RWO disk reattach)
+func writeClusterUpgradeText(w io.Writer, out *uc.UpgradeOutput) error {
+	var b strings.Builder
+	head := "Upgrade"
+	if out.Plan {
+		head = "Plan: upgrade"
+	}
+	fmt.Fprintf(&b, "%s cluster %s %s", head, out.Cluster, out.KubernetesVersion)
+	if out.TargetVersion != "" {
+		fmt.Fprintf(&b, " -> %s", out.TargetVersion)
+	}
+	b.WriteString("\n")
+	if out.ControlPlane {
+		fmt.Fprintf(&b, "  control plane %s -> %s\n", out.KubernetesVersion, out.TargetVersion)
+	}
+	for _, p := range out.NodePools {
+		fmt.Fprintf(&b, "  node pool %s", p.Name)
+		switch {
+		case p.Skipped != "":
+			fmt.Fprintf(&b, " %s (%s)", p.FromVersion, p.Skipped)
+		case p.ToVersion != "":
+			fmt.Fprintf(&b, " %s -> %s", p.FromVersion, p.ToVersion)
+		default:
+			fmt.Fprintf(&b, " node image %s -> %s", p.FromNodeImage, p.ToNodeImage)
+		}
+		if p.Upgraded {
+			b.WriteString(" [upgraded]")
+		}
+		b.WriteString("\n")
+		for _, a := range p.Apps {
+			fmt.Fprintf(&b, "      %s/%s expected downtime %s (%s)\n", a.Namespace, a.Deployment, a.ExpectedDowntime, a.Reason)
+		}
+		for _, m := range p.Moved {
+			fmt.Fprintf(&b, "      moved %s\n", m)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
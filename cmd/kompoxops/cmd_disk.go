@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kompox/kompox/internal/logging"
@@ -22,7 +23,7 @@ func newCmdDisk() *cobra.Command {
 	cmd.PersistentFlags().StringP("vol-name", "V", "", "Volume name (required for list/create/assign/delete)")
 	cmd.PersistentFlags().StringVarP(&flagVolumeDiskName, "name", "N", "", "Disk name (optional for create; required for assign/delete)")
 	cmd.PersistentFlags().StringVar(&flagVolumeDiskName, "disk-name", "", "Disk name (alias of --name)")
-	cmd.AddCommand(newCmdDiskList(), newCmdDiskCreate(), newCmdDiskAssign(), newCmdDiskDelete())
+	cmd.AddCommand(newCmdDiskList(), newCmdDiskCreate(), newCmdDiskAssign(), newCmdDiskUpdate(), newCmdDiskDelete())
 	return cmd
 }
 
@@ -45,7 +46,19 @@ func newCmdDiskList() *cobra.Command {
 			return err
 		}
 
-		out, err := u.DiskList(ctx, &vuc.DiskListInput{AppID: appID, VolumeName: volName})
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := parseVolumeLabels(labelFlags)
+		if err != nil {
+			return err
+		}
+		if tree, _ := cmd.Flags().GetBool("tree"); tree {
+			if len(labels) > 0 {
+				return fmt.Errorf("--label cannot be combined with --tree")
+			}
+			return writeVolumeLineage(ctx, cmd, u, appID, volName)
+		}
+
+		out, err := u.DiskList(ctx, &vuc.DiskListInput{AppID: appID, VolumeName: volName, Labels: labels})
 		if err != nil {
 			return err
 		}
@@ -53,9 +66,36 @@ func newCmdDiskList() *cobra.Command {
 		enc.SetIndent("", "  ")
 		return enc.Encode(out.Items)
 	}}
+	cmd.Flags().StringArrayP("label", "l", nil, "Filter by label key=value (repeatable; all must match)")
+	cmd.Flags().Bool("tree", false, "Show disks and snapshots of the volume as a lineage tree")
 	return cmd
 }
 
+// parseVolumeLabels parses repeated key=value flags into a label map.
+func parseVolumeLabels(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(values))
+	for _, kv := range values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --label %q: expected key=value", kv)
+		}
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// writeVolumeLineage prints the lineage tree of disks and snapshots of a volume.
+func writeVolumeLineage(ctx context.Context, cmd *cobra.Command, u *vuc.UseCase, appID, volName string) error {
+	out, err := u.Lineage(ctx, &vuc.LineageInput{AppID: appID, VolumeName: volName})
+	if err != nil {
+		return err
+	}
+	return vuc.WriteLineageTree(cmd.OutOrStdout(), out.Roots)
+}
+
 func newCmdDiskCreate() *cobra.Command {
 	cmd := &cobra.Command{Use: "create", Short: "Create volume instance", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, _ []string) (err error) {
 		u, err := buildVolumeUseCase(cmd)
@@ -122,7 +162,14 @@ func newCmdDiskCreate() *cobra.Command {
 			return enc.Encode(disks)
 		}
 
-		input := &vuc.DiskCreateInput{AppID: appID, VolumeName: volName, DiskName: diskName, Zone: zone, Options: options, Source: source}
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := parseVolumeLabels(labelFlags)
+		if err != nil {
+			return err
+		}
+		description, _ := cmd.Flags().GetString("description")
+
+		input := &vuc.DiskCreateInput{AppID: appID, VolumeName: volName, DiskName: diskName, Zone: zone, Options: options, Source: source, Labels: labels, Description: description}
 		out, err := u.DiskCreate(ctx, input)
 		if err != nil {
 			return err
@@ -134,6 +181,8 @@ func newCmdDiskCreate() *cobra.Command {
 	cmd.Flags().StringP("zone", "Z", "", "Override deployment zone")
 	cmd.Flags().StringP("options", "O", "", "Override volume options (JSON)")
 	cmd.Flags().StringP("source", "S", "", "Source for disk creation (format depends on provider driver)")
+	cmd.Flags().StringArrayP("label", "l", nil, "Label key=value stored with the disk (repeatable)")
+	cmd.Flags().String("description", "", "Description stored with the disk")
 	cmd.Flags().Bool("bootstrap", false, "Create one assigned disk per app volume if none are assigned (ignore when already initialized)")
 	return cmd
 }
@@ -176,6 +225,53 @@ func newCmdDiskAssign() *cobra.Command {
 	return cmd
 }
 
+func newCmdDiskUpdate() *cobra.Command {
+	cmd := &cobra.Command{Use: "update", Short: "Update volume instance options in place", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, _ []string) (err error) {
+		u, err := buildVolumeUseCase(cmd)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+		defer cancel()
+
+		volName, _ := cmd.Flags().GetString("vol-name")
+		if volName == "" {
+			return fmt.Errorf("--vol-name required")
+		}
+		diskName := flagVolumeDiskName
+		if diskName == "" {
+			return fmt.Errorf("--name (or --disk-name) required")
+		}
+		optionsStr, _ := cmd.Flags().GetString("options")
+		if optionsStr == "" {
+			return fmt.Errorf("--options required")
+		}
+		var options map[string]any
+		if err := json.Unmarshal([]byte(optionsStr), &options); err != nil {
+			return fmt.Errorf("invalid JSON in --options: %w", err)
+		}
+
+		appID, err := resolveAppID(ctx, u.Repos.App, nil)
+		if err != nil {
+			return err
+		}
+
+		resourceID := appID + "/vol:" + volName + "/disk:" + diskName
+		ctx, cleanup := withCmdRunLogger(ctx, "disk.update", resourceID)
+		defer func() { cleanup(err) }()
+
+		out, err := u.DiskUpdate(ctx, &vuc.DiskUpdateInput{AppID: appID, VolumeName: volName, DiskName: diskName, Options: options})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(out.Disk)
+	}}
+	cmd.Flags().StringP("options", "O", "", "Volume options to change (JSON)")
+	return cmd
+}
+
 func newCmdDiskDelete() *cobra.Command {
 	cmd := &cobra.Command{Use: "delete", Short: "Delete volume instance", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, _ []string) (err error) {
 		u, err := buildVolumeUseCase(cmd)
@@ -44,7 +44,19 @@ func newCmdSnapshotList() *cobra.Command {
 			return err
 		}
 
-		out, err := u.SnapshotList(ctx, &vuc.SnapshotListInput{AppID: appID, VolumeName: volName})
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := parseVolumeLabels(labelFlags)
+		if err != nil {
+			return err
+		}
+		if tree, _ := cmd.Flags().GetBool("tree"); tree {
+			if len(labels) > 0 {
+				return fmt.Errorf("--label cannot be combined with --tree")
+			}
+			return writeVolumeLineage(ctx, cmd, u, appID, volName)
+		}
+
+		out, err := u.SnapshotList(ctx, &vuc.SnapshotListInput{AppID: appID, VolumeName: volName, Labels: labels})
 		if err != nil {
 			return err
 		}
@@ -52,6 +64,8 @@ func newCmdSnapshotList() *cobra.Command {
 		enc.SetIndent("", "  ")
 		return enc.Encode(out.Items)
 	}}
+	cmd.Flags().StringArrayP("label", "l", nil, "Filter by label key=value (repeatable; all must match)")
+	cmd.Flags().Bool("tree", false, "Show disks and snapshots of the volume as a lineage tree")
 	return cmd
 }
 
@@ -80,7 +94,14 @@ func newCmdSnapshotCreate() *cobra.Command {
 		ctx, cleanup := withCmdRunLogger(ctx, "snapshot.create", resourceID)
 		defer func() { cleanup(err) }()
 
-		out, err := u.SnapshotCreate(ctx, &vuc.SnapshotCreateInput{AppID: appID, VolumeName: volName, SnapshotName: snapshotName, Source: source})
+		labelFlags, _ := cmd.Flags().GetStringArray("label")
+		labels, err := parseVolumeLabels(labelFlags)
+		if err != nil {
+			return err
+		}
+		description, _ := cmd.Flags().GetString("description")
+
+		out, err := u.SnapshotCreate(ctx, &vuc.SnapshotCreateInput{AppID: appID, VolumeName: volName, SnapshotName: snapshotName, Source: source, Labels: labels, Description: description})
 		if err != nil {
 			return err
 		}
@@ -89,6 +110,8 @@ func newCmdSnapshotCreate() *cobra.Command {
 		return enc.Encode(out.Snapshot)
 	}}
 	cmd.Flags().StringP("source", "S", "", "Source identifier for snapshot creation (forwarded to provider driver)")
+	cmd.Flags().StringArrayP("label", "l", nil, "Label key=value stored with the snapshot (repeatable)")
+	cmd.Flags().String("description", "", "Description stored with the snapshot")
 	return cmd
 }
 
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+const fakeFlowKOM = `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: ws1
+  annotations:
+    ops.kompox.dev/id: /ws/ws1
+spec: {}
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: fake1
+  annotations:
+    ops.kompox.dev/id: /ws/ws1/prv/fake1
+spec:
+  driver: fake
+  settings:
+    FAKE_STATE_FILE: state.json
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: cluster1
+  annotations:
+    ops.kompox.dev/id: /ws/ws1/prv/fake1/cls/cluster1
+spec:
+  existing: false
+  ingress:
+    certEmail: admin@example.com
+    domain: cluster1.example.com
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: app1
+  annotations:
+    ops.kompox.dev/id: /ws/ws1/prv/fake1/cls/cluster1/app/app1
+spec:
+  compose: file:compose.yml
+  ingress:
+    rules:
+      - name: app
+        port: 80
+        hosts:
+          - app1.example.com
+  volumes:
+    - name: db
+      size: 1Gi
+  deployment:
+    zone: "1"
+`
+
+const fakeFlowCompose = `services:
+  app:
+    image: nginx
+    ports:
+      - "80:80"
+    volumes:
+      - db:/data
+volumes:
+  db: {}
+`
+
+// TestFakeProviderFlow runs cluster, volume, app and DNS commands end to end against the fake
+// provider driver and its in-process cluster.
+func TestFakeProviderFlow(t *testing.T) {
+	t.Setenv("KOMPOX_ROOT", "")
+	t.Setenv("KOMPOX_DIR", "")
+	t.Setenv("KOMPOX_KOM_PATH", "")
+	t.Setenv("KOMPOX_KOM_APP", "")
+	t.Setenv("KOMPOX_LOG_OUTPUT", "none")
+
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".kompox/config.yml": "version: 1\nstore:\n  type: local\n",
+		"kompoxapp.yml":      fakeFlowKOM,
+		"compose.yml":        fakeFlowCompose,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting working directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("restoring working directory: %v", err)
+		}
+	}()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("changing to temp directory: %v", err)
+	}
+
+	// cluster provision --plan reports the changes without provisioning
+	var planOut bytes.Buffer
+	root := newRootCmd()
+	root.SetContext(context.Background())
+	root.SetOut(&planOut)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"cluster", "provision", "--plan", "--format", "json"})
+	if _, err := root.ExecuteC(); err != nil {
+		t.Fatalf("kompoxops cluster provision --plan: %v", err)
+	}
+	var plan model.ClusterPlan
+	if err := json.Unmarshal(planOut.Bytes(), &plan); err != nil {
+		t.Fatalf("decoding plan: %v", err)
+	}
+	if plan.Operation != model.ClusterPlanProvision || plan.Driver != "fake" || !plan.HasChanges() {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "state.json")); !os.IsNotExist(err) {
+		t.Errorf("plan must not write provider state: %v", err)
+	}
+
+	steps := [][]string{
+		{"cluster", "provision"},
+		{"cluster", "install"},
+		{"disk", "create", "-V", "db", "-N", "d1"},
+		{"disk", "assign", "-V", "db", "-N", "d1"},
+		{"snapshot", "create", "-V", "db", "-N", "s1"},
+		{"disk", "create", "-V", "db", "-N", "d2", "-S", "snapshot:s1"},
+		{"disk", "assign", "-V", "db", "-N", "d2"},
+		{"app", "deploy"},
+		{"dns", "deploy"},
+	}
+	for _, args := range steps {
+		root := newRootCmd()
+		root.SetContext(context.Background())
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		root.SetArgs(args)
+		if _, err := root.ExecuteC(); err != nil {
+			t.Fatalf("kompoxops %v: %v", args, err)
+		}
+	}
+
+	// cluster status prints the driver capability matrix
+	var out bytes.Buffer
+	root = newRootCmd()
+	root.SetContext(context.Background())
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"cluster", "status"})
+	if _, err := root.ExecuteC(); err != nil {
+		t.Fatalf("kompoxops cluster status: %v", err)
+	}
+	var status struct {
+		Installed    bool                     `json:"installed"`
+		Capabilities model.DriverCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		t.Fatalf("decoding cluster status: %v", err)
+	}
+	if caps := status.Capabilities; !status.Installed || caps.Driver != "fake" || !caps.Volumes[model.VolumeTypeDisk].Snapshot || !caps.NodePool.Create {
+		t.Errorf("unexpected cluster status: %+v", status)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "state.json"))
+	if err != nil {
+		t.Fatalf("reading state file: %v", err)
+	}
+	var state struct {
+		Providers map[string]struct {
+			Clusters map[string]struct {
+				Provisioned bool                       `json:"provisioned"`
+				Installed   bool                       `json:"installed"`
+				Objects     map[string]json.RawMessage `json:"objects"`
+			} `json:"clusters"`
+			Volumes map[string]struct {
+				Disks []struct {
+					Name         string `json:"name"`
+					Assigned     bool   `json:"assigned"`
+					Zone         string `json:"zone"`
+					SourceHandle string `json:"sourceHandle"`
+				} `json:"disks"`
+				Snapshots []struct {
+					Name string `json:"name"`
+				} `json:"snapshots"`
+			} `json:"volumes"`
+			DNSRecords map[string]struct {
+				RData []string `json:"rdata"`
+			} `json:"dnsRecords"`
+		} `json:"providers"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("decoding state file: %v", err)
+	}
+	prv := state.Providers["ws1/fake1"]
+	cls := prv.Clusters["cluster1"]
+	if !cls.Provisioned || !cls.Installed {
+		t.Errorf("unexpected cluster state: %+v", cls)
+	}
+
+	// app deploy applied the workload to the in-process cluster, whose Ingress got the
+	// ingress IP that dns deploy then published.
+	var deployments, ingresses int
+	for key, raw := range cls.Objects {
+		var obj struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				Template struct {
+					Spec struct {
+						Volumes []struct {
+							PersistentVolumeClaim *struct {
+								ClaimName string `json:"claimName"`
+							} `json:"persistentVolumeClaim"`
+						} `json:"volumes"`
+					} `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			t.Fatalf("decoding object %s: %v", key, err)
+		}
+		switch obj.Kind {
+		case "Deployment":
+			deployments++
+			if vols := obj.Spec.Template.Spec.Volumes; len(vols) == 0 || vols[0].PersistentVolumeClaim == nil {
+				t.Errorf("deployment %s does not mount the volume claim: %s", key, raw)
+			}
+		case "Ingress":
+			ingresses++
+		}
+	}
+	if deployments != 1 || ingresses == 0 {
+		t.Errorf("unexpected cluster objects: %d deployments, %d ingresses in %d objects", deployments, ingresses, len(cls.Objects))
+	}
+	if rec, ok := prv.DNSRecords["app1.example.com/A"]; !ok || len(rec.RData) != 1 || rec.RData[0] != "192.0.2.1" {
+		t.Errorf("unexpected DNS records: %+v", prv.DNSRecords)
+	}
+	if len(prv.Volumes) != 1 {
+		t.Fatalf("unexpected volumes: %+v", prv.Volumes)
+	}
+	for _, vol := range prv.Volumes {
+		if len(vol.Snapshots) != 1 || vol.Snapshots[0].Name != "s1" {
+			t.Errorf("unexpected snapshots: %+v", vol.Snapshots)
+		}
+		if len(vol.Disks) != 2 || vol.Disks[0].Assigned || !vol.Disks[1].Assigned {
+			t.Fatalf("unexpected disks: %+v", vol.Disks)
+		}
+		if d2 := vol.Disks[1]; d2.Zone != "1" || d2.SourceHandle == "" {
+			t.Errorf("restored disk not created from snapshot in zone 1: %+v", d2)
+		}
+	}
+}
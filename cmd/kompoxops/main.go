@@ -10,8 +10,15 @@ import (
 
 	"log/slog"
 
+	_ "github.com/kompox/kompox/adapters/drivers/dns/rfc2136"
+	_ "github.com/kompox/kompox/adapters/drivers/dns/webhook"
 	_ "github.com/kompox/kompox/adapters/drivers/provider/aks"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/eks"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/fake"
 	_ "github.com/kompox/kompox/adapters/drivers/provider/k3s"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/kubernetes"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/oke"
+	_ "github.com/kompox/kompox/adapters/drivers/provider/plugin"
 	"github.com/kompox/kompox/config/kompoxenv"
 	"github.com/kompox/kompox/internal/logging"
 	"github.com/kompox/kompox/internal/naming"
@@ -1,6 +1,7 @@
 package main
 
 import (
+	dnsdrv "github.com/kompox/kompox/adapters/drivers/dns"
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
 	"github.com/kompox/kompox/usecase/app"
 	"github.com/kompox/kompox/usecase/box"
@@ -21,8 +22,11 @@ func buildAppUseCase(cmd *cobra.Command) (*app.UseCase, error) {
 		return nil, err
 	}
 	return &app.UseCase{
-		Repos:      repos,
-		VolumePort: providerdrv.GetVolumePort(repos.Workspace, repos.Provider, repos.Cluster, repos.App),
+		Repos:        repos,
+		VolumePort:   providerdrv.GetVolumePort(repos.Workspace, repos.Provider, repos.Cluster, repos.App),
+		NodePoolPort: providerdrv.GetNodePoolPort(repos.Workspace, repos.Provider),
+		IdentityPort: providerdrv.GetAppIdentityPort(repos.Workspace, repos.Provider),
+		SecretPort:   providerdrv.GetSecretPort(repos.Workspace, repos.Provider),
 	}, nil
 }
 
@@ -63,8 +67,9 @@ func buildVolumeUseCase(cmd *cobra.Command) (*volume.UseCase, error) {
 		return nil, err
 	}
 	return &volume.UseCase{
-		Repos:      repos,
-		VolumePort: providerdrv.GetVolumePort(repos.Workspace, repos.Provider, repos.Cluster, repos.App),
+		Repos:               repos,
+		VolumePort:          providerdrv.GetVolumePort(repos.Workspace, repos.Provider, repos.Cluster, repos.App),
+		VolumeInventoryPort: providerdrv.GetVolumeInventoryPort(repos.Workspace),
 	}, nil
 }
 
@@ -98,6 +103,7 @@ func buildDNSUseCase(cmd *cobra.Command) (*dns.UseCase, error) {
 	return &dns.UseCase{
 		Repos:       repos,
 		ClusterPort: providerdrv.GetClusterPort(repos.Workspace, repos.Provider),
+		DNSPort:     dnsdrv.GetDNSPort(),
 	}, nil
 }
 
@@ -6,6 +6,8 @@ import (
 	"maps"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/kompox/kompox/domain/model"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -21,6 +23,124 @@ func isValidProtectionLevel(level model.ClusterProtectionLevel) bool {
 	}
 }
 
+// toModelDNSProvider converts a DNS provider spec. A nil spec yields nil.
+func toModelDNSProvider(spec *DNSProviderSpec) (*model.DNSProvider, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.Driver == "" {
+		return nil, fmt.Errorf("driver is required")
+	}
+	return &model.DNSProvider{
+		Driver:   spec.Driver,
+		Zones:    spec.Zones,
+		Settings: spec.Settings,
+	}, nil
+}
+
+// toModelSpotHandler converts the Spot eviction helper spec. fallbackPool is required and
+// must not be one of the watched Spot pools.
+func toModelSpotHandler(spec *ClusterSpotHandlerSpec) (*model.ClusterSpotHandler, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.FallbackPool == "" {
+		return nil, fmt.Errorf("fallbackPool is required")
+	}
+	if slices.Contains(spec.SpotPools, spec.FallbackPool) {
+		return nil, fmt.Errorf("fallbackPool %q must not be listed in spotPools", spec.FallbackPool)
+	}
+	return &model.ClusterSpotHandler{
+		Image:        spec.Image,
+		FallbackPool: spec.FallbackPool,
+		SpotPools:    slices.Clone(spec.SpotPools),
+	}, nil
+}
+
+// toModelRegistries converts declared cluster registries. Servers are required and must be
+// unique; relative "file:" password sources are resolved against the directory of docPath,
+// the KOM document declaring the cluster.
+func toModelRegistries(specs []ClusterRegistrySpec, docPath string) ([]model.ClusterRegistry, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(specs))
+	registries := make([]model.ClusterRegistry, 0, len(specs))
+	for i, s := range specs {
+		server := strings.TrimSpace(s.Server)
+		if server == "" {
+			return nil, fmt.Errorf("registries[%d]: server is required", i)
+		}
+		if strings.Contains(server, "/") {
+			return nil, fmt.Errorf("registries[%d]: server %q must be a registry host without scheme or path", i, server)
+		}
+		if seen[strings.ToLower(server)] {
+			return nil, fmt.Errorf("registries[%d]: duplicate server %q", i, server)
+		}
+		seen[strings.ToLower(server)] = true
+		if s.Username == "" || s.PasswordSource == "" {
+			return nil, fmt.Errorf("registries[%d]: username and passwordSource are required", i)
+		}
+		source := s.PasswordSource
+		if p, ok := strings.CutPrefix(source, "file:"); ok && p != "" && !filepath.IsAbs(p) && docPath != "" {
+			abs, err := filepath.Abs(filepath.Join(filepath.Dir(docPath), p))
+			if err != nil {
+				return nil, fmt.Errorf("registries[%d]: resolve passwordSource: %w", i, err)
+			}
+			source = "file:" + abs
+		}
+		registries = append(registries, model.ClusterRegistry{Server: server, Username: s.Username, PasswordSource: source})
+	}
+	return registries, nil
+}
+
+// toModelNodePools converts declared node pools to model node pools.
+// Pool names are required and must be unique within the cluster.
+func toModelNodePools(specs []ClusterNodePoolSpec) ([]model.NodePool, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(specs))
+	pools := make([]model.NodePool, 0, len(specs))
+	for i, s := range specs {
+		if s.Name == "" {
+			return nil, fmt.Errorf("nodePools[%d]: name is required", i)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("nodePools[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+		pool := model.NodePool{Name: &s.Name, Extensions: s.Extensions}
+		if s.ProviderName != "" {
+			pool.ProviderName = &s.ProviderName
+		}
+		if s.Mode != "" {
+			pool.Mode = &s.Mode
+		}
+		if s.Labels != nil {
+			pool.Labels = &s.Labels
+		}
+		if s.Zones != nil {
+			pool.Zones = &s.Zones
+		}
+		if s.InstanceType != "" {
+			pool.InstanceType = &s.InstanceType
+		}
+		if s.OSDiskType != "" {
+			pool.OSDiskType = &s.OSDiskType
+		}
+		pool.OSDiskSizeGiB = s.OSDiskSizeGiB
+		if s.Priority != "" {
+			pool.Priority = &s.Priority
+		}
+		if as := s.Autoscaling; as != nil {
+			pool.Autoscaling = &model.NodePoolAutoscaling{Enabled: as.Enabled, Min: as.Min, Max: as.Max, Desired: as.Desired}
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
 // Repositories defines the repository interfaces needed for converting CRD to domain models.
 type Repositories struct {
 	Workspace WorkspaceRepository
@@ -83,6 +203,56 @@ func parseVolumeSize(size any) (int64, error) {
 	return 0, fmt.Errorf("unsupported type %T (expected int64, int, float64, or string)", size)
 }
 
+// toModelAppStandby converts the standby spec of an app in cluster clsID. The standby
+// cluster must be defined in the sink and differ from the app cluster.
+func (s *Sink) toModelAppStandby(clsID string, spec *AppStandbySpec) (*model.AppStandby, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	fqn, kind, err := ParseResourceID(spec.ClusterID)
+	if err != nil {
+		return nil, fmt.Errorf("clusterId: %w", err)
+	}
+	if kind != "Cluster" {
+		return nil, fmt.Errorf("clusterId %q must refer to a Cluster, got %s", spec.ClusterID, kind)
+	}
+	if fqn.String() == clsID {
+		return nil, fmt.Errorf("clusterId %q must differ from the app cluster", spec.ClusterID)
+	}
+	if _, ok := s.GetCluster(fqn); !ok {
+		return nil, fmt.Errorf("cluster %q not found", spec.ClusterID)
+	}
+	if spec.Retain < 0 {
+		return nil, fmt.Errorf("retain must not be negative")
+	}
+	standby := &model.AppStandby{ClusterID: fqn.String(), Retain: spec.Retain}
+	if spec.Interval != "" {
+		if standby.Interval, err = time.ParseDuration(spec.Interval); err != nil {
+			return nil, fmt.Errorf("interval: %w", err)
+		}
+		if standby.Interval <= 0 {
+			return nil, fmt.Errorf("interval must be positive")
+		}
+	}
+	return standby, nil
+}
+
+// toModelAppIdentity converts the identity spec of an app. Every role requires a scope and a role.
+func toModelAppIdentity(spec *AppIdentitySpec) (*model.AppIdentity, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	identity := &model.AppIdentity{}
+	for i, r := range spec.Roles {
+		scope, role := strings.TrimSpace(r.Scope), strings.TrimSpace(r.Role)
+		if scope == "" || role == "" {
+			return nil, fmt.Errorf("roles[%d]: scope and role are required", i)
+		}
+		identity.Roles = append(identity.Roles, model.AppIdentityRole{Scope: scope, Role: role})
+	}
+	return identity, nil
+}
+
 func validateAppDeploymentSpec(appName string, dep *AppDeploymentSpec) error {
 	if dep == nil {
 		return nil
@@ -93,6 +263,22 @@ func validateAppDeploymentSpec(appName string, dep *AppDeploymentSpec) error {
 	if dep.Zone != "" && len(dep.Zones) > 0 {
 		return fmt.Errorf("app %q: deployment.zone and deployment.zones cannot be specified together", appName)
 	}
+	if len(dep.PoolPreferences) > 0 && (dep.Pool != "" || len(dep.Pools) > 0) {
+		return fmt.Errorf("app %q: deployment.poolPreferences cannot be combined with deployment.pool or deployment.pools", appName)
+	}
+	seen := map[string]bool{}
+	for i, p := range dep.PoolPreferences {
+		if p.Pool == "" {
+			return fmt.Errorf("app %q: deployment.poolPreferences[%d].pool is required", appName, i)
+		}
+		if seen[p.Pool] {
+			return fmt.Errorf("app %q: deployment.poolPreferences[%d]: duplicate pool %q", appName, i, p.Pool)
+		}
+		seen[p.Pool] = true
+		if p.Weight < 0 || p.Weight > 100 {
+			return fmt.Errorf("app %q: deployment.poolPreferences[%d].weight must be between 1 and 100", appName, i)
+		}
+	}
 	if len(dep.Selectors) > 0 {
 		return fmt.Errorf("app %q: deployment.selectors is reserved and not supported yet", appName)
 	}
@@ -121,9 +307,14 @@ func (s *Sink) ToModels(ctx context.Context, repos Repositories, kompoxAppFilePa
 		if err != nil {
 			return fmt.Errorf("failed to extract Resource ID for workspace %q: %w", ws.ObjectMeta.Name, err)
 		}
+		dns, err := toModelDNSProvider(ws.Spec.DNS)
+		if err != nil {
+			return fmt.Errorf("invalid dns for workspace %q: %w", ws.ObjectMeta.Name, err)
+		}
 		workspace := &model.Workspace{
 			ID:   fqn.String(),
 			Name: ws.ObjectMeta.Name,
+			DNS:  dns,
 		}
 		if err := repos.Workspace.Create(ctx, workspace); err != nil {
 			return fmt.Errorf("failed to create workspace %q: %w", ws.ObjectMeta.Name, err)
@@ -210,6 +401,18 @@ func (s *Sink) ToModels(ctx context.Context, repos Repositories, kompoxAppFilePa
 				Installation: installation,
 			}
 		}
+		if cluster.DNS, err = toModelDNSProvider(cls.Spec.DNS); err != nil {
+			return fmt.Errorf("invalid dns for cluster %q: %w", cls.ObjectMeta.Name, err)
+		}
+		if cluster.NodePools, err = toModelNodePools(cls.Spec.NodePools); err != nil {
+			return fmt.Errorf("invalid nodePools for cluster %q: %w", cls.ObjectMeta.Name, err)
+		}
+		if cluster.SpotHandler, err = toModelSpotHandler(cls.Spec.SpotHandler); err != nil {
+			return fmt.Errorf("invalid spotHandler for cluster %q: %w", cls.ObjectMeta.Name, err)
+		}
+		if cluster.Registries, err = toModelRegistries(cls.Spec.Registries, cls.ObjectMeta.Annotations[AnnotationDocPath]); err != nil {
+			return fmt.Errorf("invalid registries for cluster %q: %w", cls.ObjectMeta.Name, err)
+		}
 		if err := repos.Cluster.Create(ctx, cluster); err != nil {
 			return fmt.Errorf("failed to create cluster %q: %w", cls.ObjectMeta.Name, err)
 		}
@@ -304,6 +507,9 @@ func (s *Sink) ToModels(ctx context.Context, repos Repositories, kompoxAppFilePa
 				Zones:     slices.Clone(app.Spec.Deployment.Zones),
 				Selectors: maps.Clone(app.Spec.Deployment.Selectors),
 			}
+			for _, p := range app.Spec.Deployment.PoolPreferences {
+				domainApp.Deployment.PoolPreferences = append(domainApp.Deployment.PoolPreferences, model.AppPoolPreference{Pool: p.Pool, Weight: p.Weight})
+			}
 		}
 
 		// Convert NetworkPolicy if present
@@ -391,6 +597,13 @@ func (s *Sink) ToModels(ctx context.Context, repos Repositories, kompoxAppFilePa
 			}
 		}
 
+		if domainApp.Standby, err = s.toModelAppStandby(clsID, app.Spec.Standby); err != nil {
+			return fmt.Errorf("invalid standby for app %q: %w", app.ObjectMeta.Name, err)
+		}
+		if domainApp.Identity, err = toModelAppIdentity(app.Spec.Identity); err != nil {
+			return fmt.Errorf("invalid identity for app %q: %w", app.ObjectMeta.Name, err)
+		}
+
 		if err := repos.App.Create(ctx, domainApp); err != nil {
 			return fmt.Errorf("failed to create app %q: %w", app.ObjectMeta.Name, err)
 		}
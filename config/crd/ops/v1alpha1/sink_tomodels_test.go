@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kompox/kompox/domain/model"
 )
@@ -385,6 +387,331 @@ spec:
 				// This test verifies that ToModels catches the validation error
 			},
 		},
+		{
+			name: "workspace and cluster with dns provider",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: dns-ws
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws
+spec:
+  dns:
+    driver: webhook
+    settings:
+      WEBHOOK_URL: https://dns.example.com/hook
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: dns-prv
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws/prv/dns-prv
+spec:
+  driver: k3s
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: dns-cls
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws/prv/dns-prv/cls/dns-cls
+spec:
+  dns:
+    driver: rfc2136
+    zones: [example.com]
+    settings:
+      RFC2136_SERVER: 192.0.2.53
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				workspaces, _ := repos.Workspace.List(context.Background())
+				if len(workspaces) != 1 || workspaces[0].DNS == nil || workspaces[0].DNS.Driver != "webhook" || workspaces[0].DNS.Settings["WEBHOOK_URL"] == "" {
+					t.Errorf("unexpected workspace dns: %+v", workspaces[0].DNS)
+				}
+				clusters, _ := repos.Cluster.List(context.Background())
+				if len(clusters) != 1 || clusters[0].DNS == nil {
+					t.Fatalf("expected cluster dns, got %+v", clusters)
+				}
+				if dns := clusters[0].DNS; dns.Driver != "rfc2136" || len(dns.Zones) != 1 || dns.Zones[0] != "example.com" || dns.Settings["RFC2136_SERVER"] != "192.0.2.53" {
+					t.Errorf("unexpected cluster dns: %+v", dns)
+				}
+			},
+		},
+		{
+			name: "cluster with node pools",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: np-ws
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: np-prv
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws/prv/np-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: np-cls
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws/prv/np-prv/cls/np-cls
+spec:
+  nodePools:
+    - name: system
+      mode: system
+    - name: user
+      instanceType: Standard_D4s_v3
+      zones: ["1", "2"]
+      labels:
+        team: a
+      autoscaling:
+        enabled: true
+        min: 1
+        max: 3
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				clusters, _ := repos.Cluster.List(context.Background())
+				if len(clusters) != 1 || len(clusters[0].NodePools) != 2 {
+					t.Fatalf("expected 2 node pools, got %+v", clusters)
+				}
+				sys, user := clusters[0].NodePools[0], clusters[0].NodePools[1]
+				if *sys.Name != "system" || *sys.Mode != "system" || sys.InstanceType != nil || sys.Autoscaling != nil {
+					t.Errorf("unexpected system pool: %+v", sys)
+				}
+				if *user.InstanceType != "Standard_D4s_v3" || len(*user.Zones) != 2 || (*user.Labels)["team"] != "a" || !user.Autoscaling.Enabled || user.Autoscaling.Max != 3 {
+					t.Errorf("unexpected user pool: %+v", user)
+				}
+			},
+		},
+		{
+			name: "cluster with spot handler",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: spot-ws
+  annotations:
+    ops.kompox.dev/id: /ws/spot-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: spot-prv
+  annotations:
+    ops.kompox.dev/id: /ws/spot-ws/prv/spot-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: spot-cls
+  annotations:
+    ops.kompox.dev/id: /ws/spot-ws/prv/spot-prv/cls/spot-cls
+spec:
+  spotHandler:
+    fallbackPool: user
+    spotPools: ["spot1", "spot2"]
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				clusters, _ := repos.Cluster.List(context.Background())
+				if len(clusters) != 1 || clusters[0].SpotHandler == nil {
+					t.Fatalf("expected spot handler, got %+v", clusters)
+				}
+				sh := clusters[0].SpotHandler
+				if sh.FallbackPool != "user" || len(sh.SpotPools) != 2 || sh.Image != "" {
+					t.Errorf("unexpected spot handler: %+v", sh)
+				}
+			},
+		},
+		{
+			name: "cluster with registries",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: reg-ws
+  annotations:
+    ops.kompox.dev/id: /ws/reg-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: reg-prv
+  annotations:
+    ops.kompox.dev/id: /ws/reg-ws/prv/reg-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: reg-cls
+  annotations:
+    ops.kompox.dev/id: /ws/reg-ws/prv/reg-prv/cls/reg-cls
+spec:
+  registries:
+    - server: ghcr.io
+      username: bot
+      passwordSource: file:secrets/ghcr-token
+    - server: registry.example.com:5000
+      username: deploy
+      passwordSource: https://myvault.vault.azure.net/secrets/registry-password
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				clusters, _ := repos.Cluster.List(context.Background())
+				if len(clusters) != 1 || len(clusters[0].Registries) != 2 {
+					t.Fatalf("expected 2 registries, got %+v", clusters)
+				}
+				ghcr, private := clusters[0].Registries[0], clusters[0].Registries[1]
+				path, ok := strings.CutPrefix(ghcr.PasswordSource, "file:")
+				if ghcr.Server != "ghcr.io" || ghcr.Username != "bot" || !ok || !filepath.IsAbs(path) || !strings.HasSuffix(path, filepath.Join("secrets", "ghcr-token")) {
+					t.Errorf("unexpected ghcr registry: %+v", ghcr)
+				}
+				if private.Server != "registry.example.com:5000" || private.PasswordSource != "https://myvault.vault.azure.net/secrets/registry-password" {
+					t.Errorf("unexpected private registry: %+v", private)
+				}
+			},
+		},
+		{
+			name: "cluster registries with duplicate server",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: reg2-ws
+  annotations:
+    ops.kompox.dev/id: /ws/reg2-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: reg2-prv
+  annotations:
+    ops.kompox.dev/id: /ws/reg2-ws/prv/reg2-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: reg2-cls
+  annotations:
+    ops.kompox.dev/id: /ws/reg2-ws/prv/reg2-prv/cls/reg2-cls
+spec:
+  registries:
+    - server: ghcr.io
+      username: bot
+      passwordSource: file:/tmp/a
+    - server: GHCR.io
+      username: bot2
+      passwordSource: file:/tmp/b
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "cluster spot handler with fallback pool in spot pools",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: spot2-ws
+  annotations:
+    ops.kompox.dev/id: /ws/spot2-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: spot2-prv
+  annotations:
+    ops.kompox.dev/id: /ws/spot2-ws/prv/spot2-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: spot2-cls
+  annotations:
+    ops.kompox.dev/id: /ws/spot2-ws/prv/spot2-prv/cls/spot2-cls
+spec:
+  spotHandler:
+    fallbackPool: spot1
+    spotPools: ["spot1"]
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "cluster node pools with duplicate name",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: np-ws2
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws2
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: np-prv2
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws2/prv/np-prv2
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: np-cls2
+  annotations:
+    ops.kompox.dev/id: /ws/np-ws2/prv/np-prv2/cls/np-cls2
+spec:
+  nodePools:
+    - name: user
+    - name: user
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "cluster dns without driver",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: dns-ws2
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws2
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: dns-prv2
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws2/prv/dns-prv2
+spec:
+  driver: k3s
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: dns-cls2
+  annotations:
+    ops.kompox.dev/id: /ws/dns-ws2/prv/dns-prv2/cls/dns-cls2
+spec:
+  dns:
+    zones: [example.com]
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
 		{
 			name: "missing parent workspace - should fail at NewSink validation",
 			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
@@ -806,6 +1133,139 @@ spec:
 				}
 			},
 		},
+		{
+			name: "app deployment with pool preferences",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: deppref-ws
+  annotations:
+    ops.kompox.dev/id: /ws/deppref-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: deppref-prv
+  annotations:
+    ops.kompox.dev/id: /ws/deppref-ws/prv/deppref-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: deppref-cls
+  annotations:
+    ops.kompox.dev/id: /ws/deppref-ws/prv/deppref-prv/cls/deppref-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: deppref-app
+  annotations:
+    ops.kompox.dev/id: /ws/deppref-ws/prv/deppref-prv/cls/deppref-cls/app/deppref-app
+spec:
+  compose: "services: {}"
+  deployment:
+    poolPreferences:
+    - pool: spot
+    - pool: user
+      weight: 10
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				apps, _ := repos.App.List(context.Background())
+				if len(apps) != 1 {
+					t.Fatalf("expected 1 app, got %d", len(apps))
+				}
+				prefs := apps[0].Deployment.PoolPreferences
+				if len(prefs) != 2 || prefs[0].Pool != "spot" || prefs[0].Weight != 0 || prefs[1].Pool != "user" || prefs[1].Weight != 10 {
+					t.Errorf("unexpected pool preferences: %+v", prefs)
+				}
+			},
+		},
+		{
+			name: "app deployment with pool preferences and pools should fail",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: depbad3-ws
+  annotations:
+    ops.kompox.dev/id: /ws/depbad3-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: depbad3-prv
+  annotations:
+    ops.kompox.dev/id: /ws/depbad3-ws/prv/depbad3-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: depbad3-cls
+  annotations:
+    ops.kompox.dev/id: /ws/depbad3-ws/prv/depbad3-prv/cls/depbad3-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: depbad3-app
+  annotations:
+    ops.kompox.dev/id: /ws/depbad3-ws/prv/depbad3-prv/cls/depbad3-cls/app/depbad3-app
+spec:
+  compose: "services: {}"
+  deployment:
+    pools: ["npuser1"]
+    poolPreferences:
+    - pool: spot
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "app deployment with duplicate pool preferences should fail",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: depbad4-ws
+  annotations:
+    ops.kompox.dev/id: /ws/depbad4-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: depbad4-prv
+  annotations:
+    ops.kompox.dev/id: /ws/depbad4-ws/prv/depbad4-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: depbad4-cls
+  annotations:
+    ops.kompox.dev/id: /ws/depbad4-ws/prv/depbad4-prv/cls/depbad4-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: depbad4-app
+  annotations:
+    ops.kompox.dev/id: /ws/depbad4-ws/prv/depbad4-prv/cls/depbad4-cls/app/depbad4-app
+spec:
+  compose: "services: {}"
+  deployment:
+    poolPreferences:
+    - pool: spot
+    - pool: spot
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
 		{
 			name: "app deployment with pool and pools should fail",
 			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
@@ -922,6 +1382,248 @@ spec:
   deployment:
     selectors:
       disktype: ssd
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "app with standby cluster",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: stb-ws
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: stb-prv
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls2
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls2
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: stb-app
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls/app/stb-app
+spec:
+  compose: "services: {}"
+  standby:
+    clusterId: /ws/stb-ws/prv/stb-prv/cls/stb-cls2
+    interval: 30m
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				apps, _ := repos.App.List(context.Background())
+				if len(apps) != 1 {
+					t.Fatalf("expected 1 app, got %d", len(apps))
+				}
+				sb := apps[0].Standby
+				if sb == nil || sb.ClusterID != "/ws/stb-ws/prv/stb-prv/cls/stb-cls2" {
+					t.Fatalf("unexpected standby: %+v", sb)
+				}
+				if sb.ReplicationInterval() != 30*time.Minute || sb.RetainCount() != model.DefaultStandbyRetain {
+					t.Errorf("unexpected standby interval/retain: %v/%d", sb.ReplicationInterval(), sb.RetainCount())
+				}
+			},
+		},
+		{
+			name: "app with standby on own cluster should fail",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: stb-ws
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: stb-prv
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls2
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls2
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: stb-app
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls/app/stb-app
+spec:
+  compose: "services: {}"
+  standby:
+    clusterId: /ws/stb-ws/prv/stb-prv/cls/stb-cls
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "app with unknown standby cluster should fail",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: stb-ws
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: stb-prv
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: stb-cls2
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls2
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: stb-app
+  annotations:
+    ops.kompox.dev/id: /ws/stb-ws/prv/stb-prv/cls/stb-cls/app/stb-app
+spec:
+  compose: "services: {}"
+  standby:
+    clusterId: /ws/stb-ws/prv/stb-prv/cls/missing
+`,
+			wantErr:  true,
+			validate: func(t *testing.T, repos Repositories) {},
+		},
+		{
+			name: "app with identity roles",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: id-ws
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: id-prv
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: id-cls
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv/cls/id-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: id-app
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv/cls/id-cls/app/id-app
+spec:
+  compose: "services: {}"
+  identity:
+    roles:
+    - scope: /subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.KeyVault/vaults/kv1
+      role: Key Vault Secrets User
+`,
+			wantErr: false,
+			validate: func(t *testing.T, repos Repositories) {
+				apps, _ := repos.App.List(context.Background())
+				if len(apps) != 1 {
+					t.Fatalf("expected 1 app, got %d", len(apps))
+				}
+				id := apps[0].Identity
+				if id == nil || len(id.Roles) != 1 || id.Roles[0].Role != "Key Vault Secrets User" ||
+					id.Roles[0].Scope != "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.KeyVault/vaults/kv1" {
+					t.Fatalf("unexpected identity: %+v", id)
+				}
+			},
+		},
+		{
+			name: "app with identity role without scope should fail",
+			yamlContent: `apiVersion: ops.kompox.dev/v1alpha1
+kind: Workspace
+metadata:
+  name: id-ws
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Provider
+metadata:
+  name: id-prv
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv
+spec:
+  driver: aks
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: Cluster
+metadata:
+  name: id-cls
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv/cls/id-cls
+---
+apiVersion: ops.kompox.dev/v1alpha1
+kind: App
+metadata:
+  name: id-app
+  annotations:
+    ops.kompox.dev/id: /ws/id-ws/prv/id-prv/cls/id-cls/app/id-app
+spec:
+  compose: "services: {}"
+  identity:
+    roles:
+    - role: Reader
 `,
 			wantErr:  true,
 			validate: func(t *testing.T, repos Repositories) {},
@@ -38,6 +38,20 @@ type Workspace struct {
 type WorkspaceSpec struct {
 	// Settings stores workspace-level configuration.
 	Settings map[string]string `json:"settings,omitzero"`
+	// DNS configures a standalone DNS provider for clusters of the workspace.
+	DNS *DNSProviderSpec `json:"dns,omitzero"`
+}
+
+// DNSProviderSpec configures a standalone DNS provider that writes DNS records
+// independently of the cluster provider driver.
+type DNSProviderSpec struct {
+	// Driver specifies the DNS provider implementation (e.g., "rfc2136", "webhook").
+	Driver string `json:"driver"`
+	// Zones are the DNS zones served by the provider. FQDNs outside these zones are
+	// written by the cluster provider driver. Empty routes every FQDN to the provider.
+	Zones []string `json:"zones,omitzero"`
+	// Settings stores driver-specific configuration.
+	Settings map[string]string `json:"settings,omitzero"`
 }
 
 // WorkspaceStatus defines the observed state of Workspace.
@@ -113,6 +127,15 @@ type ClusterSpec struct {
 	Ingress *ClusterIngressSpec `json:"ingress,omitzero"`
 	// Protection defines lifecycle operation guards.
 	Protection *ClusterProtectionSpec `json:"protection,omitzero"`
+	// DNS configures a standalone DNS provider. Overrides the workspace DNS provider.
+	DNS *DNSProviderSpec `json:"dns,omitzero"`
+	// NodePools declares the desired node pools reconciled by "cluster nodepool sync".
+	NodePools []ClusterNodePoolSpec `json:"nodePools,omitzero"`
+	// SpotHandler installs the Spot eviction helper with "cluster install".
+	SpotHandler *ClusterSpotHandlerSpec `json:"spotHandler,omitzero"`
+	// Registries are private container registry credentials distributed as image pull
+	// secrets to the apps pulling images from them at deploy.
+	Registries []ClusterRegistrySpec `json:"registries,omitzero"`
 	// Settings stores cluster-level configuration.
 	Settings map[string]string `json:"settings,omitzero"`
 }
@@ -131,6 +154,66 @@ type ClusterProtectionSpec struct {
 	Installation string `json:"installation,omitzero"`
 }
 
+// ClusterSpotHandlerSpec configures the in-cluster Spot eviction helper.
+type ClusterSpotHandlerSpec struct {
+	// Image is the container image providing kompoxops (default: the Kompox box image).
+	Image string `json:"image,omitzero"`
+	// FallbackPool is the regular node pool that apps on evicted Spot nodes are moved to.
+	FallbackPool string `json:"fallbackPool"`
+	// SpotPools are the node pools to watch. Defaults to nodePools with priority "spot".
+	SpotPools []string `json:"spotPools,omitzero"`
+}
+
+// ClusterRegistrySpec declares the credential of a private container registry.
+type ClusterRegistrySpec struct {
+	// Server is the registry host (e.g., "ghcr.io", "registry.example.com:5000").
+	Server string `json:"server"`
+	// Username is the registry user name.
+	Username string `json:"username"`
+	// PasswordSource locates the password or access token: "file:<path>" (relative to the
+	// KOM document) or a provider-specific locator (e.g., Key Vault secret URL for AKS).
+	PasswordSource string `json:"passwordSource"`
+}
+
+// ClusterNodePoolSpec defines the desired state of a node pool.
+// Unset fields are left to the driver defaults and are not compared during sync.
+type ClusterNodePoolSpec struct {
+	// Name is the logical node pool name.
+	Name string `json:"name"`
+	// ProviderName is the provider-specific pool name if it differs from Name.
+	ProviderName string `json:"providerName,omitzero"`
+	// Mode is the pool purpose: "system" or "user".
+	Mode string `json:"mode,omitzero"`
+	// Labels are Kubernetes node labels applied to nodes in the pool.
+	Labels map[string]string `json:"labels,omitzero"`
+	// Zones are availability zones for node placement.
+	Zones []string `json:"zones,omitzero"`
+	// InstanceType is the VM/machine type.
+	InstanceType string `json:"instanceType,omitzero"`
+	// OSDiskType is the OS disk type (e.g., "Managed", "Ephemeral").
+	OSDiskType string `json:"osDiskType,omitzero"`
+	// OSDiskSizeGiB is the OS disk size in GiB.
+	OSDiskSizeGiB *int `json:"osDiskSizeGiB,omitzero"`
+	// Priority is the VM priority: "regular" or "spot".
+	Priority string `json:"priority,omitzero"`
+	// Autoscaling configures the node count.
+	Autoscaling *ClusterNodePoolAutoscalingSpec `json:"autoscaling,omitzero"`
+	// Extensions holds provider-specific fields.
+	Extensions map[string]any `json:"extensions,omitzero"`
+}
+
+// ClusterNodePoolAutoscalingSpec defines the node count of a node pool.
+type ClusterNodePoolAutoscalingSpec struct {
+	// Enabled turns on the cluster autoscaler for the pool.
+	Enabled bool `json:"enabled,omitzero"`
+	// Min is the minimum node count when autoscaling is enabled.
+	Min int `json:"min,omitzero"`
+	// Max is the maximum node count when autoscaling is enabled.
+	Max int `json:"max,omitzero"`
+	// Desired is the fixed node count when autoscaling is disabled.
+	Desired *int `json:"desired,omitzero"`
+}
+
 // ClusterIngressSpec defines cluster-level ingress configuration.
 type ClusterIngressSpec struct {
 	// Namespace is the namespace where the ingress controller runs.
@@ -186,6 +269,10 @@ type AppSpec struct {
 	Deployment *AppDeploymentSpec `json:"deployment,omitzero"`
 	// NetworkPolicy defines network policy configuration for the app.
 	NetworkPolicy *AppNetworkPolicySpec `json:"networkPolicy,omitzero"`
+	// Standby defines a second cluster that receives snapshot replicas for failover.
+	Standby *AppStandbySpec `json:"standby,omitzero"`
+	// Identity defines the cloud workload identity of the app and the roles granted to it.
+	Identity *AppIdentitySpec `json:"identity,omitzero"`
 	// Resources stores resource-related configuration.
 	Resources map[string]string `json:"resources,omitzero"`
 	// Settings stores app-level configuration.
@@ -235,10 +322,50 @@ type AppDeploymentSpec struct {
 	Zone string `json:"zone,omitzero"`
 	// Zones specifies multiple availability zones for deployment.
 	Zones []string `json:"zones,omitzero"`
+	// PoolPreferences lists node pools in order of preference with optional weights.
+	// Cannot be combined with pool or pools.
+	PoolPreferences []AppPoolPreferenceSpec `json:"poolPreferences,omitzero"`
 	// Selectors is reserved for future scheduling extensions.
 	Selectors map[string]string `json:"selectors,omitzero"`
 }
 
+// AppPoolPreferenceSpec defines a preferred node pool of an app.
+type AppPoolPreferenceSpec struct {
+	// Pool is the node pool name.
+	Pool string `json:"pool"`
+	// Weight is the preferred scheduling weight (1-100). Defaults to 100 for the first
+	// entry, decreasing by 10 for each following entry.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int `json:"weight,omitzero"`
+}
+
+// AppStandbySpec defines the standby cluster of an app.
+type AppStandbySpec struct {
+	// ClusterID is the Resource ID of the standby cluster (e.g., /ws/ws1/prv/prv2/cls/cls2).
+	ClusterID string `json:"clusterId"`
+	// Interval is the replication interval as a Go duration (defaults to "1h").
+	Interval string `json:"interval,omitzero"`
+	// Retain is the number of replicas kept per volume (defaults to 3).
+	// +kubebuilder:validation:Minimum=0
+	Retain int `json:"retain,omitzero"`
+}
+
+// AppIdentitySpec defines the cloud workload identity of an app. The provider driver creates
+// the identity and binds it to the app ServiceAccount at deploy.
+type AppIdentitySpec struct {
+	// Roles are the roles granted to the identity.
+	Roles []AppIdentityRoleSpec `json:"roles,omitzero"`
+}
+
+// AppIdentityRoleSpec grants a role on a cloud resource to the app identity.
+type AppIdentityRoleSpec struct {
+	// Scope is the cloud resource ID the role is granted on.
+	Scope string `json:"scope"`
+	// Role is the provider-specific role name or ID (e.g., "Key Vault Secrets User").
+	Role string `json:"role"`
+}
+
 // AppNetworkPolicySpec defines network policy configuration for the app.
 type AppNetworkPolicySpec struct {
 	// IngressRules defines additional ingress rules to allow.
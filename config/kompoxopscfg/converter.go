@@ -72,6 +72,7 @@ func (r *Root) ToModels(configFilePath string) (*model.Workspace, *model.Provide
 		ProviderID: providerID,
 		Existing:   r.Cluster.Existing,
 		Ingress:    toModelClusterIngress(r.Cluster.Ingress),
+		DNS:        toModelDNSProvider(r.Cluster.DNS),
 		Settings:   r.Cluster.Settings,
 		CreatedAt:  now,
 		UpdatedAt:  now,
@@ -198,6 +199,13 @@ func toModelAppDeployment(ad AppDeployment) model.AppDeployment {
 }
 
 // toModelClusterIngress converts config ClusterIngress to domain ClusterIngress pointer.
+func toModelDNSProvider(d *ClusterDNS) *model.DNSProvider {
+	if d == nil {
+		return nil
+	}
+	return &model.DNSProvider{Driver: d.Driver, Zones: d.Zones, Settings: d.Settings}
+}
+
 func toModelClusterIngress(ci ClusterIngress) *model.ClusterIngress {
 	// If all fields are empty, return nil to indicate unspecified
 	if ci.Namespace == "" && ci.Controller == "" && ci.ServiceAccount == "" && ci.Domain == "" && ci.CertResolver == "" && ci.CertEmail == "" && len(ci.Certificates) == 0 {
@@ -29,9 +29,18 @@ type Cluster struct {
 	Name     string            `yaml:"name"`
 	Existing bool              `yaml:"existing"` // whether to use existing cluster
 	Ingress  ClusterIngress    `yaml:"ingress"`  // ingress configuration
+	DNS      *ClusterDNS       `yaml:"dns"`      // standalone DNS provider (optional)
 	Settings map[string]string `yaml:"settings"` // cluster-specific settings
 }
 
+// ClusterDNS configures a standalone DNS provider that writes DNS records instead of
+// the provider driver. FQDNs outside Zones are still written by the provider driver.
+type ClusterDNS struct {
+	Driver   string            `yaml:"driver"`             // e.g., "rfc2136", "webhook"
+	Zones    []string          `yaml:"zones,omitempty"`    // zones served; empty routes every FQDN
+	Settings map[string]string `yaml:"settings,omitempty"` // driver-specific settings
+}
+
 // ClusterIngress represents cluster-level ingress settings.
 // Namespace: Kubernetes namespace for ingress controller resources
 // Controller: Ingress controller type (e.g., "traefik")
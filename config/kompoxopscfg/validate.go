@@ -9,6 +9,9 @@ import (
 
 // Validate performs semantic validation on the configuration tree.
 func (r *Root) Validate() error {
+	if r.Cluster.DNS != nil && r.Cluster.DNS.Driver == "" {
+		return fmt.Errorf("cluster: dns.driver is required")
+	}
 	if err := r.App.validate(); err != nil {
 		return fmt.Errorf("app: %w", err)
 	}
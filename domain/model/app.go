@@ -15,6 +15,8 @@ type App struct {
 	Volumes       []AppVolume
 	Deployment    AppDeployment
 	NetworkPolicy AppNetworkPolicy
+	Standby       *AppStandby
+	Identity      *AppIdentity
 	Resources     map[string]string
 	Settings      map[string]string
 	CreatedAt     time.Time
@@ -63,10 +65,110 @@ type AppDeployment struct {
 	Zone string
 	// Zones specifies multiple availability zones for deployment.
 	Zones []string
+	// PoolPreferences lists node pools in order of preference.
+	// Pods are restricted to the listed pools and prefer them by weight.
+	// Cannot be combined with Pool or Pools.
+	PoolPreferences []AppPoolPreference
 	// Selectors is reserved for future scheduling extensions.
 	Selectors map[string]string
 }
 
+// AppPoolPreference is an entry of AppDeployment.PoolPreferences.
+type AppPoolPreference struct {
+	// Pool is the node pool name.
+	Pool string
+	// Weight is the preferred scheduling weight (1-100).
+	// Zero derives the weight from the position: 100 for the first entry, decreasing by 10.
+	Weight int
+}
+
+// PoolPreferenceWeight returns the effective weight of the i-th pool preference.
+func (d AppDeployment) PoolPreferenceWeight(i int) int {
+	if w := d.PoolPreferences[i].Weight; w > 0 {
+		return w
+	}
+	return max(100-10*i, 1)
+}
+
+// TargetPools returns the node pools the app may be scheduled to, in order of preference:
+// the pool preferences, Pools, or Pool ("user" by default).
+func (d AppDeployment) TargetPools() []string {
+	if len(d.PoolPreferences) > 0 {
+		pools := make([]string, 0, len(d.PoolPreferences))
+		for _, p := range d.PoolPreferences {
+			pools = append(pools, p.Pool)
+		}
+		return pools
+	}
+	if len(d.Pools) > 0 {
+		return d.Pools
+	}
+	if d.Pool != "" {
+		return []string{d.Pool}
+	}
+	return []string{"user"}
+}
+
+// Defaults of AppStandby.
+const (
+	DefaultStandbyInterval = time.Hour
+	DefaultStandbyRetain   = 3
+)
+
+// AppStandby defines a standby cluster for disaster recovery. Snapshots of the app volumes
+// are replicated to the provider of the standby cluster (typically in another region), and
+// failover creates the disks from the latest replicas and deploys the app there.
+type AppStandby struct {
+	// ClusterID references the standby Cluster. It must differ from App.ClusterID.
+	ClusterID string
+	// Interval is the replication interval (the target recovery point). Zero means DefaultStandbyInterval.
+	Interval time.Duration
+	// Retain is the number of replicas kept per volume on each side. Zero means DefaultStandbyRetain.
+	Retain int
+}
+
+// ReplicationInterval returns the effective replication interval.
+func (s *AppStandby) ReplicationInterval() time.Duration {
+	if s.Interval > 0 {
+		return s.Interval
+	}
+	return DefaultStandbyInterval
+}
+
+// RetainCount returns the effective number of replicas kept per volume.
+func (s *AppStandby) RetainCount() int {
+	if s.Retain > 0 {
+		return s.Retain
+	}
+	return DefaultStandbyRetain
+}
+
+// OnStandby returns a copy of the app bound to the standby cluster. Volume operations on the
+// copy address the disks and snapshots of the app in the standby provider.
+func (app *App) OnStandby() (*App, error) {
+	if app.Standby == nil || app.Standby.ClusterID == "" {
+		return nil, fmt.Errorf("app %s has no standby cluster", app.Name)
+	}
+	standby := *app
+	standby.ClusterID = app.Standby.ClusterID
+	return &standby, nil
+}
+
+// AppIdentity defines the cloud identity of the app workload. The provider driver creates
+// one identity per app, federates it with the app ServiceAccount of each cluster namespace
+// and grants the roles, so that the app containers access cloud resources without secrets.
+type AppIdentity struct {
+	Roles []AppIdentityRole
+}
+
+// AppIdentityRole is a role granted to the app identity.
+type AppIdentityRole struct {
+	// Scope is the cloud resource ID the role is granted on (e.g., an Azure Key Vault resource ID).
+	Scope string
+	// Role is the provider-specific role name or ID (e.g., "Key Vault Secrets User").
+	Role string
+}
+
 // AppNetworkPolicy defines network policy configuration for the app.
 type AppNetworkPolicy struct {
 	IngressRules []AppNetworkPolicyIngressRule
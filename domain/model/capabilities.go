@@ -0,0 +1,212 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Node pool operation names used by DriverCapabilities.CheckNodePool.
+const (
+	NodePoolOpList   = "list"
+	NodePoolOpCreate = "create"
+	NodePoolOpUpdate = "update"
+	NodePoolOpDelete = "delete"
+)
+
+// DriverCapabilities describes the operations a provider driver supports.
+// Usecases consult it before calling the driver so that unsupported operations fail
+// early with a descriptive error wrapping ErrNotSupported.
+type DriverCapabilities struct {
+	// Driver is the provider driver identifier (e.g., "aks").
+	Driver string `json:"driver"`
+	// Cluster describes the cluster lifecycle operations.
+	Cluster ClusterCapabilities `json:"cluster"`
+	// Volumes maps supported volume types ("disk", "files") to their capabilities.
+	// Types not present are not supported.
+	Volumes map[string]VolumeTypeCapabilities `json:"volumes"`
+	// VolumeInventory reports whether provider-scoped volume resources can be enumerated (admin gc).
+	VolumeInventory bool `json:"volumeInventory"`
+	// NodePool describes the node pool operations.
+	NodePool NodePoolCapabilities `json:"nodePool"`
+	// DNS reports whether ClusterDNSApply writes records. Drivers without DNS treat it as a no-op.
+	DNS bool `json:"dns"`
+	// Identity reports whether the driver manages app workload identities (AppIdentityApply).
+	Identity bool `json:"identity"`
+}
+
+// ClusterCapabilities describes the cluster lifecycle operations of a driver.
+type ClusterCapabilities struct {
+	// Provision reports whether the driver creates and deletes clusters.
+	Provision bool `json:"provision"`
+	// Existing reports whether the driver manages clusters created outside Kompox (existing: true).
+	Existing bool `json:"existing"`
+	// Install reports whether the driver installs in-cluster resources (Ingress Controller, etc.).
+	Install bool `json:"install"`
+	// Plan reports whether the driver can plan lifecycle operations without mutating anything (--plan).
+	Plan bool `json:"plan"`
+	// Upgrade reports whether the driver upgrades Kubernetes versions and node images (cluster upgrade).
+	Upgrade bool `json:"upgrade"`
+}
+
+// VolumeTypeCapabilities describes the operations supported for one volume type.
+type VolumeTypeCapabilities struct {
+	// AccessModes are the PersistentVolume access modes the driver provides (e.g., "ReadWriteMany").
+	AccessModes []string `json:"accessModes"`
+	// Snapshot reports whether snapshots can be created and restored.
+	Snapshot bool `json:"snapshot"`
+	// Update reports whether disk options can be changed in place (VolumeDiskUpdate).
+	Update bool `json:"update"`
+	// Copy reports whether snapshots of another provider can be copied in (standby replication).
+	Copy bool `json:"copy"`
+}
+
+// NodePoolCapabilities describes the node pool operations of a driver.
+type NodePoolCapabilities struct {
+	List   bool `json:"list"`
+	Create bool `json:"create"`
+	Update bool `json:"update"`
+	Delete bool `json:"delete"`
+}
+
+// CapabilityPort is an interface (domain port) for discovering driver capabilities.
+type CapabilityPort interface {
+	// Capabilities returns the capabilities of the driver managing the cluster.
+	Capabilities(ctx context.Context, cluster *Cluster) (*DriverCapabilities, error)
+}
+
+// VolumeTypes returns the supported volume types in sorted order.
+func (c *DriverCapabilities) VolumeTypes() []string {
+	types := make([]string, 0, len(c.Volumes))
+	for t := range c.Volumes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CheckClusterProvision returns an error if the driver cannot provision the cluster as configured.
+func (c *DriverCapabilities) CheckClusterProvision(cluster *Cluster) error {
+	if cluster != nil && cluster.Existing && !c.Cluster.Existing {
+		return fmt.Errorf("driver %s does not support existing clusters: %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckClusterDeprovision returns an error if the driver cannot delete clusters.
+func (c *DriverCapabilities) CheckClusterDeprovision() error {
+	if !c.Cluster.Provision {
+		return fmt.Errorf("driver %s does not deprovision clusters (they are managed outside kompox): %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckClusterInstall returns an error if the driver cannot install in-cluster resources.
+func (c *DriverCapabilities) CheckClusterInstall() error {
+	if !c.Cluster.Install {
+		return fmt.Errorf("driver %s does not install cluster resources: %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckClusterPlan returns an error if the driver cannot plan cluster lifecycle operations.
+func (c *DriverCapabilities) CheckClusterPlan() error {
+	if !c.Cluster.Plan {
+		return fmt.Errorf("driver %s does not plan cluster operations: %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckClusterUpgrade returns an error if the driver cannot upgrade clusters.
+func (c *DriverCapabilities) CheckClusterUpgrade() error {
+	if !c.Cluster.Upgrade {
+		return fmt.Errorf("driver %s does not upgrade clusters: %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckVolume returns an error if the driver does not support the volume type.
+// Files volumes additionally require ReadWriteMany.
+func (c *DriverCapabilities) CheckVolume(vol AppVolume) error {
+	volType := vol.Type
+	if volType == "" {
+		volType = VolumeTypeDisk
+	}
+	vc, ok := c.Volumes[volType]
+	if !ok {
+		return fmt.Errorf("driver %s does not support volume type %q (volume %s): %w", c.Driver, volType, vol.Name, ErrNotSupported)
+	}
+	if volType == VolumeTypeFiles && !slices.Contains(vc.AccessModes, "ReadWriteMany") {
+		return fmt.Errorf("driver %s does not provide ReadWriteMany for volume type %q (volume %s): %w", c.Driver, volType, vol.Name, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckVolumeSnapshot returns an error if snapshots are not supported for the volume.
+func (c *DriverCapabilities) CheckVolumeSnapshot(vol AppVolume) error {
+	if err := c.CheckVolume(vol); err != nil {
+		return err
+	}
+	if !c.volume(vol).Snapshot {
+		return fmt.Errorf("driver %s does not support snapshots of volume %s: %w", c.Driver, vol.Name, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckVolumeUpdate returns an error if disks of the volume cannot be updated in place.
+func (c *DriverCapabilities) CheckVolumeUpdate(vol AppVolume) error {
+	if err := c.CheckVolume(vol); err != nil {
+		return err
+	}
+	if !c.volume(vol).Update {
+		return fmt.Errorf("driver %s does not support updating disks of volume %s: %w", c.Driver, vol.Name, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckVolumeSnapshotCopy returns an error if snapshots of the volume cannot be copied from another provider.
+func (c *DriverCapabilities) CheckVolumeSnapshotCopy(vol AppVolume) error {
+	if err := c.CheckVolumeSnapshot(vol); err != nil {
+		return err
+	}
+	if !c.volume(vol).Copy {
+		return fmt.Errorf("driver %s does not support copying snapshots of volume %s: %w", c.Driver, vol.Name, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckNodePool returns an error if the node pool operation (NodePoolOp*) is not supported.
+func (c *DriverCapabilities) CheckNodePool(op string) error {
+	var ok bool
+	switch op {
+	case NodePoolOpList:
+		ok = c.NodePool.List
+	case NodePoolOpCreate:
+		ok = c.NodePool.Create
+	case NodePoolOpUpdate:
+		ok = c.NodePool.Update
+	case NodePoolOpDelete:
+		ok = c.NodePool.Delete
+	}
+	if !ok {
+		return fmt.Errorf("driver %s does not support node pool %s: %w", c.Driver, op, ErrNotSupported)
+	}
+	return nil
+}
+
+// CheckAppIdentity returns an error if the driver cannot manage app workload identities.
+func (c *DriverCapabilities) CheckAppIdentity() error {
+	if !c.Identity {
+		return fmt.Errorf("driver %s does not support app workload identities: %w", c.Driver, ErrNotSupported)
+	}
+	return nil
+}
+
+// volume returns the capabilities of the volume type, defaulting to disk.
+func (c *DriverCapabilities) volume(vol AppVolume) VolumeTypeCapabilities {
+	if vol.Type == "" {
+		return c.Volumes[VolumeTypeDisk]
+	}
+	return c.Volumes[vol.Type]
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDriverCapabilitiesChecks(t *testing.T) {
+	caps := &DriverCapabilities{
+		Driver:  "test",
+		Cluster: ClusterCapabilities{Existing: true, Install: true},
+		Volumes: map[string]VolumeTypeCapabilities{
+			VolumeTypeDisk:  {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true},
+			VolumeTypeFiles: {AccessModes: []string{"ReadWriteOnce"}},
+		},
+		NodePool: NodePoolCapabilities{List: true},
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"provision existing", caps.CheckClusterProvision(&Cluster{Existing: true}), false},
+		{"deprovision", caps.CheckClusterDeprovision(), true},
+		{"install", caps.CheckClusterInstall(), false},
+		{"upgrade", caps.CheckClusterUpgrade(), true},
+		{"default disk type", caps.CheckVolume(AppVolume{Name: "db"}), false},
+		{"files without RWX", caps.CheckVolume(AppVolume{Name: "share", Type: VolumeTypeFiles}), true},
+		{"unknown type", caps.CheckVolume(AppVolume{Name: "x", Type: "tape"}), true},
+		{"disk snapshot", caps.CheckVolumeSnapshot(AppVolume{Name: "db"}), false},
+		{"disk update", caps.CheckVolumeUpdate(AppVolume{Name: "db"}), true},
+		{"disk snapshot copy", caps.CheckVolumeSnapshotCopy(AppVolume{Name: "db"}), true},
+		{"node pool list", caps.CheckNodePool(NodePoolOpList), false},
+		{"node pool create", caps.CheckNodePool(NodePoolOpCreate), true},
+		{"app identity", caps.CheckAppIdentity(), true},
+	}
+	for _, tt := range tests {
+		if (tt.err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, tt.err, tt.wantErr)
+		}
+		if tt.err != nil && !errors.Is(tt.err, ErrNotSupported) {
+			t.Errorf("%s: error must wrap ErrNotSupported: %v", tt.name, tt.err)
+		}
+	}
+
+	caps.Cluster.Existing = false
+	if err := caps.CheckClusterProvision(&Cluster{Existing: true}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("existing cluster on provisioning-only driver: err = %v", err)
+	}
+	if got := caps.VolumeTypes(); len(got) != 2 || got[0] != VolumeTypeDisk || got[1] != VolumeTypeFiles {
+		t.Errorf("VolumeTypes() = %v", got)
+	}
+}
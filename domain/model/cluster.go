@@ -6,15 +6,19 @@ import (
 
 // Cluster represents a Kubernetes cluster resource.
 type Cluster struct {
-	ID         string
-	Name       string
-	ProviderID string // references Provider
-	Existing   bool
-	Ingress    *ClusterIngress
-	Protection *ClusterProtection
-	Settings   map[string]string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          string
+	Name        string
+	ProviderID  string // references Provider
+	Existing    bool
+	Ingress     *ClusterIngress
+	Protection  *ClusterProtection
+	DNS         *DNSProvider        // standalone DNS provider; overrides Workspace.DNS
+	NodePools   []NodePool          // desired node pools; reconciled by nodepool sync
+	SpotHandler *ClusterSpotHandler // Spot eviction helper installed by ClusterInstall; nil disables it
+	Registries  []ClusterRegistry   // registry credentials distributed to app namespaces at deploy
+	Settings    map[string]string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 // ClusterProtection defines resource protection policies for cluster operations.
@@ -63,6 +67,31 @@ type ClusterIngress struct {
 	Certificates []ClusterIngressCertificate
 }
 
+// ClusterSpotHandler configures the in-cluster helper that moves stateful apps off Spot
+// nodes being evicted.
+type ClusterSpotHandler struct {
+	// Image is the container image providing kompoxops. Empty selects the default image.
+	Image string
+	// FallbackPool is the regular node pool that apps on evicted nodes are moved to.
+	FallbackPool string
+	// SpotPools are the node pools watched for eviction. Empty selects the declared
+	// NodePools with spot priority.
+	SpotPools []string
+}
+
+// ClusterRegistry is a private container registry credential shared by the apps of the
+// cluster. App deploy adds it to the image pull secret of every app whose compose images
+// are hosted on Server, so rotating the password only requires re-deploying the apps.
+type ClusterRegistry struct {
+	// Server is the registry host (e.g., "ghcr.io", "registry.example.com:5000"). It is
+	// matched against the registry of compose images; "docker.io" matches Docker Hub images.
+	Server   string
+	Username string
+	// PasswordSource locates the password or access token: "file:<path>" or a
+	// provider-specific locator read by SecretPort (for AKS, a Key Vault secret URL).
+	PasswordSource string
+}
+
 // ClusterIngressCertificate represents a static certificate reference.
 // Name is an arbitrary identifier; it determines the Kubernetes TLS Secret name as "tls-" + Name.
 // Source is a provider-specific locator. For AKS, a Key Vault secret URL is supported.
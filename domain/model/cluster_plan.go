@@ -0,0 +1,89 @@
+package model
+
+import "context"
+
+// ClusterPlanOperation identifies the cluster lifecycle operation being planned.
+type ClusterPlanOperation string
+
+const (
+	ClusterPlanProvision   ClusterPlanOperation = "provision"
+	ClusterPlanDeprovision ClusterPlanOperation = "deprovision"
+	ClusterPlanInstall     ClusterPlanOperation = "install"
+	ClusterPlanUninstall   ClusterPlanOperation = "uninstall"
+)
+
+// ClusterChangeScope tells where a planned change is applied.
+type ClusterChangeScope string
+
+const (
+	ClusterChangeScopeCloud   ClusterChangeScope = "cloud"   // provider resources (resource groups, managed clusters, role assignments, ...)
+	ClusterChangeScopeCluster ClusterChangeScope = "cluster" // Kubernetes resources in the cluster (namespaces, Helm releases, ...)
+)
+
+// ClusterChangeAction is the action a driver would take on a resource.
+type ClusterChangeAction string
+
+const (
+	ClusterChangeCreate ClusterChangeAction = "create"
+	ClusterChangeUpdate ClusterChangeAction = "update"
+	ClusterChangeDelete ClusterChangeAction = "delete"
+	ClusterChangeNoOp   ClusterChangeAction = "no-op"
+)
+
+// ClusterChangeField is a field that differs between the current and the desired state.
+// Before is nil for fields being added and After is nil for fields being removed.
+type ClusterChangeField struct {
+	Path   string `json:"path"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// ClusterChange is one resource change reported by a cluster plan.
+type ClusterChange struct {
+	Scope    ClusterChangeScope   `json:"scope"`
+	Kind     string               `json:"kind"`     // driver-defined resource kind (e.g., "ManagedCluster", "Namespace")
+	Resource string               `json:"resource"` // resource identifier (ARM ID, namespace/name, ...)
+	Action   ClusterChangeAction  `json:"action"`
+	Fields   []ClusterChangeField `json:"fields,omitempty"`
+}
+
+// ClusterPlan lists the changes a cluster lifecycle operation would make. Drivers compute it
+// from the current state without mutating anything. Changes are in the order the operation
+// would apply them and include no-op entries for resources already in the desired state.
+type ClusterPlan struct {
+	Operation ClusterPlanOperation `json:"operation"`
+	Cluster   string               `json:"cluster"`
+	Driver    string               `json:"driver"`
+	Changes   []ClusterChange      `json:"changes"`
+}
+
+// HasChanges reports whether the plan contains any change other than no-op.
+func (p *ClusterPlan) HasChanges() bool {
+	if p == nil {
+		return false
+	}
+	for _, c := range p.Changes {
+		if c.Action != ClusterChangeNoOp {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterPlanOptions are the options of ClusterPlan. Force mirrors the force option of the
+// planned operation so that the plan reflects re-applied resources.
+type ClusterPlanOptions struct{ Force bool }
+
+type ClusterPlanOption func(*ClusterPlanOptions)
+
+// WithClusterPlanForce plans the operation as if it were run with force.
+func WithClusterPlanForce() ClusterPlanOption {
+	return func(o *ClusterPlanOptions) { o.Force = true }
+}
+
+// ClusterPlanPort is an interface (domain port) for planning cluster lifecycle operations.
+type ClusterPlanPort interface {
+	// Plan returns the changes the operation would make without mutating anything.
+	// Drivers without planning support return an error wrapping ErrNotSupported.
+	Plan(ctx context.Context, cluster *Cluster, op ClusterPlanOperation, opts ...ClusterPlanOption) (*ClusterPlan, error)
+}
@@ -50,6 +50,9 @@ func WithClusterDNSApplyDryRun() ClusterDNSApplyOption {
 
 // ClusterPort is an interface (domain port) for cluster operations.
 type ClusterPort interface {
+	CapabilityPort
+	ClusterPlanPort
+	ClusterUpgradePort
 	Status(ctx context.Context, cluster *Cluster) (*ClusterStatus, error)
 	Provision(ctx context.Context, cluster *Cluster, opts ...ClusterProvisionOption) error
 	Deprovision(ctx context.Context, cluster *Cluster, opts ...ClusterDeprovisionOption) error
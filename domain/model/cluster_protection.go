@@ -61,3 +61,19 @@ func (c *Cluster) CheckInstallationProtection(opType ClusterOperationType) error
 
 	return nil
 }
+
+// CheckProtection validates if an operation on the node pool is allowed by its own
+// protection level, given by the NodePoolProtectionLabel label of the pool. It applies in
+// addition to the cluster provisioning protection.
+func (p *NodePool) CheckProtection(opType ClusterOperationType) error {
+	if p == nil || p.Labels == nil {
+		return nil
+	}
+	level := ClusterProtectionLevel((*p.Labels)[NodePoolProtectionLabel])
+	switch {
+	case level == ProtectionReadOnly && (opType == OpUpdate || opType == OpDelete),
+		level == ProtectionCannotDelete && opType == OpDelete:
+		return fmt.Errorf("%w: node pool %s is %q (label %s), cannot perform %s operation", ErrClusterProtected, p.LogicalName(), level, NodePoolProtectionLabel, opType)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package model
+
+import "context"
+
+// ClusterVersions describes the Kubernetes versions of a cluster and the upgrades available.
+type ClusterVersions struct {
+	// KubernetesVersion is the current control plane version.
+	KubernetesVersion string `json:"kubernetesVersion"`
+	// Upgrades are the control plane versions the cluster can be upgraded to, in ascending order.
+	Upgrades []string `json:"upgrades,omitempty"`
+	// NodePools are the versions of each node pool.
+	NodePools []NodePoolVersions `json:"nodePools"`
+}
+
+// NodePoolVersions describes the Kubernetes and node image versions of a node pool.
+type NodePoolVersions struct {
+	Name              string `json:"name"`
+	Mode              string `json:"mode,omitempty"` // "system" or "user"
+	KubernetesVersion string `json:"kubernetesVersion"`
+	// NodeImageVersion is the current node image; empty when the driver does not manage node images.
+	NodeImageVersion string `json:"nodeImageVersion,omitempty"`
+	// LatestNodeImageVersion is the node image an image upgrade would install.
+	LatestNodeImageVersion string `json:"latestNodeImageVersion,omitempty"`
+	// NodeSelector are the Kubernetes node labels selecting the nodes of the pool. Empty means
+	// the nodes labeled kompox.dev/node-pool=Name.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// NodeImageUpgradable reports whether a newer node image is available for the pool.
+func (v NodePoolVersions) NodeImageUpgradable() bool {
+	return v.LatestNodeImageVersion != "" && v.LatestNodeImageVersion != v.NodeImageVersion
+}
+
+// NodePoolUpgradeOptions are the options of UpgradeNodePool. At least one of
+// KubernetesVersion and NodeImage must be set.
+type NodePoolUpgradeOptions struct {
+	// KubernetesVersion upgrades the pool to the version; it must not exceed the control plane version.
+	KubernetesVersion string
+	// NodeImage upgrades the pool to the latest node image.
+	NodeImage bool
+}
+
+type NodePoolUpgradeOption func(*NodePoolUpgradeOptions)
+
+// WithNodePoolUpgradeKubernetesVersion upgrades the node pool to the Kubernetes version.
+func WithNodePoolUpgradeKubernetesVersion(version string) NodePoolUpgradeOption {
+	return func(o *NodePoolUpgradeOptions) { o.KubernetesVersion = version }
+}
+
+// WithNodePoolUpgradeNodeImage upgrades the node pool to the latest node image.
+func WithNodePoolUpgradeNodeImage() NodePoolUpgradeOption {
+	return func(o *NodePoolUpgradeOptions) { o.NodeImage = true }
+}
+
+// ApplyNodePoolUpgradeOptions applies functional options to NodePoolUpgradeOptions.
+func ApplyNodePoolUpgradeOptions(opts ...NodePoolUpgradeOption) NodePoolUpgradeOptions {
+	var o NodePoolUpgradeOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	return o
+}
+
+// ClusterUpgradePort is an interface (domain port) for upgrading cluster versions.
+// Drivers without upgrade support return an error wrapping ErrNotSupported.
+type ClusterUpgradePort interface {
+	// Versions returns the current versions and the available upgrades.
+	Versions(ctx context.Context, cluster *Cluster) (*ClusterVersions, error)
+	// UpgradeControlPlane upgrades only the control plane to version, leaving node pools as they are.
+	UpgradeControlPlane(ctx context.Context, cluster *Cluster, version string) error
+	// UpgradeNodePool upgrades one node pool. The driver replaces or reimages its nodes,
+	// cordoning and draining them one by one.
+	UpgradeNodePool(ctx context.Context, cluster *Cluster, poolName string, opts ...NodePoolUpgradeOption) error
+}
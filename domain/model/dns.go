@@ -1,5 +1,10 @@
 package model
 
+import (
+	"context"
+	"strings"
+)
+
 // DNSRecordType represents provider-agnostic DNS record types.
 type DNSRecordType string
 
@@ -21,3 +26,39 @@ type DNSRecordSet struct {
 	TTL   uint32   // TTL in seconds. Use provider default when zero.
 	RData []string // Presentation-format RDATA. Empty slice indicates deletion.
 }
+
+// DNSProvider configures a standalone DNS provider that writes record sets independently of
+// the cluster provider driver. It is set on a Workspace or a Cluster; the cluster setting takes
+// precedence. FQDNs outside Zones are written by the cluster provider driver.
+type DNSProvider struct {
+	Driver   string            // DNS driver identifier (e.g., "rfc2136", "webhook")
+	Zones    []string          // zones served by the provider; empty routes every FQDN to it
+	Settings map[string]string // driver-specific settings
+}
+
+// Zone returns the longest configured zone that contains fqdn. When no zones are configured
+// it returns "" and true. It returns false when fqdn is outside every configured zone.
+func (p *DNSProvider) Zone(fqdn string) (string, bool) {
+	if len(p.Zones) == 0 {
+		return "", true
+	}
+	name := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	best := ""
+	for _, z := range p.Zones {
+		zone := strings.ToLower(strings.TrimSuffix(z, "."))
+		if zone == "" || len(zone) <= len(best) {
+			continue
+		}
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			best = zone
+		}
+	}
+	return best, best != ""
+}
+
+// DNSPort is an interface (domain port) for writing record sets through a standalone DNS provider.
+type DNSPort interface {
+	// DNSApply applies rset in zone using the DNS provider dns. It follows the best-effort
+	// contract of ClusterDNSApply: write failures are errors only with the Strict option.
+	DNSApply(ctx context.Context, dns *DNSProvider, zone string, rset DNSRecordSet, opts ...ClusterDNSApplyOption) error
+}
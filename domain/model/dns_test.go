@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestDNSProvider_Zone(t *testing.T) {
+	p := &DNSProvider{Zones: []string{"example.com", "Internal.Example.com.", "example.org"}}
+	tests := []struct {
+		fqdn     string
+		wantZone string
+		wantOK   bool
+	}{
+		{"www.example.com", "example.com", true},
+		{"example.com.", "example.com", true},
+		{"db.internal.example.com", "internal.example.com", true},
+		{"WWW.EXAMPLE.ORG.", "example.org", true},
+		{"badexample.com", "", false},
+		{"www.example.net", "", false},
+	}
+	for _, tt := range tests {
+		zone, ok := p.Zone(tt.fqdn)
+		if zone != tt.wantZone || ok != tt.wantOK {
+			t.Errorf("Zone(%q) = %q, %v; want %q, %v", tt.fqdn, zone, ok, tt.wantZone, tt.wantOK)
+		}
+	}
+
+	if zone, ok := (&DNSProvider{}).Zone("www.example.com"); zone != "" || !ok {
+		t.Errorf("provider without zones must accept every FQDN, got %q, %v", zone, ok)
+	}
+}
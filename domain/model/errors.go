@@ -20,4 +20,8 @@ var (
 	// ErrNotSupported indicates that the operation is not supported by the provider/driver.
 	// Used for operations like snapshots on volume types that do not support them.
 	ErrNotSupported = errors.New("operation not supported")
+
+	// ErrVolumeOptionsInvalid indicates that volume options (app.volumes.options or per-operation
+	// overrides) are rejected by the provider driver, e.g. unknown SKU or incompatible settings.
+	ErrVolumeOptionsInvalid = errors.New("volume options invalid")
 )
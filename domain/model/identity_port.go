@@ -0,0 +1,38 @@
+package model
+
+import "context"
+
+// WorkloadIdentity is the cloud identity of an app as bound to a Kubernetes ServiceAccount.
+// ServiceAccountAnnotations and PodLabels are provider-specific metadata the converter adds
+// to the app ServiceAccount and pod template, keeping the kube layer free from provider
+// assumptions (e.g., azure.workload.identity/client-id on AKS).
+type WorkloadIdentity struct {
+	// ClientID is the client (application) ID used by the workload to request tokens.
+	ClientID string `json:"clientId"`
+	// TenantID is the directory (tenant) of the identity, if the provider has one.
+	TenantID string `json:"tenantId,omitempty"`
+	// PrincipalID is the object ID the roles are assigned to.
+	PrincipalID string `json:"principalId,omitempty"`
+	// ResourceID is the provider-specific identifier of the identity resource.
+	ResourceID string `json:"resourceId,omitempty"`
+
+	ServiceAccountAnnotations map[string]string `json:"serviceAccountAnnotations,omitempty"`
+	PodLabels                 map[string]string `json:"podLabels,omitempty"`
+}
+
+// WorkloadIdentitySubject identifies the ServiceAccount federated with the app identity.
+type WorkloadIdentitySubject struct {
+	Namespace      string
+	ServiceAccount string
+}
+
+// AppIdentityPort defines app workload identity operations.
+// Implementations are provided by provider drivers.
+type AppIdentityPort interface {
+	CapabilityPort
+
+	// AppIdentityApply creates or updates the cloud identity of the app, federates it with the
+	// subject and grants the roles of app.Identity. It is idempotent; roles removed from the
+	// spec are left in place.
+	AppIdentityApply(ctx context.Context, cluster *Cluster, app *App, subject WorkloadIdentitySubject) (*WorkloadIdentity, error)
+}
@@ -43,6 +43,32 @@ type NodePool struct {
 	Extensions map[string]any
 }
 
+// NodePoolLabel is the Kubernetes node label carrying the logical node pool name
+// ("system", "user", ...). Workloads select pools by this label.
+const NodePoolLabel = "kompox.dev/node-pool"
+
+// NodePoolProtectionLabel is the node pool label holding the protection level of the pool
+// ("cannotDelete" or "readOnly"; see NodePool.CheckProtection).
+const NodePoolProtectionLabel = "kompox.dev/protection"
+
+// LogicalName returns the logical pool name workloads select by NodePoolLabel.
+// Drivers whose provider names differ from the logical name (e.g., AKS agent pools
+// "npuser1") carry it in Labels; otherwise Name is returned.
+func (p *NodePool) LogicalName() string {
+	if p == nil {
+		return ""
+	}
+	if p.Labels != nil {
+		if v := (*p.Labels)[NodePoolLabel]; v != "" {
+			return v
+		}
+	}
+	if p.Name != nil {
+		return *p.Name
+	}
+	return ""
+}
+
 // NodePoolAutoscaling defines autoscaling parameters for a node pool.
 type NodePoolAutoscaling struct {
 	// Enabled indicates whether autoscaling is active.
@@ -154,6 +180,8 @@ func ApplyNodePoolDeleteOptions(opts ...NodePoolDeleteOption) NodePoolDeleteOpti
 // NodePoolPort defines node pool management operations.
 // Implementations are provided by provider drivers.
 type NodePoolPort interface {
+	CapabilityPort
+
 	// NodePoolList returns a list of node pools for the specified cluster.
 	NodePoolList(ctx context.Context, cluster *Cluster, opts ...NodePoolListOption) ([]*NodePool, error)
 
@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+// SecretPort reads secret values referenced from KOM definitions, such as the passwords of
+// cluster registries (ClusterRegistry.PasswordSource).
+type SecretPort interface {
+	// SecretRead returns the current value of the secret at source. "file:<path>" reads a
+	// local file; other locators are resolved by the driver of the cluster provider.
+	// Unknown locators fail with ErrNotSupported.
+	SecretRead(ctx context.Context, cluster *Cluster, source string) ([]byte, error)
+}
@@ -6,6 +6,7 @@ import "time"
 type Workspace struct {
 	ID        string
 	Name      string
+	DNS       *DNSProvider // standalone DNS provider for clusters of the workspace
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -19,23 +19,46 @@ type VolumeDiskListOptions struct{ Force bool }
 type VolumeDiskCreateOptions struct {
 	Force   bool
 	Zone    string         // Override zone from app.deployment.zone config
+	Size    int64          // Minimum size in bytes; drivers use max(app.volumes.size, Size)
 	Options map[string]any // Override/merge with app.volumes.options config
+	// Labels and Description are user metadata persisted by the driver (e.g., as resource tags).
+	Labels      map[string]string
+	Description string
 }
 type VolumeDiskDeleteOptions struct{ Force bool }
 type VolumeDiskAssignOptions struct{ Force bool }
+type VolumeDiskUpdateOptions struct {
+	Force   bool
+	Options map[string]any // Provider-specific options to change on an existing disk
+}
 
 type VolumeSnapshotListOptions struct{ Force bool }
-type VolumeSnapshotCreateOptions struct{ Force bool }
+type VolumeSnapshotCreateOptions struct {
+	Force bool
+	// Labels and Description are user metadata persisted by the driver (e.g., as resource tags).
+	Labels      map[string]string
+	Description string
+	// Incremental requests an incremental snapshot from drivers that distinguish them.
+	// Some providers can copy only incremental snapshots across regions (VolumePort.SnapshotCopy).
+	Incremental bool
+}
 type VolumeSnapshotDeleteOptions struct{ Force bool }
+type VolumeSnapshotCopyOptions struct {
+	// Labels and Description are user metadata persisted by the driver on the copy.
+	Labels      map[string]string
+	Description string
+}
 
 type VolumeDiskListOption func(*VolumeDiskListOptions)
 type VolumeDiskCreateOption func(*VolumeDiskCreateOptions)
 type VolumeDiskDeleteOption func(*VolumeDiskDeleteOptions)
 type VolumeDiskAssignOption func(*VolumeDiskAssignOptions)
+type VolumeDiskUpdateOption func(*VolumeDiskUpdateOptions)
 
 type VolumeSnapshotListOption func(*VolumeSnapshotListOptions)
 type VolumeSnapshotCreateOption func(*VolumeSnapshotCreateOptions)
 type VolumeSnapshotDeleteOption func(*VolumeSnapshotDeleteOptions)
+type VolumeSnapshotCopyOption func(*VolumeSnapshotCopyOptions)
 
 // Option helpers mirroring cluster options style.
 func WithVolumeDiskListForce() VolumeDiskListOption {
@@ -47,15 +70,30 @@ func WithVolumeDiskCreateForce() VolumeDiskCreateOption {
 func WithVolumeDiskCreateZone(zone string) VolumeDiskCreateOption {
 	return func(o *VolumeDiskCreateOptions) { o.Zone = zone }
 }
+func WithVolumeDiskCreateSize(size int64) VolumeDiskCreateOption {
+	return func(o *VolumeDiskCreateOptions) { o.Size = size }
+}
 func WithVolumeDiskCreateOptions(options map[string]any) VolumeDiskCreateOption {
 	return func(o *VolumeDiskCreateOptions) { o.Options = options }
 }
+func WithVolumeDiskCreateLabels(labels map[string]string) VolumeDiskCreateOption {
+	return func(o *VolumeDiskCreateOptions) { o.Labels = labels }
+}
+func WithVolumeDiskCreateDescription(desc string) VolumeDiskCreateOption {
+	return func(o *VolumeDiskCreateOptions) { o.Description = desc }
+}
 func WithVolumeDiskDeleteForce() VolumeDiskDeleteOption {
 	return func(o *VolumeDiskDeleteOptions) { o.Force = true }
 }
 func WithVolumeDiskAssignForce() VolumeDiskAssignOption {
 	return func(o *VolumeDiskAssignOptions) { o.Force = true }
 }
+func WithVolumeDiskUpdateForce() VolumeDiskUpdateOption {
+	return func(o *VolumeDiskUpdateOptions) { o.Force = true }
+}
+func WithVolumeDiskUpdateOptions(options map[string]any) VolumeDiskUpdateOption {
+	return func(o *VolumeDiskUpdateOptions) { o.Options = options }
+}
 
 func WithVolumeSnapshotListForce() VolumeSnapshotListOption {
 	return func(o *VolumeSnapshotListOptions) { o.Force = true }
@@ -63,21 +101,42 @@ func WithVolumeSnapshotListForce() VolumeSnapshotListOption {
 func WithVolumeSnapshotCreateForce() VolumeSnapshotCreateOption {
 	return func(o *VolumeSnapshotCreateOptions) { o.Force = true }
 }
+func WithVolumeSnapshotCreateLabels(labels map[string]string) VolumeSnapshotCreateOption {
+	return func(o *VolumeSnapshotCreateOptions) { o.Labels = labels }
+}
+func WithVolumeSnapshotCreateDescription(desc string) VolumeSnapshotCreateOption {
+	return func(o *VolumeSnapshotCreateOptions) { o.Description = desc }
+}
+func WithVolumeSnapshotCreateIncremental() VolumeSnapshotCreateOption {
+	return func(o *VolumeSnapshotCreateOptions) { o.Incremental = true }
+}
 func WithVolumeSnapshotDeleteForce() VolumeSnapshotDeleteOption {
 	return func(o *VolumeSnapshotDeleteOptions) { o.Force = true }
 }
+func WithVolumeSnapshotCopyLabels(labels map[string]string) VolumeSnapshotCopyOption {
+	return func(o *VolumeSnapshotCopyOptions) { o.Labels = labels }
+}
+func WithVolumeSnapshotCopyDescription(desc string) VolumeSnapshotCopyOption {
+	return func(o *VolumeSnapshotCopyOptions) { o.Description = desc }
+}
 
 // VolumePort abstracts volume disk and snapshot operations provided by drivers.
 type VolumePort interface {
+	CapabilityPort
 	DiskList(ctx context.Context, cluster *Cluster, app *App, volName string, opts ...VolumeDiskListOption) ([]*VolumeDisk, error)
 	// DiskCreate provisions a new disk. diskName and source are forwarded to the driver as-is.
 	DiskCreate(ctx context.Context, cluster *Cluster, app *App, volName string, diskName string, source string, opts ...VolumeDiskCreateOption) (*VolumeDisk, error)
 	DiskDelete(ctx context.Context, cluster *Cluster, app *App, volName string, diskName string, opts ...VolumeDiskDeleteOption) error
 	DiskAssign(ctx context.Context, cluster *Cluster, app *App, volName string, diskName string, opts ...VolumeDiskAssignOption) error
+	// DiskUpdate changes provider-specific options of an existing disk in place (e.g., IOPS/throughput).
+	DiskUpdate(ctx context.Context, cluster *Cluster, app *App, volName string, diskName string, opts ...VolumeDiskUpdateOption) (*VolumeDisk, error)
 	SnapshotList(ctx context.Context, cluster *Cluster, app *App, volName string, opts ...VolumeSnapshotListOption) ([]*VolumeSnapshot, error)
 	// SnapshotCreate provisions a new snapshot and forwards opaque parameters to the driver.
 	SnapshotCreate(ctx context.Context, cluster *Cluster, app *App, volName string, snapName string, source string, opts ...VolumeSnapshotCreateOption) (*VolumeSnapshot, error)
 	SnapshotDelete(ctx context.Context, cluster *Cluster, app *App, volName string, snapName string, opts ...VolumeSnapshotDeleteOption) error
+	// SnapshotCopy copies a snapshot taken by another provider (typically in another region)
+	// into the logical volume of the app on cluster. It returns after the copy is complete.
+	SnapshotCopy(ctx context.Context, cluster *Cluster, app *App, volName string, snapName string, source *VolumeSnapshot, opts ...VolumeSnapshotCopyOption) (*VolumeSnapshot, error)
 }
 
 // VolumeDisk represents a specific disk of a logical volume.
@@ -88,27 +147,37 @@ type VolumePort interface {
 //   - Size: share quota in bytes; 0 if not set
 //   - Zone: empty for regional services; availability/replication via Options
 //   - Options: provider-specific attributes (protocol, skuName, availability, quotaGiB, etc.)
+//
+// Labels and Description are user metadata. SourceHandle records lineage: the Handle of the
+// disk or snapshot this disk was created from (empty for disks created empty).
 type VolumeDisk struct {
-	Name       string         `json:"name"`       // name of the volume disk
-	VolumeName string         `json:"volumeName"` // name of the logical volume this disk belongs to
-	Assigned   bool           `json:"assigned"`   // whether this disk is assigned to the logical volume
-	Size       int64          `json:"size"`       // volume disk size in bytes
-	Zone       string         `json:"zone"`       // availability zone where the disk is located (empty for regional)
-	Options    map[string]any `json:"options"`    // provider-specific configuration options
-	Handle     string         `json:"handle"`     // provider driver specific handle
-	CreatedAt  time.Time      `json:"createdAt"`
-	UpdatedAt  time.Time      `json:"updatedAt"`
+	Name         string            `json:"name"`                   // name of the volume disk
+	VolumeName   string            `json:"volumeName"`             // name of the logical volume this disk belongs to
+	Assigned     bool              `json:"assigned"`               // whether this disk is assigned to the logical volume
+	Size         int64             `json:"size"`                   // volume disk size in bytes
+	Zone         string            `json:"zone"`                   // availability zone where the disk is located (empty for regional)
+	Options      map[string]any    `json:"options"`                // provider-specific configuration options
+	Handle       string            `json:"handle"`                 // provider driver specific handle
+	Labels       map[string]string `json:"labels,omitempty"`       // user labels
+	Description  string            `json:"description,omitempty"`  // user description
+	SourceHandle string            `json:"sourceHandle,omitempty"` // handle of the disk/snapshot this disk was created from
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
 }
 
 // VolumeSnapshot represents a snapshot artifact belonging to a logical volume.
 // Handle should carry cloud-native identifier (e.g., full resource ID/URL).
+// SourceHandle records lineage: the Handle of the disk the snapshot was taken from.
 type VolumeSnapshot struct {
-	Name       string    `json:"name"`       // driver-chosen unique name within the logical volume
-	VolumeName string    `json:"volumeName"` // logical volume name
-	Size       int64     `json:"size"`       // bytes; optional for providers that do not expose
-	Handle     string    `json:"handle"`     // provider snapshot handle/ID
-	CreatedAt  time.Time `json:"createdAt"`  // snapshot creation time (provider-reported if available)
-	UpdatedAt  time.Time `json:"updatedAt"`  // last update time (if applicable)
+	Name         string            `json:"name"`                   // driver-chosen unique name within the logical volume
+	VolumeName   string            `json:"volumeName"`             // logical volume name
+	Size         int64             `json:"size"`                   // bytes; optional for providers that do not expose
+	Handle       string            `json:"handle"`                 // provider snapshot handle/ID
+	Labels       map[string]string `json:"labels,omitempty"`       // user labels
+	Description  string            `json:"description,omitempty"`  // user description
+	SourceHandle string            `json:"sourceHandle,omitempty"` // handle of the disk this snapshot was taken from
+	CreatedAt    time.Time         `json:"createdAt"`              // snapshot creation time (provider-reported if available)
+	UpdatedAt    time.Time         `json:"updatedAt"`              // last update time (if applicable)
 }
 
 // VolumeClass defines provider-specific parameters for persistent volumes.
@@ -121,4 +190,47 @@ type VolumeClass struct {
 	AccessModes      []string          // e.g. ["ReadWriteOnce"]
 	ReclaimPolicy    string            // "Retain" | "Delete"
 	VolumeMode       string            // "Filesystem" | "Block"
+	// NodeLocal selects a node-local PersistentVolume (spec.local) instead of CSI.
+	// The disk Handle must be local://<node>/<path>; the PV is pinned to <node> by node affinity.
+	NodeLocal bool
+}
+
+// MatchLabels reports whether labels contain every key/value pair of selector.
+// An empty selector matches everything.
+func MatchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// VolumeResource is a provider resource holding app volume data, discovered at provider scope
+// by the tags the driver attached when the resource was created. It is used to find resources
+// left behind by apps that no longer exist (garbage collection).
+type VolumeResource struct {
+	Kind       string     `json:"kind"`                 // driver-defined kind (e.g., "disk", "snapshot", "storageAccount", "resourceGroup")
+	Name       string     `json:"name"`                 // provider resource name
+	Handle     string     `json:"handle"`               // provider resource ID
+	AppName    string     `json:"appName"`              // app name recorded in tags
+	AppIDHash  string     `json:"appIdHash"`            // app ID hash recorded in tags (naming.Hashes.AppID)
+	VolumeName string     `json:"volumeName,omitempty"` // logical volume name (empty for container resources)
+	Container  bool       `json:"container,omitempty"`  // true if the resource contains others (deleted after its contents)
+	Size       int64      `json:"size,omitempty"`       // bytes; 0 if unknown
+	CreatedAt  time.Time  `json:"createdAt"`            // provider-reported creation time (zero if unknown)
+	OrphanedAt *time.Time `json:"orphanedAt,omitempty"` // time the resource was first marked orphaned (nil if unmarked)
+}
+
+// VolumeInventoryPort abstracts provider-scoped discovery and cleanup of app volume resources.
+// Drivers that cannot enumerate their resources return ErrNotSupported.
+type VolumeInventoryPort interface {
+	// ResourceList lists all Kompox-managed app volume resources of the provider.
+	ResourceList(ctx context.Context, provider *Provider) ([]*VolumeResource, error)
+	// ResourceMarkOrphaned records the time the resource was first found orphaned.
+	ResourceMarkOrphaned(ctx context.Context, provider *Provider, res *VolumeResource, at time.Time) error
+	// ResourceUnmarkOrphaned removes the orphaned mark from a resource owned by an app again.
+	ResourceUnmarkOrphaned(ctx context.Context, provider *Provider, res *VolumeResource) error
+	// ResourceDelete deletes the resource. NotFound is treated as success.
+	ResourceDelete(ctx context.Context, provider *Provider, res *VolumeResource) error
 }
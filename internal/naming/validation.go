@@ -37,3 +37,57 @@ func ValidateDiskName(name string) error {
 func ValidateSnapshotName(name string) error {
 	return validateDNS1123Label(name, snapshotNameMaxLength, "snapshot")
 }
+
+const (
+	volumeLabelKeyMaxLength    = 32
+	volumeLabelValueMaxLength  = 128
+	volumeLabelMaxCount        = 16
+	volumeDescriptionMaxLength = 256
+)
+
+// ValidateVolumeLabels validates user labels attached to volume disks and snapshots.
+// Keys are restricted to lowercase letters, digits and underscore (starting with a letter)
+// so that they can be stored both as cloud resource tags and as Azure Files metadata names.
+// Values must be printable ASCII.
+func ValidateVolumeLabels(labels map[string]string) error {
+	if len(labels) > volumeLabelMaxCount {
+		return fmt.Errorf("too many labels: %d exceeds %d", len(labels), volumeLabelMaxCount)
+	}
+	for k, v := range labels {
+		if k == "" || len(k) > volumeLabelKeyMaxLength {
+			return fmt.Errorf("invalid label key %q: length must be 1..%d", k, volumeLabelKeyMaxLength)
+		}
+		for i, c := range k {
+			if !(c >= 'a' && c <= 'z' || i > 0 && (c >= '0' && c <= '9' || c == '_')) {
+				return fmt.Errorf("invalid label key %q: must match [a-z][a-z0-9_]*", k)
+			}
+		}
+		if len(v) > volumeLabelValueMaxLength {
+			return fmt.Errorf("invalid label value for %q: exceeds %d characters", k, volumeLabelValueMaxLength)
+		}
+		if !isPrintableASCII(v) {
+			return fmt.Errorf("invalid label value for %q: must be printable ASCII", k)
+		}
+	}
+	return nil
+}
+
+// ValidateVolumeDescription validates a user description attached to volume disks and snapshots.
+func ValidateVolumeDescription(desc string) error {
+	if len(desc) > volumeDescriptionMaxLength {
+		return fmt.Errorf("description exceeds %d characters", volumeDescriptionMaxLength)
+	}
+	if !isPrintableASCII(desc) {
+		return fmt.Errorf("description must be printable ASCII")
+	}
+	return nil
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
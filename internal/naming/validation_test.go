@@ -80,3 +80,43 @@ func TestValidateSnapshotName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateVolumeLabels(t *testing.T) {
+	cases := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "nil", labels: nil, wantErr: false},
+		{name: "valid", labels: map[string]string{"env": "prod", "release_2": "v1.2.3"}, wantErr: false},
+		{name: "empty value", labels: map[string]string{"env": ""}, wantErr: false},
+		{name: "empty key", labels: map[string]string{"": "x"}, wantErr: true},
+		{name: "uppercase key", labels: map[string]string{"Env": "x"}, wantErr: true},
+		{name: "hyphen key", labels: map[string]string{"my-env": "x"}, wantErr: true},
+		{name: "slash key", labels: map[string]string{"app.kubernetes.io/name": "x"}, wantErr: true},
+		{name: "leading digit", labels: map[string]string{"1env": "x"}, wantErr: true},
+		{name: "too long key", labels: map[string]string{strings.Repeat("a", volumeLabelKeyMaxLength+1): "x"}, wantErr: true},
+		{name: "too long value", labels: map[string]string{"env": strings.Repeat("a", volumeLabelValueMaxLength+1)}, wantErr: true},
+		{name: "non ascii value", labels: map[string]string{"env": "本番"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateVolumeLabels(tc.labels)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ValidateVolumeLabels(%v) error = %v, wantErr %v", tc.labels, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVolumeDescription(t *testing.T) {
+	if err := ValidateVolumeDescription("restored from nightly"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateVolumeDescription(strings.Repeat("a", volumeDescriptionMaxLength+1)); err == nil {
+		t.Fatalf("expected error for too long description")
+	}
+	if err := ValidateVolumeDescription("line1\nline2"); err == nil {
+		t.Fatalf("expected error for control characters")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
 	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
 	"github.com/kompox/kompox/internal/logging"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -20,6 +21,8 @@ import (
 type DeployInput struct {
 	// AppID identifies the target app to deploy.
 	AppID string
+	// AutoZone pins node scheduling to the zone of the assigned zonal disks.
+	AutoZone bool
 }
 
 // DeployOutput is the outcome of a deployment.
@@ -35,16 +38,21 @@ func (u *UseCase) Deploy(ctx context.Context, in *DeployInput) (*DeployOutput, e
 	if in == nil || in.AppID == "" {
 		return nil, fmt.Errorf("DeployInput.AppID is required")
 	}
-	logger := logging.FromContext(ctx)
-	msgSym := "UC:app.deploy"
 
 	// Resolve the target app for cluster/provider lookup.
 	appObj, err := u.Repos.App.Get(ctx, in.AppID)
 	if err != nil || appObj == nil {
 		return nil, fmt.Errorf("failed to get app %s: %w", in.AppID, err)
 	}
+	return u.deployApp(ctx, appObj, in.AutoZone)
+}
+
+// deployApp validates, converts and applies the app to the cluster referenced by appObj.ClusterID.
+func (u *UseCase) deployApp(ctx context.Context, appObj *model.App, autoZone bool) (*DeployOutput, error) {
+	logger := logging.FromContext(ctx)
+	msgSym := "UC:app.deploy"
 
-	res, err := u.validateApp(ctx, appObj)
+	res, err := u.validateApp(ctx, appObj, validateOptions{autoZone: autoZone, applyIdentity: true})
 	if err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
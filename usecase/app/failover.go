@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// FailoverInput represents a command to move an app to its standby cluster.
+type FailoverInput struct {
+	// AppID identifies the app.
+	AppID string `json:"app_id"`
+	// AutoZone pins node scheduling to the zone of the restored zonal disks.
+	AutoZone bool `json:"auto_zone,omitempty"`
+}
+
+// FailoverOutput reports the restored volumes and the deployment on the standby cluster.
+type FailoverOutput struct {
+	App string `json:"app"`
+	// FromCluster is the primary cluster the app fails over from.
+	FromCluster string `json:"from_cluster"`
+	// ToClusterID and ToCluster identify the standby cluster the app now runs on.
+	ToClusterID string           `json:"to_cluster_id"`
+	ToCluster   string           `json:"to_cluster"`
+	Volumes     []FailoverVolume `json:"volumes"`
+	// AppliedCount is the number of Kubernetes objects applied to the standby cluster.
+	AppliedCount int      `json:"applied_count"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// FailoverVolume is the restore of one volume on the standby cluster.
+type FailoverVolume struct {
+	Volume string `json:"volume"`
+	// Replica is the snapshot the disk was created from.
+	Replica string `json:"replica,omitempty"`
+	// ReplicaTime is the point in time the volume was restored to.
+	ReplicaTime time.Time `json:"replica_time,omitzero"`
+	// Disk is the created and assigned disk.
+	Disk    string `json:"disk,omitempty"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Failover restores the app on its standby cluster. For each disk volume a disk is created
+// from the latest replica in the standby provider and assigned, then the app is deployed to
+// the standby cluster. The primary cluster is not accessed beyond reading its definition, so
+// failover works while the primary region is unavailable. DNS records and the KOM definition
+// (swapping the app cluster and the standby) are updated by the caller.
+func (u *UseCase) Failover(ctx context.Context, in *FailoverInput) (*FailoverOutput, error) {
+	if in == nil || in.AppID == "" {
+		return nil, fmt.Errorf("missing app ID")
+	}
+	logger := logging.FromContext(ctx)
+	msgSym := "UC:app.failover"
+
+	appObj, err := u.Repos.App.Get(ctx, in.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+	if appObj == nil {
+		return nil, fmt.Errorf("app not found: %s", in.AppID)
+	}
+	standbyApp, err := appObj.OnStandby()
+	if err != nil {
+		return nil, err
+	}
+	cls, err := u.getCluster(ctx, appObj.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	standbyCls, err := u.getCluster(ctx, standbyApp.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := u.VolumePort.Capabilities(ctx, standbyCls)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+
+	out := &FailoverOutput{App: appObj.Name, FromCluster: cls.Name, ToClusterID: standbyCls.ID, ToCluster: standbyCls.Name}
+	for _, vol := range standbyApp.Volumes {
+		if vol.Type == model.VolumeTypeFiles {
+			out.Volumes = append(out.Volumes, FailoverVolume{Volume: vol.Name, Skipped: "files volumes are not replicated"})
+			continue
+		}
+		if err := caps.CheckVolume(vol); err != nil {
+			return out, err
+		}
+		replicas, err := u.listReplicas(ctx, standbyCls, standbyApp, vol.Name, replicaRole(cls, standbyCls))
+		if err != nil {
+			return out, err
+		}
+		if len(replicas) == 0 {
+			return out, fmt.Errorf("no replica of volume %s found for cluster %s", vol.Name, standbyCls.Name)
+		}
+		replica := replicas[0]
+		fv := FailoverVolume{Volume: vol.Name, Replica: replica.Name, ReplicaTime: replicaTime(replica)}
+		logger.Info(ctx, msgSym+":Restore", "volume", vol.Name, "replica", replica.Name, "replicaTime", fv.ReplicaTime)
+
+		var opts []model.VolumeDiskCreateOption
+		if replica.Size > vol.Size {
+			opts = append(opts, model.WithVolumeDiskCreateSize(replica.Size))
+		}
+		opts = append(opts, model.WithVolumeDiskCreateDescription("failover from cluster "+cls.Name))
+		disk, err := u.VolumePort.DiskCreate(ctx, standbyCls, standbyApp, vol.Name, "", "snapshot:"+replica.Name, opts...)
+		if err != nil {
+			return out, fmt.Errorf("restore volume %s from %s: %w", vol.Name, replica.Name, err)
+		}
+		if err := u.VolumePort.DiskAssign(ctx, standbyCls, standbyApp, vol.Name, disk.Name); err != nil {
+			return out, fmt.Errorf("assign disk %s of volume %s: %w", disk.Name, vol.Name, err)
+		}
+		fv.Disk = disk.Name
+		out.Volumes = append(out.Volumes, fv)
+	}
+
+	logger.Info(ctx, msgSym+":Deploy", "cluster", standbyCls.Name)
+	dep, err := u.deployApp(ctx, standbyApp, in.AutoZone)
+	if err != nil {
+		return out, fmt.Errorf("deploy to cluster %s: %w", standbyCls.Name, err)
+	}
+	out.AppliedCount, out.Warnings = dep.AppliedCount, dep.Warnings
+	return out, nil
+}
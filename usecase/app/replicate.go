@@ -0,0 +1,306 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// Snapshot labels recording replication lineage. Source snapshots taken on the primary cluster
+// carry replica=source; copies in the standby provider carry replica=copy together with the
+// name and creation time of the source snapshot.
+const (
+	replicaLabel       = "replica"
+	replicaSourceLabel = "replica_source"
+	replicaTimeLabel   = "replica_time"
+
+	replicaRoleSource = "source"
+	replicaRoleCopy   = "copy"
+)
+
+// ReplicateInput represents a command to replicate the app volumes to the standby cluster.
+type ReplicateInput struct {
+	// AppID identifies the app.
+	AppID string `json:"app_id"`
+	// Volumes limits the replicated volumes; empty replicates all disk volumes.
+	Volumes []string `json:"volumes,omitempty"`
+	// Force replicates even when the latest replica is newer than the standby interval.
+	Force bool `json:"force,omitempty"`
+}
+
+// ReplicateOutput reports the replication of each volume.
+type ReplicateOutput struct {
+	App            string              `json:"app"`
+	StandbyCluster string              `json:"standby_cluster"`
+	Volumes        []VolumeReplication `json:"volumes"`
+}
+
+// VolumeReplication is the replication of one volume.
+type VolumeReplication struct {
+	Volume string `json:"volume"`
+	// Source is the snapshot taken on the primary cluster.
+	Source string `json:"source,omitempty"`
+	// Replica is the snapshot in the standby provider.
+	Replica string `json:"replica,omitempty"`
+	// ReplicaTime is the point in time captured by the latest replica.
+	ReplicaTime time.Time `json:"replica_time,omitzero"`
+	// Pruned lists the snapshots deleted beyond the retain count.
+	Pruned []string `json:"pruned,omitempty"`
+	// Skipped explains why no replica was made.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// VolumeReplicationStatus reports the replication lag of one volume.
+type VolumeReplicationStatus struct {
+	Volume        string    `json:"volume"`
+	LatestReplica string    `json:"latest_replica,omitempty"`
+	ReplicaTime   time.Time `json:"replica_time,omitzero"`
+	// Lag is the age of the latest replica (the data lost by a failover now).
+	Lag string `json:"lag,omitempty"`
+	// Stale reports that the lag exceeds the replication interval or no replica exists.
+	Stale bool   `json:"stale"`
+	Error string `json:"error,omitempty"`
+}
+
+// Replicate takes an incremental snapshot of each disk volume on the primary cluster and copies
+// it to the provider of the standby cluster. Volumes whose latest replica is newer than the
+// standby interval are skipped unless Force is set, so the command can run periodically from
+// cron. When both clusters share a provider the source snapshots serve as replicas. Replicas
+// beyond the standby retain count are pruned on both sides.
+func (u *UseCase) Replicate(ctx context.Context, in *ReplicateInput) (*ReplicateOutput, error) {
+	if in == nil || in.AppID == "" {
+		return nil, fmt.Errorf("missing app ID")
+	}
+	logger := logging.FromContext(ctx)
+	msgSym := "UC:app.replicate"
+
+	appObj, err := u.Repos.App.Get(ctx, in.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+	if appObj == nil {
+		return nil, fmt.Errorf("app not found: %s", in.AppID)
+	}
+	standbyApp, err := appObj.OnStandby()
+	if err != nil {
+		return nil, err
+	}
+	cls, err := u.getCluster(ctx, appObj.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	standbyCls, err := u.getCluster(ctx, standbyApp.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := u.VolumePort.Capabilities(ctx, cls)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+	standbyCaps, err := u.VolumePort.Capabilities(ctx, standbyCls)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+	sameProvider := cls.ProviderID == standbyCls.ProviderID
+
+	volumes, err := replicatedVolumes(appObj, in.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	out := &ReplicateOutput{App: appObj.Name, StandbyCluster: standbyCls.Name}
+	now := time.Now()
+	for _, vol := range volumes {
+		if err := caps.CheckVolumeSnapshot(vol); err != nil {
+			return out, err
+		}
+		if !sameProvider {
+			if err := standbyCaps.CheckVolumeSnapshotCopy(vol); err != nil {
+				return out, err
+			}
+		}
+		rep := VolumeReplication{Volume: vol.Name}
+		replicas, err := u.listReplicas(ctx, standbyCls, standbyApp, vol.Name, replicaRole(cls, standbyCls))
+		if err != nil {
+			return out, err
+		}
+		if len(replicas) > 0 {
+			rep.Replica, rep.ReplicaTime = replicas[0].Name, replicaTime(replicas[0])
+			if !in.Force && now.Sub(rep.ReplicaTime) < appObj.Standby.ReplicationInterval() {
+				rep.Skipped = "latest replica is within the replication interval"
+				out.Volumes = append(out.Volumes, rep)
+				continue
+			}
+		}
+
+		logger.Info(ctx, msgSym+":Snapshot", "volume", vol.Name, "cluster", cls.Name)
+		src, err := u.VolumePort.SnapshotCreate(ctx, cls, appObj, vol.Name, "", "",
+			model.WithVolumeSnapshotCreateIncremental(),
+			model.WithVolumeSnapshotCreateLabels(map[string]string{replicaLabel: replicaRoleSource}),
+			model.WithVolumeSnapshotCreateDescription("replica source for standby cluster "+standbyCls.Name),
+		)
+		if err != nil {
+			return out, fmt.Errorf("snapshot volume %s: %w", vol.Name, err)
+		}
+		rep.Source, rep.Replica, rep.ReplicaTime = src.Name, src.Name, replicaTime(src)
+		if !sameProvider {
+			logger.Info(ctx, msgSym+":Copy", "volume", vol.Name, "snapshot", src.Name, "cluster", standbyCls.Name)
+			replica, err := u.VolumePort.SnapshotCopy(ctx, standbyCls, standbyApp, vol.Name, "", src,
+				model.WithVolumeSnapshotCopyLabels(map[string]string{
+					replicaLabel:       replicaRoleCopy,
+					replicaSourceLabel: src.Name,
+					replicaTimeLabel:   rep.ReplicaTime.UTC().Format(time.RFC3339),
+				}),
+				model.WithVolumeSnapshotCopyDescription("replica of "+src.Name+" from cluster "+cls.Name),
+			)
+			if err != nil {
+				return out, fmt.Errorf("copy snapshot %s of volume %s: %w", src.Name, vol.Name, err)
+			}
+			rep.Replica = replica.Name
+		}
+
+		retain := appObj.Standby.RetainCount()
+		pruned, err := u.pruneReplicas(ctx, cls, appObj, vol.Name, replicaRoleSource, retain)
+		rep.Pruned = append(rep.Pruned, pruned...)
+		if err != nil {
+			logger.Warn(ctx, msgSym+":PruneFailed", "volume", vol.Name, "cluster", cls.Name, "err", err)
+		}
+		if !sameProvider {
+			pruned, err := u.pruneReplicas(ctx, standbyCls, standbyApp, vol.Name, replicaRoleCopy, retain)
+			rep.Pruned = append(rep.Pruned, pruned...)
+			if err != nil {
+				logger.Warn(ctx, msgSym+":PruneFailed", "volume", vol.Name, "cluster", standbyCls.Name, "err", err)
+			}
+		}
+		out.Volumes = append(out.Volumes, rep)
+	}
+	return out, nil
+}
+
+// replicationStatus reports the replication lag of the disk volumes of an app with a standby cluster.
+func (u *UseCase) replicationStatus(ctx context.Context, app *model.App, cls *model.Cluster) ([]VolumeReplicationStatus, error) {
+	standbyApp, err := app.OnStandby()
+	if err != nil {
+		return nil, err
+	}
+	standbyCls, err := u.getCluster(ctx, standbyApp.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	role := replicaRole(cls, standbyCls)
+	volumes, err := replicatedVolumes(app, nil)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var out []VolumeReplicationStatus
+	for _, vol := range volumes {
+		st := VolumeReplicationStatus{Volume: vol.Name, Stale: true}
+		replicas, err := u.listReplicas(ctx, standbyCls, standbyApp, vol.Name, role)
+		if err != nil {
+			st.Error = err.Error()
+		} else if len(replicas) > 0 {
+			st.LatestReplica, st.ReplicaTime = replicas[0].Name, replicaTime(replicas[0])
+			lag := now.Sub(st.ReplicaTime)
+			st.Lag = lag.Round(time.Second).String()
+			st.Stale = lag > app.Standby.ReplicationInterval()
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// listReplicas returns the snapshots of a volume labeled with the replica role, newest first.
+func (u *UseCase) listReplicas(ctx context.Context, cls *model.Cluster, app *model.App, volName, role string) ([]*model.VolumeSnapshot, error) {
+	snaps, err := u.VolumePort.SnapshotList(ctx, cls, app, volName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots of volume %s in cluster %s: %w", volName, cls.Name, err)
+	}
+	var replicas []*model.VolumeSnapshot
+	for _, s := range snaps {
+		if s != nil && s.Labels[replicaLabel] == role {
+			replicas = append(replicas, s)
+		}
+	}
+	slices.SortStableFunc(replicas, func(a, b *model.VolumeSnapshot) int {
+		return cmp.Compare(replicaTime(b).UnixNano(), replicaTime(a).UnixNano())
+	})
+	return replicas, nil
+}
+
+// pruneReplicas deletes the replicas of a volume beyond the newest retain ones.
+func (u *UseCase) pruneReplicas(ctx context.Context, cls *model.Cluster, app *model.App, volName, role string, retain int) ([]string, error) {
+	replicas, err := u.listReplicas(ctx, cls, app, volName, role)
+	if err != nil || len(replicas) <= retain {
+		return nil, err
+	}
+	var pruned []string
+	for _, s := range replicas[retain:] {
+		if err := u.VolumePort.SnapshotDelete(ctx, cls, app, volName, s.Name); err != nil {
+			return pruned, fmt.Errorf("delete snapshot %s: %w", s.Name, err)
+		}
+		pruned = append(pruned, s.Name)
+	}
+	return pruned, nil
+}
+
+// replicaRole returns the role of the replicas found in the standby provider. When both
+// clusters share a provider the source snapshots are the replicas.
+func replicaRole(cls, standbyCls *model.Cluster) string {
+	if cls.ProviderID == standbyCls.ProviderID {
+		return replicaRoleSource
+	}
+	return replicaRoleCopy
+}
+
+// replicaTime returns the point in time captured by a replica: the creation time of the
+// source snapshot for copies, otherwise the snapshot creation time.
+func replicaTime(s *model.VolumeSnapshot) time.Time {
+	if v := s.Labels[replicaTimeLabel]; v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return s.CreatedAt
+}
+
+// replicatedVolumes returns the disk volumes of an app, limited to names when given.
+// Files volumes are shared storage without snapshots and are not replicated.
+func replicatedVolumes(app *model.App, names []string) ([]model.AppVolume, error) {
+	for _, name := range names {
+		v, err := app.FindVolume(name)
+		if err != nil {
+			return nil, err
+		}
+		if v.Type == model.VolumeTypeFiles {
+			return nil, fmt.Errorf("volume %s of type files cannot be replicated", name)
+		}
+	}
+	var out []model.AppVolume
+	for _, v := range app.Volumes {
+		if v.Type == model.VolumeTypeFiles {
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, v.Name) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// getCluster returns the cluster or an error when it does not exist.
+func (u *UseCase) getCluster(ctx context.Context, id string) (*model.Cluster, error) {
+	cls, err := u.Repos.Cluster.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if cls == nil {
+		return nil, fmt.Errorf("cluster not found: %s", id)
+	}
+	return cls, nil
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// snapshotVolumePort keeps snapshots in memory per cluster and volume.
+type snapshotVolumePort struct {
+	fakeVolumePort
+	base  time.Time
+	seq   int
+	snaps map[string][]*model.VolumeSnapshot
+}
+
+func (p *snapshotVolumePort) Capabilities(context.Context, *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{Volumes: map[string]model.VolumeTypeCapabilities{
+		model.VolumeTypeDisk: {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true, Copy: true},
+	}}, nil
+}
+func (p *snapshotVolumePort) SnapshotList(_ context.Context, c *model.Cluster, _ *model.App, volName string, _ ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	return p.snaps[c.ID+"/"+volName], nil
+}
+func (p *snapshotVolumePort) add(c *model.Cluster, volName string, labels map[string]string) *model.VolumeSnapshot {
+	p.seq++
+	s := &model.VolumeSnapshot{Name: fmt.Sprintf("snap%d", p.seq), VolumeName: volName, Labels: labels, CreatedAt: p.base.Add(time.Duration(p.seq) * time.Second)}
+	p.snaps[c.ID+"/"+volName] = append(p.snaps[c.ID+"/"+volName], s)
+	return s
+}
+func (p *snapshotVolumePort) SnapshotCreate(_ context.Context, c *model.Cluster, _ *model.App, volName, _, _ string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	var o model.VolumeSnapshotCreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.Incremental {
+		return nil, fmt.Errorf("replica source must be incremental")
+	}
+	return p.add(c, volName, o.Labels), nil
+}
+func (p *snapshotVolumePort) SnapshotCopy(_ context.Context, c *model.Cluster, _ *model.App, volName, _ string, _ *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	var o model.VolumeSnapshotCopyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return p.add(c, volName, o.Labels), nil
+}
+func (p *snapshotVolumePort) SnapshotDelete(_ context.Context, c *model.Cluster, _ *model.App, volName, snapName string, _ ...model.VolumeSnapshotDeleteOption) error {
+	key := c.ID + "/" + volName
+	for i, s := range p.snaps[key] {
+		if s.Name == snapName {
+			p.snaps[key] = append(p.snaps[key][:i], p.snaps[key][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot %s not found", snapName)
+}
+
+type clusterMapRepo struct {
+	singleClusterRepo
+	items map[string]*model.Cluster
+}
+
+func (r *clusterMapRepo) Get(_ context.Context, id string) (*model.Cluster, error) {
+	return r.items[id], nil
+}
+
+func TestReplicate(t *testing.T) {
+	ctx := context.Background()
+	const standbyID = "/ws/ws1/prv/prv2/cls/cls2"
+	primary := &model.Cluster{ID: testClusterID, ProviderID: testProviderID, Name: "cls1"}
+	standby := &model.Cluster{ID: standbyID, ProviderID: "/ws/ws1/prv/prv2", Name: "cls2"}
+	app := &model.App{
+		ID:        testAppID,
+		Name:      "app1",
+		ClusterID: testClusterID,
+		Volumes: []model.AppVolume{
+			{Name: "data", Size: 1 << 30},
+			{Name: "shared", Type: model.VolumeTypeFiles},
+		},
+		Standby: &model.AppStandby{ClusterID: standbyID, Retain: 2},
+	}
+	port := &snapshotVolumePort{base: time.Now().Add(-time.Minute).Truncate(time.Second), snaps: map[string][]*model.VolumeSnapshot{}}
+	uc := &UseCase{
+		Repos: &Repos{
+			App:     &singleAppRepo{item: app},
+			Cluster: &clusterMapRepo{items: map[string]*model.Cluster{primary.ID: primary, standby.ID: standby}},
+		},
+		VolumePort: port,
+	}
+
+	out, err := uc.Replicate(ctx, &ReplicateInput{AppID: testAppID})
+	if err != nil {
+		t.Fatalf("replicate: %v", err)
+	}
+	if len(out.Volumes) != 1 || out.Volumes[0].Source != "snap1" || out.Volumes[0].Replica != "snap2" {
+		t.Fatalf("unexpected replication: %+v", out.Volumes)
+	}
+	copies := port.snaps[standbyID+"/data"]
+	if len(copies) != 1 || copies[0].Labels[replicaSourceLabel] != "snap1" || !replicaTime(copies[0]).Equal(port.snaps[testClusterID+"/data"][0].CreatedAt) {
+		t.Fatalf("unexpected replica: %+v", copies)
+	}
+
+	// The latest replica is within the interval.
+	if out, err = uc.Replicate(ctx, &ReplicateInput{AppID: testAppID}); err != nil {
+		t.Fatalf("replicate again: %v", err)
+	}
+	if out.Volumes[0].Skipped == "" {
+		t.Errorf("expected replication to be skipped: %+v", out.Volumes[0])
+	}
+
+	for range 3 {
+		if _, err := uc.Replicate(ctx, &ReplicateInput{AppID: testAppID, Force: true}); err != nil {
+			t.Fatalf("forced replicate: %v", err)
+		}
+	}
+	if n := len(port.snaps[testClusterID+"/data"]); n != 2 {
+		t.Errorf("primary keeps %d source snapshots, want 2", n)
+	}
+	copies = port.snaps[standbyID+"/data"]
+	if len(copies) != 2 || copies[1].Name != "snap8" {
+		t.Errorf("unexpected replicas after pruning: %+v", copies)
+	}
+
+	st, err := uc.replicationStatus(ctx, app, primary)
+	if err != nil {
+		t.Fatalf("replication status: %v", err)
+	}
+	if len(st) != 1 || st[0].LatestReplica != "snap8" || st[0].Stale {
+		t.Errorf("unexpected replication status: %+v", st)
+	}
+
+	if _, err := uc.Replicate(ctx, &ReplicateInput{AppID: testAppID, Volumes: []string{"shared"}}); err == nil {
+		t.Errorf("expected error replicating a files volume")
+	}
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// RescheduleInput represents a command to move an app to a fallback node pool.
+type RescheduleInput struct {
+	// AppID identifies the app.
+	AppID string `json:"app_id"`
+	// DryRun reports the selected pool without patching the deployment.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Reset removes the pool pinned by a previous reschedule.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// RescheduleOutput represents the result of a reschedule.
+type RescheduleOutput struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+	// Pending lists the pods that failed scheduling with the scheduler message.
+	Pending []kube.SchedulingFailure `json:"pending,omitempty"`
+	// FromPool is the pool the deployment was pinned to or preferred before the reschedule.
+	FromPool string `json:"from_pool,omitempty"`
+	// ToPool is the pool the deployment is pinned to; empty after reset.
+	ToPool string `json:"to_pool,omitempty"`
+	// Skipped lists the fallback pools that were not eligible and why.
+	Skipped []string `json:"skipped,omitempty"`
+	Patched bool     `json:"patched"`
+	DryRun  bool     `json:"dry_run"`
+	Message string   `json:"message"`
+}
+
+// Reschedule moves an app whose pods cannot be scheduled to the next node pool of its
+// deployment pool order (AppDeployment.PoolPreferences or Pools). Pods failing scheduling
+// are detected from FailedScheduling events. The next pool after the current one that can
+// run nodes and spans the zone of the assigned zonal disks is pinned with a nodeSelector
+// patch on the Deployment. The pin is kept by later deploys until Reset removes it.
+func (u *UseCase) Reschedule(ctx context.Context, in *RescheduleInput) (*RescheduleOutput, error) {
+	if in == nil || in.AppID == "" {
+		return nil, fmt.Errorf("missing app ID")
+	}
+
+	appObj, err := u.Repos.App.Get(ctx, in.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app: %w", err)
+	}
+	if appObj == nil {
+		return nil, fmt.Errorf("app not found: %s", in.AppID)
+	}
+	order := appObj.Deployment.TargetPools()
+	if len(appObj.Deployment.PoolPreferences) == 0 && len(appObj.Deployment.Pools) == 0 {
+		return nil, fmt.Errorf("app %s declares no deployment.poolPreferences or deployment.pools to fall back to", appObj.Name)
+	}
+	cls, err := u.Repos.Cluster.Get(ctx, appObj.ClusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if cls == nil {
+		return nil, fmt.Errorf("cluster not found: %s", appObj.ClusterID)
+	}
+	prv, err := u.Repos.Provider.Get(ctx, cls.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	if prv == nil {
+		return nil, fmt.Errorf("provider not found: %s", cls.ProviderID)
+	}
+	ws, err := u.Repos.Workspace.Get(ctx, prv.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	c := kube.NewConverter(ws, prv, cls, appObj, "app")
+	if _, err := c.Convert(ctx); err != nil {
+		return nil, fmt.Errorf("convert failed: %w", err)
+	}
+
+	factory, ok := providerdrv.GetDriverFactory(prv.Driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider driver: %s", prv.Driver)
+	}
+	drv, err := factory(ws, prv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver %s: %w", prv.Driver, err)
+	}
+	kubeconfig, err := drv.ClusterKubeconfig(ctx, cls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster kubeconfig: %w", err)
+	}
+	kcli, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %w", err)
+	}
+
+	out := &RescheduleOutput{Namespace: c.Namespace, Deployment: c.ResourceName, DryRun: in.DryRun}
+	pinned, err := kcli.DeploymentNodePool(ctx, c.Namespace, c.ResourceName)
+	if err != nil {
+		return nil, err
+	}
+	out.FromPool = pinned
+
+	if in.Reset {
+		if pinned == "" {
+			out.Message = "no node pool pinned"
+			return out, nil
+		}
+		if !in.DryRun {
+			if err := kcli.PatchDeploymentNodePool(ctx, c.Namespace, c.ResourceName, ""); err != nil {
+				return nil, err
+			}
+			out.Patched = true
+		}
+		out.Message = fmt.Sprintf("removed node pool pin %s", pinned)
+		return out, nil
+	}
+
+	if out.Pending, err = kcli.PodSchedulingFailures(ctx, c.Namespace, c.SelectorString); err != nil {
+		return nil, err
+	}
+	if len(out.Pending) == 0 {
+		out.Message = "no pods failing to schedule"
+		return out, nil
+	}
+	if out.FromPool == "" {
+		out.FromPool = order[0]
+	}
+
+	zone, err := u.assignedDiskZone(ctx, cls, appObj, drv)
+	if err != nil {
+		return nil, err
+	}
+	var pools []*model.NodePool
+	if u.NodePoolPort != nil {
+		if pools, err = u.NodePoolPort.NodePoolList(ctx, cls); err != nil {
+			return nil, fmt.Errorf("failed to list node pools: %w", err)
+		}
+	}
+	next, skipped := nextFallbackPool(order, out.FromPool, pools, u.NodePoolPort != nil, zone)
+	out.Skipped = skipped
+	if next == "" {
+		return out, fmt.Errorf("no fallback node pool left after %s in %v", out.FromPool, order)
+	}
+	out.ToPool = next
+	if !in.DryRun {
+		if err := kcli.PatchDeploymentNodePool(ctx, c.Namespace, c.ResourceName, next); err != nil {
+			return nil, err
+		}
+		out.Patched = true
+	}
+	out.Message = fmt.Sprintf("node pool %s -> %s", out.FromPool, next)
+	return out, nil
+}
+
+// assignedDiskZone returns the zone of the assigned disks of the app volumes that are not
+// node-local, or an empty string when no such disk has a zone.
+func (u *UseCase) assignedDiskZone(ctx context.Context, cluster *model.Cluster, app *model.App, drv providerdrv.Driver) (string, error) {
+	if u.VolumePort == nil {
+		return "", nil
+	}
+	for _, av := range app.Volumes {
+		if vc, err := drv.VolumeClass(ctx, cluster, app, av); err == nil && vc.NodeLocal {
+			continue
+		}
+		disks, err := u.VolumePort.DiskList(ctx, cluster, app, av.Name)
+		if err != nil {
+			return "", fmt.Errorf("volume disk lookup failed for %s: %w", av.Name, err)
+		}
+		for _, d := range disks {
+			if d != nil && d.Assigned && d.Zone != "" {
+				return d.Zone, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// nextFallbackPool returns the first pool after current in order that is eligible for the
+// zone. Pools in order are logical names (kompox.dev/node-pool) and may be backed by several
+// provider pools (e.g., AKS "npuser1".."npuser3"); a logical pool is eligible when any of them
+// accepts the zone. When known is true only pools listed in pools are eligible; otherwise pool
+// eligibility is not checked. Pools passed over are returned with the reason.
+func nextFallbackPool(order []string, current string, pools []*model.NodePool, known bool, zone string) (string, []string) {
+	byName := map[string][]*model.NodePool{}
+	for _, p := range pools {
+		if name := p.LogicalName(); name != "" {
+			byName[name] = append(byName[name], p)
+		}
+	}
+	var skipped []string
+	for _, name := range order[slices.Index(order, current)+1:] {
+		if !known {
+			return name, skipped
+		}
+		members, ok := byName[name]
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("pool %s not found", name))
+			continue
+		}
+		var reasons []string
+		for _, p := range members {
+			if _, reason := nodePoolAccepts(p, zone); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+		if len(reasons) == len(members) {
+			skipped = append(skipped, reasons...)
+			continue
+		}
+		return name, skipped
+	}
+	return "", skipped
+}
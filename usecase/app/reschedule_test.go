@@ -0,0 +1,69 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestNextFallbackPool(t *testing.T) {
+	pool := func(name string, max int, zones ...string) *model.NodePool {
+		return &model.NodePool{Name: &name, Zones: &zones, Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Max: max}}
+	}
+	pools := []*model.NodePool{
+		pool("spot", 5, "japaneast-1"),
+		pool("spot2", 0, "japaneast-1"),
+		pool("user", 3, "japaneast-2"),
+		pool("user2", 3, "japaneast-1", "japaneast-2"),
+	}
+	order := []string{"spot", "spot2", "missing", "user", "user2"}
+
+	tests := []struct {
+		name    string
+		current string
+		known   bool
+		zone    string
+		want    string
+	}{
+		{name: "skips ineligible pools", current: "spot", known: true, want: "user"},
+		{name: "respects disk zone", current: "spot", known: true, zone: "1", want: "user2"},
+		{name: "unknown current starts at first", current: "other", known: true, want: "spot"},
+		{name: "exhausted", current: "user2", known: true, want: ""},
+		{name: "pool port unavailable", current: "spot", known: false, want: "spot2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped := nextFallbackPool(order, tt.current, pools, tt.known, tt.zone)
+			if got != tt.want {
+				t.Errorf("next = %q, want %q (skipped %v)", got, tt.want, skipped)
+			}
+			if tt.current == "spot" && tt.known && !slices.ContainsFunc(skipped, func(s string) bool { return s == "pool missing not found" }) {
+				t.Errorf("skipped = %v, want missing pool reported", skipped)
+			}
+		})
+	}
+}
+
+func TestNextFallbackPoolAKSLabels(t *testing.T) {
+	pool := func(name, label string, zones ...string) *model.NodePool {
+		labels := map[string]string{model.NodePoolLabel: label}
+		return &model.NodePool{Name: &name, ProviderName: &name, Labels: &labels, Zones: &zones, Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Max: 3}}
+	}
+	pools := []*model.NodePool{
+		pool("npsystem", "system", "japaneast-1"),
+		pool("npspot1", "spot", "japaneast-1"),
+		pool("npuser1", "user", "japaneast-2"),
+		pool("npuser2", "user", "japaneast-1"),
+	}
+	order := []string{"spot", "user"}
+
+	got, skipped := nextFallbackPool(order, "spot", pools, true, "1")
+	if got != "user" || len(skipped) != 0 {
+		t.Errorf("next = %q (skipped %v), want user", got, skipped)
+	}
+	got, skipped = nextFallbackPool(order, "spot", pools[:3], true, "1")
+	if got != "" || len(skipped) != 1 || skipped[0] != "pool user (npuser1) spans zones [japaneast-2]" {
+		t.Errorf("next = %q (skipped %v), want none", got, skipped)
+	}
+}
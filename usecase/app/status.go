@@ -35,6 +35,9 @@ type StatusOutput struct {
 	Command      []string `json:"command"`
 	Args         []string `json:"args"`
 	IngressHosts []string `json:"ingress_hosts"`
+	// StandbyCluster and Replication report the replication lag to the standby cluster.
+	StandbyCluster string                    `json:"standby_cluster,omitempty"`
+	Replication    []VolumeReplicationStatus `json:"replication,omitempty"`
 }
 
 // Status returns status information about an app, including generated ingress hostnames.
@@ -204,6 +207,12 @@ func (u *UseCase) Status(ctx context.Context, in *StatusInput) (*StatusOutput, e
 		Args:         args,
 		IngressHosts: hosts,
 	}
+	if appObj.Standby != nil {
+		out.StandbyCluster = appObj.Standby.ClusterID
+		if out.Replication, err = u.replicationStatus(ctx, appObj, cls); err != nil {
+			return nil, fmt.Errorf("failed to get replication status: %w", err)
+		}
+	}
 	// keep types stable (no-op use): ensure JSON tags compile
 	_, _ = json.Marshal(out)
 	return out, nil
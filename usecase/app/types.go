@@ -18,4 +18,13 @@ type Repos struct {
 type UseCase struct {
 	Repos      *Repos
 	VolumePort model.VolumePort
+	// NodePoolPort is used to check that assigned zonal disks are schedulable.
+	// When nil, the check is skipped.
+	NodePoolPort model.NodePoolPort
+	// IdentityPort creates the cloud workload identity of apps with spec.identity at deploy.
+	// When nil, deploying such apps fails.
+	IdentityPort model.AppIdentityPort
+	// SecretPort reads the passwords of the cluster registries (cluster.Registries) that the
+	// app images are pulled from. When nil, validating such apps fails.
+	SecretPort model.SecretPort
 }
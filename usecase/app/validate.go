@@ -13,6 +13,8 @@ import (
 type ValidateInput struct {
 	// AppID is the application being validated.
 	AppID string `json:"app_id"`
+	// AutoZone pins node scheduling to the zone of the assigned zonal disks.
+	AutoZone bool `json:"auto_zone,omitempty"`
 }
 
 // ValidateOutput reports validation outcomes.
@@ -47,7 +49,7 @@ func (u *UseCase) Validate(ctx context.Context, in *ValidateInput) (*ValidateOut
 		return out, fmt.Errorf("app not found: %s", in.AppID)
 	}
 
-	res, err := u.validateApp(ctx, app)
+	res, err := u.validateApp(ctx, app, validateOptions{autoZone: in.AutoZone})
 	if err != nil {
 		return out, err
 	}
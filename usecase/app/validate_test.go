@@ -3,11 +3,15 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
 	"github.com/kompox/kompox/domain/model"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -71,6 +75,225 @@ func TestValidateErrorsOnMultipleAssignments(t *testing.T) {
 	}
 }
 
+func TestValidateErrorsOnInvalidVolumeOptions(t *testing.T) {
+	uc := buildTestUseCase(t, map[string][]*model.VolumeDisk{})
+	uc.Repos.App.(*singleAppRepo).item.Volumes[0].Options = map[string]any{"invalid": true}
+	out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+	if err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+	if len(out.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", out.Errors)
+	}
+	if got := out.Errors[0]; !strings.Contains(got, "volume options invalid") {
+		t.Fatalf("unexpected error message: %s", got)
+	}
+}
+
+func TestValidateErrorsOnUnsupportedVolumeType(t *testing.T) {
+	uc := buildTestUseCase(t, map[string][]*model.VolumeDisk{})
+	uc.Repos.App.(*singleAppRepo).item.Volumes[0].Type = model.VolumeTypeFiles
+	out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+	if err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+	if len(out.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", out.Errors)
+	}
+	if out.Issues[0].Code != "volume_type_unsupported" || !strings.Contains(out.Errors[0], `volume type "files"`) {
+		t.Fatalf("unexpected issue: %+v", out.Issues[0])
+	}
+}
+
+func TestValidateErrorsOnUnsupportedIdentity(t *testing.T) {
+	uc := buildTestUseCase(t, map[string][]*model.VolumeDisk{})
+	uc.Repos.App.(*singleAppRepo).item.Identity = &model.AppIdentity{Roles: []model.AppIdentityRole{{Scope: "/scope", Role: "Reader"}}}
+	out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+	if err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+	if len(out.Errors) != 1 || out.Issues[0].Code != "identity_unsupported" {
+		t.Fatalf("unexpected issues: %+v", out.Issues)
+	}
+}
+
+func TestValidateRegistryCredentials(t *testing.T) {
+	disks := func() map[string][]*model.VolumeDisk {
+		return map[string][]*model.VolumeDisk{"data": {{Name: "d1", VolumeName: "data", Assigned: true, Handle: "handle-1"}}}
+	}
+	build := func(t *testing.T, secrets map[string]string) (*UseCase, *fakeSecretPort) {
+		uc := buildTestUseCase(t, disks())
+		uc.Repos.App.(*singleAppRepo).item.Compose = strings.Replace(composeYAML, "image: nginx", "image: ghcr.io/org/app:1.0", 1)
+		uc.Repos.Cluster.(*singleClusterRepo).item.Registries = []model.ClusterRegistry{
+			{Server: "GHCR.io", Username: "bot", PasswordSource: "file:/secrets/ghcr"},
+			{Server: "quay.io", Username: "bot", PasswordSource: "file:/secrets/quay"},
+		}
+		port := &fakeSecretPort{values: secrets}
+		uc.SecretPort = port
+		return uc, port
+	}
+
+	t.Run("distributes used registries", func(t *testing.T) {
+		uc, port := build(t, map[string]string{"file:/secrets/ghcr": "token\n", "file:/secrets/quay": "other"})
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 0 {
+			t.Fatalf("unexpected issues: %+v", out.Issues)
+		}
+		if !slices.Equal(port.reads, []string{"file:/secrets/ghcr"}) {
+			t.Errorf("secret reads = %v", port.reads)
+		}
+		var doc string
+		for _, obj := range out.K8sObjects {
+			if sec, ok := obj.(*corev1.Secret); ok && sec.Name == "app1-app--registry" {
+				doc = string(sec.Data[corev1.DockerConfigJsonKey])
+			}
+		}
+		if !strings.Contains(doc, `"ghcr.io":{"username":"bot","password":"token"`) || strings.Contains(doc, "quay.io") {
+			t.Errorf("unexpected registry secret: %s", doc)
+		}
+	})
+
+	t.Run("unreadable credentials", func(t *testing.T) {
+		uc, _ := build(t, map[string]string{})
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 1 || out.Issues[len(out.Issues)-1].Code != "registry_credentials_unavailable" || len(out.K8sObjects) != 0 {
+			t.Fatalf("unexpected issues: %+v", out.Issues)
+		}
+	})
+}
+
+func TestValidateVolumeZones(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	zonalDisks := func() map[string][]*model.VolumeDisk {
+		return map[string][]*model.VolumeDisk{
+			"data": {{Name: "d1", VolumeName: "data", Assigned: true, Handle: "handle-1", Zone: "1"}},
+		}
+	}
+	userPool := func(max int, zones ...string) *model.NodePool {
+		return &model.NodePool{Name: ptr("user"), Zones: &zones, Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 0, Max: max}}
+	}
+	issueCodes := func(issues []Issue) []string {
+		var codes []string
+		for _, is := range issues {
+			codes = append(codes, is.Code)
+		}
+		return codes
+	}
+
+	t.Run("schedulable", func(t *testing.T) {
+		uc := buildTestUseCase(t, zonalDisks())
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{userPool(3, "japaneast-1", "japaneast-2")}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 0 || len(out.K8sObjects) == 0 {
+			t.Fatalf("expected successful conversion, got %v", out.Issues)
+		}
+	})
+
+	t.Run("aks shaped pools matched by label", func(t *testing.T) {
+		aksPool := func(name, zone string) *model.NodePool {
+			labels := map[string]string{model.NodePoolLabel: "user"}
+			return &model.NodePool{Name: ptr(name), ProviderName: ptr(name), Labels: &labels, Zones: &[]string{zone}, Autoscaling: &model.NodePoolAutoscaling{Enabled: true, Min: 0, Max: 3}}
+		}
+		systemLabels := map[string]string{model.NodePoolLabel: "system"}
+		uc := buildTestUseCase(t, zonalDisks())
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{
+			{Name: ptr("npsystem"), Labels: &systemLabels, Zones: &[]string{"japaneast-1"}},
+			aksPool("npuser2", "japaneast-2"),
+			aksPool("npuser1", "japaneast-1"),
+		}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 0 || len(out.K8sObjects) == 0 {
+			t.Fatalf("expected successful conversion, got %v", out.Issues)
+		}
+
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{aksPool("npuser2", "japaneast-2")}}
+		out, err = uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 1 || !strings.Contains(out.Errors[0], "user (npuser2)") {
+			t.Fatalf("expected zone error naming user (npuser2), got %v", out.Errors)
+		}
+	})
+
+	t.Run("pool outside disk zone", func(t *testing.T) {
+		uc := buildTestUseCase(t, zonalDisks())
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{userPool(3, "japaneast-2")}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 1 || out.Issues[len(out.Issues)-1].Code != "volume_zone_unschedulable" {
+			t.Fatalf("expected volume_zone_unschedulable, got %v", issueCodes(out.Issues))
+		}
+	})
+
+	t.Run("pool without capacity", func(t *testing.T) {
+		uc := buildTestUseCase(t, zonalDisks())
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{userPool(0, "japaneast-1")}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 1 || !strings.Contains(out.Errors[0], "max 0") {
+			t.Fatalf("expected capacity error, got %v", out.Errors)
+		}
+	})
+
+	t.Run("deployment zone mismatch and auto zone", func(t *testing.T) {
+		uc := buildTestUseCase(t, zonalDisks())
+		uc.Repos.App.(*singleAppRepo).item.Deployment.Zone = "japaneast-2"
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{userPool(3, "japaneast-1", "japaneast-2")}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 1 || !strings.Contains(out.Errors[0], "--auto-zone") {
+			t.Fatalf("expected deployment zone error, got %v", out.Errors)
+		}
+
+		out, err = uc.Validate(context.Background(), &ValidateInput{AppID: testAppID, AutoZone: true})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if len(out.Errors) != 0 || out.Converter == nil || out.Converter.K8sDeployment == nil {
+			t.Fatalf("expected successful conversion, got %v", issueCodes(out.Issues))
+		}
+		if got := out.Converter.K8sDeployment.Spec.Template.Spec.NodeSelector["kompox.dev/node-zone"]; got != "japaneast-1" {
+			t.Errorf("expected node zone pinned to japaneast-1, got %q", got)
+		}
+	})
+
+	t.Run("conflicting disk zones", func(t *testing.T) {
+		disks := zonalDisks()
+		disks["logs"] = []*model.VolumeDisk{{Name: "d2", VolumeName: "logs", Assigned: true, Handle: "handle-2", Zone: "2"}}
+		uc := buildTestUseCase(t, disks)
+		app := uc.Repos.App.(*singleAppRepo).item
+		app.Volumes = append(app.Volumes, model.AppVolume{Name: "logs", Size: 1 << 30})
+		app.Compose = composeYAML + "  logs: {}\n"
+		uc.NodePoolPort = &fakeNodePoolPort{pools: []*model.NodePool{userPool(3, "japaneast-1", "japaneast-2")}}
+		out, err := uc.Validate(context.Background(), &ValidateInput{AppID: testAppID, AutoZone: true})
+		if err != nil {
+			t.Fatalf("validate returned error: %v", err)
+		}
+		if !slices.Contains(issueCodes(out.Issues), "volume_zone_conflict") {
+			t.Fatalf("expected volume_zone_conflict, got %v", issueCodes(out.Issues))
+		}
+	})
+}
+
 func buildTestUseCase(t *testing.T, disks map[string][]*model.VolumeDisk) *UseCase {
 	t.Helper()
 	app := &model.App{
@@ -202,6 +425,9 @@ type fakeVolumePort struct {
 	disks map[string][]*model.VolumeDisk
 }
 
+func (f *fakeVolumePort) Capabilities(context.Context, *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{}, nil
+}
 func (f *fakeVolumePort) DiskList(_ context.Context, _ *model.Cluster, _ *model.App, volName string, _ ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
 	return f.disks[volName], nil
 }
@@ -214,6 +440,9 @@ func (f *fakeVolumePort) DiskDelete(context.Context, *model.Cluster, *model.App,
 func (f *fakeVolumePort) DiskAssign(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeDiskAssignOption) error {
 	return errors.New("not implemented")
 }
+func (f *fakeVolumePort) DiskUpdate(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, errors.New("not implemented")
+}
 func (f *fakeVolumePort) SnapshotList(context.Context, *model.Cluster, *model.App, string, ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
 	return nil, errors.New("not implemented")
 }
@@ -223,6 +452,9 @@ func (f *fakeVolumePort) SnapshotCreate(context.Context, *model.Cluster, *model.
 func (f *fakeVolumePort) SnapshotDelete(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeSnapshotDeleteOption) error {
 	return errors.New("not implemented")
 }
+func (f *fakeVolumePort) SnapshotCopy(context.Context, *model.Cluster, *model.App, string, string, *model.VolumeSnapshot, ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	return nil, errors.New("not implemented")
+}
 
 type fakeProviderDriver struct {
 	volumeClass model.VolumeClass
@@ -231,6 +463,12 @@ type fakeProviderDriver struct {
 func (f *fakeProviderDriver) ID() string            { return "fake-app-validate" }
 func (f *fakeProviderDriver) WorkspaceName() string { return "ws1" }
 func (f *fakeProviderDriver) ProviderName() string  { return "prv1" }
+func (f *fakeProviderDriver) Capabilities() model.DriverCapabilities {
+	return model.DriverCapabilities{
+		Driver:  "fake-app-validate",
+		Volumes: map[string]model.VolumeTypeCapabilities{model.VolumeTypeDisk: {AccessModes: []string{"ReadWriteOnce"}}},
+	}
+}
 func (f *fakeProviderDriver) ClusterProvision(context.Context, *model.Cluster, ...model.ClusterProvisionOption) error {
 	return nil
 }
@@ -264,6 +502,9 @@ func (f *fakeProviderDriver) VolumeDiskDelete(context.Context, *model.Cluster, *
 func (f *fakeProviderDriver) VolumeDiskAssign(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeDiskAssignOption) error {
 	return nil
 }
+func (f *fakeProviderDriver) VolumeDiskUpdate(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, nil
+}
 func (f *fakeProviderDriver) VolumeSnapshotList(context.Context, *model.Cluster, *model.App, string, ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
 	return nil, nil
 }
@@ -273,9 +514,24 @@ func (f *fakeProviderDriver) VolumeSnapshotCreate(context.Context, *model.Cluste
 func (f *fakeProviderDriver) VolumeSnapshotDelete(context.Context, *model.Cluster, *model.App, string, string, ...model.VolumeSnapshotDeleteOption) error {
 	return nil
 }
-func (f *fakeProviderDriver) VolumeClass(context.Context, *model.Cluster, *model.App, model.AppVolume) (model.VolumeClass, error) {
+func (f *fakeProviderDriver) VolumeClass(_ context.Context, _ *model.Cluster, _ *model.App, vol model.AppVolume) (model.VolumeClass, error) {
+	if _, ok := vol.Options["invalid"]; ok {
+		return model.VolumeClass{}, fmt.Errorf("%w: unknown option %q", model.ErrVolumeOptionsInvalid, "invalid")
+	}
 	return f.volumeClass, nil
 }
+func (f *fakeProviderDriver) VolumeResourceList(context.Context) ([]*model.VolumeResource, error) {
+	return nil, nil
+}
+func (f *fakeProviderDriver) VolumeResourceMarkOrphaned(context.Context, *model.VolumeResource, time.Time) error {
+	return nil
+}
+func (f *fakeProviderDriver) VolumeResourceUnmarkOrphaned(context.Context, *model.VolumeResource) error {
+	return nil
+}
+func (f *fakeProviderDriver) VolumeResourceDelete(context.Context, *model.VolumeResource) error {
+	return nil
+}
 func (f *fakeProviderDriver) NodePoolList(context.Context, *model.Cluster, ...model.NodePoolListOption) ([]*model.NodePool, error) {
 	return nil, nil
 }
@@ -288,3 +544,37 @@ func (f *fakeProviderDriver) NodePoolUpdate(context.Context, *model.Cluster, mod
 func (f *fakeProviderDriver) NodePoolDelete(context.Context, *model.Cluster, string, ...model.NodePoolDeleteOption) error {
 	return nil
 }
+
+type fakeSecretPort struct {
+	values map[string]string
+	reads  []string
+}
+
+func (f *fakeSecretPort) SecretRead(_ context.Context, _ *model.Cluster, source string) ([]byte, error) {
+	f.reads = append(f.reads, source)
+	v, ok := f.values[source]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", source)
+	}
+	return []byte(v), nil
+}
+
+type fakeNodePoolPort struct {
+	pools []*model.NodePool
+}
+
+func (f *fakeNodePoolPort) Capabilities(context.Context, *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{NodePool: model.NodePoolCapabilities{List: true}}, nil
+}
+func (f *fakeNodePoolPort) NodePoolList(context.Context, *model.Cluster, ...model.NodePoolListOption) ([]*model.NodePool, error) {
+	return f.pools, nil
+}
+func (f *fakeNodePoolPort) NodePoolCreate(context.Context, *model.Cluster, model.NodePool, ...model.NodePoolCreateOption) (*model.NodePool, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeNodePoolPort) NodePoolUpdate(context.Context, *model.Cluster, model.NodePool, ...model.NodePoolUpdateOption) (*model.NodePool, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeNodePoolPort) NodePoolDelete(context.Context, *model.Cluster, string, ...model.NodePoolDeleteOption) error {
+	return errors.New("not implemented")
+}
@@ -2,12 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
 	"github.com/kompox/kompox/adapters/kube"
 	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -103,7 +106,19 @@ func issueMessagesBySeverity(issues []Issue, target Severity) []string {
 	return msgs
 }
 
-func (u *UseCase) validateApp(ctx context.Context, app *model.App) (*validationResult, error) {
+// validateOptions controls validateApp.
+type validateOptions struct {
+	// autoZone pins node scheduling to the zone of the assigned zonal disks instead of the
+	// deployment zone(s).
+	autoZone bool
+	// applyIdentity creates or updates the cloud workload identity of the app (spec.identity)
+	// and binds it to the app ServiceAccount. Without it the identity is only checked, so that
+	// app validate does not mutate the provider.
+	applyIdentity bool
+}
+
+// validateApp validates and converts the app.
+func (u *UseCase) validateApp(ctx context.Context, app *model.App, opts validateOptions) (*validationResult, error) {
 	if app == nil {
 		return nil, fmt.Errorf("app is nil")
 	}
@@ -162,6 +177,25 @@ func (u *UseCase) validateApp(ctx context.Context, app *model.App) (*validationR
 		return res, nil
 	}
 
+	// Reject volumes the driver cannot provide before contacting the provider.
+	caps := drv.Capabilities()
+	unsupported := false
+	for _, av := range app.Volumes {
+		if err := caps.CheckVolume(av); err != nil {
+			res.addIssue(SeverityError, "volume_type_unsupported", err.Error())
+			unsupported = true
+		}
+	}
+	if app.Identity != nil {
+		if err := caps.CheckAppIdentity(); err != nil {
+			res.addIssue(SeverityError, "identity_unsupported", err.Error())
+			unsupported = true
+		}
+	}
+	if unsupported {
+		return res, nil
+	}
+
 	conv := kube.NewConverter(workspace, provider, cluster, app, "app")
 	if conv == nil {
 		res.addIssue(SeverityWarn, "converter_init_failed", "compose conversion failed: converter initialization failed")
@@ -180,10 +214,43 @@ func (u *UseCase) validateApp(ctx context.Context, app *model.App) (*validationR
 		return res, nil
 	}
 
+	zone, zoneIssues := u.validateVolumeZones(ctx, cluster, app, bindings, opts.autoZone)
+	res.Issues = append(res.Issues, zoneIssues...)
+	if hasIssuesAtOrAbove(zoneIssues, SeverityError) {
+		return res, nil
+	}
+	if zone != "" {
+		conv.PinZone(zone)
+	}
+
 	if bindErr := conv.BindVolumes(ctx, bindings); bindErr != nil {
 		res.addIssue(SeverityWarn, "compose_conversion_failed", fmt.Sprintf("compose conversion failed: %v", bindErr))
 		return res, nil
 	}
+
+	if app.Identity != nil {
+		if !opts.applyIdentity {
+			res.addIssue(SeverityInfo, "identity_not_applied", "workload identity is created and bound to the app ServiceAccount at deploy")
+		} else {
+			wi, err := u.applyAppIdentity(ctx, cluster, app, conv)
+			if err != nil {
+				return nil, err
+			}
+			if bindErr := conv.BindWorkloadIdentity(wi); bindErr != nil {
+				res.addIssue(SeverityWarn, "compose_conversion_failed", fmt.Sprintf("compose conversion failed: %v", bindErr))
+				return res, nil
+			}
+		}
+	}
+	auths, registryIssues := u.validateRegistries(ctx, cluster, conv)
+	res.Issues = append(res.Issues, registryIssues...)
+	if hasIssuesAtOrAbove(registryIssues, SeverityError) {
+		return res, nil
+	}
+	if bindErr := conv.BindRegistryCredentials(auths); bindErr != nil {
+		res.addIssue(SeverityWarn, "compose_conversion_failed", fmt.Sprintf("compose conversion failed: %v", bindErr))
+		return res, nil
+	}
 	warns2, buildErr := conv.Build()
 	if buildErr != nil {
 		res.addIssue(SeverityWarn, "compose_conversion_failed", fmt.Sprintf("compose conversion failed: %v", buildErr))
@@ -195,6 +262,59 @@ func (u *UseCase) validateApp(ctx context.Context, app *model.App) (*validationR
 	return res, nil
 }
 
+// applyAppIdentity creates or updates the workload identity of the app and federates it with
+// the app ServiceAccount in the namespace of the converter.
+func (u *UseCase) applyAppIdentity(ctx context.Context, cluster *model.Cluster, app *model.App, conv *kube.Converter) (*model.WorkloadIdentity, error) {
+	if u.IdentityPort == nil {
+		return nil, fmt.Errorf("app %s declares an identity but no identity port is configured", app.Name)
+	}
+	subject := model.WorkloadIdentitySubject{Namespace: conv.Namespace, ServiceAccount: conv.WorkloadServiceAccountName()}
+	logging.FromContext(ctx).Info(ctx, "UC:app.deploy:Identity", "namespace", subject.Namespace, "serviceAccount", subject.ServiceAccount, "roles", len(app.Identity.Roles))
+	wi, err := u.IdentityPort.AppIdentityApply(ctx, cluster, app, subject)
+	if err != nil {
+		return nil, fmt.Errorf("apply workload identity: %w", err)
+	}
+	return wi, nil
+}
+
+// validateRegistries matches the images of the converted app against the cluster registries
+// and reads the credentials of the registries in use. Registries no image is pulled from are
+// not distributed to the app.
+func (u *UseCase) validateRegistries(ctx context.Context, cluster *model.Cluster, conv *kube.Converter) ([]kube.RegistryAuth, []Issue) {
+	if len(cluster.Registries) == 0 {
+		return nil, nil
+	}
+	images, err := conv.ImageRegistries()
+	if err != nil {
+		return nil, []Issue{{Severity: SeverityError, Code: "image_reference_invalid", Message: err.Error()}}
+	}
+	var auths []kube.RegistryAuth
+	var issues []Issue
+	for _, reg := range cluster.Registries {
+		server := kube.NormalizeRegistryServer(reg.Server)
+		used := images[server]
+		if len(used) == 0 {
+			continue
+		}
+		if u.SecretPort == nil {
+			issues = append(issues, Issue{Severity: SeverityError, Code: "registry_credentials_unavailable", Message: fmt.Sprintf("credentials of registry %s unavailable: secret operations unavailable", server)})
+			continue
+		}
+		password, err := u.SecretPort.SecretRead(ctx, cluster, reg.PasswordSource)
+		if err != nil {
+			issues = append(issues, Issue{Severity: SeverityError, Code: "registry_credentials_unavailable", Message: fmt.Sprintf("credentials of registry %s for images %s unavailable: %v", server, strings.Join(used, ", "), err)})
+			continue
+		}
+		pw := strings.TrimRight(string(password), "\r\n")
+		if pw == "" {
+			issues = append(issues, Issue{Severity: SeverityError, Code: "registry_credentials_empty", Message: fmt.Sprintf("password of registry %s read from %s is empty", server, reg.PasswordSource)})
+			continue
+		}
+		auths = append(auths, kube.RegistryAuth{Server: server, Username: reg.Username, Password: pw})
+	}
+	return auths, issues
+}
+
 func (u *UseCase) validateAppVolumes(ctx context.Context, cluster *model.Cluster, app *model.App, drv providerdrv.Driver) ([]*kube.ConverterVolumeBinding, []Issue, bool) {
 	if len(app.Volumes) == 0 {
 		return nil, nil, true
@@ -208,6 +328,17 @@ func (u *UseCase) validateAppVolumes(ctx context.Context, cluster *model.Cluster
 			issues = append(issues, Issue{Severity: SeverityError, Code: "volume_port_unavailable", Message: "volume operations unavailable"})
 			continue
 		}
+		// Resolve the volume class first so that invalid volume options are reported
+		// even when no disk has been created yet.
+		var vc model.VolumeClass
+		var vcErr error
+		if drv != nil {
+			vc, vcErr = drv.VolumeClass(ctx, cluster, app, av)
+			if errors.Is(vcErr, model.ErrVolumeOptionsInvalid) {
+				issues = append(issues, Issue{Severity: SeverityError, Code: "volume_options_invalid", Message: fmt.Sprintf("volume options invalid for %s: %v", av.Name, vcErr)})
+				continue
+			}
+		}
 		disks, err := u.VolumePort.DiskList(ctx, cluster, app, av.Name)
 		if err != nil {
 			issues = append(issues, Issue{Severity: SeverityError, Code: "volume_disk_lookup_failed", Message: fmt.Sprintf("volume disk lookup failed for %s: %v", av.Name, err)})
@@ -238,13 +369,12 @@ func (u *UseCase) validateAppVolumes(ctx context.Context, cluster *model.Cluster
 			issues = append(issues, Issue{Severity: SeverityWarn, Code: "volume_class_driver_missing", Message: "compose conversion failed: provider driver unavailable for volume class resolution"})
 			continue
 		}
-		vc, err := drv.VolumeClass(ctx, cluster, app, av)
-		if err != nil {
-			issues = append(issues, Issue{Severity: SeverityWarn, Code: "volume_class_resolve_failed", Message: fmt.Sprintf("compose conversion failed: volume class resolve failed for %s: %v", av.Name, err)})
+		if vcErr != nil {
+			issues = append(issues, Issue{Severity: SeverityWarn, Code: "volume_class_resolve_failed", Message: fmt.Sprintf("compose conversion failed: volume class resolve failed for %s: %v", av.Name, vcErr)})
 			continue
 		}
 		class := vc
-		if strings.TrimSpace(class.CSIDriver) == "" {
+		if !class.NodeLocal && strings.TrimSpace(class.CSIDriver) == "" {
 			issues = append(issues, Issue{Severity: SeverityWarn, Code: "volume_class_missing_driver", Message: fmt.Sprintf("compose conversion failed: volume class missing CSI driver for %s", av.Name)})
 			continue
 		}
@@ -260,3 +390,142 @@ func (u *UseCase) validateAppVolumes(ctx context.Context, cluster *model.Cluster
 	}
 	return bindings, issues, true
 }
+
+// validateVolumeZones cross-checks the zones of the assigned disks with the node pools the
+// app is scheduled to. A zonal disk can only be attached to nodes in its zone, so the app
+// is unschedulable unless the deployment zone(s), if any, include the disk zone and one of
+// the target pools (AppDeployment.TargetPools) spans the disk zone and can run nodes. With
+// autoZone the deployment zone(s) are not checked; the returned zone, taken from the
+// matching pool, is used to pin node scheduling.
+func (u *UseCase) validateVolumeZones(ctx context.Context, cluster *model.Cluster, app *model.App, bindings []*kube.ConverterVolumeBinding, autoZone bool) (string, []Issue) {
+	var zonal *kube.ConverterVolumeBinding
+	for _, b := range bindings {
+		if b == nil || b.VolumeDisk == nil || b.VolumeDisk.Zone == "" || (b.VolumeClass != nil && b.VolumeClass.NodeLocal) {
+			continue
+		}
+		if zonal == nil {
+			zonal = b
+			continue
+		}
+		if !sameZone(zonal.VolumeDisk.Zone, b.VolumeDisk.Zone) {
+			return "", []Issue{{Severity: SeverityError, Code: "volume_zone_conflict", Message: fmt.Sprintf("volume %s (disk %s, zone %s) and volume %s (disk %s, zone %s) are in different zones and cannot be attached to the same pod", zonal.Name, zonal.VolumeDisk.Name, zonal.VolumeDisk.Zone, b.Name, b.VolumeDisk.Name, b.VolumeDisk.Zone)}}
+		}
+	}
+	if zonal == nil {
+		return "", nil
+	}
+	zone := zonal.VolumeDisk.Zone
+	subject := fmt.Sprintf("volume %s (disk %s, zone %s)", zonal.Name, zonal.VolumeDisk.Name, zone)
+
+	if !autoZone {
+		zones := app.Deployment.Zones
+		if len(zones) == 0 && app.Deployment.Zone != "" {
+			zones = []string{app.Deployment.Zone}
+		}
+		if len(zones) > 0 && !slices.ContainsFunc(zones, func(z string) bool { return sameZone(z, zone) }) {
+			return "", []Issue{{Severity: SeverityError, Code: "volume_zone_unschedulable", Message: fmt.Sprintf("%s is outside the deployment zones %v (use --auto-zone to pin the app to the disk zone)", subject, zones)}}
+		}
+	}
+
+	skipped := func(reason string) (string, []Issue) {
+		issues := []Issue{{Severity: SeverityInfo, Code: "volume_zone_check_skipped", Message: fmt.Sprintf("node pool zone check skipped for %s: %s", subject, reason)}}
+		if autoZone {
+			return zone, issues
+		}
+		return "", issues
+	}
+	if u.NodePoolPort == nil {
+		return skipped("node pool operations unavailable")
+	}
+	caps, err := u.NodePoolPort.Capabilities(ctx, cluster)
+	if err != nil {
+		return skipped(err.Error())
+	}
+	if err := caps.CheckNodePool(model.NodePoolOpList); err != nil {
+		return skipped(err.Error())
+	}
+	pools, err := u.NodePoolPort.NodePoolList(ctx, cluster)
+	if err != nil {
+		return "", []Issue{{Severity: SeverityError, Code: "volume_zone_check_failed", Message: fmt.Sprintf("node pool lookup failed for %s: %v", subject, err)}}
+	}
+
+	targets := app.Deployment.TargetPools()
+	var reasons []string
+	for _, p := range pools {
+		if p == nil || !slices.Contains(targets, p.LogicalName()) {
+			continue
+		}
+		poolZone, reason := nodePoolAccepts(p, zone)
+		if reason != "" {
+			reasons = append(reasons, reason)
+			continue
+		}
+		if autoZone {
+			return poolZone, []Issue{{Severity: SeverityInfo, Code: "volume_zone_pinned", Message: fmt.Sprintf("node scheduling pinned to zone %s of %s", poolZone, subject)}}
+		}
+		return "", nil
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, fmt.Sprintf("pools %v not found", targets))
+	}
+	return "", []Issue{{Severity: SeverityError, Code: "volume_zone_unschedulable", Message: fmt.Sprintf("no node pool can run %s: %s", subject, strings.Join(reasons, "; "))}}
+}
+
+// nodePoolAccepts checks that pool p can run nodes and, for a non-empty zone, spans the zone.
+// It returns the matching zone value of the pool, or a reason why the pool is not eligible.
+func nodePoolAccepts(p *model.NodePool, zone string) (poolZone, reason string) {
+	if n, known := nodePoolMaxNodes(p); known && n == 0 {
+		return "", fmt.Sprintf("pool %s cannot run nodes (max 0)", poolDisplayName(p))
+	}
+	if zone == "" {
+		return "", ""
+	}
+	var zones []string
+	if p.Zones != nil {
+		zones = *p.Zones
+	}
+	i := slices.IndexFunc(zones, func(z string) bool { return sameZone(z, zone) })
+	if i < 0 {
+		return "", fmt.Sprintf("pool %s spans zones %v", poolDisplayName(p), zones)
+	}
+	return zones[i], ""
+}
+
+// poolDisplayName returns the logical name of p, followed by the provider pool name when
+// they differ (e.g., "user (npuser1)").
+func poolDisplayName(p *model.NodePool) string {
+	name := p.LogicalName()
+	if p.Name != nil && *p.Name != name {
+		return fmt.Sprintf("%s (%s)", name, *p.Name)
+	}
+	return name
+}
+
+// nodePoolMaxNodes returns the maximum node count of a pool: the autoscaling max, or the
+// fixed node count. known is false when the driver reports neither.
+func nodePoolMaxNodes(p *model.NodePool) (n int, known bool) {
+	if as := p.Autoscaling; as != nil {
+		if as.Enabled {
+			return as.Max, true
+		}
+		if as.Desired != nil {
+			return *as.Desired, true
+		}
+	}
+	if p.Status != nil && p.Status.CurrentNodeCount != nil {
+		return *p.Status.CurrentNodeCount, true
+	}
+	return 0, false
+}
+
+// sameZone reports whether two zone values denote the same zone. Drivers may report zones
+// in the provider format ("1") or in the Kompox format with a region prefix ("japaneast-1").
+func sameZone(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	return !strings.Contains(b, "-") && strings.HasSuffix(a, "-"+b)
+}
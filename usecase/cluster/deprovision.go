@@ -10,8 +10,12 @@ import (
 type DeprovisionInput struct {
 	ClusterID string `json:"cluster_id"`
 	Force     bool   `json:"force,omitempty"`
+	Plan      bool   `json:"plan,omitempty"` // report planned changes without applying them
+}
+type DeprovisionOutput struct {
+	// Plan holds the planned changes when Input.Plan is set.
+	Plan *model.ClusterPlan `json:"plan,omitempty"`
 }
-type DeprovisionOutput struct{}
 
 // Deprovision deprovisions a cluster.
 func (u *UseCase) Deprovision(ctx context.Context, in *DeprovisionInput) (*DeprovisionOutput, error) {
@@ -23,11 +27,27 @@ func (u *UseCase) Deprovision(ctx context.Context, in *DeprovisionInput) (*Depro
 		return nil, err
 	}
 
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckClusterDeprovision(); err != nil {
+		return nil, err
+	}
+
 	// Check protection policy (ignore Force flag as per ADR-013)
 	if err := c.CheckProvisioningProtection(model.OpDelete); err != nil {
 		return nil, err
 	}
 
+	if in.Plan {
+		plan, err := u.plan(ctx, c, caps, model.ClusterPlanDeprovision, in.Force)
+		if err != nil {
+			return nil, err
+		}
+		return &DeprovisionOutput{Plan: plan}, nil
+	}
+
 	var opts []model.ClusterDeprovisionOption
 	if in.Force {
 		opts = append(opts, model.WithClusterDeprovisionForce())
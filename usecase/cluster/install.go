@@ -10,8 +10,12 @@ import (
 type InstallInput struct {
 	ClusterID string `json:"cluster_id"`
 	Force     bool   `json:"force,omitempty"`
+	Plan      bool   `json:"plan,omitempty"` // report planned changes without applying them
+}
+type InstallOutput struct {
+	// Plan holds the planned changes when Input.Plan is set.
+	Plan *model.ClusterPlan `json:"plan,omitempty"`
 }
-type InstallOutput struct{}
 
 // Install installs in-cluster resources (Ingress Controller, etc.).
 func (u *UseCase) Install(ctx context.Context, in *InstallInput) (*InstallOutput, error) {
@@ -23,6 +27,14 @@ func (u *UseCase) Install(ctx context.Context, in *InstallInput) (*InstallOutput
 		return nil, err
 	}
 
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckClusterInstall(); err != nil {
+		return nil, err
+	}
+
 	// Check protection policy
 	// First-time install is detected by checking if installation is not yet done
 	status, statusErr := u.ClusterPort.Status(ctx, c)
@@ -36,6 +48,14 @@ func (u *UseCase) Install(ctx context.Context, in *InstallInput) (*InstallOutput
 		return nil, err
 	}
 
+	if in.Plan {
+		plan, err := u.plan(ctx, c, caps, model.ClusterPlanInstall, in.Force)
+		if err != nil {
+			return nil, err
+		}
+		return &InstallOutput{Plan: plan}, nil
+	}
+
 	var opts []model.ClusterInstallOption
 	if in.Force {
 		opts = append(opts, model.WithClusterInstallForce())
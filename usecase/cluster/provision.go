@@ -10,8 +10,12 @@ import (
 type ProvisionInput struct {
 	ClusterID string `json:"cluster_id"`
 	Force     bool   `json:"force,omitempty"`
+	Plan      bool   `json:"plan,omitempty"` // report planned changes without applying them
+}
+type ProvisionOutput struct {
+	// Plan holds the planned changes when Input.Plan is set.
+	Plan *model.ClusterPlan `json:"plan,omitempty"`
 }
-type ProvisionOutput struct{}
 
 // Provision provisions a cluster.
 func (u *UseCase) Provision(ctx context.Context, in *ProvisionInput) (*ProvisionOutput, error) {
@@ -23,6 +27,14 @@ func (u *UseCase) Provision(ctx context.Context, in *ProvisionInput) (*Provision
 		return nil, err
 	}
 
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckClusterProvision(c); err != nil {
+		return nil, err
+	}
+
 	// Check protection policy
 	// First-time provision is detected by checking if provisioning is not yet done
 	status, statusErr := u.ClusterPort.Status(ctx, c)
@@ -36,6 +48,14 @@ func (u *UseCase) Provision(ctx context.Context, in *ProvisionInput) (*Provision
 		return nil, err
 	}
 
+	if in.Plan {
+		plan, err := u.plan(ctx, c, caps, model.ClusterPlanProvision, in.Force)
+		if err != nil {
+			return nil, err
+		}
+		return &ProvisionOutput{Plan: plan}, nil
+	}
+
 	var opts []model.ClusterProvisionOption
 	if in.Force {
 		opts = append(opts, model.WithClusterProvisionForce())
@@ -17,6 +17,8 @@ type StatusOutput struct {
 	model.ClusterStatus
 	ClusterID   string `json:"cluster_id"`
 	ClusterName string `json:"cluster_name"`
+	// Capabilities is the capability matrix of the cluster's provider driver.
+	Capabilities *model.DriverCapabilities `json:"capabilities,omitempty"`
 }
 
 // Status returns the status of a cluster.
@@ -37,9 +39,15 @@ func (u *UseCase) Status(ctx context.Context, in *StatusInput) (*StatusOutput, e
 		return nil, err
 	}
 
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
 	return &StatusOutput{
 		ClusterStatus: *cStatus,
 		ClusterID:     c.ID,
 		ClusterName:   c.Name,
+		Capabilities:  caps,
 	}, nil
 }
@@ -1,6 +1,9 @@
 package cluster
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/kompox/kompox/domain"
 	"github.com/kompox/kompox/domain/model"
 )
@@ -17,3 +20,29 @@ type UseCase struct {
 	Repos       *Repos
 	ClusterPort model.ClusterPort
 }
+
+// capabilities returns the capabilities of the driver managing the cluster.
+func (u *UseCase) capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	caps, err := u.ClusterPort.Capabilities(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+	return caps, nil
+}
+
+// plan returns the changes op would make on the cluster. It is used by the lifecycle usecases
+// in plan mode after their own capability and protection checks.
+func (u *UseCase) plan(ctx context.Context, cluster *model.Cluster, caps *model.DriverCapabilities, op model.ClusterPlanOperation, force bool) (*model.ClusterPlan, error) {
+	if err := caps.CheckClusterPlan(); err != nil {
+		return nil, err
+	}
+	var opts []model.ClusterPlanOption
+	if force {
+		opts = append(opts, model.WithClusterPlanForce())
+	}
+	plan, err := u.ClusterPort.Plan(ctx, cluster, op, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("plan cluster %s: %w", op, err)
+	}
+	return plan, nil
+}
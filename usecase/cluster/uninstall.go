@@ -10,8 +10,12 @@ import (
 type UninstallInput struct {
 	ClusterID string `json:"cluster_id"`
 	Force     bool   `json:"force,omitempty"`
+	Plan      bool   `json:"plan,omitempty"` // report planned changes without applying them
+}
+type UninstallOutput struct {
+	// Plan holds the planned changes when Input.Plan is set.
+	Plan *model.ClusterPlan `json:"plan,omitempty"`
 }
-type UninstallOutput struct{}
 
 // Uninstall uninstalls in-cluster resources (Ingress Controller, etc.).
 func (u *UseCase) Uninstall(ctx context.Context, in *UninstallInput) (*UninstallOutput, error) {
@@ -23,11 +27,27 @@ func (u *UseCase) Uninstall(ctx context.Context, in *UninstallInput) (*Uninstall
 		return nil, err
 	}
 
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckClusterInstall(); err != nil {
+		return nil, err
+	}
+
 	// Check protection policy (ignore Force flag as per ADR-013)
 	if err := c.CheckInstallationProtection(model.OpDelete); err != nil {
 		return nil, err
 	}
 
+	if in.Plan {
+		plan, err := u.plan(ctx, c, caps, model.ClusterPlanUninstall, in.Force)
+		if err != nil {
+			return nil, err
+		}
+		return &UninstallOutput{Plan: plan}, nil
+	}
+
 	var opts []model.ClusterUninstallOption
 	if in.Force {
 		opts = append(opts, model.WithClusterUninstallForce())
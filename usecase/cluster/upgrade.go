@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	providerdrv "github.com/kompox/kompox/adapters/drivers/provider"
+	"github.com/kompox/kompox/adapters/kube"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/logging"
+)
+
+// defaultUpgradeMoveTimeout bounds the wait for a moved app to become available again.
+const defaultUpgradeMoveTimeout = 10 * time.Minute
+
+// UpgradeInput represents a command to upgrade the Kubernetes version and/or the node images of a cluster.
+type UpgradeInput struct {
+	ClusterID string `json:"cluster_id"`
+	// KubernetesVersion is the target version of the control plane and the node pools.
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	// NodeImage upgrades the node pools to the latest node image.
+	NodeImage bool `json:"node_image,omitempty"`
+	// Pools limits the node pools to upgrade; empty upgrades all pools.
+	Pools []string `json:"pools,omitempty"`
+	// Plan reports the upgrade steps and the expected app downtime without upgrading.
+	Plan bool `json:"plan,omitempty"`
+	// MoveTimeout bounds the wait for each moved app to become available (default 10m).
+	MoveTimeout time.Duration `json:"move_timeout,omitempty"`
+}
+
+// UpgradeOutput reports the upgrade steps in the order they are (or would be) applied.
+type UpgradeOutput struct {
+	Cluster string `json:"cluster"`
+	Plan    bool   `json:"plan"`
+	// KubernetesVersion is the control plane version before the upgrade.
+	KubernetesVersion string `json:"kubernetes_version"`
+	// TargetVersion is the requested Kubernetes version.
+	TargetVersion string `json:"target_version,omitempty"`
+	// Available lists the control plane versions the cluster can be upgraded to.
+	Available []string `json:"available,omitempty"`
+	// ControlPlane reports whether the control plane is upgraded to TargetVersion.
+	ControlPlane bool                  `json:"control_plane"`
+	NodePools    []NodePoolUpgradeStep `json:"node_pools"`
+}
+
+// NodePoolUpgradeStep is the upgrade of one node pool.
+type NodePoolUpgradeStep struct {
+	Name          string `json:"name"`
+	FromVersion   string `json:"from_version"`
+	ToVersion     string `json:"to_version,omitempty"`
+	FromNodeImage string `json:"from_node_image,omitempty"`
+	ToNodeImage   string `json:"to_node_image,omitempty"`
+	// NodeSelector are the node labels selecting the nodes of the pool.
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	// Apps are the Kompox apps running on the pool with their expected downtime.
+	Apps []kube.PoolWorkload `json:"apps,omitempty"`
+	// Moved lists the apps (namespace/deployment) restarted onto other nodes before draining.
+	Moved []string `json:"moved,omitempty"`
+	// Skipped explains why the pool is not upgraded.
+	Skipped  string `json:"skipped,omitempty"`
+	Upgraded bool   `json:"upgraded"`
+}
+
+// Upgrade upgrades the control plane to KubernetesVersion and then the node pools one at a time,
+// system pools first. For each pool the Kompox apps on its nodes are listed with their expected
+// downtime. Before a pool is upgraded its nodes are cordoned and the apps that would be
+// disrupted (single replica, Recreate or RWO disks) are restarted one by one so that they move
+// to other nodes in a controlled way instead of being evicted by the drain. Apps that cannot
+// become available elsewhere within MoveTimeout start on the upgraded nodes.
+func (u *UseCase) Upgrade(ctx context.Context, in *UpgradeInput) (*UpgradeOutput, error) {
+	if in == nil || in.ClusterID == "" {
+		return nil, model.ErrClusterInvalid
+	}
+	if in.KubernetesVersion == "" && !in.NodeImage {
+		return nil, fmt.Errorf("nothing to upgrade: specify a kubernetes version and/or node image")
+	}
+	logger := logging.FromContext(ctx)
+	msgSym := "UC:cluster.upgrade"
+
+	c, err := u.Repos.Cluster.Get(ctx, in.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := u.capabilities(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckClusterUpgrade(); err != nil {
+		return nil, err
+	}
+	if err := c.CheckProvisioningProtection(model.OpUpdate); err != nil {
+		return nil, err
+	}
+
+	versions, err := u.ClusterPort.Versions(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("get cluster versions: %w", err)
+	}
+	out := &UpgradeOutput{
+		Cluster:           c.Name,
+		Plan:              in.Plan,
+		KubernetesVersion: versions.KubernetesVersion,
+		TargetVersion:     in.KubernetesVersion,
+		Available:         versions.Upgrades,
+	}
+	if in.KubernetesVersion != "" && in.KubernetesVersion != versions.KubernetesVersion {
+		if !slices.Contains(versions.Upgrades, in.KubernetesVersion) {
+			return nil, fmt.Errorf("cannot upgrade cluster %s from %s to %s (available: %s)", c.Name, versions.KubernetesVersion, in.KubernetesVersion, strings.Join(versions.Upgrades, ", "))
+		}
+		out.ControlPlane = true
+	}
+	for _, name := range in.Pools {
+		if !slices.ContainsFunc(versions.NodePools, func(p model.NodePoolVersions) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("node pool %s not found in cluster %s", name, c.Name)
+		}
+	}
+
+	kcli, err := u.kubeClient(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range upgradeOrder(versions.NodePools) {
+		if len(in.Pools) > 0 && !slices.Contains(in.Pools, pv.Name) {
+			continue
+		}
+		step := NodePoolUpgradeStep{Name: pv.Name, FromVersion: pv.KubernetesVersion, FromNodeImage: pv.NodeImageVersion, NodeSelector: poolNodeSelector(pv)}
+		if in.KubernetesVersion != "" && pv.KubernetesVersion != in.KubernetesVersion {
+			step.ToVersion = in.KubernetesVersion
+		}
+		if in.NodeImage && pv.NodeImageUpgradable() {
+			step.ToNodeImage = pv.LatestNodeImageVersion
+		}
+		if step.ToVersion == "" && step.ToNodeImage == "" {
+			step.Skipped = "up to date"
+		} else if step.Apps, err = kcli.NodePoolWorkloads(ctx, step.NodeSelector); err != nil {
+			return nil, err
+		}
+		out.NodePools = append(out.NodePools, step)
+	}
+	if in.Plan {
+		return out, nil
+	}
+
+	if out.ControlPlane {
+		logger.Info(ctx, msgSym+":ControlPlane", "from", versions.KubernetesVersion, "to", in.KubernetesVersion)
+		if err := u.ClusterPort.UpgradeControlPlane(ctx, c, in.KubernetesVersion); err != nil {
+			return out, fmt.Errorf("upgrade control plane: %w", err)
+		}
+	}
+	timeout := in.MoveTimeout
+	if timeout <= 0 {
+		timeout = defaultUpgradeMoveTimeout
+	}
+	for i := range out.NodePools {
+		step := &out.NodePools[i]
+		if step.Skipped != "" {
+			continue
+		}
+		if err := u.upgradeNodePool(ctx, c, kcli, step, timeout); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// upgradeNodePool moves the disrupted apps off the pool and upgrades it. The pool nodes are
+// cordoned while the apps move and uncordoned afterwards, also when the upgrade fails.
+func (u *UseCase) upgradeNodePool(ctx context.Context, c *model.Cluster, kcli *kube.Client, step *NodePoolUpgradeStep, timeout time.Duration) error {
+	logger := logging.FromContext(ctx)
+	msgSym := "UC:cluster.upgrade"
+
+	var disrupted []kube.PoolWorkload
+	for _, w := range step.Apps {
+		if w.Disruptive() {
+			disrupted = append(disrupted, w)
+		}
+	}
+	if len(disrupted) > 0 {
+		if _, err := kcli.CordonNodePool(ctx, step.NodeSelector, true); err != nil {
+			return fmt.Errorf("cordon node pool %s: %w", step.Name, err)
+		}
+		defer func() {
+			if _, err := kcli.CordonNodePool(ctx, step.NodeSelector, false); err != nil {
+				logger.Warn(ctx, msgSym+":UncordonFailed", "pool", step.Name, "err", err)
+			}
+		}()
+	}
+	for _, w := range disrupted {
+		name := w.Namespace + "/" + w.Deployment
+		logger.Info(ctx, msgSym+":MoveApp", "pool", step.Name, "deployment", name, "expectedDowntime", w.ExpectedDowntime, "reason", w.Reason)
+		if err := kcli.RestartDeployment(ctx, w.Namespace, w.Deployment, time.Now()); err != nil {
+			return fmt.Errorf("move app %s: %w", name, err)
+		}
+		step.Moved = append(step.Moved, name)
+		if err := kcli.WaitDeploymentAvailable(ctx, w.Namespace, w.Deployment, timeout); err != nil {
+			logger.Warn(ctx, msgSym+":MoveAppPending", "deployment", name, "err", err)
+		}
+	}
+
+	var opts []model.NodePoolUpgradeOption
+	if step.ToVersion != "" {
+		opts = append(opts, model.WithNodePoolUpgradeKubernetesVersion(step.ToVersion))
+	}
+	if step.ToNodeImage != "" {
+		opts = append(opts, model.WithNodePoolUpgradeNodeImage())
+	}
+	logger.Info(ctx, msgSym+":NodePool", "pool", step.Name, "toVersion", step.ToVersion, "toNodeImage", step.ToNodeImage)
+	if err := u.ClusterPort.UpgradeNodePool(ctx, c, step.Name, opts...); err != nil {
+		return fmt.Errorf("upgrade node pool %s: %w", step.Name, err)
+	}
+	step.Upgraded = true
+	return nil
+}
+
+// poolNodeSelector returns the node labels selecting the nodes of the pool: the selector given
+// by the driver, or kompox.dev/node-pool=<name> for drivers whose pool names are the logical names.
+func poolNodeSelector(pv model.NodePoolVersions) map[string]string {
+	if len(pv.NodeSelector) > 0 {
+		return pv.NodeSelector
+	}
+	return map[string]string{model.NodePoolLabel: pv.Name}
+}
+
+// upgradeOrder returns the node pools with system pools first, keeping the driver order otherwise.
+func upgradeOrder(pools []model.NodePoolVersions) []model.NodePoolVersions {
+	out := slices.Clone(pools)
+	slices.SortStableFunc(out, func(a, b model.NodePoolVersions) int {
+		return cmp.Compare(boolRank(a.Mode != "system"), boolRank(b.Mode != "system"))
+	})
+	return out
+}
+
+// boolRank maps false to 0 and true to 1 for ordering.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// kubeClient returns a Kubernetes client for the cluster using the driver kubeconfig.
+func (u *UseCase) kubeClient(ctx context.Context, c *model.Cluster) (*kube.Client, error) {
+	providerObj, err := u.Repos.Provider.Get(ctx, c.ProviderID)
+	if err != nil || providerObj == nil {
+		return nil, fmt.Errorf("failed to get provider %s: %w", c.ProviderID, err)
+	}
+	var workspaceObj *model.Workspace
+	if providerObj.WorkspaceID != "" {
+		workspaceObj, _ = u.Repos.Workspace.Get(ctx, providerObj.WorkspaceID)
+	}
+	factory, ok := providerdrv.GetDriverFactory(providerObj.Driver)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider driver: %s", providerObj.Driver)
+	}
+	drv, err := factory(workspaceObj, providerObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver %s: %w", providerObj.Driver, err)
+	}
+	kubeconfig, err := drv.ClusterKubeconfig(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster kubeconfig: %w", err)
+	}
+	kcli, err := kube.NewClientFromKubeconfig(ctx, kubeconfig, &kube.Options{UserAgent: "kompoxops"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %w", err)
+	}
+	return kcli, nil
+}
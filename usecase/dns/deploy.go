@@ -15,6 +15,8 @@ type DeployInput struct {
 	ComponentName string `json:"component_name,omitempty"`
 	Strict        bool   `json:"strict,omitempty"`
 	DryRun        bool   `json:"dry_run,omitempty"`
+	// ClusterID overrides the app cluster the records point to (e.g. the standby cluster after a failover).
+	ClusterID string `json:"cluster_id,omitempty"`
 }
 
 // DeployOutput holds the result of DNS record deployment.
@@ -47,6 +49,11 @@ func (u *UseCase) Deploy(ctx context.Context, in *DeployInput) (*DeployOutput, e
 	if err != nil {
 		return nil, fmt.Errorf("get app: %w", err)
 	}
+	if in.ClusterID != "" && in.ClusterID != app.ClusterID {
+		moved := *app
+		moved.ClusterID = in.ClusterID
+		app = &moved
+	}
 	cluster, err := u.Repos.Cluster.Get(ctx, app.ClusterID)
 	if err != nil {
 		return nil, fmt.Errorf("get cluster: %w", err)
@@ -62,6 +69,11 @@ func (u *UseCase) Deploy(ctx context.Context, in *DeployInput) (*DeployOutput, e
 		workspace, _ = u.Repos.Workspace.Get(ctx, provider.WorkspaceID)
 	}
 
+	router, err := u.newDNSRouter(ctx, cluster, workspace)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create kube.Converter to get namespace and selector
 	c := kube.NewConverter(workspace, provider, cluster, app, in.ComponentName)
 
@@ -107,6 +119,13 @@ func (u *UseCase) Deploy(ctx context.Context, in *DeployInput) (*DeployOutput, e
 	// Apply DNS records for each ingress host
 	var results []DNSRecordResult
 	for _, host := range ingressHosts {
+		if reason := router.unroutable(host.Host); reason != "" {
+			if in.Strict {
+				return nil, fmt.Errorf("%s: %w", reason, model.ErrNotSupported)
+			}
+			results = append(results, DNSRecordResult{FQDN: host.Host, Action: "skipped", Message: reason})
+			continue
+		}
 		// Use IP from IngressHost if available, otherwise skip
 		if host.IP == "" {
 			// No IP available yet for this host
@@ -129,7 +148,7 @@ func (u *UseCase) Deploy(ctx context.Context, in *DeployInput) (*DeployOutput, e
 			RData: []string{host.IP},
 		}
 
-		err := u.ClusterPort.DNSApply(ctx, cluster, rset, opts...)
+		err := router.apply(ctx, rset, opts...)
 		result := DNSRecordResult{
 			FQDN: host.Host,
 			Type: rset.Type,
@@ -54,6 +54,11 @@ func (u *UseCase) Destroy(ctx context.Context, in *DestroyInput) (*DestroyOutput
 		workspace, _ = u.Repos.Workspace.Get(ctx, provider.WorkspaceID)
 	}
 
+	router, err := u.newDNSRouter(ctx, cluster, workspace)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create kube.Converter to get namespace and selector
 	c := kube.NewConverter(workspace, provider, cluster, app, in.ComponentName)
 
@@ -106,6 +111,13 @@ func (u *UseCase) Destroy(ctx context.Context, in *DestroyInput) (*DestroyOutput
 	}
 
 	for _, host := range ingressHosts {
+		if reason := router.unroutable(host.Host); reason != "" {
+			if in.Strict {
+				return nil, fmt.Errorf("%s: %w", reason, model.ErrNotSupported)
+			}
+			results = append(results, DNSRecordResult{FQDN: host.Host, Action: "skipped", Message: reason})
+			continue
+		}
 		for _, recordType := range recordTypes {
 			// Build deletion record set (empty RData)
 			rset := model.DNSRecordSet{
@@ -115,7 +127,7 @@ func (u *UseCase) Destroy(ctx context.Context, in *DestroyInput) (*DestroyOutput
 				RData: nil,
 			}
 
-			err := u.ClusterPort.DNSApply(ctx, cluster, rset, opts...)
+			err := router.apply(ctx, rset, opts...)
 			result := DNSRecordResult{
 				FQDN: host.Host,
 				Type: recordType,
@@ -1,6 +1,9 @@
 package dns
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/kompox/kompox/domain"
 	"github.com/kompox/kompox/domain/model"
 )
@@ -17,4 +20,60 @@ type Repos struct {
 type UseCase struct {
 	Repos       *Repos
 	ClusterPort model.ClusterPort
+	// DNSPort writes record sets through the standalone DNS provider configured on the
+	// cluster or workspace. When nil, all record sets are written by the cluster driver.
+	DNSPort model.DNSPort
+}
+
+// dnsRouter routes record set writes of a cluster. FQDNs in the zones of the standalone DNS
+// provider (Cluster.DNS, else Workspace.DNS) go to the DNS port; others go to the cluster driver.
+type dnsRouter struct {
+	u         *UseCase
+	cluster   *model.Cluster
+	dns       *model.DNSProvider // nil when no standalone DNS provider is configured
+	driver    string
+	driverDNS bool // the cluster driver writes DNS records
+}
+
+// newDNSRouter resolves the standalone DNS provider and the cluster driver capabilities.
+func (u *UseCase) newDNSRouter(ctx context.Context, cluster *model.Cluster, workspace *model.Workspace) (*dnsRouter, error) {
+	caps, err := u.ClusterPort.Capabilities(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+	r := &dnsRouter{u: u, cluster: cluster, driver: caps.Driver, driverDNS: caps.DNS}
+	if u.DNSPort != nil {
+		r.dns = cluster.DNS
+		if r.dns == nil && workspace != nil {
+			r.dns = workspace.DNS
+		}
+	}
+	return r, nil
+}
+
+// unroutable returns the reason why no DNS provider writes records for fqdn, or "" if one does.
+func (r *dnsRouter) unroutable(fqdn string) string {
+	if r.dns != nil {
+		if _, ok := r.dns.Zone(fqdn); ok {
+			return ""
+		}
+	}
+	if r.driverDNS {
+		return ""
+	}
+	if r.dns != nil {
+		return fmt.Sprintf("%s is outside the zones of dns provider %s and driver %s does not manage DNS records", fqdn, r.dns.Driver, r.driver)
+	}
+	return fmt.Sprintf("driver %s does not manage DNS records", r.driver)
+}
+
+// apply writes rset through the standalone DNS provider when its zones contain the FQDN,
+// otherwise through the cluster driver.
+func (r *dnsRouter) apply(ctx context.Context, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	if r.dns != nil {
+		if zone, ok := r.dns.Zone(rset.FQDN); ok {
+			return r.u.DNSPort.DNSApply(ctx, r.dns, zone, rset, opts...)
+		}
+	}
+	return r.u.ClusterPort.DNSApply(ctx, r.cluster, rset, opts...)
 }
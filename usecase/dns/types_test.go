@@ -0,0 +1,148 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// stubClusterPort reports the given capabilities and records driver DNS writes.
+// Methods not used by the DNS router panic through the nil embedded interface.
+type stubClusterPort struct {
+	model.ClusterPort
+	caps     *model.DriverCapabilities
+	capsErr  error
+	applyErr error
+	applied  []string // FQDNs written by the driver
+}
+
+func (s *stubClusterPort) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	return s.caps, s.capsErr
+}
+
+func (s *stubClusterPort) DNSApply(ctx context.Context, cluster *model.Cluster, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	s.applied = append(s.applied, rset.FQDN)
+	return s.applyErr
+}
+
+// stubDNSPort records the zone and FQDN of standalone DNS writes.
+type stubDNSPort struct {
+	applyErr error
+	applied  []string // "zone fqdn"
+}
+
+func (s *stubDNSPort) DNSApply(ctx context.Context, dns *model.DNSProvider, zone string, rset model.DNSRecordSet, opts ...model.ClusterDNSApplyOption) error {
+	s.applied = append(s.applied, zone+" "+rset.FQDN)
+	return s.applyErr
+}
+
+func TestNewDNSRouter(t *testing.T) {
+	clusterDNS := &model.DNSProvider{Driver: "rfc2136", Zones: []string{"cluster.example.com"}}
+	workspaceDNS := &model.DNSProvider{Driver: "webhook", Zones: []string{"example.com"}}
+	errCaps := errors.New("capabilities unavailable")
+
+	tests := []struct {
+		name       string
+		clusterDNS *model.DNSProvider
+		workspace  *model.Workspace
+		noDNSPort  bool
+		capsErr    error
+		want       *model.DNSProvider
+	}{
+		{name: "cluster provider", clusterDNS: clusterDNS, workspace: &model.Workspace{DNS: workspaceDNS}, want: clusterDNS},
+		{name: "workspace provider", workspace: &model.Workspace{DNS: workspaceDNS}, want: workspaceDNS},
+		{name: "no provider", workspace: &model.Workspace{}},
+		{name: "no workspace"},
+		{name: "no DNS port", clusterDNS: clusterDNS, noDNSPort: true},
+		{name: "capabilities error", capsErr: errCaps},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &UseCase{ClusterPort: &stubClusterPort{caps: &model.DriverCapabilities{Driver: "aks", DNS: true}, capsErr: tt.capsErr}}
+			if !tt.noDNSPort {
+				u.DNSPort = &stubDNSPort{}
+			}
+			r, err := u.newDNSRouter(context.Background(), &model.Cluster{Name: "c1", DNS: tt.clusterDNS}, tt.workspace)
+			if tt.capsErr != nil {
+				if !errors.Is(err, tt.capsErr) {
+					t.Fatalf("got %v, want error %v", err, tt.capsErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.dns != tt.want || r.driver != "aks" || !r.driverDNS {
+				t.Errorf("got dns=%v driver=%q driverDNS=%v, want dns=%v", r.dns, r.driver, r.driverDNS, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSRouter(t *testing.T) {
+	zoned := &model.DNSProvider{Driver: "rfc2136", Zones: []string{"example.com", "apps.example.com"}}
+	catchAll := &model.DNSProvider{Driver: "webhook"}
+	errApply := errors.New("write failed")
+
+	tests := []struct {
+		name           string
+		dns            *model.DNSProvider
+		driverDNS      bool
+		fqdn           string
+		applyErr       error
+		wantUnroutable string
+		wantDNS        string // "zone fqdn" written by the DNS port
+		wantDriver     bool
+	}{
+		{name: "longest zone", dns: zoned, fqdn: "www.apps.example.com", wantDNS: "apps.example.com www.apps.example.com"},
+		{name: "zone apex with trailing dot", dns: zoned, fqdn: "example.com.", wantDNS: "example.com example.com."},
+		{name: "no zones", dns: catchAll, fqdn: "www.example.org", wantDNS: " www.example.org"},
+		{name: "outside zones to driver", dns: zoned, driverDNS: true, fqdn: "www.example.org", wantDriver: true},
+		{
+			name: "outside zones without driver DNS", dns: zoned, fqdn: "www.example.org", wantDriver: true,
+			wantUnroutable: "www.example.org is outside the zones of dns provider rfc2136 and driver k3s does not manage DNS records",
+		},
+		{name: "driver only", driverDNS: true, fqdn: "www.example.com", wantDriver: true},
+		{name: "no provider", fqdn: "www.example.com", wantDriver: true, wantUnroutable: "driver k3s does not manage DNS records"},
+		{name: "DNS port error", dns: zoned, fqdn: "www.example.com", applyErr: errApply, wantDNS: "example.com www.example.com"},
+		{name: "driver error", driverDNS: true, fqdn: "www.example.org", applyErr: errApply, wantDriver: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubClusterPort{applyErr: tt.applyErr}
+			dp := &stubDNSPort{applyErr: tt.applyErr}
+			r := &dnsRouter{
+				u:         &UseCase{ClusterPort: cp, DNSPort: dp},
+				cluster:   &model.Cluster{Name: "c1"},
+				dns:       tt.dns,
+				driver:    "k3s",
+				driverDNS: tt.driverDNS,
+			}
+
+			if got := r.unroutable(tt.fqdn); got != tt.wantUnroutable {
+				t.Errorf("unroutable = %q, want %q", got, tt.wantUnroutable)
+			}
+
+			err := r.apply(context.Background(), model.DNSRecordSet{FQDN: tt.fqdn, Type: model.DNSRecordTypeA, RData: []string{"192.0.2.1"}})
+			if !errors.Is(err, tt.applyErr) {
+				t.Errorf("apply error = %v, want %v", err, tt.applyErr)
+			}
+			var wantDNS, wantDriver []string
+			if tt.wantDNS != "" {
+				wantDNS = []string{tt.wantDNS}
+			}
+			if tt.wantDriver {
+				wantDriver = []string{tt.fqdn}
+			}
+			if !slices.Equal(dp.applied, wantDNS) {
+				t.Errorf("DNS port writes = %q, want %q", dp.applied, wantDNS)
+			}
+			if !slices.Equal(cp.applied, wantDriver) {
+				t.Errorf("driver writes = %q, want %q", cp.applied, wantDriver)
+			}
+		})
+	}
+}
@@ -38,6 +38,9 @@ func (u *UseCase) Create(ctx context.Context, in *CreateInput) (*CreateOutput, e
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster not found: %s", in.ClusterID)
 	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpCreate); err != nil {
+		return nil, err
+	}
 
 	var opts []model.NodePoolCreateOption
 	if in.Force {
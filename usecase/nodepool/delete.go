@@ -36,6 +36,9 @@ func (u *UseCase) Delete(ctx context.Context, in *DeleteInput) (*DeleteOutput, e
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster not found: %s", in.ClusterID)
 	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpDelete); err != nil {
+		return nil, err
+	}
 
 	var opts []model.NodePoolDeleteOption
 	if in.Force {
@@ -34,6 +34,9 @@ func (u *UseCase) List(ctx context.Context, in *ListInput) (*ListOutput, error)
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster not found: %s", in.ClusterID)
 	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpList); err != nil {
+		return nil, err
+	}
 
 	var opts []model.NodePoolListOption
 	if in.Name != "" {
@@ -44,6 +44,10 @@ type mockNodePoolPort struct {
 	deleteFunc func(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) error
 }
 
+func (m *mockNodePoolPort) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{Driver: "mock", NodePool: model.NodePoolCapabilities{List: true, Create: true, Update: true, Delete: true}}, nil
+}
+
 func (m *mockNodePoolPort) NodePoolList(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) ([]*model.NodePool, error) {
 	if m.listFunc != nil {
 		return m.listFunc(ctx, cluster, opts...)
@@ -331,3 +335,191 @@ func TestDelete(t *testing.T) {
 		}
 	})
 }
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	ptr := func(s string) *string { return &s }
+	three := 3
+
+	newCluster := func(protection *model.ClusterProtection) *model.Cluster {
+		return &model.Cluster{
+			ID:         "ws1/prv1/cls1",
+			Name:       "test-cluster",
+			Protection: protection,
+			NodePools: []model.NodePool{
+				{Name: ptr("system"), Mode: ptr("system"), InstanceType: ptr("Standard_D2s_v3")},
+				{Name: ptr("user"), Mode: ptr("user"), Labels: &map[string]string{"team": "a"}, Autoscaling: &model.NodePoolAutoscaling{Desired: &three}},
+				{Name: ptr("gpu"), InstanceType: ptr("Standard_NC6")},
+				{Name: ptr("big"), InstanceType: ptr("Standard_D8s_v3")},
+			},
+		}
+	}
+	type calls struct {
+		created, updated, deleted []string
+		update                    model.NodePool
+	}
+	newUseCase := func(cluster *model.Cluster, c *calls) *UseCase {
+		one := 1
+		port := &mockNodePoolPort{
+			listFunc: func(ctx context.Context, cluster *model.Cluster, opts ...model.NodePoolListOption) ([]*model.NodePool, error) {
+				return []*model.NodePool{
+					{Name: ptr("system"), Mode: ptr("System"), InstanceType: ptr("standard_d2s_v3"), Labels: &map[string]string{"kompox.dev/node-pool": "system"}},
+					{Name: ptr("user"), Mode: ptr("user"), Labels: &map[string]string{"team": "b", "kompox.dev/node-pool": "user"}, Autoscaling: &model.NodePoolAutoscaling{Desired: &one}},
+					{Name: ptr("big"), InstanceType: ptr("Standard_D4s_v3")},
+					{Name: ptr("legacy"), Mode: ptr("user")},
+					// Pools created by AKS cluster provision and a pool protected by label.
+					{Name: ptr("npsystem"), Mode: ptr("System"), Labels: &map[string]string{"kompox.dev/node-pool": "npsystem"}},
+					{Name: ptr("npuser1"), Mode: ptr("User"), Labels: &map[string]string{"kompox.dev/node-pool": "user"}},
+					{Name: ptr("locked"), Mode: ptr("user"), Labels: &map[string]string{model.NodePoolProtectionLabel: "cannotDelete"}},
+				}, nil
+			},
+			createFunc: func(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolCreateOption) (*model.NodePool, error) {
+				c.created = append(c.created, *pool.Name)
+				return &pool, nil
+			},
+			updateFunc: func(ctx context.Context, cluster *model.Cluster, pool model.NodePool, opts ...model.NodePoolUpdateOption) (*model.NodePool, error) {
+				c.updated = append(c.updated, *pool.Name)
+				c.update = pool
+				return &pool, nil
+			},
+			deleteFunc: func(ctx context.Context, cluster *model.Cluster, poolName string, opts ...model.NodePoolDeleteOption) error {
+				c.deleted = append(c.deleted, poolName)
+				return nil
+			},
+		}
+		repos := &Repos{Cluster: &mockClusterRepo{getFunc: func(ctx context.Context, id string) (*model.Cluster, error) {
+			return cluster, nil
+		}}}
+		return &UseCase{Repos: repos, NodePoolPort: port}
+	}
+	actions := func(out *SyncOutput) map[string]string {
+		m := map[string]string{}
+		for _, it := range out.Items {
+			m[it.Name] = it.Action
+		}
+		return m
+	}
+
+	t.Run("apply with prune", func(t *testing.T) {
+		var c calls
+		uc := newUseCase(newCluster(nil), &c)
+		out, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1", Prune: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]string{
+			"system": SyncActionUnchanged,
+			"user":   SyncActionUpdate,
+			"gpu":    SyncActionCreate,
+			"big":    SyncActionDrifted,
+			"legacy": SyncActionDelete,
+			// never pruned
+			"npsystem": SyncActionProtected,
+			"npuser1":  SyncActionProtected,
+			"locked":   SyncActionProtected,
+		}
+		if got := actions(out); len(got) != len(want) {
+			t.Fatalf("actions = %v", got)
+		} else {
+			for name, action := range want {
+				if got[name] != action {
+					t.Errorf("%s: action = %q, want %q", name, got[name], action)
+				}
+			}
+		}
+		if len(c.created) != 1 || len(c.updated) != 1 || len(c.deleted) != 1 || c.deleted[0] != "legacy" {
+			t.Errorf("calls = %+v", c)
+		}
+		// Updates carry the mutable fields only.
+		if c.update.Mode != nil || c.update.Labels == nil || c.update.Autoscaling == nil {
+			t.Errorf("update pool = %+v", c.update)
+		}
+		for _, it := range out.Items {
+			switch it.Name {
+			case "user":
+				if len(it.Fields) != 2 || it.Fields[0].Path != "labels.team" || it.Fields[0].Before != "b" || it.Fields[1].Path != "autoscaling.desired" || it.Fields[1].After != 3 {
+					t.Errorf("user fields = %+v", it.Fields)
+				}
+			case "big":
+				if len(it.Drift) != 1 || it.Drift[0].Path != "instanceType" {
+					t.Errorf("big drift = %+v", it.Drift)
+				}
+			}
+		}
+	})
+
+	t.Run("dry run without prune", func(t *testing.T) {
+		var c calls
+		uc := newUseCase(newCluster(nil), &c)
+		out, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1", DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(c.created)+len(c.updated)+len(c.deleted) != 0 {
+			t.Errorf("dry run called driver: %+v", c)
+		}
+		if got := actions(out); got["gpu"] != SyncActionCreate || got["user"] != SyncActionUpdate || got["legacy"] != SyncActionUnmanaged {
+			t.Errorf("actions = %v", got)
+		}
+	})
+
+	t.Run("protection blocks delete", func(t *testing.T) {
+		var c calls
+		uc := newUseCase(newCluster(&model.ClusterProtection{Provisioning: model.ProtectionCannotDelete}), &c)
+		out, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1", Prune: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := actions(out); got["legacy"] != SyncActionProtected || got["user"] != SyncActionUpdate {
+			t.Errorf("actions = %v", got)
+		}
+		if len(c.deleted) != 0 {
+			t.Errorf("protected pool deleted: %v", c.deleted)
+		}
+	})
+
+	t.Run("read-only protection blocks update", func(t *testing.T) {
+		var c calls
+		uc := newUseCase(newCluster(&model.ClusterProtection{Provisioning: model.ProtectionReadOnly}), &c)
+		out, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := actions(out); got["user"] != SyncActionProtected || got["gpu"] != SyncActionCreate {
+			t.Errorf("actions = %v", got)
+		}
+		if len(c.updated) != 0 {
+			t.Errorf("protected pool updated: %v", c.updated)
+		}
+	})
+
+	t.Run("provider name counts as declared", func(t *testing.T) {
+		var c calls
+		cluster := newCluster(nil)
+		cluster.NodePools = append(cluster.NodePools, model.NodePool{Name: ptr("batch"), ProviderName: ptr("legacy")})
+		uc := newUseCase(cluster, &c)
+		out, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1", Prune: true, DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := actions(out); got["legacy"] != "" {
+			t.Errorf("legacy must not be pruned: actions = %v", got)
+		}
+	})
+
+	t.Run("no declared pools", func(t *testing.T) {
+		cluster := newCluster(nil)
+		cluster.NodePools = nil
+		uc := newUseCase(cluster, &calls{})
+		if _, err := uc.Sync(ctx, &SyncInput{ClusterID: "ws1/prv1/cls1", Prune: true}); err == nil {
+			t.Error("expected error for cluster without nodePools")
+		}
+	})
+
+	t.Run("nil input", func(t *testing.T) {
+		uc := &UseCase{}
+		if _, err := uc.Sync(ctx, nil); err == nil {
+			t.Error("expected error for nil input")
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package nodepool
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+)
+
+// Sync actions reported per node pool.
+const (
+	SyncActionCreate    = "create"    // declared but missing; created (dry-run: would be created)
+	SyncActionUpdate    = "update"    // mutable fields differ; updated (dry-run: would be updated)
+	SyncActionDelete    = "delete"    // not declared; deleted with prune (dry-run: would be deleted)
+	SyncActionUnchanged = "unchanged" // matches the declaration
+	SyncActionDrifted   = "drifted"   // only immutable fields differ; the pool must be recreated
+	SyncActionUnmanaged = "unmanaged" // not declared and prune not requested
+	SyncActionProtected = "protected" // change blocked by cluster or node pool protection
+	SyncActionFailed    = "failed"    // driver call failed (see Error)
+)
+
+// SyncInput holds input parameters for reconciling node pools with the cluster spec.
+type SyncInput struct {
+	ClusterID string `json:"cluster_id"`
+	// Prune deletes node pools that are not declared in the cluster spec.
+	Prune bool `json:"prune,omitempty"`
+	// DryRun reports the changes without applying them.
+	DryRun bool `json:"dry_run,omitempty"`
+	Force  bool `json:"force,omitempty"`
+}
+
+// SyncItem reports a node pool and what sync did with it.
+type SyncItem struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Fields are the mutable field changes applied by the update.
+	Fields []model.ClusterChangeField `json:"fields,omitempty"`
+	// Drift are the immutable field differences that sync cannot apply.
+	Drift  []model.ClusterChangeField `json:"drift,omitempty"`
+	Reason string                     `json:"reason,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+}
+
+// SyncOutput holds the change report of a sync.
+type SyncOutput struct {
+	DryRun bool        `json:"dry_run"`
+	Prune  bool        `json:"prune"`
+	Items  []*SyncItem `json:"items"`
+}
+
+// Sync reconciles the node pools of a cluster with the pools declared in its spec
+// (Cluster.NodePools). Missing pools are created; existing pools are updated with the
+// mutable fields (labels and autoscaling) only. Differences in immutable fields are
+// reported as drift and left untouched. Undeclared pools are deleted only with Prune;
+// system pools and pools created by cluster provision are never pruned. Updates and
+// deletes are subject to the cluster provisioning protection and the protection label
+// of the pool (model.NodePoolProtectionLabel); blocked changes are reported as
+// protected. Driver failures are reported per pool and do not stop the remaining pools.
+//
+// The set of mutable fields is fixed here (see diffNodePool) rather than reported by
+// the driver. Fields a driver can update in place beyond labels and autoscaling (e.g.
+// the OKE boot volume size) are still reported as drift, and drivers that cannot update
+// labels or autoscaling report the failure per pool.
+func (u *UseCase) Sync(ctx context.Context, in *SyncInput) (*SyncOutput, error) {
+	if in == nil {
+		return nil, fmt.Errorf("input is required")
+	}
+	if in.ClusterID == "" {
+		return nil, fmt.Errorf("cluster ID is required")
+	}
+
+	cluster, err := u.Repos.Cluster.Get(ctx, in.ClusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster not found: %s", in.ClusterID)
+	}
+	if len(cluster.NodePools) == 0 {
+		return nil, fmt.Errorf("cluster %s declares no nodePools", cluster.Name)
+	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpList); err != nil {
+		return nil, err
+	}
+
+	current, err := u.NodePoolPort.NodePoolList(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pools: %w", err)
+	}
+	actual := make(map[string]*model.NodePool, len(current))
+	for _, p := range current {
+		if p != nil && p.Name != nil {
+			actual[*p.Name] = p
+		}
+	}
+
+	out := &SyncOutput{DryRun: in.DryRun, Prune: in.Prune, Items: []*SyncItem{}}
+	declared := make(map[string]bool, len(cluster.NodePools))
+	for _, want := range cluster.NodePools {
+		if want.Name == nil || *want.Name == "" {
+			return nil, fmt.Errorf("cluster %s declares a node pool without name", cluster.Name)
+		}
+		declared[*want.Name] = true
+		if want.ProviderName != nil && *want.ProviderName != "" {
+			declared[*want.ProviderName] = true
+		}
+		out.Items = append(out.Items, u.syncPool(ctx, cluster, want, actual[*want.Name], in))
+	}
+	for _, name := range slices.Sorted(maps.Keys(actual)) {
+		if declared[name] {
+			continue
+		}
+		out.Items = append(out.Items, u.syncPrune(ctx, cluster, actual[name], in))
+	}
+	return out, nil
+}
+
+// syncPool creates or updates a single declared pool.
+func (u *UseCase) syncPool(ctx context.Context, cluster *model.Cluster, want model.NodePool, have *model.NodePool, in *SyncInput) *SyncItem {
+	item := &SyncItem{Name: *want.Name}
+
+	if have == nil {
+		item.Action = SyncActionCreate
+		if err := u.checkCapability(ctx, cluster, model.NodePoolOpCreate); err != nil {
+			return syncFailed(item, err)
+		}
+		if in.DryRun {
+			return item
+		}
+		var opts []model.NodePoolCreateOption
+		if in.Force {
+			opts = append(opts, model.WithNodePoolCreateForce())
+		}
+		if _, err := u.NodePoolPort.NodePoolCreate(ctx, cluster, want, opts...); err != nil {
+			return syncFailed(item, fmt.Errorf("create: %w", err))
+		}
+		return item
+	}
+
+	item.Fields, item.Drift = diffNodePool(want, *have)
+	switch {
+	case len(item.Fields) == 0 && len(item.Drift) == 0:
+		item.Action = SyncActionUnchanged
+		return item
+	case len(item.Fields) == 0:
+		item.Action = SyncActionDrifted
+		item.Reason = "immutable fields differ; recreate the node pool to apply them"
+		return item
+	}
+
+	item.Action = SyncActionUpdate
+	if len(item.Drift) > 0 {
+		item.Reason = "immutable fields differ and are not applied"
+	}
+	if err := cluster.CheckProvisioningProtection(model.OpUpdate); err != nil {
+		item.Action = SyncActionProtected
+		item.Reason = err.Error()
+		return item
+	}
+	if err := have.CheckProtection(model.OpUpdate); err != nil {
+		item.Action = SyncActionProtected
+		item.Reason = err.Error()
+		return item
+	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpUpdate); err != nil {
+		return syncFailed(item, err)
+	}
+	if in.DryRun {
+		return item
+	}
+
+	// Only mutable fields are sent so that drivers do not reject the update for drift.
+	update := model.NodePool{Name: want.Name, Labels: want.Labels, Autoscaling: want.Autoscaling}
+	var opts []model.NodePoolUpdateOption
+	if in.Force {
+		opts = append(opts, model.WithNodePoolUpdateForce())
+	}
+	if _, err := u.NodePoolPort.NodePoolUpdate(ctx, cluster, update, opts...); err != nil {
+		return syncFailed(item, fmt.Errorf("update: %w", err))
+	}
+	return item
+}
+
+// syncPrune deletes an undeclared pool when pruning is requested. System pools, pools
+// created by cluster provision and pools protected by label are kept.
+func (u *UseCase) syncPrune(ctx context.Context, cluster *model.Cluster, pool *model.NodePool, in *SyncInput) *SyncItem {
+	name := *pool.Name
+	item := &SyncItem{Name: name, Action: SyncActionUnmanaged}
+	if !in.Prune {
+		item.Reason = "not declared in cluster spec (use prune to delete)"
+		return item
+	}
+
+	if reason := pruneProtection(pool); reason != "" {
+		item.Action = SyncActionProtected
+		item.Reason = reason
+		return item
+	}
+	item.Action = SyncActionDelete
+	if err := cluster.CheckProvisioningProtection(model.OpDelete); err != nil {
+		item.Action = SyncActionProtected
+		item.Reason = err.Error()
+		return item
+	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpDelete); err != nil {
+		return syncFailed(item, err)
+	}
+	if in.DryRun {
+		return item
+	}
+	var opts []model.NodePoolDeleteOption
+	if in.Force {
+		opts = append(opts, model.WithNodePoolDeleteForce())
+	}
+	if err := u.NodePoolPort.NodePoolDelete(ctx, cluster, name, opts...); err != nil {
+		return syncFailed(item, fmt.Errorf("delete: %w", err))
+	}
+	return item
+}
+
+// pruneProtection returns why an undeclared pool must not be pruned, or an empty string.
+// Pools created by cluster provision carry a logical name (kompox.dev/node-pool) that
+// differs from the provider pool name (e.g., AKS "npuser1" labeled "user").
+func pruneProtection(pool *model.NodePool) string {
+	if pool.Mode != nil && strings.EqualFold(*pool.Mode, "system") {
+		return "system node pool is never pruned"
+	}
+	if name := pool.LogicalName(); name != *pool.Name {
+		return fmt.Sprintf("created by cluster provision as pool %s; not pruned", name)
+	}
+	if err := pool.CheckProtection(model.OpDelete); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// syncFailed marks the item failed with err.
+func syncFailed(item *SyncItem, err error) *SyncItem {
+	item.Action = SyncActionFailed
+	item.Error = err.Error()
+	return item
+}
+
+// diffNodePool compares the declared fields of want with have. It returns the differences
+// in mutable fields (labels, autoscaling) and in immutable fields (mode, instance type,
+// OS disk, priority, zones). Fields not declared in want, or not reported by the driver
+// in have, are not compared. Labels are compared for the declared keys only since drivers
+// may add their own labels.
+func diffNodePool(want, have model.NodePool) (mutable, immutable []model.ClusterChangeField) {
+	if want.Labels != nil {
+		var cur map[string]string
+		if have.Labels != nil {
+			cur = *have.Labels
+		}
+		for _, k := range slices.Sorted(maps.Keys(*want.Labels)) {
+			v := (*want.Labels)[k]
+			if old, ok := cur[k]; !ok || old != v {
+				f := model.ClusterChangeField{Path: "labels." + k, After: v}
+				if ok {
+					f.Before = old
+				}
+				mutable = append(mutable, f)
+			}
+		}
+	}
+	if want.Autoscaling != nil {
+		mutable = append(mutable, diffAutoscaling(want.Autoscaling, have.Autoscaling)...)
+	}
+
+	str := func(path string, w, h *string) {
+		if w != nil && h != nil && !strings.EqualFold(*w, *h) {
+			immutable = append(immutable, model.ClusterChangeField{Path: path, Before: *h, After: *w})
+		}
+	}
+	str("mode", want.Mode, have.Mode)
+	str("instanceType", want.InstanceType, have.InstanceType)
+	str("osDiskType", want.OSDiskType, have.OSDiskType)
+	str("priority", want.Priority, have.Priority)
+	if want.OSDiskSizeGiB != nil && have.OSDiskSizeGiB != nil && *want.OSDiskSizeGiB != *have.OSDiskSizeGiB {
+		immutable = append(immutable, model.ClusterChangeField{Path: "osDiskSizeGiB", Before: *have.OSDiskSizeGiB, After: *want.OSDiskSizeGiB})
+	}
+	if want.Zones != nil && have.Zones != nil && !slices.Equal(slices.Sorted(slices.Values(*want.Zones)), slices.Sorted(slices.Values(*have.Zones))) {
+		immutable = append(immutable, model.ClusterChangeField{Path: "zones", Before: *have.Zones, After: *want.Zones})
+	}
+	return mutable, immutable
+}
+
+// diffAutoscaling compares declared scaling with the current one. Min and Max are compared
+// when autoscaling is enabled, Desired when it is disabled and declared.
+func diffAutoscaling(want, have *model.NodePoolAutoscaling) []model.ClusterChangeField {
+	var cur model.NodePoolAutoscaling
+	if have != nil {
+		cur = *have
+	}
+	var fields []model.ClusterChangeField
+	if want.Enabled != cur.Enabled {
+		fields = append(fields, model.ClusterChangeField{Path: "autoscaling.enabled", Before: cur.Enabled, After: want.Enabled})
+	}
+	if want.Enabled {
+		if want.Min != cur.Min {
+			fields = append(fields, model.ClusterChangeField{Path: "autoscaling.min", Before: cur.Min, After: want.Min})
+		}
+		if want.Max != cur.Max {
+			fields = append(fields, model.ClusterChangeField{Path: "autoscaling.max", Before: cur.Max, After: want.Max})
+		}
+		return fields
+	}
+	if want.Desired != nil && (cur.Desired == nil || *cur.Desired != *want.Desired) {
+		f := model.ClusterChangeField{Path: "autoscaling.desired", After: *want.Desired}
+		if cur.Desired != nil {
+			f.Before = *cur.Desired
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
@@ -1,6 +1,9 @@
 package nodepool
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/kompox/kompox/domain"
 	"github.com/kompox/kompox/domain/model"
 )
@@ -17,3 +20,13 @@ type UseCase struct {
 	Repos        *Repos
 	NodePoolPort model.NodePoolPort
 }
+
+// checkCapability returns an error if the driver managing the cluster does not support
+// the node pool operation (model.NodePoolOp*).
+func (u *UseCase) checkCapability(ctx context.Context, cluster *model.Cluster, op string) error {
+	caps, err := u.NodePoolPort.Capabilities(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("get driver capabilities: %w", err)
+	}
+	return caps.CheckNodePool(op)
+}
@@ -38,6 +38,9 @@ func (u *UseCase) Update(ctx context.Context, in *UpdateInput) (*UpdateOutput, e
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster not found: %s", in.ClusterID)
 	}
+	if err := u.checkCapability(ctx, cluster, model.NodePoolOpUpdate); err != nil {
+		return nil, err
+	}
 
 	var opts []model.NodePoolUpdateOption
 	if in.Force {
@@ -21,8 +21,14 @@ type DiskCreateInput struct {
 	// Options overrides/merges with app.volumes.options when specified.
 	Options map[string]any `json:"options,omitempty"`
 	// Source specifies the source for disk creation (snapshot name, resource ID, etc.).
-	// Empty means create an empty disk. The interpretation is delegated to the provider driver.
+	// Empty means create an empty disk. The interpretation is delegated to the provider driver,
+	// except for the typed app source form (app:<appID>:<volName>:[disk|snapshot:]<name>)
+	// which is resolved by this use case. See AppSourcePrefix.
 	Source string `json:"source,omitempty"`
+	// Labels are user labels stored with the disk as provider tags.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Description is a free-form user description stored with the disk.
+	Description string `json:"description,omitempty"`
 }
 
 // DiskCreateOutput result for DiskCreate use case.
@@ -44,6 +50,12 @@ func (u *UseCase) DiskCreate(ctx context.Context, in *DiskCreateInput) (*DiskCre
 			return nil, fmt.Errorf("validate disk name: %w", err)
 		}
 	}
+	if err := naming.ValidateVolumeLabels(in.Labels); err != nil {
+		return nil, fmt.Errorf("validate labels: %w", err)
+	}
+	if err := naming.ValidateVolumeDescription(in.Description); err != nil {
+		return nil, fmt.Errorf("validate description: %w", err)
+	}
 	app, err := u.Repos.App.Get(ctx, in.AppID)
 	if err != nil {
 		return nil, err
@@ -59,21 +71,49 @@ func (u *UseCase) DiskCreate(ctx context.Context, in *DiskCreateInput) (*DiskCre
 		return nil, fmt.Errorf("cluster not found: %s", app.ClusterID)
 	}
 	// Validate logical volume exists
-	_, err = app.FindVolume(in.VolumeName)
+	vol, err := app.FindVolume(in.VolumeName)
 	if err != nil {
 		return nil, fmt.Errorf("volume not defined: %w", err)
 	}
+	caps, err := u.capabilities(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckVolume(*vol); err != nil {
+		return nil, err
+	}
 
 	// Build options based on input
 	var opts []model.VolumeDiskCreateOption
-	if in.Zone != "" {
-		opts = append(opts, model.WithVolumeDiskCreateZone(in.Zone))
+	zone := in.Zone
+	source := in.Source
+	if IsAppSource(source) {
+		src, err := u.resolveAppSource(ctx, app, cluster, vol, source)
+		if err != nil {
+			return nil, fmt.Errorf("resolve source: %w", err)
+		}
+		source = src.Handle
+		if zone == "" {
+			zone = appSourceZone(app, src.Zone)
+		}
+		if src.Size > vol.Size {
+			opts = append(opts, model.WithVolumeDiskCreateSize(src.Size))
+		}
+	}
+	if zone != "" {
+		opts = append(opts, model.WithVolumeDiskCreateZone(zone))
 	}
 	if in.Options != nil {
 		opts = append(opts, model.WithVolumeDiskCreateOptions(in.Options))
 	}
+	if len(in.Labels) > 0 {
+		opts = append(opts, model.WithVolumeDiskCreateLabels(in.Labels))
+	}
+	if in.Description != "" {
+		opts = append(opts, model.WithVolumeDiskCreateDescription(in.Description))
+	}
 
-	disk, err := u.VolumePort.DiskCreate(ctx, cluster, app, in.VolumeName, in.DiskName, in.Source, opts...)
+	disk, err := u.VolumePort.DiskCreate(ctx, cluster, app, in.VolumeName, in.DiskName, source, opts...)
 	if err != nil {
 		return nil, err
 	}
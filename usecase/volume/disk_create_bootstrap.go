@@ -65,6 +65,16 @@ func (u *UseCase) DiskCreateBootstrap(ctx context.Context, in *DiskCreateBootstr
 		return &DiskCreateBootstrapOutput{Skipped: true, Reason: "no volumes defined", Duration: time.Since(start)}, nil
 	}
 
+	caps, err := u.capabilities(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, av := range app.Volumes {
+		if err := caps.CheckVolume(av); err != nil {
+			return nil, err
+		}
+	}
+
 	// Gather state per volume.
 	type volState struct {
 		name     string
@@ -0,0 +1,228 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// AppSourcePrefix is the typed source prefix referencing a disk or snapshot of another App.
+//
+// Format: app:<appID>:<volName>:[disk|snapshot:]<name>
+//
+// Example: app:/ws/w/prv/p/cls/c/app/prod:db:snapshot:nightly
+//
+// Unlike other source strings, which are opaque and interpreted by the provider driver
+// (K4x-ADR-003), app sources are resolved by the UseCase through the repositories so
+// that the referenced App can be validated. The driver receives the provider handle of
+// the resolved disk or snapshot.
+const AppSourcePrefix = "app:"
+
+// AppSourceAllowSetting is the App setting of a source App listing the App IDs allowed to
+// use its disks and snapshots in app sources, separated by commas. "*" allows every App of
+// the same Workspace. Without the setting the App cannot be used as an app source, so that
+// data is never copied out of an App without an explicit opt-in on its side.
+const AppSourceAllowSetting = "KOMPOX_VOLUME_SOURCE_ALLOW"
+
+// Source kinds accepted in app sources.
+const (
+	AppSourceKindDisk     = "disk"
+	AppSourceKindSnapshot = "snapshot"
+)
+
+// AppSource is a parsed app source reference.
+type AppSource struct {
+	// AppID is the source App resource ID (FQN: /ws/<ws>/prv/<prv>/cls/<cls>/app/<app>).
+	AppID string `json:"app_id"`
+	// VolumeName is the logical volume name in the source App.
+	VolumeName string `json:"volume_name"`
+	// Kind is "disk" or "snapshot". Defaults to "snapshot" when omitted.
+	Kind string `json:"kind"`
+	// Name is the disk or snapshot name.
+	Name string `json:"name"`
+}
+
+// IsAppSource reports whether source uses the typed app source form.
+func IsAppSource(source string) bool {
+	return strings.HasPrefix(strings.TrimSpace(source), AppSourcePrefix)
+}
+
+// ParseAppSource parses and validates an app source string.
+func ParseAppSource(source string) (*AppSource, error) {
+	source = strings.TrimSpace(source)
+	if !strings.HasPrefix(source, AppSourcePrefix) {
+		return nil, fmt.Errorf("app source must start with %q", AppSourcePrefix)
+	}
+	parts := strings.Split(strings.TrimPrefix(source, AppSourcePrefix), ":")
+	var src AppSource
+	switch len(parts) {
+	case 3:
+		src = AppSource{AppID: parts[0], VolumeName: parts[1], Kind: AppSourceKindSnapshot, Name: parts[2]}
+	case 4:
+		src = AppSource{AppID: parts[0], VolumeName: parts[1], Kind: parts[2], Name: parts[3]}
+	default:
+		return nil, fmt.Errorf("invalid app source %q: expected app:<appID>:<volName>:[disk|snapshot:]<name>", source)
+	}
+
+	segs := strings.Split(strings.TrimPrefix(src.AppID, "/"), "/")
+	if !strings.HasPrefix(src.AppID, "/") || len(segs) != 8 || segs[0] != "ws" || segs[2] != "prv" || segs[4] != "cls" || segs[6] != "app" || slices.Contains(segs, "") {
+		return nil, fmt.Errorf("invalid app source %q: app ID must be /ws/<ws>/prv/<prv>/cls/<cls>/app/<app>", source)
+	}
+	if err := naming.ValidateVolumeName(src.VolumeName); err != nil {
+		return nil, fmt.Errorf("invalid app source %q: %w", source, err)
+	}
+	switch src.Kind {
+	case AppSourceKindDisk:
+		if err := naming.ValidateDiskName(src.Name); err != nil {
+			return nil, fmt.Errorf("invalid app source %q: %w", source, err)
+		}
+	case AppSourceKindSnapshot:
+		if err := naming.ValidateSnapshotName(src.Name); err != nil {
+			return nil, fmt.Errorf("invalid app source %q: %w", source, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid app source %q: unknown kind %q (must be disk or snapshot)", source, src.Kind)
+	}
+	return &src, nil
+}
+
+// resolvedAppSource is the result of resolving an app source.
+type resolvedAppSource struct {
+	// Handle is the provider handle of the source disk or snapshot passed to the driver.
+	Handle string
+	// Size is the source size in bytes (0 if unknown).
+	Size int64
+	// Zone is the source disk zone (empty for snapshots and regional disks).
+	Zone string
+}
+
+// resolveAppSource resolves an app source against the repositories and the volume port.
+// The following checks are enforced:
+//   - the target volume is not a files volume (cross-app copy of file shares is not supported)
+//   - the source App, its Cluster and Provider exist
+//   - the source is not the target volume itself (use disk:/snapshot: instead)
+//   - source and target belong to the same Workspace and Provider, so that the source
+//     disk or snapshot is in the account and region of the target (the driver cannot
+//     copy across them)
+//   - the source App allows the target App through AppSourceAllowSetting
+//   - source and target volumes have the same volume type
+//   - the referenced disk or snapshot exists
+func (u *UseCase) resolveAppSource(ctx context.Context, app *model.App, cluster *model.Cluster, vol *model.AppVolume, source string) (*resolvedAppSource, error) {
+	src, err := ParseAppSource(source)
+	if err != nil {
+		return nil, err
+	}
+	if src.AppID == app.ID && src.VolumeName == vol.Name {
+		return nil, fmt.Errorf("app source refers to the target volume itself; use %s:%s instead", src.Kind, src.Name)
+	}
+	if volumeType(vol) == model.VolumeTypeFiles {
+		return nil, fmt.Errorf("app sources are not supported for %s volumes", model.VolumeTypeFiles)
+	}
+
+	srcApp, err := u.Repos.App.Get(ctx, src.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("source app %s: %w", src.AppID, err)
+	}
+	if srcApp == nil {
+		return nil, fmt.Errorf("source app not found: %s", src.AppID)
+	}
+	srcCluster, err := u.Repos.Cluster.Get(ctx, srcApp.ClusterID)
+	if err != nil {
+		return nil, fmt.Errorf("source cluster %s: %w", srcApp.ClusterID, err)
+	}
+	if srcCluster == nil {
+		return nil, fmt.Errorf("source cluster not found: %s", srcApp.ClusterID)
+	}
+
+	provider, err := u.Repos.Provider.Get(ctx, cluster.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %w", cluster.ProviderID, err)
+	}
+	srcProvider, err := u.Repos.Provider.Get(ctx, srcCluster.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("source provider %s: %w", srcCluster.ProviderID, err)
+	}
+	if provider == nil || srcProvider == nil {
+		return nil, fmt.Errorf("provider not found")
+	}
+	if srcProvider.WorkspaceID != provider.WorkspaceID {
+		return nil, fmt.Errorf("source app %s belongs to a different workspace", src.AppID)
+	}
+	if srcProvider.ID != provider.ID {
+		return nil, fmt.Errorf("source app %s uses provider %s (driver %q), target uses %s (driver %q): app sources must share the provider account and region; use app replicate to copy snapshots across providers", src.AppID, srcProvider.ID, srcProvider.Driver, provider.ID, provider.Driver)
+	}
+	if !appSourceAllowed(srcApp, app.ID) {
+		return nil, fmt.Errorf("source app %s does not allow %s as a source app; add it to the %s setting of the source app", src.AppID, app.ID, AppSourceAllowSetting)
+	}
+
+	srcVol, err := srcApp.FindVolume(src.VolumeName)
+	if err != nil {
+		return nil, fmt.Errorf("source app %s: %w", src.AppID, err)
+	}
+	if volumeType(srcVol) != volumeType(vol) {
+		return nil, fmt.Errorf("source volume type %q does not match target volume type %q", volumeType(srcVol), volumeType(vol))
+	}
+
+	switch src.Kind {
+	case AppSourceKindDisk:
+		disks, err := u.VolumePort.DiskList(ctx, srcCluster, srcApp, src.VolumeName)
+		if err != nil {
+			return nil, fmt.Errorf("list source disks: %w", err)
+		}
+		for _, d := range disks {
+			if d.Name == src.Name {
+				return &resolvedAppSource{Handle: d.Handle, Size: d.Size, Zone: d.Zone}, nil
+			}
+		}
+		return nil, fmt.Errorf("source disk not found: %s", src.Name)
+	default:
+		snaps, err := u.VolumePort.SnapshotList(ctx, srcCluster, srcApp, src.VolumeName)
+		if err != nil {
+			return nil, fmt.Errorf("list source snapshots: %w", err)
+		}
+		for _, s := range snaps {
+			if s.Name == src.Name {
+				return &resolvedAppSource{Handle: s.Handle, Size: s.Size}, nil
+			}
+		}
+		return nil, fmt.Errorf("source snapshot not found: %s", src.Name)
+	}
+}
+
+// appSourceAllowed reports whether srcApp allows appID to use it in app sources.
+func appSourceAllowed(srcApp *model.App, appID string) bool {
+	for _, v := range strings.Split(srcApp.Settings[AppSourceAllowSetting], ",") {
+		if v = strings.TrimSpace(v); v == "*" || v == appID {
+			return true
+		}
+	}
+	return false
+}
+
+// appSourceZone chooses the zone of a disk created from an app source when no zone was
+// explicitly requested. The target app deployment zone wins; otherwise the source zone is
+// kept if allowed by the deployment zones, falling back to the first deployment zone.
+func appSourceZone(app *model.App, srcZone string) string {
+	if app.Deployment.Zone != "" {
+		return app.Deployment.Zone
+	}
+	if len(app.Deployment.Zones) > 0 {
+		if srcZone != "" && slices.Contains(app.Deployment.Zones, srcZone) {
+			return srcZone
+		}
+		return app.Deployment.Zones[0]
+	}
+	return srcZone
+}
+
+// volumeType returns the canonical volume type (empty means disk).
+func volumeType(vol *model.AppVolume) string {
+	if vol.Type == "" {
+		return model.VolumeTypeDisk
+	}
+	return vol.Type
+}
@@ -0,0 +1,250 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kompox/kompox/adapters/store/inmem"
+	"github.com/kompox/kompox/domain/model"
+)
+
+// mockVolumePort is a mock implementation for testing.
+type mockVolumePort struct {
+	disks      map[string][]*model.VolumeDisk     // key: appID/volName
+	snapshots  map[string][]*model.VolumeSnapshot // key: appID/volName
+	createFunc func(ctx context.Context, cluster *model.Cluster, app *model.App, volName, diskName, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error)
+}
+
+func (m *mockVolumePort) Capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	return &model.DriverCapabilities{Driver: "mock", Volumes: map[string]model.VolumeTypeCapabilities{
+		model.VolumeTypeDisk:  {AccessModes: []string{"ReadWriteOnce"}, Snapshot: true},
+		model.VolumeTypeFiles: {AccessModes: []string{"ReadWriteMany"}, Snapshot: true},
+	}}, nil
+}
+
+func (m *mockVolumePort) DiskList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeDiskListOption) ([]*model.VolumeDisk, error) {
+	return m.disks[app.ID+"/"+volName], nil
+}
+
+func (m *mockVolumePort) DiskCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, cluster, app, volName, diskName, source, opts...)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockVolumePort) DiskDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskDeleteOption) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockVolumePort) DiskAssign(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskAssignOption) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockVolumePort) DiskUpdate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, diskName string, opts ...model.VolumeDiskUpdateOption) (*model.VolumeDisk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockVolumePort) SnapshotList(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, opts ...model.VolumeSnapshotListOption) ([]*model.VolumeSnapshot, error) {
+	return m.snapshots[app.ID+"/"+volName], nil
+}
+
+func (m *mockVolumePort) SnapshotCreate(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source string, opts ...model.VolumeSnapshotCreateOption) (*model.VolumeSnapshot, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockVolumePort) SnapshotDelete(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, opts ...model.VolumeSnapshotDeleteOption) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockVolumePort) SnapshotCopy(ctx context.Context, cluster *model.Cluster, app *model.App, volName string, snapName string, source *model.VolumeSnapshot, opts ...model.VolumeSnapshotCopyOption) (*model.VolumeSnapshot, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestParseAppSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    *AppSource
+		wantErr string
+	}{
+		{
+			name:   "snapshot",
+			source: "app:/ws/w/prv/p/cls/c/app/prod:db:snapshot:nightly",
+			want:   &AppSource{AppID: "/ws/w/prv/p/cls/c/app/prod", VolumeName: "db", Kind: "snapshot", Name: "nightly"},
+		},
+		{
+			name:   "disk",
+			source: "app:/ws/w/prv/p/cls/c/app/prod:db:disk:d1",
+			want:   &AppSource{AppID: "/ws/w/prv/p/cls/c/app/prod", VolumeName: "db", Kind: "disk", Name: "d1"},
+		},
+		{
+			name:   "kind defaults to snapshot",
+			source: "app:/ws/w/prv/p/cls/c/app/prod:db:nightly",
+			want:   &AppSource{AppID: "/ws/w/prv/p/cls/c/app/prod", VolumeName: "db", Kind: "snapshot", Name: "nightly"},
+		},
+		{name: "missing prefix", source: "/ws/w/prv/p/cls/c/app/prod:db:nightly", wantErr: "must start with"},
+		{name: "too few parts", source: "app:/ws/w/prv/p/cls/c/app/prod:db", wantErr: "expected app:"},
+		{name: "not an app FQN", source: "app:/ws/w/prv/p/cls/c:db:nightly", wantErr: "app ID must be"},
+		{name: "unknown kind", source: "app:/ws/w/prv/p/cls/c/app/prod:db:image:x", wantErr: "unknown kind"},
+		{name: "invalid volume", source: "app:/ws/w/prv/p/cls/c/app/prod:DB:nightly", wantErr: "invalid app source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAppSource(tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseAppSource() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAppSource() unexpected error: %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("ParseAppSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskCreateFromAppSource(t *testing.T) {
+	ctx := context.Background()
+	const (
+		prodID    = "/ws/w/prv/p/cls/c/app/prod"
+		stagingID = "/ws/w/prv/p/cls/c/app/staging"
+		otherID   = "/ws/x/prv/q/cls/c/app/other"
+		devID     = "/ws/w/prv/p/cls/c/app/dev"
+		eastID    = "/ws/w/prv/e/cls/d/app/east"
+	)
+
+	newUseCase := func(port *mockVolumePort) *UseCase {
+		repos := &Repos{
+			Workspace: inmem.NewWorkspaceRepository(),
+			Provider:  inmem.NewProviderRepository(),
+			Cluster:   inmem.NewClusterRepository(),
+			App:       inmem.NewAppRepository(),
+		}
+		_ = repos.Provider.Create(ctx, &model.Provider{ID: "/ws/w/prv/p", WorkspaceID: "/ws/w", Driver: "aks"})
+		_ = repos.Provider.Create(ctx, &model.Provider{ID: "/ws/x/prv/q", WorkspaceID: "/ws/x", Driver: "aks"})
+		_ = repos.Provider.Create(ctx, &model.Provider{ID: "/ws/w/prv/e", WorkspaceID: "/ws/w", Driver: "aks"})
+		_ = repos.Cluster.Create(ctx, &model.Cluster{ID: "/ws/w/prv/p/cls/c", ProviderID: "/ws/w/prv/p"})
+		_ = repos.Cluster.Create(ctx, &model.Cluster{ID: "/ws/w/prv/e/cls/d", ProviderID: "/ws/w/prv/e"})
+		_ = repos.Cluster.Create(ctx, &model.Cluster{ID: "/ws/x/prv/q/cls/c", ProviderID: "/ws/x/prv/q"})
+		_ = repos.App.Create(ctx, &model.App{ID: prodID, ClusterID: "/ws/w/prv/p/cls/c", Volumes: []model.AppVolume{{Name: "db", Size: 64 << 30}, {Name: "files", Type: model.VolumeTypeFiles}}, Settings: map[string]string{AppSourceAllowSetting: stagingID + "," + otherID + "," + eastID}})
+		_ = repos.App.Create(ctx, &model.App{ID: stagingID, ClusterID: "/ws/w/prv/p/cls/c", Volumes: []model.AppVolume{{Name: "db", Size: 32 << 30}, {Name: "files", Type: model.VolumeTypeFiles}}, Deployment: model.AppDeployment{Zones: []string{"1", "2"}}})
+		_ = repos.App.Create(ctx, &model.App{ID: otherID, ClusterID: "/ws/x/prv/q/cls/c", Volumes: []model.AppVolume{{Name: "db", Size: 32 << 30}}})
+		_ = repos.App.Create(ctx, &model.App{ID: eastID, ClusterID: "/ws/w/prv/e/cls/d", Volumes: []model.AppVolume{{Name: "db", Size: 32 << 30}}})
+		_ = repos.App.Create(ctx, &model.App{ID: devID, ClusterID: "/ws/w/prv/p/cls/c", Volumes: []model.AppVolume{{Name: "db", Size: 32 << 30}}})
+		return &UseCase{Repos: repos, VolumePort: port}
+	}
+
+	t.Run("snapshot source is resolved to handle with size adjustment", func(t *testing.T) {
+		var gotSource string
+		var gotOpts model.VolumeDiskCreateOptions
+		port := &mockVolumePort{
+			snapshots: map[string][]*model.VolumeSnapshot{
+				prodID + "/db": {{Name: "nightly", Size: 64 << 30, Handle: "/subscriptions/s/snapshots/nightly"}},
+			},
+			createFunc: func(ctx context.Context, cluster *model.Cluster, app *model.App, volName, diskName, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+				gotSource = source
+				for _, o := range opts {
+					o(&gotOpts)
+				}
+				return &model.VolumeDisk{Name: "new"}, nil
+			},
+		}
+		u := newUseCase(port)
+		_, err := u.DiskCreate(ctx, &DiskCreateInput{AppID: stagingID, VolumeName: "db", Source: "app:" + prodID + ":db:snapshot:nightly"})
+		if err != nil {
+			t.Fatalf("DiskCreate() unexpected error: %v", err)
+		}
+		if gotSource != "/subscriptions/s/snapshots/nightly" {
+			t.Errorf("source = %q, want snapshot handle", gotSource)
+		}
+		if gotOpts.Size != 64<<30 {
+			t.Errorf("size option = %d, want %d", gotOpts.Size, int64(64<<30))
+		}
+		if gotOpts.Zone != "1" {
+			t.Errorf("zone option = %q, want first deployment zone", gotOpts.Zone)
+		}
+	})
+
+	t.Run("disk source keeps source zone when allowed", func(t *testing.T) {
+		var gotOpts model.VolumeDiskCreateOptions
+		port := &mockVolumePort{
+			disks: map[string][]*model.VolumeDisk{
+				prodID + "/db": {{Name: "d1", Size: 16 << 30, Zone: "2", Handle: "/subscriptions/s/disks/d1"}},
+			},
+			createFunc: func(ctx context.Context, cluster *model.Cluster, app *model.App, volName, diskName, source string, opts ...model.VolumeDiskCreateOption) (*model.VolumeDisk, error) {
+				for _, o := range opts {
+					o(&gotOpts)
+				}
+				return &model.VolumeDisk{Name: "new"}, nil
+			},
+		}
+		u := newUseCase(port)
+		_, err := u.DiskCreate(ctx, &DiskCreateInput{AppID: stagingID, VolumeName: "db", Source: "app:" + prodID + ":db:disk:d1"})
+		if err != nil {
+			t.Fatalf("DiskCreate() unexpected error: %v", err)
+		}
+		if gotOpts.Zone != "2" {
+			t.Errorf("zone option = %q, want source zone", gotOpts.Zone)
+		}
+		if gotOpts.Size != 0 {
+			t.Errorf("size option = %d, want 0 (source smaller than volume)", gotOpts.Size)
+		}
+	})
+
+	errorCases := []struct {
+		name    string
+		appID   string
+		volName string
+		source  string
+		wantErr string
+	}{
+		{name: "not allowed by source app", appID: devID, source: "app:" + prodID + ":db:nightly", wantErr: "does not allow"},
+		{name: "files volume", appID: stagingID, volName: "files", source: "app:" + prodID + ":files:nightly", wantErr: "not supported for files volumes"},
+		{name: "different workspace", appID: otherID, source: "app:" + prodID + ":db:nightly", wantErr: "different workspace"},
+		{name: "different provider", appID: eastID, source: "app:" + prodID + ":db:nightly", wantErr: "must share the provider account and region"},
+		{name: "unknown app", appID: stagingID, source: "app:/ws/w/prv/p/cls/c/app/none:db:nightly", wantErr: "source app"},
+		{name: "volume type mismatch", appID: stagingID, source: "app:" + prodID + ":files:nightly", wantErr: "does not match"},
+		{name: "snapshot not found", appID: stagingID, source: "app:" + prodID + ":db:missing", wantErr: "source snapshot not found"},
+		{name: "self reference", appID: prodID, source: "app:" + prodID + ":db:nightly", wantErr: "target volume itself"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUseCase(&mockVolumePort{})
+			volName := tt.volName
+			if volName == "" {
+				volName = "db"
+			}
+			_, err := u.DiskCreate(ctx, &DiskCreateInput{AppID: tt.appID, VolumeName: volName, Source: tt.source})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("DiskCreate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppSourceAllowed(t *testing.T) {
+	const appID = "/ws/w/prv/p/cls/c/app/staging"
+	tests := []struct {
+		setting string
+		want    bool
+	}{
+		{setting: "", want: false},
+		{setting: appID, want: true},
+		{setting: "/ws/w/prv/p/cls/c/app/dev, " + appID, want: true},
+		{setting: "/ws/w/prv/p/cls/c/app/dev", want: false},
+		{setting: "*", want: true},
+	}
+	for _, tt := range tests {
+		src := &model.App{Settings: map[string]string{AppSourceAllowSetting: tt.setting}}
+		if got := appSourceAllowed(src, appID); got != tt.want {
+			t.Errorf("appSourceAllowed(%q) = %v, want %v", tt.setting, got, tt.want)
+		}
+	}
+}
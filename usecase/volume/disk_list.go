@@ -14,6 +14,8 @@ type DiskListInput struct {
 	AppID string `json:"app_id"`
 	// VolumeName logical volume name within the app.
 	VolumeName string `json:"volume_name"`
+	// Labels is an optional label selector; only disks having all key/value pairs are returned.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // DiskListOutput result for DiskList use case.
@@ -53,5 +55,14 @@ func (u *UseCase) DiskList(ctx context.Context, in *DiskListInput) (*DiskListOut
 	if err != nil {
 		return nil, err
 	}
+	if len(in.Labels) > 0 {
+		filtered := make([]*model.VolumeDisk, 0, len(items))
+		for _, d := range items {
+			if model.MatchLabels(d.Labels, in.Labels) {
+				filtered = append(filtered, d)
+			}
+		}
+		items = filtered
+	}
 	return &DiskListOutput{Items: items}, nil
 }
@@ -0,0 +1,75 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// DiskUpdateInput parameters for DiskUpdate use case.
+type DiskUpdateInput struct {
+	// AppID owning application identifier.
+	AppID string `json:"app_id"`
+	// VolumeName logical volume name.
+	VolumeName string `json:"volume_name"`
+	// DiskName disk name to update.
+	DiskName string `json:"disk_name"`
+	// Options provider-specific options to change in place (e.g., iops, mbps for AKS).
+	Options map[string]any `json:"options"`
+}
+
+// DiskUpdateOutput result for DiskUpdate use case.
+type DiskUpdateOutput struct {
+	// Disk is the updated volume disk.
+	Disk *model.VolumeDisk `json:"disk"`
+}
+
+// DiskUpdate changes options of an existing volume disk without recreating it.
+// Options that cannot be changed online are rejected by the provider driver.
+func (u *UseCase) DiskUpdate(ctx context.Context, in *DiskUpdateInput) (*DiskUpdateOutput, error) {
+	if in == nil || in.AppID == "" || in.VolumeName == "" || in.DiskName == "" {
+		return nil, fmt.Errorf("missing parameters")
+	}
+	if len(in.Options) == 0 {
+		return nil, fmt.Errorf("no options to update")
+	}
+	if err := naming.ValidateVolumeName(in.VolumeName); err != nil {
+		return nil, fmt.Errorf("validate volume name: %w", err)
+	}
+	if err := naming.ValidateDiskName(in.DiskName); err != nil {
+		return nil, fmt.Errorf("validate disk name: %w", err)
+	}
+	app, err := u.Repos.App.Get(ctx, in.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, fmt.Errorf("app not found: %s", in.AppID)
+	}
+	cluster, err := u.Repos.Cluster.Get(ctx, app.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster not found: %s", app.ClusterID)
+	}
+	// Validate logical volume exists
+	vol, err := app.FindVolume(in.VolumeName)
+	if err != nil {
+		return nil, fmt.Errorf("volume not defined: %w", err)
+	}
+	caps, err := u.capabilities(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckVolumeUpdate(*vol); err != nil {
+		return nil, err
+	}
+	disk, err := u.VolumePort.DiskUpdate(ctx, cluster, app, in.VolumeName, in.DiskName, model.WithVolumeDiskUpdateOptions(in.Options))
+	if err != nil {
+		return nil, err
+	}
+	return &DiskUpdateOutput{Disk: disk}, nil
+}
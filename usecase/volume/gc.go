@@ -0,0 +1,315 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// DefaultGCGracePeriod is the default time an orphaned resource must stay orphaned before deletion.
+const DefaultGCGracePeriod = 7 * 24 * time.Hour
+
+// GC actions reported per orphaned resource.
+const (
+	GCActionOrphaned    = "orphaned"     // dry-run: found orphaned, not yet marked
+	GCActionMarked      = "marked"       // marked orphaned in this run; deletion after the grace period
+	GCActionPending     = "pending"      // marked earlier; grace period not elapsed yet
+	GCActionWouldDelete = "would-delete" // dry-run: grace period elapsed, would be deleted
+	GCActionDeleted     = "deleted"      // deleted in this run
+	GCActionProtected   = "protected"    // deletion blocked by cluster protection policy
+	GCActionFailed      = "failed"       // marking or deletion failed (see Error)
+	GCActionWouldUnmark = "would-unmark" // dry-run: marked earlier but owned by an app again
+	GCActionUnmarked    = "unmarked"     // owned by an app again; orphaned mark removed in this run
+)
+
+// gcNow is replaceable in tests.
+var gcNow = time.Now
+
+// GCInput parameters for GC use case.
+type GCInput struct {
+	// ProviderID limits the scan to a single provider. Empty scans all providers.
+	ProviderID string `json:"provider_id,omitempty"`
+	// DryRun reports orphans without marking or deleting anything.
+	DryRun bool `json:"dry_run"`
+	// GracePeriod is the minimum time between the first run that marks a resource
+	// orphaned and its deletion. Zero deletes orphans in the same run and requires Force.
+	GracePeriod time.Duration `json:"grace_period"`
+	// Force allows a zero GracePeriod. Orphans are judged against the Apps loaded from KOM
+	// only, so an App missing from the loaded set loses its volumes without a grace period.
+	Force bool `json:"force"`
+}
+
+// GCItem reports an orphaned provider resource and what GC did with it.
+type GCItem struct {
+	ProviderID string                `json:"provider_id"`
+	Resource   *model.VolumeResource `json:"resource"`
+	Action     string                `json:"action"`
+	Reason     string                `json:"reason,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// GCSkippedProvider reports a provider that could not be scanned.
+type GCSkippedProvider struct {
+	ProviderID string `json:"provider_id"`
+	Reason     string `json:"reason"`
+}
+
+// GCOutput result for GC use case.
+type GCOutput struct {
+	DryRun      bool                `json:"dry_run"`
+	GracePeriod string              `json:"grace_period"`
+	Items       []*GCItem           `json:"items"`
+	Skipped     []GCSkippedProvider `json:"skipped,omitempty"`
+}
+
+// GC finds provider volume resources (disks, snapshots, storage accounts, resource groups)
+// whose app ID hash no longer matches any App in the repository and reports or deletes them.
+//
+// An orphan is first marked (its OrphanedAt is recorded by the driver) and deleted only
+// after GracePeriod has elapsed since marking, so that an App temporarily missing from
+// the KOM set does not lose its data. Marked resources whose App is defined again are
+// unmarked. Resources of providers having a cluster whose
+// provisioning protection blocks deletion are reported as protected and left untouched.
+// Providers whose driver does not support inventory are reported as skipped.
+func (u *UseCase) GC(ctx context.Context, in *GCInput) (*GCOutput, error) {
+	if in == nil {
+		return nil, fmt.Errorf("missing parameters")
+	}
+	if in.GracePeriod < 0 {
+		return nil, fmt.Errorf("grace period must not be negative")
+	}
+	if in.GracePeriod == 0 && !in.DryRun && !in.Force {
+		return nil, fmt.Errorf("zero grace period deletes resources of apps missing from the loaded KOM set without a grace period; force is required")
+	}
+	if u.VolumeInventoryPort == nil {
+		return nil, fmt.Errorf("volume inventory port not configured")
+	}
+
+	providers, err := u.Repos.Provider.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list providers: %w", err)
+	}
+	if in.ProviderID != "" {
+		var selected []*model.Provider
+		for _, p := range providers {
+			if p.ID == in.ProviderID {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("%w: %s", model.ErrProviderNotFound, in.ProviderID)
+		}
+		providers = selected
+	}
+
+	live, err := u.liveAppHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	protection, err := u.providerDeleteProtection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &GCOutput{DryRun: in.DryRun, GracePeriod: in.GracePeriod.String(), Items: []*GCItem{}}
+	now := gcNow()
+	for _, p := range providers {
+		resources, err := u.VolumeInventoryPort.ResourceList(ctx, p)
+		if err != nil {
+			if errors.Is(err, model.ErrNotSupported) {
+				out.Skipped = append(out.Skipped, GCSkippedProvider{ProviderID: p.ID, Reason: fmt.Sprintf("driver %s does not support volume inventory", p.Driver)})
+				continue
+			}
+			return nil, fmt.Errorf("list volume resources of provider %s: %w", p.ID, err)
+		}
+		var orphans, owned []*model.VolumeResource
+		for _, r := range resources {
+			if r == nil || r.AppIDHash == "" {
+				continue
+			}
+			if live[p.ID][r.AppIDHash] {
+				if r.OrphanedAt != nil {
+					owned = append(owned, r)
+				}
+				continue
+			}
+			orphans = append(orphans, r)
+		}
+		out.Items = append(out.Items, u.gcUnmark(ctx, p, owned, in)...)
+		out.Items = append(out.Items, u.gcProvider(ctx, p, orphans, protection[p.ID], in, now)...)
+	}
+	return out, nil
+}
+
+// gcUnmark removes the orphaned mark from resources whose App is defined again, so that
+// an App restored within the grace period does not keep an old mark that would let a later
+// orphaning delete its resources without a new grace period.
+func (u *UseCase) gcUnmark(ctx context.Context, p *model.Provider, owned []*model.VolumeResource, in *GCInput) []*GCItem {
+	var items []*GCItem
+	for _, r := range owned {
+		item := &GCItem{ProviderID: p.ID, Resource: r, Reason: "owned by an app again"}
+		items = append(items, item)
+		if in.DryRun {
+			item.Action = GCActionWouldUnmark
+			continue
+		}
+		if err := u.VolumeInventoryPort.ResourceUnmarkOrphaned(ctx, p, r); err != nil {
+			item.Action = GCActionFailed
+			item.Error = fmt.Sprintf("unmark orphaned: %v", err)
+			continue
+		}
+		r.OrphanedAt = nil
+		item.Action = GCActionUnmarked
+	}
+	return items
+}
+
+// gcProvider processes the orphans of a single provider. Contained resources are handled
+// before containers; a container is deleted only when all its contents were deleted.
+func (u *UseCase) gcProvider(ctx context.Context, p *model.Provider, orphans []*model.VolumeResource, protectErr error, in *GCInput, now time.Time) []*GCItem {
+	sort.SliceStable(orphans, func(i, j int) bool {
+		if orphans[i].Container != orphans[j].Container {
+			return !orphans[i].Container
+		}
+		return orphans[i].Handle < orphans[j].Handle
+	})
+
+	var items []*GCItem
+	for _, r := range orphans {
+		item := &GCItem{ProviderID: p.ID, Resource: r}
+		items = append(items, item)
+
+		if protectErr != nil {
+			item.Action = GCActionProtected
+			item.Reason = protectErr.Error()
+			continue
+		}
+
+		orphanedAt := r.OrphanedAt
+		marked := false
+		if orphanedAt == nil {
+			if in.DryRun {
+				item.Action = GCActionOrphaned
+			} else {
+				if err := u.VolumeInventoryPort.ResourceMarkOrphaned(ctx, p, r, now); err != nil {
+					item.Action = GCActionFailed
+					item.Error = fmt.Sprintf("mark orphaned: %v", err)
+					continue
+				}
+				t := now
+				orphanedAt = &t
+				r.OrphanedAt = orphanedAt
+				marked = true
+				item.Action = GCActionMarked
+			}
+		}
+
+		elapsed := time.Duration(0)
+		if orphanedAt != nil {
+			elapsed = now.Sub(*orphanedAt)
+		}
+		if in.GracePeriod > 0 && (orphanedAt == nil || elapsed < in.GracePeriod) {
+			if !marked && orphanedAt != nil {
+				item.Action = GCActionPending
+			}
+			item.Reason = fmt.Sprintf("grace period %s not elapsed", in.GracePeriod)
+			continue
+		}
+
+		if r.Container {
+			if blocker := gcContainerBlocker(r, items); blocker != nil {
+				if !marked && orphanedAt != nil {
+					item.Action = GCActionPending
+				}
+				item.Reason = fmt.Sprintf("contains %s %s (%s)", blocker.Resource.Kind, blocker.Resource.Name, blocker.Action)
+				continue
+			}
+		}
+
+		if in.DryRun {
+			item.Action = GCActionWouldDelete
+			continue
+		}
+		if err := u.VolumeInventoryPort.ResourceDelete(ctx, p, r); err != nil {
+			item.Action = GCActionFailed
+			item.Error = fmt.Sprintf("delete: %v", err)
+			continue
+		}
+		item.Action = GCActionDeleted
+	}
+	return items
+}
+
+// gcContainerBlocker returns the first processed item inside container that was not
+// deleted (or would not be deleted in dry-run).
+func gcContainerBlocker(container *model.VolumeResource, items []*GCItem) *GCItem {
+	prefix := strings.ToLower(container.Handle) + "/"
+	for _, it := range items {
+		if it.Resource == container || !strings.HasPrefix(strings.ToLower(it.Resource.Handle), prefix) {
+			continue
+		}
+		if it.Action != GCActionDeleted && it.Action != GCActionWouldDelete {
+			return it
+		}
+	}
+	return nil
+}
+
+// liveAppHashes returns the app ID hashes of all Apps keyed by provider ID.
+// Any App whose cluster or provider cannot be resolved aborts GC, since its
+// resources could otherwise be mistaken for orphans.
+func (u *UseCase) liveAppHashes(ctx context.Context) (map[string]map[string]bool, error) {
+	apps, err := u.Repos.App.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list apps: %w", err)
+	}
+	live := map[string]map[string]bool{}
+	for _, app := range apps {
+		cluster, err := u.Repos.Cluster.Get(ctx, app.ClusterID)
+		if err != nil || cluster == nil {
+			return nil, fmt.Errorf("app %s: cluster %s not resolvable: %v", app.ID, app.ClusterID, err)
+		}
+		provider, err := u.Repos.Provider.Get(ctx, cluster.ProviderID)
+		if err != nil || provider == nil {
+			return nil, fmt.Errorf("app %s: provider %s not resolvable: %v", app.ID, cluster.ProviderID, err)
+		}
+		workspaceName := "(nil)"
+		if provider.WorkspaceID != "" {
+			if ws, err := u.Repos.Workspace.Get(ctx, provider.WorkspaceID); err == nil && ws != nil {
+				workspaceName = ws.Name
+			}
+		}
+		h := naming.NewHashes(workspaceName, provider.Name, "", app.Name)
+		if live[provider.ID] == nil {
+			live[provider.ID] = map[string]bool{}
+		}
+		live[provider.ID][h.AppID] = true
+	}
+	return live, nil
+}
+
+// providerDeleteProtection returns, per provider ID, the first cluster protection error
+// blocking delete operations. App volume resources are not tied to a cluster in provider
+// tags, so any protected cluster of the provider protects all of its orphans.
+func (u *UseCase) providerDeleteProtection(ctx context.Context) (map[string]error, error) {
+	clusters, err := u.Repos.Cluster.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list clusters: %w", err)
+	}
+	out := map[string]error{}
+	for _, c := range clusters {
+		if out[c.ProviderID] != nil {
+			continue
+		}
+		if err := c.CheckProvisioningProtection(model.OpDelete); err != nil {
+			out[c.ProviderID] = fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,186 @@
+package volume
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/store/inmem"
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// fakeInventoryPort is an in-memory model.VolumeInventoryPort.
+type fakeInventoryPort struct {
+	resources map[string][]*model.VolumeResource // key: provider ID
+	marked    []string
+	unmarked  []string
+	deleted   []string
+}
+
+func (f *fakeInventoryPort) ResourceList(ctx context.Context, provider *model.Provider) ([]*model.VolumeResource, error) {
+	if provider.Driver == "k3s" {
+		return nil, model.ErrNotSupported
+	}
+	return f.resources[provider.ID], nil
+}
+
+func (f *fakeInventoryPort) ResourceMarkOrphaned(ctx context.Context, provider *model.Provider, res *model.VolumeResource, at time.Time) error {
+	f.marked = append(f.marked, res.Name)
+	return nil
+}
+
+func (f *fakeInventoryPort) ResourceUnmarkOrphaned(ctx context.Context, provider *model.Provider, res *model.VolumeResource) error {
+	f.unmarked = append(f.unmarked, res.Name)
+	return nil
+}
+
+func (f *fakeInventoryPort) ResourceDelete(ctx context.Context, provider *model.Provider, res *model.VolumeResource) error {
+	f.deleted = append(f.deleted, res.Name)
+	return nil
+}
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	gcNow = func() time.Time { return now }
+	defer func() { gcNow = time.Now }()
+
+	liveHash := naming.NewHashes("w", "p", "", "live").AppID
+	goneHash := naming.NewHashes("w", "p", "", "gone").AppID
+	oldMark := now.Add(-30 * 24 * time.Hour)
+	recentMark := now.Add(-time.Hour)
+
+	newFixture := func(protected bool) (*UseCase, *fakeInventoryPort) {
+		repos := &Repos{
+			Workspace: inmem.NewWorkspaceRepository(),
+			Provider:  inmem.NewProviderRepository(),
+			Cluster:   inmem.NewClusterRepository(),
+			App:       inmem.NewAppRepository(),
+		}
+		_ = repos.Workspace.Create(ctx, &model.Workspace{ID: "/ws/w", Name: "w"})
+		_ = repos.Provider.Create(ctx, &model.Provider{ID: "/ws/w/prv/p", Name: "p", WorkspaceID: "/ws/w", Driver: "aks"})
+		_ = repos.Provider.Create(ctx, &model.Provider{ID: "/ws/w/prv/k", Name: "k", WorkspaceID: "/ws/w", Driver: "k3s"})
+		cls := &model.Cluster{ID: "/ws/w/prv/p/cls/c", Name: "c", ProviderID: "/ws/w/prv/p"}
+		if protected {
+			cls.Protection = &model.ClusterProtection{Provisioning: model.ProtectionCannotDelete}
+		}
+		_ = repos.Cluster.Create(ctx, cls)
+		_ = repos.App.Create(ctx, &model.App{ID: "/ws/w/prv/p/cls/c/app/live", Name: "live", ClusterID: cls.ID})
+
+		rg := "/subscriptions/s/resourceGroups/rg_gone"
+		port := &fakeInventoryPort{resources: map[string][]*model.VolumeResource{
+			"/ws/w/prv/p": {
+				{Kind: "resourceGroup", Name: "rg_gone", Handle: rg, AppIDHash: goneHash, Container: true, OrphanedAt: &oldMark},
+				{Kind: "disk", Name: "disk-old", Handle: rg + "/providers/Microsoft.Compute/disks/disk-old", AppIDHash: goneHash, OrphanedAt: &oldMark},
+				{Kind: "snapshot", Name: "snap-new", Handle: rg + "/providers/Microsoft.Compute/snapshots/snap-new", AppIDHash: goneHash},
+				{Kind: "disk", Name: "disk-recent", Handle: "/subscriptions/s/resourceGroups/rg_other/providers/Microsoft.Compute/disks/disk-recent", AppIDHash: goneHash, OrphanedAt: &recentMark},
+				{Kind: "disk", Name: "disk-live", Handle: "/subscriptions/s/resourceGroups/rg_live/providers/Microsoft.Compute/disks/disk-live", AppIDHash: liveHash},
+				{Kind: "snapshot", Name: "snap-back", Handle: "/subscriptions/s/resourceGroups/rg_live/providers/Microsoft.Compute/snapshots/snap-back", AppIDHash: liveHash, OrphanedAt: &oldMark},
+			},
+		}}
+		return &UseCase{Repos: repos, VolumeInventoryPort: port}, port
+	}
+
+	actions := func(out *GCOutput) map[string]string {
+		m := map[string]string{}
+		for _, it := range out.Items {
+			m[it.Resource.Name] = it.Action
+		}
+		return m
+	}
+
+	t.Run("dry-run reports without side effects", func(t *testing.T) {
+		u, port := newFixture(false)
+		out, err := u.GC(ctx, &GCInput{DryRun: true, GracePeriod: 7 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("GC() error = %v", err)
+		}
+		got := actions(out)
+		want := map[string]string{
+			"disk-old":    GCActionWouldDelete,
+			"snap-new":    GCActionOrphaned,
+			"disk-recent": GCActionPending,
+			"rg_gone":     GCActionPending,
+			"snap-back":   GCActionWouldUnmark,
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("action[%s] = %q, want %q", k, got[k], v)
+			}
+		}
+		if _, ok := got["disk-live"]; ok {
+			t.Errorf("live app resource must not be reported")
+		}
+		if len(port.marked) != 0 || len(port.unmarked) != 0 || len(port.deleted) != 0 {
+			t.Errorf("dry-run must not mark or delete: marked=%v deleted=%v", port.marked, port.deleted)
+		}
+		if len(out.Skipped) != 1 || out.Skipped[0].ProviderID != "/ws/w/prv/k" {
+			t.Errorf("skipped = %+v, want k3s provider", out.Skipped)
+		}
+	})
+
+	t.Run("delete marks new orphans and deletes expired ones", func(t *testing.T) {
+		u, port := newFixture(false)
+		out, err := u.GC(ctx, &GCInput{GracePeriod: 7 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("GC() error = %v", err)
+		}
+		got := actions(out)
+		if got["disk-old"] != GCActionDeleted || got["snap-new"] != GCActionMarked || got["rg_gone"] != GCActionPending {
+			t.Errorf("actions = %v", got)
+		}
+		if strings.Join(port.deleted, ",") != "disk-old" {
+			t.Errorf("deleted = %v, want [disk-old] (resource group still has contents)", port.deleted)
+		}
+		if strings.Join(port.marked, ",") != "snap-new" {
+			t.Errorf("marked = %v, want [snap-new]", port.marked)
+		}
+		if got["snap-back"] != GCActionUnmarked || strings.Join(port.unmarked, ",") != "snap-back" {
+			t.Errorf("unmarked = %v (action %q), want [snap-back]", port.unmarked, got["snap-back"])
+		}
+	})
+
+	t.Run("zero grace period requires force", func(t *testing.T) {
+		u, port := newFixture(false)
+		if _, err := u.GC(ctx, &GCInput{ProviderID: "/ws/w/prv/p"}); err == nil {
+			t.Fatal("expected error for zero grace period without force")
+		}
+		if len(port.marked) != 0 || len(port.deleted) != 0 {
+			t.Errorf("rejected run must not mark or delete: marked=%v deleted=%v", port.marked, port.deleted)
+		}
+		if _, err := u.GC(ctx, &GCInput{ProviderID: "/ws/w/prv/p", DryRun: true}); err != nil {
+			t.Errorf("dry-run with zero grace period: %v", err)
+		}
+	})
+
+	t.Run("zero grace period deletes contents before container", func(t *testing.T) {
+		u, port := newFixture(false)
+		if _, err := u.GC(ctx, &GCInput{ProviderID: "/ws/w/prv/p", Force: true}); err != nil {
+			t.Fatalf("GC() error = %v", err)
+		}
+		if n := len(port.deleted); n != 4 || port.deleted[n-1] != "rg_gone" {
+			t.Errorf("deleted = %v, want 4 items with resource group last", port.deleted)
+		}
+	})
+
+	t.Run("cluster protection blocks deletion", func(t *testing.T) {
+		u, port := newFixture(true)
+		out, err := u.GC(ctx, &GCInput{Force: true})
+		if err != nil {
+			t.Fatalf("GC() error = %v", err)
+		}
+		for _, it := range out.Items {
+			if it.Resource.AppIDHash == liveHash {
+				continue
+			}
+			if it.Action != GCActionProtected {
+				t.Errorf("action[%s] = %q, want protected", it.Resource.Name, it.Action)
+			}
+		}
+		if len(port.deleted) != 0 || len(port.marked) != 0 {
+			t.Errorf("protected provider must not be touched: marked=%v deleted=%v", port.marked, port.deleted)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kompox/kompox/domain/model"
+	"github.com/kompox/kompox/internal/naming"
+)
+
+// Lineage node kinds.
+const (
+	LineageKindDisk     = "disk"
+	LineageKindSnapshot = "snapshot"
+)
+
+// LineageInput parameters for Lineage use case.
+type LineageInput struct {
+	// AppID owning application identifier.
+	AppID string `json:"app_id"`
+	// VolumeName logical volume name within the app.
+	VolumeName string `json:"volume_name"`
+}
+
+// LineageNode is a disk or snapshot in the lineage tree of a logical volume.
+type LineageNode struct {
+	// Kind is "disk" or "snapshot".
+	Kind string `json:"kind"`
+	// Disk is set when Kind is "disk".
+	Disk *model.VolumeDisk `json:"disk,omitempty"`
+	// Snapshot is set when Kind is "snapshot".
+	Snapshot *model.VolumeSnapshot `json:"snapshot,omitempty"`
+	// Children are the disks and snapshots created from this node.
+	Children []*LineageNode `json:"children,omitempty"`
+}
+
+// LineageOutput result for Lineage use case.
+type LineageOutput struct {
+	// Roots are the nodes whose source is empty or outside the logical volume.
+	Roots []*LineageNode `json:"roots"`
+}
+
+// Lineage returns the disks and snapshots of a logical volume arranged as a tree
+// following SourceHandle -> Handle links.
+func (u *UseCase) Lineage(ctx context.Context, in *LineageInput) (*LineageOutput, error) {
+	if in == nil || in.AppID == "" || in.VolumeName == "" {
+		return nil, fmt.Errorf("missing parameters")
+	}
+	if err := naming.ValidateVolumeName(in.VolumeName); err != nil {
+		return nil, fmt.Errorf("validate volume name: %w", err)
+	}
+	app, err := u.Repos.App.Get(ctx, in.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, fmt.Errorf("app not found: %s", in.AppID)
+	}
+	cluster, err := u.Repos.Cluster.Get(ctx, app.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster not found: %s", app.ClusterID)
+	}
+	if _, err := app.FindVolume(in.VolumeName); err != nil {
+		return nil, fmt.Errorf("volume not defined: %w", err)
+	}
+	disks, err := u.VolumePort.DiskList(ctx, cluster, app, in.VolumeName)
+	if err != nil {
+		return nil, fmt.Errorf("list disks: %w", err)
+	}
+	snaps, err := u.VolumePort.SnapshotList(ctx, cluster, app, in.VolumeName)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+	return &LineageOutput{Roots: BuildLineage(disks, snaps)}, nil
+}
+
+// BuildLineage links disks and snapshots by SourceHandle -> Handle and returns the roots.
+// Handles are compared case-insensitively because providers may normalize resource IDs.
+// Nodes at each level are ordered by creation time, then name.
+func BuildLineage(disks []*model.VolumeDisk, snaps []*model.VolumeSnapshot) []*LineageNode {
+	var nodes []*LineageNode
+	byHandle := map[string]*LineageNode{}
+	for _, d := range disks {
+		n := &LineageNode{Kind: LineageKindDisk, Disk: d}
+		nodes = append(nodes, n)
+		if d.Handle != "" {
+			byHandle[strings.ToLower(d.Handle)] = n
+		}
+	}
+	for _, s := range snaps {
+		n := &LineageNode{Kind: LineageKindSnapshot, Snapshot: s}
+		nodes = append(nodes, n)
+		if s.Handle != "" {
+			byHandle[strings.ToLower(s.Handle)] = n
+		}
+	}
+
+	var roots []*LineageNode
+	for _, n := range nodes {
+		src := n.sourceHandle()
+		if parent, ok := byHandle[strings.ToLower(src)]; ok && src != "" && parent != n {
+			parent.Children = append(parent.Children, n)
+			continue
+		}
+		roots = append(roots, n)
+	}
+	sortLineage(roots)
+	return roots
+}
+
+// WriteLineageTree writes the lineage tree as indented text.
+func WriteLineageTree(w io.Writer, roots []*LineageNode) error {
+	var walk func(nodes []*LineageNode, prefix string) error
+	walk = func(nodes []*LineageNode, prefix string) error {
+		for i, n := range nodes {
+			branch, next := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, next = "└── ", "    "
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%s\n", prefix, branch, n.label()); err != nil {
+				return err
+			}
+			if err := walk(n.Children, prefix+next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(roots, "")
+}
+
+func (n *LineageNode) name() string {
+	if n.Kind == LineageKindDisk {
+		return n.Disk.Name
+	}
+	return n.Snapshot.Name
+}
+
+func (n *LineageNode) sourceHandle() string {
+	if n.Kind == LineageKindDisk {
+		return n.Disk.SourceHandle
+	}
+	return n.Snapshot.SourceHandle
+}
+
+func (n *LineageNode) label() string {
+	var sb strings.Builder
+	sb.WriteString(n.Kind)
+	sb.WriteString(":")
+	sb.WriteString(n.name())
+	if n.Kind == LineageKindDisk && n.Disk.Assigned {
+		sb.WriteString(" (assigned)")
+	}
+	var labels map[string]string
+	if n.Kind == LineageKindDisk {
+		labels = n.Disk.Labels
+	} else {
+		labels = n.Snapshot.Labels
+	}
+	if len(labels) > 0 {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+labels[k])
+		}
+		sb.WriteString(" [")
+		sb.WriteString(strings.Join(pairs, ","))
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+func sortLineage(nodes []*LineageNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ti, tj := nodes[i].createdAtUnix(), nodes[j].createdAtUnix()
+		if ti != tj {
+			return ti < tj
+		}
+		return nodes[i].name() < nodes[j].name()
+	})
+	for _, n := range nodes {
+		sortLineage(n.Children)
+	}
+}
+
+func (n *LineageNode) createdAtUnix() int64 {
+	if n.Kind == LineageKindDisk {
+		return n.Disk.CreatedAt.UnixNano()
+	}
+	return n.Snapshot.CreatedAt.UnixNano()
+}
@@ -0,0 +1,100 @@
+package volume
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kompox/kompox/adapters/store/inmem"
+	"github.com/kompox/kompox/domain/model"
+)
+
+func TestBuildLineage(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	disks := []*model.VolumeDisk{
+		{Name: "d1", Handle: "/subscriptions/s/disks/d1", Assigned: true, CreatedAt: t0},
+		{Name: "d2", Handle: "/subscriptions/s/disks/d2", SourceHandle: "/SUBSCRIPTIONS/S/snapshots/s1", CreatedAt: t0.Add(2 * time.Hour), Labels: map[string]string{"env": "dev"}},
+		{Name: "d3", Handle: "/subscriptions/s/disks/d3", SourceHandle: "/subscriptions/other/snapshots/x", CreatedAt: t0.Add(3 * time.Hour)},
+	}
+	snaps := []*model.VolumeSnapshot{
+		{Name: "s1", Handle: "/subscriptions/s/snapshots/s1", SourceHandle: "/subscriptions/s/disks/d1", CreatedAt: t0.Add(time.Hour)},
+	}
+
+	roots := BuildLineage(disks, snaps)
+	if len(roots) != 2 {
+		t.Fatalf("roots = %d, want 2", len(roots))
+	}
+	if roots[0].Disk == nil || roots[0].Disk.Name != "d1" || roots[1].Disk == nil || roots[1].Disk.Name != "d3" {
+		t.Fatalf("unexpected roots order: %s, %s", roots[0].name(), roots[1].name())
+	}
+	s1 := roots[0].Children
+	if len(s1) != 1 || s1[0].Kind != LineageKindSnapshot || s1[0].Snapshot.Name != "s1" {
+		t.Fatalf("d1 children = %+v, want snapshot s1", s1)
+	}
+	if len(s1[0].Children) != 1 || s1[0].Children[0].Disk.Name != "d2" {
+		t.Fatalf("s1 children = %+v, want disk d2", s1[0].Children)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLineageTree(&buf, roots); err != nil {
+		t.Fatal(err)
+	}
+	want := "├── disk:d1 (assigned)\n" +
+		"│   └── snapshot:s1\n" +
+		"│       └── disk:d2 [env=dev]\n" +
+		"└── disk:d3\n"
+	if buf.String() != want {
+		t.Errorf("WriteLineageTree() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestListLabelSelector(t *testing.T) {
+	ctx := context.Background()
+	const appID = "/ws/w/prv/p/cls/c/app/a"
+	repos := &Repos{
+		Workspace: inmem.NewWorkspaceRepository(),
+		Provider:  inmem.NewProviderRepository(),
+		Cluster:   inmem.NewClusterRepository(),
+		App:       inmem.NewAppRepository(),
+	}
+	_ = repos.Cluster.Create(ctx, &model.Cluster{ID: "/ws/w/prv/p/cls/c", ProviderID: "/ws/w/prv/p"})
+	_ = repos.App.Create(ctx, &model.App{ID: appID, ClusterID: "/ws/w/prv/p/cls/c", Volumes: []model.AppVolume{{Name: "db"}}})
+	port := &mockVolumePort{
+		disks: map[string][]*model.VolumeDisk{appID + "/db": {
+			{Name: "d1", Labels: map[string]string{"env": "prod", "tier": "db"}},
+			{Name: "d2", Labels: map[string]string{"env": "dev"}},
+			{Name: "d3"},
+		}},
+		snapshots: map[string][]*model.VolumeSnapshot{appID + "/db": {
+			{Name: "s1", Labels: map[string]string{"env": "prod"}},
+			{Name: "s2"},
+		}},
+	}
+	u := &UseCase{Repos: repos, VolumePort: port}
+
+	dout, err := u.DiskList(ctx, &DiskListInput{AppID: appID, VolumeName: "db", Labels: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("DiskList() error = %v", err)
+	}
+	if len(dout.Items) != 1 || dout.Items[0].Name != "d1" {
+		t.Errorf("DiskList() items = %v, want [d1]", dout.Items)
+	}
+	dout, err = u.DiskList(ctx, &DiskListInput{AppID: appID, VolumeName: "db"})
+	if err != nil {
+		t.Fatalf("DiskList() error = %v", err)
+	}
+	if len(dout.Items) != 3 {
+		t.Errorf("DiskList() without selector returned %d items, want 3", len(dout.Items))
+	}
+	sout, err := u.SnapshotList(ctx, &SnapshotListInput{AppID: appID, VolumeName: "db", Labels: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("SnapshotList() error = %v", err)
+	}
+	if len(sout.Items) != 1 || sout.Items[0].Name != "s1" {
+		t.Errorf("SnapshotList() items = %v, want [s1]", sout.Items)
+	}
+	if _, err := u.DiskCreate(ctx, &DiskCreateInput{AppID: appID, VolumeName: "db", Labels: map[string]string{"Bad-Key": "x"}}); err == nil {
+		t.Errorf("DiskCreate() with invalid label key must fail")
+	}
+}
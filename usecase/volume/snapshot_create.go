@@ -14,6 +14,10 @@ type SnapshotCreateInput struct {
 	VolumeName   string `json:"volume_name"`
 	SnapshotName string `json:"snapshot_name,omitempty"`
 	Source       string `json:"source,omitempty"`
+	// Labels are user labels stored with the snapshot as provider tags.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Description is a free-form user description stored with the snapshot.
+	Description string `json:"description,omitempty"`
 }
 
 // SnapshotCreateOutput result for creating a snapshot.
@@ -34,6 +38,12 @@ func (u *UseCase) SnapshotCreate(ctx context.Context, in *SnapshotCreateInput) (
 			return nil, fmt.Errorf("validate snapshot name: %w", err)
 		}
 	}
+	if err := naming.ValidateVolumeLabels(in.Labels); err != nil {
+		return nil, fmt.Errorf("validate labels: %w", err)
+	}
+	if err := naming.ValidateVolumeDescription(in.Description); err != nil {
+		return nil, fmt.Errorf("validate description: %w", err)
+	}
 	app, err := u.Repos.App.Get(ctx, in.AppID)
 	if err != nil {
 		return nil, err
@@ -49,11 +59,25 @@ func (u *UseCase) SnapshotCreate(ctx context.Context, in *SnapshotCreateInput) (
 		return nil, fmt.Errorf("cluster not found: %s", app.ClusterID)
 	}
 	// Validate logical volume exists
-	_, err = app.FindVolume(in.VolumeName)
+	vol, err := app.FindVolume(in.VolumeName)
 	if err != nil {
 		return nil, fmt.Errorf("volume not defined: %w", err)
 	}
-	snap, err := u.VolumePort.SnapshotCreate(ctx, cluster, app, in.VolumeName, in.SnapshotName, in.Source)
+	caps, err := u.capabilities(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := caps.CheckVolumeSnapshot(*vol); err != nil {
+		return nil, err
+	}
+	var opts []model.VolumeSnapshotCreateOption
+	if len(in.Labels) > 0 {
+		opts = append(opts, model.WithVolumeSnapshotCreateLabels(in.Labels))
+	}
+	if in.Description != "" {
+		opts = append(opts, model.WithVolumeSnapshotCreateDescription(in.Description))
+	}
+	snap, err := u.VolumePort.SnapshotCreate(ctx, cluster, app, in.VolumeName, in.SnapshotName, in.Source, opts...)
 	if err != nil {
 		return nil, err
 	}
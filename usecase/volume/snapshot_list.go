@@ -12,6 +12,8 @@ import (
 type SnapshotListInput struct {
 	AppID      string `json:"app_id"`
 	VolumeName string `json:"volume_name"`
+	// Labels is an optional label selector; only snapshots having all key/value pairs are returned.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // SnapshotListOutput result of listing snapshots.
@@ -50,5 +52,14 @@ func (u *UseCase) SnapshotList(ctx context.Context, in *SnapshotListInput) (*Sna
 	if err != nil {
 		return nil, err
 	}
+	if len(in.Labels) > 0 {
+		filtered := make([]*model.VolumeSnapshot, 0, len(items))
+		for _, s := range items {
+			if model.MatchLabels(s.Labels, in.Labels) {
+				filtered = append(filtered, s)
+			}
+		}
+		items = filtered
+	}
 	return &SnapshotListOutput{Items: items}, nil
 }
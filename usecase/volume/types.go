@@ -1,6 +1,9 @@
 package volume
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/kompox/kompox/domain"
 	"github.com/kompox/kompox/domain/model"
 )
@@ -17,4 +20,15 @@ type Repos struct {
 type UseCase struct {
 	Repos      *Repos
 	VolumePort model.VolumePort
+	// VolumeInventoryPort is used by GC to enumerate provider resources across apps.
+	VolumeInventoryPort model.VolumeInventoryPort
+}
+
+// capabilities returns the capabilities of the driver managing the cluster.
+func (u *UseCase) capabilities(ctx context.Context, cluster *model.Cluster) (*model.DriverCapabilities, error) {
+	caps, err := u.VolumePort.Capabilities(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("get driver capabilities: %w", err)
+	}
+	return caps, nil
 }